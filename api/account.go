@@ -2,54 +2,266 @@ package api
 
 import (
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	db "github.com/khuongkd/simplebank/db/sqlc"
+	"github.com/khuongkd/simplebank/util"
 )
 
+// accountResponse is the account as exposed to clients, with its formatted account number.
+// The ID is what the routes and transfer requests address the account by.
+type accountResponse struct {
+	ID            int64     `json:"id"`
+	AccountNumber string    `json:"account_number"`
+	Owner         string    `json:"owner"`
+	Balance       int64     `json:"balance"`
+	Currency      string    `json:"currency"`
+	CreatedAt     time.Time `json:"created_at"`
+	// outgoing transfers only go to destinations in account_whitelist
+	WhitelistEnabled bool `json:"whitelist_enabled"`
+	// part of the balance reserved by authorized holds
+	HeldBalance int64 `json:"held_balance"`
+	// checking or savings; selects the policy applied to transfers out of the account
+	AccountType string `json:"account_type"`
+	// outgoing transfers cannot take the balance below it
+	MinBalance int64   `json:"min_balance"`
+	Nickname   *string `json:"nickname"`
+	// free-form JSON object kept for the owner
+	Metadata json.RawMessage `json:"metadata"`
+	// a frozen account takes part in no transfer
+	Frozen bool `json:"frozen"`
+	// why a frozen account was frozen, from the configured taxonomy; null while not frozen
+	FreezeReason *string `json:"freeze_reason"`
+}
+
+func (server *Server) newAccountResponse(account db.Account) accountResponse {
+	return accountResponse{
+		ID:               account.ID,
+		AccountNumber:    util.FormatAccountNumber(server.config.AccountNumberPrefix, account.Number),
+		Owner:            account.Owner,
+		Balance:          account.Balance,
+		Currency:         account.Currency,
+		CreatedAt:        account.CreatedAt,
+		WhitelistEnabled: account.WhitelistEnabled,
+		HeldBalance:      account.HeldBalance,
+		AccountType:      account.AccountType,
+		MinBalance:       account.MinBalance,
+		Nickname:         account.Nickname,
+		Metadata:         account.Metadata,
+		Frozen:           account.Frozen,
+		FreezeReason:     account.FreezeReason,
+	}
+}
+
 type createAccountRequest struct {
+	// Owner must satisfy the configured owner policy; it is a free-form name until users exist
 	Owner    string `json:"owner" binding:"required"`
-	Currency string `json:"currency" binding:"required,oneof=USD EUR"`
+	Currency string `json:"currency" binding:"required"`
+	// AccountType defaults to checking
+	AccountType string `json:"account_type" binding:"omitempty,oneof=checking savings"`
 }
 
+// createAccount godoc
+// @Summary  Create an account
+// @Description  The owner must satisfy the configured owner policy; the details of a 400 list every rule it breaks.
+// @Tags     accounts
+// @Accept   json
+// @Produce  json
+// @Param    request  body      createAccountRequest  true  "Account to create"
+// @Success  200      {object}  accountResponse
+// @Failure  400      {object}  apiError
+// @Failure  403      {object}  apiError
+// @Failure  429      {object}  apiError
+// @Failure  500      {object}  apiError
+// @Router   /accounts [post]
 func (server *Server) createAccount(ctx *gin.Context) {
 	var req createAccountRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	if !server.validOwner(ctx, req.Owner) {
+		return
+	}
+
+	if !server.supportedCurrency(ctx, req.Currency) {
+		return
+	}
+
+	if !server.withinAccountCreationLimit(ctx, req.Owner) {
 		return
 	}
 
 	arg := db.CreateAcountParams{
-		Owner:    req.Owner,
-		Currency: req.Currency,
-		Balance:  0,
+		Owner:             req.Owner,
+		Currency:          req.Currency,
+		Balance:           0,
+		AccountType:       req.AccountType,
+		NumberPerCurrency: server.config.AccountNumberPerCurrency,
+	}
+	if !server.allowedToCreateAccount(ctx, arg) {
+		return
 	}
 
-	account, err := server.store.CreateAcount(ctx, arg)
+	account, err := server.store.CreateAcount(ctx.Request.Context(), arg)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, account)
+	ctx.JSON(http.StatusOK, server.newAccountResponse(account))
+}
+
+// validOwner checks the owner of a new account against the configured policy, writing the error response otherwise.
+// The details list every rule the owner breaks, the same way they list the fields that fail binding.
+func (server *Server) validOwner(ctx *gin.Context, owner string) bool {
+	policy := server.currentConfig().OwnerPolicy()
+	err := util.ValidateOwner(owner, policy)
+	if err == nil {
+		return true
+	}
+
+	rsp := apiError{Code: ErrCodeInvalidRequest, Message: err.Error()}
+	var policyErr *util.OwnerPolicyError
+	if errors.As(err, &policyErr) {
+		details := make([]fieldError, len(policyErr.Violations))
+		for i, rule := range policyErr.Violations {
+			details[i] = fieldError{Field: "owner", Rule: string(rule), Param: ownerRuleParam(rule, policy)}
+		}
+		rsp.Details = details
+	}
+	ctx.JSON(http.StatusBadRequest, rsp)
+	return false
+}
+
+// ownerRuleParam is the setting of the owner policy a rule checks against, if any
+func ownerRuleParam(rule util.OwnerRule, policy util.OwnerPolicy) string {
+	switch rule {
+	case util.OwnerRuleMinLength:
+		return strconv.Itoa(policy.MinLength)
+	case util.OwnerRuleMaxLength:
+		return strconv.Itoa(policy.MaxLength)
+	case util.OwnerRuleCharacters:
+		return policy.AllowedSymbols
+	}
+	return ""
+}
+
+// withinAccountCreationLimit checks the owner has created fewer accounts than allowed within the configured window,
+// writing the error response otherwise. A zero limit or window disables the check.
+func (server *Server) withinAccountCreationLimit(ctx *gin.Context, owner string) bool {
+	config := server.currentConfig()
+	if config.AccountCreationLimit <= 0 || config.AccountCreationWindow <= 0 {
+		return true
+	}
+
+	count, err := server.store.CountAccountsByOwnerSince(ctx.Request.Context(), db.CountAccountsByOwnerSinceParams{
+		Owner: owner,
+		Since: time.Now().Add(-config.AccountCreationWindow),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+
+	if count >= int64(config.AccountCreationLimit) {
+		err := fmt.Errorf("%w: %d accounts created in the last %s", errAccountLimitExceeded, count, config.AccountCreationWindow)
+		ctx.Header("Retry-After", server.rateLimitRetryAfter())
+		ctx.JSON(http.StatusTooManyRequests, errorResponse(err))
+		return false
+	}
+
+	return true
 }
 
 type getAccountRequest struct {
 	ID int64 `uri:"id" binding:"required,min=1"`
 }
 
+// getAccount godoc
+// @Summary  Get an account by ID
+// @Tags     accounts
+// @Produce  json
+// @Param    id   path      int  true  "Account ID"
+// @Success  200  {object}  accountResponse
+// @Failure  400  {object}  apiError
+// @Failure  404  {object}  apiError
+// @Failure  500  {object}  apiError
+// @Router   /account/{id} [get]
 func (server *Server) getAccount(ctx *gin.Context) {
 	var req getAccountRequest
 	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	account, err := server.store.GetAccount(ctx.Request.Context(), req.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(errAccountNotFound))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, server.newAccountResponse(account))
+}
+
+type getAccountByNumberRequest struct {
+	Number string `uri:"number" binding:"required"`
+}
+
+type getAccountByNumberQuery struct {
+	Currency string `form:"currency" binding:"required"`
+}
+
+// getAccountByNumber godoc
+// @Summary  Get an account by its account number
+// @Description  Account numbers are only unique within a currency, so the currency is part of the lookup.
+// @Tags     accounts
+// @Produce  json
+// @Param    number    path      string  true  "Account number"
+// @Param    currency  query     string  true  "Currency of the account"
+// @Success  200     {object}  accountResponse
+// @Failure  400     {object}  apiError
+// @Failure  404     {object}  apiError
+// @Failure  500     {object}  apiError
+// @Router   /accounts/by-number/{number} [get]
+func (server *Server) getAccountByNumber(ctx *gin.Context) {
+	var req getAccountByNumberRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	var query getAccountByNumberQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	number, err := util.ParseAccountNumber(server.config.AccountNumberPrefix, req.Number)
+	if err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
 
-	account, err := server.store.GetAccount(ctx, req.ID)
+	account, err := server.store.GetAccountByNumberAndCurrency(ctx.Request.Context(), db.GetAccountByNumberAndCurrencyParams{
+		Number:   number,
+		Currency: query.Currency,
+	})
 	if err != nil {
 		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			ctx.JSON(http.StatusNotFound, errorResponse(errAccountNotFound))
 			return
 		}
 
@@ -57,30 +269,41 @@ func (server *Server) getAccount(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, account)
+	ctx.JSON(http.StatusOK, server.newAccountResponse(account))
 }
 
 type listAccountRequest struct {
-	PageID   int32 `form:"page_id" binding:"required,min=1"`
-	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+	pageRequest
 }
 
+// listAccount godoc
+// @Summary  List accounts
+// @Tags     accounts
+// @Produce  json
+// @Param    page_id    query     int     true   "Page number, starting at 1"
+// @Param    page_size  query     int     false  "Page size, between 5 and 10; defaults to the configured page size"
+// @Param    format     query     string  false  "Response format, bare or envelope; defaults to the configured format"
+// @Success  200        {array}   accountResponse
+// @Failure  400        {object}  apiError
+// @Failure  500        {object}  apiError
+// @Router   /accounts [get]
 func (server *Server) listAccount(ctx *gin.Context) {
 	var req listAccountRequest
 	if err := ctx.ShouldBindQuery(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
 		return
 	}
 
+	limit, offset := server.page(req.pageRequest)
 	listAccountsParams := db.ListAccountsParams{
-		Limit:  req.PageSize,
-		Offset: (req.PageID - 1) * req.PageSize,
+		Limit:  limit,
+		Offset: offset,
 	}
 
-	account, err := server.store.ListAccounts(ctx, listAccountsParams)
+	accounts, err := server.store.ListAccounts(ctx.Request.Context(), listAccountsParams)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			ctx.JSON(http.StatusNotFound, errorResponse(errAccountNotFound))
 			return
 		}
 
@@ -88,5 +311,9 @@ func (server *Server) listAccount(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, account)
+	rsp := make([]accountResponse, len(accounts))
+	for i, account := range accounts {
+		rsp[i] = server.newAccountResponse(account)
+	}
+	server.writeList(ctx, req.pageRequest, rsp)
 }
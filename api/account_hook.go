@@ -0,0 +1,42 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+)
+
+// ErrAccountCreationVetoed is wrapped by a BeforeCreateAccountFunc to refuse an account, such as one failing a KYC check.
+// The client gets a 403 response carrying the message of the returned error.
+var ErrAccountCreationVetoed = errors.New("account creation was refused")
+
+// BeforeCreateAccountFunc runs before an account is created and may veto it by returning an error wrapping ErrAccountCreationVetoed.
+// Any other error fails the request as an internal error.
+type BeforeCreateAccountFunc func(ctx context.Context, arg db.CreateAcountParams) error
+
+// UseBeforeCreateAccount runs hook before every account the server creates. A nil hook removes it.
+func (server *Server) UseBeforeCreateAccount(hook BeforeCreateAccountFunc) {
+	server.beforeCreateAccount = hook
+}
+
+// allowedToCreateAccount runs the account creation hook, if one is set, writing the error response when it fails
+func (server *Server) allowedToCreateAccount(ctx *gin.Context, arg db.CreateAcountParams) bool {
+	if server.beforeCreateAccount == nil {
+		return true
+	}
+
+	if err := server.beforeCreateAccount(ctx.Request.Context(), arg); err != nil {
+		if errors.Is(err, ErrAccountCreationVetoed) {
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return false
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+
+	return true
+}
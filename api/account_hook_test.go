@@ -0,0 +1,93 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khuongkd/simplebank/db/mock"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBeforeCreateAccountHook(t *testing.T) {
+	account := randomAccount()
+
+	testCases := []struct {
+		name          string
+		hook          BeforeCreateAccountFunc
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Allow",
+			hook: func(_ context.Context, arg db.CreateAcountParams) error {
+				require.Equal(t, account.Owner, arg.Owner)
+				require.Equal(t, account.Currency, arg.Currency)
+				return nil
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAcount(gomock.Any(), gomock.Any()).Times(1).Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchAccount(t, recorder.Body, account)
+			},
+		},
+		{
+			name: "Veto",
+			hook: func(context.Context, db.CreateAcountParams) error {
+				return fmt.Errorf("%w: identity not verified", ErrAccountCreationVetoed)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAcount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				rsp := requireErrorCode(t, recorder, ErrCodeAccountVetoed)
+				require.Contains(t, rsp.Message, "identity not verified")
+			},
+		},
+		{
+			name: "HookError",
+			hook: func(context.Context, db.CreateAcountParams) error {
+				return errors.New("kyc service unavailable")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAcount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeInternal)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			server.UseBeforeCreateAccount(tc.hook)
+			recorder := httptest.NewRecorder()
+
+			request := newPostRequest(t, "/accounts", gin.H{
+				"owner":    account.Owner,
+				"currency": account.Currency,
+			})
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
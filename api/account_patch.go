@@ -0,0 +1,171 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+	"github.com/khuongkd/simplebank/util"
+)
+
+type patchAccountURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// patchAccountRequest holds the fields to change; the ones left out keep their value
+type patchAccountRequest struct {
+	// Nickname is removed when empty
+	Nickname *string `json:"nickname" binding:"omitempty,max=64"`
+	// Metadata replaces the whole metadata object
+	Metadata json.RawMessage `json:"metadata" swaggertype:"object"`
+	// Frozen can only be changed by a banker
+	Frozen *bool `json:"frozen"`
+	// FreezeReason is required when freezing, one of the configured reasons such as fraud or kyc
+	FreezeReason *string `json:"freeze_reason"`
+}
+
+// fields lists the JSON names of the fields set in the request
+func (req patchAccountRequest) fields() []string {
+	var fields []string
+	if req.Nickname != nil {
+		fields = append(fields, "nickname")
+	}
+	if req.Metadata != nil {
+		fields = append(fields, "metadata")
+	}
+	if req.Frozen != nil {
+		fields = append(fields, "frozen")
+	}
+	if req.FreezeReason != nil {
+		fields = append(fields, "freeze_reason")
+	}
+	return fields
+}
+
+// bankerOnly lists the fields set in the request that only a banker may change
+func (req patchAccountRequest) bankerOnly() []string {
+	var fields []string
+	if req.Frozen != nil {
+		fields = append(fields, "frozen")
+	}
+	if req.FreezeReason != nil {
+		fields = append(fields, "freeze_reason")
+	}
+	return fields
+}
+
+// checkFreezeReason makes sure a reason comes with freezing an account, and only then
+func (req patchAccountRequest) checkFreezeReason(config *util.Config) error {
+	freezing := req.Frozen != nil && *req.Frozen
+	switch {
+	case freezing && req.FreezeReason == nil:
+		return errFreezeReasonRequired
+	case !freezing && req.FreezeReason != nil:
+		return errFreezeReasonUnfrozen
+	case freezing && !config.AllowsFreezeReason(*req.FreezeReason):
+		return fmt.Errorf("%w: %q", errInvalidFreezeReason, *req.FreezeReason)
+	}
+	return nil
+}
+
+// patchAccount godoc
+// @Summary  Change some fields of an account
+// @Description  Only the fields given change. Freezing or unfreezing an account is left to bankers, through the admin route.
+// @Tags     accounts
+// @Accept   json
+// @Produce  json
+// @Param    id       path      int                  true  "Account ID"
+// @Param    request  body      patchAccountRequest  true  "Fields to change"
+// @Success  200      {object}  accountResponse
+// @Failure  400      {object}  apiError
+// @Failure  403      {object}  apiError
+// @Failure  404      {object}  apiError
+// @Failure  500      {object}  apiError
+// @Router   /accounts/{id} [patch]
+func (server *Server) patchAccount(ctx *gin.Context) {
+	server.applyAccountPatch(ctx, false)
+}
+
+// bankerPatchAccount godoc
+// @Summary  Change some fields of an account, frozen included
+// @Description  Freezing an account takes one of the configured freeze reasons; unfreezing it clears the reason.
+// @Tags     admin
+// @Accept   json
+// @Produce  json
+// @Param    id       path      int                  true  "Account ID"
+// @Param    request  body      patchAccountRequest  true  "Fields to change"
+// @Success  200      {object}  accountResponse
+// @Failure  400      {object}  apiError
+// @Failure  404      {object}  apiError
+// @Failure  500      {object}  apiError
+// @Router   /admin/accounts/{id} [patch]
+func (server *Server) bankerPatchAccount(ctx *gin.Context) {
+	server.applyAccountPatch(ctx, true)
+}
+
+// applyAccountPatch changes the fields of the request in a single update, once the caller is allowed to change all of them
+func (server *Server) applyAccountPatch(ctx *gin.Context, banker bool) {
+	var uri patchAccountURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	var req patchAccountRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	if len(req.fields()) == 0 {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errEmptyPatch))
+		return
+	}
+	if req.Metadata != nil && !bytes.HasPrefix(bytes.TrimSpace(req.Metadata), []byte("{")) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errMetadataNotObject))
+		return
+	}
+	if fields := req.bankerOnly(); !banker && len(fields) > 0 {
+		err := fmt.Errorf("%w: %v", errFieldNotPermitted, fields)
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return
+	}
+	if err := req.checkFreezeReason(server.currentConfig()); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := db.PatchAccountParams{ID: uri.ID}
+	if req.Nickname != nil {
+		arg.SetNickname = true
+		arg.Nickname = *req.Nickname
+	}
+	if req.Metadata != nil {
+		arg.SetMetadata = true
+		arg.Metadata = string(req.Metadata)
+	}
+	if req.Frozen != nil {
+		arg.SetFrozen = true
+		arg.Frozen = *req.Frozen
+		if req.FreezeReason != nil {
+			arg.FreezeReason = *req.FreezeReason
+		}
+	}
+
+	account, err := server.store.PatchAccount(ctx.Request.Context(), arg)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(errAccountNotFound))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, server.newAccountResponse(account))
+}
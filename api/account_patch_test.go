@@ -0,0 +1,265 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khuongkd/simplebank/db/mock"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+	"github.com/khuongkd/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatchAccountAPI(t *testing.T) {
+	account := randomAccount()
+	customerURL := fmt.Sprintf("/accounts/%d", account.ID)
+	bankerURL := fmt.Sprintf("/admin/accounts/%d", account.ID)
+
+	testCases := []struct {
+		name          string
+		url           string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Nickname",
+			url:  customerURL,
+			body: gin.H{"nickname": "rainy day"},
+			buildStubs: func(store *mockdb.MockStore) {
+				nickname := "rainy day"
+				updated := account
+				updated.Nickname = &nickname
+				arg := db.PatchAccountParams{ID: account.ID, SetNickname: true, Nickname: nickname}
+				store.EXPECT().PatchAccount(gomock.Any(), gomock.Eq(arg)).Times(1).Return(updated, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp accountResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, "rainy day", *rsp.Nickname)
+			},
+		},
+		{
+			name: "NicknameAndMetadata",
+			url:  customerURL,
+			body: gin.H{"nickname": "", "metadata": gin.H{"color": "blue"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				updated := account
+				updated.Metadata = json.RawMessage(`{"color":"blue"}`)
+				arg := db.PatchAccountParams{
+					ID:          account.ID,
+					SetNickname: true,
+					SetMetadata: true,
+					Metadata:    `{"color":"blue"}`,
+				}
+				store.EXPECT().PatchAccount(gomock.Any(), gomock.Eq(arg)).Times(1).Return(updated, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp accountResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Nil(t, rsp.Nickname)
+				require.JSONEq(t, `{"color":"blue"}`, string(rsp.Metadata))
+			},
+		},
+		{
+			name: "FrozenByCustomer",
+			url:  customerURL,
+			body: gin.H{"nickname": "rainy day", "frozen": true},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().PatchAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeFieldNotPermitted)
+			},
+		},
+		{
+			name: "FrozenByBanker",
+			url:  bankerURL,
+			body: gin.H{"nickname": "rainy day", "frozen": true, "freeze_reason": "fraud"},
+			buildStubs: func(store *mockdb.MockStore) {
+				reason := "fraud"
+				updated := account
+				updated.Frozen = true
+				updated.FreezeReason = &reason
+				arg := db.PatchAccountParams{
+					ID:           account.ID,
+					SetNickname:  true,
+					Nickname:     "rainy day",
+					SetFrozen:    true,
+					Frozen:       true,
+					FreezeReason: reason,
+				}
+				store.EXPECT().PatchAccount(gomock.Any(), gomock.Eq(arg)).Times(1).Return(updated, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp accountResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.True(t, rsp.Frozen)
+				require.Equal(t, "fraud", *rsp.FreezeReason)
+			},
+		},
+		{
+			name: "FrozenWithoutReason",
+			url:  bankerURL,
+			body: gin.H{"frozen": true},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().PatchAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeInvalidRequest)
+			},
+		},
+		{
+			name: "UnknownFreezeReason",
+			url:  bankerURL,
+			body: gin.H{"frozen": true, "freeze_reason": "boredom"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().PatchAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeInvalidRequest)
+			},
+		},
+		{
+			name: "FreezeReasonWhenUnfreezing",
+			url:  bankerURL,
+			body: gin.H{"frozen": false, "freeze_reason": "fraud"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().PatchAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeInvalidRequest)
+			},
+		},
+		{
+			name: "FreezeReasonByCustomer",
+			url:  customerURL,
+			body: gin.H{"freeze_reason": "fraud"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().PatchAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeFieldNotPermitted)
+			},
+		},
+		{
+			name: "UnfreezeByBanker",
+			url:  bankerURL,
+			body: gin.H{"frozen": false},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.PatchAccountParams{ID: account.ID, SetFrozen: true, Frozen: false}
+				store.EXPECT().PatchAccount(gomock.Any(), gomock.Eq(arg)).Times(1).Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "NoField",
+			url:  customerURL,
+			body: gin.H{},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().PatchAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeInvalidRequest)
+			},
+		},
+		{
+			name: "MetadataNotObject",
+			url:  customerURL,
+			body: gin.H{"metadata": []string{"blue"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().PatchAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeInvalidRequest)
+			},
+		},
+		{
+			name: "NotFound",
+			url:  customerURL,
+			body: gin.H{"nickname": "rainy day"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().PatchAccount(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeAccountNotFound)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPatch, tc.url, bytes.NewReader(data))
+			require.NoError(t, err)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestFreezeAccountReasons(t *testing.T) {
+	account := randomAccount()
+
+	for _, reason := range util.DefaultFreezeReasons() {
+		reason := reason
+
+		t.Run(reason, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			arg := db.PatchAccountParams{ID: account.ID, SetFrozen: true, Frozen: true, FreezeReason: reason}
+			store.EXPECT().PatchAccount(gomock.Any(), gomock.Eq(arg)).Times(1).Return(account, nil)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"frozen": true, "freeze_reason": reason})
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/admin/accounts/%d", account.ID)
+			request, err := http.NewRequest(http.MethodPatch, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusOK, recorder.Code)
+		})
+	}
+}
@@ -9,8 +9,12 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strconv"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
 	mockdb "github.com/khuongkd/simplebank/db/mock"
 	db "github.com/khuongkd/simplebank/db/sqlc"
@@ -52,6 +56,7 @@ func TestGetAccountAPI(t *testing.T) {
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeAccountNotFound)
 			},
 		},
 		{
@@ -64,6 +69,8 @@ func TestGetAccountAPI(t *testing.T) {
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				rsp := requireErrorCode(t, recorder, ErrCodeInvalidRequest)
+				require.Equal(t, []fieldError{{Field: "id", Rule: "required"}}, rsp.Details)
 			},
 		},
 		{
@@ -77,6 +84,7 @@ func TestGetAccountAPI(t *testing.T) {
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeInternal)
 			},
 		},
 	}
@@ -90,7 +98,7 @@ func TestGetAccountAPI(t *testing.T) {
 
 			store := mockdb.NewMockStore(ctr)
 			tc.buildStubs(store)
-			server := NewServer(store)
+			server := newTestServer(t, store)
 			recorder := httptest.NewRecorder()
 
 			url := fmt.Sprintf("/account/%d", tc.accountID)
@@ -103,6 +111,107 @@ func TestGetAccountAPI(t *testing.T) {
 	}
 }
 
+func TestGetAccountByNumberAPI(t *testing.T) {
+	account := randomAccount()
+	config := util.Config{AccountNumberPrefix: "SB"}
+	accountNumber := util.FormatAccountNumber(config.AccountNumberPrefix, account.Number)
+	arg := db.GetAccountByNumberAndCurrencyParams{
+		Number:   account.Number,
+		Currency: account.Currency,
+	}
+
+	testCases := []struct {
+		name          string
+		accountNumber string
+		currency      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:          "OK",
+			accountNumber: accountNumber,
+			currency:      account.Currency,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccountByNumberAndCurrency(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var gotAccount accountResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &gotAccount)
+				require.NoError(t, err)
+				require.Equal(t, accountNumber, gotAccount.AccountNumber)
+				requireBodyMatchAccount(t, recorder.Body, account)
+			},
+		},
+		{
+			name:          "NotFound",
+			accountNumber: accountNumber,
+			currency:      account.Currency,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccountByNumberAndCurrency(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.Account{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:          "WrongPrefix",
+			accountNumber: util.FormatAccountNumber("XX", account.Number),
+			currency:      account.Currency,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccountByNumberAndCurrency(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeInvalidAccountNumber)
+			},
+		},
+		{
+			name:          "MissingCurrency",
+			accountNumber: accountNumber,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccountByNumberAndCurrency(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeInvalidRequest)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			server := NewServer(config, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/by-number/%s?currency=%s", tc.accountNumber, tc.currency)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
 func TestCreateAccount(t *testing.T) {
 	account := randomAccount()
 
@@ -129,6 +238,45 @@ func TestCreateAccount(t *testing.T) {
 				requireBodyMatchAccount(t, recorder.Body, account)
 			},
 		},
+		{
+			name: "Savings",
+			req: db.CreateAcountParams{
+				Owner:       account.Owner,
+				Currency:    account.Currency,
+				Balance:     0,
+				AccountType: db.AccountTypeSavings,
+			},
+			buildStubs: func(store *mockdb.MockStore, params db.CreateAcountParams) {
+				store.EXPECT().
+					CreateAcount(gomock.Any(), params).
+					Times(1).
+					Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidAccountType",
+			req: db.CreateAcountParams{
+				Owner:       account.Owner,
+				Currency:    account.Currency,
+				Balance:     0,
+				AccountType: "brokerage",
+			},
+			buildStubs: func(store *mockdb.MockStore, params db.CreateAcountParams) {
+				store.EXPECT().
+					CreateAcount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				rsp := requireErrorCode(t, recorder, ErrCodeInvalidRequest)
+				require.Equal(t, []fieldError{
+					{Field: "account_type", Rule: "oneof", Param: "checking savings"},
+				}, rsp.Details)
+			},
+		},
 		{
 			name: "InternalError",
 			req: db.CreateAcountParams{
@@ -159,6 +307,30 @@ func TestCreateAccount(t *testing.T) {
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				rsp := requireErrorCode(t, recorder, ErrCodeInvalidRequest)
+				require.Equal(t, []fieldError{
+					{Field: "owner", Rule: "required"},
+				}, rsp.Details)
+			},
+		},
+		{
+			name: "UnsupportedCurrency",
+			req: db.CreateAcountParams{
+				Owner:    account.Owner,
+				Currency: "xyz",
+				Balance:  0,
+			},
+			buildStubs: func(store *mockdb.MockStore, params db.CreateAcountParams) {
+				store.EXPECT().
+					CreateAcount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				rsp := requireErrorCode(t, recorder, ErrCodeInvalidRequest)
+				require.Equal(t, []fieldError{
+					{Field: "currency", Rule: "oneof", Param: strings.Join(util.KnownCurrencies(), " ")},
+				}, rsp.Details)
 			},
 		},
 	}
@@ -169,12 +341,13 @@ func TestCreateAccount(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			store := mockdb.NewMockStore(ctrl)
 
-			server := NewServer(store)
+			server := newTestServer(t, store)
 			tc.buildStubs(store, tc.req)
 			recorder := httptest.NewRecorder()
 			params := createAccountRequest{
-				Owner:    tc.req.Owner,
-				Currency: tc.req.Currency,
+				Owner:       tc.req.Owner,
+				Currency:    tc.req.Currency,
+				AccountType: tc.req.AccountType,
 			}
 			var buf bytes.Buffer
 			err := json.NewEncoder(&buf).Encode(params)
@@ -188,6 +361,75 @@ func TestCreateAccount(t *testing.T) {
 	}
 }
 
+func TestCreateAccountOwnerPolicy(t *testing.T) {
+	config := util.Config{OwnerMinLength: 3, OwnerMaxLength: 10, OwnerAllowedSymbols: "._-"}
+
+	testCases := []struct {
+		name    string
+		owner   string
+		details []fieldError
+	}{
+		{
+			name:  "OK",
+			owner: "jane.doe-1",
+		},
+		{
+			name:    "TooShort",
+			owner:   "jd",
+			details: []fieldError{{Field: "owner", Rule: "min_length", Param: "3"}},
+		},
+		{
+			name:    "TooLong",
+			owner:   "jane.doe.smith",
+			details: []fieldError{{Field: "owner", Rule: "max_length", Param: "10"}},
+		},
+		{
+			name:    "DisallowedCharacter",
+			owner:   "jane@doe",
+			details: []fieldError{{Field: "owner", Rule: "characters", Param: "._-"}},
+		},
+		{
+			name:  "SurroundingWhitespace",
+			owner: " jane ",
+			details: []fieldError{
+				{Field: "owner", Rule: "characters", Param: "._-"},
+				{Field: "owner", Rule: "whitespace"},
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			if tc.details == nil {
+				account := randomAccount()
+				account.Owner = tc.owner
+				store.EXPECT().CreateAcount(gomock.Any(), gomock.Any()).Times(1).Return(account, nil)
+			} else {
+				store.EXPECT().CreateAcount(gomock.Any(), gomock.Any()).Times(0)
+			}
+
+			server := NewServer(config, store)
+			recorder := httptest.NewRecorder()
+			request := newPostRequest(t, "/accounts", gin.H{"owner": tc.owner, "currency": "USD"})
+			server.router.ServeHTTP(recorder, request)
+
+			if tc.details == nil {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				return
+			}
+			require.Equal(t, http.StatusBadRequest, recorder.Code)
+			rsp := requireErrorCode(t, recorder, ErrCodeInvalidRequest)
+			require.Equal(t, tc.details, rsp.Details)
+		})
+	}
+}
+
 func TestListAccounts(t *testing.T) {
 	listAccount := []db.Account{}
 	n := 10
@@ -264,7 +506,7 @@ func TestListAccounts(t *testing.T) {
 	for _, tc := range testCases {
 		ctrl := gomock.NewController(t)
 		store := mockdb.NewMockStore(ctrl)
-		server := NewServer(store)
+		server := newTestServer(t, store)
 		listAccountsParams := db.ListAccountsParams{
 			Limit:  tc.pageSize,
 			Offset: (tc.pageID - 1) * tc.pageSize,
@@ -284,12 +526,179 @@ func TestListAccounts(t *testing.T) {
 	}
 }
 
+func TestListAccountsDefaultPageSize(t *testing.T) {
+	testCases := []struct {
+		name     string
+		query    string
+		config   util.Config
+		expected db.ListAccountsParams
+	}{
+		{
+			name:     "OmittedUsesDefault",
+			query:    "page_id=2",
+			config:   util.Config{DefaultPageSize: 7},
+			expected: db.ListAccountsParams{Limit: 7, Offset: 7},
+		},
+		{
+			name:     "ExplicitOverridesDefault",
+			query:    "page_id=2&page_size=10",
+			config:   util.Config{DefaultPageSize: 7},
+			expected: db.ListAccountsParams{Limit: 10, Offset: 10},
+		},
+		{
+			name:     "OmittedWithoutDefault",
+			query:    "page_id=1",
+			expected: db.ListAccountsParams{Limit: fallbackPageSize, Offset: 0},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().
+				ListAccounts(gomock.Any(), gomock.Eq(tc.expected)).
+				Times(1).
+				Return([]db.Account{}, nil)
+
+			server := NewServer(tc.config, store)
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, "/accounts?"+tc.query, nil)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusOK, recorder.Code)
+		})
+	}
+}
+
+func TestListAccountsResponseFormat(t *testing.T) {
+	accounts := []db.Account{randomAccount(), randomAccount()}
+
+	requireBare := func(t *testing.T, recorder *httptest.ResponseRecorder) {
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		var rsp []accountResponse
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+		require.Len(t, rsp, len(accounts))
+		require.Equal(t, util.FormatAccountNumber("", accounts[0].Number), rsp[0].AccountNumber)
+	}
+	requireEnvelope := func(t *testing.T, recorder *httptest.ResponseRecorder) {
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		var rsp struct {
+			Data []accountResponse `json:"data"`
+			Meta listMeta          `json:"meta"`
+		}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+		require.Len(t, rsp.Data, len(accounts))
+		require.Equal(t, util.FormatAccountNumber("", accounts[0].Number), rsp.Data[0].AccountNumber)
+		require.Equal(t, listMeta{PageID: 2, PageSize: 5, Count: len(accounts)}, rsp.Meta)
+	}
+
+	testCases := []struct {
+		name          string
+		query         string
+		config        util.Config
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:          "DefaultsToBare",
+			query:         "page_id=2&page_size=5",
+			checkResponse: requireBare,
+		},
+		{
+			name:          "ConfiguredEnvelope",
+			query:         "page_id=2&page_size=5",
+			config:        util.Config{ResponseFormat: util.ResponseFormatEnvelope},
+			checkResponse: requireEnvelope,
+		},
+		{
+			name:          "QueryEnvelope",
+			query:         "page_id=2&page_size=5&format=envelope",
+			checkResponse: requireEnvelope,
+		},
+		{
+			name:          "QueryOverridesConfig",
+			query:         "page_id=2&page_size=5&format=bare",
+			config:        util.Config{ResponseFormat: util.ResponseFormatEnvelope},
+			checkResponse: requireBare,
+		},
+		{
+			name:  "EnvelopeUsesDefaultPageSize",
+			query: "page_id=2&format=envelope",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Body.String(), fmt.Sprintf(`"page_size":%d`, fallbackPageSize))
+			},
+		},
+		{
+			name:  "InvalidFormat",
+			query: "page_id=2&format=xml",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeInvalidRequest)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().
+				ListAccounts(gomock.Any(), gomock.Any()).
+				AnyTimes().
+				Return(accounts, nil)
+
+			server := NewServer(tc.config, store)
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, "/accounts?"+tc.query, nil)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListAccountsMissingPageID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ListAccounts(gomock.Any(), gomock.Any()).Times(0)
+
+	server := NewServer(util.Config{DefaultPageSize: 7}, store)
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/accounts", nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+	requireErrorCode(t, recorder, ErrCodeInvalidRequest)
+}
+
+// lastRandomAccountID keeps the IDs of random accounts apart, since a transfer from an account to itself is rejected
+var lastRandomAccountID int64
+
 func randomAccount() db.Account {
+	currency := util.RandomCurrency()
 	return db.Account{
-		ID:       util.RandomInt(1, 1000),
+		ID:       atomic.AddInt64(&lastRandomAccountID, util.RandomInt(1, 1000)),
 		Owner:    util.RandomOwner(),
-		Balance:  util.RandomMoney(),
-		Currency: util.RandomCurrency(),
+		Balance:  util.RandomMoneyForCurrency(currency),
+		Currency: currency,
+		Number:   util.RandomInt(1, 1000),
+		Metadata: json.RawMessage(`{}`),
 	}
 }
 
@@ -300,6 +709,10 @@ func requireBodyMatchAccount(t *testing.T, body *bytes.Buffer, account db.Accoun
 	var gotAccount db.Account
 	err = json.Unmarshal(data, &gotAccount)
 	require.NoError(t, err)
+
+	// the raw account number is only exposed formatted, as account_number
+	require.NotContains(t, string(data), `"number"`)
+	account.Number = 0
 	require.Equal(t, account, gotAccount)
 }
 
@@ -315,3 +728,100 @@ func requireBodyMatchListAccount(t *testing.T, body *bytes.Buffer, accounts []db
 		require.NotEmpty(t, account)
 	}
 }
+
+func TestCreateAccountNumberPerCurrency(t *testing.T) {
+	account := randomAccount()
+	config := util.Config{AccountNumberPrefix: "SB", AccountNumberPerCurrency: true}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		CreateAcount(gomock.Any(), gomock.Eq(db.CreateAcountParams{
+			Owner:             account.Owner,
+			Currency:          account.Currency,
+			NumberPerCurrency: true,
+		})).
+		Times(1).
+		Return(account, nil)
+
+	server := NewServer(config, store)
+	recorder := httptest.NewRecorder()
+	request := newPostRequest(t, "/accounts", gin.H{
+		"owner":    account.Owner,
+		"currency": account.Currency,
+	})
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var gotAccount accountResponse
+	err := json.Unmarshal(recorder.Body.Bytes(), &gotAccount)
+	require.NoError(t, err)
+	require.Equal(t, util.FormatAccountNumber("SB", account.Number), gotAccount.AccountNumber)
+}
+
+func TestCreateAccountLimit(t *testing.T) {
+	account := randomAccount()
+	window := time.Hour
+
+	testCases := []struct {
+		name   string
+		limit  int
+		count  int64
+		status int
+	}{
+		{name: "UnderLimit", limit: 3, count: 2, status: http.StatusOK},
+		{name: "AtLimit", limit: 3, count: 3, status: http.StatusTooManyRequests},
+		{name: "OverLimit", limit: 3, count: 7, status: http.StatusTooManyRequests},
+		{name: "NoLimit", limit: 0, status: http.StatusOK},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			countCalls := 1
+			if tc.limit == 0 {
+				countCalls = 0
+			}
+			createCalls := 0
+			if tc.status == http.StatusOK {
+				createCalls = 1
+			}
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().
+				CountAccountsByOwnerSince(gomock.Any(), gomock.Any()).
+				Times(countCalls).
+				DoAndReturn(func(_ interface{}, arg db.CountAccountsByOwnerSinceParams) (int64, error) {
+					require.Equal(t, account.Owner, arg.Owner)
+					require.WithinDuration(t, time.Now().Add(-window), arg.Since, time.Second)
+					return tc.count, nil
+				})
+			store.EXPECT().
+				CreateAcount(gomock.Any(), gomock.Any()).
+				Times(createCalls).
+				Return(account, nil)
+
+			config := util.Config{AccountCreationLimit: tc.limit, AccountCreationWindow: window}
+			server := NewServer(config, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(createAccountRequest{Owner: account.Owner, Currency: account.Currency})
+			require.NoError(t, err)
+			request, err := http.NewRequest(http.MethodPost, "/accounts", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, tc.status, recorder.Code)
+			if tc.status == http.StatusTooManyRequests {
+				requireErrorCode(t, recorder, ErrCodeAccountLimit)
+				require.Equal(t, "1", recorder.Header().Get("Retry-After"))
+			}
+		})
+	}
+}
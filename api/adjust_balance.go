@@ -0,0 +1,108 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+)
+
+type adjustBalanceURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type adjustBalanceRequest struct {
+	// Amount is credited when positive and debited when negative
+	Amount int64  `json:"amount" binding:"required"`
+	Reason string `json:"reason" binding:"required"`
+	// AdjustedBy names the banker making the correction, for the audit trail
+	AdjustedBy string `json:"adjusted_by" binding:"required"`
+}
+
+type adjustBalanceResponse struct {
+	Account    accountResponse      `json:"account"`
+	Entry      db.Entry             `json:"entry"`
+	Adjustment db.AccountAdjustment `json:"adjustment"`
+}
+
+// adjustAccountBalance godoc
+// @Summary  Correct an account's balance
+// @Description  Credits or debits the account with an entry recording the reason. The balance never goes negative.
+// @Tags     admin
+// @Accept   json
+// @Produce  json
+// @Param    id       path      int                   true  "Account ID"
+// @Param    request  body      adjustBalanceRequest  true  "Signed amount and the reason for it"
+// @Success  200      {object}  adjustBalanceResponse
+// @Failure  400      {object}  apiError
+// @Failure  404      {object}  apiError
+// @Failure  500      {object}  apiError
+// @Router   /admin/accounts/{id}/adjust [post]
+func (server *Server) adjustAccountBalance(ctx *gin.Context) {
+	var uri adjustBalanceURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	var req adjustBalanceRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	result, err := server.store.AdjustAccountBalanceTx(ctx.Request.Context(), db.AdjustAccountBalanceTxParams{
+		AccountID:  uri.ID,
+		Amount:     req.Amount,
+		Reason:     req.Reason,
+		AdjustedBy: req.AdjustedBy,
+	})
+	if err != nil {
+		switch {
+		case err == sql.ErrNoRows:
+			ctx.JSON(http.StatusNotFound, errorResponse(errAccountNotFound))
+		case errors.Is(err, db.ErrInsufficientFunds),
+			errors.Is(err, db.ErrConstraintViolation):
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		default:
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, adjustBalanceResponse{
+		Account:    server.newAccountResponse(result.Account),
+		Entry:      result.Entry,
+		Adjustment: result.Adjustment,
+	})
+}
+
+// listAccountAdjustments godoc
+// @Summary  List the balance corrections of an account
+// @Tags     admin
+// @Produce  json
+// @Param    id   path      int  true  "Account ID"
+// @Success  200  {array}   db.AccountAdjustment
+// @Failure  400  {object}  apiError
+// @Failure  500  {object}  apiError
+// @Router   /admin/accounts/{id}/adjustments [get]
+func (server *Server) listAccountAdjustments(ctx *gin.Context) {
+	var uri adjustBalanceURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	adjustments, err := server.store.ListAccountAdjustments(ctx.Request.Context(), uri.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if adjustments == nil {
+		adjustments = []db.AccountAdjustment{}
+	}
+
+	ctx.JSON(http.StatusOK, adjustments)
+}
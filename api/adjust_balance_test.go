@@ -0,0 +1,150 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khuongkd/simplebank/db/mock"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdjustAccountBalanceAPI(t *testing.T) {
+	account := randomAccount()
+
+	adjustResult := func(amount int64) func(context.Context, db.AdjustAccountBalanceTxParams) (db.AdjustAccountBalanceTxResult, error) {
+		return func(_ context.Context, params db.AdjustAccountBalanceTxParams) (db.AdjustAccountBalanceTxResult, error) {
+			require.Equal(t, db.AdjustAccountBalanceTxParams{
+				AccountID:  account.ID,
+				Amount:     amount,
+				Reason:     "bank error",
+				AdjustedBy: "banker",
+			}, params)
+
+			adjusted := account
+			adjusted.Balance += amount
+			entry := db.Entry{ID: 1, AccountID: account.ID, Amount: amount}
+			return db.AdjustAccountBalanceTxResult{
+				Account: adjusted,
+				Entry:   entry,
+				Adjustment: db.AccountAdjustment{
+					ID:         1,
+					AccountID:  account.ID,
+					EntryID:    entry.ID,
+					Amount:     amount,
+					Reason:     params.Reason,
+					AdjustedBy: params.AdjustedBy,
+				},
+			}, nil
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Credit",
+			body: gin.H{"amount": 50, "reason": "bank error", "adjusted_by": "banker"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AdjustAccountBalanceTx(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(adjustResult(50))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp adjustBalanceResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, account.Balance+50, rsp.Account.Balance)
+				require.Equal(t, int64(50), rsp.Entry.Amount)
+				require.Equal(t, "bank error", rsp.Adjustment.Reason)
+			},
+		},
+		{
+			name: "Debit",
+			body: gin.H{"amount": -50, "reason": "bank error", "adjusted_by": "banker"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AdjustAccountBalanceTx(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(adjustResult(-50))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp adjustBalanceResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, account.Balance-50, rsp.Account.Balance)
+				require.Equal(t, int64(-50), rsp.Entry.Amount)
+			},
+		},
+		{
+			name: "NegativeBalance",
+			body: gin.H{"amount": -(account.Balance + 1), "reason": "bank error", "adjusted_by": "banker"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AdjustAccountBalanceTx(gomock.Any(), gomock.Any()).Times(1).Return(db.AdjustAccountBalanceTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeInsufficientFunds)
+			},
+		},
+		{
+			name: "MissingReason",
+			body: gin.H{"amount": 50, "adjusted_by": "banker"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AdjustAccountBalanceTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeInvalidRequest)
+			},
+		},
+		{
+			name: "ZeroAmount",
+			body: gin.H{"amount": 0, "reason": "bank error", "adjusted_by": "banker"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AdjustAccountBalanceTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeInvalidRequest)
+			},
+		},
+		{
+			name: "NotFound",
+			body: gin.H{"amount": 50, "reason": "bank error", "adjusted_by": "banker"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AdjustAccountBalanceTx(gomock.Any(), gomock.Any()).Times(1).Return(db.AdjustAccountBalanceTxResult{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeAccountNotFound)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/admin/accounts/%d/adjust", account.ID)
+			server.router.ServeHTTP(recorder, newPostRequest(t, url, tc.body))
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
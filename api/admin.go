@@ -0,0 +1,142 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+)
+
+// dbStatsResponse reports the health of the database connection pool
+type dbStatsResponse struct {
+	MaxOpenConnections int   `json:"max_open_connections"`
+	OpenConnections    int   `json:"open_connections"`
+	InUse              int   `json:"in_use"`
+	Idle               int   `json:"idle"`
+	WaitCount          int64 `json:"wait_count"`
+	WaitDurationMillis int64 `json:"wait_duration_ms"`
+	MaxIdleClosed      int64 `json:"max_idle_closed"`
+	MaxIdleTimeClosed  int64 `json:"max_idle_time_closed"`
+	MaxLifetimeClosed  int64 `json:"max_lifetime_closed"`
+	// Deadlocks counts the transactions aborted to break a deadlock since the server started
+	Deadlocks int64 `json:"deadlocks"`
+}
+
+// getDBStats godoc
+// @Summary  Get database connection pool statistics
+// @Tags     admin
+// @Produce  json
+// @Success  200  {object}  dbStatsResponse
+// @Router   /admin/db-stats [get]
+func (server *Server) getDBStats(ctx *gin.Context) {
+	stats := server.store.Stats()
+
+	ctx.JSON(http.StatusOK, dbStatsResponse{
+		MaxOpenConnections: stats.MaxOpenConnections,
+		OpenConnections:    stats.OpenConnections,
+		InUse:              stats.InUse,
+		Idle:               stats.Idle,
+		WaitCount:          stats.WaitCount,
+		WaitDurationMillis: stats.WaitDuration.Milliseconds(),
+		MaxIdleClosed:      stats.MaxIdleClosed,
+		MaxIdleTimeClosed:  stats.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+		Deadlocks:          db.DeadlockCount.Value(),
+	})
+}
+
+type reconcileAccountRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// reconcileAccount godoc
+// @Summary  Compare an account balance with the sum of its entries
+// @Tags     admin
+// @Produce  json
+// @Param    id   path      int  true  "Account ID"
+// @Success  200  {object}  db.AccountReconciliation
+// @Failure  400  {object}  apiError
+// @Failure  404  {object}  apiError
+// @Failure  500  {object}  apiError
+// @Router   /admin/accounts/{id}/reconcile [get]
+func (server *Server) reconcileAccount(ctx *gin.Context) {
+	var req reconcileAccountRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	result, err := server.store.ReconcileAccount(ctx.Request.Context(), req.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(errAccountNotFound))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+type listBalanceDiscrepanciesRequest struct {
+	pageRequest
+}
+
+// listBalanceDiscrepancies godoc
+// @Summary  List the accounts whose balance differs from the sum of their entries
+// @Description  Checks every account in one query, for audits that would otherwise reconcile the accounts one at a time.
+// @Tags     admin
+// @Produce  json
+// @Param    page_id    query     int     true   "Page number, starting at 1"
+// @Param    page_size  query     int     false  "Page size, between 5 and 10; defaults to the configured page size"
+// @Param    format     query     string  false  "Response format, bare or envelope; defaults to the configured format"
+// @Success  200        {array}   db.FindBalanceDiscrepanciesRow
+// @Failure  400        {object}  apiError
+// @Failure  500        {object}  apiError
+// @Router   /admin/reconcile/discrepancies [get]
+func (server *Server) listBalanceDiscrepancies(ctx *gin.Context) {
+	var req listBalanceDiscrepanciesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	limit, offset := server.page(req.pageRequest)
+	discrepancies, err := server.store.FindBalanceDiscrepancies(ctx.Request.Context(), db.FindBalanceDiscrepanciesParams{
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if discrepancies == nil {
+		discrepancies = []db.FindBalanceDiscrepanciesRow{}
+	}
+	server.writeList(ctx, req.pageRequest, discrepancies)
+}
+
+// getTreasury godoc
+// @Summary  Get the total money held in each currency
+// @Description  Sums every account balance per currency and reconciles it against the sum of the entries, archived ones included.
+// @Tags     admin
+// @Produce  json
+// @Success  200  {array}   db.TotalSystemBalanceRow
+// @Failure  500  {object}  apiError
+// @Router   /admin/treasury [get]
+func (server *Server) getTreasury(ctx *gin.Context) {
+	totals, err := server.store.TotalSystemBalance(ctx.Request.Context())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if totals == nil {
+		totals = []db.TotalSystemBalanceRow{}
+	}
+	ctx.JSON(http.StatusOK, totals)
+}
@@ -0,0 +1,295 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khuongkd/simplebank/db/mock"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetDBStatsAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().Stats().Times(1).Return(sql.DBStats{
+		MaxOpenConnections: 10,
+		OpenConnections:    4,
+		InUse:              3,
+		Idle:               1,
+		WaitCount:          7,
+		WaitDuration:       1500 * time.Millisecond,
+	})
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/admin/db-stats", nil)
+	require.NoError(t, err)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var stats map[string]int64
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &stats))
+	require.Equal(t, map[string]int64{
+		"max_open_connections": 10,
+		"open_connections":     4,
+		"in_use":               3,
+		"idle":                 1,
+		"wait_count":           7,
+		"wait_duration_ms":     1500,
+		"max_idle_closed":      0,
+		"max_idle_time_closed": 0,
+		"max_lifetime_closed":  0,
+		"deadlocks":            db.DeadlockCount.Value(),
+	}, stats)
+}
+
+func TestReconcileAccountAPI(t *testing.T) {
+	account := randomAccount()
+
+	testCases := []struct {
+		name          string
+		accountID     int64
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "Consistent",
+			accountID: account.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReconcileAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(db.AccountReconciliation{AccountID: account.ID, Balance: 40, EntriesTotal: 40}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp db.AccountReconciliation
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Zero(t, rsp.Discrepancy)
+			},
+		},
+		{
+			name:      "Drifted",
+			accountID: account.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReconcileAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(db.AccountReconciliation{AccountID: account.ID, Balance: 55, EntriesTotal: 40, Discrepancy: 15}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp map[string]int64
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, int64(15), rsp["discrepancy"])
+			},
+		},
+		{
+			name:      "NotFound",
+			accountID: account.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ReconcileAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.AccountReconciliation{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeAccountNotFound)
+			},
+		},
+		{
+			name:      "InvalidID",
+			accountID: 0,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ReconcileAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeInvalidRequest)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/admin/accounts/%d/reconcile", tc.accountID)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListBalanceDiscrepanciesAPI(t *testing.T) {
+	account := randomAccount()
+	discrepancy := db.FindBalanceDiscrepanciesRow{AccountID: account.ID, Balance: 55, EntriesTotal: 40, Discrepancy: 15}
+
+	testCases := []struct {
+		name          string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: "page_id=2&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.FindBalanceDiscrepanciesParams{Limit: 5, Offset: 5}
+				store.EXPECT().
+					FindBalanceDiscrepancies(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return([]db.FindBalanceDiscrepanciesRow{discrepancy}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp []db.FindBalanceDiscrepanciesRow
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, []db.FindBalanceDiscrepanciesRow{discrepancy}, rsp)
+			},
+		},
+		{
+			name:  "NoDiscrepancy",
+			query: "page_id=1",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().FindBalanceDiscrepancies(gomock.Any(), gomock.Any()).Times(1).Return(nil, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.JSONEq(t, "[]", recorder.Body.String())
+			},
+		},
+		{
+			name:  "InternalError",
+			query: "page_id=1",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().FindBalanceDiscrepancies(gomock.Any(), gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:  "MissingPage",
+			query: "",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().FindBalanceDiscrepancies(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeInvalidRequest)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/admin/reconcile/discrepancies?"+tc.query, nil)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestGetTreasuryAPI(t *testing.T) {
+	totals := []db.TotalSystemBalanceRow{
+		{Currency: "EUR", Accounts: 1, Balance: 70, EntriesTotal: 70},
+		{Currency: "USD", Accounts: 2, Balance: 160, EntriesTotal: 150, Discrepancy: 10},
+	}
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().TotalSystemBalance(gomock.Any()).Times(1).Return(totals, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp []db.TotalSystemBalanceRow
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, totals, rsp)
+			},
+		},
+		{
+			name: "NoAccounts",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().TotalSystemBalance(gomock.Any()).Times(1).Return(nil, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.JSONEq(t, "[]", recorder.Body.String())
+			},
+		},
+		{
+			name: "InternalError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().TotalSystemBalance(gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/admin/treasury", nil)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+)
+
+// auditLogResponse is an audit log record as exposed to clients.
+// It spells out the fields of db.AccountAuditLog so that the account snapshots can be documented as JSON objects.
+type auditLogResponse struct {
+	ID        int64           `json:"id"`
+	AccountID int64           `json:"account_id"`
+	Action    string          `json:"action"`
+	Before    json.RawMessage `json:"before" swaggertype:"object"`
+	After     json.RawMessage `json:"after" swaggertype:"object"`
+	Actor     string          `json:"actor"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+func newAuditLogResponse(record db.AccountAuditLog) auditLogResponse {
+	return auditLogResponse{
+		ID:        record.ID,
+		AccountID: record.AccountID,
+		Action:    record.Action,
+		Before:    record.Before,
+		After:     record.After,
+		Actor:     record.Actor,
+		CreatedAt: record.CreatedAt,
+	}
+}
+
+type listAccountAuditLogURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type listAccountAuditLogRequest struct {
+	pageRequest
+}
+
+// listAccountAuditLog godoc
+// @Summary  List the changes made to an account, oldest first
+// @Description  Every insert, update and delete of the account row is recorded with the row before and after it.
+// @Description  The log outlives the account, so a deleted account still lists its changes.
+// @Tags     admin
+// @Produce  json
+// @Param    id         path      int     true   "Account ID"
+// @Param    page_id    query     int     true   "Page number, starting at 1"
+// @Param    page_size  query     int     false  "Page size, between 5 and 10; defaults to the configured page size"
+// @Param    format     query     string  false  "Response format, bare or envelope; defaults to the configured format"
+// @Success  200        {array}   auditLogResponse
+// @Failure  400        {object}  apiError
+// @Failure  500        {object}  apiError
+// @Router   /admin/accounts/{id}/audit-log [get]
+func (server *Server) listAccountAuditLog(ctx *gin.Context) {
+	var uri listAccountAuditLogURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	var req listAccountAuditLogRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	limit, offset := server.page(req.pageRequest)
+	records, err := server.store.ListAccountAuditLog(ctx.Request.Context(), db.ListAccountAuditLogParams{
+		AccountID: uri.ID,
+		Limit:     limit,
+		Offset:    offset,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := make([]auditLogResponse, len(records))
+	for i, record := range records {
+		rsp[i] = newAuditLogResponse(record)
+	}
+	server.writeList(ctx, req.pageRequest, rsp)
+}
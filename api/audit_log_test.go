@@ -0,0 +1,117 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khuongkd/simplebank/db/mock"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListAccountAuditLogAPI(t *testing.T) {
+	account := randomAccount()
+	updated := account
+	updated.Balance += 10
+	before, err := json.Marshal(account)
+	require.NoError(t, err)
+	after, err := json.Marshal(updated)
+	require.NoError(t, err)
+
+	record := db.AccountAuditLog{
+		ID:        1,
+		AccountID: account.ID,
+		Action:    "update",
+		Before:    before,
+		After:     after,
+		Actor:     "scheduler",
+	}
+
+	testCases := []struct {
+		name          string
+		accountID     int64
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			accountID: account.ID,
+			query:     "?page_id=2&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAccountAuditLog(gomock.Any(), gomock.Eq(db.ListAccountAuditLogParams{
+						AccountID: account.ID,
+						Limit:     5,
+						Offset:    5,
+					})).
+					Times(1).
+					Return([]db.AccountAuditLog{record}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp []struct {
+					Action string     `json:"action"`
+					Actor  string     `json:"actor"`
+					Before db.Account `json:"before"`
+					After  db.Account `json:"after"`
+				}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Len(t, rsp, 1)
+				require.Equal(t, "update", rsp[0].Action)
+				require.Equal(t, "scheduler", rsp[0].Actor)
+				require.Equal(t, account.Balance, rsp[0].Before.Balance)
+				require.Equal(t, updated.Balance, rsp[0].After.Balance)
+			},
+		},
+		{
+			name:      "InvalidID",
+			accountID: 0,
+			query:     "?page_id=1",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountAuditLog(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeInvalidRequest)
+			},
+		},
+		{
+			name:      "MissingPage",
+			accountID: account.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountAuditLog(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeInvalidRequest)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			target := fmt.Sprintf("/admin/accounts/%d/audit-log%s", tc.accountID, tc.query)
+			request, err := http.NewRequest(http.MethodGet, target, nil)
+			require.NoError(t, err)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
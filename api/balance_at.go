@@ -0,0 +1,67 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type getBalanceAtURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type getBalanceAtRequest struct {
+	Time time.Time `form:"time" binding:"required" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+type balanceAtResponse struct {
+	AccountID int64     `json:"account_id"`
+	Balance   int64     `json:"balance"`
+	AsOf      time.Time `json:"as_of"`
+}
+
+// getBalanceAt godoc
+// @Summary  Get the balance an account had at a point in time
+// @Description  The balance is reconstructed from the account entries. It is zero before the account was created
+// @Description  and the current balance for times in the future.
+// @Tags     accounts
+// @Produce  json
+// @Param    id    path      int     true  "Account ID"
+// @Param    time  query     string  true  "RFC 3339 time, such as 2022-01-01T00:00:00Z"
+// @Success  200   {object}  balanceAtResponse
+// @Failure  400   {object}  apiError
+// @Failure  404   {object}  apiError
+// @Failure  500   {object}  apiError
+// @Router   /accounts/{id}/balance-at [get]
+func (server *Server) getBalanceAt(ctx *gin.Context) {
+	var uri getBalanceAtURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	var req getBalanceAtRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	balance, err := server.store.AccountBalanceAsOf(ctx.Request.Context(), uri.ID, req.Time)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(errAccountNotFound))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, balanceAtResponse{
+		AccountID: uri.ID,
+		Balance:   balance,
+		AsOf:      req.Time,
+	})
+}
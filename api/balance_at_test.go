@@ -0,0 +1,123 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khuongkd/simplebank/db/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetBalanceAtAPI(t *testing.T) {
+	account := randomAccount()
+	at := time.Date(2022, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name          string
+		accountID     int64
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			accountID: account.ID,
+			query:     "?time=2022-03-01T12:00:00Z",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AccountBalanceAsOf(gomock.Any(), gomock.Eq(account.ID), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, _ int64, asOf time.Time) (int64, error) {
+						require.True(t, at.Equal(asOf))
+						return 70, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp balanceAtResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, account.ID, rsp.AccountID)
+				require.Equal(t, int64(70), rsp.Balance)
+				require.True(t, at.Equal(rsp.AsOf))
+			},
+		},
+		{
+			name:      "NotFound",
+			accountID: account.ID,
+			query:     "?time=2022-03-01T12:00:00Z",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AccountBalanceAsOf(gomock.Any(), gomock.Eq(account.ID), gomock.Any()).
+					Times(1).
+					Return(int64(0), sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeAccountNotFound)
+			},
+		},
+		{
+			name:      "MissingTime",
+			accountID: account.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AccountBalanceAsOf(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeInvalidRequest)
+			},
+		},
+		{
+			name:      "InvalidTime",
+			accountID: account.ID,
+			query:     "?time=yesterday",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AccountBalanceAsOf(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeInvalidRequest)
+			},
+		},
+		{
+			name:      "InvalidID",
+			accountID: 0,
+			query:     "?time=2022-03-01T12:00:00Z",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AccountBalanceAsOf(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeInvalidRequest)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			target := fmt.Sprintf("/accounts/%d/balance-at%s", tc.accountID, tc.query)
+			request, err := http.NewRequest(http.MethodGet, target, nil)
+			require.NoError(t, err)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
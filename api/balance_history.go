@@ -0,0 +1,67 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+)
+
+type getBalanceHistoryURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type getBalanceHistoryRequest struct {
+	pageRequest
+}
+
+// getBalanceHistory godoc
+// @Summary  List the entries of an account, oldest first, each with the balance it left
+// @Description  Archived entries are included. The running balance is computed by the database over the whole history,
+// @Description  so it is the same on every page.
+// @Tags     accounts
+// @Produce  json
+// @Param    id         path      int     true   "Account ID"
+// @Param    page_id    query     int     true   "Page number, starting at 1"
+// @Param    page_size  query     int     false  "Page size, between 5 and 10; defaults to the configured page size"
+// @Param    format     query     string  false  "Response format, bare or envelope; defaults to the configured format"
+// @Success  200        {array}   db.EntryWithBalance
+// @Failure  400        {object}  apiError
+// @Failure  404        {object}  apiError
+// @Failure  500        {object}  apiError
+// @Router   /accounts/{id}/balance-history [get]
+func (server *Server) getBalanceHistory(ctx *gin.Context) {
+	var uri getBalanceHistoryURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	var req getBalanceHistoryRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	if _, err := server.store.GetAccount(ctx.Request.Context(), uri.ID); err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(errAccountNotFound))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	limit, offset := server.page(req.pageRequest)
+	entries, err := server.store.ListEntriesWithBalance(ctx.Request.Context(), uri.ID, limit, offset)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if entries == nil {
+		entries = []db.EntryWithBalance{}
+	}
+	server.writeList(ctx, req.pageRequest, entries)
+}
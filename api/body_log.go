@@ -0,0 +1,100 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultBodyLogLimit is the largest request body logged when LogRequestBodyLimit is not configured
+const defaultBodyLogLimit = 4096
+
+// redactedValue replaces the value of every sensitive field in a logged body
+const redactedValue = "[REDACTED]"
+
+// sensitiveFields lists the JSON fields, compared case-insensitively, whose values never reach the log
+var sensitiveFields = map[string]bool{
+	"password":        true,
+	"hashed_password": true,
+	"authorization":   true,
+	"token":           true,
+	"access_token":    true,
+	"refresh_token":   true,
+}
+
+// bodyLogMiddleware logs the body of every request when LogRequestBodies is set, with sensitive fields masked.
+// Only JSON bodies that fit within the size limit are logged: a body that cannot be parsed in full
+// cannot be redacted either, so only its size is reported.
+// The handler still reads the complete body.
+func (server *Server) bodyLogMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		config := server.currentConfig()
+		if !config.LogRequestBodies || ctx.Request.Body == nil {
+			ctx.Next()
+			return
+		}
+
+		limit := config.LogRequestBodyLimit
+		if limit <= 0 {
+			limit = defaultBodyLogLimit
+		}
+
+		body := ctx.Request.Body
+		head, err := ioutil.ReadAll(io.LimitReader(body, int64(limit)+1))
+		ctx.Request.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(head), body), body}
+		if err != nil {
+			ctx.Next()
+			return
+		}
+
+		if len(head) > 0 {
+			server.bodyLogger.Printf("%s %s %s body: %s", requestID(ctx), ctx.Request.Method, ctx.Request.URL.Path, redactBody(head, limit))
+		}
+		ctx.Next()
+	}
+}
+
+// redactBody returns the body as it may be logged
+func redactBody(body []byte, limit int) string {
+	if len(body) > limit {
+		return fmt.Sprintf("<body over %d bytes not logged>", limit)
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return fmt.Sprintf("<non-JSON body of %d bytes not logged>", len(body))
+	}
+
+	redacted, err := json.Marshal(redactValue(value))
+	if err != nil {
+		return "<body not logged>"
+	}
+	return string(redacted)
+}
+
+// redactValue masks the sensitive fields of a decoded JSON value, at any depth
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if sensitiveFields[strings.ToLower(key)] {
+				v[key] = redactedValue
+				continue
+			}
+			v[key] = redactValue(field)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+	}
+	return value
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/khuongkd/simplebank/util"
+)
+
+type capabilitiesResponse struct {
+	// FXEnabled reports whether any supported currency may be converted into another one
+	FXEnabled bool `json:"fx_enabled"`
+	// SchedulingEnabled reports whether scheduled transfers are carried out
+	SchedulingEnabled   bool     `json:"scheduling_enabled"`
+	SupportedCurrencies []string `json:"supported_currencies"`
+	// MaxTransferAmount is the largest amount a single transfer may move; zero means no limit
+	MaxTransferAmount int64 `json:"max_transfer_amount"`
+}
+
+// getCapabilities godoc
+// @Summary  Describe what the server supports
+// @Description  The capabilities follow the active config, so they change when it is reloaded.
+// @Tags     capabilities
+// @Produce  json
+// @Success  200  {object}  capabilitiesResponse
+// @Router   /capabilities [get]
+func (server *Server) getCapabilities(ctx *gin.Context) {
+	config := server.currentConfig()
+	currencies := config.Currencies()
+
+	ctx.JSON(http.StatusOK, capabilitiesResponse{
+		FXEnabled:           anyConversionAllowed(currencies, config.CurrencyPairs()),
+		SchedulingEnabled:   config.SchedulerInterval > 0,
+		SupportedCurrencies: currencies,
+		MaxTransferAmount:   config.MaxTransferAmount,
+	})
+}
+
+// anyConversionAllowed reports whether pairs allow converting one of currencies into another
+func anyConversionAllowed(currencies []string, pairs util.CurrencyPairs) bool {
+	for _, from := range currencies {
+		for _, to := range currencies {
+			if from != to && pairs.Allows(from, to) {
+				return true
+			}
+		}
+	}
+	return false
+}
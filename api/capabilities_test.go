@@ -0,0 +1,81 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/khuongkd/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCapabilitiesAPI(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config util.Config
+		want   capabilitiesResponse
+	}{
+		{
+			name: "Configured",
+			config: util.Config{
+				SupportedCurrencies: []string{"USD", "EUR"},
+				FXAllowedPairs:      []string{"USD/EUR"},
+				SchedulerInterval:   time.Minute,
+				MaxTransferAmount:   5000,
+			},
+			want: capabilitiesResponse{
+				FXEnabled:           true,
+				SchedulingEnabled:   true,
+				SupportedCurrencies: []string{"USD", "EUR"},
+				MaxTransferAmount:   5000,
+			},
+		},
+		{
+			name: "NoAllowedPairAmongCurrencies",
+			config: util.Config{
+				SupportedCurrencies: []string{"USD", "EUR"},
+				FXAllowedPairs:      []string{"USD/CAD"},
+			},
+			want: capabilitiesResponse{
+				SupportedCurrencies: []string{"USD", "EUR"},
+			},
+		},
+		{
+			name: "SingleCurrency",
+			config: util.Config{
+				SupportedCurrencies: []string{"USD"},
+			},
+			want: capabilitiesResponse{
+				SupportedCurrencies: []string{"USD"},
+			},
+		},
+		{
+			name:   "Defaults",
+			config: util.Config{},
+			want: capabilitiesResponse{
+				FXEnabled:           true,
+				SupportedCurrencies: util.KnownCurrencies(),
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			server := NewServer(tc.config, nil)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/capabilities", nil)
+			require.NoError(t, err)
+			server.router.ServeHTTP(recorder, request)
+
+			require.Equal(t, http.StatusOK, recorder.Code)
+			var rsp capabilitiesResponse
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+			require.Equal(t, tc.want, rsp)
+		})
+	}
+}
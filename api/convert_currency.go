@@ -0,0 +1,103 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"math/big"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+	"github.com/khuongkd/simplebank/util"
+)
+
+type convertCurrencyURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type convertCurrencyRequest struct {
+	Currency string `json:"currency" binding:"required"`
+	// Rate is a decimal string such as "0.9123", to avoid floating point rounding
+	Rate string `json:"rate" binding:"required"`
+}
+
+type convertCurrencyResponse struct {
+	Account      accountResponse   `json:"account"`
+	Entry        db.Entry          `json:"entry"`
+	RoundingMode util.RoundingMode `json:"rounding_mode"`
+	// FoldedSubBalance is the debit of the sub-balance the account held in the new currency, which moved into its balance
+	FoldedSubBalance *db.CurrencyBalanceChange `json:"folded_sub_balance,omitempty"`
+}
+
+// convertAccountCurrency godoc
+// @Summary  Convert an account's balance to another currency
+// @Tags     admin
+// @Accept   json
+// @Produce  json
+// @Param    id       path      int                     true  "Account ID"
+// @Param    request  body      convertCurrencyRequest  true  "Target currency and exchange rate"
+// @Success  200      {object}  convertCurrencyResponse
+// @Failure  400      {object}  apiError
+// @Failure  403      {object}  apiError
+// @Failure  404      {object}  apiError
+// @Failure  409      {object}  apiError
+// @Failure  500      {object}  apiError
+// @Router   /admin/accounts/{id}/convert-currency [post]
+func (server *Server) convertAccountCurrency(ctx *gin.Context) {
+	var uri convertCurrencyURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	var req convertCurrencyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	if !server.supportedCurrency(ctx, req.Currency) {
+		return
+	}
+
+	rate, ok := new(big.Rat).SetString(req.Rate)
+	if !ok || rate.Sign() <= 0 {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errInvalidRate))
+		return
+	}
+
+	result, err := server.store.ConvertAccountCurrencyTx(ctx.Request.Context(), db.ConvertAccountCurrencyTxParams{
+		AccountID:         uri.ID,
+		Currency:          req.Currency,
+		Rate:              rate,
+		NumberPerCurrency: server.config.AccountNumberPerCurrency,
+		RoundingMode:      server.currentConfig().FXRoundingMode,
+		AllowedPairs:      server.currentConfig().CurrencyPairs(),
+	})
+	if err != nil {
+		switch {
+		case err == sql.ErrNoRows:
+			ctx.JSON(http.StatusNotFound, errorResponse(errAccountNotFound))
+		case errors.Is(err, db.ErrPendingHolds),
+			errors.Is(err, db.ErrPendingScheduledTransfers),
+			errors.Is(err, db.ErrPendingConfirmations):
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+		case errors.Is(err, db.ErrCurrencyPairNotAllowed):
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+		case errors.Is(err, db.ErrCurrencyUnchanged),
+			errors.Is(err, util.ErrAmountOutOfRange),
+			errors.Is(err, db.ErrConstraintViolation):
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		default:
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, convertCurrencyResponse{
+		Account:          server.newAccountResponse(result.Account),
+		Entry:            result.Entry,
+		RoundingMode:     result.RoundingMode,
+		FoldedSubBalance: result.FoldedSubBalance,
+	})
+}
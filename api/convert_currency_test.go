@@ -0,0 +1,178 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khuongkd/simplebank/db/mock"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+	"github.com/khuongkd/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertAccountCurrencyAPI(t *testing.T) {
+	account := randomAccount()
+	account.Currency = "EUR"
+	account.Balance = 9123
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"currency": "EUR", "rate": "0.91234"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ConvertAccountCurrencyTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, params db.ConvertAccountCurrencyTxParams) (db.ConvertAccountCurrencyTxResult, error) {
+						require.Equal(t, account.ID, params.AccountID)
+						require.Equal(t, "EUR", params.Currency)
+						require.Zero(t, params.Rate.Cmp(big.NewRat(91234, 100000)))
+						require.True(t, params.AllowedPairs.Allows("USD", "EUR"))
+						require.False(t, params.AllowedPairs.Allows("EUR", "USD"))
+						return db.ConvertAccountCurrencyTxResult{
+							Account:      account,
+							Entry:        db.Entry{ID: 1, AccountID: account.ID, Amount: -877},
+							RoundingMode: util.RoundHalfUp,
+						}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp convertCurrencyResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, account.Balance, rsp.Account.Balance)
+				require.Equal(t, int64(-877), rsp.Entry.Amount)
+				require.Equal(t, util.RoundHalfUp, rsp.RoundingMode)
+			},
+		},
+		{
+			name: "PendingHolds",
+			body: gin.H{"currency": "EUR", "rate": "0.9"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ConvertAccountCurrencyTx(gomock.Any(), gomock.Any()).Times(1).Return(db.ConvertAccountCurrencyTxResult{}, db.ErrPendingHolds)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodePendingHolds)
+			},
+		},
+		{
+			name: "PendingScheduledTransfers",
+			body: gin.H{"currency": "EUR", "rate": "0.9"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ConvertAccountCurrencyTx(gomock.Any(), gomock.Any()).Times(1).Return(db.ConvertAccountCurrencyTxResult{}, db.ErrPendingScheduledTransfers)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodePendingScheduled)
+			},
+		},
+		{
+			name: "PendingConfirmations",
+			body: gin.H{"currency": "EUR", "rate": "0.9"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ConvertAccountCurrencyTx(gomock.Any(), gomock.Any()).Times(1).Return(db.ConvertAccountCurrencyTxResult{}, db.ErrPendingConfirmations)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodePendingConfirmations)
+			},
+		},
+		{
+			name: "CurrencyPairNotAllowed",
+			body: gin.H{"currency": "USD", "rate": "1.1"},
+			buildStubs: func(store *mockdb.MockStore) {
+				err := fmt.Errorf("%w: EUR to USD", db.ErrCurrencyPairNotAllowed)
+				store.EXPECT().ConvertAccountCurrencyTx(gomock.Any(), gomock.Any()).Times(1).Return(db.ConvertAccountCurrencyTxResult{}, err)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeCurrencyPair)
+			},
+		},
+		{
+			name: "CurrencyUnchanged",
+			body: gin.H{"currency": "EUR", "rate": "1"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ConvertAccountCurrencyTx(gomock.Any(), gomock.Any()).Times(1).Return(db.ConvertAccountCurrencyTxResult{}, db.ErrCurrencyUnchanged)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeInvalidRequest)
+			},
+		},
+		{
+			name: "NotFound",
+			body: gin.H{"currency": "EUR", "rate": "0.9"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ConvertAccountCurrencyTx(gomock.Any(), gomock.Any()).Times(1).Return(db.ConvertAccountCurrencyTxResult{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeAccountNotFound)
+			},
+		},
+		{
+			name: "InvalidRate",
+			body: gin.H{"currency": "EUR", "rate": "-0.5"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ConvertAccountCurrencyTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeInvalidRequest)
+			},
+		},
+		{
+			name: "UnsupportedCurrency",
+			body: gin.H{"currency": "XYZ", "rate": "0.9"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ConvertAccountCurrencyTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeInvalidRequest)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewServer(util.Config{FXAllowedPairs: []string{"USD/EUR"}}, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/admin/accounts/%d/convert-currency", account.ID)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
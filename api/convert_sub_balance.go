@@ -0,0 +1,118 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+	"github.com/khuongkd/simplebank/util"
+)
+
+type convertSubBalanceURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type convertSubBalanceRequest struct {
+	Owner        string `json:"owner" binding:"required"`
+	FromCurrency string `json:"from_currency" binding:"required"`
+	ToCurrency   string `json:"to_currency" binding:"required"`
+	// Amount is debited in FromCurrency
+	Amount int64 `json:"amount" binding:"required,gt=0"`
+	// Rate is a decimal string such as "0.9123", to avoid floating point rounding
+	Rate string `json:"rate" binding:"required"`
+}
+
+// convertSubBalance godoc
+// @Summary  Convert money between two currencies held by an account
+// @Tags     accounts
+// @Accept   json
+// @Produce  json
+// @Param    id       path      int                       true  "Account ID"
+// @Param    request  body      convertSubBalanceRequest  true  "Owner, currencies, amount and exchange rate"
+// @Success  200      {object}  db.ConvertSubBalanceTxResult
+// @Failure  400      {object}  apiError
+// @Failure  403      {object}  apiError
+// @Failure  404      {object}  apiError
+// @Failure  429      {object}  apiError
+// @Failure  500      {object}  apiError
+// @Router   /accounts/{id}/convert [post]
+func (server *Server) convertSubBalance(ctx *gin.Context) {
+	var uri convertSubBalanceURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	var req convertSubBalanceRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	if !server.supportedCurrency(ctx, req.FromCurrency) || !server.supportedCurrency(ctx, req.ToCurrency) {
+		return
+	}
+
+	rate, ok := new(big.Rat).SetString(req.Rate)
+	if !ok || rate.Sign() <= 0 {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errInvalidRate))
+		return
+	}
+
+	account, err := server.store.GetAccount(ctx.Request.Context(), uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(errAccountNotFound))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if account.Owner != req.Owner {
+		ctx.JSON(http.StatusForbidden, errorResponse(db.ErrAccountOwnerMismatch))
+		return
+	}
+	if account.Frozen {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("%w: account [%d]", errAccountFrozen, account.ID)))
+		return
+	}
+
+	unlock, ok := server.lockAccount(ctx, uri.ID)
+	if !ok {
+		return
+	}
+	defer unlock()
+
+	result, err := server.store.ConvertSubBalanceTx(ctx.Request.Context(), db.ConvertSubBalanceTxParams{
+		AccountID:    uri.ID,
+		FromCurrency: req.FromCurrency,
+		ToCurrency:   req.ToCurrency,
+		Amount:       req.Amount,
+		Rate:         rate,
+		RoundingMode: server.currentConfig().FXRoundingMode,
+		AllowedPairs: server.currentConfig().CurrencyPairs(),
+	})
+	if err != nil {
+		switch {
+		case err == sql.ErrNoRows:
+			ctx.JSON(http.StatusNotFound, errorResponse(errAccountNotFound))
+		case errors.Is(err, db.ErrCurrencyPairNotAllowed):
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+		case errors.Is(err, db.ErrInsufficientFunds),
+			errors.Is(err, db.ErrMinBalanceViolation),
+			errors.Is(err, db.ErrSameCurrency),
+			errors.Is(err, util.ErrAmountOutOfRange),
+			errors.Is(err, db.ErrConstraintViolation):
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		default:
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
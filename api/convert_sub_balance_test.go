@@ -0,0 +1,215 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khuongkd/simplebank/db/mock"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+	"github.com/khuongkd/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertSubBalanceAPI(t *testing.T) {
+	account := randomAccount()
+	account.Currency = "USD"
+	account.Balance = 1000
+
+	frozen := account
+	frozen.Frozen = true
+
+	body := gin.H{"owner": account.Owner, "from_currency": "USD", "to_currency": "EUR", "amount": 100, "rate": "0.9"}
+	with := func(key string, value interface{}) gin.H {
+		h := gin.H{}
+		for k, v := range body {
+			h[k] = v
+		}
+		h[key] = value
+		return h
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					ConvertSubBalanceTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, params db.ConvertSubBalanceTxParams) (db.ConvertSubBalanceTxResult, error) {
+						require.Equal(t, account.ID, params.AccountID)
+						require.Equal(t, "USD", params.FromCurrency)
+						require.Equal(t, "EUR", params.ToCurrency)
+						require.Equal(t, int64(100), params.Amount)
+						require.Zero(t, params.Rate.Cmp(big.NewRat(9, 10)))
+						require.True(t, params.AllowedPairs.Allows("USD", "EUR"))
+						require.False(t, params.AllowedPairs.Allows("EUR", "USD"))
+						return db.ConvertSubBalanceTxResult{
+							Account:      account,
+							From:         db.CurrencyBalanceChange{Currency: "USD", Amount: -100, Balance: 900, EntryID: 1},
+							To:           db.CurrencyBalanceChange{Currency: "EUR", Amount: 90, Balance: 90, EntryID: 1},
+							RoundingMode: util.RoundHalfUp,
+						}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp db.ConvertSubBalanceTxResult
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, int64(-100), rsp.From.Amount)
+				require.Equal(t, int64(900), rsp.From.Balance)
+				require.Equal(t, int64(90), rsp.To.Amount)
+				require.Equal(t, int64(90), rsp.To.Balance)
+			},
+		},
+		{
+			name: "InsufficientFunds",
+			body: with("amount", 5000),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ConvertSubBalanceTx(gomock.Any(), gomock.Any()).Times(1).Return(db.ConvertSubBalanceTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeInsufficientFunds)
+			},
+		},
+		{
+			name: "SameCurrency",
+			body: with("to_currency", "USD"),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ConvertSubBalanceTx(gomock.Any(), gomock.Any()).Times(1).Return(db.ConvertSubBalanceTxResult{}, db.ErrSameCurrency)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeInvalidRequest)
+			},
+		},
+		{
+			name: "CurrencyPairNotAllowed",
+			body: gin.H{"owner": account.Owner, "from_currency": "EUR", "to_currency": "USD", "amount": 100, "rate": "1.1"},
+			buildStubs: func(store *mockdb.MockStore) {
+				err := fmt.Errorf("%w: EUR to USD", db.ErrCurrencyPairNotAllowed)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ConvertSubBalanceTx(gomock.Any(), gomock.Any()).Times(1).Return(db.ConvertSubBalanceTxResult{}, err)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeCurrencyPair)
+			},
+		},
+		{
+			name: "OwnerMismatch",
+			body: with("owner", "someone-else"),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ConvertSubBalanceTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeAccountOwnerMismatch)
+			},
+		},
+		{
+			name: "Frozen",
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(frozen, nil)
+				store.EXPECT().ConvertSubBalanceTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeAccountFrozen)
+			},
+		},
+		{
+			name: "NotFound",
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().ConvertSubBalanceTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeAccountNotFound)
+			},
+		},
+		{
+			name: "InvalidRate",
+			body: with("rate", "zero"),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ConvertSubBalanceTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeInvalidRequest)
+			},
+		},
+		{
+			name: "UnsupportedCurrency",
+			body: with("to_currency", "XYZ"),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ConvertSubBalanceTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeInvalidRequest)
+			},
+		},
+		{
+			name: "NonPositiveAmount",
+			body: with("amount", 0),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ConvertSubBalanceTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewServer(util.Config{FXAllowedPairs: []string{"USD/EUR"}}, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/accounts/%d/convert", account.ID)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+)
+
+type listCounterpartiesURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type listCounterpartiesRequest struct {
+	pageRequest
+}
+
+// listCounterparties godoc
+// @Summary  List the accounts that exchanged money with an account
+// @Description  Meant for fraud investigations: every other account that sent money to or received money from the account
+// @Description  is listed once, in ID order, with the direction the money moved in (outgoing, incoming or both),
+// @Description  the number of transfers and the amounts sent and received by the account.
+// @Tags     admin
+// @Produce  json
+// @Param    id         path      int     true   "Account ID"
+// @Param    page_id    query     int     true   "Page number, starting at 1"
+// @Param    page_size  query     int     false  "Page size, between 5 and 10; defaults to the configured page size"
+// @Param    format     query     string  false  "Response format, bare or envelope; defaults to the configured format"
+// @Success  200        {array}   db.FindCounterpartyAccountsRow
+// @Failure  400        {object}  apiError
+// @Failure  500        {object}  apiError
+// @Router   /admin/accounts/{id}/counterparties [get]
+func (server *Server) listCounterparties(ctx *gin.Context) {
+	var uri listCounterpartiesURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	var req listCounterpartiesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	limit, offset := server.page(req.pageRequest)
+	counterparties, err := server.store.FindCounterpartyAccounts(ctx.Request.Context(), db.FindCounterpartyAccountsParams{
+		AccountID: uri.ID,
+		Limit:     limit,
+		Offset:    offset,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if counterparties == nil {
+		counterparties = []db.FindCounterpartyAccountsRow{}
+	}
+
+	server.writeList(ctx, req.pageRequest, counterparties)
+}
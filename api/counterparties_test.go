@@ -0,0 +1,112 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khuongkd/simplebank/db/mock"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListCounterpartiesAPI(t *testing.T) {
+	counterparties := []db.FindCounterpartyAccountsRow{
+		{ID: 2, Owner: "bob", Currency: "USD", Direction: db.CounterpartyOutgoing, Transfers: 2, AmountSent: 30},
+		{ID: 5, Owner: "carol", Currency: "USD", Direction: db.CounterpartyBoth, Transfers: 3, AmountSent: 10, AmountReceived: 45},
+	}
+
+	testCases := []struct {
+		name          string
+		accountID     int64
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			accountID: 1,
+			query:     "?page_id=2&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.FindCounterpartyAccountsParams{AccountID: 1, Limit: 5, Offset: 5}
+				store.EXPECT().FindCounterpartyAccounts(gomock.Any(), gomock.Eq(arg)).Times(1).Return(counterparties, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp []db.FindCounterpartyAccountsRow
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, counterparties, rsp)
+			},
+		},
+		{
+			name:      "NoCounterparties",
+			accountID: 1,
+			query:     "?page_id=1",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().FindCounterpartyAccounts(gomock.Any(), gomock.Any()).Times(1).Return(nil, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.JSONEq(t, `[]`, recorder.Body.String())
+			},
+		},
+		{
+			name:      "InvalidID",
+			accountID: 0,
+			query:     "?page_id=1",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().FindCounterpartyAccounts(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "MissingPage",
+			accountID: 1,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().FindCounterpartyAccounts(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "InternalError",
+			accountID: 1,
+			query:     "?page_id=1",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().FindCounterpartyAccounts(gomock.Any(), gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			target := fmt.Sprintf("/admin/accounts/%d/counterparties%s", tc.accountID, tc.query)
+			request, err := http.NewRequest(http.MethodGet, target, nil)
+			require.NoError(t, err)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/khuongkd/simplebank/util"
+)
+
+type currencyResponse struct {
+	Code     string `json:"code"`
+	Symbol   string `json:"symbol"`
+	Decimals int    `json:"decimals"`
+}
+
+// listCurrencies godoc
+// @Summary  List the currencies an account can hold
+// @Tags     currencies
+// @Produce  json
+// @Success  200  {array}  currencyResponse
+// @Router   /currencies [get]
+func (server *Server) listCurrencies(ctx *gin.Context) {
+	codes := server.currentConfig().Currencies()
+
+	rsp := make([]currencyResponse, len(codes))
+	for i, code := range codes {
+		currency := util.LookupCurrency(code)
+		rsp[i] = currencyResponse{
+			Code:     currency.Code,
+			Symbol:   currency.Symbol,
+			Decimals: currency.Decimals,
+		}
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// supportedCurrency checks the currency is one of the configured currencies, writing the error response otherwise.
+func (server *Server) supportedCurrency(ctx *gin.Context, currency string) bool {
+	if serr := server.checkCurrency(currency); serr != nil {
+		serr.write(ctx)
+		return false
+	}
+	return true
+}
+
+// checkCurrency checks the currency is one of the configured currencies.
+// The error reads like a failed oneof binding, since the list used to be fixed in the request struct.
+func (server *Server) checkCurrency(currency string) *statusError {
+	config := server.currentConfig()
+	if config.SupportsCurrency(currency) {
+		return nil
+	}
+
+	param := strings.Join(config.Currencies(), " ")
+	return &statusError{
+		status: http.StatusBadRequest,
+		rsp: apiError{
+			Code:    ErrCodeInvalidRequest,
+			Message: fmt.Sprintf("currency %s is not one of %s", currency, param),
+			Details: []fieldError{{Field: "currency", Rule: "oneof", Param: param}},
+		},
+	}
+}
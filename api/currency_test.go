@@ -0,0 +1,68 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khuongkd/simplebank/db/mock"
+	"github.com/khuongkd/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListCurrenciesAPI(t *testing.T) {
+	testCases := []struct {
+		name     string
+		config   util.Config
+		expected []currencyResponse
+	}{
+		{
+			name:   "Configured",
+			config: util.Config{SupportedCurrencies: []string{"USD", "JPY"}},
+			expected: []currencyResponse{
+				{Code: "USD", Symbol: "$", Decimals: 2},
+				{Code: "JPY", Symbol: "¥", Decimals: 0},
+			},
+		},
+		{
+			name:   "DefaultsToKnownCurrencies",
+			config: util.Config{},
+			expected: []currencyResponse{
+				{Code: "USD", Symbol: "$", Decimals: 2},
+				{Code: "EUR", Symbol: "€", Decimals: 2},
+				{Code: "GBP", Symbol: "£", Decimals: 2},
+				{Code: "VND", Symbol: "₫", Decimals: 0},
+				{Code: "JPY", Symbol: "¥", Decimals: 0},
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			server := NewServer(tc.config, mockdb.NewMockStore(ctrl))
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/currencies", nil)
+			require.NoError(t, err)
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusOK, recorder.Code)
+
+			var currencies []currencyResponse
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &currencies))
+			require.Equal(t, tc.expected, currencies)
+
+			codes := make([]string, len(currencies))
+			for i, currency := range currencies {
+				codes[i] = currency.Code
+			}
+			require.Equal(t, tc.config.Currencies(), codes)
+		})
+	}
+}
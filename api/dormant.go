@@ -0,0 +1,52 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+)
+
+type listDormantAccountsRequest struct {
+	pageRequest
+	Since time.Time `form:"since" binding:"required" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+// listDormantAccounts godoc
+// @Summary  List accounts without activity since a point in time
+// @Description  An account is dormant when none of its entries were created at or after since, including accounts that never had one.
+// @Tags     admin
+// @Produce  json
+// @Param    since      query     string  true   "RFC 3339 time, such as 2022-01-01T00:00:00Z"
+// @Param    page_id    query     int     true   "Page number, starting at 1"
+// @Param    page_size  query     int     false  "Page size, between 5 and 10; defaults to the configured page size"
+// @Param    format     query     string  false  "Response format, bare or envelope; defaults to the configured format"
+// @Success  200        {array}   accountResponse
+// @Failure  400        {object}  apiError
+// @Failure  500        {object}  apiError
+// @Router   /admin/accounts/dormant [get]
+func (server *Server) listDormantAccounts(ctx *gin.Context) {
+	var req listDormantAccountsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	limit, offset := server.page(req.pageRequest)
+	accounts, err := server.store.ListDormantAccounts(ctx.Request.Context(), db.ListDormantAccountsParams{
+		Since:  req.Since,
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := make([]accountResponse, len(accounts))
+	for i, account := range accounts {
+		rsp[i] = server.newAccountResponse(account)
+	}
+	server.writeList(ctx, req.pageRequest, rsp)
+}
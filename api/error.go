@@ -0,0 +1,217 @@
+package api
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+	"github.com/khuongkd/simplebank/util"
+)
+
+// ErrorCode is a stable, machine readable identifier of an API error
+type ErrorCode string
+
+const (
+	ErrCodeInvalidRequest       ErrorCode = "INVALID_REQUEST"
+	ErrCodeInvalidAccountNumber ErrorCode = "INVALID_ACCOUNT_NUMBER"
+	ErrCodeAccountNotFound      ErrorCode = "ACCOUNT_NOT_FOUND"
+	ErrCodeInsufficientFunds    ErrorCode = "INSUFFICIENT_FUNDS"
+	ErrCodeCurrencyMismatch     ErrorCode = "CURRENCY_MISMATCH"
+	ErrCodeTransferLimit        ErrorCode = "TRANSFER_LIMIT_EXCEEDED"
+	ErrCodeAccountBusy          ErrorCode = "ACCOUNT_BUSY"
+	ErrCodeAccountOwnerMismatch ErrorCode = "ACCOUNT_OWNER_MISMATCH"
+	ErrCodeNothingToSweep       ErrorCode = "NOTHING_TO_SWEEP"
+	ErrCodeConstraintViolation  ErrorCode = "CONSTRAINT_VIOLATION"
+	ErrCodeAccountLimit         ErrorCode = "ACCOUNT_LIMIT_EXCEEDED"
+	ErrCodeNotWhitelisted       ErrorCode = "DESTINATION_NOT_WHITELISTED"
+	ErrCodeHoldNotFound         ErrorCode = "HOLD_NOT_FOUND"
+	ErrCodeHoldNotAuthorized    ErrorCode = "HOLD_NOT_AUTHORIZED"
+	ErrCodePendingHolds         ErrorCode = "PENDING_HOLDS"
+	ErrCodePendingScheduled     ErrorCode = "PENDING_SCHEDULED_TRANSFERS"
+	ErrCodePendingConfirmations ErrorCode = "PENDING_CONFIRMATIONS"
+	ErrCodeOwnershipNotFound    ErrorCode = "OWNERSHIP_REQUEST_NOT_FOUND"
+	ErrCodeOwnershipNotPending  ErrorCode = "OWNERSHIP_REQUEST_NOT_PENDING"
+	ErrCodeOwnershipExpired     ErrorCode = "OWNERSHIP_REQUEST_EXPIRED"
+	ErrCodeOwnershipStale       ErrorCode = "OWNERSHIP_REQUEST_STALE"
+	ErrCodeRequestTimeout       ErrorCode = "REQUEST_TIMEOUT"
+	ErrCodeMinimumBalance       ErrorCode = "MINIMUM_BALANCE"
+	ErrCodeAccountVetoed        ErrorCode = "ACCOUNT_CREATION_VETOED"
+	ErrCodeDeadlock             ErrorCode = "DEADLOCK"
+	ErrCodePossibleDuplicate    ErrorCode = "POSSIBLE_DUPLICATE"
+	ErrCodeMaintenance          ErrorCode = "MAINTENANCE"
+	ErrCodeTransferVelocity     ErrorCode = "TRANSFER_VELOCITY_EXCEEDED"
+	ErrCodeCurrencyPair         ErrorCode = "CURRENCY_PAIR_NOT_ALLOWED"
+	ErrCodeTransferNotFound     ErrorCode = "TRANSFER_NOT_FOUND"
+	ErrCodeOverloaded           ErrorCode = "OVERLOADED"
+	ErrCodeDuplicateExternalRef ErrorCode = "DUPLICATE_EXTERNAL_REF"
+	ErrCodeFieldNotPermitted    ErrorCode = "FIELD_NOT_PERMITTED"
+	ErrCodeAccountFrozen        ErrorCode = "ACCOUNT_FROZEN"
+	ErrCodeStatementTooLarge    ErrorCode = "STATEMENT_TOO_LARGE"
+	ErrCodeConfirmationNotFound ErrorCode = "TRANSFER_CONFIRMATION_NOT_FOUND"
+	ErrCodeInvalidConfirmation  ErrorCode = "INVALID_CONFIRMATION_TOKEN"
+	ErrCodeConfirmationDone     ErrorCode = "TRANSFER_ALREADY_CONFIRMED"
+	ErrCodeConfirmationExpired  ErrorCode = "TRANSFER_CONFIRMATION_EXPIRED"
+	ErrCodeConfirmationRequired ErrorCode = "TRANSFER_CONFIRMATION_REQUIRED"
+	ErrCodeRequestTooLarge      ErrorCode = "REQUEST_TOO_LARGE"
+	ErrCodeInternal             ErrorCode = "INTERNAL_ERROR"
+)
+
+var (
+	errAccountNotFound          = errors.New("account not found")
+	errCurrencyMismatch         = errors.New("currency mismatch")
+	errTransferLimitExceeded    = errors.New("transfer amount exceeds the maximum allowed")
+	errSameAccount              = errors.New("source and destination account must differ")
+	errAccountLimitExceeded     = errors.New("too many accounts created recently")
+	errRunAtNotInFuture         = errors.New("run_at must be in the future")
+	errHoldNotFound             = errors.New("transfer hold not found")
+	errInvalidRate              = errors.New("rate must be a positive decimal number")
+	errOwnershipNotFound        = errors.New("ownership transfer request not found")
+	errSameOwner                = errors.New("new owner must differ from the current owner")
+	errPossibleDuplicate        = errors.New("a transfer with the same accounts and amount was made recently")
+	errMaintenance              = errors.New("the service is under maintenance and only accepts reads")
+	errTransferVelocity         = errors.New("too many transfers from the account in a short time")
+	errTransferNotFound         = errors.New("transfer not found")
+	errNotTransferParty         = errors.New("owner is not a party of the transfer")
+	errOverloaded               = errors.New("the server is overloaded, retry later")
+	errEmptyPatch               = errors.New("at least one field to change must be given")
+	errMetadataNotObject        = errors.New("metadata must be a JSON object")
+	errFieldNotPermitted        = errors.New("only a banker may change these fields")
+	errAccountFrozen            = errors.New("account is frozen")
+	errInvalidStatementRange    = errors.New("from must be before to")
+	errStatementTooLarge        = errors.New("statement has too many entries; narrow the date range or page through it with a cursor")
+	errFreezeReasonRequired     = errors.New("freezing an account requires a freeze reason")
+	errFreezeReasonUnfrozen     = errors.New("a freeze reason is only given along with frozen set to true")
+	errInvalidFreezeReason      = errors.New("freeze reason is not one of the configured reasons")
+	errConfirmationNotFound     = errors.New("transfer confirmation not found")
+	errInvalidConfirmationToken = errors.New("confirmation token does not match")
+	errConfirmationRequired     = errors.New("transfer needs a confirmation; make it on its own through /transfers")
+	errRequestBodyTooLarge      = errors.New("request body is too large")
+)
+
+// errorCodes maps known errors to the code reported to clients.
+// Errors that match none of them are reported as ErrCodeInternal.
+var errorCodes = []struct {
+	err  error
+	code ErrorCode
+}{
+	{errAccountNotFound, ErrCodeAccountNotFound},
+	{util.ErrInvalidAccountNumber, ErrCodeInvalidAccountNumber},
+	{errCurrencyMismatch, ErrCodeCurrencyMismatch},
+	{errTransferLimitExceeded, ErrCodeTransferLimit},
+	{errAccountBusy, ErrCodeAccountBusy},
+	{errSameAccount, ErrCodeInvalidRequest},
+	{errAccountLimitExceeded, ErrCodeAccountLimit},
+	{errRunAtNotInFuture, ErrCodeInvalidRequest},
+	{errHoldNotFound, ErrCodeHoldNotFound},
+	{errInvalidRate, ErrCodeInvalidRequest},
+	{db.ErrHoldNotAuthorized, ErrCodeHoldNotAuthorized},
+	{db.ErrPendingHolds, ErrCodePendingHolds},
+	{db.ErrPendingScheduledTransfers, ErrCodePendingScheduled},
+	{db.ErrPendingConfirmations, ErrCodePendingConfirmations},
+	{errOwnershipNotFound, ErrCodeOwnershipNotFound},
+	{errSameOwner, ErrCodeInvalidRequest},
+	{errRequestTimeout, ErrCodeRequestTimeout},
+	{errPossibleDuplicate, ErrCodePossibleDuplicate},
+	{errMaintenance, ErrCodeMaintenance},
+	{errTransferVelocity, ErrCodeTransferVelocity},
+	{errTransferNotFound, ErrCodeTransferNotFound},
+	{errNotTransferParty, ErrCodeAccountOwnerMismatch},
+	{errOverloaded, ErrCodeOverloaded},
+	{errEmptyPatch, ErrCodeInvalidRequest},
+	{errMetadataNotObject, ErrCodeInvalidRequest},
+	{errFieldNotPermitted, ErrCodeFieldNotPermitted},
+	{errAccountFrozen, ErrCodeAccountFrozen},
+	{errInvalidStatementRange, ErrCodeInvalidRequest},
+	{errStatementTooLarge, ErrCodeStatementTooLarge},
+	{errFreezeReasonRequired, ErrCodeInvalidRequest},
+	{errFreezeReasonUnfrozen, ErrCodeInvalidRequest},
+	{errInvalidFreezeReason, ErrCodeInvalidRequest},
+	{errConfirmationNotFound, ErrCodeConfirmationNotFound},
+	{errInvalidConfirmationToken, ErrCodeInvalidConfirmation},
+	{errConfirmationRequired, ErrCodeConfirmationRequired},
+	{errRequestBodyTooLarge, ErrCodeRequestTooLarge},
+	{db.ErrTransferConfirmationNotPending, ErrCodeConfirmationDone},
+	{db.ErrTransferConfirmationExpired, ErrCodeConfirmationExpired},
+	{ErrAccountCreationVetoed, ErrCodeAccountVetoed},
+	{db.ErrOwnershipRequestNotPending, ErrCodeOwnershipNotPending},
+	{db.ErrOwnershipRequestExpired, ErrCodeOwnershipExpired},
+	{db.ErrOwnershipRequestStale, ErrCodeOwnershipStale},
+	{db.ErrCurrencyUnchanged, ErrCodeInvalidRequest},
+	{db.ErrSameCurrency, ErrCodeInvalidRequest},
+	{db.ErrCurrencyPairNotAllowed, ErrCodeCurrencyPair},
+	{util.ErrAmountOutOfRange, ErrCodeInvalidRequest},
+	{db.ErrInsufficientFunds, ErrCodeInsufficientFunds},
+	{db.ErrTransferLimitExceeded, ErrCodeTransferLimit},
+	{db.ErrBelowMinimumBalance, ErrCodeMinimumBalance},
+	{db.ErrMinBalanceViolation, ErrCodeMinimumBalance},
+	{db.ErrDestinationNotWhitelisted, ErrCodeNotWhitelisted},
+	{db.ErrCurrencyMismatch, ErrCodeCurrencyMismatch},
+	{db.ErrAccountFrozen, ErrCodeAccountFrozen},
+	{db.ErrAccountOwnerMismatch, ErrCodeAccountOwnerMismatch},
+	{db.ErrNothingToSweep, ErrCodeNothingToSweep},
+	{db.ErrConstraintViolation, ErrCodeConstraintViolation},
+	{db.ErrDeadlock, ErrCodeDeadlock},
+	{db.ErrDuplicateExternalRef, ErrCodeDuplicateExternalRef},
+}
+
+// apiError is the envelope of every error response
+type apiError struct {
+	Code    ErrorCode   `json:"code"`
+	Message string      `json:"message"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+// fieldError describes a request field that failed validation
+type fieldError struct {
+	Field string `json:"field"`
+	Rule  string `json:"rule"`
+	Param string `json:"param,omitempty"`
+}
+
+func errorResponse(err error) apiError {
+	for _, known := range errorCodes {
+		if errors.Is(err, known.err) {
+			return apiError{Code: known.code, Message: err.Error()}
+		}
+	}
+
+	return apiError{Code: ErrCodeInternal, Message: err.Error()}
+}
+
+// bindingErrorResponse reports a request that could not be bound or validated,
+// listing the offending fields when the validator provides them
+func bindingErrorResponse(err error) apiError {
+	rsp := apiError{Code: ErrCodeInvalidRequest, Message: err.Error()}
+
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		details := make([]fieldError, len(validationErrs))
+		for i, fe := range validationErrs {
+			details[i] = fieldError{
+				Field: fe.Field(),
+				Rule:  fe.Tag(),
+				Param: fe.Param(),
+			}
+		}
+		rsp.Details = details
+	}
+
+	return rsp
+}
+
+// requestFieldName names a struct field after the json, uri or form tag it is bound from,
+// so validation details refer to the names clients actually send
+func requestFieldName(field reflect.StructField) string {
+	for _, key := range []string{"json", "uri", "form"} {
+		name := strings.SplitN(field.Tag.Get(key), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/khuongkd/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorResponse(t *testing.T) {
+	testCases := []struct {
+		err  error
+		code ErrorCode
+	}{
+		{errAccountNotFound, ErrCodeAccountNotFound},
+		{fmt.Errorf("lookup: %w", errAccountNotFound), ErrCodeAccountNotFound},
+		{util.ErrInvalidAccountNumber, ErrCodeInvalidAccountNumber},
+		{sql.ErrConnDone, ErrCodeInternal},
+	}
+
+	for _, tc := range testCases {
+		rsp := errorResponse(tc.err)
+		require.Equal(t, tc.code, rsp.Code)
+		require.Equal(t, tc.err.Error(), rsp.Message)
+		require.Nil(t, rsp.Details)
+	}
+}
+
+// requireErrorCode checks the response carries the error envelope with the given code
+func requireErrorCode(t *testing.T, recorder *httptest.ResponseRecorder, code ErrorCode) apiError {
+	var rsp struct {
+		Code    ErrorCode    `json:"code"`
+		Message string       `json:"message"`
+		Details []fieldError `json:"details"`
+	}
+	err := json.Unmarshal(recorder.Body.Bytes(), &rsp)
+	require.NoError(t, err)
+	require.Equal(t, code, rsp.Code)
+	require.NotEmpty(t, rsp.Message)
+
+	return apiError{Code: rsp.Code, Message: rsp.Message, Details: rsp.Details}
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+)
+
+// eventResponse is an event of the log as exposed to clients.
+// It spells out the fields of db.Event so that the payload can be documented as a JSON object.
+type eventResponse struct {
+	ID         int64           `json:"id"`
+	EventType  string          `json:"event_type"`
+	Payload    json.RawMessage `json:"payload" swaggertype:"object"`
+	AccountIds []int64         `json:"account_ids"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+func newEventResponse(event db.Event) eventResponse {
+	return eventResponse{
+		ID:         event.ID,
+		EventType:  event.EventType,
+		Payload:    event.Payload,
+		AccountIds: event.AccountIds,
+		CreatedAt:  event.CreatedAt,
+	}
+}
+
+type listEventsRequest struct {
+	pageRequest
+}
+
+// listEvents godoc
+// @Summary  List the event log, oldest first
+// @Tags     admin
+// @Produce  json
+// @Param    page_id    query     int     true   "Page number, starting at 1"
+// @Param    page_size  query     int     false  "Page size, between 5 and 10; defaults to the configured page size"
+// @Param    format     query     string  false  "Response format, bare or envelope; defaults to the configured format"
+// @Success  200        {array}   eventResponse
+// @Failure  400        {object}  apiError
+// @Failure  500        {object}  apiError
+// @Router   /admin/events [get]
+func (server *Server) listEvents(ctx *gin.Context) {
+	var req listEventsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	limit, offset := server.page(req.pageRequest)
+	events, err := server.store.ListEvents(ctx.Request.Context(), db.ListEventsParams{
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := make([]eventResponse, len(events))
+	for i, event := range events {
+		rsp[i] = newEventResponse(event)
+	}
+	server.writeList(ctx, req.pageRequest, rsp)
+}
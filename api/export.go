@@ -0,0 +1,57 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+)
+
+// exportFlushEvery is the number of accounts written between flushes of an export
+const exportFlushEvery = 100
+
+// exportAccounts godoc
+// @Summary  Export every account as newline delimited JSON
+// @Description  Streams all accounts in id order, one JSON object per line, for reconciliation jobs.
+// @Description  An error after the first account cannot change the status anymore, so the stream is cut short instead;
+// @Description  clients should compare the last id they read with what they expect.
+// @Description  Accounts are flushed to the client every 100 lines, compressed or not; from the first flush on,
+// @Description  REQUEST_TIMEOUT no longer holds the response back but still cancels the export, so give the route a longer ROUTE_TIMEOUTS entry.
+// @Tags     admin
+// @Produce  application/x-ndjson
+// @Success  200  {array}   accountResponse
+// @Failure  500  {object}  apiError
+// @Router   /admin/accounts/export [get]
+func (server *Server) exportAccounts(ctx *gin.Context) {
+	encoder := json.NewEncoder(ctx.Writer)
+	written := 0
+	err := server.store.StreamAllAccounts(ctx.Request.Context(), func(account db.Account) error {
+		if written == 0 {
+			ctx.Header("Content-Type", "application/x-ndjson")
+			ctx.Status(http.StatusOK)
+		}
+		if err := encoder.Encode(server.newAccountResponse(account)); err != nil {
+			return err
+		}
+
+		written++
+		if written%exportFlushEvery == 0 {
+			ctx.Writer.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		if written == 0 {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		ctx.Error(err)
+		return
+	}
+
+	if written == 0 {
+		ctx.Header("Content-Type", "application/x-ndjson")
+		ctx.Status(http.StatusOK)
+	}
+}
@@ -0,0 +1,180 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khuongkd/simplebank/db/mock"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+	"github.com/khuongkd/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportAccountsAPI(t *testing.T) {
+	accounts := []db.Account{randomAccount(), randomAccount(), randomAccount()}
+	errExport := errors.New("connection lost")
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					StreamAllAccounts(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, fn func(db.Account) error) error {
+						for _, account := range accounts {
+							if err := fn(account); err != nil {
+								return err
+							}
+						}
+						return nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "application/x-ndjson", recorder.Header().Get("Content-Type"))
+
+				var ids []int64
+				scanner := bufio.NewScanner(bytes.NewReader(recorder.Body.Bytes()))
+				for scanner.Scan() {
+					var rsp accountResponse
+					require.NoError(t, json.Unmarshal(scanner.Bytes(), &rsp))
+					ids = append(ids, rsp.ID)
+				}
+				require.NoError(t, scanner.Err())
+				require.Equal(t, []int64{accounts[0].ID, accounts[1].ID, accounts[2].ID}, ids)
+			},
+		},
+		{
+			name: "NoAccounts",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().StreamAllAccounts(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "application/x-ndjson", recorder.Header().Get("Content-Type"))
+				require.Zero(t, recorder.Body.Len())
+			},
+		},
+		{
+			name: "ErrorBeforeFirstAccount",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().StreamAllAccounts(gomock.Any(), gomock.Any()).Times(1).Return(errExport)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeInternal)
+			},
+		},
+		{
+			name: "ErrorMidStream",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					StreamAllAccounts(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, fn func(db.Account) error) error {
+						require.NoError(t, fn(accounts[0]))
+						return errExport
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				// the stream is cut short after the accounts already sent
+				lines := bytes.Split(bytes.TrimSpace(recorder.Body.Bytes()), []byte("\n"))
+				require.Len(t, lines, 1)
+				var rsp accountResponse
+				require.NoError(t, json.Unmarshal(lines[0], &rsp))
+				require.Equal(t, accounts[0].ID, rsp.ID)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/admin/accounts/export", nil)
+			require.NoError(t, err)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestExportAccountsStreams(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	accounts := make([]db.Account, exportFlushEvery)
+	for i := range accounts {
+		accounts[i] = randomAccount()
+	}
+	release := make(chan struct{})
+	finished := make(chan struct{})
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		StreamAllAccounts(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ interface{}, fn func(db.Account) error) error {
+			defer close(finished)
+			for _, account := range accounts {
+				if err := fn(account); err != nil {
+					return err
+				}
+			}
+			// the export only ends once the client has read what was flushed
+			select {
+			case <-release:
+			case <-time.After(5 * time.Second):
+			}
+			return nil
+		})
+
+	// responses are held by the timeout middleware and buffered by the gzip one until they are flushed
+	server := NewServer(util.Config{RequestTimeout: 10 * time.Second, GzipMinSize: 1 << 20}, store)
+	httpServer := httptest.NewServer(server.router)
+	defer httpServer.Close()
+
+	// the default transport asks for gzip and decompresses transparently
+	rsp, err := http.Get(httpServer.URL + "/admin/accounts/export")
+	require.NoError(t, err)
+	defer rsp.Body.Close()
+	require.Equal(t, http.StatusOK, rsp.StatusCode)
+	require.True(t, rsp.Uncompressed)
+
+	reader := bufio.NewReader(rsp.Body)
+	line, err := reader.ReadBytes('\n')
+	require.NoError(t, err)
+	var first accountResponse
+	require.NoError(t, json.Unmarshal(line, &first))
+	require.Equal(t, accounts[0].ID, first.ID)
+
+	select {
+	case <-finished:
+		t.Fatal("export finished before the first line was read")
+	default:
+	}
+	close(release)
+}
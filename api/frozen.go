@@ -0,0 +1,56 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+)
+
+type listFrozenAccountsRequest struct {
+	pageRequest
+	Reason string `form:"reason"`
+}
+
+// listFrozenAccounts godoc
+// @Summary  List frozen accounts, optionally only those frozen for one reason
+// @Tags     admin
+// @Produce  json
+// @Param    reason     query     string  false  "Freeze reason, one of the configured reasons; lists every frozen account when left out"
+// @Param    page_id    query     int     true   "Page number, starting at 1"
+// @Param    page_size  query     int     false  "Page size, between 5 and 10; defaults to the configured page size"
+// @Param    format     query     string  false  "Response format, bare or envelope; defaults to the configured format"
+// @Success  200        {array}   accountResponse
+// @Failure  400        {object}  apiError
+// @Failure  500        {object}  apiError
+// @Router   /admin/accounts/frozen [get]
+func (server *Server) listFrozenAccounts(ctx *gin.Context) {
+	var req listFrozenAccountsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	if req.Reason != "" && !server.currentConfig().AllowsFreezeReason(req.Reason) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("%w: %q", errInvalidFreezeReason, req.Reason)))
+		return
+	}
+
+	limit, offset := server.page(req.pageRequest)
+	accounts, err := server.store.ListFrozenAccounts(ctx.Request.Context(), db.ListFrozenAccountsParams{
+		Reason: req.Reason,
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := make([]accountResponse, len(accounts))
+	for i, account := range accounts {
+		rsp[i] = server.newAccountResponse(account)
+	}
+	server.writeList(ctx, req.pageRequest, rsp)
+}
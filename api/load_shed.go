@@ -0,0 +1,149 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// overloadRetryAfter is the number of seconds clients are asked to wait before retrying a shed request
+	overloadRetryAfter = "1"
+	// latencySamples is how many of the latest request latencies the p99 is computed from
+	latencySamples = 1024
+	// minLatencySamples is how many latencies the window must hold before the p99 is trusted,
+	// so a single slow request on an idle server does not shed the next ones
+	minLatencySamples = 20
+	// latencyRecomputeInterval is how long a computed p99 is reused before it is computed again
+	latencyRecomputeInterval = 100 * time.Millisecond
+	// defaultLatencyWindow is used when LatencyWindow is not configured
+	defaultLatencyWindow = 10 * time.Second
+)
+
+// loadShedder tracks the requests being served and the latency of recent ones
+type loadShedder struct {
+	inFlight  int64
+	latencies *latencyTracker
+}
+
+func newLoadShedder() *loadShedder {
+	return &loadShedder{latencies: newLatencyTracker(time.Now)}
+}
+
+// loadShedMiddleware rejects requests with a 503 while the server is saturated, rather than queueing them:
+// when MaxInFlightRequests are already being served, or when the recent p99 latency is over LatencyBudget.
+// Admin routes are never shed, so that operators can still look into the overload.
+func (server *Server) loadShedMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if strings.HasPrefix(ctx.Request.URL.Path, "/admin/") {
+			ctx.Next()
+			return
+		}
+
+		config := server.currentConfig()
+		shedder := server.loadShedder
+
+		inFlight := atomic.AddInt64(&shedder.inFlight, 1)
+		defer atomic.AddInt64(&shedder.inFlight, -1)
+
+		if config.MaxInFlightRequests > 0 && inFlight > config.MaxInFlightRequests {
+			rejectOverloaded(ctx)
+			return
+		}
+
+		if config.LatencyBudget > 0 {
+			window := config.LatencyWindow
+			if window <= 0 {
+				window = defaultLatencyWindow
+			}
+			if shedder.latencies.p99(window) > config.LatencyBudget {
+				rejectOverloaded(ctx)
+				return
+			}
+		}
+
+		start := time.Now()
+		ctx.Next()
+		shedder.latencies.record(time.Since(start))
+	}
+}
+
+func rejectOverloaded(ctx *gin.Context) {
+	ctx.Header("Retry-After", overloadRetryAfter)
+	ctx.AbortWithStatusJSON(http.StatusServiceUnavailable, errorResponse(errOverloaded))
+}
+
+type latencySample struct {
+	at       time.Time
+	duration time.Duration
+}
+
+// latencyTracker keeps the latest request latencies in a ring buffer.
+// Latencies older than the window are left out of the p99, so once requests are shed and no new ones
+// finish, the p99 falls back under the budget and requests are let through again.
+type latencyTracker struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	samples []latencySample
+	next    int
+
+	cached     time.Duration
+	cachedAt   time.Time
+	cachedOver time.Duration
+}
+
+func newLatencyTracker(now func() time.Time) *latencyTracker {
+	return &latencyTracker{
+		now:     now,
+		samples: make([]latencySample, 0, latencySamples),
+	}
+}
+
+func (tracker *latencyTracker) record(duration time.Duration) {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	sample := latencySample{at: tracker.now(), duration: duration}
+	if len(tracker.samples) < latencySamples {
+		tracker.samples = append(tracker.samples, sample)
+		return
+	}
+	tracker.samples[tracker.next] = sample
+	tracker.next = (tracker.next + 1) % latencySamples
+}
+
+// p99 returns the 99th percentile of the latencies recorded within window,
+// or zero when there are too few of them to tell
+func (tracker *latencyTracker) p99(window time.Duration) time.Duration {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	now := tracker.now()
+	if tracker.cachedOver == window && now.Sub(tracker.cachedAt) < latencyRecomputeInterval {
+		return tracker.cached
+	}
+
+	since := now.Add(-window)
+	durations := make([]time.Duration, 0, len(tracker.samples))
+	for _, sample := range tracker.samples {
+		if sample.at.After(since) {
+			durations = append(durations, sample.duration)
+		}
+	}
+
+	var p99 time.Duration
+	if len(durations) >= minLatencySamples {
+		sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+		p99 = durations[(len(durations)*99+99)/100-1]
+	}
+
+	tracker.cached = p99
+	tracker.cachedAt = now
+	tracker.cachedOver = window
+	return p99
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/khuongkd/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadShedMaxInFlight(t *testing.T) {
+	server := NewServer(util.Config{MaxInFlightRequests: 2}, nil)
+
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	server.router.GET("/test/slow", func(ctx *gin.Context) {
+		entered <- struct{}{}
+		<-release
+		ctx.Status(http.StatusOK)
+	})
+
+	var wg sync.WaitGroup
+	codes := make(chan int, 2)
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			recorder := httptest.NewRecorder()
+			server.router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/test/slow", nil))
+			codes <- recorder.Code
+		}()
+	}
+	<-entered
+	<-entered
+
+	// the server is saturated: excess requests are shed instead of queued
+	for i := 0; i < 3; i++ {
+		recorder := httptest.NewRecorder()
+		server.router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/test/slow", nil))
+		require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+		require.Equal(t, overloadRetryAfter, recorder.Header().Get("Retry-After"))
+		requireErrorCode(t, recorder, ErrCodeOverloaded)
+	}
+
+	// operators still get through
+	recorder := httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/admin/maintenance", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	close(release)
+	wg.Wait()
+	close(codes)
+	for code := range codes {
+		require.Equal(t, http.StatusOK, code)
+	}
+
+	// shed requests do not leak a slot
+	go func() { <-entered }()
+	recorder = httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/test/slow", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestLoadShedLatencyBudget(t *testing.T) {
+	server := NewServer(util.Config{LatencyBudget: 50 * time.Millisecond, LatencyWindow: time.Minute}, nil)
+	server.router.GET("/test/fast", func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+
+	now := time.Now()
+	server.loadShedder.latencies = newLatencyTracker(func() time.Time { return now })
+	for i := 0; i < minLatencySamples; i++ {
+		server.loadShedder.latencies.record(time.Second)
+	}
+
+	recorder := httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/test/fast", nil))
+	require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	requireErrorCode(t, recorder, ErrCodeOverloaded)
+
+	// once the slow requests fall out of the window, requests are let through again
+	now = now.Add(time.Minute + time.Second)
+	recorder = httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/test/fast", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestLatencyTrackerP99(t *testing.T) {
+	now := time.Now()
+	tracker := newLatencyTracker(func() time.Time { return now })
+
+	for i := 0; i < minLatencySamples-1; i++ {
+		tracker.record(time.Second)
+	}
+	require.Zero(t, tracker.p99(time.Minute), "too few samples to tell")
+
+	tracker = newLatencyTracker(func() time.Time { return now })
+	for i := 0; i < 99; i++ {
+		tracker.record(time.Millisecond)
+	}
+	tracker.record(time.Second)
+	require.Equal(t, time.Millisecond, tracker.p99(time.Minute))
+
+	// the p99 is reused until it is due to be computed again
+	tracker.record(time.Second)
+	require.Equal(t, time.Millisecond, tracker.p99(time.Minute))
+	now = now.Add(latencyRecomputeInterval)
+	require.Equal(t, time.Second, tracker.p99(time.Minute))
+
+	// the ring buffer only keeps the latest samples
+	for i := 0; i < latencySamples; i++ {
+		tracker.record(2 * time.Millisecond)
+	}
+	now = now.Add(latencyRecomputeInterval)
+	require.Equal(t, 2*time.Millisecond, tracker.p99(time.Minute))
+	require.Len(t, tracker.samples, latencySamples)
+}
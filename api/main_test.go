@@ -1,13 +1,34 @@
 package api
 
 import (
+	"bytes"
+	"encoding/json"
+	"net/http"
 	"os"
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+	"github.com/khuongkd/simplebank/util"
+	"github.com/stretchr/testify/require"
 )
 
+func newTestServer(t *testing.T, store db.Store) *Server {
+	config := util.Config{}
+	return NewServer(config, store)
+}
+
 func TestMain(m *testing.M) {
 	gin.SetMode(gin.TestMode)
 	os.Exit(m.Run())
 }
+
+// newPostRequest builds a POST request to url with body encoded as JSON
+func newPostRequest(t *testing.T, url string, body gin.H) *http.Request {
+	data, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+	require.NoError(t, err)
+	return request
+}
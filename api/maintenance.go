@@ -0,0 +1,108 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maintenanceRetryAfter is the number of seconds clients are asked to wait before retrying a write rejected for maintenance
+const maintenanceRetryAfter = "60"
+
+// maintenanceSwitch holds whether the server is in maintenance mode.
+// It starts from MaintenanceMode and is flipped at runtime through the admin endpoint.
+type maintenanceSwitch struct {
+	on int32
+}
+
+func newMaintenanceSwitch(on bool) *maintenanceSwitch {
+	m := &maintenanceSwitch{}
+	m.set(on)
+	return m
+}
+
+func (m *maintenanceSwitch) enabled() bool {
+	return atomic.LoadInt32(&m.on) == 1
+}
+
+func (m *maintenanceSwitch) set(on bool) {
+	var value int32
+	if on {
+		value = 1
+	}
+	atomic.StoreInt32(&m.on, value)
+}
+
+// readOnlyPostRoutes lists the POST routes that only read, taking a body because their input does not fit a query string
+var readOnlyPostRoutes = map[string]bool{
+	"/transfers/simulate": true,
+	"/transfers/status":   true,
+}
+
+// maintenanceMiddleware rejects every write with a 503 while the server is in maintenance mode; reads still go through.
+// Admin routes are left alone so that operators can keep working and turn maintenance off again.
+func (server *Server) maintenanceMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !server.maintenance.enabled() || isRead(ctx.Request.Method, ctx.FullPath()) ||
+			strings.HasPrefix(ctx.Request.URL.Path, "/admin/") {
+			ctx.Next()
+			return
+		}
+
+		ctx.Header("Retry-After", maintenanceRetryAfter)
+		ctx.AbortWithStatusJSON(http.StatusServiceUnavailable, errorResponse(errMaintenance))
+	}
+}
+
+// isRead reports whether requests with the HTTP method to the route leave data untouched
+func isRead(method, route string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	case http.MethodPost:
+		return readOnlyPostRoutes[route]
+	}
+	return false
+}
+
+type maintenanceRequest struct {
+	// Enabled is a pointer so that false is told apart from a missing field
+	Enabled *bool `json:"enabled" binding:"required"`
+}
+
+type maintenanceResponse struct {
+	Enabled bool `json:"enabled"`
+}
+
+// getMaintenance godoc
+// @Summary  Report whether the server is in maintenance mode
+// @Tags     admin
+// @Produce  json
+// @Success  200  {object}  maintenanceResponse
+// @Router   /admin/maintenance [get]
+func (server *Server) getMaintenance(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, maintenanceResponse{Enabled: server.maintenance.enabled()})
+}
+
+// setMaintenance godoc
+// @Summary      Turn maintenance mode on or off
+// @Description  While maintenance mode is on, every write outside the admin routes is rejected with 503 and reads go on as usual.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        request  body      maintenanceRequest  true  "Whether maintenance mode is on"
+// @Success      200      {object}  maintenanceResponse
+// @Failure      400      {object}  apiError
+// @Router       /admin/maintenance [put]
+func (server *Server) setMaintenance(ctx *gin.Context) {
+	var req maintenanceRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	server.maintenance.set(*req.Enabled)
+	ctx.JSON(http.StatusOK, maintenanceResponse{Enabled: *req.Enabled})
+}
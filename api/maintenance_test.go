@@ -0,0 +1,137 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khuongkd/simplebank/db/mock"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+	"github.com/khuongkd/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaintenanceModeBlocksWrites(t *testing.T) {
+	account := randomAccount()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().CreateAcount(gomock.Any(), gomock.Any()).Times(0)
+	store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+	store.EXPECT().ListAccounts(gomock.Any(), gomock.Any()).Times(1).Return([]db.Account{account}, nil)
+
+	server := NewServer(util.Config{MaintenanceMode: true}, store)
+
+	writes := []*http.Request{
+		newPostRequest(t, "/accounts", gin.H{"owner": account.Owner, "currency": account.Currency}),
+		newTransferRequest(t, gin.H{
+			"from_account_id": account.ID,
+			"to_account_id":   account.ID + 1,
+			"amount":          10,
+			"currency":        account.Currency,
+		}),
+	}
+	for _, request := range writes {
+		recorder := httptest.NewRecorder()
+		server.router.ServeHTTP(recorder, request)
+
+		require.Equal(t, http.StatusServiceUnavailable, recorder.Code, request.URL.Path)
+		require.Equal(t, maintenanceRetryAfter, recorder.Header().Get("Retry-After"))
+		requireErrorCode(t, recorder, ErrCodeMaintenance)
+	}
+
+	reads := []string{fmt.Sprintf("/account/%d", account.ID), "/accounts?page_id=1"}
+	for _, url := range reads {
+		request, err := http.NewRequest(http.MethodGet, url, nil)
+		require.NoError(t, err)
+		recorder := httptest.NewRecorder()
+		server.router.ServeHTTP(recorder, request)
+
+		require.Equal(t, http.StatusOK, recorder.Code, url)
+	}
+}
+
+func TestMaintenanceModeAllowsReadOnlyPosts(t *testing.T) {
+	account := randomAccount()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		GetTransfersByIDs(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return([]db.Transfer{{ID: 1, FromAccountID: account.ID, Amount: 10}}, nil)
+	store.EXPECT().SimulateTransfersTx(gomock.Any(), gomock.Any()).Times(0)
+
+	server := NewServer(util.Config{MaintenanceMode: true}, store)
+
+	recorder := httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, newPostRequest(t, "/transfers/status", gin.H{"owner": account.Owner, "ids": []int64{1}}))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	// an empty simulation is rejected by validation, not for maintenance
+	recorder = httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, newPostRequest(t, "/transfers/simulate", gin.H{"transfers": []gin.H{}}))
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+	requireErrorCode(t, recorder, ErrCodeInvalidRequest)
+
+	// other POST routes under /transfers are still writes
+	recorder = httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, newPostRequest(t, "/transfers/batch", gin.H{}))
+	require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	requireErrorCode(t, recorder, ErrCodeMaintenance)
+}
+
+func TestSetMaintenance(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+
+	// an empty transfer is rejected by validation when writes go through, and for maintenance otherwise
+	postTransfer := func() int {
+		recorder := httptest.NewRecorder()
+		server.router.ServeHTTP(recorder, newTransferRequest(t, gin.H{}))
+		return recorder.Code
+	}
+	setMaintenance := func(body gin.H) *httptest.ResponseRecorder {
+		request := newPostRequest(t, "/admin/maintenance", body)
+		request.Method = http.MethodPut
+		recorder := httptest.NewRecorder()
+		server.router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	require.Equal(t, http.StatusBadRequest, postTransfer())
+
+	recorder := setMaintenance(gin.H{"enabled": true})
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var rsp maintenanceResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+	require.True(t, rsp.Enabled)
+	require.Equal(t, http.StatusServiceUnavailable, postTransfer())
+
+	request, err := http.NewRequest(http.MethodGet, "/admin/maintenance", nil)
+	require.NoError(t, err)
+	recorder = httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.JSONEq(t, `{"enabled":true}`, recorder.Body.String())
+
+	recorder = setMaintenance(gin.H{})
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+	requireErrorCode(t, recorder, ErrCodeInvalidRequest)
+
+	recorder = setMaintenance(gin.H{"enabled": false})
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, http.StatusBadRequest, postTransfer())
+}
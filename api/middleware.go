@@ -0,0 +1,157 @@
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// compressedContentTypes lists content types that are already compressed and gain nothing from gzip
+var compressedContentTypes = []string{
+	"application/gzip",
+	"application/x-gzip",
+	"application/zip",
+	"application/pdf",
+	"image/png",
+	"image/jpeg",
+	"image/gif",
+	"image/webp",
+	"audio/",
+	"video/",
+}
+
+// gzipMiddleware compresses responses with gzip when the client accepts it.
+// Responses smaller than minSize bytes are sent uncompressed.
+func gzipMiddleware(minSize int) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !acceptsGzip(ctx.GetHeader("Accept-Encoding")) {
+			ctx.Next()
+			return
+		}
+
+		writer := &gzipWriter{
+			ResponseWriter: ctx.Writer,
+			minSize:        minSize,
+		}
+		ctx.Writer = writer
+		defer writer.close()
+
+		ctx.Next()
+	}
+}
+
+// acceptsGzip reports whether the Accept-Encoding header allows a gzip response
+func acceptsGzip(acceptEncoding string) bool {
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		params := strings.Split(part, ";")
+		if strings.TrimSpace(params[0]) != "gzip" {
+			continue
+		}
+		for _, param := range params[1:] {
+			kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+			if len(kv) == 2 && kv[0] == "q" {
+				q, err := strconv.ParseFloat(kv[1], 64)
+				return err == nil && q > 0
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// gzipWriter buffers the response until it reaches minSize bytes, then decides
+// whether to stream the rest of it through a gzip writer
+type gzipWriter struct {
+	gin.ResponseWriter
+	minSize int
+	buf     bytes.Buffer
+	gz      *gzip.Writer
+	decided bool
+}
+
+func (w *gzipWriter) Write(data []byte) (int, error) {
+	if w.decided {
+		if w.gz != nil {
+			return w.gz.Write(data)
+		}
+		return w.ResponseWriter.Write(data)
+	}
+
+	w.buf.Write(data)
+	if w.buf.Len() < w.minSize {
+		return len(data), nil
+	}
+
+	if err := w.decide(w.shouldCompress()); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+func (w *gzipWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// decide switches the writer to compressed or plain mode and flushes the buffered bytes
+func (w *gzipWriter) decide(compress bool) error {
+	w.decided = true
+	if compress {
+		header := w.Header()
+		header.Set("Content-Encoding", "gzip")
+		header.Add("Vary", "Accept-Encoding")
+		header.Del("Content-Length")
+		w.gz = gzip.NewWriter(w.ResponseWriter)
+		_, err := w.gz.Write(w.buf.Bytes())
+		return err
+	}
+
+	if w.buf.Len() == 0 {
+		return nil
+	}
+	_, err := w.ResponseWriter.Write(w.buf.Bytes())
+	return err
+}
+
+func (w *gzipWriter) shouldCompress() bool {
+	header := w.Header()
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+	if w.Status() == http.StatusNoContent || w.Status() == http.StatusNotModified {
+		return false
+	}
+
+	contentType := strings.ToLower(header.Get("Content-Type"))
+	for _, compressed := range compressedContentTypes {
+		if strings.HasPrefix(contentType, compressed) {
+			return false
+		}
+	}
+	return true
+}
+
+// Flush sends what the handler wrote so far, so that a streamed response reaches the client before it ends.
+// A response still shorter than minSize is decided on now, since it may never reach it.
+func (w *gzipWriter) Flush() {
+	if !w.decided {
+		w.decide(w.shouldCompress())
+	}
+	if w.gz != nil {
+		w.gz.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// close sends whatever is still buffered uncompressed and terminates the gzip stream
+func (w *gzipWriter) close() {
+	if !w.decided {
+		w.decide(false)
+	}
+	if w.gz != nil {
+		w.gz.Close()
+	}
+}
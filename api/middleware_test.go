@@ -0,0 +1,143 @@
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khuongkd/simplebank/db/mock"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+	"github.com/khuongkd/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGzipMiddleware(t *testing.T) {
+	accounts := make([]db.Account, 10)
+	for i := range accounts {
+		accounts[i] = randomAccount()
+	}
+
+	testCases := []struct {
+		name           string
+		minSize        int
+		acceptEncoding string
+		checkResponse  func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:           "Compressed",
+			minSize:        256,
+			acceptEncoding: "gzip, deflate",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "gzip", recorder.Header().Get("Content-Encoding"))
+
+				reader, err := gzip.NewReader(recorder.Body)
+				require.NoError(t, err)
+				data, err := ioutil.ReadAll(reader)
+				require.NoError(t, err)
+				requireJSONMatchAccounts(t, data, accounts)
+			},
+		},
+		{
+			name:           "BelowMinSize",
+			minSize:        1 << 20,
+			acceptEncoding: "gzip",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Empty(t, recorder.Header().Get("Content-Encoding"))
+				requireJSONMatchAccounts(t, recorder.Body.Bytes(), accounts)
+			},
+		},
+		{
+			name:           "GzipNotAccepted",
+			minSize:        256,
+			acceptEncoding: "gzip;q=0, br",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Empty(t, recorder.Header().Get("Content-Encoding"))
+				requireJSONMatchAccounts(t, recorder.Body.Bytes(), accounts)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().
+				ListAccounts(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(accounts, nil)
+
+			server := NewServer(util.Config{GzipMinSize: tc.minSize}, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/accounts?page_id=1&page_size=10", nil)
+			require.NoError(t, err)
+			request.Header.Set("Accept-Encoding", tc.acceptEncoding)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestGzipMiddlewareSkipsCompressedContent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	account := randomAccount()
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+		Times(1).
+		Return(account, nil)
+	store.EXPECT().
+		CountStatementEntries(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(int64(0), nil)
+	store.EXPECT().
+		ListStatementEntries(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return([]db.Entry{}, nil)
+	store.EXPECT().
+		SumTransferFeesByAccount(gomock.Any(), gomock.Eq(account.ID)).
+		Times(1).
+		Return(int64(0), nil)
+
+	server := NewServer(util.Config{}, store)
+	recorder := httptest.NewRecorder()
+
+	url := fmt.Sprintf("/accounts/%d/statement.pdf", account.ID)
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	request.Header.Set("Accept-Encoding", "gzip")
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Empty(t, recorder.Header().Get("Content-Encoding"))
+	require.True(t, bytes.HasPrefix(recorder.Body.Bytes(), []byte("%PDF-")))
+}
+
+func requireJSONMatchAccounts(t *testing.T, data []byte, accounts []db.Account) {
+	var gotAccounts []db.Account
+	err := json.Unmarshal(data, &gotAccounts)
+	require.NoError(t, err)
+	require.Len(t, gotAccounts, len(accounts))
+
+	// the raw account numbers are only exposed formatted, as account_number
+	for i, account := range accounts {
+		account.Number = 0
+		require.Equal(t, account, gotAccounts[i])
+	}
+}
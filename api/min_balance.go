@@ -0,0 +1,63 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+)
+
+type setMinBalanceURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type setMinBalanceRequest struct {
+	// MinBalance is a pointer so that zero, which lifts the minimum, is told apart from a missing field
+	MinBalance *int64 `json:"min_balance" binding:"required,min=0"`
+}
+
+// setAccountMinBalance godoc
+// @Summary  Set the balance outgoing transfers must leave on an account
+// @Tags     admin
+// @Accept   json
+// @Produce  json
+// @Param    id       path      int                   true  "Account ID"
+// @Param    request  body      setMinBalanceRequest  true  "New minimum balance; zero lifts it"
+// @Success  200      {object}  accountResponse
+// @Failure  400      {object}  apiError
+// @Failure  404      {object}  apiError
+// @Failure  500      {object}  apiError
+// @Router   /admin/accounts/{id}/min-balance [patch]
+func (server *Server) setAccountMinBalance(ctx *gin.Context) {
+	var uri setMinBalanceURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	var req setMinBalanceRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	account, err := server.store.SetAccountMinBalance(ctx.Request.Context(), db.SetAccountMinBalanceParams{
+		ID:         uri.ID,
+		MinBalance: *req.MinBalance,
+	})
+	if err != nil {
+		switch {
+		case err == sql.ErrNoRows:
+			ctx.JSON(http.StatusNotFound, errorResponse(errAccountNotFound))
+		case errors.Is(err, db.ErrConstraintViolation):
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		default:
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, server.newAccountResponse(account))
+}
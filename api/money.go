@@ -0,0 +1,207 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// moneyFields are the JSON fields of accounts, transfers and entries that hold amounts of money.
+// JavaScript parses JSON numbers as doubles, which only hold integers exactly up to 2^53.
+var moneyFields = map[string]bool{
+	"balance":      true,
+	"held_balance": true,
+	"min_balance":  true,
+	"amount":       true,
+	"fee":          true,
+	// totals of the reconciliation and treasury reports
+	"entries_total": true,
+	"discrepancy":   true,
+}
+
+// maxRequestBodySize is the most bytes of a JSON request body read into memory, far above the largest batch
+const maxRequestBodySize = 1 << 20
+
+// moneyFormatParam is the parameter of the Accept header that picks how amounts are written,
+// as in application/json; money=string
+const moneyFormatParam = "money"
+
+// Values of the money parameter of the Accept header
+const (
+	moneyFormatString = "string"
+	moneyFormatNumber = "number"
+)
+
+// moneyMiddleware lets clients send amounts as strings in request bodies,
+// and writes the amounts of JSON responses as strings when the client or the config asks for it.
+// Other responses, such as the NDJSON export, are left as they are.
+func (server *Server) moneyMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !acceptMoneyStrings(ctx) {
+			return
+		}
+
+		if !server.moneyAsString(ctx.GetHeader("Accept")) {
+			ctx.Next()
+			return
+		}
+
+		writer := &moneyWriter{ResponseWriter: ctx.Writer}
+		ctx.Writer = writer
+		defer writer.close()
+
+		ctx.Next()
+	}
+}
+
+// moneyAsString reports whether amounts are written as strings for a client sending the Accept header.
+// The money parameter of the header overrides MoneyAsString.
+func (server *Server) moneyAsString(accept string) bool {
+	for _, part := range strings.Split(accept, ",") {
+		_, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		switch params[moneyFormatParam] {
+		case moneyFormatString:
+			return true
+		case moneyFormatNumber:
+			return false
+		}
+	}
+	return server.currentConfig().MoneyAsString
+}
+
+// acceptMoneyStrings turns the amounts a JSON request body carries as strings into numbers before it is bound.
+// A body that is not valid JSON is left for the binding to reject. A body above maxRequestBodySize is
+// rejected with 413 and false is returned, since it is read whole into memory.
+func acceptMoneyStrings(ctx *gin.Context) bool {
+	if ctx.Request.Body == nil || (ctx.ContentType() != "" && ctx.ContentType() != gin.MIMEJSON) {
+		return true
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxRequestBodySize))
+	ctx.Request.Body.Close()
+	if err != nil && len(body) >= maxRequestBodySize {
+		ctx.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, errorResponse(errRequestBodyTooLarge))
+		return false
+	}
+	if err == nil {
+		if converted, ok := convertMoney(body, moneyStringToNumber); ok {
+			body = converted
+		}
+	}
+	ctx.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return true
+}
+
+// convertMoney applies convert to every money field of the JSON document, at any depth.
+// ok is false when the document is not valid JSON or no field was converted.
+func convertMoney(data []byte, convert func(interface{}) (interface{}, bool)) (converted []byte, ok bool) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, false
+	}
+
+	if !convertMoneyValue(value, convert) {
+		return nil, false
+	}
+	converted, err := json.Marshal(value)
+	return converted, err == nil
+}
+
+// convertMoneyValue converts the money fields of a decoded JSON value in place, reporting whether any changed
+func convertMoneyValue(value interface{}, convert func(interface{}) (interface{}, bool)) bool {
+	changed := false
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if moneyFields[key] {
+				if field, ok := convert(field); ok {
+					v[key] = field
+					changed = true
+				}
+				continue
+			}
+			changed = convertMoneyValue(field, convert) || changed
+		}
+	case []interface{}:
+		for _, item := range v {
+			changed = convertMoneyValue(item, convert) || changed
+		}
+	}
+	return changed
+}
+
+// moneyStringToNumber turns an amount sent as a string of digits into a number
+func moneyStringToNumber(value interface{}) (interface{}, bool) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, false
+	}
+	if _, err := strconv.ParseInt(s, 10, 64); err != nil {
+		return nil, false
+	}
+	return json.Number(s), true
+}
+
+// moneyNumberToString writes an amount as a string of digits
+func moneyNumberToString(value interface{}) (interface{}, bool) {
+	n, ok := value.(json.Number)
+	if !ok {
+		return nil, false
+	}
+	return n.String(), true
+}
+
+// moneyWriter holds a JSON response until the handler is done, to write its amounts as strings.
+// Responses of other types go through as they are written.
+type moneyWriter struct {
+	gin.ResponseWriter
+	buf       bytes.Buffer
+	decided   bool
+	buffering bool
+}
+
+func (w *moneyWriter) Write(data []byte) (int, error) {
+	if !w.decided {
+		w.decided = true
+		mediaType, _, _ := mime.ParseMediaType(w.Header().Get("Content-Type"))
+		w.buffering = mediaType == gin.MIMEJSON
+	}
+	if w.buffering {
+		return w.buf.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *moneyWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Flush is a no-op while the response is held
+func (w *moneyWriter) Flush() {
+	if !w.buffering {
+		w.ResponseWriter.Flush()
+	}
+}
+
+// close writes the held response with its amounts as strings
+func (w *moneyWriter) close() {
+	if !w.buffering {
+		return
+	}
+	body := w.buf.Bytes()
+	if converted, ok := convertMoney(body, moneyNumberToString); ok {
+		body = converted
+	}
+	w.ResponseWriter.Write(body)
+}
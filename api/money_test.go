@@ -0,0 +1,218 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khuongkd/simplebank/db/mock"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+	"github.com/khuongkd/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+// bigAmount is just above 2^53, the largest integer a double holds exactly
+const bigAmount int64 = 9007199254740993
+
+func TestConvertMoney(t *testing.T) {
+	data := []byte(`{"id":7,"balance":9007199254740993,"entries":[{"amount":-9007199254740993,"account_id":7}]}`)
+
+	asStrings, ok := convertMoney(data, moneyNumberToString)
+	require.True(t, ok)
+	require.JSONEq(t, `{"id":7,"balance":"9007199254740993","entries":[{"amount":"-9007199254740993","account_id":7}]}`, string(asStrings))
+
+	asNumbers, ok := convertMoney(asStrings, moneyStringToNumber)
+	require.True(t, ok)
+	require.JSONEq(t, string(data), string(asNumbers))
+
+	var account db.Account
+	require.NoError(t, json.Unmarshal(asNumbers, &account))
+	require.Equal(t, bigAmount, account.Balance)
+
+	// strings that are not integers are left for the binding to reject
+	_, ok = convertMoney([]byte(`{"amount":"ten"}`), moneyStringToNumber)
+	require.False(t, ok)
+	_, ok = convertMoney([]byte(`{"amount":`), moneyStringToNumber)
+	require.False(t, ok)
+}
+
+func TestTransferMoneyAsString(t *testing.T) {
+	account1 := randomAccount()
+	account2 := randomAccount()
+	account1.Currency = "USD"
+	account2.Currency = "USD"
+	account1.Balance = 2 * bigAmount
+
+	result := db.TransferTxResult{
+		Transfer:    db.Transfer{ID: 1, FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: bigAmount},
+		FromAccount: account1,
+		ToAccount:   account2,
+		FromEntry:   db.Entry{ID: 1, AccountID: account1.ID, Amount: -bigAmount},
+		ToEntry:     db.Entry{ID: 2, AccountID: account2.ID, Amount: bigAmount},
+	}
+	result.FromAccount.Balance = bigAmount
+	result.ToAccount.Balance = account2.Balance + bigAmount
+
+	// the accounts of the response carry their number only formatted, as account_number
+	want := result
+	want.FromAccount.Number = 0
+	want.ToAccount.Number = 0
+
+	testCases := []struct {
+		name          string
+		moneyAsString bool
+		accept        string
+		amount        interface{}
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "StringInNumbersOut",
+			amount: "9007199254740993",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Body.String(), `"amount":9007199254740993`)
+
+				var got db.TransferTxResult
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, want, got)
+			},
+		},
+		{
+			name:   "AcceptHeader",
+			accept: "application/json; money=string",
+			amount: bigAmount,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireMoneyAsString(t, recorder, want)
+			},
+		},
+		{
+			name:          "Config",
+			moneyAsString: true,
+			amount:        "9007199254740993",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireMoneyAsString(t, recorder, want)
+			},
+		},
+		{
+			name:          "AcceptHeaderOverridesConfig",
+			moneyAsString: true,
+			accept:        "application/json; money=number",
+			amount:        bigAmount,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Body.String(), `"amount":9007199254740993`)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+			store.EXPECT().
+				TransferTx(gomock.Any(), gomock.Eq(db.TransferTxParams{
+					FromAccountID: account1.ID,
+					ToAccountID:   account2.ID,
+					Amount:        bigAmount,
+				})).
+				Times(1).
+				Return(result, nil)
+
+			server := NewServer(util.Config{MoneyAsString: tc.moneyAsString}, store)
+			recorder := httptest.NewRecorder()
+
+			request := newPostRequest(t, "/transfers", gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          tc.amount,
+				"currency":        "USD",
+			})
+			request.Header.Set("Content-Type", "application/json")
+			if tc.accept != "" {
+				request.Header.Set("Accept", tc.accept)
+			}
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+// requireMoneyAsString checks that the amounts of a transfer response are strings that round-trip exactly
+func requireMoneyAsString(t *testing.T, recorder *httptest.ResponseRecorder, want db.TransferTxResult) {
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Contains(t, recorder.Header().Get("Content-Type"), gin.MIMEJSON)
+
+	var body struct {
+		Transfer    struct{ Amount string }  `json:"transfer"`
+		FromAccount struct{ Balance string } `json:"from_account"`
+		ToAccount   struct{ Balance string } `json:"to_account"`
+		FromEntry   struct{ Amount string }  `json:"from_entry"`
+	}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	require.Equal(t, "9007199254740993", body.Transfer.Amount)
+	require.Equal(t, "9007199254740993", body.FromAccount.Balance)
+	require.Equal(t, "-9007199254740993", body.FromEntry.Amount)
+
+	got, ok := convertMoney(recorder.Body.Bytes(), moneyStringToNumber)
+	require.True(t, ok)
+	var result db.TransferTxResult
+	require.NoError(t, json.Unmarshal(got, &result))
+	require.Equal(t, want, result)
+}
+
+func TestMoneyAsStringLeavesOtherResponses(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := NewServer(util.Config{MoneyAsString: true}, mockdb.NewMockStore(ctrl))
+	server.router.GET("/test/text", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, `{"amount":5}`)
+	})
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/test/text", nil)
+	require.NoError(t, err)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, `{"amount":5}`, recorder.Body.String())
+}
+
+func TestRequestBodyTooLarge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+	store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+	server := newTestServer(t, store)
+
+	// a valid transfer padded with a field large enough to go over the limit
+	recorder := httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, newTransferRequest(t, gin.H{
+		"from_account_id": 1,
+		"to_account_id":   2,
+		"amount":          "10",
+		"currency":        "USD",
+		"padding":         strings.Repeat("x", maxRequestBodySize),
+	}))
+	require.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+	requireErrorCode(t, recorder, ErrCodeRequestTooLarge)
+
+	// a body just under the limit still gets through to the binding
+	recorder = httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, newTransferRequest(t, gin.H{
+		"padding": strings.Repeat("x", maxRequestBodySize-100),
+	}))
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+	requireErrorCode(t, recorder, ErrCodeInvalidRequest)
+}
@@ -0,0 +1,149 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+)
+
+// defaultOwnershipRequestTTL is how long a request stays open when OwnershipRequestTTL is not configured
+const defaultOwnershipRequestTTL = 72 * time.Hour
+
+type ownershipURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type requestOwnershipTransferRequest struct {
+	Owner    string `json:"owner" binding:"required"`
+	NewOwner string `json:"new_owner" binding:"required"`
+}
+
+// requestOwnershipTransfer godoc
+// @Summary  Ask another owner to take over an account
+// @Tags     accounts
+// @Accept   json
+// @Produce  json
+// @Param    id       path      int                              true  "Account ID"
+// @Param    request  body      requestOwnershipTransferRequest  true  "Current and new owner"
+// @Success  200      {object}  db.OwnershipTransferRequest
+// @Failure  400      {object}  apiError
+// @Failure  403      {object}  apiError
+// @Failure  404      {object}  apiError
+// @Failure  500      {object}  apiError
+// @Router   /accounts/{id}/transfer-ownership [post]
+func (server *Server) requestOwnershipTransfer(ctx *gin.Context) {
+	var uri ownershipURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	var req requestOwnershipTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	if req.NewOwner == req.Owner {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errSameOwner))
+		return
+	}
+
+	account, err := server.store.GetAccount(ctx.Request.Context(), uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(errAccountNotFound))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if account.Owner != req.Owner {
+		ctx.JSON(http.StatusForbidden, errorResponse(db.ErrAccountOwnerMismatch))
+		return
+	}
+
+	ttl := server.currentConfig().OwnershipRequestTTL
+	if ttl <= 0 {
+		ttl = defaultOwnershipRequestTTL
+	}
+
+	request, err := server.store.CreateOwnershipTransferRequest(ctx.Request.Context(), db.CreateOwnershipTransferRequestParams{
+		AccountID:    account.ID,
+		CurrentOwner: account.Owner,
+		NewOwner:     req.NewOwner,
+		ExpiresAt:    time.Now().Add(ttl),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, request)
+}
+
+type acceptOwnershipTransferRequest struct {
+	Owner string `json:"owner" binding:"required"`
+}
+
+type acceptOwnershipTransferResponse struct {
+	Request db.OwnershipTransferRequest `json:"request"`
+	Account accountResponse             `json:"account"`
+}
+
+// acceptOwnershipTransfer godoc
+// @Summary  Accept an ownership transfer as the new owner
+// @Tags     accounts
+// @Accept   json
+// @Produce  json
+// @Param    id       path      int                             true  "Ownership transfer request ID"
+// @Param    request  body      acceptOwnershipTransferRequest  true  "Owner accepting the account"
+// @Success  200      {object}  acceptOwnershipTransferResponse
+// @Failure  400      {object}  apiError
+// @Failure  403      {object}  apiError
+// @Failure  404      {object}  apiError
+// @Failure  409      {object}  apiError
+// @Failure  410      {object}  apiError
+// @Failure  500      {object}  apiError
+// @Router   /ownership-requests/{id}/accept [post]
+func (server *Server) acceptOwnershipTransfer(ctx *gin.Context) {
+	var uri ownershipURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	var req acceptOwnershipTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	result, err := server.store.AcceptOwnershipTransferTx(ctx.Request.Context(), uri.ID, req.Owner)
+	if err != nil {
+		switch {
+		case err == sql.ErrNoRows:
+			ctx.JSON(http.StatusNotFound, errorResponse(errOwnershipNotFound))
+		case errors.Is(err, db.ErrAccountOwnerMismatch):
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+		case errors.Is(err, db.ErrOwnershipRequestExpired):
+			ctx.JSON(http.StatusGone, errorResponse(err))
+		case errors.Is(err, db.ErrOwnershipRequestNotPending), errors.Is(err, db.ErrOwnershipRequestStale):
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+		default:
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, acceptOwnershipTransferResponse{
+		Request: result.Request,
+		Account: server.newAccountResponse(result.Account),
+	})
+}
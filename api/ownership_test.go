@@ -0,0 +1,231 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khuongkd/simplebank/db/mock"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+	"github.com/khuongkd/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestOwnershipTransferAPI(t *testing.T) {
+	account := randomAccount()
+	newOwner := util.RandomOwner()
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"owner": account.Owner, "new_owner": newOwner},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					CreateOwnershipTransferRequest(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateOwnershipTransferRequestParams) (db.OwnershipTransferRequest, error) {
+						require.Equal(t, account.ID, arg.AccountID)
+						require.Equal(t, account.Owner, arg.CurrentOwner)
+						require.Equal(t, newOwner, arg.NewOwner)
+						require.WithinDuration(t, time.Now().Add(defaultOwnershipRequestTTL), arg.ExpiresAt, time.Minute)
+						return db.OwnershipTransferRequest{
+							ID:           1,
+							AccountID:    arg.AccountID,
+							CurrentOwner: arg.CurrentOwner,
+							NewOwner:     arg.NewOwner,
+							Status:       db.OwnershipRequestPending,
+							ExpiresAt:    arg.ExpiresAt,
+						}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp db.OwnershipTransferRequest
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, db.OwnershipRequestPending, rsp.Status)
+				require.Equal(t, newOwner, rsp.NewOwner)
+			},
+		},
+		{
+			name: "NotOwner",
+			body: gin.H{"owner": util.RandomOwner(), "new_owner": newOwner},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().CreateOwnershipTransferRequest(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeAccountOwnerMismatch)
+			},
+		},
+		{
+			name: "SameOwner",
+			body: gin.H{"owner": account.Owner, "new_owner": account.Owner},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateOwnershipTransferRequest(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeInvalidRequest)
+			},
+		},
+		{
+			name: "AccountNotFound",
+			body: gin.H{"owner": account.Owner, "new_owner": newOwner},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().CreateOwnershipTransferRequest(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeAccountNotFound)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%d/transfer-ownership", account.ID)
+			server.router.ServeHTTP(recorder, newPostRequest(t, url, tc.body))
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAcceptOwnershipTransferAPI(t *testing.T) {
+	account := randomAccount()
+	newOwner := util.RandomOwner()
+	requestID := int64(3)
+
+	testCases := []struct {
+		name          string
+		owner         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			owner: newOwner,
+			buildStubs: func(store *mockdb.MockStore) {
+				transferred := account
+				transferred.Owner = newOwner
+				store.EXPECT().
+					AcceptOwnershipTransferTx(gomock.Any(), gomock.Eq(requestID), gomock.Eq(newOwner)).
+					Times(1).
+					Return(db.AcceptOwnershipTransferTxResult{
+						Request: db.OwnershipTransferRequest{ID: requestID, AccountID: account.ID, NewOwner: newOwner, Status: db.OwnershipRequestAccepted},
+						Account: transferred,
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp acceptOwnershipTransferResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, db.OwnershipRequestAccepted, rsp.Request.Status)
+				require.Equal(t, newOwner, rsp.Account.Owner)
+			},
+		},
+		{
+			name:  "Unauthorized",
+			owner: account.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AcceptOwnershipTransferTx(gomock.Any(), gomock.Eq(requestID), gomock.Eq(account.Owner)).
+					Times(1).
+					Return(db.AcceptOwnershipTransferTxResult{}, db.ErrAccountOwnerMismatch)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeAccountOwnerMismatch)
+			},
+		},
+		{
+			name:  "Expired",
+			owner: newOwner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AcceptOwnershipTransferTx(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(db.AcceptOwnershipTransferTxResult{}, db.ErrOwnershipRequestExpired)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusGone, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeOwnershipExpired)
+			},
+		},
+		{
+			name:  "AlreadyAccepted",
+			owner: newOwner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AcceptOwnershipTransferTx(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(db.AcceptOwnershipTransferTxResult{}, db.ErrOwnershipRequestNotPending)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeOwnershipNotPending)
+			},
+		},
+		{
+			name:  "NotFound",
+			owner: newOwner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AcceptOwnershipTransferTx(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(db.AcceptOwnershipTransferTxResult{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeOwnershipNotFound)
+			},
+		},
+		{
+			name: "MissingOwner",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AcceptOwnershipTransferTx(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeInvalidRequest)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/ownership-requests/%d/accept", requestID)
+			server.router.ServeHTTP(recorder, newPostRequest(t, url, gin.H{"owner": tc.owner}))
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"math"
+	"net/http"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	"github.com/khuongkd/simplebank/util"
+)
+
+// Bounds of page_size, which the binding tags of pageRequest spell out again
+const (
+	minPageSize = 5
+	maxPageSize = 10
+)
+
+// fallbackPageSize is used for list requests without page_size when DefaultPageSize is not configured
+const fallbackPageSize = 5
+
+// pageRequest is the pagination part of every list request
+type pageRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"omitempty,min=5,max=10"`
+	// Format picks the shape of the response, overriding the configured one
+	Format util.ResponseFormat `form:"format" binding:"omitempty,oneof=bare envelope"`
+}
+
+// listEnvelope is a page of a list in the envelope format
+type listEnvelope struct {
+	Data interface{} `json:"data"`
+	Meta listMeta    `json:"meta"`
+}
+
+// listMeta describes the page held by a listEnvelope
+type listMeta struct {
+	PageID   int32 `json:"page_id"`
+	PageSize int32 `json:"page_size"`
+	Count    int   `json:"count"`
+}
+
+// pageSize returns the requested page size, or the configured default when the client omitted it.
+// A default outside the bounds clients are held to is clamped into them.
+func (server *Server) pageSize(requested int32) int32 {
+	if requested > 0 {
+		return requested
+	}
+
+	size := server.currentConfig().DefaultPageSize
+	switch {
+	case size <= 0:
+		return fallbackPageSize
+	case size < minPageSize:
+		return minPageSize
+	case size > maxPageSize:
+		return maxPageSize
+	}
+	return size
+}
+
+// page returns the limit and offset of the requested page.
+// The offset is computed without overflowing and is never negative,
+// so a page past the end of the int32 range reads as empty rather than wrapping around.
+func (server *Server) page(req pageRequest) (limit, offset int32) {
+	limit = server.pageSize(req.PageSize)
+	if req.PageID <= 1 {
+		return limit, 0
+	}
+
+	skipped := int64(req.PageID-1) * int64(limit)
+	if skipped > math.MaxInt32 {
+		return limit, math.MaxInt32
+	}
+	return limit, int32(skipped)
+}
+
+// writeList sends items, a page of a list, in the format the client asked for or else the configured one.
+// Every list endpoint goes through it, so that the formats stay the same across them.
+func (server *Server) writeList(ctx *gin.Context, req pageRequest, items interface{}) {
+	format := req.Format
+	if format == "" {
+		format = server.currentConfig().ResponseFormat
+	}
+	if format != util.ResponseFormatEnvelope {
+		ctx.JSON(http.StatusOK, items)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, listEnvelope{
+		Data: items,
+		Meta: listMeta{
+			PageID:   req.PageID,
+			PageSize: server.pageSize(req.PageSize),
+			Count:    reflect.ValueOf(items).Len(),
+		},
+	})
+}
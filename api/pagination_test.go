@@ -0,0 +1,124 @@
+package api
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khuongkd/simplebank/db/mock"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+	"github.com/khuongkd/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListAccountsPageBounds(t *testing.T) {
+	testCases := []struct {
+		name     string
+		query    string
+		config   util.Config
+		expected *db.ListAccountsParams
+	}{
+		{
+			name:  "ZeroPageID",
+			query: "page_id=0&page_size=5",
+		},
+		{
+			name:  "NegativePageID",
+			query: "page_id=-1&page_size=5",
+		},
+		{
+			name:  "OversizedPageSize",
+			query: "page_id=1&page_size=11",
+		},
+		{
+			name:  "UndersizedPageSize",
+			query: "page_id=1&page_size=4",
+		},
+		{
+			name:  "PageIDOutOfRange",
+			query: "page_id=2147483648&page_size=5",
+		},
+		{
+			name:     "MinPageSize",
+			query:    "page_id=1&page_size=5",
+			expected: &db.ListAccountsParams{Limit: minPageSize, Offset: 0},
+		},
+		{
+			name:     "MaxPageSize",
+			query:    "page_id=3&page_size=10",
+			expected: &db.ListAccountsParams{Limit: maxPageSize, Offset: 20},
+		},
+		{
+			name:     "LastPageID",
+			query:    "page_id=2147483647&page_size=10",
+			expected: &db.ListAccountsParams{Limit: maxPageSize, Offset: math.MaxInt32},
+		},
+		{
+			name:     "OversizedDefaultClamped",
+			query:    "page_id=2",
+			config:   util.Config{DefaultPageSize: 50},
+			expected: &db.ListAccountsParams{Limit: maxPageSize, Offset: maxPageSize},
+		},
+		{
+			name:     "UndersizedDefaultClamped",
+			query:    "page_id=2",
+			config:   util.Config{DefaultPageSize: 2},
+			expected: &db.ListAccountsParams{Limit: minPageSize, Offset: minPageSize},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			if tc.expected != nil {
+				store.EXPECT().
+					ListAccounts(gomock.Any(), gomock.Eq(*tc.expected)).
+					Times(1).
+					Return([]db.Account{}, nil)
+			} else {
+				store.EXPECT().ListAccounts(gomock.Any(), gomock.Any()).Times(0)
+			}
+
+			server := NewServer(tc.config, store)
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, "/accounts?"+tc.query, nil)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			if tc.expected != nil {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				return
+			}
+			require.Equal(t, http.StatusBadRequest, recorder.Code)
+			requireErrorCode(t, recorder, ErrCodeInvalidRequest)
+		})
+	}
+}
+
+func TestPageOffsetNeverNegative(t *testing.T) {
+	server := newTestServer(t, nil)
+
+	testCases := []struct {
+		req    pageRequest
+		offset int32
+	}{
+		{req: pageRequest{PageID: 0, PageSize: 5}, offset: 0},
+		{req: pageRequest{PageID: -3, PageSize: 5}, offset: 0},
+		{req: pageRequest{PageID: 1, PageSize: 5}, offset: 0},
+		{req: pageRequest{PageID: 2, PageSize: 5}, offset: 5},
+		{req: pageRequest{PageID: math.MaxInt32, PageSize: 10}, offset: math.MaxInt32},
+	}
+
+	for _, tc := range testCases {
+		limit, offset := server.page(tc.req)
+		require.Equal(t, tc.req.PageSize, limit)
+		require.Equal(t, tc.offset, offset, "page_id %d", tc.req.PageID)
+	}
+}
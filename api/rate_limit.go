@@ -0,0 +1,29 @@
+package api
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+	"time"
+)
+
+// rateLimitRetryAfter is the Retry-After value of a 429 response: RateLimitRetryAfter give or take up to
+// RateLimitRetryJitter, so that clients turned away at the same time do not all come back at the same second
+func (server *Server) rateLimitRetryAfter() string {
+	config := server.currentConfig()
+	return strconv.FormatInt(jitteredSeconds(config.RateLimitRetryAfter, config.RateLimitRetryJitter), 10)
+}
+
+// jitteredSeconds picks a whole number of seconds between base-jitter and base+jitter.
+// It is never below one second, as a Retry-After of zero would have clients retry right away.
+func jitteredSeconds(base, jitter time.Duration) int64 {
+	min := int64(math.Ceil((base - jitter).Seconds()))
+	max := int64(math.Floor((base + jitter).Seconds()))
+	if min < 1 {
+		min = 1
+	}
+	if max < min {
+		return min
+	}
+	return min + rand.Int63n(max-min+1)
+}
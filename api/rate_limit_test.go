@@ -0,0 +1,75 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/khuongkd/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJitteredSeconds(t *testing.T) {
+	testCases := []struct {
+		name     string
+		base     time.Duration
+		jitter   time.Duration
+		min, max int64
+	}{
+		{name: "NoJitter", base: 5 * time.Second, min: 5, max: 5},
+		{name: "Jitter", base: 5 * time.Second, jitter: 2 * time.Second, min: 3, max: 7},
+		{name: "FractionalBounds", base: 5 * time.Second, jitter: 1500 * time.Millisecond, min: 4, max: 6},
+		{name: "JitterBelowOneSecond", base: 2 * time.Second, jitter: 5 * time.Second, min: 1, max: 7},
+		{name: "Unset", min: 1, max: 1},
+		{name: "SubSecond", base: 300 * time.Millisecond, jitter: 100 * time.Millisecond, min: 1, max: 1},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			seen := make(map[int64]bool)
+			for i := 0; i < 1000; i++ {
+				seconds := jitteredSeconds(tc.base, tc.jitter)
+				require.GreaterOrEqual(t, seconds, tc.min)
+				require.LessOrEqual(t, seconds, tc.max)
+				seen[seconds] = true
+			}
+			// every value of the range comes up, so the jitter does spread retries
+			require.Len(t, seen, int(tc.max-tc.min+1))
+		})
+	}
+}
+
+func TestRateLimitRetryAfterHeader(t *testing.T) {
+	account := randomAccount()
+	server := NewServer(util.Config{
+		TransferLockTimeout:  10 * time.Millisecond,
+		RateLimitRetryAfter:  10 * time.Second,
+		RateLimitRetryJitter: 3 * time.Second,
+	}, nil)
+
+	// hold the transfer lock of the account so that the requests below are turned away as busy
+	unlock, err := server.transferLocks.acquire(context.Background(), account.ID, 0)
+	require.NoError(t, err)
+	defer unlock()
+
+	for i := 0; i < 20; i++ {
+		recorder := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(recorder)
+		ctx.Request = httptest.NewRequest(http.MethodPost, "/transfers", nil)
+
+		_, ok := server.lockAccount(ctx, account.ID)
+		require.False(t, ok)
+		require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+
+		seconds, err := strconv.ParseInt(recorder.Header().Get("Retry-After"), 10, 64)
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, seconds, int64(7))
+		require.LessOrEqual(t, seconds, int64(13))
+	}
+}
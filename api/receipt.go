@@ -0,0 +1,136 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+	"github.com/khuongkd/simplebank/util"
+)
+
+// transferReceipt is a shareable record of a transfer.
+// VerificationHash covers every other field, so anyone holding the receipt can check it was not altered;
+// Signature, when a signing key is configured, proves the bank issued it.
+type transferReceipt struct {
+	TransferID       int64     `json:"transfer_id"`
+	FromAccount      string    `json:"from_account"`
+	ToAccount        string    `json:"to_account"`
+	Amount           int64     `json:"amount"`
+	Fee              int64     `json:"fee"`
+	Currency         string    `json:"currency"`
+	CreatedAt        time.Time `json:"created_at"`
+	VerificationHash string    `json:"verification_hash"`
+	Signature        string    `json:"signature,omitempty"`
+}
+
+// hash is the hex SHA-256 of the receipt fields, in a fixed order and format
+func (receipt transferReceipt) hash() string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d|%s|%s|%d|%d|%s|%s",
+		receipt.TransferID,
+		receipt.FromAccount,
+		receipt.ToAccount,
+		receipt.Amount,
+		receipt.Fee,
+		receipt.Currency,
+		receipt.CreatedAt.UTC().Format(time.RFC3339Nano),
+	)))
+	return hex.EncodeToString(sum[:])
+}
+
+// signReceiptHash returns the hex HMAC-SHA256 of the verification hash under key
+func signReceiptHash(key, hash string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(hash))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+type getTransferReceiptRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type getTransferReceiptQuery struct {
+	Owner string `form:"owner" binding:"required"`
+}
+
+// getTransferReceipt godoc
+// @Summary  Get the receipt of a transfer
+// @Description  Only the owner of the source or destination account may get the receipt. Account numbers are masked.
+// @Tags     transfers
+// @Produce  json
+// @Param    id     path      int     true  "Transfer ID"
+// @Param    owner  query     string  true  "Owner of either account of the transfer"
+// @Success  200    {object}  transferReceipt
+// @Failure  400    {object}  apiError
+// @Failure  403    {object}  apiError
+// @Failure  404    {object}  apiError
+// @Failure  500    {object}  apiError
+// @Router   /transfers/{id}/receipt [get]
+func (server *Server) getTransferReceipt(ctx *gin.Context) {
+	var req getTransferReceiptRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	var query getTransferReceiptQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	transfer, err := server.store.GetTransfer(ctx.Request.Context(), req.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(errTransferNotFound))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	from, err := server.store.GetAccount(ctx.Request.Context(), transfer.FromAccountID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	to, err := server.store.GetAccount(ctx.Request.Context(), transfer.ToAccountID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if query.Owner != from.Owner && query.Owner != to.Owner {
+		ctx.JSON(http.StatusForbidden, errorResponse(errNotTransferParty))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, server.newTransferReceipt(transfer))
+}
+
+// newTransferReceipt builds the receipt from what the transfer recorded when it was made,
+// so that it stays the same after either account is converted or renumbered
+func (server *Server) newTransferReceipt(transfer db.Transfer) transferReceipt {
+	prefix := server.config.AccountNumberPrefix
+	receipt := transferReceipt{
+		TransferID:  transfer.ID,
+		FromAccount: util.MaskAccountNumber(util.FormatAccountNumber(prefix, transfer.FromAccountNumber)),
+		ToAccount:   util.MaskAccountNumber(util.FormatAccountNumber(prefix, transfer.ToAccountNumber)),
+		Amount:      transfer.Amount,
+		Fee:         transfer.Fee,
+		Currency:    transfer.Currency,
+		CreatedAt:   transfer.CreatedAt,
+	}
+	receipt.VerificationHash = receipt.hash()
+	if key := server.config.ReceiptSigningKey; key != "" {
+		receipt.Signature = signReceiptHash(key, receipt.VerificationHash)
+	}
+	return receipt
+}
@@ -0,0 +1,172 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khuongkd/simplebank/db/mock"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+	"github.com/khuongkd/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTransferReceiptAPI(t *testing.T) {
+	from := randomAccount()
+	to := randomAccount()
+	to.ID = from.ID + 1
+	to.Currency = from.Currency
+	transfer := db.Transfer{
+		ID:            util.RandomInt(1, 1000),
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        25,
+		Fee:           1,
+		CreatedAt:     time.Now().Truncate(time.Second),
+		Currency:      from.Currency,
+		// the numbers the accounts had when the transfer was made
+		FromAccountNumber: from.Number,
+		ToAccountNumber:   to.Number,
+	}
+	// the source account was converted and renumbered since, which the receipt must not show
+	from.Currency = "JPY"
+	if transfer.Currency == "JPY" {
+		from.Currency = "VND"
+	}
+	from.Number += 1000
+	signingKey := util.RandomString(16)
+
+	stubTransfer := func(store *mockdb.MockStore) {
+		store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(from.ID)).Times(1).Return(from, nil)
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(to.ID)).Times(1).Return(to, nil)
+	}
+
+	requireReceipt := func(t *testing.T, recorder *httptest.ResponseRecorder) {
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		var receipt transferReceipt
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &receipt))
+		require.Equal(t, transfer.ID, receipt.TransferID)
+		require.Equal(t, util.MaskAccountNumber(util.FormatAccountNumber("SB", transfer.FromAccountNumber)), receipt.FromAccount)
+		require.Equal(t, util.MaskAccountNumber(util.FormatAccountNumber("SB", transfer.ToAccountNumber)), receipt.ToAccount)
+		require.NotContains(t, recorder.Body.String(), util.FormatAccountNumber("SB", transfer.FromAccountNumber))
+		require.Equal(t, transfer.Amount, receipt.Amount)
+		require.Equal(t, transfer.Fee, receipt.Fee)
+		require.Equal(t, transfer.Currency, receipt.Currency)
+		require.True(t, transfer.CreatedAt.Equal(receipt.CreatedAt))
+
+		require.Equal(t, receipt.hash(), receipt.VerificationHash)
+		require.Equal(t, signReceiptHash(signingKey, receipt.VerificationHash), receipt.Signature)
+
+		tampered := receipt
+		tampered.Amount++
+		require.NotEqual(t, receipt.VerificationHash, tampered.hash())
+	}
+
+	testCases := []struct {
+		name          string
+		transferID    int64
+		owner         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:          "Sender",
+			transferID:    transfer.ID,
+			owner:         from.Owner,
+			buildStubs:    stubTransfer,
+			checkResponse: requireReceipt,
+		},
+		{
+			name:          "Recipient",
+			transferID:    transfer.ID,
+			owner:         to.Owner,
+			buildStubs:    stubTransfer,
+			checkResponse: requireReceipt,
+		},
+		{
+			name:       "NotAParty",
+			transferID: transfer.ID,
+			owner:      "someone-else",
+			buildStubs: stubTransfer,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeAccountOwnerMismatch)
+			},
+		},
+		{
+			name:       "NotFound",
+			transferID: transfer.ID,
+			owner:      from.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(db.Transfer{}, sql.ErrNoRows)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeTransferNotFound)
+			},
+		},
+		{
+			name:       "MissingOwner",
+			transferID: transfer.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeInvalidRequest)
+			},
+		},
+		{
+			name:       "InvalidID",
+			transferID: 0,
+			owner:      from.Owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeInvalidRequest)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewServer(util.Config{AccountNumberPrefix: "SB", ReceiptSigningKey: signingKey}, store)
+			recorder := httptest.NewRecorder()
+
+			target := fmt.Sprintf("/transfers/%d/receipt", tc.transferID)
+			if tc.owner != "" {
+				target += "?owner=" + url.QueryEscape(tc.owner)
+			}
+			request, err := http.NewRequest(http.MethodGet, target, nil)
+			require.NoError(t, err)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestTransferReceiptUnsigned(t *testing.T) {
+	server := newTestServer(t, nil)
+	receipt := server.newTransferReceipt(db.Transfer{ID: 1, Amount: 10, Currency: "USD", FromAccountNumber: 1, ToAccountNumber: 2})
+	require.Equal(t, receipt.hash(), receipt.VerificationHash)
+	require.Empty(t, receipt.Signature)
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requestIDKey is the key of the request ID in the gin context
+const requestIDKey = "request_id"
+
+// maxRequestIDLength is the longest request ID taken from a client; a traceparent is 55 characters
+const maxRequestIDLength = 128
+
+// requestIDMiddleware gives every request an ID, taken from the first of the headers that is set
+// or generated when none is, so that a request can be followed from the gateway through the logs.
+// The ID is echoed back under the first header and kept in the context for the handlers.
+func requestIDMiddleware(headers []string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id := ""
+		for _, header := range headers {
+			if value := ctx.GetHeader(header); validRequestID(value) {
+				id = value
+				break
+			}
+		}
+		if id == "" {
+			id = newRequestID()
+		}
+
+		ctx.Set(requestIDKey, id)
+		ctx.Header(headers[0], id)
+		ctx.Next()
+	}
+}
+
+// validRequestID reports whether an ID sent by a client can be used as is.
+// Only printable ASCII is taken, so that the ID cannot forge log lines or response headers.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID returns a random 128-bit ID
+func newRequestID() string {
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(id[:])
+}
+
+// requestID returns the ID of the request
+func requestID(ctx *gin.Context) string {
+	return ctx.GetString(requestIDKey)
+}
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khuongkd/simplebank/db/mock"
+	"github.com/khuongkd/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	traceparent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	testCases := []struct {
+		name          string
+		headers       []string
+		setupRequest  func(request *http.Request)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "DefaultHeader",
+			setupRequest: func(request *http.Request) {
+				request.Header.Set("X-Request-ID", "abc-123")
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, "abc-123", recorder.Header().Get("X-Request-ID"))
+			},
+		},
+		{
+			name:    "ConfiguredHeader",
+			headers: []string{"X-Correlation-ID"},
+			setupRequest: func(request *http.Request) {
+				request.Header.Set("X-Correlation-ID", "abc-123")
+				request.Header.Set("X-Request-ID", "ignored")
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, "abc-123", recorder.Header().Get("X-Correlation-ID"))
+				require.Empty(t, recorder.Header().Get("X-Request-ID"))
+			},
+		},
+		{
+			name:    "FallbackHeader",
+			headers: []string{"X-Correlation-ID", "traceparent"},
+			setupRequest: func(request *http.Request) {
+				request.Header.Set("traceparent", traceparent)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				// the ID is echoed under the first header, whichever it was read from
+				require.Equal(t, traceparent, recorder.Header().Get("X-Correlation-ID"))
+				require.Empty(t, recorder.Header().Get("traceparent"))
+			},
+		},
+		{
+			name:    "FirstHeaderWins",
+			headers: []string{"X-Correlation-ID", "X-Request-ID"},
+			setupRequest: func(request *http.Request) {
+				request.Header.Set("X-Request-ID", "second")
+				request.Header.Set("X-Correlation-ID", "first")
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, "first", recorder.Header().Get("X-Correlation-ID"))
+			},
+		},
+		{
+			name:         "Generated",
+			setupRequest: func(request *http.Request) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Len(t, recorder.Header().Get("X-Request-ID"), 32)
+			},
+		},
+		{
+			name: "InvalidReplaced",
+			setupRequest: func(request *http.Request) {
+				request.Header.Set("X-Request-ID", strings.Repeat("a", maxRequestIDLength+1))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Len(t, recorder.Header().Get("X-Request-ID"), 32)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			server := NewServer(util.Config{RequestIDHeaders: tc.headers}, store)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, "/no-such-route", nil)
+			require.NoError(t, err)
+			tc.setupRequest(request)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRequestIDLogged(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := NewServer(util.Config{LogRequestBodies: true}, mockdb.NewMockStore(ctrl))
+	var logged bytes.Buffer
+	server.bodyLogger = log.New(&logged, "", 0)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "/accounts", strings.NewReader(`{}`))
+	require.NoError(t, err)
+	request.Header.Set("X-Request-ID", "abc-123")
+
+	server.router.ServeHTTP(recorder, request)
+	require.Contains(t, logged.String(), "abc-123 POST /accounts body: {}")
+}
+
+func TestValidRequestID(t *testing.T) {
+	require.True(t, validRequestID("abc-123"))
+	require.False(t, validRequestID(""))
+	require.False(t, validRequestID("abc\n123"))
+	require.False(t, validRequestID("abcé"))
+	require.False(t, validRequestID(strings.Repeat("a", maxRequestIDLength+1)))
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+)
+
+type scheduleTransferRequest struct {
+	FromAccountID int64     `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID   int64     `json:"to_account_id" binding:"required,min=1"`
+	Amount        int64     `json:"amount" binding:"required,gt=0"`
+	Currency      string    `json:"currency" binding:"required"`
+	RunAt         time.Time `json:"run_at" binding:"required"`
+}
+
+// scheduledTransferResponse is a scheduled transfer as exposed to clients
+type scheduledTransferResponse struct {
+	ID            int64     `json:"id"`
+	FromAccountID int64     `json:"from_account_id"`
+	ToAccountID   int64     `json:"to_account_id"`
+	Amount        int64     `json:"amount"`
+	Currency      string    `json:"currency"`
+	RunAt         time.Time `json:"run_at"`
+	Status        string    `json:"status"`
+	FailureReason string    `json:"failure_reason,omitempty"`
+	TransferID    *int64    `json:"transfer_id,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func newScheduledTransferResponse(scheduled db.ScheduledTransfer) scheduledTransferResponse {
+	rsp := scheduledTransferResponse{
+		ID:            scheduled.ID,
+		FromAccountID: scheduled.FromAccountID,
+		ToAccountID:   scheduled.ToAccountID,
+		Amount:        scheduled.Amount,
+		Currency:      scheduled.Currency,
+		RunAt:         scheduled.RunAt,
+		Status:        scheduled.Status,
+		FailureReason: scheduled.FailureReason,
+		CreatedAt:     scheduled.CreatedAt,
+	}
+	if scheduled.TransferID.Valid {
+		rsp.TransferID = &scheduled.TransferID.Int64
+	}
+	return rsp
+}
+
+// scheduleTransfer godoc
+// @Summary  Schedule a transfer to run at a later time
+// @Tags     transfers
+// @Accept   json
+// @Produce  json
+// @Param    request  body      scheduleTransferRequest  true  "Transfer to schedule"
+// @Success  200      {object}  scheduledTransferResponse
+// @Failure  400      {object}  apiError
+// @Failure  404      {object}  apiError
+// @Failure  500      {object}  apiError
+// @Router   /transfers/schedule [post]
+func (server *Server) scheduleTransfer(ctx *gin.Context) {
+	var req scheduleTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	if !req.RunAt.After(time.Now()) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errRunAtNotInFuture))
+		return
+	}
+
+	transfer := transferRequest{
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		Currency:      req.Currency,
+	}
+	if serr := server.checkNoConfirmation(transfer); serr != nil {
+		serr.write(ctx)
+		return
+	}
+	if !server.validTransfer(ctx, transfer) {
+		return
+	}
+
+	scheduled, err := server.store.CreateScheduledTransfer(ctx.Request.Context(), db.CreateScheduledTransferParams{
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		Currency:      req.Currency,
+		RunAt:         req.RunAt,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrConstraintViolation) {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newScheduledTransferResponse(scheduled))
+}
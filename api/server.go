@@ -1,32 +1,141 @@
 package api
 
 import (
+	"log"
+	"os"
+
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
 	db "github.com/khuongkd/simplebank/db/sqlc"
+	_ "github.com/khuongkd/simplebank/docs"
+	"github.com/khuongkd/simplebank/internal/tracing"
+	"github.com/khuongkd/simplebank/util"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	swaggerFiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Server serves HTTP requests for banking service.
 type Server struct {
-	store  db.Store
-	router *gin.Engine
+	config        util.Config
+	store         db.Store
+	router        *gin.Engine
+	transferLocks *accountLocks
+	configWatcher *util.ConfigWatcher
+	bodyLogger    *log.Logger
+	maintenance   *maintenanceSwitch
+	loadShedder   *loadShedder
+	tracer        trace.Tracer
+
+	beforeCreateAccount BeforeCreateAccountFunc
 }
 
-func NewServer(store db.Store) *Server {
-	server := &Server{store: store}
+func NewServer(config util.Config, store db.Store) *Server {
+	server := &Server{
+		config:        config,
+		store:         store,
+		transferLocks: newAccountLocks(),
+		bodyLogger:    log.New(os.Stderr, "[body] ", log.LstdFlags),
+		maintenance:   newMaintenanceSwitch(config.MaintenanceMode),
+		loadShedder:   newLoadShedder(),
+		tracer:        otel.Tracer(tracing.InstrumentationName),
+	}
 	router := gin.Default()
 
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(requestFieldName)
+	}
+
+	router.Use(server.tracingMiddleware())
+	router.Use(requestIDMiddleware(config.RequestIDHeaderNames()))
+	router.Use(server.loadShedMiddleware())
+	router.Use(server.timeoutMiddleware())
+	router.Use(server.bodyLogMiddleware())
+	router.Use(server.maintenanceMiddleware())
+	router.Use(gzipMiddleware(config.GzipMinSize))
+	router.Use(server.moneyMiddleware())
+
 	router.POST("/accounts", server.createAccount)
 	router.GET("/account/:id", server.getAccount)
 	router.GET("/accounts", server.listAccount)
+	router.GET("/accounts/by-number/:number", server.getAccountByNumber)
+	router.GET("/accounts/:id/statement.pdf", server.getAccountStatementPDF)
+	router.GET("/accounts/:id/balance-at", server.getBalanceAt)
+	router.GET("/accounts/:id/balance-history", server.getBalanceHistory)
+	router.GET("/accounts/:id/spending", server.getSpending)
+	router.POST("/accounts/:id/sweep", server.sweepAccount)
+	router.POST("/accounts/:id/convert", server.convertSubBalance)
+	router.GET("/accounts/:id/whitelist", server.getWhitelist)
+	router.PATCH("/accounts/:id", server.patchAccount)
+	router.PUT("/accounts/:id/whitelist", server.setWhitelistEnabled)
+	router.POST("/accounts/:id/whitelist/destinations", server.addWhitelistDestination)
+	router.DELETE("/accounts/:id/whitelist/destinations/:destination_id", server.removeWhitelistDestination)
+	router.POST("/accounts/:id/transfer-ownership", server.requestOwnershipTransfer)
+	router.POST("/ownership-requests/:id/accept", server.acceptOwnershipTransfer)
+
+	router.POST("/transfers", server.createTransfer)
+	router.POST("/transfers/batch", server.createBatchTransfer)
+	router.POST("/transfers/simulate", server.simulateTransfers)
+	router.POST("/transfers/status", server.getTransferStatuses)
+	router.POST("/transfers/schedule", server.scheduleTransfer)
+	router.POST("/transfers/authorize", server.authorizeTransfer)
+	router.POST("/transfers/capture", server.captureTransfer)
+	router.POST("/transfers/void", server.voidTransfer)
+	router.POST("/transfers/:id/confirm", server.confirmTransfer)
+	router.GET("/transfers/:id/receipt", server.getTransferReceipt)
+
+	router.GET("/currencies", server.listCurrencies)
+	router.GET("/capabilities", server.getCapabilities)
+
+	// admin routes are meant for operators; restrict them to banker/admin roles once authentication exists
+	admin := router.Group("/admin")
+	admin.GET("/db-stats", server.getDBStats)
+	admin.GET("/accounts/dormant", server.listDormantAccounts)
+	admin.GET("/accounts/frozen", server.listFrozenAccounts)
+	admin.GET("/accounts/top", server.listTopAccounts)
+	admin.GET("/accounts/export", server.exportAccounts)
+	admin.GET("/accounts/:id/reconcile", server.reconcileAccount)
+	admin.GET("/reconcile/discrepancies", server.listBalanceDiscrepancies)
+	admin.GET("/treasury", server.getTreasury)
+	admin.POST("/accounts/:id/convert-currency", server.convertAccountCurrency)
+	admin.POST("/accounts/:id/adjust", server.adjustAccountBalance)
+	admin.GET("/accounts/:id/adjustments", server.listAccountAdjustments)
+	admin.GET("/accounts/:id/audit-log", server.listAccountAuditLog)
+	admin.GET("/accounts/:id/counterparties", server.listCounterparties)
+	admin.PATCH("/accounts/:id", server.bankerPatchAccount)
+	admin.PATCH("/accounts/:id/min-balance", server.setAccountMinBalance)
+	admin.GET("/events", server.listEvents)
+	admin.GET("/maintenance", server.getMaintenance)
+	admin.PUT("/maintenance", server.setMaintenance)
+
+	if config.EnableSwagger {
+		router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	}
+	if config.QueryMetrics {
+		router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	}
 
 	server.router = router
 	return server
 }
 
-func (server *Server) Start(address string) error {
-	return server.router.Run(address)
+// UseConfigWatcher makes handlers read the reload-safe settings from watcher,
+// so they follow changes to the config file without a restart
+func (server *Server) UseConfigWatcher(watcher *util.ConfigWatcher) {
+	server.configWatcher = watcher
 }
 
-func errorResponse(err error) gin.H {
-	return gin.H{"error": err.Error()}
+// currentConfig returns the config a request should use
+func (server *Server) currentConfig() *util.Config {
+	if server.configWatcher != nil {
+		return server.configWatcher.Config()
+	}
+	return &server.config
+}
+
+func (server *Server) Start(address string) error {
+	return server.router.Run(address)
 }
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+)
+
+// spendingReport breaks down the money sent from an account by the category of the transfers.
+// Transfers sent without a category are totalled under an empty category.
+type spendingReport struct {
+	AccountID  int64                      `json:"account_id"`
+	Currency   string                     `json:"currency"`
+	Total      int64                      `json:"total"`
+	Categories []db.SpendingByCategoryRow `json:"categories"`
+}
+
+type getSpendingURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type getSpendingQuery struct {
+	Owner string    `form:"owner" binding:"required"`
+	From  time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To    time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+// getSpending godoc
+// @Summary  Break down the spending of an account by category
+// @Description  Totals the transfers sent from the account in [from, to) per category, largest first.
+// @Description  Only the owner of the account may get the report.
+// @Tags     accounts
+// @Produce  json
+// @Param    id     path      int     true   "Account ID"
+// @Param    owner  query     string  true   "Owner of the account"
+// @Param    from   query     string  false  "RFC 3339 time of the first transfer included"
+// @Param    to     query     string  false  "RFC 3339 time before which transfers are included"
+// @Success  200    {object}  spendingReport
+// @Failure  400    {object}  apiError
+// @Failure  403    {object}  apiError
+// @Failure  404    {object}  apiError
+// @Failure  500    {object}  apiError
+// @Router   /accounts/{id}/spending [get]
+func (server *Server) getSpending(ctx *gin.Context) {
+	var uri getSpendingURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	var query getSpendingQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+	if query.To.IsZero() {
+		query.To = statementEnd
+	}
+	if !query.From.Before(query.To) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errInvalidStatementRange))
+		return
+	}
+
+	account, err := server.store.GetAccount(ctx.Request.Context(), uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(errAccountNotFound))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if account.Owner != query.Owner {
+		ctx.JSON(http.StatusForbidden, errorResponse(db.ErrAccountOwnerMismatch))
+		return
+	}
+
+	rows, err := server.store.SpendingByCategory(ctx.Request.Context(), db.SpendingByCategoryParams{
+		AccountID: account.ID,
+		FromTime:  query.From,
+		ToTime:    query.To,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	report := spendingReport{
+		AccountID:  account.ID,
+		Currency:   account.Currency,
+		Categories: []db.SpendingByCategoryRow{},
+	}
+	for _, row := range rows {
+		report.Total += row.Total
+		report.Categories = append(report.Categories, row)
+	}
+	ctx.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,224 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-pdf/fpdf"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+)
+
+// accountStatement holds the data rendered into a PDF statement
+type accountStatement struct {
+	Account db.Account
+	Entries []db.Entry
+	// TotalEntries counts the entries of the whole date range, of which Entries may only be a page
+	TotalEntries int64
+	// NextCursor continues the statement after the last entry of the page; zero when there is nothing left
+	NextCursor int64
+	// FeesPaid is the total of the fees charged on transfers from the account
+	FeesPaid    int64
+	GeneratedAt time.Time
+}
+
+// statementEnd stands for the end of a statement requested without one
+var statementEnd = time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+// statementTooLarge details a statement refused for having more entries than MaxStatementEntries
+type statementTooLarge struct {
+	TotalEntries int64 `json:"total_entries"`
+	MaxEntries   int32 `json:"max_entries"`
+}
+
+// buildAccountStatement gathers the account, its entries created in [from, to) with an id above cursor
+// and the transfer fees it paid for a PDF statement, archived entries included.
+// With paged set, at most MaxStatementEntries entries are returned along with the cursor of the next page;
+// otherwise a statement with more entries than that is refused with errStatementTooLarge.
+func (server *Server) buildAccountStatement(ctx context.Context, accountID int64, from, to time.Time, cursor int64, paged bool) (accountStatement, *statusError) {
+	account, err := server.store.GetAccount(ctx, accountID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return accountStatement{}, newStatusError(http.StatusNotFound, errAccountNotFound)
+		}
+		return accountStatement{}, newStatusError(http.StatusInternalServerError, err)
+	}
+
+	total, err := server.store.CountStatementEntries(ctx, db.CountStatementEntriesParams{
+		AccountID: accountID,
+		FromTime:  from,
+		ToTime:    to,
+	})
+	if err != nil {
+		return accountStatement{}, newStatusError(http.StatusInternalServerError, err)
+	}
+
+	max := server.currentConfig().MaxStatementEntries
+	fetch := int32(math.MaxInt32)
+	if max > 0 {
+		if !paged && total > int64(max) {
+			serr := newStatusError(http.StatusBadRequest, errStatementTooLarge)
+			serr.rsp.Details = statementTooLarge{TotalEntries: total, MaxEntries: max}
+			return accountStatement{}, serr
+		}
+		// one entry more than the page holds tells whether another page follows
+		if max < math.MaxInt32 {
+			fetch = max + 1
+		}
+	}
+
+	entries, err := server.store.ListStatementEntries(ctx, db.ListStatementEntriesParams{
+		AccountID: accountID,
+		FromTime:  from,
+		ToTime:    to,
+		AfterID:   cursor,
+		Limit:     fetch,
+	})
+	if err != nil {
+		return accountStatement{}, newStatusError(http.StatusInternalServerError, err)
+	}
+
+	feesPaid, err := server.store.SumTransferFeesByAccount(ctx, accountID)
+	if err != nil {
+		return accountStatement{}, newStatusError(http.StatusInternalServerError, err)
+	}
+
+	statement := accountStatement{
+		Account:      account,
+		Entries:      entries,
+		TotalEntries: total,
+		FeesPaid:     feesPaid,
+		GeneratedAt:  time.Now(),
+	}
+	if max > 0 && len(entries) > int(max) {
+		statement.Entries = entries[:max]
+		statement.NextCursor = entries[max-1].ID
+	}
+	return statement, nil
+}
+
+type getAccountStatementRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type getAccountStatementQuery struct {
+	From time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To   time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	// Cursor pages through the statement; zero starts at its first entry
+	Cursor *int64 `form:"cursor" binding:"omitempty,min=0"`
+}
+
+// getAccountStatementPDF godoc
+// @Summary  Download the account statement as a PDF
+// @Description  A statement with more entries than the configured maximum is refused unless the date range is narrowed or a cursor is given.
+// @Description  Pass cursor=0 to get the first page; the X-Next-Cursor header holds the cursor of the next one while entries are left.
+// @Description  X-Total-Entries counts the entries of the whole date range.
+// @Tags     accounts
+// @Produce  application/pdf
+// @Param    id      path      int     true   "Account ID"
+// @Param    from    query     string  false  "RFC 3339 time of the first entry included"
+// @Param    to      query     string  false  "RFC 3339 time before which entries are included"
+// @Param    cursor  query     int     false  "Cursor of the page to get"
+// @Success  200     {file}    binary
+// @Header   200     {integer}  X-Total-Entries  "Entries in the date range"
+// @Header   200     {integer}  X-Next-Cursor    "Cursor of the next page"
+// @Failure  400     {object}  apiError
+// @Failure  404     {object}  apiError
+// @Failure  500     {object}  apiError
+// @Router   /accounts/{id}/statement.pdf [get]
+func (server *Server) getAccountStatementPDF(ctx *gin.Context) {
+	var req getAccountStatementRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	var query getAccountStatementQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+	if query.To.IsZero() {
+		query.To = statementEnd
+	}
+	if !query.From.Before(query.To) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errInvalidStatementRange))
+		return
+	}
+
+	var cursor int64
+	if query.Cursor != nil {
+		cursor = *query.Cursor
+	}
+	statement, serr := server.buildAccountStatement(ctx.Request.Context(), req.ID, query.From, query.To, cursor, query.Cursor != nil)
+	if serr != nil {
+		serr.write(ctx)
+		return
+	}
+
+	pdf := newStatementPDF(statement)
+	if err := pdf.Error(); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.Header("Content-Type", "application/pdf")
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="statement-%d.pdf"`, req.ID))
+	ctx.Header("X-Total-Entries", strconv.FormatInt(statement.TotalEntries, 10))
+	if statement.NextCursor != 0 {
+		ctx.Header("X-Next-Cursor", strconv.FormatInt(statement.NextCursor, 10))
+	}
+	ctx.Status(http.StatusOK)
+	if err := pdf.Output(ctx.Writer); err != nil {
+		ctx.Error(err)
+	}
+}
+
+// newStatementPDF lays out the statement as a single PDF document with a header,
+// the account details and a table of the account entries
+func newStatementPDF(statement accountStatement) *fpdf.Fpdf {
+	account := statement.Account
+
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle(fmt.Sprintf("Account Statement %d", account.ID), false)
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.Cell(0, 10, "Simple Bank - Account Statement")
+	pdf.Ln(12)
+
+	pdf.SetFont("Helvetica", "", 11)
+	pdf.Cell(0, 6, fmt.Sprintf("Account ID: %d", account.ID))
+	pdf.Ln(6)
+	pdf.Cell(0, 6, fmt.Sprintf("Owner: %s", account.Owner))
+	pdf.Ln(6)
+	pdf.Cell(0, 6, fmt.Sprintf("Currency: %s", account.Currency))
+	pdf.Ln(6)
+	pdf.Cell(0, 6, fmt.Sprintf("Balance: %d %s", account.Balance, account.Currency))
+	pdf.Ln(6)
+	pdf.Cell(0, 6, fmt.Sprintf("Fees paid: %d %s", statement.FeesPaid, account.Currency))
+	pdf.Ln(6)
+	pdf.Cell(0, 6, fmt.Sprintf("Entries: %d of %d", len(statement.Entries), statement.TotalEntries))
+	pdf.Ln(6)
+	pdf.Cell(0, 6, fmt.Sprintf("Generated at: %s", statement.GeneratedAt.Format(time.RFC3339)))
+	pdf.Ln(10)
+
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.CellFormat(30, 7, "Entry ID", "1", 0, "L", false, 0, "")
+	pdf.CellFormat(70, 7, "Date", "1", 0, "L", false, 0, "")
+	pdf.CellFormat(50, 7, "Amount", "1", 1, "R", false, 0, "")
+
+	pdf.SetFont("Helvetica", "", 11)
+	for _, entry := range statement.Entries {
+		pdf.CellFormat(30, 7, fmt.Sprintf("%d", entry.ID), "1", 0, "L", false, 0, "")
+		pdf.CellFormat(70, 7, entry.CreatedAt.Format(time.RFC3339), "1", 0, "L", false, 0, "")
+		pdf.CellFormat(50, 7, fmt.Sprintf("%d", entry.Amount), "1", 1, "R", false, 0, "")
+	}
+
+	return pdf
+}
@@ -0,0 +1,293 @@
+package api
+
+import (
+	"bytes"
+	"compress/zlib"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khuongkd/simplebank/db/mock"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+	"github.com/khuongkd/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAccountStatementPDFAPI(t *testing.T) {
+	account := randomAccount()
+	entries := []db.Entry{
+		{ID: 1, AccountID: account.ID, Amount: util.RandomInt(1001, 2000)},
+		randomEntry(account.ID),
+		randomEntry(account.ID),
+	}
+	entries[1].ID, entries[2].ID = 2, 3
+	feesPaid := util.RandomInt(1, 100)
+	from := time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2022, time.February, 1, 0, 0, 0, 0, time.UTC)
+
+	countArg := db.CountStatementEntriesParams{AccountID: account.ID, ToTime: statementEnd}
+	listArg := db.ListStatementEntriesParams{AccountID: account.ID, ToTime: statementEnd, Limit: math.MaxInt32}
+	expectStatement := func(store *mockdb.MockStore, total int64, listArg db.ListStatementEntriesParams, listed []db.Entry) {
+		store.EXPECT().
+			GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+			Times(1).
+			Return(account, nil)
+		store.EXPECT().
+			CountStatementEntries(gomock.Any(), gomock.Eq(db.CountStatementEntriesParams{
+				AccountID: listArg.AccountID,
+				FromTime:  listArg.FromTime,
+				ToTime:    listArg.ToTime,
+			})).
+			Times(1).
+			Return(total, nil)
+		store.EXPECT().
+			ListStatementEntries(gomock.Any(), gomock.Eq(listArg)).
+			Times(1).
+			Return(listed, nil)
+		store.EXPECT().
+			SumTransferFeesByAccount(gomock.Any(), gomock.Eq(account.ID)).
+			Times(1).
+			Return(feesPaid, nil)
+	}
+
+	testCases := []struct {
+		name          string
+		accountID     int64
+		query         string
+		maxEntries    int32
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			accountID: account.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectStatement(store, 3, listArg, entries)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "application/pdf", recorder.Header().Get("Content-Type"))
+				require.Equal(t,
+					fmt.Sprintf(`attachment; filename="statement-%d.pdf"`, account.ID),
+					recorder.Header().Get("Content-Disposition"),
+				)
+				require.Equal(t, "3", recorder.Header().Get("X-Total-Entries"))
+				require.Empty(t, recorder.Header().Get("X-Next-Cursor"))
+
+				body := recorder.Body.Bytes()
+				require.NotEmpty(t, body)
+				require.True(t, bytes.HasPrefix(body, []byte("%PDF-")))
+				require.Contains(t, string(body), "%%EOF")
+
+				text := pdfStreamText(t, body)
+				require.Contains(t, text, "Account Statement")
+				require.Contains(t, text, fmt.Sprintf("Owner: %s", account.Owner))
+				for _, entry := range entries {
+					require.Contains(t, text, fmt.Sprintf("(%d)", entry.Amount))
+				}
+				require.Contains(t, text, fmt.Sprintf("Fees paid: %d %s", feesPaid, account.Currency))
+				require.Contains(t, text, "Entries: 3 of 3")
+			},
+		},
+		{
+			name:       "WithinCap",
+			accountID:  account.ID,
+			query:      "from=2022-01-01T00:00:00Z&to=2022-02-01T00:00:00Z",
+			maxEntries: 3,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListStatementEntriesParams{AccountID: account.ID, FromTime: from, ToTime: to, Limit: 4}
+				expectStatement(store, 3, arg, entries)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "3", recorder.Header().Get("X-Total-Entries"))
+				require.Empty(t, recorder.Header().Get("X-Next-Cursor"))
+			},
+		},
+		{
+			name:       "OverCap",
+			accountID:  account.ID,
+			maxEntries: 2,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().CountStatementEntries(gomock.Any(), gomock.Eq(countArg)).Times(1).Return(int64(3), nil)
+				store.EXPECT().ListStatementEntries(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+				var rsp struct {
+					Code    ErrorCode         `json:"code"`
+					Details statementTooLarge `json:"details"`
+				}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, ErrCodeStatementTooLarge, rsp.Code)
+				require.Equal(t, statementTooLarge{TotalEntries: 3, MaxEntries: 2}, rsp.Details)
+			},
+		},
+		{
+			name:       "FirstPage",
+			accountID:  account.ID,
+			query:      "cursor=0",
+			maxEntries: 2,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListStatementEntriesParams{AccountID: account.ID, ToTime: statementEnd, Limit: 3}
+				expectStatement(store, 3, arg, entries)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "3", recorder.Header().Get("X-Total-Entries"))
+				require.Equal(t, "2", recorder.Header().Get("X-Next-Cursor"))
+
+				text := pdfStreamText(t, recorder.Body.Bytes())
+				require.Contains(t, text, "Entries: 2 of 3")
+				require.NotContains(t, text, fmt.Sprintf("(%d)", entries[2].Amount))
+			},
+		},
+		{
+			name:       "NextPage",
+			accountID:  account.ID,
+			query:      "cursor=2",
+			maxEntries: 2,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListStatementEntriesParams{AccountID: account.ID, ToTime: statementEnd, AfterID: 2, Limit: 3}
+				expectStatement(store, 3, arg, entries[2:])
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "3", recorder.Header().Get("X-Total-Entries"))
+				require.Empty(t, recorder.Header().Get("X-Next-Cursor"))
+
+				text := pdfStreamText(t, recorder.Body.Bytes())
+				require.Contains(t, text, "Entries: 1 of 3")
+				require.Contains(t, text, fmt.Sprintf("(%d)", entries[2].Amount))
+			},
+		},
+		{
+			name:      "InvalidRange",
+			accountID: account.ID,
+			query:     "from=2022-02-01T00:00:00Z&to=2022-01-01T00:00:00Z",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeInvalidRequest)
+			},
+		},
+		{
+			name:      "NotFound",
+			accountID: account.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().
+					ListStatementEntries(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "InternalError",
+			accountID: account.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					CountStatementEntries(gomock.Any(), gomock.Eq(countArg)).
+					Times(1).
+					Return(int64(3), nil)
+				store.EXPECT().
+					ListStatementEntries(gomock.Any(), gomock.Eq(listArg)).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidID",
+			accountID: 0,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			server := newTestServer(t, store)
+			server.config.MaxStatementEntries = tc.maxEntries
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%d/statement.pdf?%s", tc.accountID, tc.query)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func randomEntry(accountID int64) db.Entry {
+	return db.Entry{
+		ID:        util.RandomInt(1, 1000),
+		AccountID: accountID,
+		Amount:    util.RandomInt(-1000, 1000),
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+}
+
+// pdfStreamText inflates every compressed stream of the document and returns their concatenated content
+func pdfStreamText(t *testing.T, doc []byte) string {
+	var text bytes.Buffer
+	rest := doc
+	for {
+		start := bytes.Index(rest, []byte("stream\n"))
+		if start < 0 {
+			break
+		}
+		rest = rest[start+len("stream\n"):]
+
+		end := bytes.Index(rest, []byte("endstream"))
+		require.True(t, end >= 0)
+
+		reader, err := zlib.NewReader(bytes.NewReader(rest[:end]))
+		if err == nil {
+			data, err := ioutil.ReadAll(reader)
+			if err == nil {
+				text.Write(data)
+			}
+		}
+		rest = rest[end+len("endstream"):]
+	}
+
+	return text.String()
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khuongkd/simplebank/db/mock"
+	"github.com/khuongkd/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSwaggerDocs(t *testing.T) {
+	testCases := []struct {
+		name          string
+		enableSwagger bool
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:          "Enabled",
+			enableSwagger: true,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var spec struct {
+					Paths map[string]interface{} `json:"paths"`
+				}
+				err := json.Unmarshal(recorder.Body.Bytes(), &spec)
+				require.NoError(t, err)
+				for _, path := range []string{
+					"/accounts",
+					"/account/{id}",
+					"/accounts/by-number/{number}",
+					"/accounts/{id}/statement.pdf",
+					"/transfers",
+				} {
+					require.Contains(t, spec.Paths, path)
+				}
+			},
+		},
+		{
+			name:          "Disabled",
+			enableSwagger: false,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			server := NewServer(util.Config{EnableSwagger: tc.enableSwagger}, store)
+			recorder := httptest.NewRecorder()
+
+			// the swagger handler routes on RequestURI, which only server-side requests carry
+			request := httptest.NewRequest(http.MethodGet, "/swagger/doc.json", nil)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
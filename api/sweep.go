@@ -0,0 +1,76 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+)
+
+type sweepAccountURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type sweepAccountRequest struct {
+	ToAccountID int64  `json:"to_account_id" binding:"required,min=1"`
+	Owner       string `json:"owner" binding:"required"`
+}
+
+// sweepAccount godoc
+// @Summary  Move the whole balance of an account to another account of the same owner
+// @Tags     accounts
+// @Accept   json
+// @Produce  json
+// @Param    id       path      int                  true  "Source account ID"
+// @Param    request  body      sweepAccountRequest  true  "Destination account and owner of both accounts"
+// @Success  200      {object}  transferTxResponse
+// @Failure  400      {object}  apiError
+// @Failure  403      {object}  apiError
+// @Failure  404      {object}  apiError
+// @Failure  429      {object}  apiError
+// @Failure  500      {object}  apiError
+// @Failure  503      {object}  apiError
+// @Router   /accounts/{id}/sweep [post]
+func (server *Server) sweepAccount(ctx *gin.Context) {
+	var uri sweepAccountURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	var req sweepAccountRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	if uri.ID == req.ToAccountID {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errSameAccount))
+		return
+	}
+
+	unlock, ok := server.lockAccount(ctx, uri.ID)
+	if !ok {
+		return
+	}
+	defer unlock()
+
+	result, err := server.store.SweepOwnAccountsTx(ctx.Request.Context(), uri.ID, req.ToAccountID, req.Owner)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			ctx.JSON(http.StatusNotFound, errorResponse(errAccountNotFound))
+		case errors.Is(err, db.ErrAccountOwnerMismatch):
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+		case errors.Is(err, db.ErrCurrencyMismatch), errors.Is(err, db.ErrNothingToSweep):
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		default:
+			transferTxError(err).write(ctx)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, server.newTransferTxResponse(result))
+}
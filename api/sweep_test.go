@@ -0,0 +1,254 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khuongkd/simplebank/db/mock"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSweepAccountAPI(t *testing.T) {
+	account1 := randomAccount()
+	account2 := randomAccount()
+	account2.Owner = account1.Owner
+
+	testCases := []struct {
+		name          string
+		accountID     int64
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			accountID: account1.ID,
+			body: gin.H{
+				"to_account_id": account2.ID,
+				"owner":         account1.Owner,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SweepOwnAccountsTx(gomock.Any(), gomock.Eq(account1.ID), gomock.Eq(account2.ID), gomock.Eq(account1.Owner)).
+					Times(1).
+					Return(db.TransferTxResult{
+						Transfer: db.Transfer{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: account1.Balance},
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var result db.TransferTxResult
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+				require.Equal(t, account1.Balance, result.Transfer.Amount)
+			},
+		},
+		{
+			name:      "OtherOwner",
+			accountID: account1.ID,
+			body: gin.H{
+				"to_account_id": account2.ID,
+				"owner":         "someone-else",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SweepOwnAccountsTx(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrAccountOwnerMismatch)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeAccountOwnerMismatch)
+			},
+		},
+		{
+			name:      "AccountFrozen",
+			accountID: account1.ID,
+			body: gin.H{
+				"to_account_id": account2.ID,
+				"owner":         account1.Owner,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SweepOwnAccountsTx(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrAccountFrozen)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeAccountFrozen)
+			},
+		},
+		{
+			name:      "CurrencyMismatch",
+			accountID: account1.ID,
+			body: gin.H{
+				"to_account_id": account2.ID,
+				"owner":         account1.Owner,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SweepOwnAccountsTx(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrCurrencyMismatch)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeCurrencyMismatch)
+			},
+		},
+		{
+			name:      "NothingToSweep",
+			accountID: account1.ID,
+			body: gin.H{
+				"to_account_id": account2.ID,
+				"owner":         account1.Owner,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SweepOwnAccountsTx(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrNothingToSweep)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeNothingToSweep)
+			},
+		},
+		{
+			name:      "NotFound",
+			accountID: account1.ID,
+			body: gin.H{
+				"to_account_id": account2.ID,
+				"owner":         account1.Owner,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SweepOwnAccountsTx(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeAccountNotFound)
+			},
+		},
+		{
+			name:      "SameAccount",
+			accountID: account1.ID,
+			body: gin.H{
+				"to_account_id": account1.ID,
+				"owner":         account1.Owner,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SweepOwnAccountsTx(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeInvalidRequest)
+			},
+		},
+		{
+			name:      "MissingOwner",
+			accountID: account1.ID,
+			body: gin.H{
+				"to_account_id": account2.ID,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SweepOwnAccountsTx(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeInvalidRequest)
+			},
+		},
+		{
+			name:      "BelowMinimumBalance",
+			accountID: account1.ID,
+			body: gin.H{
+				"to_account_id": account2.ID,
+				"owner":         account1.Owner,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SweepOwnAccountsTx(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrBelowMinimumBalance)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeMinimumBalance)
+			},
+		},
+		{
+			name:      "Deadlock",
+			accountID: account1.ID,
+			body: gin.H{
+				"to_account_id": account2.ID,
+				"owner":         account1.Owner,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SweepOwnAccountsTx(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrDeadlock)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+				require.Equal(t, deadlockRetryAfter, recorder.Header().Get("Retry-After"))
+				requireErrorCode(t, recorder, ErrCodeDeadlock)
+			},
+		},
+		{
+			name:      "InternalError",
+			accountID: account1.ID,
+			body: gin.H{
+				"to_account_id": account2.ID,
+				"owner":         account1.Owner,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SweepOwnAccountsTx(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeInternal)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/accounts/%d/sweep", tc.accountID)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+var errRequestTimeout = errors.New("request took too long to complete")
+
+// timeoutMiddleware bounds every request by the timeout configured for its route.
+// The handler learns about the deadline from the context of its request, which cancels its queries.
+// Its response is held until it returns, so that a request which ran out of time gets a clean 503
+// rather than the error of whatever was cancelled. A handler that flushes, such as the export,
+// streams from then on and keeps what it sent.
+func (server *Server) timeoutMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		timeout := server.currentConfig().TimeoutFor(ctx.FullPath())
+		if timeout <= 0 {
+			ctx.Next()
+			return
+		}
+
+		reqCtx, cancel := context.WithTimeout(ctx.Request.Context(), timeout)
+		defer cancel()
+		ctx.Request = ctx.Request.WithContext(reqCtx)
+
+		original := ctx.Writer
+		writer := &timeoutWriter{ResponseWriter: original, header: make(http.Header)}
+		ctx.Writer = writer
+		// restored before a panic reaches the recovery middleware, so that its response is not held
+		defer func() { ctx.Writer = original }()
+
+		ctx.Next()
+
+		switch {
+		case writer.streaming:
+		case errors.Is(reqCtx.Err(), context.DeadlineExceeded):
+			writeTimeoutResponse(original)
+		default:
+			writer.copyTo(original)
+		}
+	}
+}
+
+// writeTimeoutResponse reports a timed out request directly on w
+func writeTimeoutResponse(w gin.ResponseWriter) {
+	body, _ := json.Marshal(errorResponse(errRequestTimeout))
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusServiceUnavailable)
+	w.Write(body)
+}
+
+// timeoutWriter holds the response of a request until it is known to have finished in time.
+// Once the handler flushes, the held response is sent and the writer passes everything through.
+type timeoutWriter struct {
+	gin.ResponseWriter
+	header    http.Header
+	body      bytes.Buffer
+	status    int
+	streaming bool
+}
+
+func (w *timeoutWriter) Header() http.Header {
+	if w.streaming {
+		return w.ResponseWriter.Header()
+	}
+	return w.header
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	if w.streaming {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if code > 0 && w.status == 0 {
+		w.status = code
+	}
+}
+
+func (w *timeoutWriter) WriteHeaderNow() {
+	if w.streaming {
+		w.ResponseWriter.WriteHeaderNow()
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	if w.streaming {
+		return w.ResponseWriter.Write(data)
+	}
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(data)
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *timeoutWriter) Status() int {
+	if w.streaming {
+		return w.ResponseWriter.Status()
+	}
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+func (w *timeoutWriter) Size() int {
+	if w.streaming {
+		return w.ResponseWriter.Size()
+	}
+	if w.status == 0 {
+		return -1
+	}
+	return w.body.Len()
+}
+
+func (w *timeoutWriter) Written() bool {
+	if w.streaming {
+		return w.ResponseWriter.Written()
+	}
+	return w.status != 0
+}
+
+// Flush commits the response: what is held is sent, and the rest streams through.
+// A request that runs out of time after that keeps the response it started.
+func (w *timeoutWriter) Flush() {
+	if !w.streaming {
+		w.streaming = true
+		w.copyTo(w.ResponseWriter)
+		w.body.Reset()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// copyTo sends the held response to dst
+func (w *timeoutWriter) copyTo(dst gin.ResponseWriter) {
+	header := dst.Header()
+	for key, values := range w.header {
+		header[key] = values
+	}
+	if w.status != 0 {
+		dst.WriteHeader(w.status)
+	}
+	if w.body.Len() > 0 {
+		dst.Write(w.body.Bytes())
+	}
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khuongkd/simplebank/db/mock"
+	"github.com/khuongkd/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeoutMiddleware(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := NewServer(util.Config{
+		RequestTimeout: 20 * time.Millisecond,
+		RouteTimeouts:  map[string]time.Duration{"/test/long": time.Second},
+	}, mockdb.NewMockStore(ctrl))
+
+	slow := func(ctx *gin.Context) {
+		select {
+		case <-time.After(100 * time.Millisecond):
+		case <-ctx.Request.Context().Done():
+		}
+		ctx.JSON(http.StatusOK, gin.H{"path": ctx.FullPath()})
+	}
+	server.router.GET("/test/long", slow)
+	server.router.GET("/test/short", slow)
+
+	// the long route has its own timeout and finishes
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/test/long", nil)
+	require.NoError(t, err)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.JSONEq(t, `{"path":"/test/long"}`, recorder.Body.String())
+
+	// the short route falls back to the global timeout and is cut off
+	recorder = httptest.NewRecorder()
+	request, err = http.NewRequest(http.MethodGet, "/test/short", nil)
+	require.NoError(t, err)
+	start := time.Now()
+	server.router.ServeHTTP(recorder, request)
+	require.Less(t, int64(time.Since(start)), int64(100*time.Millisecond))
+	require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	requireErrorCode(t, recorder, ErrCodeRequestTimeout)
+}
+
+func TestTimeoutMiddlewareDisabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := NewServer(util.Config{}, mockdb.NewMockStore(ctrl))
+	server.router.GET("/test/slow", func(ctx *gin.Context) {
+		time.Sleep(20 * time.Millisecond)
+		ctx.Status(http.StatusNoContent)
+	})
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/test/slow", nil)
+	require.NoError(t, err)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusNoContent, recorder.Code)
+}
+
+func TestTimeoutMiddlewareStreaming(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := NewServer(util.Config{RequestTimeout: 20 * time.Millisecond}, mockdb.NewMockStore(ctrl))
+	server.router.GET("/test/stream", func(ctx *gin.Context) {
+		ctx.Header("Content-Type", "application/x-ndjson")
+		ctx.Status(http.StatusOK)
+		ctx.Writer.WriteString("{\"line\":1}\n")
+		ctx.Writer.Flush()
+
+		// the stream is cut off by the deadline, not turned into a 503
+		<-ctx.Request.Context().Done()
+		ctx.Writer.WriteString("{\"line\":2}\n")
+	})
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/test/stream", nil)
+	require.NoError(t, err)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.True(t, recorder.Flushed)
+	require.Equal(t, "application/x-ndjson", recorder.Header().Get("Content-Type"))
+	require.Equal(t, "{\"line\":1}\n{\"line\":2}\n", recorder.Body.String())
+}
+
+func TestTimeoutMiddlewarePanic(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := NewServer(util.Config{RequestTimeout: time.Second}, mockdb.NewMockStore(ctrl))
+	server.router.GET("/test/panic", func(ctx *gin.Context) {
+		panic("boom")
+	})
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/test/panic", nil)
+	require.NoError(t, err)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusInternalServerError, recorder.Code)
+}
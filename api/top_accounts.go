@@ -0,0 +1,59 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+)
+
+// defaultTopAccounts is the number of accounts listed by listTopAccounts when the limit is omitted
+const defaultTopAccounts = 10
+
+type listTopAccountsRequest struct {
+	Currency string `form:"currency" binding:"required"`
+	// Limit is capped, so that a leaderboard cannot turn into a dump of every account
+	Limit int32 `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// listTopAccounts godoc
+// @Summary  List the accounts with the largest balances in a currency
+// @Description  Accounts are listed from the largest balance down, ties going to the oldest account.
+// @Tags     admin
+// @Produce  json
+// @Param    currency  query     string  true   "Currency of the accounts"
+// @Param    limit     query     int     false  "Number of accounts, at most 100; defaults to 10"
+// @Success  200       {array}   accountResponse
+// @Failure  400       {object}  apiError
+// @Failure  500       {object}  apiError
+// @Router   /admin/accounts/top [get]
+func (server *Server) listTopAccounts(ctx *gin.Context) {
+	var req listTopAccountsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	if !server.supportedCurrency(ctx, req.Currency) {
+		return
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultTopAccounts
+	}
+	accounts, err := server.store.TopAccountsByBalance(ctx.Request.Context(), db.TopAccountsByBalanceParams{
+		Currency: req.Currency,
+		Limit:    limit,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := make([]accountResponse, len(accounts))
+	for i, account := range accounts {
+		rsp[i] = server.newAccountResponse(account)
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khuongkd/simplebank/db/mock"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListTopAccountsAPI(t *testing.T) {
+	account1 := randomAccount()
+	account2 := randomAccount()
+
+	testCases := []struct {
+		name          string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: "?currency=USD&limit=2",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					TopAccountsByBalance(gomock.Any(), gomock.Eq(db.TopAccountsByBalanceParams{Currency: "USD", Limit: 2})).
+					Times(1).
+					Return([]db.Account{account1, account2}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp []accountResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Len(t, rsp, 2)
+				require.Equal(t, account1.ID, rsp[0].ID)
+				require.Equal(t, account2.ID, rsp[1].ID)
+			},
+		},
+		{
+			name:  "DefaultLimit",
+			query: "?currency=EUR",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					TopAccountsByBalance(gomock.Any(), gomock.Eq(db.TopAccountsByBalanceParams{Currency: "EUR", Limit: defaultTopAccounts})).
+					Times(1).
+					Return(nil, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.JSONEq(t, "[]", recorder.Body.String())
+			},
+		},
+		{
+			name:  "LimitTooLarge",
+			query: "?currency=USD&limit=101",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().TopAccountsByBalance(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeInvalidRequest)
+			},
+		},
+		{
+			name:  "MissingCurrency",
+			query: "?limit=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().TopAccountsByBalance(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeInvalidRequest)
+			},
+		},
+		{
+			name:  "UnsupportedCurrency",
+			query: "?currency=XYZ",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().TopAccountsByBalance(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeInvalidRequest)
+			},
+		},
+		{
+			name:  "InternalError",
+			query: "?currency=USD",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					TopAccountsByBalance(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/admin/accounts/top"+tc.query, nil)
+			require.NoError(t, err)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
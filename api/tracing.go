@@ -0,0 +1,50 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/khuongkd/simplebank/internal/tracing"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// UseTracerProvider makes the server start the spans of its requests with provider
+func (server *Server) UseTracerProvider(provider trace.TracerProvider) {
+	server.tracer = provider.Tracer(tracing.InstrumentationName)
+}
+
+// tracingMiddleware starts a span for every request, continuing the trace of the caller when it sends a traceparent.
+// The span is carried by the request context, so the store operations the handler runs are its children.
+func (server *Server) tracingMiddleware() gin.HandlerFunc {
+	propagator := propagation.TraceContext{}
+	return func(ctx *gin.Context) {
+		route := ctx.FullPath()
+		name := ctx.Request.Method
+		if route != "" {
+			name += " " + route
+		}
+
+		reqCtx := propagator.Extract(ctx.Request.Context(), propagation.HeaderCarrier(ctx.Request.Header))
+		reqCtx, span := server.tracer.Start(reqCtx, name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPMethodKey.String(ctx.Request.Method),
+				semconv.HTTPRouteKey.String(route),
+				semconv.HTTPTargetKey.String(ctx.Request.URL.Path),
+			),
+		)
+		defer span.End()
+		ctx.Request = ctx.Request.WithContext(reqCtx)
+
+		ctx.Next()
+
+		status := ctx.Writer.Status()
+		span.SetAttributes(semconv.HTTPStatusCodeKey.Int(status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
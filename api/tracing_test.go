@@ -0,0 +1,127 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khuongkd/simplebank/db/mock"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+	"github.com/khuongkd/simplebank/internal/tracing"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// observedQuery runs the observer around a query the way db.SQLStore does
+func observedQuery(ctx context.Context, observer db.QueryObserver, method string) {
+	ctx = observer.BeforeQuery(ctx, method)
+	observer.AfterQuery(ctx, method, 0, nil)
+}
+
+func TestTransferTracing(t *testing.T) {
+	account1 := randomAccount()
+	account2 := randomAccount()
+	account1.Currency = "USD"
+	account2.Currency = "USD"
+
+	const remoteTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	const remoteSpanID = "00f067aa0ba902b7"
+
+	testCases := []struct {
+		name        string
+		traceparent string
+		checkRoot   func(t *testing.T, root tracetest.SpanStub)
+	}{
+		{
+			name: "NewTrace",
+			checkRoot: func(t *testing.T, root tracetest.SpanStub) {
+				require.False(t, root.Parent.IsValid())
+			},
+		},
+		{
+			name:        "RemoteParent",
+			traceparent: "00-" + remoteTraceID + "-" + remoteSpanID + "-01",
+			checkRoot: func(t *testing.T, root tracetest.SpanStub) {
+				require.True(t, root.Parent.IsRemote())
+				require.Equal(t, remoteTraceID, root.SpanContext.TraceID().String())
+				require.Equal(t, remoteSpanID, root.Parent.SpanID().String())
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			exporter := tracetest.NewInMemoryExporter()
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+			observer := tracing.NewObserver(provider)
+
+			store := mockdb.NewMockStore(ctrl)
+			for _, account := range []db.Account{account1, account2} {
+				account := account
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).
+					DoAndReturn(func(ctx context.Context, id int64) (db.Account, error) {
+						observedQuery(ctx, observer, "GetAccount")
+						return account, nil
+					})
+			}
+			store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).
+				DoAndReturn(func(ctx context.Context, arg db.TransferTxParams) (db.TransferTxResult, error) {
+					ctx = observer.BeforeQuery(ctx, "TransferTx")
+					observedQuery(ctx, observer, "CreateTransfer")
+					observedQuery(ctx, observer, "CreateEntry")
+					observer.AfterQuery(ctx, "TransferTx", 0, nil)
+					return db.TransferTxResult{}, nil
+				})
+
+			server := newTestServer(t, store)
+			server.UseTracerProvider(provider)
+			recorder := httptest.NewRecorder()
+
+			request := newPostRequest(t, "/transfers", gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          10,
+				"currency":        "USD",
+			})
+			if tc.traceparent != "" {
+				request.Header.Set("traceparent", tc.traceparent)
+			}
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusOK, recorder.Code)
+
+			spans := map[string][]tracetest.SpanStub{}
+			for _, span := range exporter.GetSpans() {
+				spans[span.Name] = append(spans[span.Name], span)
+			}
+			require.Len(t, spans["POST /transfers"], 1)
+			root := spans["POST /transfers"][0]
+			require.Equal(t, trace.SpanKindServer, root.SpanKind)
+			tc.checkRoot(t, root)
+
+			requireChildren := func(parent tracetest.SpanStub, name string, n int) []tracetest.SpanStub {
+				require.Len(t, spans[name], n)
+				for _, child := range spans[name] {
+					require.Equal(t, parent.SpanContext.TraceID(), child.SpanContext.TraceID(), name)
+					require.Equal(t, parent.SpanContext.SpanID(), child.Parent.SpanID(), name)
+				}
+				return spans[name]
+			}
+			requireChildren(root, "GetAccount", 2)
+			transferTx := requireChildren(root, "TransferTx", 1)[0]
+			require.Equal(t, trace.SpanKindClient, transferTx.SpanKind)
+			requireChildren(transferTx, "CreateTransfer", 1)
+			requireChildren(transferTx, "CreateEntry", 1)
+			require.Len(t, exporter.GetSpans(), 6)
+		})
+	}
+}
@@ -0,0 +1,409 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+	"github.com/khuongkd/simplebank/util"
+)
+
+// deadlockRetryAfter is the number of seconds clients are asked to wait before retrying a transfer that hit a deadlock
+const deadlockRetryAfter = "1"
+
+// possibleDuplicateWarning is the Warning header sent with a likely duplicate transfer that was let through
+const possibleDuplicateWarning = `199 - "possible duplicate transfer"`
+
+type transferRequest struct {
+	FromAccountID int64  `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1"`
+	Amount        int64  `json:"amount" binding:"required,gt=0"`
+	Currency      string `json:"currency" binding:"required"`
+	// ExternalRef is the caller's own reference for the transfer; a second transfer with the same one is rejected
+	ExternalRef string `json:"external_ref" binding:"omitempty,max=64"`
+	// Category files the transfer under a spending category of the sender, such as groceries
+	Category string `json:"category" binding:"omitempty,max=32"`
+}
+
+// transferResponse is a transfer as exposed to clients, without the raw account numbers it recorded
+type transferResponse struct {
+	ID            int64 `json:"id"`
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID   int64 `json:"to_account_id"`
+	Amount        int64 `json:"amount"`
+	// charged to the source account on top of the amount
+	Fee int64 `json:"fee"`
+	// currency of the accounts when the transfer was made
+	Currency  string    `json:"currency"`
+	CreatedAt time.Time `json:"created_at"`
+	// reference given by the integrator, unique when set
+	ExternalRef *string `json:"external_ref"`
+	// spending category chosen by the sender, such as groceries or rent
+	Category *string `json:"category"`
+}
+
+func newTransferResponse(transfer db.Transfer) transferResponse {
+	return transferResponse{
+		ID:            transfer.ID,
+		FromAccountID: transfer.FromAccountID,
+		ToAccountID:   transfer.ToAccountID,
+		Amount:        transfer.Amount,
+		Fee:           transfer.Fee,
+		Currency:      transfer.Currency,
+		CreatedAt:     transfer.CreatedAt,
+		ExternalRef:   transfer.ExternalRef,
+		Category:      transfer.Category,
+	}
+}
+
+// transferTxResponse is a performed transfer as exposed to clients, with both accounts as accountResponse
+type transferTxResponse struct {
+	Transfer    transferResponse `json:"transfer"`
+	FromAccount accountResponse  `json:"from_account"`
+	ToAccount   accountResponse  `json:"to_account"`
+	FromEntry   db.Entry         `json:"from_entry"`
+	ToEntry     db.Entry         `json:"to_entry"`
+	// FeeEntry is the entry debiting the fee from the source account, if a fee was charged
+	FeeEntry *db.Entry `json:"fee_entry,omitempty"`
+}
+
+func (server *Server) newTransferTxResponse(result db.TransferTxResult) transferTxResponse {
+	return transferTxResponse{
+		Transfer:    newTransferResponse(result.Transfer),
+		FromAccount: server.newAccountResponse(result.FromAccount),
+		ToAccount:   server.newAccountResponse(result.ToAccount),
+		FromEntry:   result.FromEntry,
+		ToEntry:     result.ToEntry,
+		FeeEntry:    result.FeeEntry,
+	}
+}
+
+// createTransfer godoc
+// @Summary  Transfer money between two accounts
+// @Description  A transfer above the configured confirmation threshold is not performed right away:
+// @Description  it is answered with 202 and a token to confirm it with through /transfers/{id}/confirm.
+// @Tags     transfers
+// @Accept   json
+// @Produce  json
+// @Param    request  body      transferRequest  true  "Transfer to perform"
+// @Success  200      {object}  transferTxResponse
+// @Success  202      {object}  transferConfirmationResponse
+// @Failure  400      {object}  apiError
+// @Failure  403      {object}  apiError
+// @Failure  404      {object}  apiError
+// @Failure  409      {object}  apiError
+// @Failure  429      {object}  apiError
+// @Failure  500      {object}  apiError
+// @Failure  503      {object}  apiError
+// @Router   /transfers [post]
+func (server *Server) createTransfer(ctx *gin.Context) {
+	var req transferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	if server.needsConfirmation(req) {
+		server.requestTransferConfirmation(ctx, req)
+		return
+	}
+
+	result, duplicate, serr := server.performTransfer(ctx, req)
+	if duplicate {
+		ctx.Header("Warning", possibleDuplicateWarning)
+	}
+	if serr != nil {
+		serr.write(ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, server.newTransferTxResponse(result))
+}
+
+// statusError is an error response together with the HTTP status it is sent with.
+// The checks shared by several handlers return one, so that each handler decides how to report it.
+type statusError struct {
+	status     int
+	rsp        apiError
+	retryAfter string
+}
+
+func newStatusError(status int, err error) *statusError {
+	return &statusError{status: status, rsp: errorResponse(err)}
+}
+
+func (e *statusError) Error() string {
+	return e.rsp.Message
+}
+
+// write sends the error response
+func (e *statusError) write(ctx *gin.Context) {
+	if e.retryAfter != "" {
+		ctx.Header("Retry-After", e.retryAfter)
+	}
+	ctx.JSON(e.status, e.rsp)
+}
+
+// performTransfer runs every check of a transfer and then the transfer itself, holding the lock of the source account.
+// duplicate reports a likely duplicate that was let through.
+func (server *Server) performTransfer(ctx *gin.Context, req transferRequest) (db.TransferTxResult, bool, *statusError) {
+	return server.performTransferWith(ctx, req, server.store.TransferTx)
+}
+
+// performTransferWith is performTransfer running the transfer through run
+func (server *Server) performTransferWith(ctx *gin.Context, req transferRequest, run func(context.Context, db.TransferTxParams) (db.TransferTxResult, error)) (result db.TransferTxResult, duplicate bool, serr *statusError) {
+	if serr = server.checkTransfer(ctx.Request.Context(), req); serr != nil {
+		return
+	}
+
+	unlock, serr := server.acquireAccountLock(ctx, req.FromAccountID)
+	if serr != nil {
+		return
+	}
+	defer unlock()
+
+	arg, duplicate, serr := server.prepareTransfer(ctx.Request.Context(), req, nil)
+	if serr != nil {
+		return
+	}
+
+	result, err := run(ctx.Request.Context(), arg)
+	if err != nil {
+		serr = transferTxError(err)
+	}
+	return
+}
+
+// prepareTransfer runs the checks of a transfer that must happen under the lock of the source account
+// and returns the parameters to run it with. duplicate reports a likely duplicate that was let through.
+// earlier lists the transfers that run before it in the same transaction, which the duplicate and velocity checks count
+// as if they were already stored.
+func (server *Server) prepareTransfer(ctx context.Context, req transferRequest, earlier []transferRequest) (arg db.TransferTxParams, duplicate bool, serr *statusError) {
+	if duplicate, serr = server.duplicateTransfer(ctx, req, earlier); serr != nil {
+		return
+	}
+	flagReason, serr := server.transferVelocity(ctx, req, earlier)
+	if serr != nil {
+		return
+	}
+
+	arg = db.TransferTxParams{
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		FlagReason:    flagReason,
+		ExternalRef:   req.ExternalRef,
+		Category:      req.Category,
+		BalanceOrder:  balanceOrder(server.currentConfig().BalanceUpdateOrder),
+	}
+	serr = server.transferFee(&arg, req.Currency)
+	return
+}
+
+// balanceOrder returns the order transfers update balances in for the configured one;
+// nil keeps the default account ID order
+func balanceOrder(order util.BalanceUpdateOrder) db.BalanceOrder {
+	if order == util.BalanceUpdateOrderAmount {
+		return db.OrderByAmount{}
+	}
+	return nil
+}
+
+// transferTxError reports an error returned by TransferTx with the status it calls for
+func transferTxError(err error) *statusError {
+	switch {
+	case errors.Is(err, db.ErrConstraintViolation),
+		errors.Is(err, db.ErrInsufficientFunds),
+		errors.Is(err, db.ErrTransferLimitExceeded),
+		errors.Is(err, db.ErrBelowMinimumBalance),
+		errors.Is(err, db.ErrMinBalanceViolation):
+		return newStatusError(http.StatusBadRequest, err)
+	case errors.Is(err, db.ErrDestinationNotWhitelisted), errors.Is(err, db.ErrAccountFrozen):
+		return newStatusError(http.StatusForbidden, err)
+	case errors.Is(err, db.ErrDuplicateExternalRef), errors.Is(err, db.ErrTransferConfirmationNotPending):
+		return newStatusError(http.StatusConflict, err)
+	case errors.Is(err, db.ErrTransferConfirmationExpired):
+		return newStatusError(http.StatusGone, err)
+	case errors.Is(err, db.ErrDeadlock):
+		serr := newStatusError(http.StatusServiceUnavailable, err)
+		serr.retryAfter = deadlockRetryAfter
+		return serr
+	default:
+		return newStatusError(http.StatusInternalServerError, err)
+	}
+}
+
+// validTransfer checks the currency, amount and both accounts of a transfer, writing the error response otherwise
+func (server *Server) validTransfer(ctx *gin.Context, req transferRequest) bool {
+	if serr := server.checkTransfer(ctx.Request.Context(), req); serr != nil {
+		serr.write(ctx)
+		return false
+	}
+	return true
+}
+
+// checkTransfer checks the currency, amount and both accounts of a transfer
+func (server *Server) checkTransfer(ctx context.Context, req transferRequest) *statusError {
+	if req.FromAccountID == req.ToAccountID {
+		return newStatusError(http.StatusBadRequest, errSameAccount)
+	}
+
+	if serr := server.checkCurrency(req.Currency); serr != nil {
+		return serr
+	}
+
+	if max := server.currentConfig().MaxTransferAmount; max > 0 && req.Amount > max {
+		err := fmt.Errorf("%w: %d is above the maximum of %d", errTransferLimitExceeded, req.Amount, max)
+		return newStatusError(http.StatusBadRequest, err)
+	}
+
+	if serr := server.checkAccount(ctx, req.FromAccountID, req.Currency); serr != nil {
+		return serr
+	}
+	return server.checkAccount(ctx, req.ToAccountID, req.Currency)
+}
+
+// duplicateTransfer looks for a transfer with the same accounts and amount within the configured window.
+// A likely duplicate is rejected when BlockDuplicateTransfers is set and reported through duplicate otherwise
+func (server *Server) duplicateTransfer(ctx context.Context, req transferRequest, earlier []transferRequest) (duplicate bool, serr *statusError) {
+	config := server.currentConfig()
+	if config.DuplicateTransferWindow <= 0 {
+		return false, nil
+	}
+
+	found := false
+	for _, other := range earlier {
+		if other.FromAccountID == req.FromAccountID && other.ToAccountID == req.ToAccountID && other.Amount == req.Amount {
+			found = true
+			break
+		}
+	}
+	if !found {
+		var err error
+		found, err = server.store.RecentSimilarTransferExists(ctx, db.RecentSimilarTransferExistsParams{
+			FromAccountID: req.FromAccountID,
+			ToAccountID:   req.ToAccountID,
+			Amount:        req.Amount,
+			Since:         time.Now().Add(-config.DuplicateTransferWindow),
+		})
+		if err != nil {
+			return false, newStatusError(http.StatusInternalServerError, err)
+		}
+	}
+	if !found {
+		return false, nil
+	}
+
+	if config.BlockDuplicateTransfers {
+		err := fmt.Errorf("%w: within the last %s", errPossibleDuplicate, config.DuplicateTransferWindow)
+		return false, newStatusError(http.StatusConflict, err)
+	}
+	return true, nil
+}
+
+// transferVelocity counts the transfers the source account sent within the configured window.
+// Once the transfer would go over the limit it is rejected when BlockHighVelocity is set,
+// and otherwise let through with the reason it should be flagged for review
+func (server *Server) transferVelocity(ctx context.Context, req transferRequest, earlier []transferRequest) (string, *statusError) {
+	config := server.currentConfig()
+	if config.TransferVelocityLimit <= 0 || config.TransferVelocityWindow <= 0 {
+		return "", nil
+	}
+
+	count, err := server.store.CountRecentTransfersFromAccount(ctx, db.CountRecentTransfersFromAccountParams{
+		FromAccountID: req.FromAccountID,
+		Since:         time.Now().Add(-config.TransferVelocityWindow),
+	})
+	if err != nil {
+		return "", newStatusError(http.StatusInternalServerError, err)
+	}
+	for _, other := range earlier {
+		if other.FromAccountID == req.FromAccountID {
+			count++
+		}
+	}
+	if count < config.TransferVelocityLimit {
+		return "", nil
+	}
+
+	if config.BlockHighVelocity {
+		err := fmt.Errorf("%w: %d within %s allowed", errTransferVelocity, config.TransferVelocityLimit, config.TransferVelocityWindow)
+		serr := newStatusError(http.StatusTooManyRequests, err)
+		serr.retryAfter = server.rateLimitRetryAfter()
+		return "", serr
+	}
+	return fmt.Sprintf("velocity: transfer %d within %s, above the limit of %d", count+1, config.TransferVelocityWindow, config.TransferVelocityLimit), nil
+}
+
+// transferFee sets the fee of the transfer and the account credited with it from the configured fee policy
+func (server *Server) transferFee(arg *db.TransferTxParams, currency string) *statusError {
+	fee, feeAccountID, err := server.currentConfig().TransferFee(arg.Amount, currency)
+	if errors.Is(err, util.ErrNoFeeAccount) {
+		return newStatusError(http.StatusInternalServerError, err)
+	}
+	if err != nil {
+		return newStatusError(http.StatusBadRequest, err)
+	}
+
+	arg.Fee = fee
+	arg.FeeAccountID = feeAccountID
+	return nil
+}
+
+// lockAccount takes the in-process transfer lock of the account, writing the error response when it cannot
+func (server *Server) lockAccount(ctx *gin.Context, accountID int64) (func(), bool) {
+	unlock, serr := server.acquireAccountLock(ctx, accountID)
+	if serr != nil {
+		serr.write(ctx)
+		return nil, false
+	}
+	return unlock, true
+}
+
+// acquireAccountLock takes the in-process transfer lock of the account
+func (server *Server) acquireAccountLock(ctx *gin.Context, accountID int64) (func(), *statusError) {
+	unlock, err := server.transferLocks.acquire(ctx.Request.Context(), accountID, server.currentConfig().TransferLockTimeout)
+	if err != nil {
+		if errors.Is(err, errAccountBusy) {
+			serr := newStatusError(http.StatusTooManyRequests, err)
+			serr.retryAfter = server.rateLimitRetryAfter()
+			return nil, serr
+		}
+		return nil, newStatusError(http.StatusInternalServerError, err)
+	}
+	return unlock, nil
+}
+
+// validAccount checks the account exists and holds the given currency, writing the error response otherwise
+func (server *Server) validAccount(ctx *gin.Context, accountID int64, currency string) bool {
+	if serr := server.checkAccount(ctx.Request.Context(), accountID, currency); serr != nil {
+		serr.write(ctx)
+		return false
+	}
+	return true
+}
+
+// checkAccount checks the account exists, holds the given currency and is not frozen
+func (server *Server) checkAccount(ctx context.Context, accountID int64, currency string) *statusError {
+	account, err := server.store.GetAccount(ctx, accountID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return newStatusError(http.StatusNotFound, errAccountNotFound)
+		}
+		return newStatusError(http.StatusInternalServerError, err)
+	}
+
+	if account.Currency != currency {
+		err := fmt.Errorf("%w: account [%d] currency %s vs %s", errCurrencyMismatch, account.ID, account.Currency, currency)
+		return newStatusError(http.StatusBadRequest, err)
+	}
+	if account.Frozen {
+		return newStatusError(http.StatusForbidden, fmt.Errorf("%w: account [%d]", errAccountFrozen, account.ID))
+	}
+	return nil
+}
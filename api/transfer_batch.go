@@ -0,0 +1,216 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+)
+
+const (
+	// batchModeAtomic performs every transfer of a batch or none of them
+	batchModeAtomic = "atomic"
+	// batchModeBestEffort performs each transfer of a batch on its own, whatever happens to the others
+	batchModeBestEffort = "best_effort"
+)
+
+type batchTransferQuery struct {
+	// Mode defaults to atomic
+	Mode string `form:"mode" binding:"omitempty,oneof=atomic best_effort"`
+}
+
+type batchTransferRequest struct {
+	Transfers []transferRequest `json:"transfers" binding:"required,min=1,max=100,dive"`
+}
+
+// batchTransferItem is the outcome of one transfer of a batch
+type batchTransferItem struct {
+	Index int `json:"index"`
+	// Status is the HTTP status the transfer would have got on its own
+	Status            int                 `json:"status"`
+	Result            *transferTxResponse `json:"result,omitempty"`
+	Error             *apiError           `json:"error,omitempty"`
+	PossibleDuplicate bool                `json:"possible_duplicate,omitempty"`
+}
+
+type batchTransferResponse struct {
+	Mode      string              `json:"mode"`
+	Succeeded int                 `json:"succeeded"`
+	Failed    int                 `json:"failed"`
+	Items     []batchTransferItem `json:"items"`
+}
+
+// batchTransferFailure details which transfer made an atomic batch fail
+type batchTransferFailure struct {
+	Index int `json:"index"`
+}
+
+// createBatchTransfer godoc
+// @Summary  Perform several transfers at once
+// @Description  In atomic mode, the default, either every transfer happens or none does; the error names the first transfer that failed.
+// @Description  In best_effort mode each transfer runs in its own transaction and the response reports the outcome of each one,
+// @Description  with the status and error it would have got on its own.
+// @Tags     transfers
+// @Accept   json
+// @Produce  json
+// @Param    mode     query     string                false  "atomic or best_effort"
+// @Param    request  body      batchTransferRequest  true   "Transfers to perform, at most 100"
+// @Success  200      {object}  batchTransferResponse
+// @Failure  400      {object}  apiError
+// @Failure  403      {object}  apiError
+// @Failure  404      {object}  apiError
+// @Failure  409      {object}  apiError
+// @Failure  429      {object}  apiError
+// @Failure  500      {object}  apiError
+// @Failure  503      {object}  apiError
+// @Router   /transfers/batch [post]
+func (server *Server) createBatchTransfer(ctx *gin.Context) {
+	var query batchTransferQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	var req batchTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	if query.Mode == batchModeBestEffort {
+		ctx.JSON(http.StatusOK, server.bestEffortBatchTransfer(ctx, req.Transfers))
+		return
+	}
+
+	rsp, serr := server.atomicBatchTransfer(ctx, req.Transfers)
+	if serr != nil {
+		serr.write(ctx)
+		return
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// bestEffortBatchTransfer performs the transfers one after the other, each exactly like a single transfer
+func (server *Server) bestEffortBatchTransfer(ctx *gin.Context, transfers []transferRequest) batchTransferResponse {
+	rsp := batchTransferResponse{
+		Mode:  batchModeBestEffort,
+		Items: make([]batchTransferItem, len(transfers)),
+	}
+
+	for i, req := range transfers {
+		var result db.TransferTxResult
+		var duplicate bool
+		serr := server.checkNoConfirmation(req)
+		if serr == nil {
+			result, duplicate, serr = server.performTransfer(ctx, req)
+		}
+		item := batchTransferItem{Index: i, PossibleDuplicate: duplicate}
+		if serr != nil {
+			item.Status = serr.status
+			item.Error = &serr.rsp
+			rsp.Failed++
+		} else {
+			item.Status = http.StatusOK
+			result := server.newTransferTxResponse(result)
+			item.Result = &result
+			rsp.Succeeded++
+		}
+		rsp.Items[i] = item
+	}
+
+	return rsp
+}
+
+// atomicBatchTransfer checks every transfer, then performs them all in one transaction
+// holding the locks of every source account
+func (server *Server) atomicBatchTransfer(ctx *gin.Context, transfers []transferRequest) (batchTransferResponse, *statusError) {
+	for i, req := range transfers {
+		if serr := server.checkNoConfirmation(req); serr != nil {
+			return batchTransferResponse{}, batchItemError(i, serr)
+		}
+		if serr := server.checkTransfer(ctx.Request.Context(), req); serr != nil {
+			return batchTransferResponse{}, batchItemError(i, serr)
+		}
+	}
+
+	unlock, serr := server.acquireAccountLocks(ctx, transfers)
+	if serr != nil {
+		return batchTransferResponse{}, serr
+	}
+	defer unlock()
+
+	args := make([]db.TransferTxParams, len(transfers))
+	duplicates := make([]bool, len(transfers))
+	for i, req := range transfers {
+		arg, duplicate, serr := server.prepareTransfer(ctx.Request.Context(), req, transfers[:i])
+		if serr != nil {
+			return batchTransferResponse{}, batchItemError(i, serr)
+		}
+		args[i] = arg
+		duplicates[i] = duplicate
+	}
+
+	results, err := server.store.BatchTransferTx(ctx.Request.Context(), args)
+	if err != nil {
+		serr := transferTxError(err)
+		var batchErr *db.BatchTransferError
+		if errors.As(err, &batchErr) {
+			serr = batchItemError(batchErr.Index, transferTxError(batchErr.Err))
+		}
+		return batchTransferResponse{}, serr
+	}
+
+	rsp := batchTransferResponse{
+		Mode:      batchModeAtomic,
+		Succeeded: len(results),
+		Items:     make([]batchTransferItem, len(results)),
+	}
+	for i := range results {
+		result := server.newTransferTxResponse(results[i])
+		rsp.Items[i] = batchTransferItem{
+			Index:             i,
+			Status:            http.StatusOK,
+			Result:            &result,
+			PossibleDuplicate: duplicates[i],
+		}
+	}
+	return rsp, nil
+}
+
+// batchItemError reports the error of one transfer as the error of its whole batch
+func batchItemError(index int, serr *statusError) *statusError {
+	serr.rsp.Details = batchTransferFailure{Index: index}
+	return serr
+}
+
+// acquireAccountLocks takes the transfer locks of every source account of the batch.
+// They are taken in account order, so that two batches never wait on each other.
+func (server *Server) acquireAccountLocks(ctx *gin.Context, transfers []transferRequest) (func(), *statusError) {
+	seen := make(map[int64]bool)
+	var ids []int64
+	for _, req := range transfers {
+		if !seen[req.FromAccountID] {
+			seen[req.FromAccountID] = true
+			ids = append(ids, req.FromAccountID)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	unlocks := make([]func(), 0, len(ids))
+	unlockAll := func() {
+		for i := len(unlocks) - 1; i >= 0; i-- {
+			unlocks[i]()
+		}
+	}
+	for _, id := range ids {
+		unlock, serr := server.acquireAccountLock(ctx, id)
+		if serr != nil {
+			unlockAll()
+			return nil, serr
+		}
+		unlocks = append(unlocks, unlock)
+	}
+	return unlockAll, nil
+}
@@ -0,0 +1,320 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khuongkd/simplebank/db/mock"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+	"github.com/khuongkd/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateBatchTransferAPI(t *testing.T) {
+	account1 := randomAccount()
+	account2 := randomAccount()
+	account2.ID = account1.ID + 1
+	account1.Currency = "USD"
+	account2.Currency = "USD"
+
+	transfers := []gin.H{
+		{"from_account_id": account1.ID, "to_account_id": account2.ID, "amount": 10, "currency": "USD"},
+		{"from_account_id": account1.ID, "to_account_id": account2.ID, "amount": 1000000, "currency": "USD"},
+		{"from_account_id": account2.ID, "to_account_id": account1.ID, "amount": 5, "currency": "USD"},
+	}
+	args := []db.TransferTxParams{
+		{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 10},
+		{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 1000000},
+		{FromAccountID: account2.ID, ToAccountID: account1.ID, Amount: 5},
+	}
+	insufficientFunds := fmt.Errorf("%w: 0 available, 10 needed", db.ErrInsufficientFunds)
+
+	getAccounts := func(store *mockdb.MockStore) {
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).AnyTimes().Return(account1, nil)
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).AnyTimes().Return(account2, nil)
+	}
+
+	testCases := []struct {
+		name          string
+		mode          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "BestEffortPartialFailure",
+			mode: batchModeBestEffort,
+			body: gin.H{"transfers": transfers},
+			buildStubs: func(store *mockdb.MockStore) {
+				getAccounts(store)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(args[0])).Times(1).Return(db.TransferTxResult{Transfer: db.Transfer{ID: 1}}, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(args[1])).Times(1).Return(db.TransferTxResult{}, insufficientFunds)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(args[2])).Times(1).Return(db.TransferTxResult{Transfer: db.Transfer{ID: 2}}, nil)
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				rsp := requireBatchTransferResponse(t, recorder)
+				require.Equal(t, batchModeBestEffort, rsp.Mode)
+				require.Equal(t, 2, rsp.Succeeded)
+				require.Equal(t, 1, rsp.Failed)
+				require.Len(t, rsp.Items, 3)
+
+				require.Equal(t, http.StatusOK, rsp.Items[0].Status)
+				require.Equal(t, int64(1), rsp.Items[0].Result.Transfer.ID)
+				require.Nil(t, rsp.Items[0].Error)
+
+				require.Equal(t, 1, rsp.Items[1].Index)
+				require.Equal(t, http.StatusBadRequest, rsp.Items[1].Status)
+				require.Nil(t, rsp.Items[1].Result)
+				require.Equal(t, ErrCodeInsufficientFunds, rsp.Items[1].Error.Code)
+
+				require.Equal(t, http.StatusOK, rsp.Items[2].Status)
+				require.Equal(t, int64(2), rsp.Items[2].Result.Transfer.ID)
+			},
+		},
+		{
+			name: "BestEffortInvalidItem",
+			mode: batchModeBestEffort,
+			body: gin.H{"transfers": []gin.H{
+				transfers[0],
+				{"from_account_id": account1.ID, "to_account_id": account2.ID, "amount": 10, "currency": "EUR"},
+			}},
+			buildStubs: func(store *mockdb.MockStore) {
+				getAccounts(store)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(args[0])).Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				rsp := requireBatchTransferResponse(t, recorder)
+				require.Equal(t, 1, rsp.Succeeded)
+				require.Equal(t, 1, rsp.Failed)
+				require.Equal(t, http.StatusBadRequest, rsp.Items[1].Status)
+				require.Equal(t, ErrCodeCurrencyMismatch, rsp.Items[1].Error.Code)
+			},
+		},
+		{
+			name: "AtomicOK",
+			body: gin.H{"transfers": []gin.H{transfers[0], transfers[2]}},
+			buildStubs: func(store *mockdb.MockStore) {
+				getAccounts(store)
+				results := []db.TransferTxResult{
+					{Transfer: db.Transfer{ID: 1}},
+					{Transfer: db.Transfer{ID: 2}},
+				}
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Eq([]db.TransferTxParams{args[0], args[2]})).Times(1).Return(results, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				rsp := requireBatchTransferResponse(t, recorder)
+				require.Equal(t, batchModeAtomic, rsp.Mode)
+				require.Equal(t, 2, rsp.Succeeded)
+				require.Zero(t, rsp.Failed)
+				require.Equal(t, int64(2), rsp.Items[1].Result.Transfer.ID)
+			},
+		},
+		{
+			name: "AtomicInsufficientFunds",
+			mode: batchModeAtomic,
+			body: gin.H{"transfers": transfers},
+			buildStubs: func(store *mockdb.MockStore) {
+				getAccounts(store)
+				err := &db.BatchTransferError{Index: 1, Err: insufficientFunds}
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Eq(args)).Times(1).Return(nil, err)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireBatchTransferFailure(t, recorder, ErrCodeInsufficientFunds, 1)
+			},
+		},
+		{
+			name: "AtomicAccountNotFound",
+			body: gin.H{"transfers": []gin.H{
+				transfers[0],
+				{"from_account_id": account1.ID, "to_account_id": account2.ID + 1, "amount": 10, "currency": "USD"},
+			}},
+			buildStubs: func(store *mockdb.MockStore) {
+				getAccounts(store)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID+1)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireBatchTransferFailure(t, recorder, ErrCodeAccountNotFound, 1)
+			},
+		},
+		{
+			name: "AtomicSameAccount",
+			body: gin.H{"transfers": []gin.H{
+				transfers[0],
+				{"from_account_id": account1.ID, "to_account_id": account1.ID, "amount": 10, "currency": "USD"},
+			}},
+			buildStubs: func(store *mockdb.MockStore) {
+				getAccounts(store)
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireBatchTransferFailure(t, recorder, ErrCodeInvalidRequest, 1)
+			},
+		},
+		{
+			name: "InvalidMode",
+			mode: "eventually",
+			body: gin.H{"transfers": transfers},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeInvalidRequest)
+			},
+		},
+		{
+			name: "EmptyBatch",
+			body: gin.H{"transfers": []gin.H{}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeInvalidRequest)
+			},
+		},
+		{
+			name: "InvalidItem",
+			body: gin.H{"transfers": []gin.H{
+				{"from_account_id": account1.ID, "to_account_id": account2.ID, "amount": -1, "currency": "USD"},
+			}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeInvalidRequest)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := "/transfers/batch"
+			if tc.mode != "" {
+				url += "?mode=" + tc.mode
+			}
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func requireBatchTransferResponse(t *testing.T, recorder *httptest.ResponseRecorder) batchTransferResponse {
+	var rsp batchTransferResponse
+	err := json.Unmarshal(recorder.Body.Bytes(), &rsp)
+	require.NoError(t, err)
+	return rsp
+}
+
+func requireBatchTransferFailure(t *testing.T, recorder *httptest.ResponseRecorder, code ErrorCode, index int) {
+	var rsp struct {
+		Code    ErrorCode            `json:"code"`
+		Details batchTransferFailure `json:"details"`
+	}
+	err := json.Unmarshal(recorder.Body.Bytes(), &rsp)
+	require.NoError(t, err)
+	require.Equal(t, code, rsp.Code)
+	require.Equal(t, index, rsp.Details.Index)
+}
+
+func TestAtomicBatchTransferCountsEarlierItems(t *testing.T) {
+	account1 := randomAccount()
+	account2 := randomAccount()
+	account1.Currency = "USD"
+	account2.Currency = "USD"
+	window := time.Hour
+
+	transfers := []gin.H{
+		{"from_account_id": account1.ID, "to_account_id": account2.ID, "amount": 10, "currency": "USD"},
+		{"from_account_id": account1.ID, "to_account_id": account2.ID, "amount": 20, "currency": "USD"},
+		{"from_account_id": account1.ID, "to_account_id": account2.ID, "amount": 10, "currency": "USD"},
+	}
+
+	testCases := []struct {
+		name          string
+		config        util.Config
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			// one stored transfer and two earlier items put the third one over the limit of 3
+			name:   "VelocityLimit",
+			config: util.Config{TransferVelocityLimit: 3, TransferVelocityWindow: window, BlockHighVelocity: true},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+				requireBatchTransferFailure(t, recorder, ErrCodeTransferVelocity, 2)
+			},
+		},
+		{
+			// the third item repeats the first one
+			name:   "Duplicate",
+			config: util.Config{DuplicateTransferWindow: window, BlockDuplicateTransfers: true},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireBatchTransferFailure(t, recorder, ErrCodePossibleDuplicate, 2)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).AnyTimes().Return(account1, nil)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).AnyTimes().Return(account2, nil)
+			store.EXPECT().CountRecentTransfersFromAccount(gomock.Any(), gomock.Any()).AnyTimes().Return(int64(1), nil)
+			store.EXPECT().RecentSimilarTransferExists(gomock.Any(), gomock.Any()).AnyTimes().Return(false, nil)
+			store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Any()).Times(0)
+
+			server := NewServer(tc.config, store)
+			recorder := httptest.NewRecorder()
+
+			request := newPostRequest(t, "/transfers/batch", gin.H{"transfers": transfers})
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
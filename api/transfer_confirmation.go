@@ -0,0 +1,186 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+)
+
+// defaultTransferConfirmationTTL is how long a transfer can be confirmed for when TransferConfirmationTTL is not configured
+const defaultTransferConfirmationTTL = 15 * time.Minute
+
+// transferConfirmationResponse is a transfer awaiting confirmation as exposed to clients
+type transferConfirmationResponse struct {
+	ID            int64     `json:"id"`
+	FromAccountID int64     `json:"from_account_id"`
+	ToAccountID   int64     `json:"to_account_id"`
+	Amount        int64     `json:"amount"`
+	Currency      string    `json:"currency"`
+	Status        string    `json:"status"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	CreatedAt     time.Time `json:"created_at"`
+	// Token must be sent back to confirm the transfer; it is only ever returned here
+	Token string `json:"token"`
+}
+
+// needsConfirmation reports whether the transfer is large enough to wait for the client to confirm it
+func (server *Server) needsConfirmation(req transferRequest) bool {
+	threshold := server.currentConfig().TransferConfirmationThreshold
+	return threshold > 0 && req.Amount > threshold
+}
+
+// checkNoConfirmation rejects a transfer that needs confirming where it cannot be, such as in a batch,
+// an authorized hold or a scheduled transfer
+func (server *Server) checkNoConfirmation(req transferRequest) *statusError {
+	if !server.needsConfirmation(req) {
+		return nil
+	}
+	err := fmt.Errorf("%w: %d is above the threshold of %d", errConfirmationRequired, req.Amount, server.currentConfig().TransferConfirmationThreshold)
+	return newStatusError(http.StatusBadRequest, err)
+}
+
+// requestTransferConfirmation checks the transfer and records it to be confirmed, without moving any money
+func (server *Server) requestTransferConfirmation(ctx *gin.Context, req transferRequest) {
+	if !server.validTransfer(ctx, req) {
+		return
+	}
+
+	token, err := newConfirmationToken()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ttl := server.currentConfig().TransferConfirmationTTL
+	if ttl <= 0 {
+		ttl = defaultTransferConfirmationTTL
+	}
+
+	confirmation, err := server.store.CreateTransferConfirmation(ctx.Request.Context(), db.CreateTransferConfirmationParams{
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		Currency:      req.Currency,
+		ExternalRef:   req.ExternalRef,
+		Category:      req.Category,
+		TokenHash:     hashConfirmationToken(token),
+		ExpiresAt:     time.Now().Add(ttl),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, transferConfirmationResponse{
+		ID:            confirmation.ID,
+		FromAccountID: confirmation.FromAccountID,
+		ToAccountID:   confirmation.ToAccountID,
+		Amount:        confirmation.Amount,
+		Currency:      confirmation.Currency,
+		Status:        confirmation.Status,
+		ExpiresAt:     confirmation.ExpiresAt,
+		CreatedAt:     confirmation.CreatedAt,
+		Token:         token,
+	})
+}
+
+// newConfirmationToken returns a random 256-bit token
+func newConfirmationToken() (string, error) {
+	var token [32]byte
+	if _, err := rand.Read(token[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(token[:]), nil
+}
+
+// hashConfirmationToken returns the hash a token is stored as, so that reading the database is not enough to confirm a transfer
+func hashConfirmationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+type confirmTransferURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type confirmTransferRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// confirmTransfer godoc
+// @Summary  Confirm a transfer above the confirmation threshold
+// @Description  Performs the transfer with the token returned when it was requested. Every check of a transfer runs again.
+// @Tags     transfers
+// @Accept   json
+// @Produce  json
+// @Param    id       path      int                     true  "Transfer confirmation ID"
+// @Param    request  body      confirmTransferRequest  true  "Confirmation token"
+// @Success  200      {object}  transferTxResponse
+// @Failure  400      {object}  apiError
+// @Failure  403      {object}  apiError
+// @Failure  404      {object}  apiError
+// @Failure  409      {object}  apiError
+// @Failure  410      {object}  apiError
+// @Failure  429      {object}  apiError
+// @Failure  500      {object}  apiError
+// @Failure  503      {object}  apiError
+// @Router   /transfers/{id}/confirm [post]
+func (server *Server) confirmTransfer(ctx *gin.Context) {
+	var uri confirmTransferURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	var req confirmTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	confirmation, err := server.store.GetTransferConfirmation(ctx.Request.Context(), uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(errConfirmationNotFound))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashConfirmationToken(req.Token)), []byte(confirmation.TokenHash)) != 1 {
+		ctx.JSON(http.StatusForbidden, errorResponse(errInvalidConfirmationToken))
+		return
+	}
+
+	transfer := transferRequest{
+		FromAccountID: confirmation.FromAccountID,
+		ToAccountID:   confirmation.ToAccountID,
+		Amount:        confirmation.Amount,
+		Currency:      confirmation.Currency,
+		ExternalRef:   confirmation.ExternalRef,
+		Category:      confirmation.Category,
+	}
+	result, duplicate, serr := server.performTransferWith(ctx, transfer, func(c context.Context, arg db.TransferTxParams) (db.TransferTxResult, error) {
+		return server.store.ConfirmTransferTx(c, confirmation.ID, arg)
+	})
+	if duplicate {
+		ctx.Header("Warning", possibleDuplicateWarning)
+	}
+	if serr != nil {
+		serr.write(ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, server.newTransferTxResponse(result))
+}
@@ -0,0 +1,394 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khuongkd/simplebank/db/mock"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+	"github.com/khuongkd/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+// confirmationThreshold is the TransferConfirmationThreshold of the tests
+const confirmationThreshold = 100
+
+func TestCreateTransferConfirmationAPI(t *testing.T) {
+	account1 := randomAccount()
+	account2 := randomAccount()
+	account1.Currency = "USD"
+	account2.Currency = "USD"
+
+	testCases := []struct {
+		name          string
+		amount        int64
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "BelowThreshold",
+			amount: confirmationThreshold,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().CreateTransferConfirmation(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "AboveThreshold",
+			amount: confirmationThreshold + 1,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().
+					CreateTransferConfirmation(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateTransferConfirmationParams) (db.TransferConfirmation, error) {
+						require.Equal(t, account1.ID, arg.FromAccountID)
+						require.Equal(t, account2.ID, arg.ToAccountID)
+						require.Equal(t, int64(confirmationThreshold+1), arg.Amount)
+						require.Equal(t, "USD", arg.Currency)
+						require.WithinDuration(t, time.Now().Add(time.Minute), arg.ExpiresAt, time.Second)
+						return db.TransferConfirmation{
+							ID:            7,
+							FromAccountID: arg.FromAccountID,
+							ToAccountID:   arg.ToAccountID,
+							Amount:        arg.Amount,
+							Currency:      arg.Currency,
+							TokenHash:     arg.TokenHash,
+							Status:        db.TransferConfirmationPending,
+							ExpiresAt:     arg.ExpiresAt,
+						}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+
+				var rsp transferConfirmationResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, int64(7), rsp.ID)
+				require.Equal(t, db.TransferConfirmationPending, rsp.Status)
+				require.Len(t, rsp.Token, 64)
+			},
+		},
+		{
+			name:   "AboveThresholdFrozenAccount",
+			amount: confirmationThreshold + 1,
+			buildStubs: func(store *mockdb.MockStore) {
+				frozen := account1
+				frozen.Frozen = true
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(frozen, nil)
+				store.EXPECT().CreateTransferConfirmation(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeAccountFrozen)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewServer(util.Config{
+				TransferConfirmationThreshold: confirmationThreshold,
+				TransferConfirmationTTL:       time.Minute,
+			}, store)
+			recorder := httptest.NewRecorder()
+
+			request := newPostRequest(t, "/transfers", gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          tc.amount,
+				"currency":        "USD",
+			})
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestConfirmTransferAPI(t *testing.T) {
+	account1 := randomAccount()
+	account2 := randomAccount()
+	account1.Currency = "USD"
+	account2.Currency = "USD"
+
+	const token = "0123456789abcdef"
+	confirmation := db.TransferConfirmation{
+		ID:            7,
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        confirmationThreshold + 1,
+		Currency:      "USD",
+		Category:      "rent",
+		TokenHash:     hashConfirmationToken(token),
+		Status:        db.TransferConfirmationPending,
+		ExpiresAt:     time.Now().Add(time.Minute),
+	}
+	transferArg := db.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        confirmationThreshold + 1,
+		Category:      "rent",
+	}
+
+	// expectChecks expects the checks every transfer goes through, run again on confirmation
+	expectChecks := func(store *mockdb.MockStore) {
+		store.EXPECT().GetTransferConfirmation(gomock.Any(), gomock.Eq(confirmation.ID)).Times(1).Return(confirmation, nil)
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+	}
+
+	testCases := []struct {
+		name          string
+		id            int64
+		token         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			id:    confirmation.ID,
+			token: token,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectChecks(store)
+				store.EXPECT().
+					ConfirmTransferTx(gomock.Any(), gomock.Eq(confirmation.ID), gomock.Eq(transferArg)).
+					Times(1).
+					Return(db.TransferTxResult{Transfer: db.Transfer{ID: 3, Amount: transferArg.Amount}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp db.TransferTxResult
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, int64(3), rsp.Transfer.ID)
+			},
+		},
+		{
+			name:  "WrongToken",
+			id:    confirmation.ID,
+			token: "fedcba9876543210",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferConfirmation(gomock.Any(), gomock.Eq(confirmation.ID)).Times(1).Return(confirmation, nil)
+				store.EXPECT().ConfirmTransferTx(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeInvalidConfirmation)
+			},
+		},
+		{
+			name:  "Expired",
+			id:    confirmation.ID,
+			token: token,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectChecks(store)
+				store.EXPECT().
+					ConfirmTransferTx(gomock.Any(), gomock.Eq(confirmation.ID), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrTransferConfirmationExpired)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusGone, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeConfirmationExpired)
+			},
+		},
+		{
+			name:  "AlreadyConfirmed",
+			id:    confirmation.ID,
+			token: token,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectChecks(store)
+				store.EXPECT().
+					ConfirmTransferTx(gomock.Any(), gomock.Eq(confirmation.ID), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrTransferConfirmationNotPending)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeConfirmationDone)
+			},
+		},
+		{
+			name:  "InsufficientFunds",
+			id:    confirmation.ID,
+			token: token,
+			buildStubs: func(store *mockdb.MockStore) {
+				expectChecks(store)
+				store.EXPECT().
+					ConfirmTransferTx(gomock.Any(), gomock.Eq(confirmation.ID), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeInsufficientFunds)
+			},
+		},
+		{
+			name:  "NotFound",
+			id:    confirmation.ID,
+			token: token,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferConfirmation(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferConfirmation{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeConfirmationNotFound)
+			},
+		},
+		{
+			name:  "MissingToken",
+			id:    confirmation.ID,
+			token: "",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferConfirmation(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeInvalidRequest)
+			},
+		},
+		{
+			name:  "InvalidID",
+			id:    0,
+			token: token,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferConfirmation(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewServer(util.Config{TransferConfirmationThreshold: confirmationThreshold}, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/transfers/%d/confirm", tc.id)
+			request := newPostRequest(t, url, gin.H{"token": tc.token})
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestBatchTransferConfirmationRequired(t *testing.T) {
+	account1 := randomAccount()
+	account2 := randomAccount()
+
+	for _, mode := range []string{batchModeAtomic, batchModeBestEffort} {
+		mode := mode
+
+		t.Run(mode, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Any()).Times(0)
+
+			server := NewServer(util.Config{TransferConfirmationThreshold: confirmationThreshold}, store)
+			recorder := httptest.NewRecorder()
+
+			request := newPostRequest(t, "/transfers/batch?mode="+mode, gin.H{
+				"transfers": []gin.H{{
+					"from_account_id": account1.ID,
+					"to_account_id":   account2.ID,
+					"amount":          confirmationThreshold + 1,
+					"currency":        "USD",
+				}},
+			})
+			server.router.ServeHTTP(recorder, request)
+
+			if mode == batchModeAtomic {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireBatchTransferFailure(t, recorder, ErrCodeConfirmationRequired, 0)
+				return
+			}
+			require.Equal(t, http.StatusOK, recorder.Code)
+			rsp := requireBatchTransferResponse(t, recorder)
+			require.Equal(t, 1, rsp.Failed)
+			require.Equal(t, http.StatusBadRequest, rsp.Items[0].Status)
+			require.Equal(t, ErrCodeConfirmationRequired, rsp.Items[0].Error.Code)
+		})
+	}
+}
+
+func TestHoldAndScheduleConfirmationRequired(t *testing.T) {
+	account1 := randomAccount()
+	account2 := randomAccount()
+	transfer := gin.H{
+		"from_account_id": account1.ID,
+		"to_account_id":   account2.ID,
+		"amount":          confirmationThreshold + 1,
+		"currency":        "USD",
+	}
+	scheduled := gin.H{"run_at": time.Now().Add(time.Hour)}
+	for key, value := range transfer {
+		scheduled[key] = value
+	}
+
+	testCases := []struct {
+		url  string
+		body gin.H
+	}{
+		{url: "/transfers/authorize", body: transfer},
+		{url: "/transfers/schedule", body: scheduled},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.url, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			store.EXPECT().AuthorizeTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			store.EXPECT().CreateScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+
+			server := NewServer(util.Config{TransferConfirmationThreshold: confirmationThreshold}, store)
+			recorder := httptest.NewRecorder()
+
+			request := newPostRequest(t, tc.url, tc.body)
+			server.router.ServeHTTP(recorder, request)
+
+			require.Equal(t, http.StatusBadRequest, recorder.Code)
+			requireErrorCode(t, recorder, ErrCodeConfirmationRequired)
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+)
+
+type transferHoldRequest struct {
+	HoldID int64 `json:"hold_id" binding:"required,min=1"`
+}
+
+// transferHoldResponse is a transfer hold as exposed to clients
+type transferHoldResponse struct {
+	ID            int64     `json:"id"`
+	FromAccountID int64     `json:"from_account_id"`
+	ToAccountID   int64     `json:"to_account_id"`
+	Amount        int64     `json:"amount"`
+	Status        string    `json:"status"`
+	TransferID    *int64    `json:"transfer_id,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// transferHoldTxResponse is the hold together with the source account whose held balance it changed
+type transferHoldTxResponse struct {
+	Hold        transferHoldResponse `json:"hold"`
+	FromAccount accountResponse      `json:"from_account"`
+}
+
+func (server *Server) newTransferHoldTxResponse(result db.TransferHoldTxResult) transferHoldTxResponse {
+	hold := result.Hold
+	rsp := transferHoldTxResponse{
+		Hold: transferHoldResponse{
+			ID:            hold.ID,
+			FromAccountID: hold.FromAccountID,
+			ToAccountID:   hold.ToAccountID,
+			Amount:        hold.Amount,
+			Status:        hold.Status,
+			CreatedAt:     hold.CreatedAt,
+		},
+		FromAccount: server.newAccountResponse(result.FromAccount),
+	}
+	if hold.TransferID.Valid {
+		rsp.Hold.TransferID = &hold.TransferID.Int64
+	}
+	return rsp
+}
+
+// authorizeTransfer godoc
+// @Summary  Hold money for a transfer without settling it
+// @Tags     transfers
+// @Accept   json
+// @Produce  json
+// @Param    request  body      transferRequest  true  "Transfer to authorize"
+// @Success  200      {object}  transferHoldTxResponse
+// @Failure  400      {object}  apiError
+// @Failure  403      {object}  apiError
+// @Failure  404      {object}  apiError
+// @Failure  429      {object}  apiError
+// @Failure  500      {object}  apiError
+// @Failure  503      {object}  apiError
+// @Router   /transfers/authorize [post]
+func (server *Server) authorizeTransfer(ctx *gin.Context) {
+	var req transferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	if serr := server.checkNoConfirmation(req); serr != nil {
+		serr.write(ctx)
+		return
+	}
+	if !server.validTransfer(ctx, req) {
+		return
+	}
+
+	unlock, ok := server.lockAccount(ctx, req.FromAccountID)
+	if !ok {
+		return
+	}
+	defer unlock()
+
+	result, err := server.store.AuthorizeTransferTx(ctx.Request.Context(), db.CreateTransferParams{
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+	})
+	if err != nil {
+		transferTxError(err).write(ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, server.newTransferHoldTxResponse(result))
+}
+
+// captureTransfer godoc
+// @Summary  Settle an authorized transfer hold
+// @Tags     transfers
+// @Accept   json
+// @Produce  json
+// @Param    request  body      transferHoldRequest  true  "Hold to capture"
+// @Success  200      {object}  transferTxResponse
+// @Failure  400      {object}  apiError
+// @Failure  403      {object}  apiError
+// @Failure  404      {object}  apiError
+// @Failure  409      {object}  apiError
+// @Failure  500      {object}  apiError
+// @Failure  503      {object}  apiError
+// @Router   /transfers/capture [post]
+func (server *Server) captureTransfer(ctx *gin.Context) {
+	var req transferHoldRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	result, err := server.store.CaptureTransferTx(ctx.Request.Context(), req.HoldID)
+	if err != nil {
+		if err == sql.ErrNoRows || errors.Is(err, db.ErrHoldNotAuthorized) {
+			writeHoldError(ctx, err)
+			return
+		}
+
+		transferTxError(err).write(ctx)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, server.newTransferTxResponse(result))
+}
+
+// voidTransfer godoc
+// @Summary  Release an authorized transfer hold without moving money
+// @Tags     transfers
+// @Accept   json
+// @Produce  json
+// @Param    request  body      transferHoldRequest  true  "Hold to void"
+// @Success  200      {object}  transferHoldTxResponse
+// @Failure  400      {object}  apiError
+// @Failure  404      {object}  apiError
+// @Failure  409      {object}  apiError
+// @Failure  500      {object}  apiError
+// @Router   /transfers/void [post]
+func (server *Server) voidTransfer(ctx *gin.Context) {
+	var req transferHoldRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	result, err := server.store.VoidTransferTx(ctx.Request.Context(), req.HoldID)
+	if err != nil {
+		writeHoldError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, server.newTransferHoldTxResponse(result))
+}
+
+// writeHoldError reports a hold that is missing or already settled, and anything else as an internal error
+func writeHoldError(ctx *gin.Context, err error) {
+	if err == sql.ErrNoRows {
+		ctx.JSON(http.StatusNotFound, errorResponse(errHoldNotFound))
+		return
+	}
+	if errors.Is(err, db.ErrHoldNotAuthorized) {
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var errAccountBusy = errors.New("another transfer from this account is in progress")
+
+// accountLocks serializes transfers from the same account inside this process
+// while letting transfers from different accounts run in parallel.
+// It reduces lock contention in the database; it does not replace database locking.
+type accountLocks struct {
+	mu    sync.Mutex
+	locks map[int64]*accountLock
+}
+
+type accountLock struct {
+	sem  chan struct{}
+	refs int
+}
+
+func newAccountLocks() *accountLocks {
+	return &accountLocks{locks: make(map[int64]*accountLock)}
+}
+
+// acquire waits for the lock of the account for at most wait (zero means no limit)
+// and returns the function releasing it. It returns errAccountBusy when the wait runs out.
+func (l *accountLocks) acquire(ctx context.Context, accountID int64, wait time.Duration) (func(), error) {
+	l.mu.Lock()
+	lock, ok := l.locks[accountID]
+	if !ok {
+		lock = &accountLock{sem: make(chan struct{}, 1)}
+		l.locks[accountID] = lock
+	}
+	lock.refs++
+	l.mu.Unlock()
+
+	var timeout <-chan time.Time
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case lock.sem <- struct{}{}:
+		return func() {
+			<-lock.sem
+			l.release(accountID, lock)
+		}, nil
+	case <-timeout:
+		l.release(accountID, lock)
+		return nil, errAccountBusy
+	case <-ctx.Done():
+		l.release(accountID, lock)
+		return nil, ctx.Err()
+	}
+}
+
+// release drops a reference to the lock and forgets it once nobody holds or waits for it
+func (l *accountLocks) release(accountID int64, lock *accountLock) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	lock.refs--
+	if lock.refs == 0 {
+		delete(l.locks, accountID)
+	}
+}
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khuongkd/simplebank/db/mock"
+	"github.com/khuongkd/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountLocksSerializeSameAccount(t *testing.T) {
+	locks := newAccountLocks()
+	accountID := util.RandomInt(1, 1000)
+
+	n := 10
+	var active, maxActive int32
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			unlock, err := locks.acquire(context.Background(), accountID, 0)
+			require.NoError(t, err)
+			defer unlock()
+
+			current := atomic.AddInt32(&active, 1)
+			for {
+				max := atomic.LoadInt32(&maxActive)
+				if current <= max || atomic.CompareAndSwapInt32(&maxActive, max, current) {
+					break
+				}
+			}
+			time.Sleep(2 * time.Millisecond)
+			atomic.AddInt32(&active, -1)
+		}()
+	}
+	wg.Wait()
+
+	require.Equal(t, int32(1), maxActive)
+	require.Empty(t, locks.locks)
+}
+
+func TestAccountLocksParallelAcrossAccounts(t *testing.T) {
+	locks := newAccountLocks()
+
+	// every goroutine holds its own account lock until all of them are inside,
+	// which only completes if different accounts do not block each other
+	n := 5
+	var inside sync.WaitGroup
+	inside.Add(n)
+	done := make(chan error, n)
+	for i := 0; i < n; i++ {
+		accountID := int64(i + 1)
+		go func() {
+			unlock, err := locks.acquire(context.Background(), accountID, time.Second)
+			if err != nil {
+				inside.Done()
+				done <- err
+				return
+			}
+			defer unlock()
+
+			inside.Done()
+			inside.Wait()
+			done <- nil
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		select {
+		case err := <-done:
+			require.NoError(t, err)
+		case <-time.After(2 * time.Second):
+			t.Fatal("transfers from different accounts did not run in parallel")
+		}
+	}
+}
+
+func TestAccountLocksMaxWait(t *testing.T) {
+	locks := newAccountLocks()
+
+	unlock, err := locks.acquire(context.Background(), 1, 0)
+	require.NoError(t, err)
+
+	_, err = locks.acquire(context.Background(), 1, 20*time.Millisecond)
+	require.ErrorIs(t, err, errAccountBusy)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = locks.acquire(ctx, 1, 0)
+	require.ErrorIs(t, err, context.Canceled)
+
+	unlock()
+	require.Empty(t, locks.locks)
+
+	unlock, err = locks.acquire(context.Background(), 1, 20*time.Millisecond)
+	require.NoError(t, err)
+	unlock()
+}
+
+func TestCreateTransferAccountBusy(t *testing.T) {
+	account1 := randomAccount()
+	account2 := randomAccount()
+	account1.Currency = "USD"
+	account2.Currency = "USD"
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+	store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+
+	server := NewServer(util.Config{TransferLockTimeout: 20 * time.Millisecond}, store)
+	unlock, err := server.transferLocks.acquire(context.Background(), account1.ID, 0)
+	require.NoError(t, err)
+	defer unlock()
+
+	recorder := httptest.NewRecorder()
+	request := newTransferRequest(t, gin.H{
+		"from_account_id": account1.ID,
+		"to_account_id":   account2.ID,
+		"amount":          10,
+		"currency":        "USD",
+	})
+	server.router.ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	requireErrorCode(t, recorder, ErrCodeAccountBusy)
+}
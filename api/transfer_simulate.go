@@ -0,0 +1,97 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+)
+
+type simulateTransfersRequest struct {
+	Transfers []transferRequest `json:"transfers" binding:"required,min=1,max=100,dive"`
+}
+
+type simulateTransfersResponse struct {
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+	// Items report the transfers in the order they were given, with the status each one would get
+	Items []batchTransferItem `json:"items"`
+	// Balances are what the accounts the transfers touch would be left with
+	Balances []db.ProjectedBalance `json:"balances"`
+}
+
+// simulateTransfers godoc
+// @Summary  Simulate a sequence of transfers without making them
+// @Description  The transfers run in order against the current balances, in a transaction that is always rolled back.
+// @Description  A transfer that would fail is reported and skipped, and the transfers after it see the balances without it.
+// @Tags     transfers
+// @Accept   json
+// @Produce  json
+// @Param    request  body      simulateTransfersRequest  true  "Transfers to simulate, at most 100"
+// @Success  200      {object}  simulateTransfersResponse
+// @Failure  400      {object}  apiError
+// @Failure  500      {object}  apiError
+// @Router   /transfers/simulate [post]
+func (server *Server) simulateTransfers(ctx *gin.Context) {
+	var req simulateTransfersRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	rsp := simulateTransfersResponse{Items: make([]batchTransferItem, len(req.Transfers))}
+
+	// the transfers that fail the checks of a single transfer never reach the store
+	var args []db.TransferTxParams
+	var indexes []int
+	var prepared []transferRequest
+	for i, transfer := range req.Transfers {
+		rsp.Items[i].Index = i
+		serr := server.checkTransfer(ctx.Request.Context(), transfer)
+		if serr == nil {
+			var arg db.TransferTxParams
+			arg, rsp.Items[i].PossibleDuplicate, serr = server.prepareTransfer(ctx.Request.Context(), transfer, prepared)
+			if serr == nil {
+				args = append(args, arg)
+				prepared = append(prepared, transfer)
+				indexes = append(indexes, i)
+				continue
+			}
+		}
+		rsp.Items[i].Status = serr.status
+		rsp.Items[i].Error = &serr.rsp
+	}
+
+	rsp.Balances = []db.ProjectedBalance{}
+	if len(args) > 0 {
+		simulation, err := server.store.SimulateTransfersTx(ctx.Request.Context(), args)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		for i, simulated := range simulation.Transfers {
+			item := &rsp.Items[indexes[i]]
+			if simulated.Err != nil {
+				serr := transferTxError(simulated.Err)
+				item.Status = serr.status
+				item.Error = &serr.rsp
+				continue
+			}
+			item.Status = http.StatusOK
+			result := server.newTransferTxResponse(simulated.Result)
+			item.Result = &result
+		}
+		if simulation.Balances != nil {
+			rsp.Balances = simulation.Balances
+		}
+	}
+
+	for _, item := range rsp.Items {
+		if item.Error != nil {
+			rsp.Failed++
+		} else {
+			rsp.Succeeded++
+		}
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
@@ -0,0 +1,169 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khuongkd/simplebank/db/mock"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimulateTransfersAPI(t *testing.T) {
+	account1 := randomAccount()
+	account2 := randomAccount()
+	account2.ID = account1.ID + 1
+	account1.Currency = "USD"
+	account2.Currency = "USD"
+
+	transfers := []gin.H{
+		{"from_account_id": account1.ID, "to_account_id": account2.ID, "amount": 1000000, "currency": "USD"},
+		{"from_account_id": account1.ID, "to_account_id": account2.ID, "amount": 10, "currency": "EUR"},
+		{"from_account_id": account2.ID, "to_account_id": account1.ID, "amount": 5, "currency": "USD"},
+	}
+	insufficientFunds := fmt.Errorf("%w: 0 available, 10 needed", db.ErrInsufficientFunds)
+
+	getAccounts := func(store *mockdb.MockStore) {
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).AnyTimes().Return(account1, nil)
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).AnyTimes().Return(account2, nil)
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "PartialFailure",
+			body: gin.H{"transfers": transfers},
+			buildStubs: func(store *mockdb.MockStore) {
+				getAccounts(store)
+				// the transfer in the wrong currency fails its checks and is left out of the simulation
+				args := []db.TransferTxParams{
+					{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 1000000},
+					{FromAccountID: account2.ID, ToAccountID: account1.ID, Amount: 5},
+				}
+				simulation := db.TransferSimulation{
+					Transfers: []db.SimulatedTransfer{
+						{Index: 0, Err: insufficientFunds},
+						{Index: 1, Result: db.TransferTxResult{Transfer: db.Transfer{ID: 7}}},
+					},
+					Balances: []db.ProjectedBalance{
+						{AccountID: account1.ID, Balance: account1.Balance + 5},
+						{AccountID: account2.ID, Balance: account2.Balance - 5},
+					},
+				}
+				store.EXPECT().SimulateTransfersTx(gomock.Any(), gomock.Eq(args)).Times(1).Return(simulation, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp simulateTransfersResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, 1, rsp.Succeeded)
+				require.Equal(t, 2, rsp.Failed)
+				require.Len(t, rsp.Items, 3)
+
+				require.Equal(t, http.StatusBadRequest, rsp.Items[0].Status)
+				require.Equal(t, ErrCodeInsufficientFunds, rsp.Items[0].Error.Code)
+
+				require.Equal(t, 1, rsp.Items[1].Index)
+				require.Equal(t, http.StatusBadRequest, rsp.Items[1].Status)
+				require.Equal(t, ErrCodeCurrencyMismatch, rsp.Items[1].Error.Code)
+
+				require.Equal(t, 2, rsp.Items[2].Index)
+				require.Equal(t, http.StatusOK, rsp.Items[2].Status)
+				require.Equal(t, int64(7), rsp.Items[2].Result.Transfer.ID)
+
+				require.Equal(t, []db.ProjectedBalance{
+					{AccountID: account1.ID, Balance: account1.Balance + 5},
+					{AccountID: account2.ID, Balance: account2.Balance - 5},
+				}, rsp.Balances)
+			},
+		},
+		{
+			name: "EveryTransferFailsItsChecks",
+			body: gin.H{"transfers": transfers[1:2]},
+			buildStubs: func(store *mockdb.MockStore) {
+				getAccounts(store)
+				store.EXPECT().SimulateTransfersTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp simulateTransfersResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, 1, rsp.Failed)
+				require.NotNil(t, rsp.Balances)
+				require.Empty(t, rsp.Balances)
+			},
+		},
+		{
+			name: "SameAccount",
+			body: gin.H{"transfers": []gin.H{
+				{"from_account_id": account1.ID, "to_account_id": account1.ID, "amount": 10, "currency": "USD"},
+			}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().SimulateTransfersTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp simulateTransfersResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, 1, rsp.Failed)
+				require.Equal(t, ErrCodeInvalidRequest, rsp.Items[0].Error.Code)
+			},
+		},
+		{
+			name: "StoreError",
+			body: gin.H{"transfers": transfers},
+			buildStubs: func(store *mockdb.MockStore) {
+				getAccounts(store)
+				store.EXPECT().SimulateTransfersTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferSimulation{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "NoTransfers",
+			body: gin.H{"transfers": []gin.H{}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SimulateTransfersTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeInvalidRequest)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			server.router.ServeHTTP(recorder, newPostRequest(t, "/transfers/simulate", tc.body))
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+)
+
+// transferStatusCompleted is the status of a transfer that was made.
+// Transfers are only recorded once they are done, so it is the status of every transfer found.
+const transferStatusCompleted = "completed"
+
+type transferStatusRequest struct {
+	Owner string  `json:"owner" binding:"required"`
+	IDs   []int64 `json:"ids" binding:"required,min=1,max=100,dive,min=1"`
+}
+
+type transferStatus struct {
+	ID     int64  `json:"id"`
+	Status string `json:"status"`
+	Amount int64  `json:"amount"`
+}
+
+type transferStatusResponse struct {
+	// Transfers are in ID order, without the IDs the owner is not party to or that do not exist
+	Transfers []transferStatus `json:"transfers"`
+}
+
+// getTransferStatuses godoc
+// @Summary  Get the status of several transfers at once
+// @Description  Only the transfers that move money out of or into an account of the owner are returned; the other IDs are left out.
+// @Tags     transfers
+// @Accept   json
+// @Produce  json
+// @Param    request  body      transferStatusRequest  true  "Owner and transfer IDs, at most 100"
+// @Success  200      {object}  transferStatusResponse
+// @Failure  400      {object}  apiError
+// @Failure  500      {object}  apiError
+// @Router   /transfers/status [post]
+func (server *Server) getTransferStatuses(ctx *gin.Context) {
+	var req transferStatusRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	transfers, err := server.store.GetTransfersByIDs(ctx.Request.Context(), db.GetTransfersByIDsParams{
+		Ids:   req.IDs,
+		Owner: req.Owner,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := transferStatusResponse{Transfers: make([]transferStatus, len(transfers))}
+	for i, transfer := range transfers {
+		rsp.Transfers[i] = transferStatus{
+			ID:     transfer.ID,
+			Status: transferStatusCompleted,
+			Amount: transfer.Amount,
+		}
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
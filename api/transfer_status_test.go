@@ -0,0 +1,131 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khuongkd/simplebank/db/mock"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTransferStatusesAPI(t *testing.T) {
+	owner := "alice"
+	owned := []db.Transfer{
+		{ID: 3, FromAccountID: 1, ToAccountID: 2, Amount: 10},
+		{ID: 8, FromAccountID: 2, ToAccountID: 1, Amount: 25},
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OwnedAndNotOwned",
+			body: gin.H{"owner": owner, "ids": []int64{8, 3, 5, 99}},
+			buildStubs: func(store *mockdb.MockStore) {
+				// 5 belongs to other owners and 99 does not exist, so the store leaves them out
+				arg := db.GetTransfersByIDsParams{Ids: []int64{8, 3, 5, 99}, Owner: owner}
+				store.EXPECT().GetTransfersByIDs(gomock.Any(), gomock.Eq(arg)).Times(1).Return(owned, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp transferStatusResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, []transferStatus{
+					{ID: 3, Status: transferStatusCompleted, Amount: 10},
+					{ID: 8, Status: transferStatusCompleted, Amount: 25},
+				}, rsp.Transfers)
+			},
+		},
+		{
+			name: "NoneOwned",
+			body: gin.H{"owner": owner, "ids": []int64{5}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfersByIDs(gomock.Any(), gomock.Any()).Times(1).Return([]db.Transfer{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.JSONEq(t, `{"transfers": []}`, recorder.Body.String())
+			},
+		},
+		{
+			name: "MissingOwner",
+			body: gin.H{"ids": []int64{3}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfersByIDs(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeInvalidRequest)
+			},
+		},
+		{
+			name: "NoIDs",
+			body: gin.H{"owner": owner, "ids": []int64{}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfersByIDs(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidID",
+			body: gin.H{"owner": owner, "ids": []int64{3, 0}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfersByIDs(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "TooManyIDs",
+			body: gin.H{"owner": owner, "ids": make([]int64, 101)},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfersByIDs(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			body: gin.H{"owner": owner, "ids": []int64{3}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfersByIDs(gomock.Any(), gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request := newPostRequest(t, "/transfers/status", tc.body)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+)
+
+type whitelistURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type whitelistDestinationURI struct {
+	ID            int64 `uri:"id" binding:"required,min=1"`
+	DestinationID int64 `uri:"destination_id" binding:"required,min=1"`
+}
+
+type setWhitelistEnabledRequest struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+}
+
+type addWhitelistDestinationRequest struct {
+	DestinationAccountID int64 `json:"destination_account_id" binding:"required,min=1"`
+}
+
+// whitelistResponse tells whether an account only sends money to whitelisted destinations, and which ones
+type whitelistResponse struct {
+	Enabled      bool    `json:"enabled"`
+	Destinations []int64 `json:"destinations"`
+}
+
+// getWhitelist godoc
+// @Summary  Get the transfer whitelist of an account
+// @Tags     accounts
+// @Produce  json
+// @Param    id   path      int  true  "Account ID"
+// @Success  200  {object}  whitelistResponse
+// @Failure  400  {object}  apiError
+// @Failure  404  {object}  apiError
+// @Failure  500  {object}  apiError
+// @Router   /accounts/{id}/whitelist [get]
+func (server *Server) getWhitelist(ctx *gin.Context) {
+	var uri whitelistURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	account, err := server.store.GetAccount(ctx.Request.Context(), uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(errAccountNotFound))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	items, err := server.store.ListWhitelistedDestinations(ctx.Request.Context(), account.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := whitelistResponse{
+		Enabled:      account.WhitelistEnabled,
+		Destinations: make([]int64, len(items)),
+	}
+	for i, item := range items {
+		rsp.Destinations[i] = item.DestinationAccountID
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// setWhitelistEnabled godoc
+// @Summary  Turn the transfer whitelist of an account on or off
+// @Tags     accounts
+// @Accept   json
+// @Produce  json
+// @Param    id       path      int                         true  "Account ID"
+// @Param    request  body      setWhitelistEnabledRequest  true  "Whether outgoing transfers are restricted to the whitelist"
+// @Success  200      {object}  accountResponse
+// @Failure  400      {object}  apiError
+// @Failure  404      {object}  apiError
+// @Failure  500      {object}  apiError
+// @Router   /accounts/{id}/whitelist [put]
+func (server *Server) setWhitelistEnabled(ctx *gin.Context) {
+	var uri whitelistURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	var req setWhitelistEnabledRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	account, err := server.store.SetAccountWhitelistEnabled(ctx.Request.Context(), db.SetAccountWhitelistEnabledParams{
+		ID:      uri.ID,
+		Enabled: *req.Enabled,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(errAccountNotFound))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, server.newAccountResponse(account))
+}
+
+// addWhitelistDestination godoc
+// @Summary  Allow an account to send money to a destination account
+// @Tags     accounts
+// @Accept   json
+// @Param    id       path  int                             true  "Account ID"
+// @Param    request  body  addWhitelistDestinationRequest  true  "Destination to whitelist"
+// @Success  204
+// @Failure  400  {object}  apiError
+// @Failure  404  {object}  apiError
+// @Failure  500  {object}  apiError
+// @Router   /accounts/{id}/whitelist/destinations [post]
+func (server *Server) addWhitelistDestination(ctx *gin.Context) {
+	var uri whitelistURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	var req addWhitelistDestinationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	for _, accountID := range []int64{uri.ID, req.DestinationAccountID} {
+		if _, err := server.store.GetAccount(ctx.Request.Context(), accountID); err != nil {
+			if err == sql.ErrNoRows {
+				ctx.JSON(http.StatusNotFound, errorResponse(errAccountNotFound))
+				return
+			}
+
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+	}
+
+	err := server.store.AddWhitelistedDestination(ctx.Request.Context(), db.AddWhitelistedDestinationParams{
+		AccountID:            uri.ID,
+		DestinationAccountID: req.DestinationAccountID,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// removeWhitelistDestination godoc
+// @Summary  Remove a destination from the transfer whitelist of an account
+// @Tags     accounts
+// @Param    id              path  int  true  "Account ID"
+// @Param    destination_id  path  int  true  "Destination account ID"
+// @Success  204
+// @Failure  400  {object}  apiError
+// @Failure  500  {object}  apiError
+// @Router   /accounts/{id}/whitelist/destinations/{destination_id} [delete]
+func (server *Server) removeWhitelistDestination(ctx *gin.Context) {
+	var uri whitelistDestinationURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	err := server.store.RemoveWhitelistedDestination(ctx.Request.Context(), db.RemoveWhitelistedDestinationParams{
+		AccountID:            uri.ID,
+		DestinationAccountID: uri.DestinationID,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
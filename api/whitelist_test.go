@@ -0,0 +1,176 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khuongkd/simplebank/db/mock"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWhitelistAPI(t *testing.T) {
+	account := randomAccount()
+	account.WhitelistEnabled = true
+	destination := randomAccount()
+
+	testCases := []struct {
+		name          string
+		method        string
+		url           string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "Get",
+			method: http.MethodGet,
+			url:    fmt.Sprintf("/accounts/%d/whitelist", account.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					ListWhitelistedDestinations(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return([]db.AccountWhitelist{{AccountID: account.ID, DestinationAccountID: destination.ID}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp whitelistResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, whitelistResponse{Enabled: true, Destinations: []int64{destination.ID}}, rsp)
+			},
+		},
+		{
+			name:   "GetNotFound",
+			method: http.MethodGet,
+			url:    fmt.Sprintf("/accounts/%d/whitelist", account.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().ListWhitelistedDestinations(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeAccountNotFound)
+			},
+		},
+		{
+			name:   "Disable",
+			method: http.MethodPut,
+			url:    fmt.Sprintf("/accounts/%d/whitelist", account.ID),
+			body:   gin.H{"enabled": false},
+			buildStubs: func(store *mockdb.MockStore) {
+				disabled := account
+				disabled.WhitelistEnabled = false
+				store.EXPECT().
+					SetAccountWhitelistEnabled(gomock.Any(), gomock.Eq(db.SetAccountWhitelistEnabledParams{ID: account.ID, Enabled: false})).
+					Times(1).
+					Return(disabled, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp accountResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.False(t, rsp.WhitelistEnabled)
+			},
+		},
+		{
+			name:   "SetWithoutEnabled",
+			method: http.MethodPut,
+			url:    fmt.Sprintf("/accounts/%d/whitelist", account.ID),
+			body:   gin.H{},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SetAccountWhitelistEnabled(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeInvalidRequest)
+			},
+		},
+		{
+			name:   "AddDestination",
+			method: http.MethodPost,
+			url:    fmt.Sprintf("/accounts/%d/whitelist/destinations", account.ID),
+			body:   gin.H{"destination_account_id": destination.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(destination.ID)).Times(1).Return(destination, nil)
+				store.EXPECT().
+					AddWhitelistedDestination(gomock.Any(), gomock.Eq(db.AddWhitelistedDestinationParams{
+						AccountID:            account.ID,
+						DestinationAccountID: destination.ID,
+					})).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name:   "AddUnknownDestination",
+			method: http.MethodPost,
+			url:    fmt.Sprintf("/accounts/%d/whitelist/destinations", account.ID),
+			body:   gin.H{"destination_account_id": destination.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(destination.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().AddWhitelistedDestination(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireErrorCode(t, recorder, ErrCodeAccountNotFound)
+			},
+		},
+		{
+			name:   "RemoveDestination",
+			method: http.MethodDelete,
+			url:    fmt.Sprintf("/accounts/%d/whitelist/destinations/%d", account.ID, destination.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RemoveWhitelistedDestination(gomock.Any(), gomock.Eq(db.RemoveWhitelistedDestinationParams{
+						AccountID:            account.ID,
+						DestinationAccountID: destination.ID,
+					})).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			var body bytes.Buffer
+			if tc.body != nil {
+				require.NoError(t, json.NewEncoder(&body).Encode(tc.body))
+			}
+			request, err := http.NewRequest(tc.method, tc.url, &body)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
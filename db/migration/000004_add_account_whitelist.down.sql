@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS account_whitelist;
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "whitelist_enabled";
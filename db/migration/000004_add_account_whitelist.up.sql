@@ -0,0 +1,14 @@
+ALTER TABLE "accounts" ADD COLUMN "whitelist_enabled" boolean NOT NULL DEFAULT false;
+
+CREATE TABLE "account_whitelist" (
+  "account_id" bigint NOT NULL,
+  "destination_account_id" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("account_id", "destination_account_id")
+);
+
+COMMENT ON COLUMN "accounts"."whitelist_enabled" IS 'outgoing transfers only go to destinations in account_whitelist';
+
+ALTER TABLE "account_whitelist" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "account_whitelist" ADD FOREIGN KEY ("destination_account_id") REFERENCES "accounts" ("id");
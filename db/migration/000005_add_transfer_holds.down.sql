@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS transfer_holds;
+
+ALTER TABLE IF EXISTS "accounts" DROP CONSTRAINT IF EXISTS "accounts_held_balance_valid";
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "held_balance";
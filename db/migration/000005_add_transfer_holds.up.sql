@@ -0,0 +1,27 @@
+ALTER TABLE "accounts" ADD COLUMN "held_balance" bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE "accounts" ADD CONSTRAINT "accounts_held_balance_valid" CHECK ("held_balance" >= 0 AND "held_balance" <= "balance");
+
+COMMENT ON COLUMN "accounts"."held_balance" IS 'part of the balance reserved by authorized holds';
+
+CREATE TABLE "transfer_holds" (
+  "id" bigserial PRIMARY KEY,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'authorized',
+  "transfer_id" bigint,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "transfer_holds" ("from_account_id");
+
+COMMENT ON COLUMN "transfer_holds"."status" IS 'authorized, captured or voided';
+
+ALTER TABLE "transfer_holds" ADD CONSTRAINT "transfer_holds_amount_positive" CHECK ("amount" > 0);
+
+ALTER TABLE "transfer_holds" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfer_holds" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfer_holds" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
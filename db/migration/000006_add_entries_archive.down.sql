@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "entries_created_at_idx";
+
+DROP TABLE IF EXISTS entries_archive;
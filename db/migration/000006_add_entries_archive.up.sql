@@ -0,0 +1,15 @@
+CREATE TABLE "entries_archive" (
+  "id" bigint PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL,
+  "archived_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "entries_archive" ("account_id");
+
+CREATE INDEX ON "entries" ("created_at");
+
+COMMENT ON TABLE "entries_archive" IS 'entries older than the retention period, moved out of entries';
+
+ALTER TABLE "entries_archive" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
@@ -0,0 +1,17 @@
+CREATE TABLE "ownership_transfer_requests" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "current_owner" varchar NOT NULL,
+  "new_owner" varchar NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "ownership_transfer_requests" ("account_id");
+
+CREATE INDEX ON "ownership_transfer_requests" ("status", "expires_at");
+
+COMMENT ON COLUMN "ownership_transfer_requests"."status" IS 'pending, accepted or expired';
+
+ALTER TABLE "ownership_transfer_requests" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
@@ -0,0 +1,3 @@
+ALTER TABLE IF EXISTS "transfers" DROP CONSTRAINT IF EXISTS "transfers_fee_non_negative";
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "fee";
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "accounts_currency_number_key";
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "number";
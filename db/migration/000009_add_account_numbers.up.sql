@@ -0,0 +1,11 @@
+ALTER TABLE "accounts" ADD COLUMN "number" bigint;
+
+UPDATE "accounts" SET "number" = "id";
+
+ALTER TABLE "accounts" ALTER COLUMN "number" SET NOT NULL;
+
+CREATE UNIQUE INDEX "accounts_currency_number_key" ON "accounts" ("currency", "number");
+
+CREATE INDEX ON "accounts" ("number");
+
+COMMENT ON COLUMN "accounts"."number" IS 'unique within the currency; unique across currencies unless numbers are assigned per currency';
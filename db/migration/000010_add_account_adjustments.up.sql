@@ -0,0 +1,19 @@
+CREATE TABLE "account_adjustments" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "entry_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "reason" varchar NOT NULL,
+  "adjusted_by" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "account_adjustments" ("account_id");
+
+COMMENT ON COLUMN "account_adjustments"."entry_id" IS 'the entry may have been moved to entries_archive, so it has no foreign key';
+
+ALTER TABLE "account_adjustments" ADD CONSTRAINT "account_adjustments_amount_non_zero" CHECK ("amount" <> 0);
+
+ALTER TABLE "account_adjustments" ADD CONSTRAINT "account_adjustments_reason_present" CHECK ("reason" <> '');
+
+ALTER TABLE "account_adjustments" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
@@ -0,0 +1,5 @@
+ALTER TABLE "accounts" ADD COLUMN "account_type" varchar NOT NULL DEFAULT 'checking';
+
+ALTER TABLE "accounts" ADD CONSTRAINT "accounts_account_type_valid" CHECK ("account_type" IN ('checking', 'savings'));
+
+COMMENT ON COLUMN "accounts"."account_type" IS 'checking or savings; selects the policy applied to transfers out of the account';
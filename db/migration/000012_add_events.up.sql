@@ -0,0 +1,13 @@
+CREATE TABLE "events" (
+  "id" bigserial PRIMARY KEY,
+  "event_type" varchar NOT NULL,
+  "payload" jsonb NOT NULL,
+  "account_ids" bigint[] NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "events" USING GIN ("account_ids");
+
+COMMENT ON TABLE "events" IS 'append-only; rows are never updated or deleted';
+
+COMMENT ON COLUMN "events"."account_ids" IS 'accounts whose balance the event changed';
@@ -0,0 +1,5 @@
+ALTER TABLE "accounts" ADD COLUMN "min_balance" bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE "accounts" ADD CONSTRAINT "accounts_min_balance_non_negative" CHECK ("min_balance" >= 0);
+
+COMMENT ON COLUMN "accounts"."min_balance" IS 'outgoing transfers cannot take the balance below it';
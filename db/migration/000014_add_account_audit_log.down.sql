@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS "accounts_audit_update" ON "accounts";
+DROP TRIGGER IF EXISTS "accounts_audit" ON "accounts";
+DROP FUNCTION IF EXISTS record_account_audit();
+DROP TABLE IF EXISTS "account_audit_log";
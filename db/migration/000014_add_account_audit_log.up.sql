@@ -0,0 +1,49 @@
+CREATE TABLE "account_audit_log" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "action" varchar NOT NULL,
+  "before" jsonb NOT NULL,
+  "after" jsonb NOT NULL,
+  "actor" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "account_audit_log" ("account_id");
+
+COMMENT ON TABLE "account_audit_log" IS 'append-only; written by the accounts_audit trigger, rows are never updated or deleted';
+
+COMMENT ON COLUMN "account_audit_log"."account_id" IS 'the account may have been deleted, so it has no foreign key';
+
+COMMENT ON COLUMN "account_audit_log"."action" IS 'insert, update or delete';
+
+COMMENT ON COLUMN "account_audit_log"."before" IS 'the account row before the change; null for an insert';
+
+COMMENT ON COLUMN "account_audit_log"."after" IS 'the account row after the change; null for a delete';
+
+COMMENT ON COLUMN "account_audit_log"."actor" IS 'simplebank.actor as set by the transaction, or the database user';
+
+CREATE FUNCTION record_account_audit() RETURNS trigger AS $$
+DECLARE
+  actor varchar := COALESCE(NULLIF(current_setting('simplebank.actor', true), ''), current_user);
+BEGIN
+  IF TG_OP = 'INSERT' THEN
+    INSERT INTO account_audit_log (account_id, action, before, after, actor)
+    VALUES (NEW.id, 'insert', 'null', to_jsonb(NEW), actor);
+  ELSIF TG_OP = 'UPDATE' THEN
+    INSERT INTO account_audit_log (account_id, action, before, after, actor)
+    VALUES (NEW.id, 'update', to_jsonb(OLD), to_jsonb(NEW), actor);
+  ELSE
+    INSERT INTO account_audit_log (account_id, action, before, after, actor)
+    VALUES (OLD.id, 'delete', to_jsonb(OLD), 'null', actor);
+  END IF;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "accounts_audit"
+AFTER INSERT OR DELETE ON "accounts"
+FOR EACH ROW EXECUTE FUNCTION record_account_audit();
+
+CREATE TRIGGER "accounts_audit_update"
+AFTER UPDATE ON "accounts"
+FOR EACH ROW WHEN (OLD.* IS DISTINCT FROM NEW.*) EXECUTE FUNCTION record_account_audit();
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "transfers_external_ref_key";
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "external_ref";
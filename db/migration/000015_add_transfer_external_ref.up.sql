@@ -0,0 +1,5 @@
+ALTER TABLE "transfers" ADD COLUMN "external_ref" varchar;
+
+CREATE UNIQUE INDEX "transfers_external_ref_key" ON "transfers" ("external_ref");
+
+COMMENT ON COLUMN "transfers"."external_ref" IS 'reference given by the integrator, unique when set';
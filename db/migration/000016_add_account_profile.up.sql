@@ -0,0 +1,6 @@
+ALTER TABLE "accounts" ADD COLUMN "nickname" varchar;
+ALTER TABLE "accounts" ADD COLUMN "metadata" jsonb NOT NULL DEFAULT '{}';
+ALTER TABLE "accounts" ADD COLUMN "frozen" boolean NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN "accounts"."metadata" IS 'free-form JSON object kept for the owner';
+COMMENT ON COLUMN "accounts"."frozen" IS 'a frozen account takes part in no transfer';
@@ -0,0 +1,5 @@
+ALTER TABLE "transfers" ADD COLUMN "category" varchar;
+
+CREATE INDEX ON "transfers" ("from_account_id", "category");
+
+COMMENT ON COLUMN "transfers"."category" IS 'spending category chosen by the sender, such as groceries or rent';
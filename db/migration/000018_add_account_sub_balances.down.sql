@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS sub_balance_entries;
+DROP TABLE IF EXISTS account_sub_balances;
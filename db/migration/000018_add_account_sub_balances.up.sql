@@ -0,0 +1,27 @@
+CREATE TABLE "account_sub_balances" (
+  "account_id" bigint NOT NULL,
+  "currency" varchar NOT NULL,
+  "balance" bigint NOT NULL DEFAULT 0,
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("account_id", "currency")
+);
+
+CREATE TABLE "sub_balance_entries" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "currency" varchar NOT NULL,
+  "amount" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "sub_balance_entries" ("account_id", "currency");
+
+COMMENT ON TABLE "account_sub_balances" IS 'money an account holds in currencies other than its own, which stays in accounts.balance';
+
+COMMENT ON COLUMN "sub_balance_entries"."amount" IS 'can be negative or positive';
+
+ALTER TABLE "account_sub_balances" ADD CONSTRAINT "account_sub_balances_balance_non_negative" CHECK ("balance" >= 0);
+
+ALTER TABLE "account_sub_balances" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "sub_balance_entries" ADD FOREIGN KEY ("account_id", "currency") REFERENCES "account_sub_balances" ("account_id", "currency");
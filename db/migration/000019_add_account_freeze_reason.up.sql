@@ -0,0 +1,7 @@
+ALTER TABLE "accounts" ADD COLUMN "freeze_reason" varchar(32);
+
+ALTER TABLE "accounts" ADD CONSTRAINT "accounts_freeze_reason_required" CHECK ("frozen" = ("freeze_reason" IS NOT NULL));
+
+CREATE INDEX ON "accounts" ("freeze_reason") WHERE "frozen";
+
+COMMENT ON COLUMN "accounts"."freeze_reason" IS 'why a frozen account was frozen, from the configured taxonomy; null while not frozen';
@@ -0,0 +1,28 @@
+CREATE TABLE "transfer_confirmations" (
+  "id" bigserial PRIMARY KEY,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "currency" varchar NOT NULL,
+  "external_ref" varchar NOT NULL DEFAULT '',
+  "category" varchar NOT NULL DEFAULT '',
+  "token_hash" varchar NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending_confirmation',
+  "transfer_id" bigint,
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "transfer_confirmations" ("from_account_id");
+
+COMMENT ON COLUMN "transfer_confirmations"."token_hash" IS 'hex SHA-256 of the confirmation token; the token itself is only given to the client';
+
+COMMENT ON COLUMN "transfer_confirmations"."status" IS 'pending_confirmation or confirmed';
+
+ALTER TABLE "transfer_confirmations" ADD CONSTRAINT "transfer_confirmations_amount_positive" CHECK ("amount" > 0);
+
+ALTER TABLE "transfer_confirmations" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfer_confirmations" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfer_confirmations" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
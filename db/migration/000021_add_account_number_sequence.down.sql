@@ -0,0 +1,3 @@
+COMMENT ON COLUMN "accounts"."number" IS 'unique within the currency; unique across currencies unless numbers are assigned per currency';
+
+DROP SEQUENCE IF EXISTS "account_numbers";
@@ -0,0 +1,7 @@
+CREATE SEQUENCE "account_numbers";
+
+SELECT setval('account_numbers', COALESCE(MAX("number"), 0) + 1, false) FROM "accounts";
+
+COMMENT ON SEQUENCE "account_numbers" IS 'hands out account numbers unique across currencies, unless numbers are assigned per currency';
+
+COMMENT ON COLUMN "accounts"."number" IS 'unique within the currency; unique across currencies when taken from account_numbers';
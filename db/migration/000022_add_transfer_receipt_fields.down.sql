@@ -0,0 +1,5 @@
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "to_account_number";
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "from_account_number";
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "currency";
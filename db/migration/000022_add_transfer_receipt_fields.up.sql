@@ -0,0 +1,24 @@
+ALTER TABLE "transfers" ADD COLUMN "currency" varchar;
+
+ALTER TABLE "transfers" ADD COLUMN "from_account_number" bigint;
+
+ALTER TABLE "transfers" ADD COLUMN "to_account_number" bigint;
+
+UPDATE "transfers" t SET
+  "currency" = f."currency",
+  "from_account_number" = f."number",
+  "to_account_number" = d."number"
+FROM "accounts" f, "accounts" d
+WHERE f."id" = t."from_account_id" AND d."id" = t."to_account_id";
+
+ALTER TABLE "transfers" ALTER COLUMN "currency" SET NOT NULL;
+
+ALTER TABLE "transfers" ALTER COLUMN "from_account_number" SET NOT NULL;
+
+ALTER TABLE "transfers" ALTER COLUMN "to_account_number" SET NOT NULL;
+
+COMMENT ON COLUMN "transfers"."currency" IS 'currency of the accounts when the transfer was made, which a later conversion does not change';
+
+COMMENT ON COLUMN "transfers"."from_account_number" IS 'number of the source account when the transfer was made';
+
+COMMENT ON COLUMN "transfers"."to_account_number" IS 'number of the destination account when the transfer was made';
@@ -6,7 +6,9 @@ package mockdb
 
 import (
 	context "context"
+	sql "database/sql"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	db "github.com/khuongkd/simplebank/db/sqlc"
@@ -35,215 +37,1454 @@ func (m *MockStore) EXPECT() *MockStoreMockRecorder {
 	return m.recorder
 }
 
+// AcceptOwnershipTransferRequest mocks base method.
+func (m *MockStore) AcceptOwnershipTransferRequest(arg0 context.Context, arg1 int64) (db.OwnershipTransferRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcceptOwnershipTransferRequest", arg0, arg1)
+	ret0, _ := ret[0].(db.OwnershipTransferRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcceptOwnershipTransferRequest indicates an expected call of AcceptOwnershipTransferRequest.
+func (mr *MockStoreMockRecorder) AcceptOwnershipTransferRequest(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptOwnershipTransferRequest", reflect.TypeOf((*MockStore)(nil).AcceptOwnershipTransferRequest), arg0, arg1)
+}
+
+// AcceptOwnershipTransferTx mocks base method.
+func (m *MockStore) AcceptOwnershipTransferTx(arg0 context.Context, arg1 int64, arg2 string) (db.AcceptOwnershipTransferTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcceptOwnershipTransferTx", arg0, arg1, arg2)
+	ret0, _ := ret[0].(db.AcceptOwnershipTransferTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcceptOwnershipTransferTx indicates an expected call of AcceptOwnershipTransferTx.
+func (mr *MockStoreMockRecorder) AcceptOwnershipTransferTx(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptOwnershipTransferTx", reflect.TypeOf((*MockStore)(nil).AcceptOwnershipTransferTx), arg0, arg1, arg2)
+}
+
+// AccountBalanceAsOf mocks base method.
+func (m *MockStore) AccountBalanceAsOf(arg0 context.Context, arg1 int64, arg2 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AccountBalanceAsOf", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AccountBalanceAsOf indicates an expected call of AccountBalanceAsOf.
+func (mr *MockStoreMockRecorder) AccountBalanceAsOf(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AccountBalanceAsOf", reflect.TypeOf((*MockStore)(nil).AccountBalanceAsOf), arg0, arg1, arg2)
+}
+
 // AddAccountBalance mocks base method.
 func (m *MockStore) AddAccountBalance(arg0 context.Context, arg1 db.AddAccountBalanceParams) (db.Account, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddAccountBalance", arg0, arg1)
-	ret0, _ := ret[0].(db.Account)
+	ret := m.ctrl.Call(m, "AddAccountBalance", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddAccountBalance indicates an expected call of AddAccountBalance.
+func (mr *MockStoreMockRecorder) AddAccountBalance(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAccountBalance", reflect.TypeOf((*MockStore)(nil).AddAccountBalance), arg0, arg1)
+}
+
+// AddAccountHeldBalance mocks base method.
+func (m *MockStore) AddAccountHeldBalance(arg0 context.Context, arg1 db.AddAccountHeldBalanceParams) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddAccountHeldBalance", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddAccountHeldBalance indicates an expected call of AddAccountHeldBalance.
+func (mr *MockStoreMockRecorder) AddAccountHeldBalance(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAccountHeldBalance", reflect.TypeOf((*MockStore)(nil).AddAccountHeldBalance), arg0, arg1)
+}
+
+// AddSubBalance mocks base method.
+func (m *MockStore) AddSubBalance(arg0 context.Context, arg1 db.AddSubBalanceParams) (db.AccountSubBalance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddSubBalance", arg0, arg1)
+	ret0, _ := ret[0].(db.AccountSubBalance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddSubBalance indicates an expected call of AddSubBalance.
+func (mr *MockStoreMockRecorder) AddSubBalance(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddSubBalance", reflect.TypeOf((*MockStore)(nil).AddSubBalance), arg0, arg1)
+}
+
+// AddWhitelistedDestination mocks base method.
+func (m *MockStore) AddWhitelistedDestination(arg0 context.Context, arg1 db.AddWhitelistedDestinationParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddWhitelistedDestination", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddWhitelistedDestination indicates an expected call of AddWhitelistedDestination.
+func (mr *MockStoreMockRecorder) AddWhitelistedDestination(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddWhitelistedDestination", reflect.TypeOf((*MockStore)(nil).AddWhitelistedDestination), arg0, arg1)
+}
+
+// AdjustAccountBalanceTx mocks base method.
+func (m *MockStore) AdjustAccountBalanceTx(arg0 context.Context, arg1 db.AdjustAccountBalanceTxParams) (db.AdjustAccountBalanceTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdjustAccountBalanceTx", arg0, arg1)
+	ret0, _ := ret[0].(db.AdjustAccountBalanceTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdjustAccountBalanceTx indicates an expected call of AdjustAccountBalanceTx.
+func (mr *MockStoreMockRecorder) AdjustAccountBalanceTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdjustAccountBalanceTx", reflect.TypeOf((*MockStore)(nil).AdjustAccountBalanceTx), arg0, arg1)
+}
+
+// ArchiveEntries mocks base method.
+func (m *MockStore) ArchiveEntries(arg0 context.Context, arg1 db.ArchiveEntriesParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ArchiveEntries", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ArchiveEntries indicates an expected call of ArchiveEntries.
+func (mr *MockStoreMockRecorder) ArchiveEntries(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ArchiveEntries", reflect.TypeOf((*MockStore)(nil).ArchiveEntries), arg0, arg1)
+}
+
+// AuthorizeTransferTx mocks base method.
+func (m *MockStore) AuthorizeTransferTx(arg0 context.Context, arg1 db.CreateTransferParams) (db.TransferHoldTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthorizeTransferTx", arg0, arg1)
+	ret0, _ := ret[0].(db.TransferHoldTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuthorizeTransferTx indicates an expected call of AuthorizeTransferTx.
+func (mr *MockStoreMockRecorder) AuthorizeTransferTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthorizeTransferTx", reflect.TypeOf((*MockStore)(nil).AuthorizeTransferTx), arg0, arg1)
+}
+
+// BatchTransferTx mocks base method.
+func (m *MockStore) BatchTransferTx(arg0 context.Context, arg1 []db.TransferTxParams) ([]db.TransferTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchTransferTx", arg0, arg1)
+	ret0, _ := ret[0].([]db.TransferTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BatchTransferTx indicates an expected call of BatchTransferTx.
+func (mr *MockStoreMockRecorder) BatchTransferTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchTransferTx", reflect.TypeOf((*MockStore)(nil).BatchTransferTx), arg0, arg1)
+}
+
+// CancelScheduledTransfer mocks base method.
+func (m *MockStore) CancelScheduledTransfer(arg0 context.Context, arg1 int64) (db.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelScheduledTransfer", arg0, arg1)
+	ret0, _ := ret[0].(db.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelScheduledTransfer indicates an expected call of CancelScheduledTransfer.
+func (mr *MockStoreMockRecorder) CancelScheduledTransfer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelScheduledTransfer", reflect.TypeOf((*MockStore)(nil).CancelScheduledTransfer), arg0, arg1)
+}
+
+// CaptureTransferTx mocks base method.
+func (m *MockStore) CaptureTransferTx(arg0 context.Context, arg1 int64) (db.TransferTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CaptureTransferTx", arg0, arg1)
+	ret0, _ := ret[0].(db.TransferTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CaptureTransferTx indicates an expected call of CaptureTransferTx.
+func (mr *MockStoreMockRecorder) CaptureTransferTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CaptureTransferTx", reflect.TypeOf((*MockStore)(nil).CaptureTransferTx), arg0, arg1)
+}
+
+// CheckSchema mocks base method.
+func (m *MockStore) CheckSchema(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckSchema", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckSchema indicates an expected call of CheckSchema.
+func (mr *MockStoreMockRecorder) CheckSchema(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckSchema", reflect.TypeOf((*MockStore)(nil).CheckSchema), arg0)
+}
+
+// CompleteScheduledTransfer mocks base method.
+func (m *MockStore) CompleteScheduledTransfer(arg0 context.Context, arg1 db.CompleteScheduledTransferParams) (db.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteScheduledTransfer", arg0, arg1)
+	ret0, _ := ret[0].(db.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompleteScheduledTransfer indicates an expected call of CompleteScheduledTransfer.
+func (mr *MockStoreMockRecorder) CompleteScheduledTransfer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteScheduledTransfer", reflect.TypeOf((*MockStore)(nil).CompleteScheduledTransfer), arg0, arg1)
+}
+
+// CompleteTransferConfirmation mocks base method.
+func (m *MockStore) CompleteTransferConfirmation(arg0 context.Context, arg1 db.CompleteTransferConfirmationParams) (db.TransferConfirmation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteTransferConfirmation", arg0, arg1)
+	ret0, _ := ret[0].(db.TransferConfirmation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompleteTransferConfirmation indicates an expected call of CompleteTransferConfirmation.
+func (mr *MockStoreMockRecorder) CompleteTransferConfirmation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteTransferConfirmation", reflect.TypeOf((*MockStore)(nil).CompleteTransferConfirmation), arg0, arg1)
+}
+
+// ConfirmTransferTx mocks base method.
+func (m *MockStore) ConfirmTransferTx(arg0 context.Context, arg1 int64, arg2 db.TransferTxParams) (db.TransferTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmTransferTx", arg0, arg1, arg2)
+	ret0, _ := ret[0].(db.TransferTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmTransferTx indicates an expected call of ConfirmTransferTx.
+func (mr *MockStoreMockRecorder) ConfirmTransferTx(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmTransferTx", reflect.TypeOf((*MockStore)(nil).ConfirmTransferTx), arg0, arg1, arg2)
+}
+
+// ConvertAccountCurrencyTx mocks base method.
+func (m *MockStore) ConvertAccountCurrencyTx(arg0 context.Context, arg1 db.ConvertAccountCurrencyTxParams) (db.ConvertAccountCurrencyTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConvertAccountCurrencyTx", arg0, arg1)
+	ret0, _ := ret[0].(db.ConvertAccountCurrencyTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConvertAccountCurrencyTx indicates an expected call of ConvertAccountCurrencyTx.
+func (mr *MockStoreMockRecorder) ConvertAccountCurrencyTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConvertAccountCurrencyTx", reflect.TypeOf((*MockStore)(nil).ConvertAccountCurrencyTx), arg0, arg1)
+}
+
+// ConvertSubBalanceTx mocks base method.
+func (m *MockStore) ConvertSubBalanceTx(arg0 context.Context, arg1 db.ConvertSubBalanceTxParams) (db.ConvertSubBalanceTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConvertSubBalanceTx", arg0, arg1)
+	ret0, _ := ret[0].(db.ConvertSubBalanceTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConvertSubBalanceTx indicates an expected call of ConvertSubBalanceTx.
+func (mr *MockStoreMockRecorder) ConvertSubBalanceTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConvertSubBalanceTx", reflect.TypeOf((*MockStore)(nil).ConvertSubBalanceTx), arg0, arg1)
+}
+
+// CountAccountsByOwnerSince mocks base method.
+func (m *MockStore) CountAccountsByOwnerSince(arg0 context.Context, arg1 db.CountAccountsByOwnerSinceParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountAccountsByOwnerSince", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountAccountsByOwnerSince indicates an expected call of CountAccountsByOwnerSince.
+func (mr *MockStoreMockRecorder) CountAccountsByOwnerSince(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountAccountsByOwnerSince", reflect.TypeOf((*MockStore)(nil).CountAccountsByOwnerSince), arg0, arg1)
+}
+
+// CountAuthorizedHoldsByAccount mocks base method.
+func (m *MockStore) CountAuthorizedHoldsByAccount(arg0 context.Context, arg1 int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountAuthorizedHoldsByAccount", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountAuthorizedHoldsByAccount indicates an expected call of CountAuthorizedHoldsByAccount.
+func (mr *MockStoreMockRecorder) CountAuthorizedHoldsByAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountAuthorizedHoldsByAccount", reflect.TypeOf((*MockStore)(nil).CountAuthorizedHoldsByAccount), arg0, arg1)
+}
+
+// CountPendingScheduledTransfersByAccount mocks base method.
+func (m *MockStore) CountPendingScheduledTransfersByAccount(arg0 context.Context, arg1 int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountPendingScheduledTransfersByAccount", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountPendingScheduledTransfersByAccount indicates an expected call of CountPendingScheduledTransfersByAccount.
+func (mr *MockStoreMockRecorder) CountPendingScheduledTransfersByAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountPendingScheduledTransfersByAccount", reflect.TypeOf((*MockStore)(nil).CountPendingScheduledTransfersByAccount), arg0, arg1)
+}
+
+// CountPendingTransferConfirmationsByAccount mocks base method.
+func (m *MockStore) CountPendingTransferConfirmationsByAccount(arg0 context.Context, arg1 db.CountPendingTransferConfirmationsByAccountParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountPendingTransferConfirmationsByAccount", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountPendingTransferConfirmationsByAccount indicates an expected call of CountPendingTransferConfirmationsByAccount.
+func (mr *MockStoreMockRecorder) CountPendingTransferConfirmationsByAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountPendingTransferConfirmationsByAccount", reflect.TypeOf((*MockStore)(nil).CountPendingTransferConfirmationsByAccount), arg0, arg1)
+}
+
+// CountRecentTransfersFromAccount mocks base method.
+func (m *MockStore) CountRecentTransfersFromAccount(arg0 context.Context, arg1 db.CountRecentTransfersFromAccountParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountRecentTransfersFromAccount", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountRecentTransfersFromAccount indicates an expected call of CountRecentTransfersFromAccount.
+func (mr *MockStoreMockRecorder) CountRecentTransfersFromAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountRecentTransfersFromAccount", reflect.TypeOf((*MockStore)(nil).CountRecentTransfersFromAccount), arg0, arg1)
+}
+
+// CountStatementEntries mocks base method.
+func (m *MockStore) CountStatementEntries(arg0 context.Context, arg1 db.CountStatementEntriesParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountStatementEntries", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountStatementEntries indicates an expected call of CountStatementEntries.
+func (mr *MockStoreMockRecorder) CountStatementEntries(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountStatementEntries", reflect.TypeOf((*MockStore)(nil).CountStatementEntries), arg0, arg1)
+}
+
+// CreateAccountAdjustment mocks base method.
+func (m *MockStore) CreateAccountAdjustment(arg0 context.Context, arg1 db.CreateAccountAdjustmentParams) (db.AccountAdjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccountAdjustment", arg0, arg1)
+	ret0, _ := ret[0].(db.AccountAdjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAccountAdjustment indicates an expected call of CreateAccountAdjustment.
+func (mr *MockStoreMockRecorder) CreateAccountAdjustment(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccountAdjustment", reflect.TypeOf((*MockStore)(nil).CreateAccountAdjustment), arg0, arg1)
+}
+
+// CreateAcount mocks base method.
+func (m *MockStore) CreateAcount(arg0 context.Context, arg1 db.CreateAcountParams) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAcount", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAcount indicates an expected call of CreateAcount.
+func (mr *MockStoreMockRecorder) CreateAcount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAcount", reflect.TypeOf((*MockStore)(nil).CreateAcount), arg0, arg1)
+}
+
+// CreateEntry mocks base method.
+func (m *MockStore) CreateEntry(arg0 context.Context, arg1 db.CreateEntryParams) (db.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateEntry", arg0, arg1)
+	ret0, _ := ret[0].(db.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateEntry indicates an expected call of CreateEntry.
+func (mr *MockStoreMockRecorder) CreateEntry(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEntry", reflect.TypeOf((*MockStore)(nil).CreateEntry), arg0, arg1)
+}
+
+// CreateEvent mocks base method.
+func (m *MockStore) CreateEvent(arg0 context.Context, arg1 db.CreateEventParams) (db.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateEvent", arg0, arg1)
+	ret0, _ := ret[0].(db.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateEvent indicates an expected call of CreateEvent.
+func (mr *MockStoreMockRecorder) CreateEvent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEvent", reflect.TypeOf((*MockStore)(nil).CreateEvent), arg0, arg1)
+}
+
+// CreateOwnershipTransferRequest mocks base method.
+func (m *MockStore) CreateOwnershipTransferRequest(arg0 context.Context, arg1 db.CreateOwnershipTransferRequestParams) (db.OwnershipTransferRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOwnershipTransferRequest", arg0, arg1)
+	ret0, _ := ret[0].(db.OwnershipTransferRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOwnershipTransferRequest indicates an expected call of CreateOwnershipTransferRequest.
+func (mr *MockStoreMockRecorder) CreateOwnershipTransferRequest(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOwnershipTransferRequest", reflect.TypeOf((*MockStore)(nil).CreateOwnershipTransferRequest), arg0, arg1)
+}
+
+// CreateScheduledTransfer mocks base method.
+func (m *MockStore) CreateScheduledTransfer(arg0 context.Context, arg1 db.CreateScheduledTransferParams) (db.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateScheduledTransfer", arg0, arg1)
+	ret0, _ := ret[0].(db.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateScheduledTransfer indicates an expected call of CreateScheduledTransfer.
+func (mr *MockStoreMockRecorder) CreateScheduledTransfer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateScheduledTransfer", reflect.TypeOf((*MockStore)(nil).CreateScheduledTransfer), arg0, arg1)
+}
+
+// CreateSubBalanceEntry mocks base method.
+func (m *MockStore) CreateSubBalanceEntry(arg0 context.Context, arg1 db.CreateSubBalanceEntryParams) (db.SubBalanceEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSubBalanceEntry", arg0, arg1)
+	ret0, _ := ret[0].(db.SubBalanceEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSubBalanceEntry indicates an expected call of CreateSubBalanceEntry.
+func (mr *MockStoreMockRecorder) CreateSubBalanceEntry(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSubBalanceEntry", reflect.TypeOf((*MockStore)(nil).CreateSubBalanceEntry), arg0, arg1)
+}
+
+// CreateTransfer mocks base method.
+func (m *MockStore) CreateTransfer(arg0 context.Context, arg1 db.CreateTransferParams) (db.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransfer", arg0, arg1)
+	ret0, _ := ret[0].(db.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTransfer indicates an expected call of CreateTransfer.
+func (mr *MockStoreMockRecorder) CreateTransfer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransfer", reflect.TypeOf((*MockStore)(nil).CreateTransfer), arg0, arg1)
+}
+
+// CreateTransferConfirmation mocks base method.
+func (m *MockStore) CreateTransferConfirmation(arg0 context.Context, arg1 db.CreateTransferConfirmationParams) (db.TransferConfirmation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransferConfirmation", arg0, arg1)
+	ret0, _ := ret[0].(db.TransferConfirmation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTransferConfirmation indicates an expected call of CreateTransferConfirmation.
+func (mr *MockStoreMockRecorder) CreateTransferConfirmation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransferConfirmation", reflect.TypeOf((*MockStore)(nil).CreateTransferConfirmation), arg0, arg1)
+}
+
+// CreateTransferHold mocks base method.
+func (m *MockStore) CreateTransferHold(arg0 context.Context, arg1 db.CreateTransferHoldParams) (db.TransferHold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTransferHold", arg0, arg1)
+	ret0, _ := ret[0].(db.TransferHold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTransferHold indicates an expected call of CreateTransferHold.
+func (mr *MockStoreMockRecorder) CreateTransferHold(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransferHold", reflect.TypeOf((*MockStore)(nil).CreateTransferHold), arg0, arg1)
+}
+
+// DeleteAccount mocks base method.
+func (m *MockStore) DeleteAccount(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccount", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAccount indicates an expected call of DeleteAccount.
+func (mr *MockStoreMockRecorder) DeleteAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccount", reflect.TypeOf((*MockStore)(nil).DeleteAccount), arg0, arg1)
+}
+
+// DeleteEntry mocks base method.
+func (m *MockStore) DeleteEntry(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteEntry", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteEntry indicates an expected call of DeleteEntry.
+func (mr *MockStoreMockRecorder) DeleteEntry(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteEntry", reflect.TypeOf((*MockStore)(nil).DeleteEntry), arg0, arg1)
+}
+
+// DeleteTransfer mocks base method.
+func (m *MockStore) DeleteTransfer(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTransfer", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTransfer indicates an expected call of DeleteTransfer.
+func (mr *MockStoreMockRecorder) DeleteTransfer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTransfer", reflect.TypeOf((*MockStore)(nil).DeleteTransfer), arg0, arg1)
+}
+
+// ExecuteScheduledTransferTx mocks base method.
+func (m *MockStore) ExecuteScheduledTransferTx(arg0 context.Context, arg1 int64) (db.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecuteScheduledTransferTx", arg0, arg1)
+	ret0, _ := ret[0].(db.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExecuteScheduledTransferTx indicates an expected call of ExecuteScheduledTransferTx.
+func (mr *MockStoreMockRecorder) ExecuteScheduledTransferTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteScheduledTransferTx", reflect.TypeOf((*MockStore)(nil).ExecuteScheduledTransferTx), arg0, arg1)
+}
+
+// ExpireOwnershipTransferRequests mocks base method.
+func (m *MockStore) ExpireOwnershipTransferRequests(arg0 context.Context, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireOwnershipTransferRequests", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpireOwnershipTransferRequests indicates an expected call of ExpireOwnershipTransferRequests.
+func (mr *MockStoreMockRecorder) ExpireOwnershipTransferRequests(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireOwnershipTransferRequests", reflect.TypeOf((*MockStore)(nil).ExpireOwnershipTransferRequests), arg0, arg1)
+}
+
+// FailScheduledTransfer mocks base method.
+func (m *MockStore) FailScheduledTransfer(arg0 context.Context, arg1 db.FailScheduledTransferParams) (db.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailScheduledTransfer", arg0, arg1)
+	ret0, _ := ret[0].(db.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FailScheduledTransfer indicates an expected call of FailScheduledTransfer.
+func (mr *MockStoreMockRecorder) FailScheduledTransfer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailScheduledTransfer", reflect.TypeOf((*MockStore)(nil).FailScheduledTransfer), arg0, arg1)
+}
+
+// FindBalanceDiscrepancies mocks base method.
+func (m *MockStore) FindBalanceDiscrepancies(arg0 context.Context, arg1 db.FindBalanceDiscrepanciesParams) ([]db.FindBalanceDiscrepanciesRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindBalanceDiscrepancies", arg0, arg1)
+	ret0, _ := ret[0].([]db.FindBalanceDiscrepanciesRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindBalanceDiscrepancies indicates an expected call of FindBalanceDiscrepancies.
+func (mr *MockStoreMockRecorder) FindBalanceDiscrepancies(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindBalanceDiscrepancies", reflect.TypeOf((*MockStore)(nil).FindBalanceDiscrepancies), arg0, arg1)
+}
+
+// FindCounterpartyAccounts mocks base method.
+func (m *MockStore) FindCounterpartyAccounts(arg0 context.Context, arg1 db.FindCounterpartyAccountsParams) ([]db.FindCounterpartyAccountsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindCounterpartyAccounts", arg0, arg1)
+	ret0, _ := ret[0].([]db.FindCounterpartyAccountsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindCounterpartyAccounts indicates an expected call of FindCounterpartyAccounts.
+func (mr *MockStoreMockRecorder) FindCounterpartyAccounts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindCounterpartyAccounts", reflect.TypeOf((*MockStore)(nil).FindCounterpartyAccounts), arg0, arg1)
+}
+
+// GetAccount mocks base method.
+func (m *MockStore) GetAccount(arg0 context.Context, arg1 int64) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccount", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccount indicates an expected call of GetAccount.
+func (mr *MockStoreMockRecorder) GetAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccount", reflect.TypeOf((*MockStore)(nil).GetAccount), arg0, arg1)
+}
+
+// GetAccountByNumberAndCurrency mocks base method.
+func (m *MockStore) GetAccountByNumberAndCurrency(arg0 context.Context, arg1 db.GetAccountByNumberAndCurrencyParams) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountByNumberAndCurrency", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountByNumberAndCurrency indicates an expected call of GetAccountByNumberAndCurrency.
+func (mr *MockStoreMockRecorder) GetAccountByNumberAndCurrency(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountByNumberAndCurrency", reflect.TypeOf((*MockStore)(nil).GetAccountByNumberAndCurrency), arg0, arg1)
+}
+
+// GetAccountForUpdate mocks base method.
+func (m *MockStore) GetAccountForUpdate(arg0 context.Context, arg1 int64) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountForUpdate indicates an expected call of GetAccountForUpdate.
+func (mr *MockStoreMockRecorder) GetAccountForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountForUpdate", reflect.TypeOf((*MockStore)(nil).GetAccountForUpdate), arg0, arg1)
+}
+
+// GetEntry mocks base method.
+func (m *MockStore) GetEntry(arg0 context.Context, arg1 int64) (db.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEntry", arg0, arg1)
+	ret0, _ := ret[0].(db.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEntry indicates an expected call of GetEntry.
+func (mr *MockStoreMockRecorder) GetEntry(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntry", reflect.TypeOf((*MockStore)(nil).GetEntry), arg0, arg1)
+}
+
+// GetOwnershipTransferRequest mocks base method.
+func (m *MockStore) GetOwnershipTransferRequest(arg0 context.Context, arg1 int64) (db.OwnershipTransferRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOwnershipTransferRequest", arg0, arg1)
+	ret0, _ := ret[0].(db.OwnershipTransferRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOwnershipTransferRequest indicates an expected call of GetOwnershipTransferRequest.
+func (mr *MockStoreMockRecorder) GetOwnershipTransferRequest(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOwnershipTransferRequest", reflect.TypeOf((*MockStore)(nil).GetOwnershipTransferRequest), arg0, arg1)
+}
+
+// GetOwnershipTransferRequestForUpdate mocks base method.
+func (m *MockStore) GetOwnershipTransferRequestForUpdate(arg0 context.Context, arg1 int64) (db.OwnershipTransferRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOwnershipTransferRequestForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.OwnershipTransferRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOwnershipTransferRequestForUpdate indicates an expected call of GetOwnershipTransferRequestForUpdate.
+func (mr *MockStoreMockRecorder) GetOwnershipTransferRequestForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOwnershipTransferRequestForUpdate", reflect.TypeOf((*MockStore)(nil).GetOwnershipTransferRequestForUpdate), arg0, arg1)
+}
+
+// GetScheduledTransfer mocks base method.
+func (m *MockStore) GetScheduledTransfer(arg0 context.Context, arg1 int64) (db.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetScheduledTransfer", arg0, arg1)
+	ret0, _ := ret[0].(db.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetScheduledTransfer indicates an expected call of GetScheduledTransfer.
+func (mr *MockStoreMockRecorder) GetScheduledTransfer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScheduledTransfer", reflect.TypeOf((*MockStore)(nil).GetScheduledTransfer), arg0, arg1)
+}
+
+// GetScheduledTransferForUpdate mocks base method.
+func (m *MockStore) GetScheduledTransferForUpdate(arg0 context.Context, arg1 int64) (db.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetScheduledTransferForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetScheduledTransferForUpdate indicates an expected call of GetScheduledTransferForUpdate.
+func (mr *MockStoreMockRecorder) GetScheduledTransferForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScheduledTransferForUpdate", reflect.TypeOf((*MockStore)(nil).GetScheduledTransferForUpdate), arg0, arg1)
+}
+
+// GetSubBalanceForUpdate mocks base method.
+func (m *MockStore) GetSubBalanceForUpdate(arg0 context.Context, arg1 db.GetSubBalanceForUpdateParams) (db.AccountSubBalance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubBalanceForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.AccountSubBalance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubBalanceForUpdate indicates an expected call of GetSubBalanceForUpdate.
+func (mr *MockStoreMockRecorder) GetSubBalanceForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubBalanceForUpdate", reflect.TypeOf((*MockStore)(nil).GetSubBalanceForUpdate), arg0, arg1)
+}
+
+// GetTransfer mocks base method.
+func (m *MockStore) GetTransfer(arg0 context.Context, arg1 int64) (db.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransfer", arg0, arg1)
+	ret0, _ := ret[0].(db.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransfer indicates an expected call of GetTransfer.
+func (mr *MockStoreMockRecorder) GetTransfer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransfer", reflect.TypeOf((*MockStore)(nil).GetTransfer), arg0, arg1)
+}
+
+// GetTransferConfirmation mocks base method.
+func (m *MockStore) GetTransferConfirmation(arg0 context.Context, arg1 int64) (db.TransferConfirmation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransferConfirmation", arg0, arg1)
+	ret0, _ := ret[0].(db.TransferConfirmation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransferConfirmation indicates an expected call of GetTransferConfirmation.
+func (mr *MockStoreMockRecorder) GetTransferConfirmation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransferConfirmation", reflect.TypeOf((*MockStore)(nil).GetTransferConfirmation), arg0, arg1)
+}
+
+// GetTransferConfirmationForUpdate mocks base method.
+func (m *MockStore) GetTransferConfirmationForUpdate(arg0 context.Context, arg1 int64) (db.TransferConfirmation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransferConfirmationForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.TransferConfirmation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransferConfirmationForUpdate indicates an expected call of GetTransferConfirmationForUpdate.
+func (mr *MockStoreMockRecorder) GetTransferConfirmationForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransferConfirmationForUpdate", reflect.TypeOf((*MockStore)(nil).GetTransferConfirmationForUpdate), arg0, arg1)
+}
+
+// GetTransferHold mocks base method.
+func (m *MockStore) GetTransferHold(arg0 context.Context, arg1 int64) (db.TransferHold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransferHold", arg0, arg1)
+	ret0, _ := ret[0].(db.TransferHold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransferHold indicates an expected call of GetTransferHold.
+func (mr *MockStoreMockRecorder) GetTransferHold(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransferHold", reflect.TypeOf((*MockStore)(nil).GetTransferHold), arg0, arg1)
+}
+
+// GetTransferHoldForUpdate mocks base method.
+func (m *MockStore) GetTransferHoldForUpdate(arg0 context.Context, arg1 int64) (db.TransferHold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransferHoldForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.TransferHold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransferHoldForUpdate indicates an expected call of GetTransferHoldForUpdate.
+func (mr *MockStoreMockRecorder) GetTransferHoldForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransferHoldForUpdate", reflect.TypeOf((*MockStore)(nil).GetTransferHoldForUpdate), arg0, arg1)
+}
+
+// GetTransfersByIDs mocks base method.
+func (m *MockStore) GetTransfersByIDs(arg0 context.Context, arg1 db.GetTransfersByIDsParams) ([]db.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransfersByIDs", arg0, arg1)
+	ret0, _ := ret[0].([]db.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransfersByIDs indicates an expected call of GetTransfersByIDs.
+func (mr *MockStoreMockRecorder) GetTransfersByIDs(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransfersByIDs", reflect.TypeOf((*MockStore)(nil).GetTransfersByIDs), arg0, arg1)
+}
+
+// IsDestinationWhitelisted mocks base method.
+func (m *MockStore) IsDestinationWhitelisted(arg0 context.Context, arg1 db.IsDestinationWhitelistedParams) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsDestinationWhitelisted", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsDestinationWhitelisted indicates an expected call of IsDestinationWhitelisted.
+func (mr *MockStoreMockRecorder) IsDestinationWhitelisted(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsDestinationWhitelisted", reflect.TypeOf((*MockStore)(nil).IsDestinationWhitelisted), arg0, arg1)
+}
+
+// ListAccountAdjustments mocks base method.
+func (m *MockStore) ListAccountAdjustments(arg0 context.Context, arg1 int64) ([]db.AccountAdjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccountAdjustments", arg0, arg1)
+	ret0, _ := ret[0].([]db.AccountAdjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccountAdjustments indicates an expected call of ListAccountAdjustments.
+func (mr *MockStoreMockRecorder) ListAccountAdjustments(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccountAdjustments", reflect.TypeOf((*MockStore)(nil).ListAccountAdjustments), arg0, arg1)
+}
+
+// ListAccountAuditLog mocks base method.
+func (m *MockStore) ListAccountAuditLog(arg0 context.Context, arg1 db.ListAccountAuditLogParams) ([]db.AccountAuditLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccountAuditLog", arg0, arg1)
+	ret0, _ := ret[0].([]db.AccountAuditLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccountAuditLog indicates an expected call of ListAccountAuditLog.
+func (mr *MockStoreMockRecorder) ListAccountAuditLog(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccountAuditLog", reflect.TypeOf((*MockStore)(nil).ListAccountAuditLog), arg0, arg1)
+}
+
+// ListAccounts mocks base method.
+func (m *MockStore) ListAccounts(arg0 context.Context, arg1 db.ListAccountsParams) ([]db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccounts", arg0, arg1)
+	ret0, _ := ret[0].([]db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccounts indicates an expected call of ListAccounts.
+func (mr *MockStoreMockRecorder) ListAccounts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccounts", reflect.TypeOf((*MockStore)(nil).ListAccounts), arg0, arg1)
+}
+
+// ListAccountsAfter mocks base method.
+func (m *MockStore) ListAccountsAfter(arg0 context.Context, arg1 db.ListAccountsAfterParams) ([]db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAccountsAfter", arg0, arg1)
+	ret0, _ := ret[0].([]db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAccountsAfter indicates an expected call of ListAccountsAfter.
+func (mr *MockStoreMockRecorder) ListAccountsAfter(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccountsAfter", reflect.TypeOf((*MockStore)(nil).ListAccountsAfter), arg0, arg1)
+}
+
+// ListArchivedEntriesByAccount mocks base method.
+func (m *MockStore) ListArchivedEntriesByAccount(arg0 context.Context, arg1 int64) ([]db.EntriesArchive, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListArchivedEntriesByAccount", arg0, arg1)
+	ret0, _ := ret[0].([]db.EntriesArchive)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListArchivedEntriesByAccount indicates an expected call of ListArchivedEntriesByAccount.
+func (mr *MockStoreMockRecorder) ListArchivedEntriesByAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListArchivedEntriesByAccount", reflect.TypeOf((*MockStore)(nil).ListArchivedEntriesByAccount), arg0, arg1)
+}
+
+// ListDormantAccounts mocks base method.
+func (m *MockStore) ListDormantAccounts(arg0 context.Context, arg1 db.ListDormantAccountsParams) ([]db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDormantAccounts", arg0, arg1)
+	ret0, _ := ret[0].([]db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDormantAccounts indicates an expected call of ListDormantAccounts.
+func (mr *MockStoreMockRecorder) ListDormantAccounts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDormantAccounts", reflect.TypeOf((*MockStore)(nil).ListDormantAccounts), arg0, arg1)
+}
+
+// ListDueScheduledTransfers mocks base method.
+func (m *MockStore) ListDueScheduledTransfers(arg0 context.Context, arg1 db.ListDueScheduledTransfersParams) ([]db.ScheduledTransfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDueScheduledTransfers", arg0, arg1)
+	ret0, _ := ret[0].([]db.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDueScheduledTransfers indicates an expected call of ListDueScheduledTransfers.
+func (mr *MockStoreMockRecorder) ListDueScheduledTransfers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDueScheduledTransfers", reflect.TypeOf((*MockStore)(nil).ListDueScheduledTransfers), arg0, arg1)
+}
+
+// ListEntries mocks base method.
+func (m *MockStore) ListEntries(arg0 context.Context, arg1 db.ListEntriesParams) ([]db.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEntries", arg0, arg1)
+	ret0, _ := ret[0].([]db.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEntries indicates an expected call of ListEntries.
+func (mr *MockStoreMockRecorder) ListEntries(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEntries", reflect.TypeOf((*MockStore)(nil).ListEntries), arg0, arg1)
+}
+
+// ListEntriesWithBalance mocks base method.
+func (m *MockStore) ListEntriesWithBalance(arg0 context.Context, arg1 int64, arg2, arg3 int32) ([]db.EntryWithBalance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEntriesWithBalance", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]db.EntryWithBalance)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// AddAccountBalance indicates an expected call of AddAccountBalance.
-func (mr *MockStoreMockRecorder) AddAccountBalance(arg0, arg1 interface{}) *gomock.Call {
+// ListEntriesWithBalance indicates an expected call of ListEntriesWithBalance.
+func (mr *MockStoreMockRecorder) ListEntriesWithBalance(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAccountBalance", reflect.TypeOf((*MockStore)(nil).AddAccountBalance), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEntriesWithBalance", reflect.TypeOf((*MockStore)(nil).ListEntriesWithBalance), arg0, arg1, arg2, arg3)
 }
 
-// CreateAcount mocks base method.
-func (m *MockStore) CreateAcount(arg0 context.Context, arg1 db.CreateAcountParams) (db.Account, error) {
+// ListEntriesWithRunningBalance mocks base method.
+func (m *MockStore) ListEntriesWithRunningBalance(arg0 context.Context, arg1 db.ListEntriesWithRunningBalanceParams) ([]db.ListEntriesWithRunningBalanceRow, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateAcount", arg0, arg1)
-	ret0, _ := ret[0].(db.Account)
+	ret := m.ctrl.Call(m, "ListEntriesWithRunningBalance", arg0, arg1)
+	ret0, _ := ret[0].([]db.ListEntriesWithRunningBalanceRow)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateAcount indicates an expected call of CreateAcount.
-func (mr *MockStoreMockRecorder) CreateAcount(arg0, arg1 interface{}) *gomock.Call {
+// ListEntriesWithRunningBalance indicates an expected call of ListEntriesWithRunningBalance.
+func (mr *MockStoreMockRecorder) ListEntriesWithRunningBalance(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAcount", reflect.TypeOf((*MockStore)(nil).CreateAcount), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEntriesWithRunningBalance", reflect.TypeOf((*MockStore)(nil).ListEntriesWithRunningBalance), arg0, arg1)
 }
 
-// CreateEntry mocks base method.
-func (m *MockStore) CreateEntry(arg0 context.Context, arg1 db.CreateEntryParams) (db.Entry, error) {
+// ListEvents mocks base method.
+func (m *MockStore) ListEvents(arg0 context.Context, arg1 db.ListEventsParams) ([]db.Event, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateEntry", arg0, arg1)
-	ret0, _ := ret[0].(db.Entry)
+	ret := m.ctrl.Call(m, "ListEvents", arg0, arg1)
+	ret0, _ := ret[0].([]db.Event)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateEntry indicates an expected call of CreateEntry.
-func (mr *MockStoreMockRecorder) CreateEntry(arg0, arg1 interface{}) *gomock.Call {
+// ListEvents indicates an expected call of ListEvents.
+func (mr *MockStoreMockRecorder) ListEvents(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEntry", reflect.TypeOf((*MockStore)(nil).CreateEntry), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEvents", reflect.TypeOf((*MockStore)(nil).ListEvents), arg0, arg1)
 }
 
-// CreateTransfer mocks base method.
-func (m *MockStore) CreateTransfer(arg0 context.Context, arg1 db.CreateTransferParams) (db.Transfer, error) {
+// ListEventsByAccount mocks base method.
+func (m *MockStore) ListEventsByAccount(arg0 context.Context, arg1 int64) ([]db.Event, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateTransfer", arg0, arg1)
-	ret0, _ := ret[0].(db.Transfer)
+	ret := m.ctrl.Call(m, "ListEventsByAccount", arg0, arg1)
+	ret0, _ := ret[0].([]db.Event)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateTransfer indicates an expected call of CreateTransfer.
-func (mr *MockStoreMockRecorder) CreateTransfer(arg0, arg1 interface{}) *gomock.Call {
+// ListEventsByAccount indicates an expected call of ListEventsByAccount.
+func (mr *MockStoreMockRecorder) ListEventsByAccount(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransfer", reflect.TypeOf((*MockStore)(nil).CreateTransfer), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEventsByAccount", reflect.TypeOf((*MockStore)(nil).ListEventsByAccount), arg0, arg1)
 }
 
-// DeleteAccount mocks base method.
-func (m *MockStore) DeleteAccount(arg0 context.Context, arg1 int64) error {
+// ListFrozenAccounts mocks base method.
+func (m *MockStore) ListFrozenAccounts(arg0 context.Context, arg1 db.ListFrozenAccountsParams) ([]db.Account, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteAccount", arg0, arg1)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "ListFrozenAccounts", arg0, arg1)
+	ret0, _ := ret[0].([]db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// DeleteAccount indicates an expected call of DeleteAccount.
-func (mr *MockStoreMockRecorder) DeleteAccount(arg0, arg1 interface{}) *gomock.Call {
+// ListFrozenAccounts indicates an expected call of ListFrozenAccounts.
+func (mr *MockStoreMockRecorder) ListFrozenAccounts(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccount", reflect.TypeOf((*MockStore)(nil).DeleteAccount), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFrozenAccounts", reflect.TypeOf((*MockStore)(nil).ListFrozenAccounts), arg0, arg1)
 }
 
-// DeleteEntry mocks base method.
-func (m *MockStore) DeleteEntry(arg0 context.Context, arg1 int64) error {
+// ListScheduledTransfers mocks base method.
+func (m *MockStore) ListScheduledTransfers(arg0 context.Context, arg1 db.ListScheduledTransfersParams) ([]db.ScheduledTransfer, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteEntry", arg0, arg1)
+	ret := m.ctrl.Call(m, "ListScheduledTransfers", arg0, arg1)
+	ret0, _ := ret[0].([]db.ScheduledTransfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListScheduledTransfers indicates an expected call of ListScheduledTransfers.
+func (mr *MockStoreMockRecorder) ListScheduledTransfers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListScheduledTransfers", reflect.TypeOf((*MockStore)(nil).ListScheduledTransfers), arg0, arg1)
+}
+
+// ListStatementEntries mocks base method.
+func (m *MockStore) ListStatementEntries(arg0 context.Context, arg1 db.ListStatementEntriesParams) ([]db.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListStatementEntries", arg0, arg1)
+	ret0, _ := ret[0].([]db.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListStatementEntries indicates an expected call of ListStatementEntries.
+func (mr *MockStoreMockRecorder) ListStatementEntries(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListStatementEntries", reflect.TypeOf((*MockStore)(nil).ListStatementEntries), arg0, arg1)
+}
+
+// ListSubBalances mocks base method.
+func (m *MockStore) ListSubBalances(arg0 context.Context, arg1 int64) ([]db.AccountSubBalance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSubBalances", arg0, arg1)
+	ret0, _ := ret[0].([]db.AccountSubBalance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSubBalances indicates an expected call of ListSubBalances.
+func (mr *MockStoreMockRecorder) ListSubBalances(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSubBalances", reflect.TypeOf((*MockStore)(nil).ListSubBalances), arg0, arg1)
+}
+
+// ListTransfers mocks base method.
+func (m *MockStore) ListTransfers(arg0 context.Context, arg1 db.ListTransfersParams) ([]db.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTransfers", arg0, arg1)
+	ret0, _ := ret[0].([]db.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTransfers indicates an expected call of ListTransfers.
+func (mr *MockStoreMockRecorder) ListTransfers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTransfers", reflect.TypeOf((*MockStore)(nil).ListTransfers), arg0, arg1)
+}
+
+// ListWhitelistedDestinations mocks base method.
+func (m *MockStore) ListWhitelistedDestinations(arg0 context.Context, arg1 int64) ([]db.AccountWhitelist, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWhitelistedDestinations", arg0, arg1)
+	ret0, _ := ret[0].([]db.AccountWhitelist)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWhitelistedDestinations indicates an expected call of ListWhitelistedDestinations.
+func (mr *MockStoreMockRecorder) ListWhitelistedDestinations(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWhitelistedDestinations", reflect.TypeOf((*MockStore)(nil).ListWhitelistedDestinations), arg0, arg1)
+}
+
+// PatchAccount mocks base method.
+func (m *MockStore) PatchAccount(arg0 context.Context, arg1 db.PatchAccountParams) (db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchAccount", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PatchAccount indicates an expected call of PatchAccount.
+func (mr *MockStoreMockRecorder) PatchAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchAccount", reflect.TypeOf((*MockStore)(nil).PatchAccount), arg0, arg1)
+}
+
+// Ping mocks base method.
+func (m *MockStore) Ping(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", arg0)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteEntry indicates an expected call of DeleteEntry.
-func (mr *MockStoreMockRecorder) DeleteEntry(arg0, arg1 interface{}) *gomock.Call {
+// Ping indicates an expected call of Ping.
+func (mr *MockStoreMockRecorder) Ping(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteEntry", reflect.TypeOf((*MockStore)(nil).DeleteEntry), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockStore)(nil).Ping), arg0)
 }
 
-// DeleteTransfer mocks base method.
-func (m *MockStore) DeleteTransfer(arg0 context.Context, arg1 int64) error {
+// RecentSimilarTransferExists mocks base method.
+func (m *MockStore) RecentSimilarTransferExists(arg0 context.Context, arg1 db.RecentSimilarTransferExistsParams) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteTransfer", arg0, arg1)
+	ret := m.ctrl.Call(m, "RecentSimilarTransferExists", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecentSimilarTransferExists indicates an expected call of RecentSimilarTransferExists.
+func (mr *MockStoreMockRecorder) RecentSimilarTransferExists(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecentSimilarTransferExists", reflect.TypeOf((*MockStore)(nil).RecentSimilarTransferExists), arg0, arg1)
+}
+
+// ReconcileAccount mocks base method.
+func (m *MockStore) ReconcileAccount(arg0 context.Context, arg1 int64) (db.AccountReconciliation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReconcileAccount", arg0, arg1)
+	ret0, _ := ret[0].(db.AccountReconciliation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReconcileAccount indicates an expected call of ReconcileAccount.
+func (mr *MockStoreMockRecorder) ReconcileAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReconcileAccount", reflect.TypeOf((*MockStore)(nil).ReconcileAccount), arg0, arg1)
+}
+
+// RemoveWhitelistedDestination mocks base method.
+func (m *MockStore) RemoveWhitelistedDestination(arg0 context.Context, arg1 db.RemoveWhitelistedDestinationParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveWhitelistedDestination", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteTransfer indicates an expected call of DeleteTransfer.
-func (mr *MockStoreMockRecorder) DeleteTransfer(arg0, arg1 interface{}) *gomock.Call {
+// RemoveWhitelistedDestination indicates an expected call of RemoveWhitelistedDestination.
+func (mr *MockStoreMockRecorder) RemoveWhitelistedDestination(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTransfer", reflect.TypeOf((*MockStore)(nil).DeleteTransfer), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveWhitelistedDestination", reflect.TypeOf((*MockStore)(nil).RemoveWhitelistedDestination), arg0, arg1)
 }
 
-// GetAccount mocks base method.
-func (m *MockStore) GetAccount(arg0 context.Context, arg1 int64) (db.Account, error) {
+// SetAccountCurrency mocks base method.
+func (m *MockStore) SetAccountCurrency(arg0 context.Context, arg1 db.SetAccountCurrencyParams) (db.Account, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAccount", arg0, arg1)
+	ret := m.ctrl.Call(m, "SetAccountCurrency", arg0, arg1)
 	ret0, _ := ret[0].(db.Account)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAccount indicates an expected call of GetAccount.
-func (mr *MockStoreMockRecorder) GetAccount(arg0, arg1 interface{}) *gomock.Call {
+// SetAccountCurrency indicates an expected call of SetAccountCurrency.
+func (mr *MockStoreMockRecorder) SetAccountCurrency(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccount", reflect.TypeOf((*MockStore)(nil).GetAccount), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAccountCurrency", reflect.TypeOf((*MockStore)(nil).SetAccountCurrency), arg0, arg1)
 }
 
-// GetAccountForUpdate mocks base method.
-func (m *MockStore) GetAccountForUpdate(arg0 context.Context, arg1 int64) (db.Account, error) {
+// SetAccountMinBalance mocks base method.
+func (m *MockStore) SetAccountMinBalance(arg0 context.Context, arg1 db.SetAccountMinBalanceParams) (db.Account, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAccountForUpdate", arg0, arg1)
+	ret := m.ctrl.Call(m, "SetAccountMinBalance", arg0, arg1)
 	ret0, _ := ret[0].(db.Account)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAccountForUpdate indicates an expected call of GetAccountForUpdate.
-func (mr *MockStoreMockRecorder) GetAccountForUpdate(arg0, arg1 interface{}) *gomock.Call {
+// SetAccountMinBalance indicates an expected call of SetAccountMinBalance.
+func (mr *MockStoreMockRecorder) SetAccountMinBalance(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountForUpdate", reflect.TypeOf((*MockStore)(nil).GetAccountForUpdate), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAccountMinBalance", reflect.TypeOf((*MockStore)(nil).SetAccountMinBalance), arg0, arg1)
 }
 
-// GetEntry mocks base method.
-func (m *MockStore) GetEntry(arg0 context.Context, arg1 int64) (db.Entry, error) {
+// SetAccountOwner mocks base method.
+func (m *MockStore) SetAccountOwner(arg0 context.Context, arg1 db.SetAccountOwnerParams) (db.Account, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetEntry", arg0, arg1)
-	ret0, _ := ret[0].(db.Entry)
+	ret := m.ctrl.Call(m, "SetAccountOwner", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetEntry indicates an expected call of GetEntry.
-func (mr *MockStoreMockRecorder) GetEntry(arg0, arg1 interface{}) *gomock.Call {
+// SetAccountOwner indicates an expected call of SetAccountOwner.
+func (mr *MockStoreMockRecorder) SetAccountOwner(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntry", reflect.TypeOf((*MockStore)(nil).GetEntry), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAccountOwner", reflect.TypeOf((*MockStore)(nil).SetAccountOwner), arg0, arg1)
 }
 
-// GetTransfer mocks base method.
-func (m *MockStore) GetTransfer(arg0 context.Context, arg1 int64) (db.Transfer, error) {
+// SetAccountWhitelistEnabled mocks base method.
+func (m *MockStore) SetAccountWhitelistEnabled(arg0 context.Context, arg1 db.SetAccountWhitelistEnabledParams) (db.Account, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetTransfer", arg0, arg1)
-	ret0, _ := ret[0].(db.Transfer)
+	ret := m.ctrl.Call(m, "SetAccountWhitelistEnabled", arg0, arg1)
+	ret0, _ := ret[0].(db.Account)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetTransfer indicates an expected call of GetTransfer.
-func (mr *MockStoreMockRecorder) GetTransfer(arg0, arg1 interface{}) *gomock.Call {
+// SetAccountWhitelistEnabled indicates an expected call of SetAccountWhitelistEnabled.
+func (mr *MockStoreMockRecorder) SetAccountWhitelistEnabled(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransfer", reflect.TypeOf((*MockStore)(nil).GetTransfer), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAccountWhitelistEnabled", reflect.TypeOf((*MockStore)(nil).SetAccountWhitelistEnabled), arg0, arg1)
 }
 
-// ListAccounts mocks base method.
-func (m *MockStore) ListAccounts(arg0 context.Context, arg1 db.ListAccountsParams) ([]db.Account, error) {
+// SettleTransferHold mocks base method.
+func (m *MockStore) SettleTransferHold(arg0 context.Context, arg1 db.SettleTransferHoldParams) (db.TransferHold, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListAccounts", arg0, arg1)
-	ret0, _ := ret[0].([]db.Account)
+	ret := m.ctrl.Call(m, "SettleTransferHold", arg0, arg1)
+	ret0, _ := ret[0].(db.TransferHold)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListAccounts indicates an expected call of ListAccounts.
-func (mr *MockStoreMockRecorder) ListAccounts(arg0, arg1 interface{}) *gomock.Call {
+// SettleTransferHold indicates an expected call of SettleTransferHold.
+func (mr *MockStoreMockRecorder) SettleTransferHold(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAccounts", reflect.TypeOf((*MockStore)(nil).ListAccounts), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SettleTransferHold", reflect.TypeOf((*MockStore)(nil).SettleTransferHold), arg0, arg1)
 }
 
-// ListEntries mocks base method.
-func (m *MockStore) ListEntries(arg0 context.Context, arg1 db.ListEntriesParams) ([]db.Entry, error) {
+// SimulateTransfersTx mocks base method.
+func (m *MockStore) SimulateTransfersTx(arg0 context.Context, arg1 []db.TransferTxParams) (db.TransferSimulation, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListEntries", arg0, arg1)
-	ret0, _ := ret[0].([]db.Entry)
+	ret := m.ctrl.Call(m, "SimulateTransfersTx", arg0, arg1)
+	ret0, _ := ret[0].(db.TransferSimulation)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListEntries indicates an expected call of ListEntries.
-func (mr *MockStoreMockRecorder) ListEntries(arg0, arg1 interface{}) *gomock.Call {
+// SimulateTransfersTx indicates an expected call of SimulateTransfersTx.
+func (mr *MockStoreMockRecorder) SimulateTransfersTx(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEntries", reflect.TypeOf((*MockStore)(nil).ListEntries), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SimulateTransfersTx", reflect.TypeOf((*MockStore)(nil).SimulateTransfersTx), arg0, arg1)
 }
 
-// ListTransfers mocks base method.
-func (m *MockStore) ListTransfers(arg0 context.Context, arg1 db.ListTransfersParams) ([]db.Transfer, error) {
+// SpendingByCategory mocks base method.
+func (m *MockStore) SpendingByCategory(arg0 context.Context, arg1 db.SpendingByCategoryParams) ([]db.SpendingByCategoryRow, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListTransfers", arg0, arg1)
-	ret0, _ := ret[0].([]db.Transfer)
+	ret := m.ctrl.Call(m, "SpendingByCategory", arg0, arg1)
+	ret0, _ := ret[0].([]db.SpendingByCategoryRow)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListTransfers indicates an expected call of ListTransfers.
-func (mr *MockStoreMockRecorder) ListTransfers(arg0, arg1 interface{}) *gomock.Call {
+// SpendingByCategory indicates an expected call of SpendingByCategory.
+func (mr *MockStoreMockRecorder) SpendingByCategory(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTransfers", reflect.TypeOf((*MockStore)(nil).ListTransfers), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SpendingByCategory", reflect.TypeOf((*MockStore)(nil).SpendingByCategory), arg0, arg1)
+}
+
+// Stats mocks base method.
+func (m *MockStore) Stats() sql.DBStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(sql.DBStats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockStoreMockRecorder) Stats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockStore)(nil).Stats))
+}
+
+// StreamAllAccounts mocks base method.
+func (m *MockStore) StreamAllAccounts(arg0 context.Context, arg1 func(db.Account) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamAllAccounts", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamAllAccounts indicates an expected call of StreamAllAccounts.
+func (mr *MockStoreMockRecorder) StreamAllAccounts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamAllAccounts", reflect.TypeOf((*MockStore)(nil).StreamAllAccounts), arg0, arg1)
+}
+
+// SumEntriesByAccount mocks base method.
+func (m *MockStore) SumEntriesByAccount(arg0 context.Context, arg1 int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SumEntriesByAccount", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SumEntriesByAccount indicates an expected call of SumEntriesByAccount.
+func (mr *MockStoreMockRecorder) SumEntriesByAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SumEntriesByAccount", reflect.TypeOf((*MockStore)(nil).SumEntriesByAccount), arg0, arg1)
+}
+
+// SumEntriesByAccountAsOf mocks base method.
+func (m *MockStore) SumEntriesByAccountAsOf(arg0 context.Context, arg1 db.SumEntriesByAccountAsOfParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SumEntriesByAccountAsOf", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SumEntriesByAccountAsOf indicates an expected call of SumEntriesByAccountAsOf.
+func (mr *MockStoreMockRecorder) SumEntriesByAccountAsOf(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SumEntriesByAccountAsOf", reflect.TypeOf((*MockStore)(nil).SumEntriesByAccountAsOf), arg0, arg1)
+}
+
+// SumTransferFeesByAccount mocks base method.
+func (m *MockStore) SumTransferFeesByAccount(arg0 context.Context, arg1 int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SumTransferFeesByAccount", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SumTransferFeesByAccount indicates an expected call of SumTransferFeesByAccount.
+func (mr *MockStoreMockRecorder) SumTransferFeesByAccount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SumTransferFeesByAccount", reflect.TypeOf((*MockStore)(nil).SumTransferFeesByAccount), arg0, arg1)
+}
+
+// SweepOwnAccountsTx mocks base method.
+func (m *MockStore) SweepOwnAccountsTx(arg0 context.Context, arg1, arg2 int64, arg3 string) (db.TransferTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SweepOwnAccountsTx", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(db.TransferTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SweepOwnAccountsTx indicates an expected call of SweepOwnAccountsTx.
+func (mr *MockStoreMockRecorder) SweepOwnAccountsTx(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SweepOwnAccountsTx", reflect.TypeOf((*MockStore)(nil).SweepOwnAccountsTx), arg0, arg1, arg2, arg3)
+}
+
+// TopAccountsByBalance mocks base method.
+func (m *MockStore) TopAccountsByBalance(arg0 context.Context, arg1 db.TopAccountsByBalanceParams) ([]db.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TopAccountsByBalance", arg0, arg1)
+	ret0, _ := ret[0].([]db.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TopAccountsByBalance indicates an expected call of TopAccountsByBalance.
+func (mr *MockStoreMockRecorder) TopAccountsByBalance(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TopAccountsByBalance", reflect.TypeOf((*MockStore)(nil).TopAccountsByBalance), arg0, arg1)
+}
+
+// TotalSystemBalance mocks base method.
+func (m *MockStore) TotalSystemBalance(arg0 context.Context) ([]db.TotalSystemBalanceRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TotalSystemBalance", arg0)
+	ret0, _ := ret[0].([]db.TotalSystemBalanceRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TotalSystemBalance indicates an expected call of TotalSystemBalance.
+func (mr *MockStoreMockRecorder) TotalSystemBalance(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TotalSystemBalance", reflect.TypeOf((*MockStore)(nil).TotalSystemBalance), arg0)
 }
 
 // TransferTx mocks base method.
-func (m *MockStore) TransferTx(arg0 context.Context, arg1 db.CreateTransferParams) (db.TransferTxResult, error) {
+func (m *MockStore) TransferTx(arg0 context.Context, arg1 db.TransferTxParams) (db.TransferTxResult, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "TransferTx", arg0, arg1)
 	ret0, _ := ret[0].(db.TransferTxResult)
@@ -301,3 +1542,18 @@ func (mr *MockStoreMockRecorder) UpdateTransfer(arg0, arg1 interface{}) *gomock.
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTransfer", reflect.TypeOf((*MockStore)(nil).UpdateTransfer), arg0, arg1)
 }
+
+// VoidTransferTx mocks base method.
+func (m *MockStore) VoidTransferTx(arg0 context.Context, arg1 int64) (db.TransferHoldTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VoidTransferTx", arg0, arg1)
+	ret0, _ := ret[0].(db.TransferHoldTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VoidTransferTx indicates an expected call of VoidTransferTx.
+func (mr *MockStoreMockRecorder) VoidTransferTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VoidTransferTx", reflect.TypeOf((*MockStore)(nil).VoidTransferTx), arg0, arg1)
+}
@@ -1,15 +1,30 @@
 -- name: CreateAcount :one
+-- With number_per_currency set the account takes the next number of its currency; concurrent inserts may
+-- pick the same number, which the unique index rejects. Otherwise the number comes from the account_numbers
+-- sequence, so it is unique across currencies, which the per-currency index alone cannot guarantee.
+-- An empty account_type creates a checking account.
 INSERT INTO accounts (
-  owner, balance, currency
-) VALUES (
-  $1, $2, $3
+  owner, balance, currency, number, account_type
 )
+SELECT
+  sqlc.arg(owner)::varchar, sqlc.arg(balance)::bigint, sqlc.arg(currency)::varchar,
+  CASE
+    WHEN sqlc.arg(number_per_currency)::bool THEN COALESCE(MAX(a.number), 0) + 1
+    ELSE nextval('account_numbers')
+  END,
+  COALESCE(NULLIF(sqlc.arg(account_type)::varchar, ''), 'checking')
+FROM accounts a
+WHERE a.currency = sqlc.arg(currency)::varchar
 RETURNING *;
 
 -- name: GetAccount :one
 SELECT * FROM accounts
 WHERE id = $1 LIMIT 1;
 
+-- name: GetAccountByNumberAndCurrency :one
+SELECT * FROM accounts
+WHERE number = $1 AND currency = $2 LIMIT 1;
+
 -- name: GetAccountForUpdate :one
 SELECT * FROM accounts
 WHERE id = $1 LIMIT 1
@@ -21,11 +36,85 @@ ORDER BY id
 LIMIT $1
 OFFSET $2;
 
+-- name: ListAccountsAfter :many
+SELECT * FROM accounts
+WHERE id > sqlc.arg(after_id)
+ORDER BY id
+LIMIT sqlc.arg(batch_size);
+
+-- name: TopAccountsByBalance :many
+-- Lists the accounts of a currency from the largest balance down; ties go to the oldest account.
+SELECT * FROM accounts
+WHERE currency = sqlc.arg(currency)
+ORDER BY balance DESC, id
+LIMIT sqlc.arg('limit');
+
+-- name: CountAccountsByOwnerSince :one
+SELECT count(*) FROM accounts
+WHERE owner = sqlc.arg(owner) AND created_at >= sqlc.arg(since);
+
 -- name: UpdateAccount :one
 UPDATE accounts SET balance = $1 WHERE id = $2 RETURNING *;
 
 -- name: AddAccountBalance :one
 UPDATE accounts SET balance = balance + sqlc.arg(amount) WHERE id = sqlc.arg(id) RETURNING *;
 
+-- name: ListDormantAccounts :many
+-- An account is dormant when it has no entry, archived or not, created at or after since.
+SELECT * FROM accounts
+WHERE NOT EXISTS (
+  SELECT 1 FROM entries e WHERE e.account_id = accounts.id AND e.created_at >= sqlc.arg(since)
+) AND NOT EXISTS (
+  SELECT 1 FROM entries_archive ea WHERE ea.account_id = accounts.id AND ea.created_at >= sqlc.arg(since)
+)
+ORDER BY id
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: SetAccountCurrency :one
+-- With number_per_currency set the account is renumbered, since its number may be taken in the new currency.
+UPDATE accounts SET
+  currency = sqlc.arg(currency),
+  balance = sqlc.arg(balance),
+  min_balance = sqlc.arg(min_balance),
+  number = CASE
+    WHEN sqlc.arg(number_per_currency)::bool
+    THEN (SELECT COALESCE(MAX(a.number), 0) + 1 FROM accounts a WHERE a.currency = sqlc.arg(currency))
+    ELSE accounts.number
+  END
+WHERE accounts.id = sqlc.arg(id)
+RETURNING *;
+
+-- name: SetAccountOwner :one
+UPDATE accounts SET owner = sqlc.arg(owner)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: PatchAccount :one
+-- Only the fields whose set_ flag is true change; the others keep their current value.
+-- An empty nickname removes it.
+-- The freeze reason changes along with frozen; unfreezing passes an empty one, which removes it.
+-- The flags stand in for NULL parameters, which sqlc cannot generate for NOT NULL columns.
+UPDATE accounts SET
+  nickname = CASE WHEN sqlc.arg(set_nickname)::bool THEN NULLIF(sqlc.arg(nickname)::varchar, '') ELSE nickname END,
+  metadata = CASE WHEN sqlc.arg(set_metadata)::bool THEN sqlc.arg(metadata)::text::jsonb ELSE metadata END,
+  frozen = CASE WHEN sqlc.arg(set_frozen)::bool THEN sqlc.arg(frozen)::bool ELSE frozen END,
+  freeze_reason = CASE WHEN sqlc.arg(set_frozen)::bool THEN NULLIF(sqlc.arg(freeze_reason)::varchar, '') ELSE freeze_reason END
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: ListFrozenAccounts :many
+-- An empty reason lists every frozen account.
+SELECT * FROM accounts
+WHERE frozen AND (sqlc.arg(reason)::varchar = '' OR freeze_reason = sqlc.arg(reason)::varchar)
+ORDER BY id
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: SetAccountMinBalance :one
+UPDATE accounts SET min_balance = sqlc.arg(min_balance)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
 -- name: DeleteAccount :exec
 DELETE FROM accounts WHERE id = $1;
\ No newline at end of file
@@ -0,0 +1,12 @@
+-- name: CreateAccountAdjustment :one
+INSERT INTO account_adjustments (
+  account_id, entry_id, amount, reason, adjusted_by
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+RETURNING *;
+
+-- name: ListAccountAdjustments :many
+SELECT * FROM account_adjustments
+WHERE account_id = $1
+ORDER BY id;
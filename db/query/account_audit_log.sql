@@ -0,0 +1,6 @@
+-- name: ListAccountAuditLog :many
+SELECT * FROM account_audit_log
+WHERE account_id = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
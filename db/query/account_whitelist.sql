@@ -0,0 +1,27 @@
+-- name: SetAccountWhitelistEnabled :one
+UPDATE accounts SET whitelist_enabled = sqlc.arg(enabled)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: AddWhitelistedDestination :exec
+INSERT INTO account_whitelist (
+  account_id, destination_account_id
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (account_id, destination_account_id) DO NOTHING;
+
+-- name: RemoveWhitelistedDestination :exec
+DELETE FROM account_whitelist
+WHERE account_id = $1 AND destination_account_id = $2;
+
+-- name: ListWhitelistedDestinations :many
+SELECT * FROM account_whitelist
+WHERE account_id = $1
+ORDER BY destination_account_id;
+
+-- name: IsDestinationWhitelisted :one
+SELECT EXISTS (
+  SELECT 1 FROM account_whitelist
+  WHERE account_id = $1 AND destination_account_id = $2
+);
@@ -18,4 +18,90 @@ DELETE FROM entries WHERE id = $1;
 SELECT * FROM entries WHERE id = $1;
 
 -- name: ListEntries :many
-SELECT * FROM entries ORDER BY id Limit $1 OFFSET $2;
\ No newline at end of file
+SELECT * FROM entries ORDER BY id Limit $1 OFFSET $2;
+
+-- name: SumEntriesByAccount :one
+SELECT (
+  COALESCE((SELECT SUM(e.amount) FROM entries e WHERE e.account_id = sqlc.arg(account_id)), 0) +
+  COALESCE((SELECT SUM(a.amount) FROM entries_archive a WHERE a.account_id = sqlc.arg(account_id)), 0)
+)::bigint AS total;
+
+-- name: SumEntriesByAccountAsOf :one
+SELECT (
+  COALESCE((SELECT SUM(e.amount) FROM entries e WHERE e.account_id = sqlc.arg(account_id) AND e.created_at <= sqlc.arg(as_of)), 0) +
+  COALESCE((SELECT SUM(a.amount) FROM entries_archive a WHERE a.account_id = sqlc.arg(account_id) AND a.created_at <= sqlc.arg(as_of)), 0)
+)::bigint AS total;
+
+-- name: FindBalanceDiscrepancies :many
+-- Lists the accounts whose balance differs from the sum of their entries, archived ones included, in a single pass over the entries.
+SELECT
+  a.id AS account_id,
+  a.balance,
+  (COALESCE(e.total, 0) + COALESCE(ea.total, 0))::bigint AS entries_total,
+  (a.balance - COALESCE(e.total, 0) - COALESCE(ea.total, 0))::bigint AS discrepancy
+FROM accounts a
+LEFT JOIN (SELECT account_id, SUM(amount) AS total FROM entries GROUP BY account_id) e ON e.account_id = a.id
+LEFT JOIN (SELECT account_id, SUM(amount) AS total FROM entries_archive GROUP BY account_id) ea ON ea.account_id = a.id
+WHERE a.balance <> COALESCE(e.total, 0) + COALESCE(ea.total, 0)
+ORDER BY a.id
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: TotalSystemBalance :many
+-- Sums the balances of all accounts per currency, next to the sum of their entries, archived ones included,
+-- so treasury can see how much money the bank holds and whether the ledger backs it.
+-- Sub-balances count towards their own currency, backed by their sub-balance entries; accounts counts the accounts held in the currency.
+SELECT
+  t.currency,
+  SUM(t.accounts)::bigint AS accounts,
+  SUM(t.balance)::bigint AS balance,
+  SUM(t.entries_total)::bigint AS entries_total,
+  (SUM(t.balance) - SUM(t.entries_total))::bigint AS discrepancy
+FROM (
+  SELECT a.currency, 1 AS accounts, a.balance, COALESCE(e.total, 0) + COALESCE(ea.total, 0) AS entries_total
+  FROM accounts a
+  LEFT JOIN (SELECT account_id, SUM(amount) AS total FROM entries GROUP BY account_id) e ON e.account_id = a.id
+  LEFT JOIN (SELECT account_id, SUM(amount) AS total FROM entries_archive GROUP BY account_id) ea ON ea.account_id = a.id
+  UNION ALL
+  SELECT s.currency, 0 AS accounts, s.balance, COALESCE(se.total, 0) AS entries_total
+  FROM account_sub_balances s
+  LEFT JOIN (SELECT account_id, currency, SUM(amount) AS total FROM sub_balance_entries GROUP BY account_id, currency) se
+    ON se.account_id = s.account_id AND se.currency = s.currency
+) t
+GROUP BY t.currency
+ORDER BY t.currency;
+
+-- name: CountStatementEntries :one
+-- Counts the entries of an account, archived ones included, created in [from_time, to_time).
+SELECT (
+  (SELECT COUNT(*) FROM entries e WHERE e.account_id = sqlc.arg(account_id) AND e.created_at >= sqlc.arg(from_time) AND e.created_at < sqlc.arg(to_time)) +
+  (SELECT COUNT(*) FROM entries_archive a WHERE a.account_id = sqlc.arg(account_id) AND a.created_at >= sqlc.arg(from_time) AND a.created_at < sqlc.arg(to_time))
+)::bigint AS total;
+
+-- name: ListStatementEntries :many
+-- Lists the entries of an account, archived ones included, created in [from_time, to_time) with an id above after_id.
+-- Archived entries keep their id, so ordering by id pages through both tables as one.
+SELECT e.id, e.account_id, e.amount, e.created_at FROM entries e
+WHERE e.account_id = sqlc.arg(account_id) AND e.created_at >= sqlc.arg(from_time) AND e.created_at < sqlc.arg(to_time) AND e.id > sqlc.arg(after_id)
+UNION ALL
+SELECT a.id, a.account_id, a.amount, a.created_at FROM entries_archive a
+WHERE a.account_id = sqlc.arg(account_id) AND a.created_at >= sqlc.arg(from_time) AND a.created_at < sqlc.arg(to_time) AND a.id > sqlc.arg(after_id)
+ORDER BY id
+LIMIT sqlc.arg('limit');
+
+-- name: ListEntriesWithRunningBalance :many
+-- Lists the entries of an account, archived ones included, each with the sum of the amounts up to and including it.
+-- The sum runs over every entry before the page, so each page carries on from the previous one.
+SELECT h.id, h.account_id, h.amount, h.created_at, h.running_balance FROM (
+  SELECT
+    u.id, u.account_id, u.amount, u.created_at,
+    (SUM(u.amount) OVER (PARTITION BY u.account_id ORDER BY u.created_at, u.id))::bigint AS running_balance
+  FROM (
+    SELECT e.id, e.account_id, e.amount, e.created_at FROM entries e WHERE e.account_id = sqlc.arg(account_id)
+    UNION ALL
+    SELECT a.id, a.account_id, a.amount, a.created_at FROM entries_archive a WHERE a.account_id = sqlc.arg(account_id)
+  ) u
+) h
+ORDER BY h.created_at, h.id
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
@@ -0,0 +1,19 @@
+-- name: ArchiveEntries :execrows
+WITH moved AS (
+  DELETE FROM entries
+  WHERE id IN (
+    SELECT e.id FROM entries e
+    WHERE e.created_at < sqlc.arg(before)
+    ORDER BY e.id
+    LIMIT sqlc.arg(batch_size)
+    FOR UPDATE SKIP LOCKED
+  )
+  RETURNING id, account_id, amount, created_at
+)
+INSERT INTO entries_archive (id, account_id, amount, created_at)
+SELECT id, account_id, amount, created_at FROM moved;
+
+-- name: ListArchivedEntriesByAccount :many
+SELECT * FROM entries_archive
+WHERE account_id = $1
+ORDER BY id;
@@ -0,0 +1,18 @@
+-- name: CreateEvent :one
+INSERT INTO events (
+  event_type, payload, account_ids
+) VALUES (
+  $1, $2, $3
+)
+RETURNING *;
+
+-- name: ListEvents :many
+SELECT * FROM events
+ORDER BY id
+LIMIT $1
+OFFSET $2;
+
+-- name: ListEventsByAccount :many
+SELECT * FROM events
+WHERE account_ids @> ARRAY[sqlc.arg(account_id)::bigint]
+ORDER BY id;
@@ -0,0 +1,25 @@
+-- name: CreateOwnershipTransferRequest :one
+INSERT INTO ownership_transfer_requests (
+  account_id, current_owner, new_owner, expires_at
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: GetOwnershipTransferRequest :one
+SELECT * FROM ownership_transfer_requests
+WHERE id = $1 LIMIT 1;
+
+-- name: GetOwnershipTransferRequestForUpdate :one
+SELECT * FROM ownership_transfer_requests
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: AcceptOwnershipTransferRequest :one
+UPDATE ownership_transfer_requests SET status = 'accepted'
+WHERE id = $1 AND status = 'pending'
+RETURNING *;
+
+-- name: ExpireOwnershipTransferRequests :execrows
+UPDATE ownership_transfer_requests SET status = 'expired'
+WHERE status = 'pending' AND expires_at <= sqlc.arg(now);
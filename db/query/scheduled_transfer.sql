@@ -0,0 +1,49 @@
+-- name: CreateScheduledTransfer :one
+INSERT INTO scheduled_transfers (
+  from_account_id, to_account_id, amount, currency, run_at
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+RETURNING *;
+
+-- name: GetScheduledTransfer :one
+SELECT * FROM scheduled_transfers
+WHERE id = $1 LIMIT 1;
+
+-- name: GetScheduledTransferForUpdate :one
+SELECT * FROM scheduled_transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListScheduledTransfers :many
+SELECT * FROM scheduled_transfers
+WHERE from_account_id = $1
+ORDER BY run_at, id
+LIMIT $2
+OFFSET $3;
+
+-- name: ListDueScheduledTransfers :many
+SELECT * FROM scheduled_transfers
+WHERE status = 'pending' AND run_at <= sqlc.arg(now)
+ORDER BY run_at, id
+LIMIT sqlc.arg(max_count);
+
+-- name: CancelScheduledTransfer :one
+UPDATE scheduled_transfers SET status = 'canceled'
+WHERE id = $1 AND status = 'pending'
+RETURNING *;
+
+-- name: CompleteScheduledTransfer :one
+UPDATE scheduled_transfers SET status = 'done', transfer_id = sqlc.arg(transfer_id)
+WHERE id = sqlc.arg(id) AND status = 'pending'
+RETURNING *;
+
+-- name: FailScheduledTransfer :one
+UPDATE scheduled_transfers SET status = 'failed', failure_reason = sqlc.arg(failure_reason)
+WHERE id = sqlc.arg(id) AND status = 'pending'
+RETURNING *;
+
+-- name: CountPendingScheduledTransfersByAccount :one
+SELECT count(*) FROM scheduled_transfers
+WHERE (from_account_id = sqlc.arg(account_id) OR to_account_id = sqlc.arg(account_id))
+  AND status = 'pending';
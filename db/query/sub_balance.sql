@@ -0,0 +1,22 @@
+-- name: GetSubBalanceForUpdate :one
+SELECT * FROM account_sub_balances
+WHERE account_id = $1 AND currency = $2
+FOR NO KEY UPDATE;
+
+-- name: ListSubBalances :many
+SELECT * FROM account_sub_balances
+WHERE account_id = $1
+ORDER BY currency;
+
+-- name: AddSubBalance :one
+-- Credits or debits the sub-balance, opening it on the first credit.
+INSERT INTO account_sub_balances (account_id, currency, balance)
+VALUES (sqlc.arg(account_id), sqlc.arg(currency), sqlc.arg(amount))
+ON CONFLICT (account_id, currency)
+DO UPDATE SET balance = account_sub_balances.balance + EXCLUDED.balance, updated_at = now()
+RETURNING *;
+
+-- name: CreateSubBalanceEntry :one
+INSERT INTO sub_balance_entries (account_id, currency, amount)
+VALUES ($1, $2, $3)
+RETURNING *;
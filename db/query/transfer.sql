@@ -1,6 +1,16 @@
 -- name: CreateTransfer :one
-INSERT INTO transfers(from_account_id, to_account_id, amount)
-VALUES ($1, $2, $3)
+-- The currency and account numbers are copied from the accounts as they are now,
+-- so that receipts keep showing them after an account is converted or renumbered.
+INSERT INTO transfers(
+  from_account_id, to_account_id, amount, fee, external_ref, category,
+  currency, from_account_number, to_account_number
+)
+VALUES (
+  $1, $2, $3, $4, $5, $6,
+  (SELECT a.currency FROM accounts a WHERE a.id = $1),
+  (SELECT a.number FROM accounts a WHERE a.id = $1),
+  (SELECT a.number FROM accounts a WHERE a.id = $2)
+)
 RETURNING *;
 
 -- name: UpdateTransfer :one
@@ -15,4 +25,74 @@ DELETE FROM transfers WHERE id = $1;
 SELECT * FROM transfers WHERE id = $1;
 
 -- name: ListTransfers :many
-SELECT * FROM transfers ORDER BY id LIMIT $1 OFFSET $2;
\ No newline at end of file
+SELECT * FROM transfers ORDER BY id LIMIT $1 OFFSET $2;
+
+-- name: SumTransferFeesByAccount :one
+SELECT COALESCE(SUM(fee), 0)::bigint AS total
+FROM transfers
+WHERE from_account_id = $1;
+
+-- name: RecentSimilarTransferExists :one
+SELECT EXISTS(
+  SELECT 1 FROM transfers
+  WHERE from_account_id = $1 AND to_account_id = $2 AND amount = $3
+    AND created_at >= sqlc.arg(since)
+) AS found;
+
+
+-- name: CountRecentTransfersFromAccount :one
+SELECT COUNT(*) FROM transfers
+WHERE from_account_id = $1 AND created_at >= sqlc.arg(since);
+
+-- name: SpendingByCategory :many
+-- Sums the transfers out of an account within [from_time, to_time) per category, largest first.
+-- Transfers without a category are summed under an empty one.
+SELECT
+  COALESCE(category, '')::varchar AS category,
+  SUM(amount)::bigint AS total,
+  COUNT(*) AS transfers
+FROM transfers
+WHERE from_account_id = sqlc.arg(account_id) AND created_at >= sqlc.arg(from_time) AND created_at < sqlc.arg(to_time)
+GROUP BY COALESCE(category, '')
+ORDER BY total DESC, category;
+
+-- name: GetTransfersByIDs :many
+-- Returns the transfers among the IDs that move money out of or into an account of the owner, in ID order.
+-- IDs of transfers the owner is not party to, or that do not exist, are left out.
+SELECT * FROM transfers
+WHERE id = ANY(sqlc.arg(ids)::bigint[])
+  AND EXISTS (
+    SELECT 1 FROM accounts
+    WHERE accounts.owner = sqlc.arg(owner)
+      AND accounts.id IN (transfers.from_account_id, transfers.to_account_id)
+  )
+ORDER BY id;
+
+-- name: FindCounterpartyAccounts :many
+-- Lists every other account that sent money to or received money from the account, once each, in ID order.
+-- Direction is outgoing when the account only sent money to the counterparty, incoming when it only received from it,
+-- and both otherwise.
+SELECT
+  accounts.id,
+  accounts.owner,
+  accounts.currency,
+  accounts.frozen,
+  (CASE
+    WHEN bool_and(transfers.from_account_id = sqlc.arg(account_id)) THEN 'outgoing'
+    WHEN bool_and(transfers.to_account_id = sqlc.arg(account_id)) THEN 'incoming'
+    ELSE 'both'
+  END)::varchar AS direction,
+  COUNT(*) AS transfers,
+  COALESCE(SUM(transfers.amount) FILTER (WHERE transfers.from_account_id = sqlc.arg(account_id)), 0)::bigint AS amount_sent,
+  COALESCE(SUM(transfers.amount) FILTER (WHERE transfers.to_account_id = sqlc.arg(account_id)), 0)::bigint AS amount_received
+FROM transfers
+JOIN accounts ON accounts.id = (CASE
+  WHEN transfers.from_account_id = sqlc.arg(account_id) THEN transfers.to_account_id
+  ELSE transfers.from_account_id
+END)
+WHERE (transfers.from_account_id = sqlc.arg(account_id) OR transfers.to_account_id = sqlc.arg(account_id))
+  AND accounts.id <> sqlc.arg(account_id)
+GROUP BY accounts.id
+ORDER BY accounts.id
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
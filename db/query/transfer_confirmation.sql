@@ -0,0 +1,27 @@
+-- name: CreateTransferConfirmation :one
+INSERT INTO transfer_confirmations (
+  from_account_id, to_account_id, amount, currency, external_ref, category, token_hash, expires_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8
+)
+RETURNING *;
+
+-- name: GetTransferConfirmation :one
+SELECT * FROM transfer_confirmations
+WHERE id = $1 LIMIT 1;
+
+-- name: GetTransferConfirmationForUpdate :one
+SELECT * FROM transfer_confirmations
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: CompleteTransferConfirmation :one
+UPDATE transfer_confirmations SET status = 'confirmed', transfer_id = sqlc.arg(transfer_id)
+WHERE id = sqlc.arg(id) AND status = 'pending_confirmation'
+RETURNING *;
+
+-- name: CountPendingTransferConfirmationsByAccount :one
+SELECT count(*) FROM transfer_confirmations
+WHERE (from_account_id = sqlc.arg(account_id) OR to_account_id = sqlc.arg(account_id))
+  AND status = 'pending_confirmation'
+  AND expires_at > sqlc.arg(now);
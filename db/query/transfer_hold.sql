@@ -0,0 +1,31 @@
+-- name: CreateTransferHold :one
+INSERT INTO transfer_holds (
+  from_account_id, to_account_id, amount
+) VALUES (
+  $1, $2, $3
+)
+RETURNING *;
+
+-- name: GetTransferHold :one
+SELECT * FROM transfer_holds
+WHERE id = $1 LIMIT 1;
+
+-- name: GetTransferHoldForUpdate :one
+SELECT * FROM transfer_holds
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: SettleTransferHold :one
+UPDATE transfer_holds SET status = sqlc.arg(status), transfer_id = sqlc.arg(transfer_id)
+WHERE id = sqlc.arg(id) AND status = 'authorized'
+RETURNING *;
+
+-- name: AddAccountHeldBalance :one
+UPDATE accounts SET held_balance = held_balance + sqlc.arg(amount)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: CountAuthorizedHoldsByAccount :one
+SELECT count(*) FROM transfer_holds
+WHERE (from_account_id = sqlc.arg(account_id) OR to_account_id = sqlc.arg(account_id))
+  AND status = 'authorized';
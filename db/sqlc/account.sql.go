@@ -7,10 +7,11 @@ package db
 
 import (
 	"context"
+	"time"
 )
 
 const addAccountBalance = `-- name: AddAccountBalance :one
-UPDATE accounts SET balance = balance + $1 WHERE id = $2 RETURNING id, owner, balance, currency, created_at
+UPDATE accounts SET balance = balance + $1 WHERE id = $2 RETURNING id, owner, balance, currency, created_at, whitelist_enabled, held_balance, number, account_type, min_balance, nickname, metadata, frozen, freeze_reason
 `
 
 type AddAccountBalanceParams struct {
@@ -27,27 +28,72 @@ func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalancePa
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.WhitelistEnabled,
+		&i.HeldBalance,
+		&i.Number,
+		&i.AccountType,
+		&i.MinBalance,
+		&i.Nickname,
+		&i.Metadata,
+		&i.Frozen,
+		&i.FreezeReason,
 	)
 	return i, err
 }
 
+const countAccountsByOwnerSince = `-- name: CountAccountsByOwnerSince :one
+SELECT count(*) FROM accounts
+WHERE owner = $1 AND created_at >= $2
+`
+
+type CountAccountsByOwnerSinceParams struct {
+	Owner string    `json:"owner"`
+	Since time.Time `json:"since"`
+}
+
+func (q *Queries) CountAccountsByOwnerSince(ctx context.Context, arg CountAccountsByOwnerSinceParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countAccountsByOwnerSince, arg.Owner, arg.Since)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createAcount = `-- name: CreateAcount :one
 INSERT INTO accounts (
-  owner, balance, currency
-) VALUES (
-  $1, $2, $3
+  owner, balance, currency, number, account_type
 )
-RETURNING id, owner, balance, currency, created_at
+SELECT
+  $1::varchar, $2::bigint, $3::varchar,
+  CASE
+    WHEN $4::bool THEN COALESCE(MAX(a.number), 0) + 1
+    ELSE nextval('account_numbers')
+  END,
+  COALESCE(NULLIF($5::varchar, ''), 'checking')
+FROM accounts a
+WHERE a.currency = $3::varchar
+RETURNING id, owner, balance, currency, created_at, whitelist_enabled, held_balance, number, account_type, min_balance, nickname, metadata, frozen, freeze_reason
 `
 
 type CreateAcountParams struct {
-	Owner    string `json:"owner"`
-	Balance  int64  `json:"balance"`
-	Currency string `json:"currency"`
+	Owner             string `json:"owner"`
+	Balance           int64  `json:"balance"`
+	Currency          string `json:"currency"`
+	NumberPerCurrency bool   `json:"number_per_currency"`
+	AccountType       string `json:"account_type"`
 }
 
+// With number_per_currency set the account takes the next number of its currency; concurrent inserts may
+// pick the same number, which the unique index rejects. Otherwise the number comes from the account_numbers
+// sequence, so it is unique across currencies, which the per-currency index alone cannot guarantee.
+// An empty account_type creates a checking account.
 func (q *Queries) CreateAcount(ctx context.Context, arg CreateAcountParams) (Account, error) {
-	row := q.db.QueryRowContext(ctx, createAcount, arg.Owner, arg.Balance, arg.Currency)
+	row := q.db.QueryRowContext(ctx, createAcount,
+		arg.Owner,
+		arg.Balance,
+		arg.Currency,
+		arg.NumberPerCurrency,
+		arg.AccountType,
+	)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -55,6 +101,15 @@ func (q *Queries) CreateAcount(ctx context.Context, arg CreateAcountParams) (Acc
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.WhitelistEnabled,
+		&i.HeldBalance,
+		&i.Number,
+		&i.AccountType,
+		&i.MinBalance,
+		&i.Nickname,
+		&i.Metadata,
+		&i.Frozen,
+		&i.FreezeReason,
 	)
 	return i, err
 }
@@ -69,7 +124,7 @@ func (q *Queries) DeleteAccount(ctx context.Context, id int64) error {
 }
 
 const getAccount = `-- name: GetAccount :one
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, whitelist_enabled, held_balance, number, account_type, min_balance, nickname, metadata, frozen, freeze_reason FROM accounts
 WHERE id = $1 LIMIT 1
 `
 
@@ -82,12 +137,53 @@ func (q *Queries) GetAccount(ctx context.Context, id int64) (Account, error) {
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.WhitelistEnabled,
+		&i.HeldBalance,
+		&i.Number,
+		&i.AccountType,
+		&i.MinBalance,
+		&i.Nickname,
+		&i.Metadata,
+		&i.Frozen,
+		&i.FreezeReason,
+	)
+	return i, err
+}
+
+const getAccountByNumberAndCurrency = `-- name: GetAccountByNumberAndCurrency :one
+SELECT id, owner, balance, currency, created_at, whitelist_enabled, held_balance, number, account_type, min_balance, nickname, metadata, frozen, freeze_reason FROM accounts
+WHERE number = $1 AND currency = $2 LIMIT 1
+`
+
+type GetAccountByNumberAndCurrencyParams struct {
+	Number   int64  `json:"number"`
+	Currency string `json:"currency"`
+}
+
+func (q *Queries) GetAccountByNumberAndCurrency(ctx context.Context, arg GetAccountByNumberAndCurrencyParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, getAccountByNumberAndCurrency, arg.Number, arg.Currency)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.WhitelistEnabled,
+		&i.HeldBalance,
+		&i.Number,
+		&i.AccountType,
+		&i.MinBalance,
+		&i.Nickname,
+		&i.Metadata,
+		&i.Frozen,
+		&i.FreezeReason,
 	)
 	return i, err
 }
 
 const getAccountForUpdate = `-- name: GetAccountForUpdate :one
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, whitelist_enabled, held_balance, number, account_type, min_balance, nickname, metadata, frozen, freeze_reason FROM accounts
 WHERE id = $1 LIMIT 1
 FOR NO KEY UPDATE
 `
@@ -101,12 +197,21 @@ func (q *Queries) GetAccountForUpdate(ctx context.Context, id int64) (Account, e
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.WhitelistEnabled,
+		&i.HeldBalance,
+		&i.Number,
+		&i.AccountType,
+		&i.MinBalance,
+		&i.Nickname,
+		&i.Metadata,
+		&i.Frozen,
+		&i.FreezeReason,
 	)
 	return i, err
 }
 
 const listAccounts = `-- name: ListAccounts :many
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, whitelist_enabled, held_balance, number, account_type, min_balance, nickname, metadata, frozen, freeze_reason FROM accounts
 ORDER BY id
 LIMIT $1
 OFFSET $2
@@ -132,6 +237,399 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 			&i.Balance,
 			&i.Currency,
 			&i.CreatedAt,
+			&i.WhitelistEnabled,
+			&i.HeldBalance,
+			&i.Number,
+			&i.AccountType,
+			&i.MinBalance,
+			&i.Nickname,
+			&i.Metadata,
+			&i.Frozen,
+			&i.FreezeReason,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountsAfter = `-- name: ListAccountsAfter :many
+SELECT id, owner, balance, currency, created_at, whitelist_enabled, held_balance, number, account_type, min_balance, nickname, metadata, frozen, freeze_reason FROM accounts
+WHERE id > $1
+ORDER BY id
+LIMIT $2
+`
+
+type ListAccountsAfterParams struct {
+	AfterID   int64 `json:"after_id"`
+	BatchSize int32 `json:"batch_size"`
+}
+
+func (q *Queries) ListAccountsAfter(ctx context.Context, arg ListAccountsAfterParams) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountsAfter, arg.AfterID, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Account
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.WhitelistEnabled,
+			&i.HeldBalance,
+			&i.Number,
+			&i.AccountType,
+			&i.MinBalance,
+			&i.Nickname,
+			&i.Metadata,
+			&i.Frozen,
+			&i.FreezeReason,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDormantAccounts = `-- name: ListDormantAccounts :many
+SELECT id, owner, balance, currency, created_at, whitelist_enabled, held_balance, number, account_type, min_balance, nickname, metadata, frozen, freeze_reason FROM accounts
+WHERE NOT EXISTS (
+  SELECT 1 FROM entries e WHERE e.account_id = accounts.id AND e.created_at >= $1
+) AND NOT EXISTS (
+  SELECT 1 FROM entries_archive ea WHERE ea.account_id = accounts.id AND ea.created_at >= $1
+)
+ORDER BY id
+LIMIT $3
+OFFSET $2
+`
+
+type ListDormantAccountsParams struct {
+	Since  time.Time `json:"since"`
+	Offset int32     `json:"offset"`
+	Limit  int32     `json:"limit"`
+}
+
+// An account is dormant when it has no entry, archived or not, created at or after since.
+func (q *Queries) ListDormantAccounts(ctx context.Context, arg ListDormantAccountsParams) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, listDormantAccounts, arg.Since, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Account
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.WhitelistEnabled,
+			&i.HeldBalance,
+			&i.Number,
+			&i.AccountType,
+			&i.MinBalance,
+			&i.Nickname,
+			&i.Metadata,
+			&i.Frozen,
+			&i.FreezeReason,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFrozenAccounts = `-- name: ListFrozenAccounts :many
+SELECT id, owner, balance, currency, created_at, whitelist_enabled, held_balance, number, account_type, min_balance, nickname, metadata, frozen, freeze_reason FROM accounts
+WHERE frozen AND ($1::varchar = '' OR freeze_reason = $1::varchar)
+ORDER BY id
+LIMIT $3
+OFFSET $2
+`
+
+type ListFrozenAccountsParams struct {
+	Reason string `json:"reason"`
+	Offset int32  `json:"offset"`
+	Limit  int32  `json:"limit"`
+}
+
+// An empty reason lists every frozen account.
+func (q *Queries) ListFrozenAccounts(ctx context.Context, arg ListFrozenAccountsParams) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, listFrozenAccounts, arg.Reason, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Account
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.WhitelistEnabled,
+			&i.HeldBalance,
+			&i.Number,
+			&i.AccountType,
+			&i.MinBalance,
+			&i.Nickname,
+			&i.Metadata,
+			&i.Frozen,
+			&i.FreezeReason,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const patchAccount = `-- name: PatchAccount :one
+UPDATE accounts SET
+  nickname = CASE WHEN $1::bool THEN NULLIF($2::varchar, '') ELSE nickname END,
+  metadata = CASE WHEN $3::bool THEN $4::text::jsonb ELSE metadata END,
+  frozen = CASE WHEN $5::bool THEN $6::bool ELSE frozen END,
+  freeze_reason = CASE WHEN $5::bool THEN NULLIF($7::varchar, '') ELSE freeze_reason END
+WHERE id = $8
+RETURNING id, owner, balance, currency, created_at, whitelist_enabled, held_balance, number, account_type, min_balance, nickname, metadata, frozen, freeze_reason
+`
+
+type PatchAccountParams struct {
+	SetNickname  bool   `json:"set_nickname"`
+	Nickname     string `json:"nickname"`
+	SetMetadata  bool   `json:"set_metadata"`
+	Metadata     string `json:"metadata"`
+	SetFrozen    bool   `json:"set_frozen"`
+	Frozen       bool   `json:"frozen"`
+	FreezeReason string `json:"freeze_reason"`
+	ID           int64  `json:"id"`
+}
+
+// Only the fields whose set_ flag is true change; the others keep their current value.
+// An empty nickname removes it.
+// The freeze reason changes along with frozen; unfreezing passes an empty one, which removes it.
+// The flags stand in for NULL parameters, which sqlc cannot generate for NOT NULL columns.
+func (q *Queries) PatchAccount(ctx context.Context, arg PatchAccountParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, patchAccount,
+		arg.SetNickname,
+		arg.Nickname,
+		arg.SetMetadata,
+		arg.Metadata,
+		arg.SetFrozen,
+		arg.Frozen,
+		arg.FreezeReason,
+		arg.ID,
+	)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.WhitelistEnabled,
+		&i.HeldBalance,
+		&i.Number,
+		&i.AccountType,
+		&i.MinBalance,
+		&i.Nickname,
+		&i.Metadata,
+		&i.Frozen,
+		&i.FreezeReason,
+	)
+	return i, err
+}
+
+const setAccountCurrency = `-- name: SetAccountCurrency :one
+UPDATE accounts SET
+  currency = $1,
+  balance = $2,
+  min_balance = $3,
+  number = CASE
+    WHEN $4::bool
+    THEN (SELECT COALESCE(MAX(a.number), 0) + 1 FROM accounts a WHERE a.currency = $1)
+    ELSE accounts.number
+  END
+WHERE accounts.id = $5
+RETURNING id, owner, balance, currency, created_at, whitelist_enabled, held_balance, number, account_type, min_balance, nickname, metadata, frozen, freeze_reason
+`
+
+type SetAccountCurrencyParams struct {
+	Currency          string `json:"currency"`
+	Balance           int64  `json:"balance"`
+	MinBalance        int64  `json:"min_balance"`
+	NumberPerCurrency bool   `json:"number_per_currency"`
+	ID                int64  `json:"id"`
+}
+
+// With number_per_currency set the account is renumbered, since its number may be taken in the new currency.
+func (q *Queries) SetAccountCurrency(ctx context.Context, arg SetAccountCurrencyParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, setAccountCurrency,
+		arg.Currency,
+		arg.Balance,
+		arg.MinBalance,
+		arg.NumberPerCurrency,
+		arg.ID,
+	)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.WhitelistEnabled,
+		&i.HeldBalance,
+		&i.Number,
+		&i.AccountType,
+		&i.MinBalance,
+		&i.Nickname,
+		&i.Metadata,
+		&i.Frozen,
+		&i.FreezeReason,
+	)
+	return i, err
+}
+
+const setAccountMinBalance = `-- name: SetAccountMinBalance :one
+UPDATE accounts SET min_balance = $1
+WHERE id = $2
+RETURNING id, owner, balance, currency, created_at, whitelist_enabled, held_balance, number, account_type, min_balance, nickname, metadata, frozen, freeze_reason
+`
+
+type SetAccountMinBalanceParams struct {
+	MinBalance int64 `json:"min_balance"`
+	ID         int64 `json:"id"`
+}
+
+func (q *Queries) SetAccountMinBalance(ctx context.Context, arg SetAccountMinBalanceParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, setAccountMinBalance, arg.MinBalance, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.WhitelistEnabled,
+		&i.HeldBalance,
+		&i.Number,
+		&i.AccountType,
+		&i.MinBalance,
+		&i.Nickname,
+		&i.Metadata,
+		&i.Frozen,
+		&i.FreezeReason,
+	)
+	return i, err
+}
+
+const setAccountOwner = `-- name: SetAccountOwner :one
+UPDATE accounts SET owner = $1
+WHERE id = $2
+RETURNING id, owner, balance, currency, created_at, whitelist_enabled, held_balance, number, account_type, min_balance, nickname, metadata, frozen, freeze_reason
+`
+
+type SetAccountOwnerParams struct {
+	Owner string `json:"owner"`
+	ID    int64  `json:"id"`
+}
+
+func (q *Queries) SetAccountOwner(ctx context.Context, arg SetAccountOwnerParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, setAccountOwner, arg.Owner, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.WhitelistEnabled,
+		&i.HeldBalance,
+		&i.Number,
+		&i.AccountType,
+		&i.MinBalance,
+		&i.Nickname,
+		&i.Metadata,
+		&i.Frozen,
+		&i.FreezeReason,
+	)
+	return i, err
+}
+
+const topAccountsByBalance = `-- name: TopAccountsByBalance :many
+SELECT id, owner, balance, currency, created_at, whitelist_enabled, held_balance, number, account_type, min_balance, nickname, metadata, frozen, freeze_reason FROM accounts
+WHERE currency = $1
+ORDER BY balance DESC, id
+LIMIT $2
+`
+
+type TopAccountsByBalanceParams struct {
+	Currency string `json:"currency"`
+	Limit    int32  `json:"limit"`
+}
+
+// Lists the accounts of a currency from the largest balance down; ties go to the oldest account.
+func (q *Queries) TopAccountsByBalance(ctx context.Context, arg TopAccountsByBalanceParams) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, topAccountsByBalance, arg.Currency, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Account
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.WhitelistEnabled,
+			&i.HeldBalance,
+			&i.Number,
+			&i.AccountType,
+			&i.MinBalance,
+			&i.Nickname,
+			&i.Metadata,
+			&i.Frozen,
+			&i.FreezeReason,
 		); err != nil {
 			return nil, err
 		}
@@ -147,7 +645,7 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 }
 
 const updateAccount = `-- name: UpdateAccount :one
-UPDATE accounts SET balance = $1 WHERE id = $2 RETURNING id, owner, balance, currency, created_at
+UPDATE accounts SET balance = $1 WHERE id = $2 RETURNING id, owner, balance, currency, created_at, whitelist_enabled, held_balance, number, account_type, min_balance, nickname, metadata, frozen, freeze_reason
 `
 
 type UpdateAccountParams struct {
@@ -164,6 +662,15 @@ func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.WhitelistEnabled,
+		&i.HeldBalance,
+		&i.Number,
+		&i.AccountType,
+		&i.MinBalance,
+		&i.Nickname,
+		&i.Metadata,
+		&i.Frozen,
+		&i.FreezeReason,
 	)
 	return i, err
 }
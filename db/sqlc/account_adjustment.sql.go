@@ -0,0 +1,85 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.13.0
+// source: account_adjustment.sql
+
+package db
+
+import (
+	"context"
+)
+
+const createAccountAdjustment = `-- name: CreateAccountAdjustment :one
+INSERT INTO account_adjustments (
+  account_id, entry_id, amount, reason, adjusted_by
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+RETURNING id, account_id, entry_id, amount, reason, adjusted_by, created_at
+`
+
+type CreateAccountAdjustmentParams struct {
+	AccountID  int64  `json:"account_id"`
+	EntryID    int64  `json:"entry_id"`
+	Amount     int64  `json:"amount"`
+	Reason     string `json:"reason"`
+	AdjustedBy string `json:"adjusted_by"`
+}
+
+func (q *Queries) CreateAccountAdjustment(ctx context.Context, arg CreateAccountAdjustmentParams) (AccountAdjustment, error) {
+	row := q.db.QueryRowContext(ctx, createAccountAdjustment,
+		arg.AccountID,
+		arg.EntryID,
+		arg.Amount,
+		arg.Reason,
+		arg.AdjustedBy,
+	)
+	var i AccountAdjustment
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.EntryID,
+		&i.Amount,
+		&i.Reason,
+		&i.AdjustedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listAccountAdjustments = `-- name: ListAccountAdjustments :many
+SELECT id, account_id, entry_id, amount, reason, adjusted_by, created_at FROM account_adjustments
+WHERE account_id = $1
+ORDER BY id
+`
+
+func (q *Queries) ListAccountAdjustments(ctx context.Context, accountID int64) ([]AccountAdjustment, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountAdjustments, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AccountAdjustment
+	for rows.Next() {
+		var i AccountAdjustment
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.EntryID,
+			&i.Amount,
+			&i.Reason,
+			&i.AdjustedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,55 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.13.0
+// source: account_audit_log.sql
+
+package db
+
+import (
+	"context"
+)
+
+const listAccountAuditLog = `-- name: ListAccountAuditLog :many
+SELECT id, account_id, action, before, after, actor, created_at FROM account_audit_log
+WHERE account_id = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3
+`
+
+type ListAccountAuditLogParams struct {
+	AccountID int64 `json:"account_id"`
+	Limit     int32 `json:"limit"`
+	Offset    int32 `json:"offset"`
+}
+
+func (q *Queries) ListAccountAuditLog(ctx context.Context, arg ListAccountAuditLogParams) ([]AccountAuditLog, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountAuditLog, arg.AccountID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AccountAuditLog
+	for rows.Next() {
+		var i AccountAuditLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Action,
+			&i.Before,
+			&i.After,
+			&i.Actor,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
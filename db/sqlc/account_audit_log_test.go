@@ -0,0 +1,88 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/khuongkd/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func listTestAuditLog(t *testing.T, accountID int64) []AccountAuditLog {
+	records, err := testQueries.ListAccountAuditLog(context.Background(), ListAccountAuditLogParams{
+		AccountID: accountID,
+		Limit:     100,
+	})
+	require.NoError(t, err)
+	return records
+}
+
+func requireAuditAccount(t *testing.T, data json.RawMessage) Account {
+	var account Account
+	require.NoError(t, json.Unmarshal(data, &account))
+	return account
+}
+
+func TestAccountAuditLog(t *testing.T) {
+	account := createTestAccount(t)
+
+	_, err := testQueries.UpdateAccount(context.Background(), UpdateAccountParams{ID: account.ID, Balance: account.Balance + 10})
+	require.NoError(t, err)
+	_, err = testQueries.SetAccountWhitelistEnabled(context.Background(), SetAccountWhitelistEnabledParams{ID: account.ID, Enabled: true})
+	require.NoError(t, err)
+	// an update that changes nothing is not recorded
+	_, err = testQueries.SetAccountWhitelistEnabled(context.Background(), SetAccountWhitelistEnabledParams{ID: account.ID, Enabled: true})
+	require.NoError(t, err)
+	require.NoError(t, testQueries.DeleteAccount(context.Background(), account.ID))
+
+	records := listTestAuditLog(t, account.ID)
+	require.Len(t, records, 4)
+
+	require.Equal(t, "insert", records[0].Action)
+	require.JSONEq(t, "null", string(records[0].Before))
+	require.Equal(t, account.Balance, requireAuditAccount(t, records[0].After).Balance)
+
+	require.Equal(t, "update", records[1].Action)
+	require.Equal(t, account.Balance, requireAuditAccount(t, records[1].Before).Balance)
+	require.Equal(t, account.Balance+10, requireAuditAccount(t, records[1].After).Balance)
+
+	require.Equal(t, "update", records[2].Action)
+	require.False(t, requireAuditAccount(t, records[2].Before).WhitelistEnabled)
+	require.True(t, requireAuditAccount(t, records[2].After).WhitelistEnabled)
+
+	require.Equal(t, "delete", records[3].Action)
+	require.JSONEq(t, "null", string(records[3].After))
+
+	for _, record := range records {
+		require.NotEmpty(t, record.Actor)
+	}
+}
+
+func TestAccountAuditLogActor(t *testing.T) {
+	store := NewStore(testDB)
+	owner := util.RandomOwner()
+	account1 := fundTestAccount(t, createTestAccountFor(t, owner, "USD"), 100)
+	account2 := createTestAccountFor(t, util.RandomOwner(), "USD")
+
+	ctx := WithActor(context.Background(), "tester")
+	_, err := store.TransferTx(ctx, TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	for _, account := range []Account{account1, account2} {
+		records := listTestAuditLog(t, account.ID)
+		last := records[len(records)-1]
+		require.Equal(t, "update", last.Action)
+		require.Equal(t, "tester", last.Actor)
+	}
+
+	// the actor only lasts for the transaction it was set in
+	_, err = testQueries.UpdateAccount(context.Background(), UpdateAccountParams{ID: account2.ID, Balance: 1})
+	require.NoError(t, err)
+	records := listTestAuditLog(t, account2.ID)
+	require.NotEqual(t, "tester", records[len(records)-1].Actor)
+}
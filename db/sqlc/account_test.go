@@ -12,10 +12,11 @@ import (
 
 // createTestAccount create new account
 func createTestAccount(t *testing.T) Account {
+	currency := util.RandomCurrency()
 	arg := CreateAcountParams{
 		Owner:    util.RandomOwner(),
-		Balance:  util.RandomMoney(),
-		Currency: util.RandomCurrency(),
+		Balance:  util.RandomMoneyForCurrency(currency),
+		Currency: currency,
 	}
 
 	account, err := testQueries.CreateAcount(context.Background(), arg)
@@ -50,7 +51,7 @@ func TestCreateAndDeleteAccount(t *testing.T) {
 func TestUpdateAccount(t *testing.T) {
 	account := createTestAccount(t)
 	arg := UpdateAccountParams{
-		Balance: util.RandomMoney(),
+		Balance: util.RandomMoneyForCurrency(account.Currency),
 		ID:      account.ID,
 	}
 	updatedAccount, err := testQueries.UpdateAccount(context.Background(), arg)
@@ -92,3 +93,260 @@ func TestListAccounts(t *testing.T) {
 		require.NotEmpty(t, account)
 	}
 }
+
+func TestCountAccountsByOwnerSince(t *testing.T) {
+	owner := util.RandomOwner()
+	for i := 0; i < 3; i++ {
+		_, err := testQueries.CreateAcount(context.Background(), CreateAcountParams{
+			Owner:    owner,
+			Balance:  0,
+			Currency: util.RandomCurrency(),
+		})
+		require.NoError(t, err)
+	}
+	createTestAccount(t)
+
+	count, err := testQueries.CountAccountsByOwnerSince(context.Background(), CountAccountsByOwnerSinceParams{
+		Owner: owner,
+		Since: time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(3), count)
+
+	count, err = testQueries.CountAccountsByOwnerSince(context.Background(), CountAccountsByOwnerSinceParams{
+		Owner: owner,
+		Since: time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+	require.Zero(t, count)
+}
+
+func TestAccountNumberPerCurrency(t *testing.T) {
+	store := NewStore(testDB)
+	create := func(currency string) Account {
+		account, err := store.CreateAcount(context.Background(), CreateAcountParams{
+			Owner:             util.RandomOwner(),
+			Currency:          currency,
+			NumberPerCurrency: true,
+		})
+		require.NoError(t, err)
+		require.NotZero(t, account.Number)
+		return account
+	}
+
+	usd1 := create("USD")
+	usd2 := create("USD")
+	eur := create("EUR")
+	require.Greater(t, usd2.Number, usd1.Number)
+
+	// a number taken in one currency is still free in another
+	_, err := testDB.ExecContext(context.Background(), "UPDATE accounts SET number = $1 WHERE id = $2", usd1.Number, eur.ID)
+	require.NoError(t, err)
+
+	gotUSD, err := store.GetAccountByNumberAndCurrency(context.Background(), GetAccountByNumberAndCurrencyParams{
+		Number:   usd1.Number,
+		Currency: "USD",
+	})
+	require.NoError(t, err)
+	require.Equal(t, usd1.ID, gotUSD.ID)
+
+	gotEUR, err := store.GetAccountByNumberAndCurrency(context.Background(), GetAccountByNumberAndCurrencyParams{
+		Number:   usd1.Number,
+		Currency: "EUR",
+	})
+	require.NoError(t, err)
+	require.Equal(t, eur.ID, gotEUR.ID)
+
+	// but not twice within the same currency
+	_, err = testDB.ExecContext(context.Background(), "UPDATE accounts SET number = $1 WHERE id = $2", usd1.Number, usd2.ID)
+	require.True(t, isUniqueViolation(err, accountNumberKey))
+}
+
+func TestAccountNumberGlobal(t *testing.T) {
+	account1 := createTestAccount(t)
+	account2 := createTestAccount(t)
+	require.NotEqual(t, account1.Number, account2.Number)
+
+	// concurrent inserts in different currencies must not share a number either
+	store := NewStore(testDB)
+	currencies := []string{"USD", "EUR", "GBP", "USD", "EUR", "GBP"}
+	numbers := make(chan int64, len(currencies))
+	errs := make(chan error, len(currencies))
+	for _, currency := range currencies {
+		go func(currency string) {
+			account, err := store.CreateAcount(context.Background(), CreateAcountParams{
+				Owner:    util.RandomOwner(),
+				Currency: currency,
+			})
+			numbers <- account.Number
+			errs <- err
+		}(currency)
+	}
+
+	seen := make(map[int64]bool)
+	for range currencies {
+		require.NoError(t, <-errs)
+		number := <-numbers
+		require.False(t, seen[number])
+		seen[number] = true
+	}
+}
+
+func TestListDormantAccounts(t *testing.T) {
+	since := time.Now().Add(-time.Second)
+	dormant := createTestAccount(t)
+	active := createTestAccount(t)
+	_, err := testQueries.CreateEntry(context.Background(), CreateEntryParams{AccountID: active.ID, Amount: 10})
+	require.NoError(t, err)
+
+	// page through every dormant account, since other tests create accounts too
+	found := make(map[int64]bool)
+	for offset := int32(0); ; offset += 100 {
+		accounts, err := testQueries.ListDormantAccounts(context.Background(), ListDormantAccountsParams{
+			Since:  since,
+			Limit:  100,
+			Offset: offset,
+		})
+		require.NoError(t, err)
+		for _, account := range accounts {
+			found[account.ID] = true
+		}
+		if len(accounts) < 100 {
+			break
+		}
+	}
+
+	require.True(t, found[dormant.ID])
+	require.False(t, found[active.ID])
+}
+
+func TestPatchAccount(t *testing.T) {
+	account := createTestAccount(t)
+	require.Nil(t, account.Nickname)
+	require.JSONEq(t, `{}`, string(account.Metadata))
+	require.False(t, account.Frozen)
+
+	// a single field leaves the others as they are
+	patched, err := testQueries.PatchAccount(context.Background(), PatchAccountParams{
+		ID:          account.ID,
+		SetNickname: true,
+		Nickname:    "savings for a bike",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "savings for a bike", *patched.Nickname)
+	require.JSONEq(t, `{}`, string(patched.Metadata))
+	require.False(t, patched.Frozen)
+	require.Equal(t, account.Balance, patched.Balance)
+
+	patched, err = testQueries.PatchAccount(context.Background(), PatchAccountParams{
+		ID:           account.ID,
+		SetMetadata:  true,
+		Metadata:     `{"color": "blue"}`,
+		SetFrozen:    true,
+		Frozen:       true,
+		FreezeReason: "kyc",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "savings for a bike", *patched.Nickname)
+	require.JSONEq(t, `{"color": "blue"}`, string(patched.Metadata))
+	require.True(t, patched.Frozen)
+	require.Equal(t, "kyc", *patched.FreezeReason)
+
+	// an empty nickname removes it
+	patched, err = testQueries.PatchAccount(context.Background(), PatchAccountParams{ID: account.ID, SetNickname: true})
+	require.NoError(t, err)
+	require.Nil(t, patched.Nickname)
+
+	_, err = testQueries.PatchAccount(context.Background(), PatchAccountParams{ID: account.ID + 1000000, SetFrozen: true})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestFreezeReason(t *testing.T) {
+	store := NewStore(testDB)
+
+	for _, reason := range util.DefaultFreezeReasons() {
+		account := createTestAccount(t)
+		frozen, err := store.PatchAccount(context.Background(), PatchAccountParams{
+			ID:           account.ID,
+			SetFrozen:    true,
+			Frozen:       true,
+			FreezeReason: reason,
+		})
+		require.NoError(t, err)
+		require.True(t, frozen.Frozen)
+		require.Equal(t, reason, *frozen.FreezeReason)
+
+		// the account shows up under its own reason only
+		accounts, err := store.ListFrozenAccounts(context.Background(), ListFrozenAccountsParams{Reason: reason, Limit: 1000000})
+		require.NoError(t, err)
+		require.Contains(t, accountIDs(accounts), account.ID)
+		for _, listed := range accounts {
+			require.Equal(t, reason, *listed.FreezeReason)
+		}
+
+		accounts, err = store.ListFrozenAccounts(context.Background(), ListFrozenAccountsParams{Limit: 1000000})
+		require.NoError(t, err)
+		require.Contains(t, accountIDs(accounts), account.ID)
+
+		// unfreezing clears the reason and takes the account off the list
+		unfrozen, err := store.PatchAccount(context.Background(), PatchAccountParams{ID: account.ID, SetFrozen: true})
+		require.NoError(t, err)
+		require.False(t, unfrozen.Frozen)
+		require.Nil(t, unfrozen.FreezeReason)
+
+		accounts, err = store.ListFrozenAccounts(context.Background(), ListFrozenAccountsParams{Reason: reason, Limit: 1000000})
+		require.NoError(t, err)
+		require.NotContains(t, accountIDs(accounts), account.ID)
+	}
+
+	// a frozen account always has a reason
+	account := createTestAccount(t)
+	_, err := store.PatchAccount(context.Background(), PatchAccountParams{ID: account.ID, SetFrozen: true, Frozen: true})
+	require.ErrorIs(t, err, ErrConstraintViolation)
+}
+
+func accountIDs(accounts []Account) []int64 {
+	ids := make([]int64, len(accounts))
+	for i, account := range accounts {
+		ids[i] = account.ID
+	}
+	return ids
+}
+
+func TestTopAccountsByBalance(t *testing.T) {
+	// the database keeps the accounts of earlier runs, so the seeded balances must outrank theirs
+	base := time.Now().UnixNano()
+	seed := func(currency string, balance int64) Account {
+		account, err := testQueries.CreateAcount(context.Background(), CreateAcountParams{
+			Owner:    util.RandomOwner(),
+			Balance:  balance,
+			Currency: currency,
+		})
+		require.NoError(t, err)
+		return account
+	}
+
+	middle := seed("EUR", base+2)
+	lowest := seed("EUR", base+1)
+	highest := seed("EUR", base+3)
+	// a larger balance in another currency is left out
+	seed("USD", base+10)
+
+	accounts, err := testQueries.TopAccountsByBalance(context.Background(), TopAccountsByBalanceParams{
+		Currency: "EUR",
+		Limit:    3,
+	})
+	require.NoError(t, err)
+	require.Len(t, accounts, 3)
+	require.Equal(t, highest.ID, accounts[0].ID)
+	require.Equal(t, middle.ID, accounts[1].ID)
+	require.Equal(t, lowest.ID, accounts[2].ID)
+
+	accounts, err = testQueries.TopAccountsByBalance(context.Background(), TopAccountsByBalanceParams{
+		Currency: "EUR",
+		Limit:    1,
+	})
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+	require.Equal(t, highest.ID, accounts[0].ID)
+}
@@ -0,0 +1,125 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.13.0
+// source: account_whitelist.sql
+
+package db
+
+import (
+	"context"
+)
+
+const addWhitelistedDestination = `-- name: AddWhitelistedDestination :exec
+INSERT INTO account_whitelist (
+  account_id, destination_account_id
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (account_id, destination_account_id) DO NOTHING
+`
+
+type AddWhitelistedDestinationParams struct {
+	AccountID            int64 `json:"account_id"`
+	DestinationAccountID int64 `json:"destination_account_id"`
+}
+
+func (q *Queries) AddWhitelistedDestination(ctx context.Context, arg AddWhitelistedDestinationParams) error {
+	_, err := q.db.ExecContext(ctx, addWhitelistedDestination, arg.AccountID, arg.DestinationAccountID)
+	return err
+}
+
+const isDestinationWhitelisted = `-- name: IsDestinationWhitelisted :one
+SELECT EXISTS (
+  SELECT 1 FROM account_whitelist
+  WHERE account_id = $1 AND destination_account_id = $2
+)
+`
+
+type IsDestinationWhitelistedParams struct {
+	AccountID            int64 `json:"account_id"`
+	DestinationAccountID int64 `json:"destination_account_id"`
+}
+
+func (q *Queries) IsDestinationWhitelisted(ctx context.Context, arg IsDestinationWhitelistedParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, isDestinationWhitelisted, arg.AccountID, arg.DestinationAccountID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const listWhitelistedDestinations = `-- name: ListWhitelistedDestinations :many
+SELECT account_id, destination_account_id, created_at FROM account_whitelist
+WHERE account_id = $1
+ORDER BY destination_account_id
+`
+
+func (q *Queries) ListWhitelistedDestinations(ctx context.Context, accountID int64) ([]AccountWhitelist, error) {
+	rows, err := q.db.QueryContext(ctx, listWhitelistedDestinations, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AccountWhitelist
+	for rows.Next() {
+		var i AccountWhitelist
+		if err := rows.Scan(&i.AccountID, &i.DestinationAccountID, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const removeWhitelistedDestination = `-- name: RemoveWhitelistedDestination :exec
+DELETE FROM account_whitelist
+WHERE account_id = $1 AND destination_account_id = $2
+`
+
+type RemoveWhitelistedDestinationParams struct {
+	AccountID            int64 `json:"account_id"`
+	DestinationAccountID int64 `json:"destination_account_id"`
+}
+
+func (q *Queries) RemoveWhitelistedDestination(ctx context.Context, arg RemoveWhitelistedDestinationParams) error {
+	_, err := q.db.ExecContext(ctx, removeWhitelistedDestination, arg.AccountID, arg.DestinationAccountID)
+	return err
+}
+
+const setAccountWhitelistEnabled = `-- name: SetAccountWhitelistEnabled :one
+UPDATE accounts SET whitelist_enabled = $1
+WHERE id = $2
+RETURNING id, owner, balance, currency, created_at, whitelist_enabled, held_balance, number, account_type, min_balance, nickname, metadata, frozen, freeze_reason
+`
+
+type SetAccountWhitelistEnabledParams struct {
+	Enabled bool  `json:"enabled"`
+	ID      int64 `json:"id"`
+}
+
+func (q *Queries) SetAccountWhitelistEnabled(ctx context.Context, arg SetAccountWhitelistEnabledParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, setAccountWhitelistEnabled, arg.Enabled, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.WhitelistEnabled,
+		&i.HeldBalance,
+		&i.Number,
+		&i.AccountType,
+		&i.MinBalance,
+		&i.Nickname,
+		&i.Metadata,
+		&i.Frozen,
+		&i.FreezeReason,
+	)
+	return i, err
+}
@@ -0,0 +1,55 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountWhitelist(t *testing.T) {
+	account := createTestAccount(t)
+	destination1 := createTestAccount(t)
+	destination2 := createTestAccount(t)
+
+	updated, err := testQueries.SetAccountWhitelistEnabled(context.Background(), SetAccountWhitelistEnabledParams{
+		ID:      account.ID,
+		Enabled: true,
+	})
+	require.NoError(t, err)
+	require.True(t, updated.WhitelistEnabled)
+
+	for _, destination := range []Account{destination2, destination1, destination1} {
+		err := testQueries.AddWhitelistedDestination(context.Background(), AddWhitelistedDestinationParams{
+			AccountID:            account.ID,
+			DestinationAccountID: destination.ID,
+		})
+		require.NoError(t, err)
+	}
+
+	items, err := testQueries.ListWhitelistedDestinations(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	require.Equal(t, destination1.ID, items[0].DestinationAccountID)
+	require.Equal(t, destination2.ID, items[1].DestinationAccountID)
+
+	err = testQueries.RemoveWhitelistedDestination(context.Background(), RemoveWhitelistedDestinationParams{
+		AccountID:            account.ID,
+		DestinationAccountID: destination1.ID,
+	})
+	require.NoError(t, err)
+
+	whitelisted, err := testQueries.IsDestinationWhitelisted(context.Background(), IsDestinationWhitelistedParams{
+		AccountID:            account.ID,
+		DestinationAccountID: destination1.ID,
+	})
+	require.NoError(t, err)
+	require.False(t, whitelisted)
+
+	whitelisted, err = testQueries.IsDestinationWhitelisted(context.Background(), IsDestinationWhitelistedParams{
+		AccountID:            account.ID,
+		DestinationAccountID: destination2.ID,
+	})
+	require.NoError(t, err)
+	require.True(t, whitelisted)
+}
@@ -0,0 +1,23 @@
+package db
+
+import "context"
+
+type actorKey struct{}
+
+// WithActor returns ctx with the actor recorded in the account audit log
+// for the changes store transactions run with it make
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFrom returns the actor set on ctx by WithActor, if any
+func ActorFrom(ctx context.Context) (string, bool) {
+	actor, ok := ctx.Value(actorKey{}).(string)
+	return actor, ok && actor != ""
+}
+
+// setActor makes the audit trigger record actor for the rest of the transaction
+func setActor(ctx context.Context, tx DBTX, actor string) error {
+	_, err := tx.ExecContext(ctx, "SELECT set_config('simplebank.actor', $1, true)", actor)
+	return err
+}
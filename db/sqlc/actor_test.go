@@ -0,0 +1,20 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestActorFrom(t *testing.T) {
+	_, ok := ActorFrom(context.Background())
+	require.False(t, ok)
+
+	_, ok = ActorFrom(WithActor(context.Background(), ""))
+	require.False(t, ok)
+
+	actor, ok := ActorFrom(WithActor(context.Background(), "scheduler"))
+	require.True(t, ok)
+	require.Equal(t, "scheduler", actor)
+}
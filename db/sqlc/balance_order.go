@@ -0,0 +1,80 @@
+package db
+
+import (
+	"context"
+	"sort"
+)
+
+// BalanceUpdate is a change a transfer makes to the balance of one account
+type BalanceUpdate struct {
+	AccountID int64
+	Amount    int64
+}
+
+// BalanceOrder decides the order in which a transfer updates the balances of its accounts.
+// Each update locks the row of its account until the transaction ends, so the order sets
+// how concurrent transfers that share accounts wait on each other.
+type BalanceOrder interface {
+	// Less reports whether a is applied before b
+	Less(a, b BalanceUpdate) bool
+}
+
+// OrderByAccountID updates the balances in account ID order. Every transfer agrees on that order,
+// so concurrent transfers cannot deadlock on their accounts. It is the default.
+type OrderByAccountID struct{}
+
+func (OrderByAccountID) Less(a, b BalanceUpdate) bool {
+	return a.AccountID < b.AccountID
+}
+
+// OrderByAmount updates the balances from the largest debit to the largest credit, in account ID order for equal amounts.
+// Transfers between the same accounts in opposite directions lock them in opposite orders,
+// so unlike OrderByAccountID it can deadlock; it is meant to compare contention under different workloads.
+type OrderByAmount struct{}
+
+func (OrderByAmount) Less(a, b BalanceUpdate) bool {
+	if a.Amount != b.Amount {
+		return a.Amount < b.Amount
+	}
+	return a.AccountID < b.AccountID
+}
+
+// BalanceOrderFunc orders the balance updates with a custom comparator
+type BalanceOrderFunc func(a, b BalanceUpdate) bool
+
+func (less BalanceOrderFunc) Less(a, b BalanceUpdate) bool {
+	return less(a, b)
+}
+
+// balanceUpdateOrder returns the indexes of the updates in the order they are applied in.
+// A nil order is OrderByAccountID; updates the order does not tell apart keep their relative order.
+func balanceUpdateOrder(updates []BalanceUpdate, order BalanceOrder) []int {
+	if order == nil {
+		order = OrderByAccountID{}
+	}
+	indexes := make([]int, len(updates))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return order.Less(updates[indexes[i]], updates[indexes[j]])
+	})
+	return indexes
+}
+
+// addBalanceUpdates applies the updates in the given order and returns the updated accounts,
+// each at the index of its update
+func (q *Queries) addBalanceUpdates(ctx context.Context, updates []BalanceUpdate, order BalanceOrder) ([]Account, error) {
+	accounts := make([]Account, len(updates))
+	for _, i := range balanceUpdateOrder(updates, order) {
+		account, err := q.AddAccountBalance(ctx, AddAccountBalanceParams{
+			Amount: updates[i].Amount,
+			ID:     updates[i].AccountID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		accounts[i] = account
+	}
+	return accounts, nil
+}
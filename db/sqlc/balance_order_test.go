@@ -0,0 +1,149 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/khuongkd/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+// orderedAccountIDs returns the account IDs of the updates in the order they are applied in
+func orderedAccountIDs(updates []BalanceUpdate, order BalanceOrder) []int64 {
+	var ids []int64
+	for _, i := range balanceUpdateOrder(updates, order) {
+		ids = append(ids, updates[i].AccountID)
+	}
+	return ids
+}
+
+func TestBalanceUpdateOrder(t *testing.T) {
+	// the balance updates of a set of transfers, the last one charging a fee to account 1
+	transfers := [][]BalanceUpdate{
+		{{AccountID: 3, Amount: -10}, {AccountID: 7, Amount: 10}},
+		{{AccountID: 7, Amount: -50}, {AccountID: 3, Amount: 50}},
+		{{AccountID: 5, Amount: -20}, {AccountID: 2, Amount: 15}, {AccountID: 1, Amount: 5}},
+	}
+
+	testCases := []struct {
+		name  string
+		order BalanceOrder
+		want  [][]int64
+	}{
+		{
+			name: "Default",
+			want: [][]int64{{3, 7}, {3, 7}, {1, 2, 5}},
+		},
+		{
+			name:  "AccountID",
+			order: OrderByAccountID{},
+			want:  [][]int64{{3, 7}, {3, 7}, {1, 2, 5}},
+		},
+		{
+			name:  "Amount",
+			order: OrderByAmount{},
+			want:  [][]int64{{3, 7}, {7, 3}, {5, 1, 2}},
+		},
+		{
+			name: "Custom",
+			order: BalanceOrderFunc(func(a, b BalanceUpdate) bool {
+				return a.AccountID > b.AccountID
+			}),
+			want: [][]int64{{7, 3}, {7, 3}, {5, 2, 1}},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			for j, updates := range transfers {
+				require.Equal(t, tc.want[j], orderedAccountIDs(updates, tc.order))
+			}
+		})
+	}
+}
+
+func TestBalanceUpdateOrderAmountTies(t *testing.T) {
+	updates := []BalanceUpdate{{AccountID: 9, Amount: 10}, {AccountID: 4, Amount: 10}, {AccountID: 6, Amount: -20}}
+	require.Equal(t, []int64{6, 4, 9}, orderedAccountIDs(updates, OrderByAmount{}))
+}
+
+// balanceUpdatesDB records the accounts whose balance is updated through it
+type balanceUpdatesDB struct {
+	DBTX
+	accountIDs []int64
+}
+
+func (db *balanceUpdatesDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	if queryName(query) == "AddAccountBalance" {
+		db.accountIDs = append(db.accountIDs, args[1].(int64))
+	}
+	return db.DBTX.QueryRowContext(ctx, query, args...)
+}
+
+func TestTransferBalanceOrder(t *testing.T) {
+	account1 := fundTestAccount(t, createTestAccount(t), 1000)
+	// another owner, since transfers between one's own accounts are free of fees
+	account2 := fundTestAccount(t, createTestAccountFor(t, util.RandomOwner(), account1.Currency), 1000)
+	feeAccount := createTestAccountFor(t, account1.Owner, account1.Currency)
+
+	testCases := []struct {
+		name   string
+		params TransferTxParams
+		want   []int64
+	}{
+		{
+			name:   "AccountID",
+			params: TransferTxParams{FromAccountID: account2.ID, ToAccountID: account1.ID, Amount: 10},
+			want:   []int64{account1.ID, account2.ID},
+		},
+		{
+			name:   "Amount",
+			params: TransferTxParams{FromAccountID: account2.ID, ToAccountID: account1.ID, Amount: 10, BalanceOrder: OrderByAmount{}},
+			want:   []int64{account2.ID, account1.ID},
+		},
+		{
+			name: "AmountWithFee",
+			params: TransferTxParams{
+				FromAccountID: account2.ID,
+				ToAccountID:   account1.ID,
+				Amount:        10,
+				Fee:           20,
+				FeeAccountID:  feeAccount.ID,
+				BalanceOrder:  OrderByAmount{},
+			},
+			want: []int64{account2.ID, account1.ID, feeAccount.ID},
+		},
+		{
+			name: "Custom",
+			params: TransferTxParams{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        10,
+				Fee:           5,
+				FeeAccountID:  feeAccount.ID,
+				BalanceOrder: BalanceOrderFunc(func(a, b BalanceUpdate) bool {
+					return a.AccountID > b.AccountID
+				}),
+			},
+			want: []int64{feeAccount.ID, account2.ID, account1.ID},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			tx, err := testDB.BeginTx(context.Background(), nil)
+			require.NoError(t, err)
+			defer tx.Rollback()
+
+			db := &balanceUpdatesDB{DBTX: tx}
+			_, err = NewStoreWithDialect(testDB, Postgres).transfer(context.Background(), New(db), tc.params)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, db.accountIDs)
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package db
+
+import (
+	"errors"
+	"expvar"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// deadlockDetected is the Postgres error code of a transaction aborted to break a deadlock
+const deadlockDetected = "40P01"
+
+// ErrDeadlock is matched by every DeadlockError
+var ErrDeadlock = errors.New("deadlock detected")
+
+// DeadlockCount counts the transactions Postgres aborted to break a deadlock, retried or not
+var DeadlockCount = expvar.NewInt("db_deadlocks")
+
+// DeadlockError reports a transaction that Postgres aborted to break a deadlock
+type DeadlockError struct {
+	// AccountIDs are the accounts the transaction was updating
+	AccountIDs []int64
+	// Retried tells whether the transaction already ran a second time before giving up
+	Retried bool
+	Err     error
+}
+
+func (e *DeadlockError) Error() string {
+	return fmt.Sprintf("%s on accounts %v (retried: %t): %v", ErrDeadlock, e.AccountIDs, e.Retried, e.Err)
+}
+
+func (e *DeadlockError) Is(target error) bool {
+	return target == ErrDeadlock
+}
+
+func (e *DeadlockError) Unwrap() error {
+	return e.Err
+}
+
+func isDeadlock(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == deadlockDetected
+}
+
+// deadlockError counts a deadlock and wraps it in a DeadlockError naming the accounts involved,
+// and returns any other error unchanged
+func deadlockError(err error, accountIDs []int64, retried bool) error {
+	if !isDeadlock(err) {
+		return err
+	}
+	DeadlockCount.Add(1)
+	return &DeadlockError{AccountIDs: accountIDs, Retried: retried, Err: err}
+}
@@ -0,0 +1,31 @@
+package db
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeadlockError(t *testing.T) {
+	before := DeadlockCount.Value()
+
+	err := deadlockError(&pq.Error{Code: deadlockDetected}, []int64{1, 2}, true)
+	require.ErrorIs(t, err, ErrDeadlock)
+	require.Equal(t, before+1, DeadlockCount.Value())
+
+	var deadlockErr *DeadlockError
+	require.True(t, errors.As(err, &deadlockErr))
+	require.Equal(t, []int64{1, 2}, deadlockErr.AccountIDs)
+	require.True(t, deadlockErr.Retried)
+	require.Contains(t, err.Error(), "[1 2]")
+
+	var pqErr *pq.Error
+	require.True(t, errors.As(err, &pqErr))
+
+	other := errors.New("other")
+	require.Equal(t, other, deadlockError(other, []int64{1, 2}, false))
+	require.NoError(t, deadlockError(nil, []int64{1, 2}, false))
+	require.Equal(t, before+1, DeadlockCount.Value())
+}
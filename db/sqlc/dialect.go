@@ -0,0 +1,27 @@
+package db
+
+import "fmt"
+
+// Dialect is the SQL database the store runs on.
+// The queries sqlc generates are Postgres SQL, so Postgres is the only one; MySQL is not supported.
+type Dialect interface {
+	// Name is the database/sql driver the dialect goes with
+	Name() string
+}
+
+// Postgres is the dialect of the lib/pq driver
+var Postgres Dialect = postgresDialect{}
+
+// DialectFor returns the dialect of the database/sql driver
+func DialectFor(driver string) (Dialect, error) {
+	if driver != Postgres.Name() {
+		return nil, fmt.Errorf("no SQL dialect for driver %q, only %s is supported", driver, Postgres.Name())
+	}
+	return Postgres, nil
+}
+
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string {
+	return "postgres"
+}
@@ -0,0 +1,18 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDialectFor(t *testing.T) {
+	dialect, err := DialectFor("postgres")
+	require.NoError(t, err)
+	require.Equal(t, Postgres, dialect)
+
+	for _, driver := range []string{"mysql", "sqlite3", ""} {
+		_, err = DialectFor(driver)
+		require.Error(t, err, driver)
+	}
+}
@@ -7,8 +7,30 @@ package db
 
 import (
 	"context"
+	"time"
 )
 
+const countStatementEntries = `-- name: CountStatementEntries :one
+SELECT (
+  (SELECT COUNT(*) FROM entries e WHERE e.account_id = $1 AND e.created_at >= $2 AND e.created_at < $3) +
+  (SELECT COUNT(*) FROM entries_archive a WHERE a.account_id = $1 AND a.created_at >= $2 AND a.created_at < $3)
+)::bigint AS total
+`
+
+type CountStatementEntriesParams struct {
+	AccountID int64     `json:"account_id"`
+	FromTime  time.Time `json:"from_time"`
+	ToTime    time.Time `json:"to_time"`
+}
+
+// Counts the entries of an account, archived ones included, created in [from_time, to_time).
+func (q *Queries) CountStatementEntries(ctx context.Context, arg CountStatementEntriesParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countStatementEntries, arg.AccountID, arg.FromTime, arg.ToTime)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
+
 const createEntry = `-- name: CreateEntry :one
 INSERT INTO entries(
   account_id,
@@ -43,6 +65,62 @@ func (q *Queries) DeleteEntry(ctx context.Context, id int64) error {
 	return err
 }
 
+const findBalanceDiscrepancies = `-- name: FindBalanceDiscrepancies :many
+SELECT
+  a.id AS account_id,
+  a.balance,
+  (COALESCE(e.total, 0) + COALESCE(ea.total, 0))::bigint AS entries_total,
+  (a.balance - COALESCE(e.total, 0) - COALESCE(ea.total, 0))::bigint AS discrepancy
+FROM accounts a
+LEFT JOIN (SELECT account_id, SUM(amount) AS total FROM entries GROUP BY account_id) e ON e.account_id = a.id
+LEFT JOIN (SELECT account_id, SUM(amount) AS total FROM entries_archive GROUP BY account_id) ea ON ea.account_id = a.id
+WHERE a.balance <> COALESCE(e.total, 0) + COALESCE(ea.total, 0)
+ORDER BY a.id
+LIMIT $2
+OFFSET $1
+`
+
+type FindBalanceDiscrepanciesParams struct {
+	Offset int32 `json:"offset"`
+	Limit  int32 `json:"limit"`
+}
+
+type FindBalanceDiscrepanciesRow struct {
+	AccountID    int64 `json:"account_id"`
+	Balance      int64 `json:"balance"`
+	EntriesTotal int64 `json:"entries_total"`
+	Discrepancy  int64 `json:"discrepancy"`
+}
+
+// Lists the accounts whose balance differs from the sum of their entries, archived ones included, in a single pass over the entries.
+func (q *Queries) FindBalanceDiscrepancies(ctx context.Context, arg FindBalanceDiscrepanciesParams) ([]FindBalanceDiscrepanciesRow, error) {
+	rows, err := q.db.QueryContext(ctx, findBalanceDiscrepancies, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FindBalanceDiscrepanciesRow
+	for rows.Next() {
+		var i FindBalanceDiscrepanciesRow
+		if err := rows.Scan(
+			&i.AccountID,
+			&i.Balance,
+			&i.EntriesTotal,
+			&i.Discrepancy,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getEntry = `-- name: GetEntry :one
 SELECT id, account_id, amount, created_at FROM entries WHERE id = $1
 `
@@ -96,6 +174,216 @@ func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Ent
 	return items, nil
 }
 
+const listEntriesWithRunningBalance = `-- name: ListEntriesWithRunningBalance :many
+SELECT h.id, h.account_id, h.amount, h.created_at, h.running_balance FROM (
+  SELECT
+    u.id, u.account_id, u.amount, u.created_at,
+    (SUM(u.amount) OVER (PARTITION BY u.account_id ORDER BY u.created_at, u.id))::bigint AS running_balance
+  FROM (
+    SELECT e.id, e.account_id, e.amount, e.created_at FROM entries e WHERE e.account_id = $1
+    UNION ALL
+    SELECT a.id, a.account_id, a.amount, a.created_at FROM entries_archive a WHERE a.account_id = $1
+  ) u
+) h
+ORDER BY h.created_at, h.id
+LIMIT $3
+OFFSET $2
+`
+
+type ListEntriesWithRunningBalanceParams struct {
+	AccountID int64 `json:"account_id"`
+	Offset    int32 `json:"offset"`
+	Limit     int32 `json:"limit"`
+}
+
+type ListEntriesWithRunningBalanceRow struct {
+	ID             int64     `json:"id"`
+	AccountID      int64     `json:"account_id"`
+	Amount         int64     `json:"amount"`
+	CreatedAt      time.Time `json:"created_at"`
+	RunningBalance int64     `json:"running_balance"`
+}
+
+// Lists the entries of an account, archived ones included, each with the sum of the amounts up to and including it.
+// The sum runs over every entry before the page, so each page carries on from the previous one.
+func (q *Queries) ListEntriesWithRunningBalance(ctx context.Context, arg ListEntriesWithRunningBalanceParams) ([]ListEntriesWithRunningBalanceRow, error) {
+	rows, err := q.db.QueryContext(ctx, listEntriesWithRunningBalance, arg.AccountID, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListEntriesWithRunningBalanceRow
+	for rows.Next() {
+		var i ListEntriesWithRunningBalanceRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.RunningBalance,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStatementEntries = `-- name: ListStatementEntries :many
+SELECT e.id, e.account_id, e.amount, e.created_at FROM entries e
+WHERE e.account_id = $2 AND e.created_at >= $3 AND e.created_at < $4 AND e.id > $5
+UNION ALL
+SELECT a.id, a.account_id, a.amount, a.created_at FROM entries_archive a
+WHERE a.account_id = $2 AND a.created_at >= $3 AND a.created_at < $4 AND a.id > $5
+ORDER BY id
+LIMIT $1
+`
+
+type ListStatementEntriesParams struct {
+	Limit     int32     `json:"limit"`
+	AccountID int64     `json:"account_id"`
+	FromTime  time.Time `json:"from_time"`
+	ToTime    time.Time `json:"to_time"`
+	AfterID   int64     `json:"after_id"`
+}
+
+// Lists the entries of an account, archived ones included, created in [from_time, to_time) with an id above after_id.
+// Archived entries keep their id, so ordering by id pages through both tables as one.
+func (q *Queries) ListStatementEntries(ctx context.Context, arg ListStatementEntriesParams) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, listStatementEntries,
+		arg.Limit,
+		arg.AccountID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.AfterID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Entry
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const sumEntriesByAccount = `-- name: SumEntriesByAccount :one
+SELECT (
+  COALESCE((SELECT SUM(e.amount) FROM entries e WHERE e.account_id = $1), 0) +
+  COALESCE((SELECT SUM(a.amount) FROM entries_archive a WHERE a.account_id = $1), 0)
+)::bigint AS total
+`
+
+func (q *Queries) SumEntriesByAccount(ctx context.Context, accountID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, sumEntriesByAccount, accountID)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
+
+const sumEntriesByAccountAsOf = `-- name: SumEntriesByAccountAsOf :one
+SELECT (
+  COALESCE((SELECT SUM(e.amount) FROM entries e WHERE e.account_id = $1 AND e.created_at <= $2), 0) +
+  COALESCE((SELECT SUM(a.amount) FROM entries_archive a WHERE a.account_id = $1 AND a.created_at <= $2), 0)
+)::bigint AS total
+`
+
+type SumEntriesByAccountAsOfParams struct {
+	AccountID int64     `json:"account_id"`
+	AsOf      time.Time `json:"as_of"`
+}
+
+func (q *Queries) SumEntriesByAccountAsOf(ctx context.Context, arg SumEntriesByAccountAsOfParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, sumEntriesByAccountAsOf, arg.AccountID, arg.AsOf)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
+
+const totalSystemBalance = `-- name: TotalSystemBalance :many
+SELECT
+  t.currency,
+  SUM(t.accounts)::bigint AS accounts,
+  SUM(t.balance)::bigint AS balance,
+  SUM(t.entries_total)::bigint AS entries_total,
+  (SUM(t.balance) - SUM(t.entries_total))::bigint AS discrepancy
+FROM (
+  SELECT a.currency, 1 AS accounts, a.balance, COALESCE(e.total, 0) + COALESCE(ea.total, 0) AS entries_total
+  FROM accounts a
+  LEFT JOIN (SELECT account_id, SUM(amount) AS total FROM entries GROUP BY account_id) e ON e.account_id = a.id
+  LEFT JOIN (SELECT account_id, SUM(amount) AS total FROM entries_archive GROUP BY account_id) ea ON ea.account_id = a.id
+  UNION ALL
+  SELECT s.currency, 0 AS accounts, s.balance, COALESCE(se.total, 0) AS entries_total
+  FROM account_sub_balances s
+  LEFT JOIN (SELECT account_id, currency, SUM(amount) AS total FROM sub_balance_entries GROUP BY account_id, currency) se
+    ON se.account_id = s.account_id AND se.currency = s.currency
+) t
+GROUP BY t.currency
+ORDER BY t.currency
+`
+
+type TotalSystemBalanceRow struct {
+	Currency     string `json:"currency"`
+	Accounts     int64  `json:"accounts"`
+	Balance      int64  `json:"balance"`
+	EntriesTotal int64  `json:"entries_total"`
+	Discrepancy  int64  `json:"discrepancy"`
+}
+
+// Sums the balances of all accounts per currency, next to the sum of their entries, archived ones included,
+// so treasury can see how much money the bank holds and whether the ledger backs it.
+// Sub-balances count towards their own currency, backed by their sub-balance entries; accounts counts the accounts held in the currency.
+func (q *Queries) TotalSystemBalance(ctx context.Context) ([]TotalSystemBalanceRow, error) {
+	rows, err := q.db.QueryContext(ctx, totalSystemBalance)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TotalSystemBalanceRow
+	for rows.Next() {
+		var i TotalSystemBalanceRow
+		if err := rows.Scan(
+			&i.Currency,
+			&i.Accounts,
+			&i.Balance,
+			&i.EntriesTotal,
+			&i.Discrepancy,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateEntry = `-- name: UpdateEntry :one
 UPDATE entries
 SET amount = $1
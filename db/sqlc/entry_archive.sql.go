@@ -0,0 +1,75 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.13.0
+// source: entry_archive.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const archiveEntries = `-- name: ArchiveEntries :execrows
+WITH moved AS (
+  DELETE FROM entries
+  WHERE id IN (
+    SELECT e.id FROM entries e
+    WHERE e.created_at < $1
+    ORDER BY e.id
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+  )
+  RETURNING id, account_id, amount, created_at
+)
+INSERT INTO entries_archive (id, account_id, amount, created_at)
+SELECT id, account_id, amount, created_at FROM moved
+`
+
+type ArchiveEntriesParams struct {
+	Before    time.Time `json:"before"`
+	BatchSize int32     `json:"batch_size"`
+}
+
+func (q *Queries) ArchiveEntries(ctx context.Context, arg ArchiveEntriesParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, archiveEntries, arg.Before, arg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const listArchivedEntriesByAccount = `-- name: ListArchivedEntriesByAccount :many
+SELECT id, account_id, amount, created_at, archived_at FROM entries_archive
+WHERE account_id = $1
+ORDER BY id
+`
+
+func (q *Queries) ListArchivedEntriesByAccount(ctx context.Context, accountID int64) ([]EntriesArchive, error) {
+	rows, err := q.db.QueryContext(ctx, listArchivedEntriesByAccount, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []EntriesArchive
+	for rows.Next() {
+		var i EntriesArchive
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ArchivedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
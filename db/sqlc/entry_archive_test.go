@@ -0,0 +1,117 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchiveEntries(t *testing.T) {
+	account := createTestAccount(t)
+	var created []Entry
+	for _, amount := range []int64{10, -4, 7} {
+		entry, err := testQueries.CreateEntry(context.Background(), CreateEntryParams{AccountID: account.ID, Amount: amount})
+		require.NoError(t, err)
+		created = append(created, entry)
+	}
+	before := created[len(created)-1].CreatedAt.Add(time.Second)
+
+	// other tests leave entries behind, so archive in batches until this account's entries are gone
+	for {
+		n, err := testQueries.ArchiveEntries(context.Background(), ArchiveEntriesParams{Before: before, BatchSize: 100})
+		require.NoError(t, err)
+		if n < 100 {
+			break
+		}
+	}
+
+	for _, entry := range created {
+		_, err := testQueries.GetEntry(context.Background(), entry.ID)
+		require.ErrorIs(t, err, sql.ErrNoRows)
+	}
+
+	archived, err := testQueries.ListArchivedEntriesByAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Len(t, archived, len(created))
+	for i, entry := range archived {
+		require.Equal(t, created[i].ID, entry.ID)
+		require.Equal(t, created[i].Amount, entry.Amount)
+		require.WithinDuration(t, created[i].CreatedAt, entry.CreatedAt, time.Second)
+		require.NotZero(t, entry.ArchivedAt)
+	}
+
+	// archived entries still count towards the account's total
+	total, err := testQueries.SumEntriesByAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(13), total)
+}
+
+func TestArchiveEntriesKeepsRecent(t *testing.T) {
+	account := createTestAccount(t)
+	entry, err := testQueries.CreateEntry(context.Background(), CreateEntryParams{AccountID: account.ID, Amount: 5})
+	require.NoError(t, err)
+
+	_, err = testQueries.ArchiveEntries(context.Background(), ArchiveEntriesParams{
+		Before:    entry.CreatedAt.Add(-time.Hour),
+		BatchSize: 100,
+	})
+	require.NoError(t, err)
+
+	hot, err := testQueries.GetEntry(context.Background(), entry.ID)
+	require.NoError(t, err)
+	require.Equal(t, entry.ID, hot.ID)
+}
+
+func TestListStatementEntries(t *testing.T) {
+	account := createTestAccount(t)
+	var created []Entry
+	for _, amount := range []int64{10, -4, 7} {
+		entry, err := testQueries.CreateEntry(context.Background(), CreateEntryParams{AccountID: account.ID, Amount: amount})
+		require.NoError(t, err)
+		created = append(created, entry)
+	}
+
+	// archive the first entry only, so that the statement spans both tables
+	for {
+		n, err := testQueries.ArchiveEntries(context.Background(), ArchiveEntriesParams{Before: created[1].CreatedAt, BatchSize: 100})
+		require.NoError(t, err)
+		if n < 100 {
+			break
+		}
+	}
+
+	from := created[0].CreatedAt.Add(-time.Second)
+	to := created[2].CreatedAt.Add(time.Second)
+	total, err := testQueries.CountStatementEntries(context.Background(), CountStatementEntriesParams{
+		AccountID: account.ID,
+		FromTime:  from,
+		ToTime:    to,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(3), total)
+
+	arg := ListStatementEntriesParams{AccountID: account.ID, FromTime: from, ToTime: to, Limit: 2}
+	page, err := testQueries.ListStatementEntries(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	require.Equal(t, created[0].ID, page[0].ID)
+	require.Equal(t, created[1].ID, page[1].ID)
+
+	arg.AfterID = page[1].ID
+	page, err = testQueries.ListStatementEntries(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	require.Equal(t, created[2].ID, page[0].ID)
+
+	// the end of the range is excluded
+	total, err = testQueries.CountStatementEntries(context.Background(), CountStatementEntriesParams{
+		AccountID: account.ID,
+		FromTime:  from,
+		ToTime:    created[2].CreatedAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), total)
+}
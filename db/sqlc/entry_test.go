@@ -13,7 +13,7 @@ func createTestEntry(t *testing.T) Entry {
 	account := createTestAccount(t)
 	arg := CreateEntryParams{
 		AccountID: account.ID,
-		Amount:    util.RandomMoney(),
+		Amount:    util.RandomMoneyForCurrency(account.Currency),
 	}
 
 	entry, err := testQueries.CreateEntry(context.Background(), arg)
@@ -85,3 +85,20 @@ func TestListEntries(t *testing.T) {
 		require.NotEmpty(t, entry)
 	}
 }
+
+func TestSumEntriesByAccount(t *testing.T) {
+	account := createTestAccount(t)
+
+	total, err := testQueries.SumEntriesByAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Zero(t, total)
+
+	for _, amount := range []int64{10, -3, 25} {
+		_, err := testQueries.CreateEntry(context.Background(), CreateEntryParams{AccountID: account.ID, Amount: amount})
+		require.NoError(t, err)
+	}
+
+	total, err = testQueries.SumEntriesByAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(32), total)
+}
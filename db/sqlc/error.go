@@ -0,0 +1,148 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// checkViolation is the Postgres error code of a failed CHECK constraint
+const checkViolation = "23514"
+
+// uniqueViolation is the Postgres error code of a duplicate key in a unique index
+const uniqueViolation = "23505"
+
+// accountNumberKey is the unique index keeping account numbers unique within a currency
+const accountNumberKey = "accounts_currency_number_key"
+
+// transferExternalRefKey is the unique index keeping the external references of transfers unique
+const transferExternalRefKey = "transfers_external_ref_key"
+
+// maxAccountNumberAttempts bounds how often CreateAcount retries when a concurrent insert took the same account number
+const maxAccountNumberAttempts = 5
+
+// Names of the CHECK constraints enforcing business invariants in the database
+const (
+	ConstraintBalanceNonNegative = "accounts_balance_non_negative"
+	ConstraintAmountPositive     = "transfers_amount_positive"
+	ConstraintFeeNonNegative     = "transfers_fee_non_negative"
+
+	ConstraintScheduledAmountPositive = "scheduled_transfers_amount_positive"
+	ConstraintHeldBalanceValid        = "accounts_held_balance_valid"
+	ConstraintHoldAmountPositive      = "transfer_holds_amount_positive"
+	ConstraintAdjustmentAmountNonZero = "account_adjustments_amount_non_zero"
+	ConstraintAdjustmentReasonPresent = "account_adjustments_reason_present"
+	ConstraintAccountTypeValid        = "accounts_account_type_valid"
+	ConstraintMinBalanceNonNegative   = "accounts_min_balance_non_negative"
+	ConstraintSubBalanceNonNegative   = "account_sub_balances_balance_non_negative"
+	ConstraintFreezeReasonRequired    = "accounts_freeze_reason_required"
+
+	ConstraintConfirmationAmountPositive = "transfer_confirmations_amount_positive"
+)
+
+// ErrConstraintViolation is returned when a write breaks one of the database CHECK constraints
+var ErrConstraintViolation = errors.New("constraint violation")
+
+// ErrDuplicateExternalRef is returned when a transfer is given the external reference of an earlier one
+var ErrDuplicateExternalRef = errors.New("duplicate external reference")
+
+// constraintError translates a check violation reported by Postgres into ErrConstraintViolation
+// naming the constraint, and a duplicate transfer external reference into ErrDuplicateExternalRef.
+// It returns any other error unchanged.
+func constraintError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == checkViolation {
+		return fmt.Errorf("%w: %s", ErrConstraintViolation, pqErr.Constraint)
+	}
+	if isUniqueViolation(err, transferExternalRefKey) {
+		return ErrDuplicateExternalRef
+	}
+	return err
+}
+
+// isUniqueViolation reports whether err is a duplicate key in the named unique index
+func isUniqueViolation(err error, index string) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == uniqueViolation && pqErr.Constraint == index
+}
+
+// The writes below touch constrained columns outside a transaction,
+// so SQLStore wraps them to report check violations the same way execTx does.
+
+// CreateAcount also retries when a concurrent insert took the account number first
+func (store *SQLStore) CreateAcount(ctx context.Context, arg CreateAcountParams) (Account, error) {
+	for attempt := 1; ; attempt++ {
+		account, err := store.Queries.CreateAcount(ctx, arg)
+		if attempt < maxAccountNumberAttempts && isUniqueViolation(err, accountNumberKey) {
+			continue
+		}
+		return account, constraintError(err)
+	}
+}
+
+func (store *SQLStore) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error) {
+	account, err := store.Queries.UpdateAccount(ctx, arg)
+	return account, constraintError(err)
+}
+
+func (store *SQLStore) PatchAccount(ctx context.Context, arg PatchAccountParams) (Account, error) {
+	account, err := store.Queries.PatchAccount(ctx, arg)
+	return account, constraintError(err)
+}
+
+func (store *SQLStore) SetAccountMinBalance(ctx context.Context, arg SetAccountMinBalanceParams) (Account, error) {
+	account, err := store.Queries.SetAccountMinBalance(ctx, arg)
+	return account, constraintError(err)
+}
+
+func (store *SQLStore) AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error) {
+	account, err := store.Queries.AddAccountBalance(ctx, arg)
+	return account, constraintError(err)
+}
+
+func (store *SQLStore) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
+	transfer, err := store.Queries.CreateTransfer(ctx, arg)
+	return transfer, constraintError(err)
+}
+
+func (store *SQLStore) UpdateTransfer(ctx context.Context, arg UpdateTransferParams) (Transfer, error) {
+	transfer, err := store.Queries.UpdateTransfer(ctx, arg)
+	return transfer, constraintError(err)
+}
+
+func (store *SQLStore) CreateScheduledTransfer(ctx context.Context, arg CreateScheduledTransferParams) (ScheduledTransfer, error) {
+	scheduled, err := store.Queries.CreateScheduledTransfer(ctx, arg)
+	return scheduled, constraintError(err)
+}
+
+func (store *SQLStore) CreateTransferHold(ctx context.Context, arg CreateTransferHoldParams) (TransferHold, error) {
+	hold, err := store.Queries.CreateTransferHold(ctx, arg)
+	return hold, constraintError(err)
+}
+
+func (store *SQLStore) AddAccountHeldBalance(ctx context.Context, arg AddAccountHeldBalanceParams) (Account, error) {
+	account, err := store.Queries.AddAccountHeldBalance(ctx, arg)
+	return account, constraintError(err)
+}
+
+func (store *SQLStore) SetAccountCurrency(ctx context.Context, arg SetAccountCurrencyParams) (Account, error) {
+	account, err := store.Queries.SetAccountCurrency(ctx, arg)
+	return account, constraintError(err)
+}
+
+func (store *SQLStore) AddSubBalance(ctx context.Context, arg AddSubBalanceParams) (AccountSubBalance, error) {
+	subBalance, err := store.Queries.AddSubBalance(ctx, arg)
+	return subBalance, constraintError(err)
+}
+
+func (store *SQLStore) CreateAccountAdjustment(ctx context.Context, arg CreateAccountAdjustmentParams) (AccountAdjustment, error) {
+	adjustment, err := store.Queries.CreateAccountAdjustment(ctx, arg)
+	return adjustment, constraintError(err)
+}
+
+func (store *SQLStore) CreateTransferConfirmation(ctx context.Context, arg CreateTransferConfirmationParams) (TransferConfirmation, error) {
+	confirmation, err := store.Queries.CreateTransferConfirmation(ctx, arg)
+	return confirmation, constraintError(err)
+}
@@ -0,0 +1,105 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/khuongkd/simplebank/util"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConstraintError(t *testing.T) {
+	err := constraintError(&pq.Error{Code: checkViolation, Constraint: ConstraintAmountPositive})
+	require.ErrorIs(t, err, ErrConstraintViolation)
+	require.Contains(t, err.Error(), ConstraintAmountPositive)
+
+	err = constraintError(&pq.Error{Code: uniqueViolation, Constraint: transferExternalRefKey})
+	require.ErrorIs(t, err, ErrDuplicateExternalRef)
+
+	other := errors.New("other")
+	require.Equal(t, other, constraintError(other))
+	require.NoError(t, constraintError(nil))
+}
+
+func TestBalanceNonNegativeConstraint(t *testing.T) {
+	store := NewStore(testDB)
+	account := createTestAccount(t)
+
+	_, err := store.UpdateAccount(context.Background(), UpdateAccountParams{ID: account.ID, Balance: -1})
+	require.ErrorIs(t, err, ErrConstraintViolation)
+	require.Contains(t, err.Error(), ConstraintBalanceNonNegative)
+
+	// overdrawing through a transfer rolls the whole transaction back
+	toAccount := createTestAccount(t)
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        account.Balance + 1,
+	})
+	require.ErrorIs(t, err, ErrConstraintViolation)
+	require.Contains(t, err.Error(), ConstraintBalanceNonNegative)
+
+	updatedAccount, err := store.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance, updatedAccount.Balance)
+}
+
+func TestAmountPositiveConstraint(t *testing.T) {
+	store := NewStore(testDB)
+	fromAccount := createTestAccount(t)
+	toAccount := createTestAccount(t)
+
+	_, err := store.CreateTransfer(context.Background(), CreateTransferParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        0,
+	})
+	require.ErrorIs(t, err, ErrConstraintViolation)
+	require.Contains(t, err.Error(), ConstraintAmountPositive)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        -1,
+	})
+	require.ErrorIs(t, err, ErrConstraintViolation)
+	require.Contains(t, err.Error(), ConstraintAmountPositive)
+}
+
+func TestTransferExternalRefUnique(t *testing.T) {
+	store := NewStore(testDB)
+	fromAccount := fundTestAccount(t, createTestAccount(t), 100)
+	toAccount := createTestAccount(t)
+	ref := util.RandomString(12)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        10,
+		ExternalRef:   ref,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, result.Transfer.ExternalRef)
+	require.Equal(t, ref, *result.Transfer.ExternalRef)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        10,
+		ExternalRef:   ref,
+	})
+	require.ErrorIs(t, err, ErrDuplicateExternalRef)
+
+	// transfers without a reference never collide
+	for i := 0; i < 2; i++ {
+		result, err = store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: fromAccount.ID,
+			ToAccountID:   toAccount.ID,
+			Amount:        10,
+		})
+		require.NoError(t, err)
+		require.Nil(t, result.Transfer.ExternalRef)
+	}
+}
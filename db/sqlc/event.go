@@ -0,0 +1,120 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Types of an event
+const (
+	EventTransferCreated = "transfer.created"
+	EventTransferFlagged = "transfer.flagged"
+)
+
+// TransferEvent is the payload of an EventTransferCreated event
+type TransferEvent struct {
+	TransferID    int64 `json:"transfer_id"`
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID   int64 `json:"to_account_id"`
+	Amount        int64 `json:"amount"`
+	Fee           int64 `json:"fee,omitempty"`
+	FeeAccountID  int64 `json:"fee_account_id,omitempty"`
+}
+
+// TransferFlaggedEvent is the payload of an EventTransferFlagged event, marking a transfer for review
+type TransferFlaggedEvent struct {
+	TransferID    int64  `json:"transfer_id"`
+	FromAccountID int64  `json:"from_account_id"`
+	Reason        string `json:"reason"`
+}
+
+// NewTransferFlaggedEvent describes the flag on the transfer as an event
+func NewTransferFlaggedEvent(transfer Transfer, reason string) (CreateEventParams, error) {
+	payload, err := json.Marshal(TransferFlaggedEvent{
+		TransferID:    transfer.ID,
+		FromAccountID: transfer.FromAccountID,
+		Reason:        reason,
+	})
+	if err != nil {
+		return CreateEventParams{}, err
+	}
+	return CreateEventParams{
+		EventType:  EventTransferFlagged,
+		Payload:    payload,
+		AccountIds: []int64{transfer.FromAccountID},
+	}, nil
+}
+
+// NewTransferEvent describes the transfer as an event. The fee account only counts when a fee was charged.
+func NewTransferEvent(transfer Transfer, feeAccountID int64) (CreateEventParams, error) {
+	event := TransferEvent{
+		TransferID:    transfer.ID,
+		FromAccountID: transfer.FromAccountID,
+		ToAccountID:   transfer.ToAccountID,
+		Amount:        transfer.Amount,
+	}
+	accountIDs := []int64{transfer.FromAccountID, transfer.ToAccountID}
+	if transfer.Fee > 0 {
+		event.Fee = transfer.Fee
+		event.FeeAccountID = feeAccountID
+		accountIDs = append(accountIDs, feeAccountID)
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return CreateEventParams{}, err
+	}
+	return CreateEventParams{
+		EventType:  EventTransferCreated,
+		Payload:    payload,
+		AccountIds: accountIDs,
+	}, nil
+}
+
+// recordTransferEvents appends the transfer to the event log, along with its flag when the caller asked for a review,
+// within the transaction that made it
+func (q *Queries) recordTransferEvents(ctx context.Context, transfer Transfer, params TransferTxParams) error {
+	arg, err := NewTransferEvent(transfer, params.FeeAccountID)
+	if err != nil {
+		return err
+	}
+	if _, err = q.CreateEvent(ctx, arg); err != nil {
+		return err
+	}
+	if params.FlagReason == "" {
+		return nil
+	}
+
+	arg, err = NewTransferFlaggedEvent(transfer, params.FlagReason)
+	if err != nil {
+		return err
+	}
+	_, err = q.CreateEvent(ctx, arg)
+	return err
+}
+
+// ReplayEvents returns the change the events made to the balance of every account they touch, keyed by account ID.
+// Adding the changes to the balances from before the first event gives the balances after the last one.
+func ReplayEvents(events []Event) (map[int64]int64, error) {
+	changes := make(map[int64]int64)
+	for _, event := range events {
+		switch event.EventType {
+		case EventTransferCreated:
+			var transfer TransferEvent
+			if err := json.Unmarshal(event.Payload, &transfer); err != nil {
+				return nil, fmt.Errorf("cannot decode event %d: %w", event.ID, err)
+			}
+			changes[transfer.FromAccountID] -= transfer.Amount + transfer.Fee
+			changes[transfer.ToAccountID] += transfer.Amount
+			if transfer.Fee > 0 {
+				changes[transfer.FeeAccountID] += transfer.Fee
+			}
+		case EventTransferFlagged:
+			// a flag only marks a transfer for review and moves no money
+		default:
+			return nil, fmt.Errorf("cannot replay event %d of unknown type %q", event.ID, event.EventType)
+		}
+	}
+	return changes, nil
+}
@@ -0,0 +1,117 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.13.0
+// source: event.sql
+
+package db
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/lib/pq"
+)
+
+const createEvent = `-- name: CreateEvent :one
+INSERT INTO events (
+  event_type, payload, account_ids
+) VALUES (
+  $1, $2, $3
+)
+RETURNING id, event_type, payload, account_ids, created_at
+`
+
+type CreateEventParams struct {
+	EventType  string          `json:"event_type"`
+	Payload    json.RawMessage `json:"payload"`
+	AccountIds []int64         `json:"account_ids"`
+}
+
+func (q *Queries) CreateEvent(ctx context.Context, arg CreateEventParams) (Event, error) {
+	row := q.db.QueryRowContext(ctx, createEvent, arg.EventType, arg.Payload, pq.Array(arg.AccountIds))
+	var i Event
+	err := row.Scan(
+		&i.ID,
+		&i.EventType,
+		&i.Payload,
+		pq.Array(&i.AccountIds),
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listEvents = `-- name: ListEvents :many
+SELECT id, event_type, payload, account_ids, created_at FROM events
+ORDER BY id
+LIMIT $1
+OFFSET $2
+`
+
+type ListEventsParams struct {
+	Limit  int32 `json:"limit"`
+	Offset int32 `json:"offset"`
+}
+
+func (q *Queries) ListEvents(ctx context.Context, arg ListEventsParams) ([]Event, error) {
+	rows, err := q.db.QueryContext(ctx, listEvents, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Event
+	for rows.Next() {
+		var i Event
+		if err := rows.Scan(
+			&i.ID,
+			&i.EventType,
+			&i.Payload,
+			pq.Array(&i.AccountIds),
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEventsByAccount = `-- name: ListEventsByAccount :many
+SELECT id, event_type, payload, account_ids, created_at FROM events
+WHERE account_ids @> ARRAY[$1::bigint]
+ORDER BY id
+`
+
+func (q *Queries) ListEventsByAccount(ctx context.Context, accountID int64) ([]Event, error) {
+	rows, err := q.db.QueryContext(ctx, listEventsByAccount, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Event
+	for rows.Next() {
+		var i Event
+		if err := rows.Scan(
+			&i.ID,
+			&i.EventType,
+			&i.Payload,
+			pq.Array(&i.AccountIds),
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
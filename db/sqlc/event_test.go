@@ -0,0 +1,123 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/khuongkd/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferTxEvent(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createTestAccountFor(t, util.RandomOwner(), "USD")
+	account2 := createTestAccountFor(t, util.RandomOwner(), "USD")
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	events, err := store.ListEventsByAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, EventTransferCreated, events[0].EventType)
+	require.Equal(t, []int64{account1.ID, account2.ID}, events[0].AccountIds)
+
+	var payload TransferEvent
+	require.NoError(t, json.Unmarshal(events[0].Payload, &payload))
+	require.Equal(t, TransferEvent{
+		TransferID:    result.Transfer.ID,
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	}, payload)
+}
+
+func TestTransferTxFlagged(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createTestAccountFor(t, util.RandomOwner(), "USD")
+	account2 := createTestAccountFor(t, util.RandomOwner(), "USD")
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		FlagReason:    "velocity",
+	})
+	require.NoError(t, err)
+
+	events, err := store.ListEventsByAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	require.Equal(t, EventTransferCreated, events[0].EventType)
+	require.Equal(t, EventTransferFlagged, events[1].EventType)
+	require.Equal(t, []int64{account1.ID}, events[1].AccountIds)
+
+	var payload TransferFlaggedEvent
+	require.NoError(t, json.Unmarshal(events[1].Payload, &payload))
+	require.Equal(t, TransferFlaggedEvent{
+		TransferID:    result.Transfer.ID,
+		FromAccountID: account1.ID,
+		Reason:        "velocity",
+	}, payload)
+}
+
+func TestReplayEventsRebuildsBalances(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createTestAccountFor(t, util.RandomOwner(), "USD")
+	account2 := createTestAccountFor(t, util.RandomOwner(), "USD")
+
+	for i := 0; i < 3; i++ {
+		_, err := store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        10,
+		})
+		require.NoError(t, err)
+	}
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account2.ID,
+		ToAccountID:   account1.ID,
+		Amount:        5,
+	})
+	require.NoError(t, err)
+
+	events, err := store.ListEventsByAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	changes, err := ReplayEvents(events)
+	require.NoError(t, err)
+
+	for _, account := range []Account{account1, account2} {
+		current, err := store.GetAccount(context.Background(), account.ID)
+		require.NoError(t, err)
+		require.Equal(t, current.Balance, account.Balance+changes[account.ID])
+	}
+}
+
+func TestReplayEvents(t *testing.T) {
+	transfer, err := NewTransferEvent(Transfer{ID: 1, FromAccountID: 1, ToAccountID: 2, Amount: 100}, 0)
+	require.NoError(t, err)
+	withFee, err := NewTransferEvent(Transfer{ID: 2, FromAccountID: 2, ToAccountID: 1, Amount: 40, Fee: 5}, 3)
+	require.NoError(t, err)
+	require.Equal(t, []int64{2, 1, 3}, withFee.AccountIds)
+
+	flagged, err := NewTransferFlaggedEvent(Transfer{ID: 2, FromAccountID: 2, ToAccountID: 1, Amount: 40}, "velocity")
+	require.NoError(t, err)
+	require.Equal(t, []int64{2}, flagged.AccountIds)
+
+	events := []Event{
+		{ID: 1, EventType: transfer.EventType, Payload: transfer.Payload, AccountIds: transfer.AccountIds},
+		{ID: 2, EventType: withFee.EventType, Payload: withFee.Payload, AccountIds: withFee.AccountIds},
+		{ID: 3, EventType: flagged.EventType, Payload: flagged.Payload, AccountIds: flagged.AccountIds},
+	}
+	changes, err := ReplayEvents(events)
+	require.NoError(t, err)
+	require.Equal(t, map[int64]int64{1: -60, 2: 55, 3: 5}, changes)
+
+	_, err = ReplayEvents([]Event{{ID: 3, EventType: "account.closed", Payload: []byte("{}")}})
+	require.Error(t, err)
+}
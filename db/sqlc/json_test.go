@@ -0,0 +1,85 @@
+package db
+
+import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// TestJSONShape pins the JSON field names clients see, so renaming a column or
+// a struct field cannot silently change the API
+func TestJSONShape(t *testing.T) {
+	createdAt := time.Date(2022, time.May, 1, 12, 30, 0, 0, time.UTC)
+	account1 := Account{ID: 1, Owner: "alice", Balance: 1000, Currency: "USD", CreatedAt: createdAt, AccountType: AccountTypeChecking, Metadata: json.RawMessage(`{}`)}
+	account2 := Account{ID: 2, Owner: "bob", Balance: 500, Currency: "USD", CreatedAt: createdAt, AccountType: AccountTypeChecking, Metadata: json.RawMessage(`{}`)}
+	transfer := Transfer{ID: 1, FromAccountID: 1, ToAccountID: 2, Amount: 10, CreatedAt: createdAt, Currency: "USD"}
+	fromEntry := Entry{ID: 1, AccountID: 1, Amount: -10, CreatedAt: createdAt}
+	toEntry := Entry{ID: 2, AccountID: 2, Amount: 10, CreatedAt: createdAt}
+
+	testCases := []struct {
+		name  string
+		value interface{}
+		keys  []string
+	}{
+		{
+			name:  "account",
+			value: account1,
+			keys:  []string{"account_type", "balance", "created_at", "currency", "freeze_reason", "frozen", "held_balance", "id", "metadata", "min_balance", "nickname", "number", "owner", "whitelist_enabled"},
+		},
+		{
+			name:  "entry",
+			value: fromEntry,
+			keys:  []string{"account_id", "amount", "created_at", "id"},
+		},
+		{
+			name:  "transfer",
+			value: transfer,
+			keys:  []string{"amount", "category", "created_at", "currency", "external_ref", "fee", "from_account_id", "from_account_number", "id", "to_account_id", "to_account_number"},
+		},
+		{
+			name: "transfer_tx_result",
+			value: TransferTxResult{
+				Transfer:    transfer,
+				FromAccount: account1,
+				ToAccount:   account2,
+				FromEntry:   fromEntry,
+				ToEntry:     toEntry,
+			},
+			keys: []string{"from_account", "from_entry", "to_account", "to_entry", "transfer"},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := json.MarshalIndent(tc.value, "", "  ")
+			require.NoError(t, err)
+
+			var fields map[string]json.RawMessage
+			require.NoError(t, json.Unmarshal(data, &fields))
+			keys := make([]string, 0, len(fields))
+			for key := range fields {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			require.Equal(t, tc.keys, keys)
+
+			golden := filepath.Join("testdata", tc.name+".golden.json")
+			if *updateGolden {
+				require.NoError(t, ioutil.WriteFile(golden, data, 0644))
+			}
+			want, err := ioutil.ReadFile(golden)
+			require.NoError(t, err)
+			require.JSONEq(t, string(want), string(data))
+		})
+	}
+}
@@ -5,6 +5,8 @@
 package db
 
 import (
+	"database/sql"
+	"encoding/json"
 	"time"
 )
 
@@ -14,6 +16,73 @@ type Account struct {
 	Balance   int64     `json:"balance"`
 	Currency  string    `json:"currency"`
 	CreatedAt time.Time `json:"created_at"`
+	// outgoing transfers only go to destinations in account_whitelist
+	WhitelistEnabled bool `json:"whitelist_enabled"`
+	// part of the balance reserved by authorized holds
+	HeldBalance int64 `json:"held_balance"`
+	// unique within the currency; unique across currencies when taken from account_numbers
+	Number int64 `json:"number"`
+	// checking or savings; selects the policy applied to transfers out of the account
+	AccountType string `json:"account_type"`
+	// outgoing transfers cannot take the balance below it
+	MinBalance int64   `json:"min_balance"`
+	Nickname   *string `json:"nickname"`
+	// free-form JSON object kept for the owner
+	Metadata json.RawMessage `json:"metadata"`
+	// a frozen account takes part in no transfer
+	Frozen bool `json:"frozen"`
+	// why a frozen account was frozen, from the configured taxonomy; null while not frozen
+	FreezeReason *string `json:"freeze_reason"`
+}
+
+type AccountAdjustment struct {
+	ID        int64 `json:"id"`
+	AccountID int64 `json:"account_id"`
+	// the entry may have been moved to entries_archive, so it has no foreign key
+	EntryID    int64     `json:"entry_id"`
+	Amount     int64     `json:"amount"`
+	Reason     string    `json:"reason"`
+	AdjustedBy string    `json:"adjusted_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// append-only; written by the accounts_audit trigger, rows are never updated or deleted
+type AccountAuditLog struct {
+	ID int64 `json:"id"`
+	// the account may have been deleted, so it has no foreign key
+	AccountID int64 `json:"account_id"`
+	// insert, update or delete
+	Action string `json:"action"`
+	// the account row before the change; null for an insert
+	Before json.RawMessage `json:"before"`
+	// the account row after the change; null for a delete
+	After json.RawMessage `json:"after"`
+	// simplebank.actor as set by the transaction, or the database user
+	Actor     string    `json:"actor"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// money an account holds in currencies other than its own, which stays in accounts.balance
+type AccountSubBalance struct {
+	AccountID int64     `json:"account_id"`
+	Currency  string    `json:"currency"`
+	Balance   int64     `json:"balance"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type AccountWhitelist struct {
+	AccountID            int64     `json:"account_id"`
+	DestinationAccountID int64     `json:"destination_account_id"`
+	CreatedAt            time.Time `json:"created_at"`
+}
+
+// entries older than the retention period, moved out of entries
+type EntriesArchive struct {
+	ID         int64     `json:"id"`
+	AccountID  int64     `json:"account_id"`
+	Amount     int64     `json:"amount"`
+	CreatedAt  time.Time `json:"created_at"`
+	ArchivedAt time.Time `json:"archived_at"`
 }
 
 type Entry struct {
@@ -24,6 +93,50 @@ type Entry struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// append-only; rows are never updated or deleted
+type Event struct {
+	ID        int64           `json:"id"`
+	EventType string          `json:"event_type"`
+	Payload   json.RawMessage `json:"payload"`
+	// accounts whose balance the event changed
+	AccountIds []int64   `json:"account_ids"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type OwnershipTransferRequest struct {
+	ID           int64  `json:"id"`
+	AccountID    int64  `json:"account_id"`
+	CurrentOwner string `json:"current_owner"`
+	NewOwner     string `json:"new_owner"`
+	// pending, accepted or expired
+	Status    string    `json:"status"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ScheduledTransfer struct {
+	ID            int64     `json:"id"`
+	FromAccountID int64     `json:"from_account_id"`
+	ToAccountID   int64     `json:"to_account_id"`
+	Amount        int64     `json:"amount"`
+	Currency      string    `json:"currency"`
+	RunAt         time.Time `json:"run_at"`
+	// pending, done, failed or canceled
+	Status        string        `json:"status"`
+	FailureReason string        `json:"failure_reason"`
+	TransferID    sql.NullInt64 `json:"transfer_id"`
+	CreatedAt     time.Time     `json:"created_at"`
+}
+
+type SubBalanceEntry struct {
+	ID        int64  `json:"id"`
+	AccountID int64  `json:"account_id"`
+	Currency  string `json:"currency"`
+	// can be negative or positive
+	Amount    int64     `json:"amount"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
 type Transfer struct {
 	ID            int64 `json:"id"`
 	FromAccountID int64 `json:"from_account_id"`
@@ -31,4 +144,44 @@ type Transfer struct {
 	// must be positive
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	// charged to the source account on top of the amount
+	Fee int64 `json:"fee"`
+	// reference given by the integrator, unique when set
+	ExternalRef *string `json:"external_ref"`
+	// spending category chosen by the sender, such as groceries or rent
+	Category *string `json:"category"`
+	// currency of the accounts when the transfer was made, which a later conversion does not change
+	Currency string `json:"currency"`
+	// number of the source account when the transfer was made
+	FromAccountNumber int64 `json:"from_account_number"`
+	// number of the destination account when the transfer was made
+	ToAccountNumber int64 `json:"to_account_number"`
+}
+
+type TransferConfirmation struct {
+	ID            int64  `json:"id"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	Currency      string `json:"currency"`
+	ExternalRef   string `json:"external_ref"`
+	Category      string `json:"category"`
+	// hex SHA-256 of the confirmation token; the token itself is only given to the client
+	TokenHash string `json:"token_hash"`
+	// pending_confirmation or confirmed
+	Status     string        `json:"status"`
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	ExpiresAt  time.Time     `json:"expires_at"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+type TransferHold struct {
+	ID            int64 `json:"id"`
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID   int64 `json:"to_account_id"`
+	Amount        int64 `json:"amount"`
+	// authorized, captured or voided
+	Status     string        `json:"status"`
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	CreatedAt  time.Time     `json:"created_at"`
 }
@@ -0,0 +1,128 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// QueryObserver is told about every query and transaction a store runs, for metrics and tracing.
+// The method is the name of the Querier or Store method, such as GetAccount or TransferTx;
+// the queries a transaction runs are reported on their own as well.
+type QueryObserver interface {
+	// BeforeQuery is called before the operation starts. The context it returns is the one the operation runs with,
+	// and the one AfterQuery is called with.
+	BeforeQuery(ctx context.Context, method string) context.Context
+	// AfterQuery is called once the operation is done, with how long it took and the error it failed with
+	AfterQuery(ctx context.Context, method string, duration time.Duration, err error)
+}
+
+// QueryObservers tells several observers about each operation,
+// calling them in order before it and in reverse order after it
+type QueryObservers []QueryObserver
+
+func (observers QueryObservers) BeforeQuery(ctx context.Context, method string) context.Context {
+	for _, observer := range observers {
+		ctx = observer.BeforeQuery(ctx, method)
+	}
+	return ctx
+}
+
+func (observers QueryObservers) AfterQuery(ctx context.Context, method string, duration time.Duration, err error) {
+	for i := len(observers) - 1; i >= 0; i-- {
+		observers[i].AfterQuery(ctx, method, duration, err)
+	}
+}
+
+// observe tells the observer that the operation starts and returns the context to run it with
+// and the function to call with its error once it is done
+func observe(ctx context.Context, observer QueryObserver, method string) (context.Context, func(error)) {
+	ctx = observer.BeforeQuery(ctx, method)
+	start := time.Now()
+	return ctx, func(err error) {
+		observer.AfterQuery(ctx, method, time.Since(start), err)
+	}
+}
+
+// observedDB reports the sqlc queries run on a DBTX to an observer.
+// Statements without a sqlc name, such as savepoints, are internal to the store and are not reported.
+type observedDB struct {
+	DBTX
+	observer QueryObserver
+	// scope is the context of the transaction the queries run in, whose values they are observed with,
+	// so that an observer can tell which transaction a query belongs to
+	scope context.Context
+}
+
+// scopedContext is a context whose values are those of another one
+type scopedContext struct {
+	context.Context
+	values context.Context
+}
+
+func (ctx scopedContext) Value(key interface{}) interface{} {
+	return ctx.values.Value(key)
+}
+
+// observe tells the observer of the database that the query starts
+func (db observedDB) observe(ctx context.Context, method string) (context.Context, func(error)) {
+	if db.scope != nil {
+		ctx = scopedContext{Context: ctx, values: db.scope}
+	}
+	return observe(ctx, db.observer, method)
+}
+
+// observedMethod returns the sqlc name of the query, which is the name of its Querier method
+func observedMethod(query string) (string, bool) {
+	if !strings.HasPrefix(query, "-- name: ") {
+		return "", false
+	}
+	return queryName(query), true
+}
+
+// queryName returns the sqlc name of the query for error messages
+func queryName(query string) string {
+	const prefix = "-- name: "
+	if !strings.HasPrefix(query, prefix) {
+		return "query"
+	}
+	name := strings.Fields(query[len(prefix):])
+	if len(name) == 0 {
+		return "query"
+	}
+	return name[0]
+}
+
+func (db observedDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	method, ok := observedMethod(query)
+	if !ok {
+		return db.DBTX.ExecContext(ctx, query, args...)
+	}
+	ctx, done := db.observe(ctx, method)
+	result, err := db.DBTX.ExecContext(ctx, query, args...)
+	done(err)
+	return result, err
+}
+
+func (db observedDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	method, ok := observedMethod(query)
+	if !ok {
+		return db.DBTX.QueryContext(ctx, query, args...)
+	}
+	ctx, done := db.observe(ctx, method)
+	rows, err := db.DBTX.QueryContext(ctx, query, args...)
+	done(err)
+	return rows, err
+}
+
+func (db observedDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	method, ok := observedMethod(query)
+	if !ok {
+		return db.DBTX.QueryRowContext(ctx, query, args...)
+	}
+	ctx, done := db.observe(ctx, method)
+	row := db.DBTX.QueryRowContext(ctx, query, args...)
+	done(row.Err())
+	return row
+}
@@ -0,0 +1,159 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// observation is an operation a recordingObserver was told about
+type observation struct {
+	method string
+	parent string
+	err    error
+}
+
+type (
+	observedMethodKey struct{}
+	observedParentKey struct{}
+)
+
+// recordingObserver records the operations it is told about, along with the operation they ran within
+type recordingObserver struct {
+	mu           sync.Mutex
+	observations []observation
+}
+
+func (observer *recordingObserver) BeforeQuery(ctx context.Context, method string) context.Context {
+	parent, _ := ctx.Value(observedMethodKey{}).(string)
+	return context.WithValue(context.WithValue(ctx, observedParentKey{}, parent), observedMethodKey{}, method)
+}
+
+func (observer *recordingObserver) AfterQuery(ctx context.Context, method string, duration time.Duration, err error) {
+	parent, _ := ctx.Value(observedParentKey{}).(string)
+	observer.mu.Lock()
+	defer observer.mu.Unlock()
+	observer.observations = append(observer.observations, observation{method: method, parent: parent, err: err})
+}
+
+func (observer *recordingObserver) methods() []string {
+	observer.mu.Lock()
+	defer observer.mu.Unlock()
+	var methods []string
+	for _, o := range observer.observations {
+		methods = append(methods, o.method)
+	}
+	return methods
+}
+
+// failingDB fails every query it is asked to run
+type failingDB struct {
+	DBTX
+}
+
+var errQueryFailed = errors.New("query failed")
+
+func (failingDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return nil, errQueryFailed
+}
+
+func (failingDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return nil, errQueryFailed
+}
+
+func TestObservedDB(t *testing.T) {
+	observer := &recordingObserver{}
+	db := observedDB{DBTX: failingDB{}, observer: observer}
+	q := New(db)
+
+	_, err := q.ListAccounts(context.Background(), ListAccountsParams{Limit: 5})
+	require.ErrorIs(t, err, errQueryFailed)
+	err = q.DeleteAccount(context.Background(), 3)
+	require.ErrorIs(t, err, errQueryFailed)
+
+	// statements without a sqlc name are not reported
+	_, err = db.ExecContext(context.Background(), "SAVEPOINT test")
+	require.ErrorIs(t, err, errQueryFailed)
+
+	require.Equal(t, []observation{
+		{method: "ListAccounts", err: errQueryFailed},
+		{method: "DeleteAccount", err: errQueryFailed},
+	}, observer.observations)
+}
+
+func TestObservedDBScope(t *testing.T) {
+	observer := &recordingObserver{}
+	scope := observer.BeforeQuery(context.Background(), "TransferTx")
+	q := New(observedDB{DBTX: failingDB{}, observer: observer, scope: scope})
+
+	_, err := q.ListAccounts(context.Background(), ListAccountsParams{Limit: 5})
+	require.ErrorIs(t, err, errQueryFailed)
+	require.Equal(t, []observation{{method: "ListAccounts", parent: "TransferTx", err: errQueryFailed}}, observer.observations)
+}
+
+// orderObserver appends its name to calls before and after each operation
+type orderObserver struct {
+	name  string
+	calls *[]string
+}
+
+func (observer orderObserver) BeforeQuery(ctx context.Context, method string) context.Context {
+	*observer.calls = append(*observer.calls, "before "+observer.name)
+	return ctx
+}
+
+func (observer orderObserver) AfterQuery(ctx context.Context, method string, duration time.Duration, err error) {
+	*observer.calls = append(*observer.calls, "after "+observer.name)
+}
+
+func TestQueryObserversOrder(t *testing.T) {
+	var calls []string
+	observers := QueryObservers{orderObserver{"a", &calls}, orderObserver{"b", &calls}}
+
+	ctx, done := observe(context.Background(), observers, "GetAccount")
+	require.NotNil(t, ctx)
+	done(nil)
+	require.Equal(t, []string{"before a", "before b", "after b", "after a"}, calls)
+}
+
+func TestStoreQueryObserver(t *testing.T) {
+	observer := &recordingObserver{}
+	store := NewStoreWithDialect(testDB, Postgres, observer)
+	account1 := fundTestAccount(t, createTestAccount(t), 100)
+	account2 := createTestAccount(t)
+
+	_, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, []string{"GetAccount"}, observer.methods())
+
+	observer.observations = nil
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	methods := observer.methods()
+	require.Equal(t, "TransferTx", methods[len(methods)-1])
+	require.NoError(t, observer.observations[len(methods)-1].err)
+	for _, o := range observer.observations[:len(methods)-1] {
+		require.Equal(t, "TransferTx", o.parent, o.method)
+	}
+	require.Contains(t, methods, "CreateTransfer")
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account2.ID,
+		ToAccountID:   account1.ID,
+		Amount:        1000,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+	last := observer.observations[len(observer.observations)-1]
+	require.Equal(t, "TransferTx", last.method)
+	require.ErrorIs(t, last.err, ErrInsufficientFunds)
+}
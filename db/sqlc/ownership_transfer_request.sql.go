@@ -0,0 +1,122 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.13.0
+// source: ownership_transfer_request.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const acceptOwnershipTransferRequest = `-- name: AcceptOwnershipTransferRequest :one
+UPDATE ownership_transfer_requests SET status = 'accepted'
+WHERE id = $1 AND status = 'pending'
+RETURNING id, account_id, current_owner, new_owner, status, expires_at, created_at
+`
+
+func (q *Queries) AcceptOwnershipTransferRequest(ctx context.Context, id int64) (OwnershipTransferRequest, error) {
+	row := q.db.QueryRowContext(ctx, acceptOwnershipTransferRequest, id)
+	var i OwnershipTransferRequest
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.CurrentOwner,
+		&i.NewOwner,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createOwnershipTransferRequest = `-- name: CreateOwnershipTransferRequest :one
+INSERT INTO ownership_transfer_requests (
+  account_id, current_owner, new_owner, expires_at
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING id, account_id, current_owner, new_owner, status, expires_at, created_at
+`
+
+type CreateOwnershipTransferRequestParams struct {
+	AccountID    int64     `json:"account_id"`
+	CurrentOwner string    `json:"current_owner"`
+	NewOwner     string    `json:"new_owner"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateOwnershipTransferRequest(ctx context.Context, arg CreateOwnershipTransferRequestParams) (OwnershipTransferRequest, error) {
+	row := q.db.QueryRowContext(ctx, createOwnershipTransferRequest,
+		arg.AccountID,
+		arg.CurrentOwner,
+		arg.NewOwner,
+		arg.ExpiresAt,
+	)
+	var i OwnershipTransferRequest
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.CurrentOwner,
+		&i.NewOwner,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const expireOwnershipTransferRequests = `-- name: ExpireOwnershipTransferRequests :execrows
+UPDATE ownership_transfer_requests SET status = 'expired'
+WHERE status = 'pending' AND expires_at <= $1
+`
+
+func (q *Queries) ExpireOwnershipTransferRequests(ctx context.Context, now time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, expireOwnershipTransferRequests, now)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getOwnershipTransferRequest = `-- name: GetOwnershipTransferRequest :one
+SELECT id, account_id, current_owner, new_owner, status, expires_at, created_at FROM ownership_transfer_requests
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetOwnershipTransferRequest(ctx context.Context, id int64) (OwnershipTransferRequest, error) {
+	row := q.db.QueryRowContext(ctx, getOwnershipTransferRequest, id)
+	var i OwnershipTransferRequest
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.CurrentOwner,
+		&i.NewOwner,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getOwnershipTransferRequestForUpdate = `-- name: GetOwnershipTransferRequestForUpdate :one
+SELECT id, account_id, current_owner, new_owner, status, expires_at, created_at FROM ownership_transfer_requests
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetOwnershipTransferRequestForUpdate(ctx context.Context, id int64) (OwnershipTransferRequest, error) {
+	row := q.db.QueryRowContext(ctx, getOwnershipTransferRequestForUpdate, id)
+	var i OwnershipTransferRequest
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.CurrentOwner,
+		&i.NewOwner,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
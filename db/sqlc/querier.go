@@ -6,23 +6,120 @@ package db
 
 import (
 	"context"
+	"time"
 )
 
 type Querier interface {
+	AcceptOwnershipTransferRequest(ctx context.Context, id int64) (OwnershipTransferRequest, error)
 	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
+	AddAccountHeldBalance(ctx context.Context, arg AddAccountHeldBalanceParams) (Account, error)
+	// Credits or debits the sub-balance, opening it on the first credit.
+	AddSubBalance(ctx context.Context, arg AddSubBalanceParams) (AccountSubBalance, error)
+	AddWhitelistedDestination(ctx context.Context, arg AddWhitelistedDestinationParams) error
+	ArchiveEntries(ctx context.Context, arg ArchiveEntriesParams) (int64, error)
+	CancelScheduledTransfer(ctx context.Context, id int64) (ScheduledTransfer, error)
+	CompleteScheduledTransfer(ctx context.Context, arg CompleteScheduledTransferParams) (ScheduledTransfer, error)
+	CompleteTransferConfirmation(ctx context.Context, arg CompleteTransferConfirmationParams) (TransferConfirmation, error)
+	CountAccountsByOwnerSince(ctx context.Context, arg CountAccountsByOwnerSinceParams) (int64, error)
+	CountAuthorizedHoldsByAccount(ctx context.Context, accountID int64) (int64, error)
+	CountPendingScheduledTransfersByAccount(ctx context.Context, accountID int64) (int64, error)
+	CountPendingTransferConfirmationsByAccount(ctx context.Context, arg CountPendingTransferConfirmationsByAccountParams) (int64, error)
+	CountRecentTransfersFromAccount(ctx context.Context, arg CountRecentTransfersFromAccountParams) (int64, error)
+	// Counts the entries of an account, archived ones included, created in [from_time, to_time).
+	CountStatementEntries(ctx context.Context, arg CountStatementEntriesParams) (int64, error)
+	CreateAccountAdjustment(ctx context.Context, arg CreateAccountAdjustmentParams) (AccountAdjustment, error)
+	// With number_per_currency set the account takes the next number of its currency; concurrent inserts may
+	// pick the same number, which the unique index rejects. Otherwise the number comes from the account_numbers
+	// sequence, so it is unique across currencies, which the per-currency index alone cannot guarantee.
+	// An empty account_type creates a checking account.
 	CreateAcount(ctx context.Context, arg CreateAcountParams) (Account, error)
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateEvent(ctx context.Context, arg CreateEventParams) (Event, error)
+	CreateOwnershipTransferRequest(ctx context.Context, arg CreateOwnershipTransferRequestParams) (OwnershipTransferRequest, error)
+	CreateScheduledTransfer(ctx context.Context, arg CreateScheduledTransferParams) (ScheduledTransfer, error)
+	CreateSubBalanceEntry(ctx context.Context, arg CreateSubBalanceEntryParams) (SubBalanceEntry, error)
+	// The currency and account numbers are copied from the accounts as they are now,
+	// so that receipts keep showing them after an account is converted or renumbered.
 	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
+	CreateTransferConfirmation(ctx context.Context, arg CreateTransferConfirmationParams) (TransferConfirmation, error)
+	CreateTransferHold(ctx context.Context, arg CreateTransferHoldParams) (TransferHold, error)
 	DeleteAccount(ctx context.Context, id int64) error
 	DeleteEntry(ctx context.Context, id int64) error
 	DeleteTransfer(ctx context.Context, id int64) error
+	ExpireOwnershipTransferRequests(ctx context.Context, now time.Time) (int64, error)
+	FailScheduledTransfer(ctx context.Context, arg FailScheduledTransferParams) (ScheduledTransfer, error)
+	// Lists the accounts whose balance differs from the sum of their entries, archived ones included, in a single pass over the entries.
+	FindBalanceDiscrepancies(ctx context.Context, arg FindBalanceDiscrepanciesParams) ([]FindBalanceDiscrepanciesRow, error)
+	// Lists every other account that sent money to or received money from the account, once each, in ID order.
+	// Direction is outgoing when the account only sent money to the counterparty, incoming when it only received from it,
+	// and both otherwise.
+	FindCounterpartyAccounts(ctx context.Context, arg FindCounterpartyAccountsParams) ([]FindCounterpartyAccountsRow, error)
 	GetAccount(ctx context.Context, id int64) (Account, error)
+	GetAccountByNumberAndCurrency(ctx context.Context, arg GetAccountByNumberAndCurrencyParams) (Account, error)
 	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
 	GetEntry(ctx context.Context, id int64) (Entry, error)
+	GetOwnershipTransferRequest(ctx context.Context, id int64) (OwnershipTransferRequest, error)
+	GetOwnershipTransferRequestForUpdate(ctx context.Context, id int64) (OwnershipTransferRequest, error)
+	GetScheduledTransfer(ctx context.Context, id int64) (ScheduledTransfer, error)
+	GetScheduledTransferForUpdate(ctx context.Context, id int64) (ScheduledTransfer, error)
+	GetSubBalanceForUpdate(ctx context.Context, arg GetSubBalanceForUpdateParams) (AccountSubBalance, error)
 	GetTransfer(ctx context.Context, id int64) (Transfer, error)
+	GetTransferConfirmation(ctx context.Context, id int64) (TransferConfirmation, error)
+	GetTransferConfirmationForUpdate(ctx context.Context, id int64) (TransferConfirmation, error)
+	GetTransferHold(ctx context.Context, id int64) (TransferHold, error)
+	GetTransferHoldForUpdate(ctx context.Context, id int64) (TransferHold, error)
+	// Returns the transfers among the IDs that move money out of or into an account of the owner, in ID order.
+	// IDs of transfers the owner is not party to, or that do not exist, are left out.
+	GetTransfersByIDs(ctx context.Context, arg GetTransfersByIDsParams) ([]Transfer, error)
+	IsDestinationWhitelisted(ctx context.Context, arg IsDestinationWhitelistedParams) (bool, error)
+	ListAccountAdjustments(ctx context.Context, accountID int64) ([]AccountAdjustment, error)
+	ListAccountAuditLog(ctx context.Context, arg ListAccountAuditLogParams) ([]AccountAuditLog, error)
 	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
+	ListAccountsAfter(ctx context.Context, arg ListAccountsAfterParams) ([]Account, error)
+	ListArchivedEntriesByAccount(ctx context.Context, accountID int64) ([]EntriesArchive, error)
+	// An account is dormant when it has no entry, archived or not, created at or after since.
+	ListDormantAccounts(ctx context.Context, arg ListDormantAccountsParams) ([]Account, error)
+	ListDueScheduledTransfers(ctx context.Context, arg ListDueScheduledTransfersParams) ([]ScheduledTransfer, error)
 	ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error)
+	// Lists the entries of an account, archived ones included, each with the sum of the amounts up to and including it.
+	// The sum runs over every entry before the page, so each page carries on from the previous one.
+	ListEntriesWithRunningBalance(ctx context.Context, arg ListEntriesWithRunningBalanceParams) ([]ListEntriesWithRunningBalanceRow, error)
+	ListEvents(ctx context.Context, arg ListEventsParams) ([]Event, error)
+	ListEventsByAccount(ctx context.Context, accountID int64) ([]Event, error)
+	// An empty reason lists every frozen account.
+	ListFrozenAccounts(ctx context.Context, arg ListFrozenAccountsParams) ([]Account, error)
+	ListScheduledTransfers(ctx context.Context, arg ListScheduledTransfersParams) ([]ScheduledTransfer, error)
+	// Lists the entries of an account, archived ones included, created in [from_time, to_time) with an id above after_id.
+	// Archived entries keep their id, so ordering by id pages through both tables as one.
+	ListStatementEntries(ctx context.Context, arg ListStatementEntriesParams) ([]Entry, error)
+	ListSubBalances(ctx context.Context, accountID int64) ([]AccountSubBalance, error)
 	ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error)
+	ListWhitelistedDestinations(ctx context.Context, accountID int64) ([]AccountWhitelist, error)
+	// Only the fields whose set_ flag is true change; the others keep their current value.
+	// An empty nickname removes it.
+	// The freeze reason changes along with frozen; unfreezing passes an empty one, which removes it.
+	// The flags stand in for NULL parameters, which sqlc cannot generate for NOT NULL columns.
+	PatchAccount(ctx context.Context, arg PatchAccountParams) (Account, error)
+	RecentSimilarTransferExists(ctx context.Context, arg RecentSimilarTransferExistsParams) (bool, error)
+	RemoveWhitelistedDestination(ctx context.Context, arg RemoveWhitelistedDestinationParams) error
+	// With number_per_currency set the account is renumbered, since its number may be taken in the new currency.
+	SetAccountCurrency(ctx context.Context, arg SetAccountCurrencyParams) (Account, error)
+	SetAccountMinBalance(ctx context.Context, arg SetAccountMinBalanceParams) (Account, error)
+	SetAccountOwner(ctx context.Context, arg SetAccountOwnerParams) (Account, error)
+	SetAccountWhitelistEnabled(ctx context.Context, arg SetAccountWhitelistEnabledParams) (Account, error)
+	SettleTransferHold(ctx context.Context, arg SettleTransferHoldParams) (TransferHold, error)
+	// Sums the transfers out of an account within [from_time, to_time) per category, largest first.
+	// Transfers without a category are summed under an empty one.
+	SpendingByCategory(ctx context.Context, arg SpendingByCategoryParams) ([]SpendingByCategoryRow, error)
+	SumEntriesByAccount(ctx context.Context, accountID int64) (int64, error)
+	SumEntriesByAccountAsOf(ctx context.Context, arg SumEntriesByAccountAsOfParams) (int64, error)
+	SumTransferFeesByAccount(ctx context.Context, fromAccountID int64) (int64, error)
+	// Lists the accounts of a currency from the largest balance down; ties go to the oldest account.
+	TopAccountsByBalance(ctx context.Context, arg TopAccountsByBalanceParams) ([]Account, error)
+	// Sums the balances of all accounts per currency, next to the sum of their entries, archived ones included,
+	// so treasury can see how much money the bank holds and whether the ledger backs it.
+	// Sub-balances count towards their own currency, backed by their sub-balance entries; accounts counts the accounts held in the currency.
+	TotalSystemBalance(ctx context.Context) ([]TotalSystemBalanceRow, error)
 	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error)
 	UpdateEntry(ctx context.Context, arg UpdateEntryParams) (Entry, error)
 	UpdateTransfer(ctx context.Context, arg UpdateTransferParams) (Transfer, error)
@@ -0,0 +1,165 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/khuongkd/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createTestScheduledTransfer(t *testing.T, from, to Account, amount int64, runAt time.Time) ScheduledTransfer {
+	arg := CreateScheduledTransferParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        amount,
+		Currency:      from.Currency,
+		RunAt:         runAt,
+	}
+
+	scheduled, err := testQueries.CreateScheduledTransfer(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, scheduled.ID)
+	require.Equal(t, arg.Amount, scheduled.Amount)
+	require.WithinDuration(t, arg.RunAt, scheduled.RunAt, time.Second)
+	require.Equal(t, ScheduledTransferPending, scheduled.Status)
+	require.False(t, scheduled.TransferID.Valid)
+	return scheduled
+}
+
+func TestListAndCancelScheduledTransfers(t *testing.T) {
+	owner := util.RandomOwner()
+	from := createTestAccountFor(t, owner, "USD")
+	to := createTestAccountFor(t, owner, "USD")
+
+	now := time.Now()
+	later := createTestScheduledTransfer(t, from, to, 10, now.Add(2*time.Hour))
+	sooner := createTestScheduledTransfer(t, from, to, 10, now.Add(time.Hour))
+
+	scheduled, err := testQueries.ListScheduledTransfers(context.Background(), ListScheduledTransfersParams{
+		FromAccountID: from.ID,
+		Limit:         5,
+	})
+	require.NoError(t, err)
+	require.Len(t, scheduled, 2)
+	require.Equal(t, sooner.ID, scheduled[0].ID)
+	require.Equal(t, later.ID, scheduled[1].ID)
+
+	canceled, err := testQueries.CancelScheduledTransfer(context.Background(), sooner.ID)
+	require.NoError(t, err)
+	require.Equal(t, ScheduledTransferCanceled, canceled.Status)
+
+	// only pending transfers can be canceled
+	_, err = testQueries.CancelScheduledTransfer(context.Background(), sooner.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestExecuteScheduledTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+	owner := util.RandomOwner()
+	from := createTestAccountFor(t, owner, "USD")
+	to := createTestAccountFor(t, owner, "USD")
+	scheduled := createTestScheduledTransfer(t, from, to, from.Balance, time.Now().Add(-time.Minute))
+
+	due, err := store.ListDueScheduledTransfers(context.Background(), ListDueScheduledTransfersParams{
+		Now:      time.Now(),
+		MaxCount: 1000,
+	})
+	require.NoError(t, err)
+	require.Contains(t, due, scheduled)
+
+	done, err := store.ExecuteScheduledTransferTx(context.Background(), scheduled.ID)
+	require.NoError(t, err)
+	require.Equal(t, ScheduledTransferDone, done.Status)
+	require.True(t, done.TransferID.Valid)
+
+	updatedFrom, err := store.GetAccount(context.Background(), from.ID)
+	require.NoError(t, err)
+	require.Zero(t, updatedFrom.Balance)
+
+	_, err = store.ExecuteScheduledTransferTx(context.Background(), scheduled.ID)
+	require.ErrorIs(t, err, ErrScheduledTransferNotPending)
+}
+
+func TestExecuteScheduledTransferTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+	owner := util.RandomOwner()
+	from := createTestAccountFor(t, owner, "USD")
+	to := createTestAccountFor(t, owner, "USD")
+	scheduled := createTestScheduledTransfer(t, from, to, from.Balance+1, time.Now().Add(-time.Minute))
+
+	_, err := store.ExecuteScheduledTransferTx(context.Background(), scheduled.ID)
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	failed, err := store.FailScheduledTransfer(context.Background(), FailScheduledTransferParams{
+		ID:            scheduled.ID,
+		FailureReason: err.Error(),
+	})
+	require.NoError(t, err)
+	require.Equal(t, ScheduledTransferFailed, failed.Status)
+	require.Equal(t, ErrInsufficientFunds.Error(), failed.FailureReason)
+
+	updatedFrom, err := store.GetAccount(context.Background(), from.ID)
+	require.NoError(t, err)
+	require.Equal(t, from.Balance, updatedFrom.Balance)
+}
+
+func TestExecuteScheduledTransferTxFee(t *testing.T) {
+	store := NewStoreWithDialect(testDB, Postgres)
+	from := createTestAccountFor(t, util.RandomOwner(), "USD")
+	to := createTestAccountFor(t, util.RandomOwner(), "USD")
+	feeAccount := createTestAccountFor(t, util.RandomOwner(), "USD")
+	store.UseTransferFees(func(amount int64, currency string) (int64, int64, error) {
+		return util.Config{TransferFeeFlat: 2, TransferFeeAccounts: map[string]int64{"USD": feeAccount.ID}}.TransferFee(amount, currency)
+	})
+
+	scheduled := createTestScheduledTransfer(t, from, to, 10, time.Now().Add(-time.Minute))
+	done, err := store.ExecuteScheduledTransferTx(context.Background(), scheduled.ID)
+	require.NoError(t, err)
+
+	transfer, err := store.GetTransfer(context.Background(), done.TransferID.Int64)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), transfer.Fee)
+
+	updatedFrom, err := store.GetAccount(context.Background(), from.ID)
+	require.NoError(t, err)
+	require.Equal(t, from.Balance-12, updatedFrom.Balance)
+	updatedFeeAccount, err := store.GetAccount(context.Background(), feeAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, feeAccount.Balance+2, updatedFeeAccount.Balance)
+}
+
+func TestExecuteScheduledTransferTxAccounts(t *testing.T) {
+	store := NewStore(testDB)
+	from := createTestAccountFor(t, util.RandomOwner(), "USD")
+	to := createTestAccountFor(t, util.RandomOwner(), "USD")
+
+	frozen := createTestScheduledTransfer(t, from, to, 10, time.Now().Add(-time.Minute))
+	_, err := store.PatchAccount(context.Background(), PatchAccountParams{ID: to.ID, SetFrozen: true, Frozen: true, FreezeReason: "kyc"})
+	require.NoError(t, err)
+	_, err = store.ExecuteScheduledTransferTx(context.Background(), frozen.ID)
+	require.ErrorIs(t, err, ErrAccountFrozen)
+
+	_, err = store.PatchAccount(context.Background(), PatchAccountParams{ID: to.ID, SetFrozen: true, Frozen: false})
+	require.NoError(t, err)
+	_, err = store.CancelScheduledTransfer(context.Background(), frozen.ID)
+	require.NoError(t, err)
+	_, err = store.ConvertAccountCurrencyTx(context.Background(), ConvertAccountCurrencyTxParams{
+		AccountID: to.ID,
+		Currency:  "EUR",
+		Rate:      big.NewRat(9, 10),
+	})
+	require.NoError(t, err)
+
+	// a transfer scheduled in dollars must not credit the account now held in euros
+	mismatched := createTestScheduledTransfer(t, from, to, 10, time.Now().Add(-time.Minute))
+	_, err = store.ExecuteScheduledTransferTx(context.Background(), mismatched.ID)
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	updatedFrom, err := store.GetAccount(context.Background(), from.ID)
+	require.NoError(t, err)
+	require.Equal(t, from.Balance, updatedFrom.Balance)
+}
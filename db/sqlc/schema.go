@@ -0,0 +1,33 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+// SchemaVersion is the migration the code expects the database to be at.
+// It must be bumped along with every new migration in db/migration.
+const SchemaVersion = 22
+
+// Ping checks the database can be reached
+func (store *SQLStore) Ping(ctx context.Context) error {
+	return store.db.PingContext(ctx)
+}
+
+// CheckSchema checks the migrations applied by migrate are current and did not fail halfway
+func (store *SQLStore) CheckSchema(ctx context.Context) error {
+	var version int64
+	var dirty bool
+	err := store.db.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations").Scan(&version, &dirty)
+	if err != nil {
+		return fmt.Errorf("cannot read the applied migration: %w", err)
+	}
+
+	if dirty {
+		return fmt.Errorf("migration %d failed halfway and must be fixed by hand", version)
+	}
+	if version != SchemaVersion {
+		return fmt.Errorf("database is at migration %d, expected %d", version, SchemaVersion)
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package db
+
+import (
+	"context"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaVersionMatchesMigrations(t *testing.T) {
+	files, err := ioutil.ReadDir("../migration")
+	require.NoError(t, err)
+
+	var latest int64
+	for _, file := range files {
+		prefix := strings.SplitN(file.Name(), "_", 2)[0]
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		require.NoError(t, err, file.Name())
+		if version > latest {
+			latest = version
+		}
+	}
+
+	require.Equal(t, latest, int64(SchemaVersion))
+}
+
+func TestCheckSchema(t *testing.T) {
+	store := NewStore(testDB)
+	require.NoError(t, store.Ping(context.Background()))
+	require.NoError(t, store.CheckSchema(context.Background()))
+}
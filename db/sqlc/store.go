@@ -3,37 +3,177 @@ package db
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"sort"
+	"time"
+
+	"github.com/khuongkd/simplebank/util"
+)
+
+var (
+	ErrAccountOwnerMismatch = errors.New("account does not belong to the owner")
+	ErrCurrencyMismatch     = errors.New("accounts hold different currencies")
+	ErrNothingToSweep       = errors.New("source account has no funds to sweep")
+	ErrInsufficientFunds    = errors.New("insufficient funds")
+	ErrAccountFrozen        = errors.New("account is frozen")
+
+	ErrTransferLimitExceeded = errors.New("transfer amount exceeds the limit of the account type")
+	ErrBelowMinimumBalance   = errors.New("transfer would take the balance below the minimum of the account type")
+	ErrMinBalanceViolation   = errors.New("transfer would take the balance below the minimum balance of the account")
+
+	ErrDestinationNotWhitelisted = errors.New("destination account is not whitelisted for the source account")
+
+	ErrScheduledTransferNotPending = errors.New("scheduled transfer is no longer pending")
+)
+
+// Statuses of a scheduled transfer
+const (
+	ScheduledTransferPending  = "pending"
+	ScheduledTransferDone     = "done"
+	ScheduledTransferFailed   = "failed"
+	ScheduledTransferCanceled = "canceled"
+)
+
+// Types of an account
+const (
+	AccountTypeChecking = "checking"
+	AccountTypeSavings  = "savings"
+)
+
+// Directions money moved in between an account and a counterparty found by FindCounterpartyAccounts
+const (
+	CounterpartyOutgoing = "outgoing"
+	CounterpartyIncoming = "incoming"
+	CounterpartyBoth     = "both"
 )
 
 type Store interface {
 	Querier
-	TransferTx(ctx context.Context, params CreateTransferParams) (TransferTxResult, error)
+	TransferTx(ctx context.Context, params TransferTxParams) (TransferTxResult, error)
+	BatchTransferTx(ctx context.Context, params []TransferTxParams) ([]TransferTxResult, error)
+	SimulateTransfersTx(ctx context.Context, params []TransferTxParams) (TransferSimulation, error)
+	SweepOwnAccountsTx(ctx context.Context, fromAccountID, toAccountID int64, owner string) (TransferTxResult, error)
+	ExecuteScheduledTransferTx(ctx context.Context, id int64) (ScheduledTransfer, error)
+	AuthorizeTransferTx(ctx context.Context, params CreateTransferParams) (TransferHoldTxResult, error)
+	CaptureTransferTx(ctx context.Context, holdID int64) (TransferTxResult, error)
+	VoidTransferTx(ctx context.Context, holdID int64) (TransferHoldTxResult, error)
+	ReconcileAccount(ctx context.Context, accountID int64) (AccountReconciliation, error)
+	AccountBalanceAsOf(ctx context.Context, accountID int64, t time.Time) (int64, error)
+	ListEntriesWithBalance(ctx context.Context, accountID int64, limit, offset int32) ([]EntryWithBalance, error)
+	ConvertAccountCurrencyTx(ctx context.Context, params ConvertAccountCurrencyTxParams) (ConvertAccountCurrencyTxResult, error)
+	ConvertSubBalanceTx(ctx context.Context, params ConvertSubBalanceTxParams) (ConvertSubBalanceTxResult, error)
+	AcceptOwnershipTransferTx(ctx context.Context, requestID int64, owner string) (AcceptOwnershipTransferTxResult, error)
+	ConfirmTransferTx(ctx context.Context, id int64, params TransferTxParams) (TransferTxResult, error)
+	AdjustAccountBalanceTx(ctx context.Context, params AdjustAccountBalanceTxParams) (AdjustAccountBalanceTxResult, error)
+	StreamAllAccounts(ctx context.Context, fn func(Account) error) error
+	Stats() sql.DBStats
+	Ping(ctx context.Context) error
+	CheckSchema(ctx context.Context) error
 }
 
 // Store provides all functions to execute db queries and transactions
 type SQLStore struct {
 	*Queries
-	db *sql.DB
+	db       *sql.DB
+	dialect  Dialect
+	observer QueryObserver
+	policies func() util.AccountPolicies
+	fees     func(amount int64, currency string) (fee int64, feeAccountID int64, err error)
 }
 
+// NewStore returns a store running on Postgres
 func NewStore(db *sql.DB) Store {
-	return &SQLStore{
+	return NewStoreWithDialect(db, Postgres)
+}
+
+// NewStoreWithDialect returns a store running on the database of the dialect.
+// The observers are told about every query and transaction the store runs.
+func NewStoreWithDialect(db *sql.DB, dialect Dialect, observers ...QueryObserver) *SQLStore {
+	store := &SQLStore{
 		db:      db,
-		Queries: New(db),
+		dialect: dialect,
+	}
+	if len(observers) > 0 {
+		store.observer = QueryObservers(observers)
+	}
+	store.Queries = New(store.wrap(db, nil))
+	return store
+}
+
+// UseAccountPolicies makes every transfer the store performs follow the policy of the type of its source account.
+// policies is called for each transfer, so that a reloaded config applies right away.
+func (store *SQLStore) UseAccountPolicies(policies func() util.AccountPolicies) {
+	store.policies = policies
+}
+
+// accountPolicies returns the policies transfers follow, or nil when the store was given none
+func (store *SQLStore) accountPolicies() util.AccountPolicies {
+	if store.policies == nil {
+		return nil
+	}
+	return store.policies()
+}
+
+// UseTransferFees makes the transfers the store starts on its own, scheduled ones and captured holds, pay the fee fees returns.
+// Transfers made through TransferTx are charged the fee their params carry.
+func (store *SQLStore) UseTransferFees(fees func(amount int64, currency string) (fee int64, feeAccountID int64, err error)) {
+	store.fees = fees
+}
+
+// transferFee returns the fee of a transfer of amount in the currency and the account credited with it,
+// or no fee when the store was given none
+func (store *SQLStore) transferFee(amount int64, currency string) (fee int64, feeAccountID int64, err error) {
+	if store.fees == nil {
+		return 0, 0, nil
+	}
+	return store.fees(amount, currency)
+}
+
+// wrap reports the queries run on db to the observers of the store,
+// within the scope of the transaction they belong to if any
+func (store *SQLStore) wrap(db DBTX, scope context.Context) DBTX {
+	if store.observer != nil {
+		db = observedDB{DBTX: db, observer: store.observer, scope: scope}
 	}
+	return db
+}
+
+// Stats returns the connection pool statistics of the underlying database
+func (store *SQLStore) Stats() sql.DBStats {
+	return store.db.Stats()
 }
 
-// execTx executes a function within a database transaction
-func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) error {
+// execTx executes a function within a database transaction, reported to the observers as method
+func (store *SQLStore) execTx(ctx context.Context, method string, fn func(*Queries) error) (err error) {
+	if store.observer != nil {
+		var done func(error)
+		ctx, done = observe(ctx, store.observer, method)
+		defer func() {
+			// a simulation always rolls back on purpose
+			if err == errSimulationDone {
+				done(nil)
+				return
+			}
+			done(err)
+		}()
+	}
+
 	tx, err := store.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 
-	db := New(tx)
-	err = fn(db)
+	db := New(store.wrap(tx, ctx))
+	// the audit trigger only exists on Postgres
+	if actor, ok := ActorFrom(ctx); ok && store.dialect == Postgres {
+		err = setActor(ctx, tx, actor)
+	}
+	if err == nil {
+		err = fn(db)
+	}
 	if err != nil {
+		err = constraintError(err)
 		if errRb := tx.Rollback(); errRb != nil {
 			return fmt.Errorf("txErr: %v, rbErr: %v", err, errRb)
 		}
@@ -41,7 +181,25 @@ func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) erro
 		return err
 	}
 
-	return tx.Commit()
+	return constraintError(tx.Commit())
+}
+
+// TransferTxParams contains the input of a transfer.
+// A positive Fee is debited from the source account on top of the amount and credited to FeeAccountID.
+type TransferTxParams struct {
+	FromAccountID int64
+	ToAccountID   int64
+	Amount        int64
+	Fee           int64
+	FeeAccountID  int64
+	// FlagReason flags the transfer for review in the event log when set
+	FlagReason string
+	// ExternalRef is the integrator's own reference for the transfer, unique across transfers when set
+	ExternalRef string
+	// Category is the spending category the sender filed the transfer under, if any
+	Category string
+	// BalanceOrder is the order the balances of the accounts are updated in; nil updates them in account ID order
+	BalanceOrder BalanceOrder
 }
 
 type TransferTxResult struct {
@@ -50,93 +208,444 @@ type TransferTxResult struct {
 	ToAccount   Account  `json:"to_account"`
 	FromEntry   Entry    `json:"from_entry"`
 	ToEntry     Entry    `json:"to_entry"`
+	// FeeEntry is the entry debiting the fee from the source account, if a fee was charged
+	FeeEntry *Entry `json:"fee_entry,omitempty"`
 }
 
 // TransferTx performs a money transfer from one account to another account
 // It create a transfer record, add account entries, and update account's balance within a single database transaction
-func (store *SQLStore) TransferTx(ctx context.Context, params CreateTransferParams) (TransferTxResult, error) {
+// Transfers between two accounts of the same owner in the same currency are free of fees; every other check still applies.
+// It returns ErrAccountFrozen when either account is frozen,
+// ErrDestinationNotWhitelisted when the source account only allows whitelisted destinations,
+// ErrCurrencyMismatch when the fee account holds another currency than the source account,
+// ErrTransferLimitExceeded or ErrBelowMinimumBalance when the policy of the source account type rejects the transfer,
+// ErrMinBalanceViolation when the transfer would take the source account below its own minimum balance,
+// and ErrInsufficientFunds when the source account cannot cover the amount and the fee, held money excluded.
+// A transaction aborted to break a deadlock runs once more before a DeadlockError is returned.
+func (store *SQLStore) TransferTx(ctx context.Context, params TransferTxParams) (TransferTxResult, error) {
 	var result TransferTxResult
-	err := store.execTx(ctx, func(*Queries) error {
-		// create transfer
-		transfer, err := store.CreateTransfer(ctx, params)
-		if err != nil {
-			return err
+	fn := func(q *Queries) error {
+		var err error
+		result, err = store.transfer(ctx, q, params)
+		return err
+	}
+
+	err := store.execTx(ctx, "TransferTx", fn)
+	retried := false
+	if isDeadlock(err) {
+		DeadlockCount.Add(1)
+		retried = true
+		err = store.execTx(ctx, "TransferTx", fn)
+	}
+
+	return result, deadlockError(err, transferAccountIDs(params), retried)
+}
+
+// transferAccountIDs lists the accounts whose balances a transfer updates
+func transferAccountIDs(params TransferTxParams) []int64 {
+	ids := []int64{params.FromAccountID, params.ToAccountID}
+	if params.Fee > 0 {
+		ids = append(ids, params.FeeAccountID)
+	}
+	return ids
+}
+
+// transfer creates the transfer record and account entries and updates both balances using q,
+// which must run inside the caller's database transaction
+func (store *SQLStore) transfer(ctx context.Context, q *Queries, params TransferTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+	accounts, err := q.lockTransferAccounts(ctx, params)
+	if err != nil {
+		return result, err
+	}
+	fromAccount := accounts[params.FromAccountID]
+	if err := checkNotFrozen(fromAccount, accounts[params.ToAccountID]); err != nil {
+		return result, err
+	}
+	if isInternalTransfer(fromAccount, accounts[params.ToAccountID]) {
+		params.Fee = 0
+		params.FeeAccountID = 0
+	}
+
+	if err := q.checkWhitelisted(ctx, fromAccount, params.ToAccountID); err != nil {
+		return result, err
+	}
+	if params.Fee > 0 {
+		if err := checkFeeAccount(fromAccount, accounts[params.FeeAccountID]); err != nil {
+			return result, err
 		}
-		result.Transfer = transfer
+	}
+	policy := store.accountPolicies().For(fromAccount.AccountType)
+	if err := checkTransferLimit(policy, params.Amount); err != nil {
+		return result, err
+	}
+	if err := checkAvailableBalance(fromAccount, params.Amount+params.Fee); err != nil {
+		return result, err
+	}
 
-		// create fromEntry
-		fromEntry, err := store.CreateEntry(ctx, CreateEntryParams{
-			AccountID: transfer.FromAccountID,
-			Amount:    -transfer.Amount,
-		})
+	// create transfer
+	transfer, err := q.CreateTransfer(ctx, CreateTransferParams{
+		FromAccountID: params.FromAccountID,
+		ToAccountID:   params.ToAccountID,
+		Amount:        params.Amount,
+		Fee:           params.Fee,
+		ExternalRef:   externalRef(params.ExternalRef),
+		Category:      transferCategory(params.Category),
+	})
+	if err != nil {
+		return result, err
+	}
+	result.Transfer = transfer
+
+	// create fromEntry
+	fromEntry, err := q.CreateEntry(ctx, CreateEntryParams{
+		AccountID: transfer.FromAccountID,
+		Amount:    -transfer.Amount,
+	})
+	if err != nil {
+		return result, err
+	}
+	result.FromEntry = fromEntry
+
+	// create toEntry
+	toEntry, err := q.CreateEntry(ctx, CreateEntryParams{
+		AccountID: transfer.ToAccountID,
+		Amount:    transfer.Amount,
+	})
+	if err != nil {
+		return result, err
+	}
+	result.ToEntry = toEntry
+
+	if params.Fee == 0 {
+		result.FromAccount, result.ToAccount, err = q.AddAccountBalanceOrder(ctx, params.FromAccountID, params.ToAccountID, params.Amount, params.BalanceOrder)
 		if err != nil {
-			return err
+			return result, err
+		}
+	} else {
+		if err := q.chargeTransferFee(ctx, params, &result); err != nil {
+			return result, err
 		}
-		result.FromEntry = fromEntry
+	}
+	if err := checkMinBalance(policy, result.FromAccount.Balance); err != nil {
+		return result, err
+	}
+	if err := checkAccountMinBalance(result.FromAccount); err != nil {
+		return result, err
+	}
 
-		// create toEntry
-		toEntry, err := store.CreateEntry(ctx, CreateEntryParams{
-			AccountID: transfer.ToAccountID,
-			Amount:    transfer.Amount,
-		})
+	err = q.recordTransferEvents(ctx, result.Transfer, params)
+	return result, err
+}
+
+// chargeTransferFee debits the fee from the source account with its own pair of entries, so statements show it apart from the amount,
+// and updates the balances of every account the transfer touches
+func (q *Queries) chargeTransferFee(ctx context.Context, params TransferTxParams, result *TransferTxResult) error {
+	feeEntry, err := q.CreateEntry(ctx, CreateEntryParams{
+		AccountID: params.FromAccountID,
+		Amount:    -params.Fee,
+	})
+	if err != nil {
+		return err
+	}
+	result.FeeEntry = &feeEntry
+
+	_, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID: params.FeeAccountID,
+		Amount:    params.Fee,
+	})
+	if err != nil {
+		return err
+	}
+
+	// accumulate the amounts, since the fee account may also be one side of the transfer
+	amounts := make(map[int64]int64, 3)
+	amounts[params.FromAccountID] -= params.Amount + params.Fee
+	amounts[params.ToAccountID] += params.Amount
+	amounts[params.FeeAccountID] += params.Fee
+	accounts, err := q.addAccountBalances(ctx, amounts, params.BalanceOrder)
+	if err != nil {
+		return err
+	}
+	result.FromAccount = accounts[params.FromAccountID]
+	result.ToAccount = accounts[params.ToAccountID]
+	return nil
+}
+
+// lockTransferAccounts locks the rows of the accounts whose balances the transfer updates and returns them by ID.
+// The rows are locked in the order the balances are updated in, so that locking them early does not change
+// how concurrent transfers wait on each other.
+func (q *Queries) lockTransferAccounts(ctx context.Context, params TransferTxParams) (map[int64]Account, error) {
+	amounts := make(map[int64]int64, 3)
+	amounts[params.FromAccountID] -= params.Amount + params.Fee
+	amounts[params.ToAccountID] += params.Amount
+	if params.Fee > 0 {
+		amounts[params.FeeAccountID] += params.Fee
+	}
+	updates := balanceUpdates(amounts)
+
+	accounts := make(map[int64]Account, len(updates))
+	for _, i := range balanceUpdateOrder(updates, params.BalanceOrder) {
+		account, err := q.GetAccountForUpdate(ctx, updates[i].AccountID)
 		if err != nil {
-			return err
+			return nil, err
+		}
+		accounts[account.ID] = account
+	}
+	return accounts, nil
+}
+
+// checkNotFrozen returns ErrAccountFrozen when one of the accounts is frozen.
+// Transfers check the locked rows, so an account frozen after the handler looked at it still cannot move money.
+func checkNotFrozen(accounts ...Account) error {
+	for _, account := range accounts {
+		if account.Frozen {
+			return fmt.Errorf("%w: account [%d]", ErrAccountFrozen, account.ID)
 		}
-		result.ToEntry = toEntry
+	}
+	return nil
+}
+
+// checkAvailableBalance returns ErrInsufficientFunds when the balance of the account, less the money held for authorized transfers,
+// cannot cover the debit
+func checkAvailableBalance(account Account, debit int64) error {
+	if account.Balance-account.HeldBalance < debit {
+		return fmt.Errorf("%w: %d available, %d needed", ErrInsufficientFunds, account.Balance-account.HeldBalance, debit)
+	}
+	return nil
+}
+
+// checkAccountMinBalance returns ErrMinBalanceViolation when the balance of the account is below its own minimum balance
+func checkAccountMinBalance(account Account) error {
+	if account.Balance < account.MinBalance {
+		return fmt.Errorf("%w: %d is below the minimum of %d", ErrMinBalanceViolation, account.Balance, account.MinBalance)
+	}
+	return nil
+}
+
+// checkTransferLimit returns ErrTransferLimitExceeded when the amount is above the cap of the policy
+func checkTransferLimit(policy util.AccountPolicy, amount int64) error {
+	if policy.MaxTransferAmount > 0 && amount > policy.MaxTransferAmount {
+		return fmt.Errorf("%w: %d is above the maximum of %d", ErrTransferLimitExceeded, amount, policy.MaxTransferAmount)
+	}
+	return nil
+}
+
+// checkMinBalance returns ErrBelowMinimumBalance when balance is below the minimum of the policy
+// and the policy does not allow overdrafts. Rejecting the balance once it is updated keeps the check under the row lock.
+func checkMinBalance(policy util.AccountPolicy, balance int64) error {
+	if !policy.AllowOverdraft && balance < policy.MinBalance {
+		return fmt.Errorf("%w: %d is below the minimum of %d", ErrBelowMinimumBalance, balance, policy.MinBalance)
+	}
+	return nil
+}
 
-		result.FromAccount, result.ToAccount, err = store.AddAccountBalanceOrder(ctx, params.FromAccountID, params.ToAccountID, params.Amount)
+// checkFeeAccount returns ErrCurrencyMismatch when the fee account holds another currency than the source account
+func checkFeeAccount(fromAccount, feeAccount Account) error {
+	if feeAccount.Currency != fromAccount.Currency {
+		return fmt.Errorf("%w: fee account [%d] holds %s", ErrCurrencyMismatch, feeAccount.ID, feeAccount.Currency)
+	}
+	return nil
+}
 
+// addAccountBalances adds the amounts to the balances of the accounts they are keyed by, updating them in the given order
+func (q *Queries) addAccountBalances(ctx context.Context, amounts map[int64]int64, order BalanceOrder) (map[int64]Account, error) {
+	updated, err := q.addBalanceUpdates(ctx, balanceUpdates(amounts), order)
+	if err != nil {
+		return nil, err
+	}
+	accounts := make(map[int64]Account, len(updated))
+	for _, account := range updated {
+		accounts[account.ID] = account
+	}
+	return accounts, nil
+}
+
+// balanceUpdates turns the amounts keyed by account ID into balance updates in account ID order,
+// so that the order does not depend on the iteration of the map
+func balanceUpdates(amounts map[int64]int64) []BalanceUpdate {
+	updates := make([]BalanceUpdate, 0, len(amounts))
+	for id, amount := range amounts {
+		updates = append(updates, BalanceUpdate{AccountID: id, Amount: amount})
+	}
+	sort.Slice(updates, func(i, j int) bool { return updates[i].AccountID < updates[j].AccountID })
+	return updates
+}
+
+// checkWhitelisted returns ErrDestinationNotWhitelisted when the source account has whitelisting enabled
+// and the destination is not on its whitelist
+func (q *Queries) checkWhitelisted(ctx context.Context, fromAccount Account, toAccountID int64) error {
+	if !fromAccount.WhitelistEnabled {
+		return nil
+	}
+
+	whitelisted, err := q.IsDestinationWhitelisted(ctx, IsDestinationWhitelistedParams{
+		AccountID:            fromAccount.ID,
+		DestinationAccountID: toAccountID,
+	})
+	if err != nil {
+		return err
+	}
+	if !whitelisted {
+		return ErrDestinationNotWhitelisted
+	}
+	return nil
+}
+
+// SweepOwnAccountsTx moves the whole available balance of one account to another account of the same owner.
+// Both accounts must belong to owner and hold the same currency.
+// Money held for authorized transfers stays behind, and so does the higher of the minimum balance of the source account
+// and the one its account-type policy sets, unless the policy allows overdrafts.
+func (store *SQLStore) SweepOwnAccountsTx(ctx context.Context, fromAccountID, toAccountID int64, owner string) (TransferTxResult, error) {
+	var result TransferTxResult
+	err := store.execTx(ctx, "SweepOwnAccountsTx", func(q *Queries) error {
+		fromAccount, toAccount, err := q.getAccountsForUpdate(ctx, fromAccountID, toAccountID)
 		if err != nil {
 			return err
 		}
 
-		return nil
+		if fromAccount.Owner != owner || toAccount.Owner != owner {
+			return ErrAccountOwnerMismatch
+		}
+		if fromAccount.Currency != toAccount.Currency {
+			return ErrCurrencyMismatch
+		}
+		policy := store.accountPolicies().For(fromAccount.AccountType)
+		available := fromAccount.Balance - fromAccount.HeldBalance - sweepFloor(policy, fromAccount)
+		if available <= 0 {
+			return ErrNothingToSweep
+		}
+
+		result, err = store.transfer(ctx, q, TransferTxParams{
+			FromAccountID: fromAccountID,
+			ToAccountID:   toAccountID,
+			Amount:        available,
+		})
+		return err
 	})
 
 	return result, err
 }
 
-func (store *SQLStore) AddAccountBalanceOrder(ctx context.Context, account1ID, account2ID, amount int64) (fromAccount Account, toAccount Account, err error) {
+// sweepFloor returns the balance a sweep leaves on the account besides held money,
+// so that it passes both minimum balance checks of the transfer
+func sweepFloor(policy util.AccountPolicy, account Account) int64 {
+	if !policy.AllowOverdraft && policy.MinBalance > account.MinBalance {
+		return policy.MinBalance
+	}
+	return account.MinBalance
+}
 
-	if account1ID < account2ID {
-		// Update from Accounts' balance
-		fromAccount, err = store.AddAccountBalance(ctx, AddAccountBalanceParams{
-			Amount: -amount,
-			ID:     account1ID,
+// ExecuteScheduledTransferTx performs a pending scheduled transfer and marks it done within a single database transaction,
+// charging the fee set through UseTransferFees.
+// It returns ErrScheduledTransferNotPending when the transfer already ran or was canceled,
+// ErrCurrencyMismatch when either account no longer holds the currency of the transfer, ErrAccountFrozen when either is frozen,
+// and ErrInsufficientFunds when the source account cannot cover the amount.
+func (store *SQLStore) ExecuteScheduledTransferTx(ctx context.Context, id int64) (ScheduledTransfer, error) {
+	var scheduled ScheduledTransfer
+	err := store.execTx(ctx, "ExecuteScheduledTransferTx", func(q *Queries) error {
+		var err error
+		scheduled, err = q.GetScheduledTransferForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if scheduled.Status != ScheduledTransferPending {
+			return ErrScheduledTransferNotPending
+		}
+
+		fromAccount, toAccount, err := q.getAccountsForUpdate(ctx, scheduled.FromAccountID, scheduled.ToAccountID)
+		if err != nil {
+			return err
+		}
+		if err := checkScheduledAccounts(scheduled, fromAccount, toAccount); err != nil {
+			return err
+		}
+		if fromAccount.Balance-fromAccount.HeldBalance < scheduled.Amount {
+			return ErrInsufficientFunds
+		}
+		fee, feeAccountID, err := store.transferFee(scheduled.Amount, scheduled.Currency)
+		if err != nil {
+			return err
+		}
+
+		result, err := store.transfer(ctx, q, TransferTxParams{
+			FromAccountID: scheduled.FromAccountID,
+			ToAccountID:   scheduled.ToAccountID,
+			Amount:        scheduled.Amount,
+			Fee:           fee,
+			FeeAccountID:  feeAccountID,
 		})
 		if err != nil {
-			return
+			return err
 		}
 
-		// Update to Accounts' balance
-		toAccount, err = store.AddAccountBalance(ctx, AddAccountBalanceParams{
-			Amount: amount,
-			ID:     account2ID,
+		scheduled, err = q.CompleteScheduledTransfer(ctx, CompleteScheduledTransferParams{
+			ID:         id,
+			TransferID: sql.NullInt64{Int64: result.Transfer.ID, Valid: true},
 		})
+		return err
+	})
+
+	return scheduled, err
+}
+
+// checkScheduledAccounts returns ErrCurrencyMismatch when an account of the scheduled transfer no longer holds its currency,
+// such as after a conversion, and ErrAccountFrozen when one is frozen
+func checkScheduledAccounts(scheduled ScheduledTransfer, accounts ...Account) error {
+	for _, account := range accounts {
+		if account.Currency != scheduled.Currency {
+			return fmt.Errorf("%w: account [%d] holds %s, the transfer is in %s", ErrCurrencyMismatch, account.ID, account.Currency, scheduled.Currency)
+		}
+		if account.Frozen {
+			return fmt.Errorf("%w: account [%d]", ErrAccountFrozen, account.ID)
+		}
+	}
+	return nil
+}
+
+// getAccountsForUpdate locks both accounts in ID order, so concurrent transactions cannot deadlock on them
+func (q *Queries) getAccountsForUpdate(ctx context.Context, account1ID, account2ID int64) (account1 Account, account2 Account, err error) {
+	if account1ID < account2ID {
+		account1, err = q.GetAccountForUpdate(ctx, account1ID)
 		if err != nil {
 			return
 		}
-
+		account2, err = q.GetAccountForUpdate(ctx, account2ID)
 		return
 	}
 
-	// Update to Accounts' balance
-	toAccount, err = store.AddAccountBalance(ctx, AddAccountBalanceParams{
-		Amount: amount,
-		ID:     account2ID,
-	})
+	account2, err = q.GetAccountForUpdate(ctx, account2ID)
 	if err != nil {
 		return
 	}
+	account1, err = q.GetAccountForUpdate(ctx, account1ID)
+	return
+}
 
-	// Update from Accounts' balance
-	fromAccount, err = store.AddAccountBalance(ctx, AddAccountBalanceParams{
-		Amount: -amount,
-		ID:     account1ID,
-	})
+// AddAccountBalanceOrder moves amount from the first account to the second, updating their balances in the given order
+func (q *Queries) AddAccountBalanceOrder(ctx context.Context, account1ID, account2ID, amount int64, order BalanceOrder) (fromAccount Account, toAccount Account, err error) {
+	accounts, err := q.addBalanceUpdates(ctx, []BalanceUpdate{
+		{AccountID: account1ID, Amount: -amount},
+		{AccountID: account2ID, Amount: amount},
+	}, order)
 	if err != nil {
 		return
 	}
+	return accounts[0], accounts[1], nil
+}
 
-	return
+// externalRef stores an empty external reference as NULL, so that transfers without one never collide
+func externalRef(ref string) *string {
+	if ref == "" {
+		return nil
+	}
+	return &ref
+}
+
+// transferCategory stores an empty category as NULL, so that uncategorized transfers are told apart from chosen ones
+func transferCategory(category string) *string {
+	if category == "" {
+		return nil
+	}
+	return &category
 }
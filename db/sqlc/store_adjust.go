@@ -0,0 +1,61 @@
+package db
+
+import "context"
+
+// AdjustAccountBalanceTxParams contains the input of a manual balance correction
+type AdjustAccountBalanceTxParams struct {
+	AccountID int64
+	// Amount is credited to the account when positive and debited when negative
+	Amount     int64
+	Reason     string
+	AdjustedBy string
+}
+
+// AdjustAccountBalanceTxResult is the result of a manual balance correction
+type AdjustAccountBalanceTxResult struct {
+	Account    Account           `json:"account"`
+	Entry      Entry             `json:"entry"`
+	Adjustment AccountAdjustment `json:"adjustment"`
+}
+
+// AdjustAccountBalanceTx corrects the balance of an account, recording the entry and the reason for it within a single database transaction.
+// It returns ErrInsufficientFunds when a debit would take the balance below zero or below the money held for authorized transfers.
+func (store *SQLStore) AdjustAccountBalanceTx(ctx context.Context, params AdjustAccountBalanceTxParams) (AdjustAccountBalanceTxResult, error) {
+	var result AdjustAccountBalanceTxResult
+	err := store.execTx(ctx, "AdjustAccountBalanceTx", func(q *Queries) error {
+		account, err := q.GetAccountForUpdate(ctx, params.AccountID)
+		if err != nil {
+			return err
+		}
+		if account.Balance+params.Amount < account.HeldBalance {
+			return ErrInsufficientFunds
+		}
+
+		result.Entry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID: params.AccountID,
+			Amount:    params.Amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Adjustment, err = q.CreateAccountAdjustment(ctx, CreateAccountAdjustmentParams{
+			AccountID:  params.AccountID,
+			EntryID:    result.Entry.ID,
+			Amount:     params.Amount,
+			Reason:     params.Reason,
+			AdjustedBy: params.AdjustedBy,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Account, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+			ID:     params.AccountID,
+			Amount: params.Amount,
+		})
+		return err
+	})
+
+	return result, err
+}
@@ -0,0 +1,72 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/khuongkd/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdjustAccountBalanceTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := fundTestAccount(t, createTestAccountFor(t, util.RandomOwner(), "USD"), 100)
+
+	credit, err := store.AdjustAccountBalanceTx(context.Background(), AdjustAccountBalanceTxParams{
+		AccountID:  account.ID,
+		Amount:     50,
+		Reason:     "refund of a duplicated charge",
+		AdjustedBy: "banker",
+	})
+	require.NoError(t, err)
+	require.Equal(t, account.Balance+50, credit.Account.Balance)
+	require.Equal(t, int64(50), credit.Entry.Amount)
+	require.Equal(t, credit.Entry.ID, credit.Adjustment.EntryID)
+	require.Equal(t, "refund of a duplicated charge", credit.Adjustment.Reason)
+	require.Equal(t, "banker", credit.Adjustment.AdjustedBy)
+
+	debit, err := store.AdjustAccountBalanceTx(context.Background(), AdjustAccountBalanceTxParams{
+		AccountID:  account.ID,
+		Amount:     -30,
+		Reason:     "reversal of a bank error",
+		AdjustedBy: "banker",
+	})
+	require.NoError(t, err)
+	require.Equal(t, credit.Account.Balance-30, debit.Account.Balance)
+	require.Equal(t, int64(-30), debit.Entry.Amount)
+
+	adjustments, err := store.ListAccountAdjustments(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Len(t, adjustments, 2)
+	require.Equal(t, credit.Adjustment, adjustments[0])
+	require.Equal(t, debit.Adjustment, adjustments[1])
+}
+
+func TestAdjustAccountBalanceTxNegative(t *testing.T) {
+	store := NewStore(testDB)
+	account := createTestAccountFor(t, util.RandomOwner(), "USD")
+
+	_, err := store.AdjustAccountBalanceTx(context.Background(), AdjustAccountBalanceTxParams{
+		AccountID:  account.ID,
+		Amount:     -(account.Balance + 1),
+		Reason:     "overdraw",
+		AdjustedBy: "banker",
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	unchanged, err := store.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance, unchanged.Balance)
+
+	adjustments, err := store.ListAccountAdjustments(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Empty(t, adjustments)
+
+	_, err = store.AdjustAccountBalanceTx(context.Background(), AdjustAccountBalanceTxParams{
+		AccountID:  account.ID,
+		Amount:     10,
+		Reason:     "",
+		AdjustedBy: "banker",
+	})
+	require.ErrorIs(t, err, ErrConstraintViolation)
+}
@@ -0,0 +1,75 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// BatchTransferError reports the transfer of a batch that failed, by its position in the batch
+type BatchTransferError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchTransferError) Error() string {
+	return fmt.Sprintf("transfer %d of the batch: %v", e.Index, e.Err)
+}
+
+func (e *BatchTransferError) Unwrap() error {
+	return e.Err
+}
+
+// BatchTransferTx performs the transfers in order within a single database transaction:
+// either all of them happen, or none does and a BatchTransferError names the first one that failed.
+// Like TransferTx, a transaction aborted to break a deadlock runs once more.
+func (store *SQLStore) BatchTransferTx(ctx context.Context, params []TransferTxParams) ([]TransferTxResult, error) {
+	var results []TransferTxResult
+	failed := -1
+	fn := func(q *Queries) error {
+		results = make([]TransferTxResult, 0, len(params))
+		for i, p := range params {
+			result, err := store.transfer(ctx, q, p)
+			if err != nil {
+				failed = i
+				return err
+			}
+			results = append(results, result)
+		}
+		return nil
+	}
+
+	err := store.execTx(ctx, "BatchTransferTx", fn)
+	retried := false
+	if isDeadlock(err) {
+		DeadlockCount.Add(1)
+		retried = true
+		failed = -1
+		err = store.execTx(ctx, "BatchTransferTx", fn)
+	}
+
+	err = deadlockError(err, batchAccountIDs(params), retried)
+	if err != nil {
+		if failed >= 0 {
+			err = &BatchTransferError{Index: failed, Err: err}
+		}
+		return nil, err
+	}
+	return results, nil
+}
+
+// batchAccountIDs lists the accounts whose balances a batch of transfers updates
+func batchAccountIDs(params []TransferTxParams) []int64 {
+	seen := make(map[int64]bool)
+	var ids []int64
+	for _, p := range params {
+		for _, id := range transferAccountIDs(p) {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
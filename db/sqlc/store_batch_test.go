@@ -0,0 +1,57 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := fundTestAccount(t, createTestAccount(t), 1000)
+	account2 := createTestAccount(t)
+
+	results, err := store.BatchTransferTx(context.Background(), []TransferTxParams{
+		{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 10},
+		{FromAccountID: account2.ID, ToAccountID: account1.ID, Amount: 4},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	require.Equal(t, account1.ID, results[0].Transfer.FromAccountID)
+	require.Equal(t, account2.ID, results[1].Transfer.FromAccountID)
+
+	updated1, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance-6, updated1.Balance)
+
+	updated2, err := store.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, account2.Balance+6, updated2.Balance)
+}
+
+func TestBatchTransferTxRollback(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createTestAccount(t)
+	account2 := createTestAccount(t)
+
+	// the second transfer overdraws account1, so the first one must be undone as well
+	_, err := store.BatchTransferTx(context.Background(), []TransferTxParams{
+		{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 1},
+		{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: account1.Balance},
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	var batchErr *BatchTransferError
+	require.True(t, errors.As(err, &batchErr))
+	require.Equal(t, 1, batchErr.Index)
+
+	updated1, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updated1.Balance)
+
+	updated2, err := store.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, account2.Balance, updated2.Balance)
+}
@@ -0,0 +1,52 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+var (
+	ErrTransferConfirmationNotPending = errors.New("transfer was already confirmed")
+	ErrTransferConfirmationExpired    = errors.New("transfer confirmation has expired")
+)
+
+// Statuses of a transfer confirmation
+const (
+	TransferConfirmationPending   = "pending_confirmation"
+	TransferConfirmationConfirmed = "confirmed"
+)
+
+// ConfirmTransferTx performs the transfer awaiting confirmation and marks it confirmed, within a single database transaction.
+// params are the parameters to run the transfer with; they must describe the transfer of the confirmation.
+// It returns ErrTransferConfirmationNotPending when the transfer was already confirmed
+// and ErrTransferConfirmationExpired when it was not confirmed in time.
+func (store *SQLStore) ConfirmTransferTx(ctx context.Context, id int64, params TransferTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+	err := store.execTx(ctx, "ConfirmTransferTx", func(q *Queries) error {
+		confirmation, err := q.GetTransferConfirmationForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if confirmation.Status != TransferConfirmationPending {
+			return ErrTransferConfirmationNotPending
+		}
+		if !time.Now().Before(confirmation.ExpiresAt) {
+			return ErrTransferConfirmationExpired
+		}
+
+		result, err = store.transfer(ctx, q, params)
+		if err != nil {
+			return err
+		}
+
+		_, err = q.CompleteTransferConfirmation(ctx, CompleteTransferConfirmationParams{
+			ID:         id,
+			TransferID: sql.NullInt64{Int64: result.Transfer.ID, Valid: true},
+		})
+		return err
+	})
+
+	return result, err
+}
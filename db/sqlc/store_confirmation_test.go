@@ -0,0 +1,66 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/khuongkd/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createTestTransferConfirmation(t *testing.T, from, to Account, amount int64, expiresAt time.Time) TransferConfirmation {
+	confirmation, err := testQueries.CreateTransferConfirmation(context.Background(), CreateTransferConfirmationParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        amount,
+		Currency:      from.Currency,
+		TokenHash:     util.RandomString(64),
+		ExpiresAt:     expiresAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, TransferConfirmationPending, confirmation.Status)
+	require.False(t, confirmation.TransferID.Valid)
+	return confirmation
+}
+
+func TestConfirmTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+	owner := util.RandomOwner()
+	from := createTestAccountFor(t, owner, "USD")
+	to := createTestAccountFor(t, util.RandomOwner(), "USD")
+	confirmation := createTestTransferConfirmation(t, from, to, 10, time.Now().Add(time.Minute))
+	arg := TransferTxParams{FromAccountID: from.ID, ToAccountID: to.ID, Amount: 10}
+
+	result, err := store.ConfirmTransferTx(context.Background(), confirmation.ID, arg)
+	require.NoError(t, err)
+	require.Equal(t, from.Balance-10, result.FromAccount.Balance)
+	require.Equal(t, to.Balance+10, result.ToAccount.Balance)
+
+	confirmed, err := store.GetTransferConfirmation(context.Background(), confirmation.ID)
+	require.NoError(t, err)
+	require.Equal(t, TransferConfirmationConfirmed, confirmed.Status)
+	require.Equal(t, result.Transfer.ID, confirmed.TransferID.Int64)
+
+	// a transfer is confirmed only once
+	_, err = store.ConfirmTransferTx(context.Background(), confirmation.ID, arg)
+	require.ErrorIs(t, err, ErrTransferConfirmationNotPending)
+}
+
+func TestConfirmTransferTxExpired(t *testing.T) {
+	store := NewStore(testDB)
+	from := createTestAccount(t)
+	to := createTestAccountFor(t, util.RandomOwner(), from.Currency)
+	confirmation := createTestTransferConfirmation(t, from, to, 10, time.Now().Add(-time.Second))
+
+	_, err := store.ConfirmTransferTx(context.Background(), confirmation.ID, TransferTxParams{FromAccountID: from.ID, ToAccountID: to.ID, Amount: 10})
+	require.ErrorIs(t, err, ErrTransferConfirmationExpired)
+
+	updatedFrom, err := store.GetAccount(context.Background(), from.ID)
+	require.NoError(t, err)
+	require.Equal(t, from.Balance, updatedFrom.Balance)
+
+	pending, err := store.GetTransferConfirmation(context.Background(), confirmation.ID)
+	require.NoError(t, err)
+	require.Equal(t, TransferConfirmationPending, pending.Status)
+}
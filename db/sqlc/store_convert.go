@@ -0,0 +1,140 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/khuongkd/simplebank/util"
+)
+
+var (
+	ErrCurrencyUnchanged = errors.New("account already holds the currency")
+	ErrPendingHolds      = errors.New("account has authorized transfer holds")
+
+	ErrPendingScheduledTransfers = errors.New("account has pending scheduled transfers")
+	ErrPendingConfirmations      = errors.New("account has transfers awaiting confirmation")
+
+	ErrCurrencyPairNotAllowed = errors.New("conversion between the currencies is not allowed")
+)
+
+// ConvertAccountCurrencyTxParams describes a change of an account's currency
+type ConvertAccountCurrencyTxParams struct {
+	AccountID int64
+	Currency  string
+	// Rate is the amount of the new currency one unit of the old currency buys
+	Rate *big.Rat
+	// NumberPerCurrency renumbers the account within the new currency, when account numbers are assigned per currency
+	NumberPerCurrency bool
+	// RoundingMode rounds the converted balance; empty means half up
+	RoundingMode util.RoundingMode
+	// AllowedPairs limits the conversions that may be made; nil allows every pair
+	AllowedPairs util.CurrencyPairs
+}
+
+// ConvertAccountCurrencyTxResult is the result of converting an account to another currency
+type ConvertAccountCurrencyTxResult struct {
+	Account Account `json:"account"`
+	// Entry records the change of the balance, so the entries of the account still add up to it
+	Entry Entry `json:"entry"`
+	// FoldedSubBalance is the debit of the sub-balance the account held in the new currency, which moved into its balance
+	FoldedSubBalance *CurrencyBalanceChange `json:"folded_sub_balance,omitempty"`
+	// RoundingMode is the rounding mode the balance was converted with
+	RoundingMode util.RoundingMode `json:"rounding_mode"`
+}
+
+// ConvertAccountCurrencyTx converts the balance and the minimum balance of an account at the given rate and switches it to the new currency.
+// A sub-balance the account holds in the new currency moves into its balance, since the balance now holds that currency.
+// It refuses accounts with authorized holds, pending scheduled transfers or transfers awaiting confirmation in either direction,
+// since those were made in the old currency.
+func (store *SQLStore) ConvertAccountCurrencyTx(ctx context.Context, params ConvertAccountCurrencyTxParams) (ConvertAccountCurrencyTxResult, error) {
+	var result ConvertAccountCurrencyTxResult
+	err := store.execTx(ctx, "ConvertAccountCurrencyTx", func(q *Queries) error {
+		account, err := q.GetAccountForUpdate(ctx, params.AccountID)
+		if err != nil {
+			return err
+		}
+		if account.Currency == params.Currency {
+			return ErrCurrencyUnchanged
+		}
+		if !params.AllowedPairs.Allows(account.Currency, params.Currency) {
+			return fmt.Errorf("%w: %s to %s", ErrCurrencyPairNotAllowed, account.Currency, params.Currency)
+		}
+
+		holds, err := q.CountAuthorizedHoldsByAccount(ctx, params.AccountID)
+		if err != nil {
+			return err
+		}
+		if holds > 0 {
+			return ErrPendingHolds
+		}
+		scheduled, err := q.CountPendingScheduledTransfersByAccount(ctx, params.AccountID)
+		if err != nil {
+			return err
+		}
+		if scheduled > 0 {
+			return ErrPendingScheduledTransfers
+		}
+		confirmations, err := q.CountPendingTransferConfirmationsByAccount(ctx, CountPendingTransferConfirmationsByAccountParams{
+			AccountID: params.AccountID,
+			Now:       time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+		if confirmations > 0 {
+			return ErrPendingConfirmations
+		}
+
+		result.RoundingMode = params.RoundingMode
+		if result.RoundingMode == "" {
+			result.RoundingMode = util.RoundHalfUp
+		}
+		balance, err := util.ConvertAmount(account.Balance, params.Rate, params.Currency, result.RoundingMode)
+		if err != nil {
+			return err
+		}
+		minBalance, err := util.ConvertAmount(account.MinBalance, params.Rate, params.Currency, result.RoundingMode)
+		if err != nil {
+			return err
+		}
+
+		subBalance, err := q.GetSubBalanceForUpdate(ctx, GetSubBalanceForUpdateParams{
+			AccountID: params.AccountID,
+			Currency:  params.Currency,
+		})
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+		if subBalance.Balance > 0 {
+			folded, err := changeSubBalance(ctx, q, params.AccountID, params.Currency, -subBalance.Balance)
+			if err != nil {
+				return err
+			}
+			result.FoldedSubBalance = &folded
+			balance += subBalance.Balance
+		}
+
+		result.Entry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID: params.AccountID,
+			Amount:    balance - account.Balance,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Account, err = q.SetAccountCurrency(ctx, SetAccountCurrencyParams{
+			ID:                params.AccountID,
+			Currency:          params.Currency,
+			Balance:           balance,
+			MinBalance:        minBalance,
+			NumberPerCurrency: params.NumberPerCurrency,
+		})
+		return err
+	})
+
+	return result, err
+}
@@ -0,0 +1,170 @@
+package db
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/khuongkd/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertAccountCurrencyTx(t *testing.T) {
+	store := NewStore(testDB)
+	account, err := store.CreateAcount(context.Background(), CreateAcountParams{
+		Owner:    util.RandomOwner(),
+		Balance:  0,
+		Currency: "USD",
+	})
+	require.NoError(t, err)
+	account = fundTestAccount(t, account, 10000)
+
+	result, err := store.ConvertAccountCurrencyTx(context.Background(), ConvertAccountCurrencyTxParams{
+		AccountID: account.ID,
+		Currency:  "EUR",
+		Rate:      big.NewRat(91234, 100000),
+	})
+	require.NoError(t, err)
+	require.Equal(t, "EUR", result.Account.Currency)
+	require.Equal(t, int64(9123), result.Account.Balance)
+	require.Equal(t, account.ID, result.Entry.AccountID)
+	require.Equal(t, int64(9123-10000), result.Entry.Amount)
+
+	_, err = store.ConvertAccountCurrencyTx(context.Background(), ConvertAccountCurrencyTxParams{
+		AccountID: account.ID,
+		Currency:  "EUR",
+		Rate:      big.NewRat(1, 1),
+	})
+	require.ErrorIs(t, err, ErrCurrencyUnchanged)
+}
+
+func TestConvertAccountCurrencyTxSubBalanceAndMinBalance(t *testing.T) {
+	store := NewStore(testDB)
+	account, err := store.CreateAcount(context.Background(), CreateAcountParams{
+		Owner:    util.RandomOwner(),
+		Balance:  0,
+		Currency: "USD",
+	})
+	require.NoError(t, err)
+	account = fundTestAccount(t, account, 10000)
+	_, err = store.SetAccountMinBalance(context.Background(), SetAccountMinBalanceParams{ID: account.ID, MinBalance: 1000})
+	require.NoError(t, err)
+
+	// 2000 USD become a sub-balance of 1800 EUR, leaving 8000 USD
+	_, err = store.ConvertSubBalanceTx(context.Background(), ConvertSubBalanceTxParams{
+		AccountID:    account.ID,
+		FromCurrency: "USD",
+		ToCurrency:   "EUR",
+		Amount:       2000,
+		Rate:         big.NewRat(9, 10),
+	})
+	require.NoError(t, err)
+
+	result, err := store.ConvertAccountCurrencyTx(context.Background(), ConvertAccountCurrencyTxParams{
+		AccountID: account.ID,
+		Currency:  "EUR",
+		Rate:      big.NewRat(9, 10),
+	})
+	require.NoError(t, err)
+	require.Equal(t, "EUR", result.Account.Currency)
+	require.Equal(t, int64(7200+1800), result.Account.Balance)
+	require.Equal(t, int64(900), result.Account.MinBalance)
+	require.Equal(t, int64(9000-8000), result.Entry.Amount)
+	require.NotNil(t, result.FoldedSubBalance)
+	require.Equal(t, int64(-1800), result.FoldedSubBalance.Amount)
+	require.Zero(t, result.FoldedSubBalance.Balance)
+
+	subBalances, err := store.ListSubBalances(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Len(t, subBalances, 1)
+	require.Zero(t, subBalances[0].Balance)
+
+	reconciled, err := store.ReconcileAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Zero(t, reconciled.Discrepancy)
+}
+
+func TestConvertAccountCurrencyTxPendingHold(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := fundTestAccount(t, createTestAccountFor(t, util.RandomOwner(), "USD"), 100)
+	account2 := createTestAccountFor(t, util.RandomOwner(), "USD")
+
+	authorizeTestHold(t, store, account1, account2, 10)
+
+	// both the payer and the payee of the hold are blocked
+	for _, account := range []Account{account1, account2} {
+		_, err := store.ConvertAccountCurrencyTx(context.Background(), ConvertAccountCurrencyTxParams{
+			AccountID: account.ID,
+			Currency:  "EUR",
+			Rate:      big.NewRat(9, 10),
+		})
+		require.ErrorIs(t, err, ErrPendingHolds)
+
+		unchanged, err := store.GetAccount(context.Background(), account.ID)
+		require.NoError(t, err)
+		require.Equal(t, "USD", unchanged.Currency)
+	}
+}
+
+func TestConvertAccountCurrencyTxAllowedPairs(t *testing.T) {
+	store := NewStore(testDB)
+	account := fundTestAccount(t, createTestAccountFor(t, util.RandomOwner(), "USD"), 100)
+	pairs := util.CurrencyPairs{{From: "USD", To: "EUR"}: true}
+
+	_, err := store.ConvertAccountCurrencyTx(context.Background(), ConvertAccountCurrencyTxParams{
+		AccountID:    account.ID,
+		Currency:     "GBP",
+		Rate:         big.NewRat(8, 10),
+		AllowedPairs: pairs,
+	})
+	require.ErrorIs(t, err, ErrCurrencyPairNotAllowed)
+
+	unchanged, err := store.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, "USD", unchanged.Currency)
+
+	result, err := store.ConvertAccountCurrencyTx(context.Background(), ConvertAccountCurrencyTxParams{
+		AccountID:    account.ID,
+		Currency:     "EUR",
+		Rate:         big.NewRat(9, 10),
+		AllowedPairs: pairs,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "EUR", result.Account.Currency)
+}
+
+func TestConvertAccountCurrencyTxPendingTransfers(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createTestAccountFor(t, util.RandomOwner(), "USD")
+	account2 := createTestAccountFor(t, util.RandomOwner(), "USD")
+	arg := ConvertAccountCurrencyTxParams{
+		AccountID: account2.ID,
+		Currency:  "EUR",
+		Rate:      big.NewRat(9, 10),
+	}
+
+	scheduled := createTestScheduledTransfer(t, account1, account2, 10, time.Now().Add(time.Hour))
+	_, err := store.ConvertAccountCurrencyTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrPendingScheduledTransfers)
+
+	_, err = store.CancelScheduledTransfer(context.Background(), scheduled.ID)
+	require.NoError(t, err)
+
+	// an expired confirmation can no longer be confirmed, so it does not block the conversion
+	createTestTransferConfirmation(t, account1, account2, 10, time.Now().Add(-time.Second))
+	count, err := store.CountPendingTransferConfirmationsByAccount(context.Background(), CountPendingTransferConfirmationsByAccountParams{
+		AccountID: account2.ID,
+		Now:       time.Now(),
+	})
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	createTestTransferConfirmation(t, account1, account2, 10, time.Now().Add(time.Minute))
+	_, err = store.ConvertAccountCurrencyTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrPendingConfirmations)
+
+	unchanged, err := store.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, "USD", unchanged.Currency)
+}
@@ -0,0 +1,37 @@
+package db
+
+import "context"
+
+// exportBatchSize is the number of accounts StreamAllAccounts reads from the database at a time
+const exportBatchSize = 500
+
+// StreamAllAccounts calls fn with every account in id order.
+// Accounts are read in batches keyed on the last id seen, so memory use stays flat however many accounts there are
+// and accounts created while streaming are picked up if their id comes later.
+// Streaming stops at the first error returned by fn or when ctx is done.
+func (store *SQLStore) StreamAllAccounts(ctx context.Context, fn func(Account) error) error {
+	var afterID int64
+	for {
+		accounts, err := store.ListAccountsAfter(ctx, ListAccountsAfterParams{
+			AfterID:   afterID,
+			BatchSize: exportBatchSize,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, account := range accounts {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := fn(account); err != nil {
+				return err
+			}
+			afterID = account.ID
+		}
+
+		if len(accounts) < exportBatchSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestListAccountsAfter(t *testing.T) {
+	account1 := createTestAccount(t)
+	account2 := createTestAccount(t)
+
+	accounts, err := testQueries.ListAccountsAfter(context.Background(), ListAccountsAfterParams{
+		AfterID:   account1.ID,
+		BatchSize: 1,
+	})
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+	require.Equal(t, account2.ID, accounts[0].ID)
+}
+
+func TestStreamAllAccounts(t *testing.T) {
+	store := NewStore(testDB)
+	want := make(map[int64]bool)
+	for i := 0; i < 5; i++ {
+		want[createTestAccount(t).ID] = true
+	}
+
+	seen := make(map[int64]int)
+	var lastID int64
+	err := store.StreamAllAccounts(context.Background(), func(account Account) error {
+		require.Greater(t, account.ID, lastID)
+		lastID = account.ID
+		seen[account.ID]++
+		return nil
+	})
+	require.NoError(t, err)
+	for id := range want {
+		require.Equal(t, 1, seen[id])
+	}
+}
+
+func TestStreamAllAccountsStops(t *testing.T) {
+	store := NewStore(testDB)
+	createTestAccount(t)
+	createTestAccount(t)
+
+	errStop := errors.New("stop")
+	visited := 0
+	err := store.StreamAllAccounts(context.Background(), func(account Account) error {
+		visited++
+		return errStop
+	})
+	require.ErrorIs(t, err, errStop)
+	require.Equal(t, 1, visited)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = store.StreamAllAccounts(ctx, func(account Account) error {
+		t.Fatal("no account should be visited once the context is done")
+		return nil
+	})
+	require.Error(t, err)
+}
@@ -0,0 +1,160 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+var ErrHoldNotAuthorized = errors.New("transfer hold was already captured or voided")
+
+// Statuses of a transfer hold
+const (
+	TransferHoldAuthorized = "authorized"
+	TransferHoldCaptured   = "captured"
+	TransferHoldVoided     = "voided"
+)
+
+// TransferHoldTxResult is the result of authorizing or voiding a hold
+type TransferHoldTxResult struct {
+	Hold        TransferHold `json:"hold"`
+	FromAccount Account      `json:"from_account"`
+}
+
+// AuthorizeTransferTx reserves the amount on the source account without moving any money.
+// The held amount stays in the balance but is no longer available to other transfers.
+// It makes the checks TransferTx makes on the source account, against the balance left once every hold on it is captured:
+// ErrTransferLimitExceeded or ErrBelowMinimumBalance when the policy of the account type rejects the transfer,
+// ErrMinBalanceViolation when it would go below its own minimum balance,
+// and ErrInsufficientFunds when the available balance cannot cover the amount.
+func (store *SQLStore) AuthorizeTransferTx(ctx context.Context, params CreateTransferParams) (TransferHoldTxResult, error) {
+	var result TransferHoldTxResult
+	err := store.execTx(ctx, "AuthorizeTransferTx", func(q *Queries) error {
+		fromAccount, _, err := q.getAccountsForUpdate(ctx, params.FromAccountID, params.ToAccountID)
+		if err != nil {
+			return err
+		}
+		if err := checkAvailableBalance(fromAccount, params.Amount); err != nil {
+			return err
+		}
+		if err := q.checkWhitelisted(ctx, fromAccount, params.ToAccountID); err != nil {
+			return err
+		}
+		if err := store.checkHoldPolicy(fromAccount, params.Amount); err != nil {
+			return err
+		}
+
+		result.Hold, err = q.CreateTransferHold(ctx, CreateTransferHoldParams{
+			FromAccountID: params.FromAccountID,
+			ToAccountID:   params.ToAccountID,
+			Amount:        params.Amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.FromAccount, err = q.AddAccountHeldBalance(ctx, AddAccountHeldBalanceParams{
+			ID:     params.FromAccountID,
+			Amount: params.Amount,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// checkHoldPolicy checks a hold of amount on the account against the policy of its type and its own minimum balance,
+// as if every hold on it, this one included, were captured
+func (store *SQLStore) checkHoldPolicy(account Account, amount int64) error {
+	policy := store.accountPolicies().For(account.AccountType)
+	if err := checkTransferLimit(policy, amount); err != nil {
+		return err
+	}
+
+	account.Balance -= account.HeldBalance + amount
+	if err := checkMinBalance(policy, account.Balance); err != nil {
+		return err
+	}
+	return checkAccountMinBalance(account)
+}
+
+// CaptureTransferTx releases an authorized hold and performs the transfer it reserved, within a single database transaction,
+// charging the fee set through UseTransferFees.
+// It returns ErrHoldNotAuthorized when the hold was already captured or voided.
+func (store *SQLStore) CaptureTransferTx(ctx context.Context, holdID int64) (TransferTxResult, error) {
+	var result TransferTxResult
+	err := store.execTx(ctx, "CaptureTransferTx", func(q *Queries) error {
+		hold, fromAccount, err := q.releaseHold(ctx, holdID)
+		if err != nil {
+			return err
+		}
+		fee, feeAccountID, err := store.transferFee(hold.Amount, fromAccount.Currency)
+		if err != nil {
+			return err
+		}
+
+		result, err = store.transfer(ctx, q, TransferTxParams{
+			FromAccountID: hold.FromAccountID,
+			ToAccountID:   hold.ToAccountID,
+			Amount:        hold.Amount,
+			Fee:           fee,
+			FeeAccountID:  feeAccountID,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = q.SettleTransferHold(ctx, SettleTransferHoldParams{
+			ID:         holdID,
+			Status:     TransferHoldCaptured,
+			TransferID: sql.NullInt64{Int64: result.Transfer.ID, Valid: true},
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// VoidTransferTx releases an authorized hold without moving any money.
+// It returns ErrHoldNotAuthorized when the hold was already captured or voided.
+func (store *SQLStore) VoidTransferTx(ctx context.Context, holdID int64) (TransferHoldTxResult, error) {
+	var result TransferHoldTxResult
+	err := store.execTx(ctx, "VoidTransferTx", func(q *Queries) error {
+		var err error
+		_, result.FromAccount, err = q.releaseHold(ctx, holdID)
+		if err != nil {
+			return err
+		}
+
+		result.Hold, err = q.SettleTransferHold(ctx, SettleTransferHoldParams{
+			ID:     holdID,
+			Status: TransferHoldVoided,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// releaseHold locks an authorized hold and both of its accounts, then gives the held amount back to the source account,
+// which it returns
+func (q *Queries) releaseHold(ctx context.Context, holdID int64) (TransferHold, Account, error) {
+	var fromAccount Account
+	hold, err := q.GetTransferHoldForUpdate(ctx, holdID)
+	if err != nil {
+		return hold, fromAccount, err
+	}
+	if hold.Status != TransferHoldAuthorized {
+		return hold, fromAccount, ErrHoldNotAuthorized
+	}
+
+	if _, _, err := q.getAccountsForUpdate(ctx, hold.FromAccountID, hold.ToAccountID); err != nil {
+		return hold, fromAccount, err
+	}
+
+	fromAccount, err = q.AddAccountHeldBalance(ctx, AddAccountHeldBalanceParams{
+		ID:     hold.FromAccountID,
+		Amount: -hold.Amount,
+	})
+	return hold, fromAccount, err
+}
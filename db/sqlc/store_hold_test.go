@@ -0,0 +1,180 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/khuongkd/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func authorizeTestHold(t *testing.T, store Store, from, to Account, amount int64) TransferHoldTxResult {
+	result, err := store.AuthorizeTransferTx(context.Background(), CreateTransferParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        amount,
+	})
+	require.NoError(t, err)
+	require.NotZero(t, result.Hold.ID)
+	require.Equal(t, TransferHoldAuthorized, result.Hold.Status)
+	require.Equal(t, amount, result.Hold.Amount)
+	require.False(t, result.Hold.TransferID.Valid)
+
+	// authorizing holds the amount without touching the balance
+	require.Equal(t, from.Balance, result.FromAccount.Balance)
+	require.Equal(t, from.HeldBalance+amount, result.FromAccount.HeldBalance)
+	return result
+}
+
+func TestAuthorizeThenCaptureTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := fundTestAccount(t, createTestAccount(t), 100)
+	account2 := createTestAccount(t)
+
+	hold := authorizeTestHold(t, store, account1, account2, 60)
+
+	// the held amount is no longer available to other holds
+	_, err := store.AuthorizeTransferTx(context.Background(), CreateTransferParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.Balance - 59,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	result, err := store.CaptureTransferTx(context.Background(), hold.Hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(60), result.Transfer.Amount)
+	require.Equal(t, account1.Balance-60, result.FromAccount.Balance)
+	require.Zero(t, result.FromAccount.HeldBalance)
+	require.Equal(t, account2.Balance+60, result.ToAccount.Balance)
+
+	settled, err := store.GetTransferHold(context.Background(), hold.Hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, TransferHoldCaptured, settled.Status)
+	require.Equal(t, result.Transfer.ID, settled.TransferID.Int64)
+
+	_, err = store.CaptureTransferTx(context.Background(), hold.Hold.ID)
+	require.ErrorIs(t, err, ErrHoldNotAuthorized)
+	_, err = store.VoidTransferTx(context.Background(), hold.Hold.ID)
+	require.ErrorIs(t, err, ErrHoldNotAuthorized)
+}
+
+func TestAuthorizeThenVoidTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := fundTestAccount(t, createTestAccount(t), 100)
+	account2 := createTestAccount(t)
+
+	hold := authorizeTestHold(t, store, account1, account2, 60)
+
+	result, err := store.VoidTransferTx(context.Background(), hold.Hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, TransferHoldVoided, result.Hold.Status)
+	require.False(t, result.Hold.TransferID.Valid)
+	require.Equal(t, account1.Balance, result.FromAccount.Balance)
+	require.Zero(t, result.FromAccount.HeldBalance)
+
+	updatedAccount2, err := store.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, account2.Balance, updatedAccount2.Balance)
+
+	_, err = store.CaptureTransferTx(context.Background(), hold.Hold.ID)
+	require.ErrorIs(t, err, ErrHoldNotAuthorized)
+}
+
+func TestAuthorizeTransferTxPolicy(t *testing.T) {
+	store := NewStoreWithDialect(testDB, Postgres)
+	store.UseAccountPolicies(func() util.AccountPolicies {
+		return util.AccountPolicies{
+			AccountTypeSavings: {MaxTransferAmount: 500, MinBalance: 1000},
+		}
+	})
+	savings, err := store.CreateAcount(context.Background(), CreateAcountParams{
+		Owner:       util.RandomOwner(),
+		Balance:     1500,
+		Currency:    "USD",
+		AccountType: AccountTypeSavings,
+	})
+	require.NoError(t, err)
+	checking, err := store.CreateAcount(context.Background(), CreateAcountParams{
+		Owner:    util.RandomOwner(),
+		Balance:  100,
+		Currency: "USD",
+	})
+	require.NoError(t, err)
+	_, err = store.SetAccountMinBalance(context.Background(), SetAccountMinBalanceParams{ID: checking.ID, MinBalance: 60})
+	require.NoError(t, err)
+
+	authorize := func(from, to Account, amount int64) error {
+		_, err := store.AuthorizeTransferTx(context.Background(), CreateTransferParams{
+			FromAccountID: from.ID,
+			ToAccountID:   to.ID,
+			Amount:        amount,
+		})
+		return err
+	}
+
+	require.ErrorIs(t, authorize(savings, checking, 600), ErrTransferLimitExceeded)
+	require.NoError(t, authorize(savings, checking, 400))
+	// the first hold counts, so the second one would leave 700
+	require.ErrorIs(t, authorize(savings, checking, 400), ErrBelowMinimumBalance)
+
+	require.ErrorIs(t, authorize(checking, savings, 50), ErrMinBalanceViolation)
+	require.NoError(t, authorize(checking, savings, 40))
+}
+
+func TestCaptureTransferTxFee(t *testing.T) {
+	store := NewStoreWithDialect(testDB, Postgres)
+	account1 := fundTestAccount(t, createTestAccountFor(t, util.RandomOwner(), "USD"), 100)
+	account2 := createTestAccountFor(t, util.RandomOwner(), "USD")
+	feeAccount := createTestAccountFor(t, util.RandomOwner(), "USD")
+	store.UseTransferFees(func(amount int64, currency string) (int64, int64, error) {
+		return util.Config{TransferFeeFlat: 2, TransferFeeAccounts: map[string]int64{"USD": feeAccount.ID}}.TransferFee(amount, currency)
+	})
+
+	hold := authorizeTestHold(t, store, account1, account2, 60)
+	result, err := store.CaptureTransferTx(context.Background(), hold.Hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), result.Transfer.Fee)
+	require.Equal(t, account1.Balance-62, result.FromAccount.Balance)
+
+	updatedFeeAccount, err := store.GetAccount(context.Background(), feeAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, feeAccount.Balance+2, updatedFeeAccount.Balance)
+}
+
+func TestCaptureTransferTxFrozen(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := fundTestAccount(t, createTestAccount(t), 100)
+	account2 := createTestAccount(t)
+
+	hold := authorizeTestHold(t, store, account1, account2, 60)
+	_, err := store.PatchAccount(context.Background(), PatchAccountParams{ID: account1.ID, SetFrozen: true, Frozen: true, FreezeReason: "kyc"})
+	require.NoError(t, err)
+
+	_, err = store.CaptureTransferTx(context.Background(), hold.Hold.ID)
+	require.ErrorIs(t, err, ErrAccountFrozen)
+
+	// the hold stays authorized and keeps its amount held
+	unsettled, err := store.GetTransferHold(context.Background(), hold.Hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, TransferHoldAuthorized, unsettled.Status)
+	updatedAccount1, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updatedAccount1.Balance)
+	require.Equal(t, hold.FromAccount.HeldBalance, updatedAccount1.HeldBalance)
+}
+
+func TestTransferTxRespectsHeldBalance(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := fundTestAccount(t, createTestAccount(t), 100)
+	account2 := createTestAccount(t)
+
+	authorizeTestHold(t, store, account1, account2, account1.Balance)
+
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
@@ -0,0 +1,7 @@
+package db
+
+// isInternalTransfer reports whether both accounts belong to the same owner and hold the same currency.
+// Moving money between one's own accounts is free of fees; the whitelist and the policy of the account type still apply.
+func isInternalTransfer(from, to Account) bool {
+	return from.ID != to.ID && from.Owner == to.Owner && from.Currency == to.Currency
+}
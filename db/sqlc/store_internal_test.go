@@ -0,0 +1,69 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/khuongkd/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferTxInternal(t *testing.T) {
+	store := NewStoreWithDialect(testDB, Postgres)
+	owner := util.RandomOwner()
+	account1 := fundTestAccount(t, createTestAccountFor(t, owner, "USD"), 100)
+	account2 := createTestAccountFor(t, owner, "USD")
+	other := createTestAccountFor(t, util.RandomOwner(), "USD")
+	feeAccount := createTestAccountFor(t, util.RandomOwner(), "USD")
+	policies := util.AccountPolicies{AccountTypeChecking: {MaxTransferAmount: 10}}
+	store.UseAccountPolicies(func() util.AccountPolicies { return policies })
+
+	send := func(toAccountID int64) (TransferTxResult, error) {
+		return store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   toAccountID,
+			Amount:        50,
+			Fee:           5,
+			FeeAccountID:  feeAccount.ID,
+		})
+	}
+
+	// between accounts of the same owner the policy and the whitelist still apply
+	_, err := send(account2.ID)
+	require.ErrorIs(t, err, ErrTransferLimitExceeded)
+
+	_, err = store.SetAccountWhitelistEnabled(context.Background(), SetAccountWhitelistEnabledParams{ID: account1.ID, Enabled: true})
+	require.NoError(t, err)
+	policies = nil
+	_, err = send(account2.ID)
+	require.ErrorIs(t, err, ErrDestinationNotWhitelisted)
+
+	_, err = store.SetAccountWhitelistEnabled(context.Background(), SetAccountWhitelistEnabledParams{ID: account1.ID, Enabled: false})
+	require.NoError(t, err)
+
+	// but the fee does not
+	result, err := send(account2.ID)
+	require.NoError(t, err)
+	require.Zero(t, result.Transfer.Fee)
+	require.Nil(t, result.FeeEntry)
+	require.Equal(t, int64(-50), result.FromEntry.Amount)
+	require.Equal(t, int64(50), result.ToEntry.Amount)
+	require.Equal(t, account1.Balance-50, result.FromAccount.Balance)
+	require.Equal(t, account2.Balance+50, result.ToAccount.Balance)
+
+	unchangedFeeAccount, err := store.GetAccount(context.Background(), feeAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, feeAccount.Balance, unchangedFeeAccount.Balance)
+
+	// to another owner the fee is charged as well
+	result, err = send(other.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), result.Transfer.Fee)
+	require.NotNil(t, result.FeeEntry)
+	require.Equal(t, account1.Balance-105, result.FromAccount.Balance)
+	require.Equal(t, other.Balance+50, result.ToAccount.Balance)
+
+	updatedFeeAccount, err := store.GetAccount(context.Background(), feeAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, feeAccount.Balance+5, updatedFeeAccount.Balance)
+}
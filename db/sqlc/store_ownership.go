@@ -0,0 +1,68 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrOwnershipRequestNotPending = errors.New("ownership transfer request was already accepted or expired")
+	ErrOwnershipRequestExpired    = errors.New("ownership transfer request has expired")
+	ErrOwnershipRequestStale      = errors.New("account owner changed since the ownership transfer was requested")
+)
+
+// Statuses of an ownership transfer request
+const (
+	OwnershipRequestPending  = "pending"
+	OwnershipRequestAccepted = "accepted"
+	OwnershipRequestExpired  = "expired"
+)
+
+// AcceptOwnershipTransferTxResult is the result of accepting an ownership transfer request
+type AcceptOwnershipTransferTxResult struct {
+	Request OwnershipTransferRequest `json:"request"`
+	Account Account                  `json:"account"`
+}
+
+// AcceptOwnershipTransferTx hands the account over to the new owner named in the request, within a single database transaction.
+// Only that new owner may accept, and only while the request is pending, unexpired and the account still has the owner it was requested from.
+func (store *SQLStore) AcceptOwnershipTransferTx(ctx context.Context, requestID int64, owner string) (AcceptOwnershipTransferTxResult, error) {
+	var result AcceptOwnershipTransferTxResult
+	err := store.execTx(ctx, "AcceptOwnershipTransferTx", func(q *Queries) error {
+		request, err := q.GetOwnershipTransferRequestForUpdate(ctx, requestID)
+		if err != nil {
+			return err
+		}
+		if request.Status != OwnershipRequestPending {
+			return ErrOwnershipRequestNotPending
+		}
+		if !time.Now().Before(request.ExpiresAt) {
+			return ErrOwnershipRequestExpired
+		}
+		if request.NewOwner != owner {
+			return ErrAccountOwnerMismatch
+		}
+
+		account, err := q.GetAccountForUpdate(ctx, request.AccountID)
+		if err != nil {
+			return err
+		}
+		if account.Owner != request.CurrentOwner {
+			return ErrOwnershipRequestStale
+		}
+
+		result.Account, err = q.SetAccountOwner(ctx, SetAccountOwnerParams{
+			ID:    request.AccountID,
+			Owner: request.NewOwner,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Request, err = q.AcceptOwnershipTransferRequest(ctx, requestID)
+		return err
+	})
+
+	return result, err
+}
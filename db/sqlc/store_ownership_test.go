@@ -0,0 +1,77 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/khuongkd/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createTestOwnershipRequest(t *testing.T, account Account, newOwner string, expiresAt time.Time) OwnershipTransferRequest {
+	request, err := testQueries.CreateOwnershipTransferRequest(context.Background(), CreateOwnershipTransferRequestParams{
+		AccountID:    account.ID,
+		CurrentOwner: account.Owner,
+		NewOwner:     newOwner,
+		ExpiresAt:    expiresAt,
+	})
+	require.NoError(t, err)
+	require.NotZero(t, request.ID)
+	require.Equal(t, OwnershipRequestPending, request.Status)
+	return request
+}
+
+func TestAcceptOwnershipTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := createTestAccount(t)
+	newOwner := util.RandomOwner()
+	request := createTestOwnershipRequest(t, account, newOwner, time.Now().Add(time.Hour))
+
+	// only the new owner may accept
+	_, err := store.AcceptOwnershipTransferTx(context.Background(), request.ID, account.Owner)
+	require.ErrorIs(t, err, ErrAccountOwnerMismatch)
+
+	result, err := store.AcceptOwnershipTransferTx(context.Background(), request.ID, newOwner)
+	require.NoError(t, err)
+	require.Equal(t, newOwner, result.Account.Owner)
+	require.Equal(t, OwnershipRequestAccepted, result.Request.Status)
+
+	_, err = store.AcceptOwnershipTransferTx(context.Background(), request.ID, newOwner)
+	require.ErrorIs(t, err, ErrOwnershipRequestNotPending)
+}
+
+func TestAcceptOwnershipTransferTxExpired(t *testing.T) {
+	store := NewStore(testDB)
+	account := createTestAccount(t)
+	newOwner := util.RandomOwner()
+	request := createTestOwnershipRequest(t, account, newOwner, time.Now().Add(-time.Minute))
+
+	_, err := store.AcceptOwnershipTransferTx(context.Background(), request.ID, newOwner)
+	require.ErrorIs(t, err, ErrOwnershipRequestExpired)
+
+	n, err := testQueries.ExpireOwnershipTransferRequests(context.Background(), time.Now())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, n, int64(1))
+
+	expired, err := testQueries.GetOwnershipTransferRequest(context.Background(), request.ID)
+	require.NoError(t, err)
+	require.Equal(t, OwnershipRequestExpired, expired.Status)
+
+	unchanged, err := testQueries.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Owner, unchanged.Owner)
+}
+
+func TestAcceptOwnershipTransferTxStale(t *testing.T) {
+	store := NewStore(testDB)
+	account := createTestAccount(t)
+	newOwner := util.RandomOwner()
+	request := createTestOwnershipRequest(t, account, newOwner, time.Now().Add(time.Hour))
+
+	_, err := testQueries.SetAccountOwner(context.Background(), SetAccountOwnerParams{ID: account.ID, Owner: util.RandomOwner()})
+	require.NoError(t, err)
+
+	_, err = store.AcceptOwnershipTransferTx(context.Background(), request.ID, newOwner)
+	require.ErrorIs(t, err, ErrOwnershipRequestStale)
+}
@@ -0,0 +1,97 @@
+package db
+
+import (
+	"context"
+	"time"
+)
+
+// AccountReconciliation compares the stored balance of an account with the sum of its entries.
+// A non-zero Discrepancy means the two drifted apart.
+type AccountReconciliation struct {
+	AccountID    int64 `json:"account_id"`
+	Balance      int64 `json:"balance"`
+	EntriesTotal int64 `json:"entries_total"`
+	Discrepancy  int64 `json:"discrepancy"`
+}
+
+// ReconcileAccount sums the entries of an account and compares them to its balance.
+// The account row is locked while summing, so transfers in flight cannot skew the result.
+func (store *SQLStore) ReconcileAccount(ctx context.Context, accountID int64) (AccountReconciliation, error) {
+	var result AccountReconciliation
+	err := store.execTx(ctx, "ReconcileAccount", func(q *Queries) error {
+		account, err := q.GetAccountForUpdate(ctx, accountID)
+		if err != nil {
+			return err
+		}
+
+		total, err := q.SumEntriesByAccount(ctx, accountID)
+		if err != nil {
+			return err
+		}
+
+		result = AccountReconciliation{
+			AccountID:    account.ID,
+			Balance:      account.Balance,
+			EntriesTotal: total,
+			Discrepancy:  account.Balance - total,
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+// AccountBalanceAsOf reconstructs the balance an account had at t from its entries, archived entries included.
+// The balance is zero before the account was created, and the current balance from now on.
+func (store *SQLStore) AccountBalanceAsOf(ctx context.Context, accountID int64, t time.Time) (int64, error) {
+	account, err := store.GetAccount(ctx, accountID)
+	if err != nil {
+		return 0, err
+	}
+
+	if t.Before(account.CreatedAt) {
+		return 0, nil
+	}
+	if !t.Before(time.Now()) {
+		return account.Balance, nil
+	}
+
+	return store.SumEntriesByAccountAsOf(ctx, SumEntriesByAccountAsOfParams{
+		AccountID: accountID,
+		AsOf:      t,
+	})
+}
+
+// EntryWithBalance is an entry along with the running balance of its account: the sum of the amounts
+// of every entry of the account up to and including it
+type EntryWithBalance struct {
+	Entry
+	RunningBalance int64 `json:"running_balance"`
+}
+
+// ListEntriesWithBalance returns a page of the entries of an account, archived entries included, oldest first,
+// each with its running balance. The running balance is computed by the database over all the entries before the page.
+func (store *SQLStore) ListEntriesWithBalance(ctx context.Context, accountID int64, limit, offset int32) ([]EntryWithBalance, error) {
+	rows, err := store.ListEntriesWithRunningBalance(ctx, ListEntriesWithRunningBalanceParams{
+		AccountID: accountID,
+		Limit:     limit,
+		Offset:    offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]EntryWithBalance, len(rows))
+	for i, row := range rows {
+		entries[i] = EntryWithBalance{
+			Entry: Entry{
+				ID:        row.ID,
+				AccountID: row.AccountID,
+				Amount:    row.Amount,
+				CreatedAt: row.CreatedAt,
+			},
+			RunningBalance: row.RunningBalance,
+		}
+	}
+	return entries, nil
+}
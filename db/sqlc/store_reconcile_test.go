@@ -0,0 +1,249 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/khuongkd/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconcileAccount(t *testing.T) {
+	store := NewStore(testDB)
+	source := fundTestAccount(t, createTestAccount(t), 100)
+	account, err := store.CreateAcount(context.Background(), CreateAcountParams{
+		Owner:    util.RandomOwner(),
+		Balance:  0,
+		Currency: source.Currency,
+	})
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: source.ID,
+		ToAccountID:   account.ID,
+		Amount:        40,
+	})
+	require.NoError(t, err)
+
+	result, err := store.ReconcileAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.ID, result.AccountID)
+	require.Equal(t, int64(40), result.Balance)
+	require.Equal(t, int64(40), result.EntriesTotal)
+	require.Zero(t, result.Discrepancy)
+
+	// corrupt the balance behind the ledger's back
+	_, err = store.UpdateAccount(context.Background(), UpdateAccountParams{ID: account.ID, Balance: 55})
+	require.NoError(t, err)
+
+	result, err = store.ReconcileAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(55), result.Balance)
+	require.Equal(t, int64(40), result.EntriesTotal)
+	require.Equal(t, int64(15), result.Discrepancy)
+}
+
+func TestReconcileAccountNotFound(t *testing.T) {
+	store := NewStore(testDB)
+
+	_, err := store.ReconcileAccount(context.Background(), -1)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestAccountBalanceAsOf(t *testing.T) {
+	store := NewStore(testDB)
+	account, err := store.CreateAcount(context.Background(), CreateAcountParams{
+		Owner:    util.RandomOwner(),
+		Balance:  0,
+		Currency: "USD",
+	})
+	require.NoError(t, err)
+
+	var points []time.Time
+	var balances []int64
+	var balance int64
+	for _, amount := range []int64{100, -30, 50} {
+		_, err := store.AdjustAccountBalanceTx(context.Background(), AdjustAccountBalanceTxParams{
+			AccountID:  account.ID,
+			Amount:     amount,
+			Reason:     "balance history",
+			AdjustedBy: "tester",
+		})
+		require.NoError(t, err)
+		balance += amount
+
+		time.Sleep(10 * time.Millisecond)
+		points = append(points, time.Now())
+		balances = append(balances, balance)
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	for i, point := range points {
+		got, err := store.AccountBalanceAsOf(context.Background(), account.ID, point)
+		require.NoError(t, err)
+		require.Equal(t, balances[i], got)
+	}
+
+	got, err := store.AccountBalanceAsOf(context.Background(), account.ID, account.CreatedAt.Add(-time.Hour))
+	require.NoError(t, err)
+	require.Zero(t, got)
+
+	_, err = store.UpdateAccount(context.Background(), UpdateAccountParams{ID: account.ID, Balance: 500})
+	require.NoError(t, err)
+	got, err = store.AccountBalanceAsOf(context.Background(), account.ID, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, int64(500), got)
+
+	_, err = store.AccountBalanceAsOf(context.Background(), -1, time.Now())
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestFindBalanceDiscrepancies(t *testing.T) {
+	store := NewStore(testDB)
+	source := fundTestAccount(t, createTestAccount(t), 100)
+	newAccount := func() Account {
+		account, err := store.CreateAcount(context.Background(), CreateAcountParams{
+			Owner:    util.RandomOwner(),
+			Balance:  0,
+			Currency: source.Currency,
+		})
+		require.NoError(t, err)
+
+		_, err = store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: source.ID,
+			ToAccountID:   account.ID,
+			Amount:        40,
+		})
+		require.NoError(t, err)
+		return account
+	}
+	consistent := newAccount()
+	drifted := newAccount()
+
+	// corrupt the balance behind the ledger's back
+	_, err := store.UpdateAccount(context.Background(), UpdateAccountParams{ID: drifted.ID, Balance: 55})
+	require.NoError(t, err)
+
+	// other tests leave discrepancies behind, such as the balances accounts are created with, so look at these two only
+	found := make(map[int64]FindBalanceDiscrepanciesRow)
+	for offset := int32(0); ; offset += 100 {
+		rows, err := store.FindBalanceDiscrepancies(context.Background(), FindBalanceDiscrepanciesParams{Limit: 100, Offset: offset})
+		require.NoError(t, err)
+		for _, row := range rows {
+			found[row.AccountID] = row
+		}
+		if len(rows) < 100 {
+			break
+		}
+	}
+
+	require.NotContains(t, found, consistent.ID)
+	require.Equal(t, FindBalanceDiscrepanciesRow{
+		AccountID:    drifted.ID,
+		Balance:      55,
+		EntriesTotal: 40,
+		Discrepancy:  15,
+	}, found[drifted.ID])
+}
+
+// treasuryTotals returns the treasury totals keyed by currency
+func treasuryTotals(t *testing.T, store Store) map[string]TotalSystemBalanceRow {
+	rows, err := store.TotalSystemBalance(context.Background())
+	require.NoError(t, err)
+	totals := make(map[string]TotalSystemBalanceRow)
+	for _, row := range rows {
+		totals[row.Currency] = row
+	}
+	return totals
+}
+
+func TestTotalSystemBalance(t *testing.T) {
+	store := NewStore(testDB)
+	// other tests leave accounts behind, so compare the totals before and after seeding
+	before := treasuryTotals(t, store)
+
+	newAccount := func(currency string, deposit int64) Account {
+		account, err := store.CreateAcount(context.Background(), CreateAcountParams{
+			Owner:    util.RandomOwner(),
+			Balance:  0,
+			Currency: currency,
+		})
+		require.NoError(t, err)
+		_, err = testQueries.CreateEntry(context.Background(), CreateEntryParams{AccountID: account.ID, Amount: deposit})
+		require.NoError(t, err)
+		return fundTestAccount(t, account, deposit)
+	}
+	usd1 := newAccount("USD", 100)
+	usd2 := newAccount("USD", 50)
+	newAccount("EUR", 70)
+
+	_, err := store.TransferTx(context.Background(), TransferTxParams{FromAccountID: usd1.ID, ToAccountID: usd2.ID, Amount: 30})
+	require.NoError(t, err)
+	// corrupt a balance behind the ledger's back
+	_, err = store.UpdateAccount(context.Background(), UpdateAccountParams{ID: usd2.ID, Balance: 90})
+	require.NoError(t, err)
+	// 40 USD become a sub-balance of 20 EUR, which counts towards EUR
+	_, err = store.ConvertSubBalanceTx(context.Background(), ConvertSubBalanceTxParams{
+		AccountID:    usd1.ID,
+		FromCurrency: "USD",
+		ToCurrency:   "EUR",
+		Amount:       40,
+		Rate:         big.NewRat(1, 2),
+	})
+	require.NoError(t, err)
+
+	after := treasuryTotals(t, store)
+	delta := func(currency string) TotalSystemBalanceRow {
+		return TotalSystemBalanceRow{
+			Currency:     currency,
+			Accounts:     after[currency].Accounts - before[currency].Accounts,
+			Balance:      after[currency].Balance - before[currency].Balance,
+			EntriesTotal: after[currency].EntriesTotal - before[currency].EntriesTotal,
+			Discrepancy:  after[currency].Discrepancy - before[currency].Discrepancy,
+		}
+	}
+	require.Equal(t, TotalSystemBalanceRow{Currency: "USD", Accounts: 2, Balance: 120, EntriesTotal: 110, Discrepancy: 10}, delta("USD"))
+	require.Equal(t, TotalSystemBalanceRow{Currency: "EUR", Accounts: 1, Balance: 90, EntriesTotal: 90}, delta("EUR"))
+}
+
+func TestListEntriesWithBalance(t *testing.T) {
+	store := NewStore(testDB)
+	account := createTestAccount(t)
+	var created []Entry
+	for _, amount := range []int64{10, -4, 7} {
+		entry, err := testQueries.CreateEntry(context.Background(), CreateEntryParams{AccountID: account.ID, Amount: amount})
+		require.NoError(t, err)
+		created = append(created, entry)
+	}
+	// entries of another account do not count towards the running balance
+	_, err := testQueries.CreateEntry(context.Background(), CreateEntryParams{AccountID: createTestAccount(t).ID, Amount: 100})
+	require.NoError(t, err)
+
+	// the first entry is archived, so that the running balance spans both tables
+	for {
+		n, err := testQueries.ArchiveEntries(context.Background(), ArchiveEntriesParams{Before: created[1].CreatedAt, BatchSize: 100})
+		require.NoError(t, err)
+		if n < 100 {
+			break
+		}
+	}
+
+	entries, err := store.ListEntriesWithBalance(context.Background(), account.ID, 2, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, created[0].ID, entries[0].ID)
+	require.Equal(t, int64(10), entries[0].RunningBalance)
+	require.Equal(t, created[1].ID, entries[1].ID)
+	require.Equal(t, int64(6), entries[1].RunningBalance)
+
+	// the balance of a later page still counts the entries of the earlier ones
+	entries, err = store.ListEntriesWithBalance(context.Background(), account.ID, 2, 2)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, created[2].ID, entries[0].ID)
+	require.Equal(t, int64(7), entries[0].Amount)
+	require.Equal(t, int64(13), entries[0].RunningBalance)
+}
@@ -0,0 +1,76 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// SimulatedTransfer is the outcome of one transfer of a simulation
+type SimulatedTransfer struct {
+	Index  int
+	Result TransferTxResult
+	// Err is why the transfer would fail; the transfers after it are simulated as if it had not been asked for
+	Err error
+}
+
+// ProjectedBalance is the balance an account would be left with once the simulated transfers were made
+type ProjectedBalance struct {
+	AccountID int64 `json:"account_id"`
+	Balance   int64 `json:"balance"`
+}
+
+// TransferSimulation is the outcome of a sequence of simulated transfers
+type TransferSimulation struct {
+	Transfers []SimulatedTransfer
+	// Balances lists every account the transfers touch, in account order
+	Balances []ProjectedBalance
+}
+
+// errSimulationDone rolls back the transaction of a simulation once it has run
+var errSimulationDone = errors.New("simulation done")
+
+// simulatedTransferSavepoint lets a failed step of a simulation be undone without aborting the whole transaction
+const simulatedTransferSavepoint = "simulated_transfer"
+
+// SimulateTransfersTx makes the transfers in order against the current balances within a single transaction,
+// then rolls it back, so that nothing is persisted. Unlike BatchTransferTx, a transfer that fails does not stop the others:
+// it is undone and reported, and the transfers after it see the balances as if it had not been asked for.
+// The IDs given to the simulated transfers and entries are never used, so later ones skip them.
+func (store *SQLStore) SimulateTransfersTx(ctx context.Context, params []TransferTxParams) (TransferSimulation, error) {
+	var simulation TransferSimulation
+	err := store.execTx(ctx, "SimulateTransfersTx", func(q *Queries) error {
+		simulation.Transfers = make([]SimulatedTransfer, len(params))
+		for i, p := range params {
+			if _, err := q.db.ExecContext(ctx, "SAVEPOINT "+simulatedTransferSavepoint); err != nil {
+				return err
+			}
+			result, err := store.transfer(ctx, q, p)
+			simulation.Transfers[i] = SimulatedTransfer{Index: i, Result: result, Err: constraintError(err)}
+			if err != nil {
+				if _, err := q.db.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+simulatedTransferSavepoint); err != nil {
+					return err
+				}
+			}
+			if _, err := q.db.ExecContext(ctx, "RELEASE SAVEPOINT "+simulatedTransferSavepoint); err != nil {
+				return err
+			}
+		}
+
+		for _, id := range batchAccountIDs(params) {
+			account, err := q.GetAccount(ctx, id)
+			if err == sql.ErrNoRows {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			simulation.Balances = append(simulation.Balances, ProjectedBalance{AccountID: id, Balance: account.Balance})
+		}
+		return errSimulationDone
+	})
+	if err != errSimulationDone {
+		return TransferSimulation{}, err
+	}
+	return simulation, nil
+}
@@ -0,0 +1,62 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/khuongkd/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimulateTransfersTx(t *testing.T) {
+	store := NewStore(testDB)
+	accounts := make([]Account, 3)
+	for i, balance := range []int64{100, 0, 0} {
+		account, err := store.CreateAcount(context.Background(), CreateAcountParams{
+			Owner:    util.RandomOwner(),
+			Balance:  balance,
+			Currency: "USD",
+		})
+		require.NoError(t, err)
+		accounts[i] = account
+	}
+	a1, a2, a3 := accounts[0].ID, accounts[1].ID, accounts[2].ID
+
+	simulation, err := store.SimulateTransfersTx(context.Background(), []TransferTxParams{
+		{FromAccountID: a1, ToAccountID: a2, Amount: 150},
+		// only goes through because the first transfer did not
+		{FromAccountID: a1, ToAccountID: a2, Amount: 100},
+		{FromAccountID: a2, ToAccountID: a3, Amount: 40},
+		{FromAccountID: a1, ToAccountID: a3, Amount: 1},
+	})
+	require.NoError(t, err)
+	require.Len(t, simulation.Transfers, 4)
+
+	require.ErrorIs(t, simulation.Transfers[0].Err, ErrInsufficientFunds)
+	require.NoError(t, simulation.Transfers[1].Err)
+	require.Equal(t, int64(0), simulation.Transfers[1].Result.FromAccount.Balance)
+	require.NoError(t, simulation.Transfers[2].Err)
+	require.Equal(t, int64(60), simulation.Transfers[2].Result.FromAccount.Balance)
+	require.ErrorIs(t, simulation.Transfers[3].Err, ErrInsufficientFunds)
+
+	require.Equal(t, []ProjectedBalance{
+		{AccountID: a1, Balance: 0},
+		{AccountID: a2, Balance: 60},
+		{AccountID: a3, Balance: 40},
+	}, simulation.Balances)
+
+	// nothing was persisted
+	for _, account := range accounts {
+		unchanged, err := store.GetAccount(context.Background(), account.ID)
+		require.NoError(t, err)
+		require.Equal(t, account.Balance, unchanged.Balance)
+
+		count, err := store.CountStatementEntries(context.Background(), CountStatementEntriesParams{
+			AccountID: account.ID,
+			ToTime:    time.Now().Add(time.Hour),
+		})
+		require.NoError(t, err)
+		require.Zero(t, count)
+	}
+}
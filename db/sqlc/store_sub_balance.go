@@ -0,0 +1,159 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/khuongkd/simplebank/util"
+)
+
+// ErrSameCurrency is returned when a conversion within an account has the same currency on both sides
+var ErrSameCurrency = errors.New("source and target currency must differ")
+
+// ConvertSubBalanceTxParams describes a conversion between two currencies held by one account.
+// The account's own currency is held in its balance, any other one in a sub-balance.
+type ConvertSubBalanceTxParams struct {
+	AccountID    int64
+	FromCurrency string
+	ToCurrency   string
+	// Amount is debited in FromCurrency
+	Amount int64
+	// Rate is the amount of ToCurrency one unit of FromCurrency buys
+	Rate *big.Rat
+	// RoundingMode rounds the converted amount; empty means half up
+	RoundingMode util.RoundingMode
+	// AllowedPairs limits the conversions that may be made; nil allows every pair
+	AllowedPairs util.CurrencyPairs
+}
+
+// CurrencyBalanceChange is the change a conversion made to one currency of an account
+type CurrencyBalanceChange struct {
+	Currency string `json:"currency"`
+	Amount   int64  `json:"amount"`
+	// Balance is the balance held in the currency after the change
+	Balance int64 `json:"balance"`
+	// EntryID is the entry recording the change: an entry of the account for its own currency, a sub-balance entry otherwise
+	EntryID int64 `json:"entry_id"`
+}
+
+// ConvertSubBalanceTxResult is the result of a conversion between two currencies of an account
+type ConvertSubBalanceTxResult struct {
+	Account      Account               `json:"account"`
+	From         CurrencyBalanceChange `json:"from"`
+	To           CurrencyBalanceChange `json:"to"`
+	RoundingMode util.RoundingMode     `json:"rounding_mode"`
+}
+
+// ConvertSubBalanceTx debits an amount in one currency of an account and credits it, converted at the given rate,
+// in another, recording an entry for each side. Money in the account's own currency moves through its balance and entries,
+// so that they keep adding up; money in any other currency moves through a sub-balance, which the first credit opens.
+// It returns ErrInsufficientFunds when the source currency holds less than the amount, held money excluded,
+// and ErrMinBalanceViolation when the debit would take the account below its own minimum balance.
+func (store *SQLStore) ConvertSubBalanceTx(ctx context.Context, params ConvertSubBalanceTxParams) (ConvertSubBalanceTxResult, error) {
+	var result ConvertSubBalanceTxResult
+	err := store.execTx(ctx, "ConvertSubBalanceTx", func(q *Queries) error {
+		account, err := q.GetAccountForUpdate(ctx, params.AccountID)
+		if err != nil {
+			return err
+		}
+		result.Account = account
+		if params.FromCurrency == params.ToCurrency {
+			return ErrSameCurrency
+		}
+		if !params.AllowedPairs.Allows(params.FromCurrency, params.ToCurrency) {
+			return fmt.Errorf("%w: %s to %s", ErrCurrencyPairNotAllowed, params.FromCurrency, params.ToCurrency)
+		}
+
+		result.RoundingMode = params.RoundingMode
+		if result.RoundingMode == "" {
+			result.RoundingMode = util.RoundHalfUp
+		}
+		converted, err := util.ConvertAmount(params.Amount, params.Rate, params.ToCurrency, result.RoundingMode)
+		if err != nil {
+			return err
+		}
+
+		if params.FromCurrency == account.Currency {
+			if account.Balance-params.Amount < account.HeldBalance {
+				return ErrInsufficientFunds
+			}
+			result.Account, result.From, err = changeOwnBalance(ctx, q, account.ID, account.Currency, -params.Amount)
+			if err != nil {
+				return err
+			}
+			if err := checkAccountMinBalance(result.Account); err != nil {
+				return err
+			}
+		} else {
+			subBalance, err := q.GetSubBalanceForUpdate(ctx, GetSubBalanceForUpdateParams{
+				AccountID: account.ID,
+				Currency:  params.FromCurrency,
+			})
+			if err != nil && err != sql.ErrNoRows {
+				return err
+			}
+			if subBalance.Balance < params.Amount {
+				return ErrInsufficientFunds
+			}
+			result.From, err = changeSubBalance(ctx, q, account.ID, params.FromCurrency, -params.Amount)
+			if err != nil {
+				return err
+			}
+		}
+
+		if params.ToCurrency == account.Currency {
+			result.Account, result.To, err = changeOwnBalance(ctx, q, account.ID, account.Currency, converted)
+			return err
+		}
+		result.To, err = changeSubBalance(ctx, q, account.ID, params.ToCurrency, converted)
+		return err
+	})
+
+	return result, err
+}
+
+// changeOwnBalance moves amount into the balance of the account, which is held in its own currency
+func changeOwnBalance(ctx context.Context, q *Queries, accountID int64, currency string, amount int64) (Account, CurrencyBalanceChange, error) {
+	entry, err := q.CreateEntry(ctx, CreateEntryParams{
+		AccountID: accountID,
+		Amount:    amount,
+	})
+	if err != nil {
+		return Account{}, CurrencyBalanceChange{}, err
+	}
+
+	account, err := q.AddAccountBalance(ctx, AddAccountBalanceParams{
+		ID:     accountID,
+		Amount: amount,
+	})
+	if err != nil {
+		return Account{}, CurrencyBalanceChange{}, err
+	}
+	return account, CurrencyBalanceChange{Currency: currency, Amount: amount, Balance: account.Balance, EntryID: entry.ID}, nil
+}
+
+// changeSubBalance moves amount into the sub-balance of the account in currency
+func changeSubBalance(ctx context.Context, q *Queries, accountID int64, currency string, amount int64) (CurrencyBalanceChange, error) {
+	subBalance, err := q.AddSubBalance(ctx, AddSubBalanceParams{
+		AccountID: accountID,
+		Currency:  currency,
+		Amount:    amount,
+	})
+	if err != nil {
+		return CurrencyBalanceChange{}, err
+	}
+
+	// the entry references the sub-balance, so it is created once the sub-balance exists
+	entry, err := q.CreateSubBalanceEntry(ctx, CreateSubBalanceEntryParams{
+		AccountID: accountID,
+		Currency:  currency,
+		Amount:    amount,
+	})
+	if err != nil {
+		return CurrencyBalanceChange{}, err
+	}
+	return CurrencyBalanceChange{Currency: currency, Amount: amount, Balance: subBalance.Balance, EntryID: entry.ID}, nil
+}
@@ -0,0 +1,106 @@
+package db
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/khuongkd/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertSubBalanceTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := fundTestAccount(t, createTestAccountFor(t, util.RandomOwner(), "USD"), 10000)
+
+	result, err := store.ConvertSubBalanceTx(context.Background(), ConvertSubBalanceTxParams{
+		AccountID:    account.ID,
+		FromCurrency: "USD",
+		ToCurrency:   "EUR",
+		Amount:       1000,
+		Rate:         big.NewRat(9, 10),
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(9000), result.Account.Balance)
+	require.Equal(t, int64(-1000), result.From.Amount)
+	require.Equal(t, int64(9000), result.From.Balance)
+	require.Equal(t, "EUR", result.To.Currency)
+	require.Equal(t, int64(900), result.To.Amount)
+	require.Equal(t, int64(900), result.To.Balance)
+
+	result, err = store.ConvertSubBalanceTx(context.Background(), ConvertSubBalanceTxParams{
+		AccountID:    account.ID,
+		FromCurrency: "EUR",
+		ToCurrency:   "USD",
+		Amount:       500,
+		Rate:         big.NewRat(11, 10),
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(400), result.From.Balance)
+	require.Equal(t, int64(550), result.To.Amount)
+	require.Equal(t, int64(9550), result.Account.Balance)
+
+	subBalances, err := store.ListSubBalances(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Len(t, subBalances, 1)
+	require.Equal(t, "EUR", subBalances[0].Currency)
+	require.Equal(t, int64(400), subBalances[0].Balance)
+
+	// the own balance still adds up from its entries
+	reconciled, err := store.ReconcileAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Zero(t, reconciled.Discrepancy)
+}
+
+func TestConvertSubBalanceTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+	account := fundTestAccount(t, createTestAccountFor(t, util.RandomOwner(), "USD"), 1000)
+
+	_, err := store.ConvertSubBalanceTx(context.Background(), ConvertSubBalanceTxParams{
+		AccountID:    account.ID,
+		FromCurrency: "USD",
+		ToCurrency:   "EUR",
+		Amount:       1000,
+		Rate:         big.NewRat(1, 1),
+	})
+	require.NoError(t, err)
+
+	// the source sub-balance can't go negative, and nothing changes when the debit is refused
+	_, err = store.ConvertSubBalanceTx(context.Background(), ConvertSubBalanceTxParams{
+		AccountID:    account.ID,
+		FromCurrency: "EUR",
+		ToCurrency:   "USD",
+		Amount:       1001,
+		Rate:         big.NewRat(1, 1),
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	subBalance, err := store.GetSubBalanceForUpdate(context.Background(), GetSubBalanceForUpdateParams{
+		AccountID: account.ID,
+		Currency:  "EUR",
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1000), subBalance.Balance)
+
+	unchanged, err := store.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Zero(t, unchanged.Balance)
+
+	// a currency never credited has no sub-balance to debit
+	_, err = store.ConvertSubBalanceTx(context.Background(), ConvertSubBalanceTxParams{
+		AccountID:    account.ID,
+		FromCurrency: "GBP",
+		ToCurrency:   "USD",
+		Amount:       1,
+		Rate:         big.NewRat(1, 1),
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// the check constraint backs the balance check up
+	_, err = store.AddSubBalance(context.Background(), AddSubBalanceParams{
+		AccountID: account.ID,
+		Currency:  "EUR",
+		Amount:    -1001,
+	})
+	require.ErrorIs(t, err, ErrConstraintViolation)
+}
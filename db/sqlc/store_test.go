@@ -3,14 +3,16 @@ package db
 import (
 	"context"
 	"testing"
+	"time"
 
+	"github.com/khuongkd/simplebank/util"
 	"github.com/stretchr/testify/require"
 )
 
 func TestTransferTx(t *testing.T) {
 	store := NewStore(testDB)
-	account1 := createTestAccount(t)
-	account2 := createTestAccount(t)
+	account1 := fundTestAccount(t, createTestAccount(t), 1000)
+	account2 := fundTestAccount(t, createTestAccount(t), 1000)
 
 	// run n concurrent transfer transactions
 	n := 5
@@ -21,7 +23,7 @@ func TestTransferTx(t *testing.T) {
 
 	for i := 0; i < n; i++ {
 		go func() {
-			result, err := store.TransferTx(context.Background(), CreateTransferParams{
+			result, err := store.TransferTx(context.Background(), TransferTxParams{
 				FromAccountID: account1.ID,
 				ToAccountID:   account2.ID,
 				Amount:        amount,
@@ -107,8 +109,8 @@ func TestTransferTx(t *testing.T) {
 
 func TestTransferTxDeadlock(t *testing.T) {
 	store := NewStore(testDB)
-	account1 := createTestAccount(t)
-	account2 := createTestAccount(t)
+	account1 := fundTestAccount(t, createTestAccount(t), 1000)
+	account2 := fundTestAccount(t, createTestAccount(t), 1000)
 
 	// run n concurrent transfer transactions
 	n := 50
@@ -127,7 +129,7 @@ func TestTransferTxDeadlock(t *testing.T) {
 		}
 
 		go func() {
-			_, err := store.TransferTx(context.Background(), CreateTransferParams{
+			_, err := store.TransferTx(context.Background(), TransferTxParams{
 				FromAccountID: fromAccount.ID,
 				ToAccountID:   toAccount.ID,
 				Amount:        amount,
@@ -153,3 +155,319 @@ func TestTransferTxDeadlock(t *testing.T) {
 	require.Equal(t, account1.Balance, updatedAccount1.Balance)
 	require.Equal(t, account2.Balance, updatedAccount2.Balance)
 }
+
+// fundTestAccount adds amount to the balance, so concurrent transfers cannot overdraw the account
+func fundTestAccount(t *testing.T, account Account, amount int64) Account {
+	account, err := testQueries.AddAccountBalance(context.Background(), AddAccountBalanceParams{
+		ID:     account.ID,
+		Amount: amount,
+	})
+	require.NoError(t, err)
+	return account
+}
+
+func createTestAccountFor(t *testing.T, owner, currency string) Account {
+	account, err := testQueries.CreateAcount(context.Background(), CreateAcountParams{
+		Owner:    owner,
+		Balance:  util.RandomMoneyForCurrency(currency) + util.CurrencyAmountStep(currency),
+		Currency: currency,
+	})
+	require.NoError(t, err)
+	return account
+}
+
+func TestTransferTxAccountPolicy(t *testing.T) {
+	store := NewStoreWithDialect(testDB, Postgres)
+	store.UseAccountPolicies(func() util.AccountPolicies {
+		return util.AccountPolicies{
+			AccountTypeChecking: {MinBalance: 100, AllowOverdraft: true},
+			AccountTypeSavings:  {MaxTransferAmount: 500, MinBalance: 1000},
+		}
+	})
+
+	accounts := make(map[string]Account)
+	for _, accountType := range []string{AccountTypeChecking, AccountTypeSavings} {
+		account, err := store.CreateAcount(context.Background(), CreateAcountParams{
+			Owner:       util.RandomOwner(),
+			Balance:     1500,
+			Currency:    "USD",
+			AccountType: accountType,
+		})
+		require.NoError(t, err)
+		require.Equal(t, accountType, account.AccountType)
+		accounts[accountType] = account
+	}
+	checking, savings := accounts[AccountTypeChecking], accounts[AccountTypeSavings]
+
+	transfer := func(from, to Account, amount int64) (TransferTxResult, error) {
+		return store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: from.ID,
+			ToAccountID:   to.ID,
+			Amount:        amount,
+		})
+	}
+
+	// savings caps each transfer and keeps its minimum balance
+	_, err := transfer(savings, checking, 600)
+	require.ErrorIs(t, err, ErrTransferLimitExceeded)
+	_, err = transfer(savings, checking, 500)
+	require.NoError(t, err)
+	_, err = transfer(savings, checking, 100)
+	require.ErrorIs(t, err, ErrBelowMinimumBalance)
+
+	unchanged, err := store.GetAccount(context.Background(), savings.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1000), unchanged.Balance)
+
+	// checking may go below its minimum since it allows overdrafts
+	result, err := transfer(checking, savings, 1950)
+	require.NoError(t, err)
+	require.Equal(t, int64(50), result.FromAccount.Balance)
+
+	// scheduled and captured transfers follow the policy too
+	scheduled, err := store.CreateScheduledTransfer(context.Background(), CreateScheduledTransferParams{
+		FromAccountID: savings.ID,
+		ToAccountID:   checking.ID,
+		Amount:        600,
+		Currency:      "USD",
+		RunAt:         time.Now(),
+	})
+	require.NoError(t, err)
+	_, err = store.ExecuteScheduledTransferTx(context.Background(), scheduled.ID)
+	require.ErrorIs(t, err, ErrTransferLimitExceeded)
+
+	hold := authorizeTestHold(t, store, result.ToAccount, checking, 600)
+	_, err = store.CaptureTransferTx(context.Background(), hold.Hold.ID)
+	require.ErrorIs(t, err, ErrTransferLimitExceeded)
+}
+
+func TestTransferTxMinBalance(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createTestAccountFor(t, util.RandomOwner(), "USD")
+	account2 := createTestAccountFor(t, util.RandomOwner(), "USD")
+
+	account1, err := store.SetAccountMinBalance(context.Background(), SetAccountMinBalanceParams{
+		ID:         account1.ID,
+		MinBalance: account1.Balance - 10,
+	})
+	require.NoError(t, err)
+
+	// leaving exactly the minimum is allowed
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+	require.Equal(t, account1.MinBalance, result.FromAccount.Balance)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1,
+	})
+	require.ErrorIs(t, err, ErrMinBalanceViolation)
+
+	unchanged, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.MinBalance, unchanged.Balance)
+
+	_, err = store.SetAccountMinBalance(context.Background(), SetAccountMinBalanceParams{ID: account1.ID, MinBalance: -1})
+	require.ErrorIs(t, err, ErrConstraintViolation)
+}
+
+func TestSweepOwnAccountsTx(t *testing.T) {
+	store := NewStore(testDB)
+	owner := util.RandomOwner()
+	account1 := createTestAccountFor(t, owner, "USD")
+	account2 := createTestAccountFor(t, owner, "USD")
+
+	result, err := store.SweepOwnAccountsTx(context.Background(), account1.ID, account2.ID, owner)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, result.Transfer.Amount)
+	require.Equal(t, -account1.Balance, result.FromEntry.Amount)
+	require.Equal(t, account1.Balance, result.ToEntry.Amount)
+	require.Zero(t, result.FromAccount.Balance)
+	require.Equal(t, account2.Balance+account1.Balance, result.ToAccount.Balance)
+
+	// the source is now empty, so a second sweep has nothing to move
+	_, err = store.SweepOwnAccountsTx(context.Background(), account1.ID, account2.ID, owner)
+	require.ErrorIs(t, err, ErrNothingToSweep)
+}
+
+func TestSweepOwnAccountsTxOtherOwner(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createTestAccountFor(t, util.RandomOwner(), "USD")
+	account2 := createTestAccountFor(t, util.RandomOwner(), "USD")
+
+	_, err := store.SweepOwnAccountsTx(context.Background(), account1.ID, account2.ID, account1.Owner)
+	require.ErrorIs(t, err, ErrAccountOwnerMismatch)
+
+	updatedAccount1, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updatedAccount1.Balance)
+}
+
+func TestSweepOwnAccountsTxCurrencyMismatch(t *testing.T) {
+	store := NewStore(testDB)
+	owner := util.RandomOwner()
+	account1 := createTestAccountFor(t, owner, "USD")
+	account2 := createTestAccountFor(t, owner, "EUR")
+
+	_, err := store.SweepOwnAccountsTx(context.Background(), account1.ID, account2.ID, owner)
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	updatedAccount1, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updatedAccount1.Balance)
+}
+
+func TestSweepOwnAccountsTxFrozen(t *testing.T) {
+	store := NewStore(testDB)
+	owner := util.RandomOwner()
+	account1 := createTestAccountFor(t, owner, "USD")
+	account2 := createTestAccountFor(t, owner, "USD")
+
+	_, err := store.PatchAccount(context.Background(), PatchAccountParams{ID: account1.ID, SetFrozen: true, Frozen: true, FreezeReason: "kyc"})
+	require.NoError(t, err)
+
+	_, err = store.SweepOwnAccountsTx(context.Background(), account1.ID, account2.ID, owner)
+	require.ErrorIs(t, err, ErrAccountFrozen)
+
+	updatedAccount1, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updatedAccount1.Balance)
+}
+
+func TestSweepOwnAccountsTxPolicy(t *testing.T) {
+	store := NewStoreWithDialect(testDB, Postgres)
+	store.UseAccountPolicies(func() util.AccountPolicies {
+		return util.AccountPolicies{
+			AccountTypeSavings: {MinBalance: 1000},
+		}
+	})
+	owner := util.RandomOwner()
+	savings, err := store.CreateAcount(context.Background(), CreateAcountParams{
+		Owner:       owner,
+		Balance:     1500,
+		Currency:    "USD",
+		AccountType: AccountTypeSavings,
+	})
+	require.NoError(t, err)
+	_, err = store.SetAccountMinBalance(context.Background(), SetAccountMinBalanceParams{ID: savings.ID, MinBalance: 200})
+	require.NoError(t, err)
+	checking := createTestAccountFor(t, owner, "USD")
+
+	// the minimum of the policy is above the one of the account, so it is what stays behind
+	result, err := store.SweepOwnAccountsTx(context.Background(), savings.ID, checking.ID, owner)
+	require.NoError(t, err)
+	require.Equal(t, int64(500), result.Transfer.Amount)
+	require.Equal(t, int64(1000), result.FromAccount.Balance)
+
+	_, err = store.SweepOwnAccountsTx(context.Background(), savings.ID, checking.ID, owner)
+	require.ErrorIs(t, err, ErrNothingToSweep)
+}
+
+func TestTransferTxWhitelist(t *testing.T) {
+	store := NewStore(testDB)
+	owner := util.RandomOwner()
+	account1 := createTestAccountFor(t, owner, "USD")
+	account2 := createTestAccountFor(t, owner, "USD")
+	account3 := createTestAccountFor(t, owner, "USD")
+
+	_, err := store.SetAccountWhitelistEnabled(context.Background(), SetAccountWhitelistEnabledParams{
+		ID:      account1.ID,
+		Enabled: true,
+	})
+	require.NoError(t, err)
+	err = store.AddWhitelistedDestination(context.Background(), AddWhitelistedDestinationParams{
+		AccountID:            account1.ID,
+		DestinationAccountID: account2.ID,
+	})
+	require.NoError(t, err)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1,
+	})
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance-1, result.FromAccount.Balance)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account3.ID,
+		Amount:        1,
+	})
+	require.ErrorIs(t, err, ErrDestinationNotWhitelisted)
+
+	updatedAccount3, err := store.GetAccount(context.Background(), account3.ID)
+	require.NoError(t, err)
+	require.Equal(t, account3.Balance, updatedAccount3.Balance)
+}
+
+func TestTransferTxFee(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createTestAccountFor(t, util.RandomOwner(), "USD")
+	account2 := createTestAccountFor(t, util.RandomOwner(), "USD")
+	feeAccount := createTestAccountFor(t, util.RandomOwner(), "USD")
+
+	account1, err := store.AddAccountBalance(context.Background(), AddAccountBalanceParams{ID: account1.ID, Amount: 100})
+	require.NoError(t, err)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        50,
+		Fee:           5,
+		FeeAccountID:  feeAccount.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(5), result.Transfer.Fee)
+	require.Equal(t, account1.Balance-55, result.FromAccount.Balance)
+	require.Equal(t, account2.Balance+50, result.ToAccount.Balance)
+	require.NotNil(t, result.FeeEntry)
+	require.Equal(t, account1.ID, result.FeeEntry.AccountID)
+	require.Equal(t, int64(-5), result.FeeEntry.Amount)
+
+	updatedFeeAccount, err := store.GetAccount(context.Background(), feeAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, feeAccount.Balance+5, updatedFeeAccount.Balance)
+
+	fees, err := store.SumTransferFeesByAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), fees)
+
+	// the balance left covers the amount but not the fee on top of it
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        result.FromAccount.Balance,
+		Fee:           5,
+		FeeAccountID:  feeAccount.ID,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	unchanged, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, result.FromAccount.Balance, unchanged.Balance)
+}
+
+func TestTransferTxFeeAccountCurrency(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createTestAccountFor(t, util.RandomOwner(), "USD")
+	account2 := createTestAccountFor(t, util.RandomOwner(), "USD")
+	feeAccount := createTestAccountFor(t, util.RandomOwner(), "EUR")
+
+	_, err := store.AddAccountBalance(context.Background(), AddAccountBalanceParams{ID: account1.ID, Amount: 100})
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        50,
+		Fee:           5,
+		FeeAccountID:  feeAccount.ID,
+	})
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+}
@@ -0,0 +1,119 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.13.0
+// source: sub_balance.sql
+
+package db
+
+import (
+	"context"
+)
+
+const addSubBalance = `-- name: AddSubBalance :one
+INSERT INTO account_sub_balances (account_id, currency, balance)
+VALUES ($1, $2, $3)
+ON CONFLICT (account_id, currency)
+DO UPDATE SET balance = account_sub_balances.balance + EXCLUDED.balance, updated_at = now()
+RETURNING account_id, currency, balance, updated_at
+`
+
+type AddSubBalanceParams struct {
+	AccountID int64  `json:"account_id"`
+	Currency  string `json:"currency"`
+	Amount    int64  `json:"amount"`
+}
+
+// Credits or debits the sub-balance, opening it on the first credit.
+func (q *Queries) AddSubBalance(ctx context.Context, arg AddSubBalanceParams) (AccountSubBalance, error) {
+	row := q.db.QueryRowContext(ctx, addSubBalance, arg.AccountID, arg.Currency, arg.Amount)
+	var i AccountSubBalance
+	err := row.Scan(
+		&i.AccountID,
+		&i.Currency,
+		&i.Balance,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createSubBalanceEntry = `-- name: CreateSubBalanceEntry :one
+INSERT INTO sub_balance_entries (account_id, currency, amount)
+VALUES ($1, $2, $3)
+RETURNING id, account_id, currency, amount, created_at
+`
+
+type CreateSubBalanceEntryParams struct {
+	AccountID int64  `json:"account_id"`
+	Currency  string `json:"currency"`
+	Amount    int64  `json:"amount"`
+}
+
+func (q *Queries) CreateSubBalanceEntry(ctx context.Context, arg CreateSubBalanceEntryParams) (SubBalanceEntry, error) {
+	row := q.db.QueryRowContext(ctx, createSubBalanceEntry, arg.AccountID, arg.Currency, arg.Amount)
+	var i SubBalanceEntry
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Currency,
+		&i.Amount,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getSubBalanceForUpdate = `-- name: GetSubBalanceForUpdate :one
+SELECT account_id, currency, balance, updated_at FROM account_sub_balances
+WHERE account_id = $1 AND currency = $2
+FOR NO KEY UPDATE
+`
+
+type GetSubBalanceForUpdateParams struct {
+	AccountID int64  `json:"account_id"`
+	Currency  string `json:"currency"`
+}
+
+func (q *Queries) GetSubBalanceForUpdate(ctx context.Context, arg GetSubBalanceForUpdateParams) (AccountSubBalance, error) {
+	row := q.db.QueryRowContext(ctx, getSubBalanceForUpdate, arg.AccountID, arg.Currency)
+	var i AccountSubBalance
+	err := row.Scan(
+		&i.AccountID,
+		&i.Currency,
+		&i.Balance,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listSubBalances = `-- name: ListSubBalances :many
+SELECT account_id, currency, balance, updated_at FROM account_sub_balances
+WHERE account_id = $1
+ORDER BY currency
+`
+
+func (q *Queries) ListSubBalances(ctx context.Context, accountID int64) ([]AccountSubBalance, error) {
+	rows, err := q.db.QueryContext(ctx, listSubBalances, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AccountSubBalance
+	for rows.Next() {
+		var i AccountSubBalance
+		if err := rows.Scan(
+			&i.AccountID,
+			&i.Currency,
+			&i.Balance,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -7,22 +7,62 @@ package db
 
 import (
 	"context"
+	"time"
+
+	"github.com/lib/pq"
 )
 
+const countRecentTransfersFromAccount = `-- name: CountRecentTransfersFromAccount :one
+SELECT COUNT(*) FROM transfers
+WHERE from_account_id = $1 AND created_at >= $2
+`
+
+type CountRecentTransfersFromAccountParams struct {
+	FromAccountID int64     `json:"from_account_id"`
+	Since         time.Time `json:"since"`
+}
+
+func (q *Queries) CountRecentTransfersFromAccount(ctx context.Context, arg CountRecentTransfersFromAccountParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countRecentTransfersFromAccount, arg.FromAccountID, arg.Since)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createTransfer = `-- name: CreateTransfer :one
-INSERT INTO transfers(from_account_id, to_account_id, amount)
-VALUES ($1, $2, $3)
-RETURNING id, from_account_id, to_account_id, amount, created_at
+INSERT INTO transfers(
+  from_account_id, to_account_id, amount, fee, external_ref, category,
+  currency, from_account_number, to_account_number
+)
+VALUES (
+  $1, $2, $3, $4, $5, $6,
+  (SELECT a.currency FROM accounts a WHERE a.id = $1),
+  (SELECT a.number FROM accounts a WHERE a.id = $1),
+  (SELECT a.number FROM accounts a WHERE a.id = $2)
+)
+RETURNING id, from_account_id, to_account_id, amount, created_at, fee, external_ref, category, currency, from_account_number, to_account_number
 `
 
 type CreateTransferParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Amount        int64 `json:"amount"`
+	FromAccountID int64   `json:"from_account_id"`
+	ToAccountID   int64   `json:"to_account_id"`
+	Amount        int64   `json:"amount"`
+	Fee           int64   `json:"fee"`
+	ExternalRef   *string `json:"external_ref"`
+	Category      *string `json:"category"`
 }
 
+// The currency and account numbers are copied from the accounts as they are now,
+// so that receipts keep showing them after an account is converted or renumbered.
 func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
-	row := q.db.QueryRowContext(ctx, createTransfer, arg.FromAccountID, arg.ToAccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.Fee,
+		arg.ExternalRef,
+		arg.Category,
+	)
 	var i Transfer
 	err := row.Scan(
 		&i.ID,
@@ -30,6 +70,12 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.Fee,
+		&i.ExternalRef,
+		&i.Category,
+		&i.Currency,
+		&i.FromAccountNumber,
+		&i.ToAccountNumber,
 	)
 	return i, err
 }
@@ -43,8 +89,87 @@ func (q *Queries) DeleteTransfer(ctx context.Context, id int64) error {
 	return err
 }
 
+const findCounterpartyAccounts = `-- name: FindCounterpartyAccounts :many
+SELECT
+  accounts.id,
+  accounts.owner,
+  accounts.currency,
+  accounts.frozen,
+  (CASE
+    WHEN bool_and(transfers.from_account_id = $1) THEN 'outgoing'
+    WHEN bool_and(transfers.to_account_id = $1) THEN 'incoming'
+    ELSE 'both'
+  END)::varchar AS direction,
+  COUNT(*) AS transfers,
+  COALESCE(SUM(transfers.amount) FILTER (WHERE transfers.from_account_id = $1), 0)::bigint AS amount_sent,
+  COALESCE(SUM(transfers.amount) FILTER (WHERE transfers.to_account_id = $1), 0)::bigint AS amount_received
+FROM transfers
+JOIN accounts ON accounts.id = (CASE
+  WHEN transfers.from_account_id = $1 THEN transfers.to_account_id
+  ELSE transfers.from_account_id
+END)
+WHERE (transfers.from_account_id = $1 OR transfers.to_account_id = $1)
+  AND accounts.id <> $1
+GROUP BY accounts.id
+ORDER BY accounts.id
+LIMIT $3
+OFFSET $2
+`
+
+type FindCounterpartyAccountsParams struct {
+	AccountID int64 `json:"account_id"`
+	Offset    int32 `json:"offset"`
+	Limit     int32 `json:"limit"`
+}
+
+type FindCounterpartyAccountsRow struct {
+	ID             int64  `json:"id"`
+	Owner          string `json:"owner"`
+	Currency       string `json:"currency"`
+	Frozen         bool   `json:"frozen"`
+	Direction      string `json:"direction"`
+	Transfers      int64  `json:"transfers"`
+	AmountSent     int64  `json:"amount_sent"`
+	AmountReceived int64  `json:"amount_received"`
+}
+
+// Lists every other account that sent money to or received money from the account, once each, in ID order.
+// Direction is outgoing when the account only sent money to the counterparty, incoming when it only received from it,
+// and both otherwise.
+func (q *Queries) FindCounterpartyAccounts(ctx context.Context, arg FindCounterpartyAccountsParams) ([]FindCounterpartyAccountsRow, error) {
+	rows, err := q.db.QueryContext(ctx, findCounterpartyAccounts, arg.AccountID, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FindCounterpartyAccountsRow
+	for rows.Next() {
+		var i FindCounterpartyAccountsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Currency,
+			&i.Frozen,
+			&i.Direction,
+			&i.Transfers,
+			&i.AmountSent,
+			&i.AmountReceived,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getTransfer = `-- name: GetTransfer :one
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers WHERE id = $1
+SELECT id, from_account_id, to_account_id, amount, created_at, fee, external_ref, category, currency, from_account_number, to_account_number FROM transfers WHERE id = $1
 `
 
 func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
@@ -56,12 +181,71 @@ func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.Fee,
+		&i.ExternalRef,
+		&i.Category,
+		&i.Currency,
+		&i.FromAccountNumber,
+		&i.ToAccountNumber,
 	)
 	return i, err
 }
 
+const getTransfersByIDs = `-- name: GetTransfersByIDs :many
+SELECT id, from_account_id, to_account_id, amount, created_at, fee, external_ref, category, currency, from_account_number, to_account_number FROM transfers
+WHERE id = ANY($1::bigint[])
+  AND EXISTS (
+    SELECT 1 FROM accounts
+    WHERE accounts.owner = $2
+      AND accounts.id IN (transfers.from_account_id, transfers.to_account_id)
+  )
+ORDER BY id
+`
+
+type GetTransfersByIDsParams struct {
+	Ids   []int64 `json:"ids"`
+	Owner string  `json:"owner"`
+}
+
+// Returns the transfers among the IDs that move money out of or into an account of the owner, in ID order.
+// IDs of transfers the owner is not party to, or that do not exist, are left out.
+func (q *Queries) GetTransfersByIDs(ctx context.Context, arg GetTransfersByIDsParams) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, getTransfersByIDs, pq.Array(arg.Ids), arg.Owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transfer
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.Fee,
+			&i.ExternalRef,
+			&i.Category,
+			&i.Currency,
+			&i.FromAccountNumber,
+			&i.ToAccountNumber,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listTransfers = `-- name: ListTransfers :many
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers ORDER BY id LIMIT $1 OFFSET $2
+SELECT id, from_account_id, to_account_id, amount, created_at, fee, external_ref, category, currency, from_account_number, to_account_number FROM transfers ORDER BY id LIMIT $1 OFFSET $2
 `
 
 type ListTransfersParams struct {
@@ -84,6 +268,12 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.Fee,
+			&i.ExternalRef,
+			&i.Category,
+			&i.Currency,
+			&i.FromAccountNumber,
+			&i.ToAccountNumber,
 		); err != nil {
 			return nil, err
 		}
@@ -98,10 +288,98 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 	return items, nil
 }
 
+const recentSimilarTransferExists = `-- name: RecentSimilarTransferExists :one
+SELECT EXISTS(
+  SELECT 1 FROM transfers
+  WHERE from_account_id = $1 AND to_account_id = $2 AND amount = $3
+    AND created_at >= $4
+) AS found
+`
+
+type RecentSimilarTransferExistsParams struct {
+	FromAccountID int64     `json:"from_account_id"`
+	ToAccountID   int64     `json:"to_account_id"`
+	Amount        int64     `json:"amount"`
+	Since         time.Time `json:"since"`
+}
+
+func (q *Queries) RecentSimilarTransferExists(ctx context.Context, arg RecentSimilarTransferExistsParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, recentSimilarTransferExists,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.Since,
+	)
+	var found bool
+	err := row.Scan(&found)
+	return found, err
+}
+
+const spendingByCategory = `-- name: SpendingByCategory :many
+SELECT
+  COALESCE(category, '')::varchar AS category,
+  SUM(amount)::bigint AS total,
+  COUNT(*) AS transfers
+FROM transfers
+WHERE from_account_id = $1 AND created_at >= $2 AND created_at < $3
+GROUP BY COALESCE(category, '')
+ORDER BY total DESC, category
+`
+
+type SpendingByCategoryParams struct {
+	AccountID int64     `json:"account_id"`
+	FromTime  time.Time `json:"from_time"`
+	ToTime    time.Time `json:"to_time"`
+}
+
+type SpendingByCategoryRow struct {
+	Category  string `json:"category"`
+	Total     int64  `json:"total"`
+	Transfers int64  `json:"transfers"`
+}
+
+// Sums the transfers out of an account within [from_time, to_time) per category, largest first.
+// Transfers without a category are summed under an empty one.
+func (q *Queries) SpendingByCategory(ctx context.Context, arg SpendingByCategoryParams) ([]SpendingByCategoryRow, error) {
+	rows, err := q.db.QueryContext(ctx, spendingByCategory, arg.AccountID, arg.FromTime, arg.ToTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SpendingByCategoryRow
+	for rows.Next() {
+		var i SpendingByCategoryRow
+		if err := rows.Scan(&i.Category, &i.Total, &i.Transfers); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const sumTransferFeesByAccount = `-- name: SumTransferFeesByAccount :one
+SELECT COALESCE(SUM(fee), 0)::bigint AS total
+FROM transfers
+WHERE from_account_id = $1
+`
+
+func (q *Queries) SumTransferFeesByAccount(ctx context.Context, fromAccountID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, sumTransferFeesByAccount, fromAccountID)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
+
 const updateTransfer = `-- name: UpdateTransfer :one
 UPDATE transfers SET amount = $1, from_account_id = $2, to_account_id = $3
 WHERE id = $4
-RETURNING id, from_account_id, to_account_id, amount, created_at
+RETURNING id, from_account_id, to_account_id, amount, created_at, fee, external_ref, category, currency, from_account_number, to_account_number
 `
 
 type UpdateTransferParams struct {
@@ -125,6 +403,12 @@ func (q *Queries) UpdateTransfer(ctx context.Context, arg UpdateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.Fee,
+		&i.ExternalRef,
+		&i.Category,
+		&i.Currency,
+		&i.FromAccountNumber,
+		&i.ToAccountNumber,
 	)
 	return i, err
 }
@@ -0,0 +1,162 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.13.0
+// source: transfer_confirmation.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const completeTransferConfirmation = `-- name: CompleteTransferConfirmation :one
+UPDATE transfer_confirmations SET status = 'confirmed', transfer_id = $1
+WHERE id = $2 AND status = 'pending_confirmation'
+RETURNING id, from_account_id, to_account_id, amount, currency, external_ref, category, token_hash, status, transfer_id, expires_at, created_at
+`
+
+type CompleteTransferConfirmationParams struct {
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	ID         int64         `json:"id"`
+}
+
+func (q *Queries) CompleteTransferConfirmation(ctx context.Context, arg CompleteTransferConfirmationParams) (TransferConfirmation, error) {
+	row := q.db.QueryRowContext(ctx, completeTransferConfirmation, arg.TransferID, arg.ID)
+	var i TransferConfirmation
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.Currency,
+		&i.ExternalRef,
+		&i.Category,
+		&i.TokenHash,
+		&i.Status,
+		&i.TransferID,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const countPendingTransferConfirmationsByAccount = `-- name: CountPendingTransferConfirmationsByAccount :one
+SELECT count(*) FROM transfer_confirmations
+WHERE (from_account_id = $1 OR to_account_id = $1)
+  AND status = 'pending_confirmation'
+  AND expires_at > $2
+`
+
+type CountPendingTransferConfirmationsByAccountParams struct {
+	AccountID int64     `json:"account_id"`
+	Now       time.Time `json:"now"`
+}
+
+func (q *Queries) CountPendingTransferConfirmationsByAccount(ctx context.Context, arg CountPendingTransferConfirmationsByAccountParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countPendingTransferConfirmationsByAccount, arg.AccountID, arg.Now)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createTransferConfirmation = `-- name: CreateTransferConfirmation :one
+INSERT INTO transfer_confirmations (
+  from_account_id, to_account_id, amount, currency, external_ref, category, token_hash, expires_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8
+)
+RETURNING id, from_account_id, to_account_id, amount, currency, external_ref, category, token_hash, status, transfer_id, expires_at, created_at
+`
+
+type CreateTransferConfirmationParams struct {
+	FromAccountID int64     `json:"from_account_id"`
+	ToAccountID   int64     `json:"to_account_id"`
+	Amount        int64     `json:"amount"`
+	Currency      string    `json:"currency"`
+	ExternalRef   string    `json:"external_ref"`
+	Category      string    `json:"category"`
+	TokenHash     string    `json:"token_hash"`
+	ExpiresAt     time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateTransferConfirmation(ctx context.Context, arg CreateTransferConfirmationParams) (TransferConfirmation, error) {
+	row := q.db.QueryRowContext(ctx, createTransferConfirmation,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.Currency,
+		arg.ExternalRef,
+		arg.Category,
+		arg.TokenHash,
+		arg.ExpiresAt,
+	)
+	var i TransferConfirmation
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.Currency,
+		&i.ExternalRef,
+		&i.Category,
+		&i.TokenHash,
+		&i.Status,
+		&i.TransferID,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getTransferConfirmation = `-- name: GetTransferConfirmation :one
+SELECT id, from_account_id, to_account_id, amount, currency, external_ref, category, token_hash, status, transfer_id, expires_at, created_at FROM transfer_confirmations
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetTransferConfirmation(ctx context.Context, id int64) (TransferConfirmation, error) {
+	row := q.db.QueryRowContext(ctx, getTransferConfirmation, id)
+	var i TransferConfirmation
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.Currency,
+		&i.ExternalRef,
+		&i.Category,
+		&i.TokenHash,
+		&i.Status,
+		&i.TransferID,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getTransferConfirmationForUpdate = `-- name: GetTransferConfirmationForUpdate :one
+SELECT id, from_account_id, to_account_id, amount, currency, external_ref, category, token_hash, status, transfer_id, expires_at, created_at FROM transfer_confirmations
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetTransferConfirmationForUpdate(ctx context.Context, id int64) (TransferConfirmation, error) {
+	row := q.db.QueryRowContext(ctx, getTransferConfirmationForUpdate, id)
+	var i TransferConfirmation
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.Currency,
+		&i.ExternalRef,
+		&i.Category,
+		&i.TokenHash,
+		&i.Status,
+		&i.TransferID,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
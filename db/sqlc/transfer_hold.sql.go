@@ -0,0 +1,155 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.13.0
+// source: transfer_hold.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+const addAccountHeldBalance = `-- name: AddAccountHeldBalance :one
+UPDATE accounts SET held_balance = held_balance + $1
+WHERE id = $2
+RETURNING id, owner, balance, currency, created_at, whitelist_enabled, held_balance, number, account_type, min_balance, nickname, metadata, frozen, freeze_reason
+`
+
+type AddAccountHeldBalanceParams struct {
+	Amount int64 `json:"amount"`
+	ID     int64 `json:"id"`
+}
+
+func (q *Queries) AddAccountHeldBalance(ctx context.Context, arg AddAccountHeldBalanceParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, addAccountHeldBalance, arg.Amount, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.WhitelistEnabled,
+		&i.HeldBalance,
+		&i.Number,
+		&i.AccountType,
+		&i.MinBalance,
+		&i.Nickname,
+		&i.Metadata,
+		&i.Frozen,
+		&i.FreezeReason,
+	)
+	return i, err
+}
+
+const countAuthorizedHoldsByAccount = `-- name: CountAuthorizedHoldsByAccount :one
+SELECT count(*) FROM transfer_holds
+WHERE (from_account_id = $1 OR to_account_id = $1)
+  AND status = 'authorized'
+`
+
+func (q *Queries) CountAuthorizedHoldsByAccount(ctx context.Context, accountID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countAuthorizedHoldsByAccount, accountID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createTransferHold = `-- name: CreateTransferHold :one
+INSERT INTO transfer_holds (
+  from_account_id, to_account_id, amount
+) VALUES (
+  $1, $2, $3
+)
+RETURNING id, from_account_id, to_account_id, amount, status, transfer_id, created_at
+`
+
+type CreateTransferHoldParams struct {
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID   int64 `json:"to_account_id"`
+	Amount        int64 `json:"amount"`
+}
+
+func (q *Queries) CreateTransferHold(ctx context.Context, arg CreateTransferHoldParams) (TransferHold, error) {
+	row := q.db.QueryRowContext(ctx, createTransferHold, arg.FromAccountID, arg.ToAccountID, arg.Amount)
+	var i TransferHold
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.Status,
+		&i.TransferID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getTransferHold = `-- name: GetTransferHold :one
+SELECT id, from_account_id, to_account_id, amount, status, transfer_id, created_at FROM transfer_holds
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetTransferHold(ctx context.Context, id int64) (TransferHold, error) {
+	row := q.db.QueryRowContext(ctx, getTransferHold, id)
+	var i TransferHold
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.Status,
+		&i.TransferID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getTransferHoldForUpdate = `-- name: GetTransferHoldForUpdate :one
+SELECT id, from_account_id, to_account_id, amount, status, transfer_id, created_at FROM transfer_holds
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetTransferHoldForUpdate(ctx context.Context, id int64) (TransferHold, error) {
+	row := q.db.QueryRowContext(ctx, getTransferHoldForUpdate, id)
+	var i TransferHold
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.Status,
+		&i.TransferID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const settleTransferHold = `-- name: SettleTransferHold :one
+UPDATE transfer_holds SET status = $1, transfer_id = $2
+WHERE id = $3 AND status = 'authorized'
+RETURNING id, from_account_id, to_account_id, amount, status, transfer_id, created_at
+`
+
+type SettleTransferHoldParams struct {
+	Status     string        `json:"status"`
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	ID         int64         `json:"id"`
+}
+
+func (q *Queries) SettleTransferHold(ctx context.Context, arg SettleTransferHoldParams) (TransferHold, error) {
+	row := q.db.QueryRowContext(ctx, settleTransferHold, arg.Status, arg.TransferID, arg.ID)
+	var i TransferHold
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.Status,
+		&i.TransferID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"math/big"
 	"testing"
 	"time"
 
@@ -15,7 +16,7 @@ func createTestTransfer(t *testing.T) Transfer {
 	arg := CreateTransferParams{
 		FromAccountID: fromAccount.ID,
 		ToAccountID:   toAccount.ID,
-		Amount:        util.RandomMoney(),
+		Amount:        util.RandomInt(1, 1000),
 	}
 
 	transfer, err := testQueries.CreateTransfer(context.Background(), arg)
@@ -35,6 +36,46 @@ func TestCreateTransfer(t *testing.T) {
 	createTestTransfer(t)
 }
 
+func TestCreateTransferKeepsAccountsAsTheyWere(t *testing.T) {
+	store := NewStore(testDB)
+	create := func() Account {
+		account, err := store.CreateAcount(context.Background(), CreateAcountParams{
+			Owner:             util.RandomOwner(),
+			Currency:          "USD",
+			NumberPerCurrency: true,
+		})
+		require.NoError(t, err)
+		return account
+	}
+	from := create()
+	to := create()
+
+	transfer, err := testQueries.CreateTransfer(context.Background(), CreateTransferParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "USD", transfer.Currency)
+	require.Equal(t, from.Number, transfer.FromAccountNumber)
+	require.Equal(t, to.Number, transfer.ToAccountNumber)
+
+	// converting the source account changes its currency and number, not those of its transfers
+	_, err = store.ConvertAccountCurrencyTx(context.Background(), ConvertAccountCurrencyTxParams{
+		AccountID:         from.ID,
+		Currency:          "EUR",
+		Rate:              big.NewRat(9, 10),
+		NumberPerCurrency: true,
+	})
+	require.NoError(t, err)
+
+	got, err := testQueries.GetTransfer(context.Background(), transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, "USD", got.Currency)
+	require.Equal(t, from.Number, got.FromAccountNumber)
+	require.Equal(t, to.Number, got.ToAccountNumber)
+}
+
 func TestUpdateTransfer(t *testing.T) {
 	transfer1 := createTestTransfer(t)
 	fromAccount := createTestAccount(t)
@@ -42,7 +83,7 @@ func TestUpdateTransfer(t *testing.T) {
 	arg := UpdateTransferParams{
 		FromAccountID: fromAccount.ID,
 		ToAccountID:   toAccount.ID,
-		Amount:        util.RandomMoney(),
+		Amount:        util.RandomInt(1, 1000),
 		ID:            transfer1.ID,
 	}
 
@@ -95,3 +136,182 @@ func TestListTransfers(t *testing.T) {
 		require.NotEmpty(t, transfer)
 	}
 }
+
+func TestRecentSimilarTransferExists(t *testing.T) {
+	transfer := createTestTransfer(t)
+	arg := RecentSimilarTransferExistsParams{
+		FromAccountID: transfer.FromAccountID,
+		ToAccountID:   transfer.ToAccountID,
+		Amount:        transfer.Amount,
+		Since:         transfer.CreatedAt.Add(-time.Minute),
+	}
+
+	found, err := testQueries.RecentSimilarTransferExists(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, found)
+
+	outsideWindow := arg
+	outsideWindow.Since = transfer.CreatedAt.Add(time.Minute)
+	found, err = testQueries.RecentSimilarTransferExists(context.Background(), outsideWindow)
+	require.NoError(t, err)
+	require.False(t, found)
+
+	otherAmount := arg
+	otherAmount.Amount = transfer.Amount + 1
+	found, err = testQueries.RecentSimilarTransferExists(context.Background(), otherAmount)
+	require.NoError(t, err)
+	require.False(t, found)
+}
+
+func TestCountRecentTransfersFromAccount(t *testing.T) {
+	transfer := createTestTransfer(t)
+	for i := 0; i < 2; i++ {
+		_, err := testQueries.CreateTransfer(context.Background(), CreateTransferParams{
+			FromAccountID: transfer.FromAccountID,
+			ToAccountID:   transfer.ToAccountID,
+			Amount:        util.RandomInt(1, 1000),
+		})
+		require.NoError(t, err)
+	}
+
+	count, err := testQueries.CountRecentTransfersFromAccount(context.Background(), CountRecentTransfersFromAccountParams{
+		FromAccountID: transfer.FromAccountID,
+		Since:         transfer.CreatedAt.Add(-time.Minute),
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(3), count)
+
+	count, err = testQueries.CountRecentTransfersFromAccount(context.Background(), CountRecentTransfersFromAccountParams{
+		FromAccountID: transfer.FromAccountID,
+		Since:         time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+	require.Zero(t, count)
+}
+
+func TestSpendingByCategory(t *testing.T) {
+	fromAccount := createTestAccount(t)
+	toAccount := createTestAccount(t)
+	send := func(account Account, amount int64, category string) Transfer {
+		transfer, err := testQueries.CreateTransfer(context.Background(), CreateTransferParams{
+			FromAccountID: account.ID,
+			ToAccountID:   toAccount.ID,
+			Amount:        amount,
+			Category:      transferCategory(category),
+		})
+		require.NoError(t, err)
+		return transfer
+	}
+
+	first := send(fromAccount, 40, "groceries")
+	send(fromAccount, 900, "rent")
+	send(fromAccount, 60, "groceries")
+	send(fromAccount, 5, "")
+	send(fromAccount, 7, "")
+	// transfers from another account are not counted
+	send(createTestAccount(t), 1000, "rent")
+
+	arg := SpendingByCategoryParams{
+		AccountID: fromAccount.ID,
+		FromTime:  first.CreatedAt.Add(-time.Minute),
+		ToTime:    time.Now().Add(time.Minute),
+	}
+	rows, err := testQueries.SpendingByCategory(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, []SpendingByCategoryRow{
+		{Category: "rent", Total: 900, Transfers: 1},
+		{Category: "groceries", Total: 100, Transfers: 2},
+		{Category: "", Total: 12, Transfers: 2},
+	}, rows)
+
+	arg.FromTime = arg.ToTime
+	arg.ToTime = arg.FromTime.Add(time.Hour)
+	rows, err = testQueries.SpendingByCategory(context.Background(), arg)
+	require.NoError(t, err)
+	require.Empty(t, rows)
+}
+
+func TestGetTransfersByIDs(t *testing.T) {
+	owner := util.RandomOwner()
+	account := createTestAccountFor(t, owner, "USD")
+	other1 := createTestAccount(t)
+	other2 := createTestAccount(t)
+
+	newTransfer := func(from, to Account) Transfer {
+		transfer, err := testQueries.CreateTransfer(context.Background(), CreateTransferParams{
+			FromAccountID: from.ID,
+			ToAccountID:   to.ID,
+			Amount:        util.RandomInt(1, 1000),
+		})
+		require.NoError(t, err)
+		return transfer
+	}
+	outgoing := newTransfer(account, other1)
+	incoming := newTransfer(other2, account)
+	unrelated := newTransfer(other1, other2)
+
+	transfers, err := testQueries.GetTransfersByIDs(context.Background(), GetTransfersByIDsParams{
+		Ids:   []int64{unrelated.ID, incoming.ID, outgoing.ID, incoming.ID + 1_000_000},
+		Owner: owner,
+	})
+	require.NoError(t, err)
+	require.Len(t, transfers, 2)
+	require.Equal(t, outgoing.ID, transfers[0].ID)
+	require.Equal(t, outgoing.Amount, transfers[0].Amount)
+	require.Equal(t, incoming.ID, transfers[1].ID)
+
+	transfers, err = testQueries.GetTransfersByIDs(context.Background(), GetTransfersByIDsParams{
+		Ids:   []int64{unrelated.ID},
+		Owner: owner,
+	})
+	require.NoError(t, err)
+	require.Empty(t, transfers)
+}
+
+func TestFindCounterpartyAccounts(t *testing.T) {
+	flagged := createTestAccount(t)
+	b := createTestAccount(t)
+	c := createTestAccount(t)
+	d := createTestAccount(t)
+	e := createTestAccount(t)
+
+	for _, transfer := range []struct {
+		from, to Account
+		amount   int64
+	}{
+		{flagged, b, 10},
+		{flagged, b, 20},
+		{c, flagged, 5},
+		{flagged, d, 7},
+		{d, flagged, 3},
+		{b, c, 100},
+		{e, b, 100},
+	} {
+		_, err := testQueries.CreateTransfer(context.Background(), CreateTransferParams{
+			FromAccountID: transfer.from.ID,
+			ToAccountID:   transfer.to.ID,
+			Amount:        transfer.amount,
+		})
+		require.NoError(t, err)
+	}
+
+	counterparties, err := testQueries.FindCounterpartyAccounts(context.Background(), FindCounterpartyAccountsParams{
+		AccountID: flagged.ID,
+		Limit:     10,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []FindCounterpartyAccountsRow{
+		{ID: b.ID, Owner: b.Owner, Currency: b.Currency, Direction: CounterpartyOutgoing, Transfers: 2, AmountSent: 30},
+		{ID: c.ID, Owner: c.Owner, Currency: c.Currency, Direction: CounterpartyIncoming, Transfers: 1, AmountReceived: 5},
+		{ID: d.ID, Owner: d.Owner, Currency: d.Currency, Direction: CounterpartyBoth, Transfers: 2, AmountSent: 7, AmountReceived: 3},
+	}, counterparties)
+
+	counterparties, err = testQueries.FindCounterpartyAccounts(context.Background(), FindCounterpartyAccountsParams{
+		AccountID: flagged.ID,
+		Limit:     2,
+		Offset:    2,
+	})
+	require.NoError(t, err)
+	require.Len(t, counterparties, 1)
+	require.Equal(t, d.ID, counterparties[0].ID)
+}
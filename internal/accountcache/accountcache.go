@@ -0,0 +1,227 @@
+// Package accountcache caches account reads in front of another store.
+package accountcache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	db "github.com/khuongkd/simplebank/db/sqlc"
+)
+
+// Store is a db.Store that serves GetAccount from a least recently used cache whose entries expire after a TTL.
+// Every operation that changes an account drops it from the cache, or drops the whole cache
+// when the accounts it changes are only known to the store.
+type Store struct {
+	db.Store
+	size int
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu      sync.Mutex
+	lru     *list.List // of *entry, most recently used first
+	entries map[int64]*list.Element
+	// generation counts invalidations, so that a read which raced with one does not cache what it read
+	generation uint64
+}
+
+type entry struct {
+	account   db.Account
+	expiresAt time.Time
+}
+
+var _ db.Store = (*Store)(nil)
+
+// New returns store with up to size accounts cached for ttl each
+func New(store db.Store, size int, ttl time.Duration) *Store {
+	return &Store{
+		Store:   store,
+		size:    size,
+		ttl:     ttl,
+		now:     time.Now,
+		lru:     list.New(),
+		entries: make(map[int64]*list.Element),
+	}
+}
+
+func (store *Store) GetAccount(ctx context.Context, id int64) (db.Account, error) {
+	store.mu.Lock()
+	if elem, ok := store.entries[id]; ok {
+		cached := elem.Value.(*entry)
+		if store.now().Before(cached.expiresAt) {
+			store.lru.MoveToFront(elem)
+			store.mu.Unlock()
+			return cached.account, nil
+		}
+		store.remove(elem)
+	}
+	generation := store.generation
+	store.mu.Unlock()
+
+	account, err := store.Store.GetAccount(ctx, id)
+	if err != nil {
+		return account, err
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if store.generation == generation {
+		store.put(account)
+	}
+	return account, nil
+}
+
+// put caches the account, evicting the least recently used one when the cache is full
+func (store *Store) put(account db.Account) {
+	if elem, ok := store.entries[account.ID]; ok {
+		store.remove(elem)
+	}
+	for store.lru.Len() >= store.size && store.lru.Len() > 0 {
+		store.remove(store.lru.Back())
+	}
+
+	store.entries[account.ID] = store.lru.PushFront(&entry{
+		account:   account,
+		expiresAt: store.now().Add(store.ttl),
+	})
+}
+
+func (store *Store) remove(elem *list.Element) {
+	store.lru.Remove(elem)
+	delete(store.entries, elem.Value.(*entry).account.ID)
+}
+
+// invalidate drops the accounts from the cache
+func (store *Store) invalidate(ids ...int64) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.generation++
+	for _, id := range ids {
+		if elem, ok := store.entries[id]; ok {
+			store.remove(elem)
+		}
+	}
+}
+
+// purge drops every account from the cache
+func (store *Store) purge() {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.generation++
+	store.lru.Init()
+	store.entries = make(map[int64]*list.Element)
+}
+
+func (store *Store) UpdateAccount(ctx context.Context, arg db.UpdateAccountParams) (db.Account, error) {
+	defer store.invalidate(arg.ID)
+	return store.Store.UpdateAccount(ctx, arg)
+}
+
+func (store *Store) AddAccountBalance(ctx context.Context, arg db.AddAccountBalanceParams) (db.Account, error) {
+	defer store.invalidate(arg.ID)
+	return store.Store.AddAccountBalance(ctx, arg)
+}
+
+func (store *Store) AddAccountHeldBalance(ctx context.Context, arg db.AddAccountHeldBalanceParams) (db.Account, error) {
+	defer store.invalidate(arg.ID)
+	return store.Store.AddAccountHeldBalance(ctx, arg)
+}
+
+func (store *Store) SetAccountCurrency(ctx context.Context, arg db.SetAccountCurrencyParams) (db.Account, error) {
+	defer store.invalidate(arg.ID)
+	return store.Store.SetAccountCurrency(ctx, arg)
+}
+
+func (store *Store) SetAccountOwner(ctx context.Context, arg db.SetAccountOwnerParams) (db.Account, error) {
+	defer store.invalidate(arg.ID)
+	return store.Store.SetAccountOwner(ctx, arg)
+}
+
+func (store *Store) SetAccountMinBalance(ctx context.Context, arg db.SetAccountMinBalanceParams) (db.Account, error) {
+	defer store.invalidate(arg.ID)
+	return store.Store.SetAccountMinBalance(ctx, arg)
+}
+
+func (store *Store) PatchAccount(ctx context.Context, arg db.PatchAccountParams) (db.Account, error) {
+	defer store.invalidate(arg.ID)
+	return store.Store.PatchAccount(ctx, arg)
+}
+
+func (store *Store) SetAccountWhitelistEnabled(ctx context.Context, arg db.SetAccountWhitelistEnabledParams) (db.Account, error) {
+	defer store.invalidate(arg.ID)
+	return store.Store.SetAccountWhitelistEnabled(ctx, arg)
+}
+
+func (store *Store) DeleteAccount(ctx context.Context, id int64) error {
+	defer store.invalidate(id)
+	return store.Store.DeleteAccount(ctx, id)
+}
+
+func (store *Store) TransferTx(ctx context.Context, params db.TransferTxParams) (db.TransferTxResult, error) {
+	defer store.invalidate(params.FromAccountID, params.ToAccountID, params.FeeAccountID)
+	return store.Store.TransferTx(ctx, params)
+}
+
+func (store *Store) BatchTransferTx(ctx context.Context, params []db.TransferTxParams) ([]db.TransferTxResult, error) {
+	ids := make([]int64, 0, 3*len(params))
+	for _, p := range params {
+		ids = append(ids, p.FromAccountID, p.ToAccountID, p.FeeAccountID)
+	}
+	defer store.invalidate(ids...)
+	return store.Store.BatchTransferTx(ctx, params)
+}
+
+func (store *Store) SweepOwnAccountsTx(ctx context.Context, fromAccountID, toAccountID int64, owner string) (db.TransferTxResult, error) {
+	defer store.invalidate(fromAccountID, toAccountID)
+	return store.Store.SweepOwnAccountsTx(ctx, fromAccountID, toAccountID, owner)
+}
+
+func (store *Store) AuthorizeTransferTx(ctx context.Context, params db.CreateTransferParams) (db.TransferHoldTxResult, error) {
+	defer store.invalidate(params.FromAccountID, params.ToAccountID)
+	return store.Store.AuthorizeTransferTx(ctx, params)
+}
+
+func (store *Store) ConfirmTransferTx(ctx context.Context, id int64, params db.TransferTxParams) (db.TransferTxResult, error) {
+	defer store.invalidate(params.FromAccountID, params.ToAccountID, params.FeeAccountID)
+	return store.Store.ConfirmTransferTx(ctx, id, params)
+}
+
+func (store *Store) ConvertAccountCurrencyTx(ctx context.Context, params db.ConvertAccountCurrencyTxParams) (db.ConvertAccountCurrencyTxResult, error) {
+	defer store.invalidate(params.AccountID)
+	return store.Store.ConvertAccountCurrencyTx(ctx, params)
+}
+
+func (store *Store) ConvertSubBalanceTx(ctx context.Context, params db.ConvertSubBalanceTxParams) (db.ConvertSubBalanceTxResult, error) {
+	defer store.invalidate(params.AccountID)
+	return store.Store.ConvertSubBalanceTx(ctx, params)
+}
+
+func (store *Store) AdjustAccountBalanceTx(ctx context.Context, params db.AdjustAccountBalanceTxParams) (db.AdjustAccountBalanceTxResult, error) {
+	defer store.invalidate(params.AccountID)
+	return store.Store.AdjustAccountBalanceTx(ctx, params)
+}
+
+// The accounts changed by the operations below are only known from the rows they lock, so they purge the cache
+
+func (store *Store) ExecuteScheduledTransferTx(ctx context.Context, id int64) (db.ScheduledTransfer, error) {
+	defer store.purge()
+	return store.Store.ExecuteScheduledTransferTx(ctx, id)
+}
+
+func (store *Store) CaptureTransferTx(ctx context.Context, holdID int64) (db.TransferTxResult, error) {
+	defer store.purge()
+	return store.Store.CaptureTransferTx(ctx, holdID)
+}
+
+func (store *Store) VoidTransferTx(ctx context.Context, holdID int64) (db.TransferHoldTxResult, error) {
+	defer store.purge()
+	return store.Store.VoidTransferTx(ctx, holdID)
+}
+
+func (store *Store) AcceptOwnershipTransferTx(ctx context.Context, requestID int64, owner string) (db.AcceptOwnershipTransferTxResult, error) {
+	defer store.purge()
+	return store.Store.AcceptOwnershipTransferTx(ctx, requestID, owner)
+}
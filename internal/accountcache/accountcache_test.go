@@ -0,0 +1,351 @@
+package accountcache
+
+import (
+	"context"
+	"database/sql"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khuongkd/simplebank/db/mock"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+	"github.com/khuongkd/simplebank/internal/memdb"
+	"github.com/khuongkd/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAccountCached(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	account := db.Account{ID: 1, Owner: util.RandomOwner(), Balance: 100, Currency: "USD"}
+	inner := mockdb.NewMockStore(ctrl)
+	inner.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+
+	store := New(inner, 10, time.Minute)
+	for i := 0; i < 3; i++ {
+		cached, err := store.GetAccount(context.Background(), account.ID)
+		require.NoError(t, err)
+		require.Equal(t, account, cached)
+	}
+}
+
+func TestGetAccountErrorNotCached(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	inner := mockdb.NewMockStore(ctrl)
+	inner.EXPECT().GetAccount(gomock.Any(), gomock.Eq(int64(1))).Times(2).Return(db.Account{}, sql.ErrNoRows)
+
+	store := New(inner, 10, time.Minute)
+	for i := 0; i < 2; i++ {
+		_, err := store.GetAccount(context.Background(), 1)
+		require.ErrorIs(t, err, sql.ErrNoRows)
+	}
+}
+
+func TestGetAccountExpires(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	account := db.Account{ID: 1}
+	inner := mockdb.NewMockStore(ctrl)
+	inner.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(2).Return(account, nil)
+
+	store := New(inner, 10, time.Minute)
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	_, err := store.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	now = now.Add(59 * time.Second)
+	_, err = store.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	now = now.Add(time.Second)
+	_, err = store.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+}
+
+func TestGetAccountEvictsLeastRecentlyUsed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	inner := mockdb.NewMockStore(ctrl)
+	inner.EXPECT().GetAccount(gomock.Any(), gomock.Eq(int64(1))).Times(1).Return(db.Account{ID: 1}, nil)
+	inner.EXPECT().GetAccount(gomock.Any(), gomock.Eq(int64(2))).Times(2).Return(db.Account{ID: 2}, nil)
+	inner.EXPECT().GetAccount(gomock.Any(), gomock.Eq(int64(3))).Times(1).Return(db.Account{ID: 3}, nil)
+
+	store := New(inner, 2, time.Minute)
+	for _, id := range []int64{1, 2, 1, 3, 1, 2} {
+		_, err := store.GetAccount(context.Background(), id)
+		require.NoError(t, err)
+	}
+	require.Equal(t, 2, store.lru.Len())
+}
+
+func TestTransferInvalidates(t *testing.T) {
+	inner := memdb.NewInMemoryStore()
+	store := New(inner, 10, time.Minute)
+
+	account1, err := store.CreateAcount(context.Background(), db.CreateAcountParams{Owner: util.RandomOwner(), Balance: 100, Currency: "USD"})
+	require.NoError(t, err)
+	account2, err := store.CreateAcount(context.Background(), db.CreateAcountParams{Owner: util.RandomOwner(), Currency: "USD"})
+	require.NoError(t, err)
+
+	for _, account := range []db.Account{account1, account2} {
+		cached, err := store.GetAccount(context.Background(), account.ID)
+		require.NoError(t, err)
+		require.Equal(t, account.Balance, cached.Balance)
+	}
+
+	_, err = store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        30,
+	})
+	require.NoError(t, err)
+
+	from, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(70), from.Balance)
+	to, err := store.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(30), to.Balance)
+
+	_, err = store.AddAccountBalance(context.Background(), db.AddAccountBalanceParams{ID: account1.ID, Amount: 5})
+	require.NoError(t, err)
+	from, err = store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(75), from.Balance)
+}
+
+func TestBatchTransferInvalidates(t *testing.T) {
+	inner := memdb.NewInMemoryStore()
+	store := New(inner, 10, time.Minute)
+
+	account1, err := store.CreateAcount(context.Background(), db.CreateAcountParams{Owner: util.RandomOwner(), Balance: 100, Currency: "USD"})
+	require.NoError(t, err)
+	account2, err := store.CreateAcount(context.Background(), db.CreateAcountParams{Owner: util.RandomOwner(), Currency: "USD"})
+	require.NoError(t, err)
+
+	for _, account := range []db.Account{account1, account2} {
+		_, err := store.GetAccount(context.Background(), account.ID)
+		require.NoError(t, err)
+	}
+
+	_, err = store.BatchTransferTx(context.Background(), []db.TransferTxParams{
+		{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 30},
+		{FromAccountID: account2.ID, ToAccountID: account1.ID, Amount: 10},
+	})
+	require.NoError(t, err)
+
+	from, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(80), from.Balance)
+	to, err := store.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(20), to.Balance)
+}
+
+func TestInvalidateDuringRead(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	inner := mockdb.NewMockStore(ctrl)
+	store := New(inner, 10, time.Minute)
+
+	// the balance changes while the read is in flight, so the value it read must not be cached
+	inner.EXPECT().GetAccount(gomock.Any(), gomock.Eq(int64(1))).Times(1).
+		DoAndReturn(func(_ context.Context, id int64) (db.Account, error) {
+			store.invalidate(id)
+			return db.Account{ID: id, Balance: 100}, nil
+		})
+	inner.EXPECT().GetAccount(gomock.Any(), gomock.Eq(int64(1))).Times(1).Return(db.Account{ID: 1, Balance: 50}, nil)
+
+	account, err := store.GetAccount(context.Background(), 1)
+	require.NoError(t, err)
+	require.Equal(t, int64(100), account.Balance)
+
+	account, err = store.GetAccount(context.Background(), 1)
+	require.NoError(t, err)
+	require.Equal(t, int64(50), account.Balance)
+}
+
+func TestConfirmTransferInvalidates(t *testing.T) {
+	inner := memdb.NewInMemoryStore()
+	store := New(inner, 10, time.Minute)
+
+	account1, err := store.CreateAcount(context.Background(), db.CreateAcountParams{Owner: util.RandomOwner(), Balance: 100, Currency: "USD"})
+	require.NoError(t, err)
+	account2, err := store.CreateAcount(context.Background(), db.CreateAcountParams{Owner: util.RandomOwner(), Currency: "USD"})
+	require.NoError(t, err)
+
+	for _, account := range []db.Account{account1, account2} {
+		_, err := store.GetAccount(context.Background(), account.ID)
+		require.NoError(t, err)
+	}
+
+	confirmation, err := store.CreateTransferConfirmation(context.Background(), db.CreateTransferConfirmationParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        30,
+		Currency:      "USD",
+		TokenHash:     "hash",
+		ExpiresAt:     time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+	_, err = store.ConfirmTransferTx(context.Background(), confirmation.ID, db.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        30,
+	})
+	require.NoError(t, err)
+
+	from, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(70), from.Balance)
+	to, err := store.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(30), to.Balance)
+}
+
+// unchangedAccounts are the methods of db.Store that leave the accounts table alone,
+// so the cache passes them through without dropping anything
+var unchangedAccounts = map[string]bool{
+	// reads
+	"AccountBalanceAsOf":                         true,
+	"CheckSchema":                                true,
+	"CountAccountsByOwnerSince":                  true,
+	"CountAuthorizedHoldsByAccount":              true,
+	"CountPendingScheduledTransfersByAccount":    true,
+	"CountPendingTransferConfirmationsByAccount": true,
+	"CountRecentTransfersFromAccount":            true,
+	"CountStatementEntries":                      true,
+	"FindBalanceDiscrepancies":                   true,
+	"FindCounterpartyAccounts":                   true,
+	"GetAccountByNumberAndCurrency":              true,
+	"GetAccountForUpdate":                        true,
+	"GetEntry":                                   true,
+	"GetOwnershipTransferRequest":                true,
+	"GetOwnershipTransferRequestForUpdate":       true,
+	"GetScheduledTransfer":                       true,
+	"GetScheduledTransferForUpdate":              true,
+	"GetSubBalanceForUpdate":                     true,
+	"GetTransfer":                                true,
+	"GetTransferConfirmation":                    true,
+	"GetTransferConfirmationForUpdate":           true,
+	"GetTransferHold":                            true,
+	"GetTransferHoldForUpdate":                   true,
+	"GetTransfersByIDs":                          true,
+	"IsDestinationWhitelisted":                   true,
+	"ListAccountAdjustments":                     true,
+	"ListAccountAuditLog":                        true,
+	"ListAccounts":                               true,
+	"ListAccountsAfter":                          true,
+	"ListArchivedEntriesByAccount":               true,
+	"ListDormantAccounts":                        true,
+	"ListDueScheduledTransfers":                  true,
+	"ListEntries":                                true,
+	"ListEntriesWithBalance":                     true,
+	"ListEntriesWithRunningBalance":              true,
+	"ListEvents":                                 true,
+	"ListEventsByAccount":                        true,
+	"ListFrozenAccounts":                         true,
+	"ListScheduledTransfers":                     true,
+	"ListStatementEntries":                       true,
+	"ListSubBalances":                            true,
+	"ListTransfers":                              true,
+	"ListWhitelistedDestinations":                true,
+	"Ping":                                       true,
+	"RecentSimilarTransferExists":                true,
+	"ReconcileAccount":                           true,
+	"SimulateTransfersTx":                        true,
+	"SpendingByCategory":                         true,
+	"Stats":                                      true,
+	"StreamAllAccounts":                          true,
+	"SumEntriesByAccount":                        true,
+	"SumEntriesByAccountAsOf":                    true,
+	"SumTransferFeesByAccount":                   true,
+	"TopAccountsByBalance":                       true,
+	"TotalSystemBalance":                         true,
+	// writes to other tables; a new account is not cached yet
+	"AcceptOwnershipTransferRequest":  true,
+	"AddSubBalance":                   true,
+	"AddWhitelistedDestination":       true,
+	"ArchiveEntries":                  true,
+	"CancelScheduledTransfer":         true,
+	"CompleteScheduledTransfer":       true,
+	"CompleteTransferConfirmation":    true,
+	"CreateAccountAdjustment":         true,
+	"CreateAcount":                    true,
+	"CreateEntry":                     true,
+	"CreateEvent":                     true,
+	"CreateOwnershipTransferRequest":  true,
+	"CreateScheduledTransfer":         true,
+	"CreateSubBalanceEntry":           true,
+	"CreateTransfer":                  true,
+	"CreateTransferConfirmation":      true,
+	"CreateTransferHold":              true,
+	"DeleteEntry":                     true,
+	"DeleteTransfer":                  true,
+	"ExpireOwnershipTransferRequests": true,
+	"FailScheduledTransfer":           true,
+	"RemoveWhitelistedDestination":    true,
+	"SettleTransferHold":              true,
+	"UpdateEntry":                     true,
+	"UpdateTransfer":                  true,
+}
+
+// TestEveryWriteWrapped fails when a method is added to db.Store without the cache either wrapping it
+// or listing it as leaving the accounts alone, which would leave stale accounts in the cache
+func TestEveryWriteWrapped(t *testing.T) {
+	wrapped := wrappedMethods(t)
+
+	storeType := reflect.TypeOf((*db.Store)(nil)).Elem()
+	for i := 0; i < storeType.NumMethod(); i++ {
+		name := storeType.Method(i).Name
+		if wrapped[name] {
+			require.False(t, unchangedAccounts[name], "%s is wrapped but listed as leaving the accounts alone", name)
+			continue
+		}
+		require.True(t, unchangedAccounts[name], "%s is neither wrapped by the cache nor listed as leaving the accounts alone", name)
+	}
+
+	for name := range unchangedAccounts {
+		_, ok := storeType.MethodByName(name)
+		require.True(t, ok, "%s is not a method of db.Store", name)
+	}
+}
+
+// wrappedMethods returns the names of the methods Store declares itself, rather than promotes from the store it wraps.
+// Reflection cannot tell the two apart, so they are read from the source of the package.
+func wrappedMethods(t *testing.T) map[string]bool {
+	pkgs, err := parser.ParseDir(token.NewFileSet(), ".", func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, 0)
+	require.NoError(t, err)
+
+	methods := make(map[string]bool)
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				fn, ok := decl.(*ast.FuncDecl)
+				if !ok || fn.Recv == nil || !fn.Name.IsExported() {
+					continue
+				}
+				if star, ok := fn.Recv.List[0].Type.(*ast.StarExpr); ok {
+					if ident, ok := star.X.(*ast.Ident); ok && ident.Name == "Store" {
+						methods[fn.Name.Name] = true
+					}
+				}
+			}
+		}
+	}
+	return methods
+}
@@ -0,0 +1,83 @@
+// Package archiver moves ledger entries older than the retention period into the archive table.
+package archiver
+
+import (
+	"context"
+	"log"
+	"time"
+
+	db "github.com/khuongkd/simplebank/db/sqlc"
+)
+
+// defaultBatchSize caps how many entries one statement moves, keeping row locks short
+const defaultBatchSize = 1000
+
+// Archiver periodically archives the entries that are older than the retention period
+type Archiver struct {
+	store     db.Store
+	interval  time.Duration
+	retention time.Duration
+	batchSize int32
+	now       func() time.Time
+}
+
+// New returns an archiver that runs every interval and keeps entries younger than retention.
+// A batch size of zero or less uses the default.
+func New(store db.Store, interval, retention time.Duration, batchSize int32) *Archiver {
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	return &Archiver{
+		store:     store,
+		interval:  interval,
+		retention: retention,
+		batchSize: batchSize,
+		now:       time.Now,
+	}
+}
+
+// Run archives old entries every interval until ctx is done
+func (archiver *Archiver) Run(ctx context.Context) {
+	ticker := time.NewTicker(archiver.interval)
+	defer ticker.Stop()
+
+	for {
+		if n, err := archiver.ArchiveOld(ctx); err != nil {
+			log.Println("cannot archive entries:", err)
+		} else if n > 0 {
+			log.Printf("archived %d entries", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ArchiveOld moves entries older than the retention period in batches until none are left
+// and returns how many it moved. Each batch is its own statement, so no lock is held for long.
+func (archiver *Archiver) ArchiveOld(ctx context.Context) (int64, error) {
+	arg := db.ArchiveEntriesParams{
+		Before:    archiver.now().Add(-archiver.retention),
+		BatchSize: archiver.batchSize,
+	}
+
+	var total int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+
+		n, err := archiver.store.ArchiveEntries(ctx, arg)
+		if err != nil {
+			return total, err
+		}
+		total += n
+
+		if n < int64(archiver.batchSize) {
+			return total, nil
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package archiver
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khuongkd/simplebank/db/mock"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+	"github.com/khuongkd/simplebank/internal/memdb"
+	"github.com/khuongkd/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchiveOld(t *testing.T) {
+	store := memdb.NewInMemoryStore()
+	account, err := store.CreateAcount(context.Background(), db.CreateAcountParams{
+		Owner:    util.RandomOwner(),
+		Currency: "USD",
+	})
+	require.NoError(t, err)
+	for i := 0; i < 5; i++ {
+		_, err := store.CreateEntry(context.Background(), db.CreateEntryParams{AccountID: account.ID, Amount: 1})
+		require.NoError(t, err)
+	}
+
+	// nothing is old enough yet
+	archiver := New(store, time.Hour, time.Hour, 2)
+	n, err := archiver.ArchiveOld(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	// an hour later every entry is past retention and moves over three batches
+	archiver.now = func() time.Time { return time.Now().Add(time.Hour + time.Second) }
+	n, err = archiver.ArchiveOld(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int64(5), n)
+
+	archived, err := store.ListArchivedEntriesByAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Len(t, archived, 5)
+	for _, entry := range archived {
+		_, err := store.GetEntry(context.Background(), entry.ID)
+		require.ErrorIs(t, err, sql.ErrNoRows)
+	}
+}
+
+func TestArchiveOldStopsOnError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Now()
+	store := mockdb.NewMockStore(ctrl)
+	arg := db.ArchiveEntriesParams{Before: now.Add(-24 * time.Hour), BatchSize: 10}
+	gomock.InOrder(
+		store.EXPECT().ArchiveEntries(gomock.Any(), gomock.Eq(arg)).Times(1).Return(int64(10), nil),
+		store.EXPECT().ArchiveEntries(gomock.Any(), gomock.Eq(arg)).Times(1).Return(int64(0), sql.ErrConnDone),
+	)
+
+	archiver := New(store, time.Hour, 24*time.Hour, 10)
+	archiver.now = func() time.Time { return now }
+
+	n, err := archiver.ArchiveOld(context.Background())
+	require.ErrorIs(t, err, sql.ErrConnDone)
+	require.Equal(t, int64(10), n)
+}
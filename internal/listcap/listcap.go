@@ -0,0 +1,48 @@
+// Package listcap caps the number of accounts a store lists in one call.
+package listcap
+
+import (
+	"context"
+	"log"
+
+	db "github.com/khuongkd/simplebank/db/sqlc"
+)
+
+// Store is a db.Store whose ListAccounts returns at most max accounts, whatever limit the caller asks for.
+// Callers which ask for more are logged, so they can be moved to pagination or StreamAllAccounts.
+type Store struct {
+	db.Store
+	max    int32
+	logger *log.Logger
+}
+
+var _ db.Store = (*Store)(nil)
+
+// New returns store with ListAccounts capped at max accounts
+func New(store db.Store, max int32) *Store {
+	return &Store{
+		Store:  store,
+		max:    max,
+		logger: log.Default(),
+	}
+}
+
+func (store *Store) ListAccounts(ctx context.Context, arg db.ListAccountsParams) ([]db.Account, error) {
+	if arg.Limit <= store.max {
+		return store.Store.ListAccounts(ctx, arg)
+	}
+
+	// one row past the cap tells whether the cap actually dropped any
+	requested := arg.Limit
+	arg.Limit = store.max + 1
+	accounts, err := store.Store.ListAccounts(ctx, arg)
+	if err != nil {
+		return nil, err
+	}
+
+	if int32(len(accounts)) > store.max {
+		accounts = accounts[:store.max]
+		store.logger.Printf("ListAccounts truncated to %d accounts, %d were requested from offset %d", store.max, requested, arg.Offset)
+	}
+	return accounts, nil
+}
@@ -0,0 +1,63 @@
+package listcap
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"testing"
+
+	db "github.com/khuongkd/simplebank/db/sqlc"
+	"github.com/khuongkd/simplebank/internal/memdb"
+	"github.com/khuongkd/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStore(t *testing.T, accounts int, max int32) (*Store, *bytes.Buffer) {
+	inner := memdb.NewInMemoryStore()
+	for i := 0; i < accounts; i++ {
+		_, err := inner.CreateAcount(context.Background(), db.CreateAcountParams{
+			Owner:    util.RandomOwner(),
+			Currency: "USD",
+		})
+		require.NoError(t, err)
+	}
+
+	var logs bytes.Buffer
+	store := New(inner, max)
+	store.logger = log.New(&logs, "", 0)
+	return store, &logs
+}
+
+func TestListAccountsCapped(t *testing.T) {
+	store, logs := newTestStore(t, 10, 3)
+
+	accounts, err := store.ListAccounts(context.Background(), db.ListAccountsParams{Limit: 100})
+	require.NoError(t, err)
+	require.Len(t, accounts, 3)
+	require.Contains(t, logs.String(), "truncated to 3 accounts, 100 were requested")
+
+	// the cap keeps the offset the caller asked for
+	accounts, err = store.ListAccounts(context.Background(), db.ListAccountsParams{Limit: 100, Offset: 3})
+	require.NoError(t, err)
+	require.Len(t, accounts, 3)
+	require.Equal(t, int64(4), accounts[0].ID)
+}
+
+func TestListAccountsWithinCap(t *testing.T) {
+	store, logs := newTestStore(t, 10, 3)
+
+	accounts, err := store.ListAccounts(context.Background(), db.ListAccountsParams{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, accounts, 2)
+	require.Empty(t, logs.String())
+}
+
+func TestListAccountsNotTruncated(t *testing.T) {
+	store, logs := newTestStore(t, 3, 3)
+
+	// asking for more than the cap is only logged when it drops accounts
+	accounts, err := store.ListAccounts(context.Background(), db.ListAccountsParams{Limit: 100})
+	require.NoError(t, err)
+	require.Len(t, accounts, 3)
+	require.Empty(t, logs.String())
+}
@@ -0,0 +1,100 @@
+package memdb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	db "github.com/khuongkd/simplebank/db/sqlc"
+)
+
+// checkAdjustment mirrors the CHECK constraints of the account_adjustments table
+func checkAdjustment(amount int64, reason string) error {
+	if amount == 0 {
+		return fmt.Errorf("%w: %s", db.ErrConstraintViolation, db.ConstraintAdjustmentAmountNonZero)
+	}
+	if reason == "" {
+		return fmt.Errorf("%w: %s", db.ErrConstraintViolation, db.ConstraintAdjustmentReasonPresent)
+	}
+	return nil
+}
+
+func (store *InMemoryStore) createAccountAdjustment(arg db.CreateAccountAdjustmentParams) (db.AccountAdjustment, error) {
+	if err := store.requireAccount(arg.AccountID); err != nil {
+		return db.AccountAdjustment{}, err
+	}
+	if err := checkAdjustment(arg.Amount, arg.Reason); err != nil {
+		return db.AccountAdjustment{}, err
+	}
+
+	store.nextAccountAdjustmentID++
+	adjustment := db.AccountAdjustment{
+		ID:         store.nextAccountAdjustmentID,
+		AccountID:  arg.AccountID,
+		EntryID:    arg.EntryID,
+		Amount:     arg.Amount,
+		Reason:     arg.Reason,
+		AdjustedBy: arg.AdjustedBy,
+		CreatedAt:  time.Now(),
+	}
+	store.accountAdjustments[adjustment.ID] = adjustment
+	return adjustment, nil
+}
+
+func (store *InMemoryStore) CreateAccountAdjustment(ctx context.Context, arg db.CreateAccountAdjustmentParams) (db.AccountAdjustment, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return store.createAccountAdjustment(arg)
+}
+
+func (store *InMemoryStore) ListAccountAdjustments(ctx context.Context, accountID int64) ([]db.AccountAdjustment, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var items []db.AccountAdjustment
+	for id := int64(1); id <= store.nextAccountAdjustmentID; id++ {
+		adjustment, ok := store.accountAdjustments[id]
+		if ok && adjustment.AccountID == accountID {
+			items = append(items, adjustment)
+		}
+	}
+	return items, nil
+}
+
+// AdjustAccountBalanceTx corrects the balance of an account, recording the entry and the reason for it.
+func (store *InMemoryStore) AdjustAccountBalanceTx(ctx context.Context, params db.AdjustAccountBalanceTxParams) (db.AdjustAccountBalanceTxResult, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var result db.AdjustAccountBalanceTxResult
+	account, ok := store.accounts[params.AccountID]
+	if !ok {
+		return result, sql.ErrNoRows
+	}
+	if account.Balance+params.Amount < account.HeldBalance {
+		return result, db.ErrInsufficientFunds
+	}
+	if err := checkAdjustment(params.Amount, params.Reason); err != nil {
+		return result, err
+	}
+
+	var err error
+	result.Entry, err = store.createEntry(params.AccountID, params.Amount)
+	if err != nil {
+		return result, err
+	}
+	result.Adjustment, err = store.createAccountAdjustment(db.CreateAccountAdjustmentParams{
+		AccountID:  params.AccountID,
+		EntryID:    result.Entry.ID,
+		Amount:     params.Amount,
+		Reason:     params.Reason,
+		AdjustedBy: params.AdjustedBy,
+	})
+	if err != nil {
+		return result, err
+	}
+	result.Account, err = store.addAccountBalance(params.AccountID, params.Amount)
+	return result, err
+}
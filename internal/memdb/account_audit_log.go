@@ -0,0 +1,66 @@
+package memdb
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"time"
+
+	db "github.com/khuongkd/simplebank/db/sqlc"
+)
+
+// auditActor stands in for the database user the audit trigger records when no actor is set
+const auditActor = "memdb"
+
+// putAccount stores account and records the change in the audit log, like the accounts_audit trigger does
+func (store *InMemoryStore) putAccount(account db.Account) {
+	before, existed := store.accounts[account.ID]
+	store.accounts[account.ID] = account
+
+	switch {
+	case !existed:
+		store.recordAudit(account.ID, "insert", nil, account)
+	case !reflect.DeepEqual(before, account):
+		store.recordAudit(account.ID, "update", before, account)
+	}
+}
+
+// deleteAccount removes an account and records the change in the audit log
+func (store *InMemoryStore) deleteAccount(id int64) {
+	before, existed := store.accounts[id]
+	if !existed {
+		return
+	}
+	delete(store.accounts, id)
+	store.recordAudit(id, "delete", before, nil)
+}
+
+func (store *InMemoryStore) recordAudit(accountID int64, action string, before, after interface{}) {
+	beforeJSON, _ := json.Marshal(before)
+	afterJSON, _ := json.Marshal(after)
+	store.auditLog = append(store.auditLog, db.AccountAuditLog{
+		ID:        int64(len(store.auditLog)) + 1,
+		AccountID: accountID,
+		Action:    action,
+		Before:    beforeJSON,
+		After:     afterJSON,
+		Actor:     auditActor,
+		CreatedAt: time.Now(),
+	})
+}
+
+func (store *InMemoryStore) ListAccountAuditLog(ctx context.Context, arg db.ListAccountAuditLogParams) ([]db.AccountAuditLog, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var matching []db.AccountAuditLog
+	for _, record := range store.auditLog {
+		if record.AccountID == arg.AccountID {
+			matching = append(matching, record)
+		}
+	}
+	start, end := page(len(matching), arg.Limit, arg.Offset)
+	var items []db.AccountAuditLog
+	items = append(items, matching[start:end]...)
+	return items, nil
+}
@@ -0,0 +1,65 @@
+package memdb
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	db "github.com/khuongkd/simplebank/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func listAuditLog(t *testing.T, store *InMemoryStore, accountID int64) []db.AccountAuditLog {
+	records, err := store.ListAccountAuditLog(context.Background(), db.ListAccountAuditLogParams{
+		AccountID: accountID,
+		Limit:     100,
+	})
+	require.NoError(t, err)
+	return records
+}
+
+func requireAuditAccount(t *testing.T, data json.RawMessage) db.Account {
+	var account db.Account
+	require.NoError(t, json.Unmarshal(data, &account))
+	return account
+}
+
+func TestAccountAuditLog(t *testing.T) {
+	store := NewInMemoryStore()
+	account := createTestAccount(t, store)
+	other := createTestAccount(t, store)
+
+	_, err := store.UpdateAccount(context.Background(), db.UpdateAccountParams{ID: account.ID, Balance: account.Balance + 10})
+	require.NoError(t, err)
+	_, err = store.SetAccountWhitelistEnabled(context.Background(), db.SetAccountWhitelistEnabledParams{ID: account.ID, Enabled: true})
+	require.NoError(t, err)
+	// a change that changes nothing is not recorded
+	_, err = store.SetAccountWhitelistEnabled(context.Background(), db.SetAccountWhitelistEnabledParams{ID: account.ID, Enabled: true})
+	require.NoError(t, err)
+	require.NoError(t, store.DeleteAccount(context.Background(), account.ID))
+
+	records := listAuditLog(t, store, account.ID)
+	require.Len(t, records, 4)
+
+	require.Equal(t, "insert", records[0].Action)
+	require.JSONEq(t, "null", string(records[0].Before))
+	require.Equal(t, account.Balance, requireAuditAccount(t, records[0].After).Balance)
+
+	require.Equal(t, "update", records[1].Action)
+	require.Equal(t, account.Balance, requireAuditAccount(t, records[1].Before).Balance)
+	require.Equal(t, account.Balance+10, requireAuditAccount(t, records[1].After).Balance)
+
+	require.Equal(t, "update", records[2].Action)
+	require.False(t, requireAuditAccount(t, records[2].Before).WhitelistEnabled)
+	require.True(t, requireAuditAccount(t, records[2].After).WhitelistEnabled)
+
+	require.Equal(t, "delete", records[3].Action)
+	require.Equal(t, account.ID, requireAuditAccount(t, records[3].Before).ID)
+	require.JSONEq(t, "null", string(records[3].After))
+
+	for _, record := range records {
+		require.Equal(t, account.ID, record.AccountID)
+		require.Equal(t, auditActor, record.Actor)
+	}
+	require.Len(t, listAuditLog(t, store, other.ID), 1)
+}
@@ -0,0 +1,89 @@
+package memdb
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	db "github.com/khuongkd/simplebank/db/sqlc"
+)
+
+func (store *InMemoryStore) SetAccountWhitelistEnabled(ctx context.Context, arg db.SetAccountWhitelistEnabledParams) (db.Account, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	account, ok := store.accounts[arg.ID]
+	if !ok {
+		return db.Account{}, sql.ErrNoRows
+	}
+	account.WhitelistEnabled = arg.Enabled
+	store.putAccount(account)
+	return account, nil
+}
+
+func (store *InMemoryStore) AddWhitelistedDestination(ctx context.Context, arg db.AddWhitelistedDestinationParams) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if err := store.requireAccount(arg.AccountID); err != nil {
+		return err
+	}
+	if err := store.requireAccount(arg.DestinationAccountID); err != nil {
+		return err
+	}
+
+	destinations, ok := store.whitelist[arg.AccountID]
+	if !ok {
+		destinations = make(map[int64]db.AccountWhitelist)
+		store.whitelist[arg.AccountID] = destinations
+	}
+	if _, ok := destinations[arg.DestinationAccountID]; !ok {
+		destinations[arg.DestinationAccountID] = db.AccountWhitelist{
+			AccountID:            arg.AccountID,
+			DestinationAccountID: arg.DestinationAccountID,
+			CreatedAt:            time.Now(),
+		}
+	}
+	return nil
+}
+
+func (store *InMemoryStore) RemoveWhitelistedDestination(ctx context.Context, arg db.RemoveWhitelistedDestinationParams) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	delete(store.whitelist[arg.AccountID], arg.DestinationAccountID)
+	return nil
+}
+
+func (store *InMemoryStore) ListWhitelistedDestinations(ctx context.Context, accountID int64) ([]db.AccountWhitelist, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var items []db.AccountWhitelist
+	for _, item := range store.whitelist[accountID] {
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].DestinationAccountID < items[j].DestinationAccountID })
+	return items, nil
+}
+
+func (store *InMemoryStore) IsDestinationWhitelisted(ctx context.Context, arg db.IsDestinationWhitelistedParams) (bool, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	_, ok := store.whitelist[arg.AccountID][arg.DestinationAccountID]
+	return ok, nil
+}
+
+// checkWhitelisted returns db.ErrDestinationNotWhitelisted when the source account restricts transfers
+// and the destination is not on its whitelist; callers must hold the mutex
+func (store *InMemoryStore) checkWhitelisted(fromAccountID, toAccountID int64) error {
+	if !store.accounts[fromAccountID].WhitelistEnabled {
+		return nil
+	}
+	if _, ok := store.whitelist[fromAccountID][toAccountID]; !ok {
+		return db.ErrDestinationNotWhitelisted
+	}
+	return nil
+}
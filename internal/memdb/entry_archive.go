@@ -0,0 +1,99 @@
+package memdb
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	db "github.com/khuongkd/simplebank/db/sqlc"
+)
+
+func (store *InMemoryStore) ArchiveEntries(ctx context.Context, arg db.ArchiveEntriesParams) (int64, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var moved int64
+	archivedAt := time.Now()
+	for _, entry := range store.sortedEntries() {
+		if moved == int64(arg.BatchSize) {
+			break
+		}
+		if !entry.CreatedAt.Before(arg.Before) {
+			continue
+		}
+
+		store.entriesArchive[entry.ID] = db.EntriesArchive{
+			ID:         entry.ID,
+			AccountID:  entry.AccountID,
+			Amount:     entry.Amount,
+			CreatedAt:  entry.CreatedAt,
+			ArchivedAt: archivedAt,
+		}
+		delete(store.entries, entry.ID)
+		moved++
+	}
+	return moved, nil
+}
+
+func (store *InMemoryStore) ListArchivedEntriesByAccount(ctx context.Context, accountID int64) ([]db.EntriesArchive, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var items []db.EntriesArchive
+	for _, entry := range store.entriesArchive {
+		if entry.AccountID == accountID {
+			items = append(items, entry)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+	return items, nil
+}
+
+// statementEntries returns the entries of the account, archived ones included, created in [from, to), in id order
+func (store *InMemoryStore) statementEntries(accountID int64, from, to time.Time) []db.Entry {
+	inRange := func(entryAccountID int64, createdAt time.Time) bool {
+		return entryAccountID == accountID && !createdAt.Before(from) && createdAt.Before(to)
+	}
+
+	var items []db.Entry
+	for _, entry := range store.entries {
+		if inRange(entry.AccountID, entry.CreatedAt) {
+			items = append(items, entry)
+		}
+	}
+	for _, entry := range store.entriesArchive {
+		if inRange(entry.AccountID, entry.CreatedAt) {
+			items = append(items, db.Entry{
+				ID:        entry.ID,
+				AccountID: entry.AccountID,
+				Amount:    entry.Amount,
+				CreatedAt: entry.CreatedAt,
+			})
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+	return items
+}
+
+func (store *InMemoryStore) CountStatementEntries(ctx context.Context, arg db.CountStatementEntriesParams) (int64, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return int64(len(store.statementEntries(arg.AccountID, arg.FromTime, arg.ToTime))), nil
+}
+
+func (store *InMemoryStore) ListStatementEntries(ctx context.Context, arg db.ListStatementEntriesParams) ([]db.Entry, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var items []db.Entry
+	for _, entry := range store.statementEntries(arg.AccountID, arg.FromTime, arg.ToTime) {
+		if len(items) == int(arg.Limit) {
+			break
+		}
+		if entry.ID > arg.AfterID {
+			items = append(items, entry)
+		}
+	}
+	return items, nil
+}
@@ -0,0 +1,72 @@
+package memdb
+
+import (
+	"context"
+	"time"
+
+	db "github.com/khuongkd/simplebank/db/sqlc"
+)
+
+func (store *InMemoryStore) createEvent(arg db.CreateEventParams) db.Event {
+	event := db.Event{
+		ID:         int64(len(store.events)) + 1,
+		EventType:  arg.EventType,
+		Payload:    arg.Payload,
+		AccountIds: arg.AccountIds,
+		CreatedAt:  time.Now(),
+	}
+	store.events = append(store.events, event)
+	return event
+}
+
+// recordTransferEvents mirrors the events db.TransferTx appends for every transfer
+func (store *InMemoryStore) recordTransferEvents(transfer db.Transfer, params db.TransferTxParams) error {
+	arg, err := db.NewTransferEvent(transfer, params.FeeAccountID)
+	if err != nil {
+		return err
+	}
+	store.createEvent(arg)
+	if params.FlagReason == "" {
+		return nil
+	}
+
+	arg, err = db.NewTransferFlaggedEvent(transfer, params.FlagReason)
+	if err != nil {
+		return err
+	}
+	store.createEvent(arg)
+	return nil
+}
+
+func (store *InMemoryStore) CreateEvent(ctx context.Context, arg db.CreateEventParams) (db.Event, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return store.createEvent(arg), nil
+}
+
+func (store *InMemoryStore) ListEvents(ctx context.Context, arg db.ListEventsParams) ([]db.Event, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	start, end := page(len(store.events), arg.Limit, arg.Offset)
+	var items []db.Event
+	items = append(items, store.events[start:end]...)
+	return items, nil
+}
+
+func (store *InMemoryStore) ListEventsByAccount(ctx context.Context, accountID int64) ([]db.Event, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var items []db.Event
+	for _, event := range store.events {
+		for _, id := range event.AccountIds {
+			if id == accountID {
+				items = append(items, event)
+				break
+			}
+		}
+	}
+	return items, nil
+}
@@ -0,0 +1,133 @@
+package memdb
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	db "github.com/khuongkd/simplebank/db/sqlc"
+)
+
+func (store *InMemoryStore) setAccountOwner(id int64, owner string) (db.Account, error) {
+	account, ok := store.accounts[id]
+	if !ok {
+		return db.Account{}, sql.ErrNoRows
+	}
+	account.Owner = owner
+	store.putAccount(account)
+	return account, nil
+}
+
+// acceptOwnershipRequest marks a pending request accepted, like the guarded UPDATE query does
+func (store *InMemoryStore) acceptOwnershipRequest(id int64) (db.OwnershipTransferRequest, error) {
+	request, ok := store.ownershipRequests[id]
+	if !ok || request.Status != db.OwnershipRequestPending {
+		return db.OwnershipTransferRequest{}, sql.ErrNoRows
+	}
+	request.Status = db.OwnershipRequestAccepted
+	store.ownershipRequests[id] = request
+	return request, nil
+}
+
+func (store *InMemoryStore) SetAccountOwner(ctx context.Context, arg db.SetAccountOwnerParams) (db.Account, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return store.setAccountOwner(arg.ID, arg.Owner)
+}
+
+func (store *InMemoryStore) CreateOwnershipTransferRequest(ctx context.Context, arg db.CreateOwnershipTransferRequestParams) (db.OwnershipTransferRequest, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if err := store.requireAccount(arg.AccountID); err != nil {
+		return db.OwnershipTransferRequest{}, err
+	}
+
+	store.nextOwnershipRequestID++
+	request := db.OwnershipTransferRequest{
+		ID:           store.nextOwnershipRequestID,
+		AccountID:    arg.AccountID,
+		CurrentOwner: arg.CurrentOwner,
+		NewOwner:     arg.NewOwner,
+		Status:       db.OwnershipRequestPending,
+		ExpiresAt:    arg.ExpiresAt,
+		CreatedAt:    time.Now(),
+	}
+	store.ownershipRequests[request.ID] = request
+	return request, nil
+}
+
+func (store *InMemoryStore) GetOwnershipTransferRequest(ctx context.Context, id int64) (db.OwnershipTransferRequest, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	request, ok := store.ownershipRequests[id]
+	if !ok {
+		return db.OwnershipTransferRequest{}, sql.ErrNoRows
+	}
+	return request, nil
+}
+
+func (store *InMemoryStore) GetOwnershipTransferRequestForUpdate(ctx context.Context, id int64) (db.OwnershipTransferRequest, error) {
+	return store.GetOwnershipTransferRequest(ctx, id)
+}
+
+func (store *InMemoryStore) AcceptOwnershipTransferRequest(ctx context.Context, id int64) (db.OwnershipTransferRequest, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return store.acceptOwnershipRequest(id)
+}
+
+func (store *InMemoryStore) ExpireOwnershipTransferRequests(ctx context.Context, now time.Time) (int64, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var n int64
+	for id, request := range store.ownershipRequests {
+		if request.Status == db.OwnershipRequestPending && !request.ExpiresAt.After(now) {
+			request.Status = db.OwnershipRequestExpired
+			store.ownershipRequests[id] = request
+			n++
+		}
+	}
+	return n, nil
+}
+
+// AcceptOwnershipTransferTx hands the account over to the new owner named in the request.
+func (store *InMemoryStore) AcceptOwnershipTransferTx(ctx context.Context, requestID int64, owner string) (db.AcceptOwnershipTransferTxResult, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var result db.AcceptOwnershipTransferTxResult
+	request, ok := store.ownershipRequests[requestID]
+	if !ok {
+		return result, sql.ErrNoRows
+	}
+	if request.Status != db.OwnershipRequestPending {
+		return result, db.ErrOwnershipRequestNotPending
+	}
+	if !time.Now().Before(request.ExpiresAt) {
+		return result, db.ErrOwnershipRequestExpired
+	}
+	if request.NewOwner != owner {
+		return result, db.ErrAccountOwnerMismatch
+	}
+
+	account, ok := store.accounts[request.AccountID]
+	if !ok {
+		return result, sql.ErrNoRows
+	}
+	if account.Owner != request.CurrentOwner {
+		return result, db.ErrOwnershipRequestStale
+	}
+
+	var err error
+	result.Account, err = store.setAccountOwner(request.AccountID, request.NewOwner)
+	if err != nil {
+		return result, err
+	}
+	result.Request, err = store.acceptOwnershipRequest(requestID)
+	return result, err
+}
@@ -0,0 +1,210 @@
+package memdb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	db "github.com/khuongkd/simplebank/db/sqlc"
+)
+
+// sortedScheduledTransfers returns the scheduled transfers matching keep, ordered by run time then ID
+func (store *InMemoryStore) sortedScheduledTransfers(keep func(db.ScheduledTransfer) bool) []db.ScheduledTransfer {
+	var scheduled []db.ScheduledTransfer
+	for _, item := range store.scheduledTransfers {
+		if keep(item) {
+			scheduled = append(scheduled, item)
+		}
+	}
+	sort.Slice(scheduled, func(i, j int) bool {
+		if !scheduled[i].RunAt.Equal(scheduled[j].RunAt) {
+			return scheduled[i].RunAt.Before(scheduled[j].RunAt)
+		}
+		return scheduled[i].ID < scheduled[j].ID
+	})
+	return scheduled
+}
+
+// setPendingScheduledTransfer applies update to a pending scheduled transfer, like the guarded UPDATE queries do
+func (store *InMemoryStore) setPendingScheduledTransfer(id int64, update func(*db.ScheduledTransfer)) (db.ScheduledTransfer, error) {
+	scheduled, ok := store.scheduledTransfers[id]
+	if !ok || scheduled.Status != db.ScheduledTransferPending {
+		return db.ScheduledTransfer{}, sql.ErrNoRows
+	}
+	update(&scheduled)
+	store.scheduledTransfers[id] = scheduled
+	return scheduled, nil
+}
+
+func (store *InMemoryStore) CreateScheduledTransfer(ctx context.Context, arg db.CreateScheduledTransferParams) (db.ScheduledTransfer, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if err := store.requireAccount(arg.FromAccountID); err != nil {
+		return db.ScheduledTransfer{}, err
+	}
+	if err := store.requireAccount(arg.ToAccountID); err != nil {
+		return db.ScheduledTransfer{}, err
+	}
+	if arg.Amount <= 0 {
+		return db.ScheduledTransfer{}, fmt.Errorf("%w: %s", db.ErrConstraintViolation, db.ConstraintScheduledAmountPositive)
+	}
+
+	store.nextScheduledTransferID++
+	scheduled := db.ScheduledTransfer{
+		ID:            store.nextScheduledTransferID,
+		FromAccountID: arg.FromAccountID,
+		ToAccountID:   arg.ToAccountID,
+		Amount:        arg.Amount,
+		Currency:      arg.Currency,
+		RunAt:         arg.RunAt,
+		Status:        db.ScheduledTransferPending,
+		CreatedAt:     time.Now(),
+	}
+	store.scheduledTransfers[scheduled.ID] = scheduled
+	return scheduled, nil
+}
+
+func (store *InMemoryStore) GetScheduledTransfer(ctx context.Context, id int64) (db.ScheduledTransfer, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	scheduled, ok := store.scheduledTransfers[id]
+	if !ok {
+		return db.ScheduledTransfer{}, sql.ErrNoRows
+	}
+	return scheduled, nil
+}
+
+// GetScheduledTransferForUpdate behaves like GetScheduledTransfer; row locks have no meaning in memory.
+func (store *InMemoryStore) GetScheduledTransferForUpdate(ctx context.Context, id int64) (db.ScheduledTransfer, error) {
+	return store.GetScheduledTransfer(ctx, id)
+}
+
+func (store *InMemoryStore) ListScheduledTransfers(ctx context.Context, arg db.ListScheduledTransfersParams) ([]db.ScheduledTransfer, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	scheduled := store.sortedScheduledTransfers(func(item db.ScheduledTransfer) bool {
+		return item.FromAccountID == arg.FromAccountID
+	})
+	start, end := page(len(scheduled), arg.Limit, arg.Offset)
+	var items []db.ScheduledTransfer
+	items = append(items, scheduled[start:end]...)
+	return items, nil
+}
+
+func (store *InMemoryStore) ListDueScheduledTransfers(ctx context.Context, arg db.ListDueScheduledTransfersParams) ([]db.ScheduledTransfer, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	scheduled := store.sortedScheduledTransfers(func(item db.ScheduledTransfer) bool {
+		return item.Status == db.ScheduledTransferPending && !item.RunAt.After(arg.Now)
+	})
+	start, end := page(len(scheduled), arg.MaxCount, 0)
+	var items []db.ScheduledTransfer
+	items = append(items, scheduled[start:end]...)
+	return items, nil
+}
+
+func (store *InMemoryStore) CancelScheduledTransfer(ctx context.Context, id int64) (db.ScheduledTransfer, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return store.setPendingScheduledTransfer(id, func(scheduled *db.ScheduledTransfer) {
+		scheduled.Status = db.ScheduledTransferCanceled
+	})
+}
+
+func (store *InMemoryStore) CompleteScheduledTransfer(ctx context.Context, arg db.CompleteScheduledTransferParams) (db.ScheduledTransfer, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return store.setPendingScheduledTransfer(arg.ID, func(scheduled *db.ScheduledTransfer) {
+		scheduled.Status = db.ScheduledTransferDone
+		scheduled.TransferID = arg.TransferID
+	})
+}
+
+func (store *InMemoryStore) FailScheduledTransfer(ctx context.Context, arg db.FailScheduledTransferParams) (db.ScheduledTransfer, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return store.setPendingScheduledTransfer(arg.ID, func(scheduled *db.ScheduledTransfer) {
+		scheduled.Status = db.ScheduledTransferFailed
+		scheduled.FailureReason = arg.FailureReason
+	})
+}
+
+// ExecuteScheduledTransferTx performs a pending scheduled transfer and marks it done.
+func (store *InMemoryStore) ExecuteScheduledTransferTx(ctx context.Context, id int64) (db.ScheduledTransfer, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	scheduled, ok := store.scheduledTransfers[id]
+	if !ok {
+		return db.ScheduledTransfer{}, sql.ErrNoRows
+	}
+	if scheduled.Status != db.ScheduledTransferPending {
+		return scheduled, db.ErrScheduledTransferNotPending
+	}
+
+	fromAccount, ok := store.accounts[scheduled.FromAccountID]
+	if !ok {
+		return scheduled, sql.ErrNoRows
+	}
+	toAccount, ok := store.accounts[scheduled.ToAccountID]
+	if !ok {
+		return scheduled, sql.ErrNoRows
+	}
+	for _, account := range []db.Account{fromAccount, toAccount} {
+		if account.Currency != scheduled.Currency {
+			return scheduled, fmt.Errorf("%w: account [%d] holds %s, the transfer is in %s", db.ErrCurrencyMismatch, account.ID, account.Currency, scheduled.Currency)
+		}
+		if account.Frozen {
+			return scheduled, fmt.Errorf("%w: account [%d]", db.ErrAccountFrozen, account.ID)
+		}
+	}
+	if fromAccount.Balance-fromAccount.HeldBalance < scheduled.Amount {
+		return scheduled, db.ErrInsufficientFunds
+	}
+	fee, feeAccountID, err := store.transferFee(scheduled.Amount, scheduled.Currency)
+	if err != nil {
+		return scheduled, err
+	}
+
+	result, err := store.transfer(db.TransferTxParams{
+		FromAccountID: scheduled.FromAccountID,
+		ToAccountID:   scheduled.ToAccountID,
+		Amount:        scheduled.Amount,
+		Fee:           fee,
+		FeeAccountID:  feeAccountID,
+	})
+	if err != nil {
+		return scheduled, err
+	}
+
+	return store.setPendingScheduledTransfer(id, func(scheduled *db.ScheduledTransfer) {
+		scheduled.Status = db.ScheduledTransferDone
+		scheduled.TransferID = sql.NullInt64{Int64: result.Transfer.ID, Valid: true}
+	})
+}
+
+func (store *InMemoryStore) CountPendingScheduledTransfersByAccount(ctx context.Context, accountID int64) (int64, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return store.countPendingScheduledTransfers(accountID), nil
+}
+
+func (store *InMemoryStore) countPendingScheduledTransfers(accountID int64) int64 {
+	var count int64
+	for _, scheduled := range store.scheduledTransfers {
+		if scheduled.Status == db.ScheduledTransferPending && (scheduled.FromAccountID == accountID || scheduled.ToAccountID == accountID) {
+			count++
+		}
+	}
+	return count
+}
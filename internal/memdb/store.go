@@ -0,0 +1,1450 @@
+// Package memdb provides an in-memory implementation of db.Store.
+// It is meant for tests and local demos only and must never back a production server.
+package memdb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	db "github.com/khuongkd/simplebank/db/sqlc"
+	"github.com/khuongkd/simplebank/util"
+)
+
+var _ db.Store = (*InMemoryStore)(nil)
+
+// InMemoryStore keeps accounts, entries and transfers in maps guarded by a single mutex
+type InMemoryStore struct {
+	mu sync.Mutex
+
+	accounts           map[int64]db.Account
+	entries            map[int64]db.Entry
+	entriesArchive     map[int64]db.EntriesArchive
+	transfers          map[int64]db.Transfer
+	scheduledTransfers map[int64]db.ScheduledTransfer
+	whitelist          map[int64]map[int64]db.AccountWhitelist
+	transferHolds      map[int64]db.TransferHold
+	ownershipRequests  map[int64]db.OwnershipTransferRequest
+	// transferConfirmations are the transfers above the confirmation threshold, awaiting confirmation or confirmed
+	transferConfirmations map[int64]db.TransferConfirmation
+	accountAdjustments    map[int64]db.AccountAdjustment
+	subBalances           map[int64]map[string]db.AccountSubBalance
+	// subBalanceEntries is append-only, so an entry's ID is its position plus one
+	subBalanceEntries []db.SubBalanceEntry
+	// events is append-only, so an event's ID is its position plus one
+	events []db.Event
+	// auditLog is append-only like events
+	auditLog []db.AccountAuditLog
+
+	nextAccountID              int64
+	nextEntryID                int64
+	nextTransferID             int64
+	nextScheduledTransferID    int64
+	nextTransferHoldID         int64
+	nextOwnershipRequestID     int64
+	nextAccountAdjustmentID    int64
+	nextTransferConfirmationID int64
+
+	// policies returns the policies transfers follow, like the one given to db.SQLStore
+	policies func() util.AccountPolicies
+	// fees returns the fee of the transfers the store starts on its own, like the one given to db.SQLStore
+	fees func(amount int64, currency string) (fee int64, feeAccountID int64, err error)
+}
+
+func NewInMemoryStore() *InMemoryStore {
+	return &InMemoryStore{
+		accounts:              make(map[int64]db.Account),
+		entries:               make(map[int64]db.Entry),
+		entriesArchive:        make(map[int64]db.EntriesArchive),
+		transfers:             make(map[int64]db.Transfer),
+		scheduledTransfers:    make(map[int64]db.ScheduledTransfer),
+		whitelist:             make(map[int64]map[int64]db.AccountWhitelist),
+		transferHolds:         make(map[int64]db.TransferHold),
+		ownershipRequests:     make(map[int64]db.OwnershipTransferRequest),
+		transferConfirmations: make(map[int64]db.TransferConfirmation),
+		accountAdjustments:    make(map[int64]db.AccountAdjustment),
+		subBalances:           make(map[int64]map[string]db.AccountSubBalance),
+	}
+}
+
+// UseAccountPolicies makes every transfer follow the policy of the type of its source account, like db.SQLStore.UseAccountPolicies
+func (store *InMemoryStore) UseAccountPolicies(policies func() util.AccountPolicies) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.policies = policies
+}
+
+// accountPolicies returns the policies transfers follow, or nil when the store was given none
+func (store *InMemoryStore) accountPolicies() util.AccountPolicies {
+	if store.policies == nil {
+		return nil
+	}
+	return store.policies()
+}
+
+// UseTransferFees makes scheduled transfers and captured holds pay the fee fees returns, like db.SQLStore.UseTransferFees
+func (store *InMemoryStore) UseTransferFees(fees func(amount int64, currency string) (fee int64, feeAccountID int64, err error)) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.fees = fees
+}
+
+// transferFee returns the fee of a transfer of amount in the currency and the account credited with it,
+// or no fee when the store was given none
+func (store *InMemoryStore) transferFee(amount int64, currency string) (fee int64, feeAccountID int64, err error) {
+	if store.fees == nil {
+		return 0, 0, nil
+	}
+	return store.fees(amount, currency)
+}
+
+// Stats returns empty statistics since the in-memory store has no connection pool
+func (store *InMemoryStore) Stats() sql.DBStats {
+	return sql.DBStats{}
+}
+
+// Ping always succeeds since the in-memory store has no database to reach
+func (store *InMemoryStore) Ping(ctx context.Context) error {
+	return nil
+}
+
+// CheckSchema always succeeds since the in-memory store has no migrations
+func (store *InMemoryStore) CheckSchema(ctx context.Context) error {
+	return nil
+}
+
+// page returns the bounds of the [offset, offset+limit) window over n sorted items
+func page(n int, limit, offset int32) (start, end int) {
+	start = int(offset)
+	if start > n {
+		start = n
+	}
+	end = start + int(limit)
+	if end > n {
+		end = n
+	}
+	return
+}
+
+func (store *InMemoryStore) requireAccount(id int64) error {
+	if _, ok := store.accounts[id]; !ok {
+		return fmt.Errorf("account %d does not exist", id)
+	}
+	return nil
+}
+
+func (store *InMemoryStore) sortedAccounts() []db.Account {
+	accounts := make([]db.Account, 0, len(store.accounts))
+	for _, account := range store.accounts {
+		accounts = append(accounts, account)
+	}
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].ID < accounts[j].ID })
+	return accounts
+}
+
+func (store *InMemoryStore) sortedEntries() []db.Entry {
+	entries := make([]db.Entry, 0, len(store.entries))
+	for _, entry := range store.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+	return entries
+}
+
+func (store *InMemoryStore) sortedTransfers() []db.Transfer {
+	transfers := make([]db.Transfer, 0, len(store.transfers))
+	for _, transfer := range store.transfers {
+		transfers = append(transfers, transfer)
+	}
+	sort.Slice(transfers, func(i, j int) bool { return transfers[i].ID < transfers[j].ID })
+	return transfers
+}
+
+// checkBalance and the check functions that follow it mirror the CHECK constraints of the accounts and transfers tables
+func checkBalance(balance int64) error {
+	if balance < 0 {
+		return fmt.Errorf("%w: %s", db.ErrConstraintViolation, db.ConstraintBalanceNonNegative)
+	}
+	return nil
+}
+
+func checkAccountType(accountType string) error {
+	if accountType != db.AccountTypeChecking && accountType != db.AccountTypeSavings {
+		return fmt.Errorf("%w: %s", db.ErrConstraintViolation, db.ConstraintAccountTypeValid)
+	}
+	return nil
+}
+
+func checkMinBalance(minBalance int64) error {
+	if minBalance < 0 {
+		return fmt.Errorf("%w: %s", db.ErrConstraintViolation, db.ConstraintMinBalanceNonNegative)
+	}
+	return nil
+}
+
+func checkFreezeReason(frozen bool, reason *string) error {
+	if frozen != (reason != nil) {
+		return fmt.Errorf("%w: %s", db.ErrConstraintViolation, db.ConstraintFreezeReasonRequired)
+	}
+	return nil
+}
+
+func checkHeldBalance(balance, held int64) error {
+	if held < 0 || held > balance {
+		return fmt.Errorf("%w: %s", db.ErrConstraintViolation, db.ConstraintHeldBalanceValid)
+	}
+	return nil
+}
+
+func checkTransferAmount(amount int64) error {
+	if amount <= 0 {
+		return fmt.Errorf("%w: %s", db.ErrConstraintViolation, db.ConstraintAmountPositive)
+	}
+	return nil
+}
+
+func checkTransferFee(fee int64) error {
+	if fee < 0 {
+		return fmt.Errorf("%w: %s", db.ErrConstraintViolation, db.ConstraintFeeNonNegative)
+	}
+	return nil
+}
+
+func (store *InMemoryStore) addAccountBalance(id, amount int64) (db.Account, error) {
+	account, ok := store.accounts[id]
+	if !ok {
+		return db.Account{}, sql.ErrNoRows
+	}
+	if err := checkBalance(account.Balance + amount); err != nil {
+		return db.Account{}, err
+	}
+	if err := checkHeldBalance(account.Balance+amount, account.HeldBalance); err != nil {
+		return db.Account{}, err
+	}
+	account.Balance += amount
+	store.putAccount(account)
+	return account, nil
+}
+
+func (store *InMemoryStore) createEntry(accountID, amount int64) (db.Entry, error) {
+	if err := store.requireAccount(accountID); err != nil {
+		return db.Entry{}, err
+	}
+	store.nextEntryID++
+	entry := db.Entry{
+		ID:        store.nextEntryID,
+		AccountID: accountID,
+		Amount:    amount,
+		CreatedAt: time.Now(),
+	}
+	store.entries[entry.ID] = entry
+	return entry, nil
+}
+
+func (store *InMemoryStore) createTransfer(arg db.CreateTransferParams) (db.Transfer, error) {
+	if err := store.requireAccount(arg.FromAccountID); err != nil {
+		return db.Transfer{}, err
+	}
+	if err := store.requireAccount(arg.ToAccountID); err != nil {
+		return db.Transfer{}, err
+	}
+	if err := checkTransferAmount(arg.Amount); err != nil {
+		return db.Transfer{}, err
+	}
+	if err := store.checkExternalRef(arg.ExternalRef); err != nil {
+		return db.Transfer{}, err
+	}
+	store.nextTransferID++
+	// the currency and account numbers are copied as they are now, like the query does
+	transfer := db.Transfer{
+		ID:                store.nextTransferID,
+		FromAccountID:     arg.FromAccountID,
+		ToAccountID:       arg.ToAccountID,
+		Amount:            arg.Amount,
+		Fee:               arg.Fee,
+		CreatedAt:         time.Now(),
+		Currency:          store.accounts[arg.FromAccountID].Currency,
+		FromAccountNumber: store.accounts[arg.FromAccountID].Number,
+		ToAccountNumber:   store.accounts[arg.ToAccountID].Number,
+	}
+	if arg.ExternalRef != nil {
+		ref := *arg.ExternalRef
+		transfer.ExternalRef = &ref
+	}
+	if arg.Category != nil {
+		category := *arg.Category
+		transfer.Category = &category
+	}
+	store.transfers[transfer.ID] = transfer
+	return transfer, nil
+}
+
+// checkExternalRef mirrors the unique index on transfers.external_ref, which leaves NULL references out
+func (store *InMemoryStore) checkExternalRef(ref *string) error {
+	if ref == nil {
+		return nil
+	}
+	for _, transfer := range store.transfers {
+		if transfer.ExternalRef != nil && *transfer.ExternalRef == *ref {
+			return db.ErrDuplicateExternalRef
+		}
+	}
+	return nil
+}
+
+func (store *InMemoryStore) AddAccountBalance(ctx context.Context, arg db.AddAccountBalanceParams) (db.Account, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return store.addAccountBalance(arg.ID, arg.Amount)
+}
+
+func (store *InMemoryStore) CountAccountsByOwnerSince(ctx context.Context, arg db.CountAccountsByOwnerSinceParams) (int64, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var count int64
+	for _, account := range store.accounts {
+		if account.Owner == arg.Owner && !account.CreatedAt.Before(arg.Since) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (store *InMemoryStore) CreateAcount(ctx context.Context, arg db.CreateAcountParams) (db.Account, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if err := checkBalance(arg.Balance); err != nil {
+		return db.Account{}, err
+	}
+	accountType := arg.AccountType
+	if accountType == "" {
+		accountType = db.AccountTypeChecking
+	}
+	if err := checkAccountType(accountType); err != nil {
+		return db.Account{}, err
+	}
+	store.nextAccountID++
+	account := db.Account{
+		ID:          store.nextAccountID,
+		Owner:       arg.Owner,
+		Balance:     arg.Balance,
+		Currency:    arg.Currency,
+		CreatedAt:   time.Now(),
+		Number:      store.nextAccountNumber(arg.Currency, arg.NumberPerCurrency),
+		AccountType: accountType,
+		Metadata:    json.RawMessage(`{}`),
+	}
+	store.putAccount(account)
+	return account, nil
+}
+
+// nextAccountNumber returns the number following the highest one in use within the currency,
+// or across all accounts unless numbers are assigned per currency
+func (store *InMemoryStore) nextAccountNumber(currency string, perCurrency bool) int64 {
+	var max int64
+	for _, account := range store.accounts {
+		if (!perCurrency || account.Currency == currency) && account.Number > max {
+			max = account.Number
+		}
+	}
+	return max + 1
+}
+
+func (store *InMemoryStore) CreateEntry(ctx context.Context, arg db.CreateEntryParams) (db.Entry, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return store.createEntry(arg.AccountID, arg.Amount)
+}
+
+func (store *InMemoryStore) CreateTransfer(ctx context.Context, arg db.CreateTransferParams) (db.Transfer, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return store.createTransfer(arg)
+}
+
+func (store *InMemoryStore) DeleteAccount(ctx context.Context, id int64) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.deleteAccount(id)
+	return nil
+}
+
+func (store *InMemoryStore) DeleteEntry(ctx context.Context, id int64) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	delete(store.entries, id)
+	return nil
+}
+
+func (store *InMemoryStore) DeleteTransfer(ctx context.Context, id int64) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	delete(store.transfers, id)
+	return nil
+}
+
+func (store *InMemoryStore) GetAccount(ctx context.Context, id int64) (db.Account, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	account, ok := store.accounts[id]
+	if !ok {
+		return db.Account{}, sql.ErrNoRows
+	}
+	return account, nil
+}
+
+func (store *InMemoryStore) GetAccountByNumberAndCurrency(ctx context.Context, arg db.GetAccountByNumberAndCurrencyParams) (db.Account, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	for _, account := range store.accounts {
+		if account.Number == arg.Number && account.Currency == arg.Currency {
+			return account, nil
+		}
+	}
+	return db.Account{}, sql.ErrNoRows
+}
+
+func (store *InMemoryStore) GetAccountForUpdate(ctx context.Context, id int64) (db.Account, error) {
+	return store.GetAccount(ctx, id)
+}
+
+func (store *InMemoryStore) GetEntry(ctx context.Context, id int64) (db.Entry, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	entry, ok := store.entries[id]
+	if !ok {
+		return db.Entry{}, sql.ErrNoRows
+	}
+	return entry, nil
+}
+
+func (store *InMemoryStore) GetTransfer(ctx context.Context, id int64) (db.Transfer, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	transfer, ok := store.transfers[id]
+	if !ok {
+		return db.Transfer{}, sql.ErrNoRows
+	}
+	return transfer, nil
+}
+
+func (store *InMemoryStore) SetAccountMinBalance(ctx context.Context, arg db.SetAccountMinBalanceParams) (db.Account, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	account, ok := store.accounts[arg.ID]
+	if !ok {
+		return db.Account{}, sql.ErrNoRows
+	}
+	if err := checkMinBalance(arg.MinBalance); err != nil {
+		return db.Account{}, err
+	}
+	account.MinBalance = arg.MinBalance
+	store.putAccount(account)
+	return account, nil
+}
+
+// errInvalidMetadata is what Postgres reports as a failed cast to jsonb
+var errInvalidMetadata = errors.New("invalid input syntax for type json")
+
+func (store *InMemoryStore) PatchAccount(ctx context.Context, arg db.PatchAccountParams) (db.Account, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	account, ok := store.accounts[arg.ID]
+	if !ok {
+		return db.Account{}, sql.ErrNoRows
+	}
+	if arg.SetMetadata && !json.Valid([]byte(arg.Metadata)) {
+		return db.Account{}, errInvalidMetadata
+	}
+	if arg.SetNickname {
+		account.Nickname = nil
+		if arg.Nickname != "" {
+			nickname := arg.Nickname
+			account.Nickname = &nickname
+		}
+	}
+	if arg.SetMetadata {
+		account.Metadata = json.RawMessage(arg.Metadata)
+	}
+	if arg.SetFrozen {
+		account.Frozen = arg.Frozen
+		account.FreezeReason = nil
+		if arg.FreezeReason != "" {
+			reason := arg.FreezeReason
+			account.FreezeReason = &reason
+		}
+		if err := checkFreezeReason(account.Frozen, account.FreezeReason); err != nil {
+			return db.Account{}, err
+		}
+	}
+	store.putAccount(account)
+	return account, nil
+}
+
+func (store *InMemoryStore) ListAccounts(ctx context.Context, arg db.ListAccountsParams) ([]db.Account, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	accounts := store.sortedAccounts()
+	start, end := page(len(accounts), arg.Limit, arg.Offset)
+	var items []db.Account
+	items = append(items, accounts[start:end]...)
+	return items, nil
+}
+
+func (store *InMemoryStore) ListDormantAccounts(ctx context.Context, arg db.ListDormantAccountsParams) ([]db.Account, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	active := make(map[int64]bool)
+	for _, entry := range store.entries {
+		if !entry.CreatedAt.Before(arg.Since) {
+			active[entry.AccountID] = true
+		}
+	}
+	for _, entry := range store.entriesArchive {
+		if !entry.CreatedAt.Before(arg.Since) {
+			active[entry.AccountID] = true
+		}
+	}
+
+	var dormant []db.Account
+	for _, account := range store.sortedAccounts() {
+		if !active[account.ID] {
+			dormant = append(dormant, account)
+		}
+	}
+	start, end := page(len(dormant), arg.Limit, arg.Offset)
+	var items []db.Account
+	items = append(items, dormant[start:end]...)
+	return items, nil
+}
+
+func (store *InMemoryStore) ListFrozenAccounts(ctx context.Context, arg db.ListFrozenAccountsParams) ([]db.Account, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var frozen []db.Account
+	for _, account := range store.sortedAccounts() {
+		if account.Frozen && (arg.Reason == "" || (account.FreezeReason != nil && *account.FreezeReason == arg.Reason)) {
+			frozen = append(frozen, account)
+		}
+	}
+	start, end := page(len(frozen), arg.Limit, arg.Offset)
+	var items []db.Account
+	items = append(items, frozen[start:end]...)
+	return items, nil
+}
+
+func (store *InMemoryStore) TopAccountsByBalance(ctx context.Context, arg db.TopAccountsByBalanceParams) ([]db.Account, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var accounts []db.Account
+	for _, account := range store.sortedAccounts() {
+		if account.Currency == arg.Currency {
+			accounts = append(accounts, account)
+		}
+	}
+	sort.SliceStable(accounts, func(i, j int) bool { return accounts[i].Balance > accounts[j].Balance })
+	start, end := page(len(accounts), arg.Limit, 0)
+	var items []db.Account
+	items = append(items, accounts[start:end]...)
+	return items, nil
+}
+
+func (store *InMemoryStore) ListAccountsAfter(ctx context.Context, arg db.ListAccountsAfterParams) ([]db.Account, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var items []db.Account
+	for _, account := range store.sortedAccounts() {
+		if int32(len(items)) >= arg.BatchSize {
+			break
+		}
+		if account.ID > arg.AfterID {
+			items = append(items, account)
+		}
+	}
+	return items, nil
+}
+
+func (store *InMemoryStore) ListEntries(ctx context.Context, arg db.ListEntriesParams) ([]db.Entry, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	entries := store.sortedEntries()
+	start, end := page(len(entries), arg.Limit, arg.Offset)
+	var items []db.Entry
+	items = append(items, entries[start:end]...)
+	return items, nil
+}
+
+func (store *InMemoryStore) ListTransfers(ctx context.Context, arg db.ListTransfersParams) ([]db.Transfer, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	transfers := store.sortedTransfers()
+	start, end := page(len(transfers), arg.Limit, arg.Offset)
+	var items []db.Transfer
+	items = append(items, transfers[start:end]...)
+	return items, nil
+}
+
+func (store *InMemoryStore) GetTransfersByIDs(ctx context.Context, arg db.GetTransfersByIDsParams) ([]db.Transfer, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	ids := make(map[int64]bool, len(arg.Ids))
+	for _, id := range arg.Ids {
+		ids[id] = true
+	}
+	var items []db.Transfer
+	for _, transfer := range store.sortedTransfers() {
+		if !ids[transfer.ID] {
+			continue
+		}
+		if store.accounts[transfer.FromAccountID].Owner == arg.Owner || store.accounts[transfer.ToAccountID].Owner == arg.Owner {
+			items = append(items, transfer)
+		}
+	}
+	return items, nil
+}
+
+func (store *InMemoryStore) FindCounterpartyAccounts(ctx context.Context, arg db.FindCounterpartyAccountsParams) ([]db.FindCounterpartyAccountsRow, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	counterparties := make(map[int64]*db.FindCounterpartyAccountsRow)
+	for _, transfer := range store.transfers {
+		var id int64
+		switch arg.AccountID {
+		case transfer.FromAccountID:
+			id = transfer.ToAccountID
+		case transfer.ToAccountID:
+			id = transfer.FromAccountID
+		default:
+			continue
+		}
+		account, ok := store.accounts[id]
+		if !ok || id == arg.AccountID {
+			continue
+		}
+
+		row, ok := counterparties[id]
+		if !ok {
+			row = &db.FindCounterpartyAccountsRow{
+				ID:       account.ID,
+				Owner:    account.Owner,
+				Currency: account.Currency,
+				Frozen:   account.Frozen,
+			}
+			counterparties[id] = row
+		}
+		row.Transfers++
+		direction := db.CounterpartyIncoming
+		if transfer.FromAccountID == arg.AccountID {
+			direction = db.CounterpartyOutgoing
+			row.AmountSent += transfer.Amount
+		} else {
+			row.AmountReceived += transfer.Amount
+		}
+		if row.Direction == "" {
+			row.Direction = direction
+		} else if row.Direction != direction {
+			row.Direction = db.CounterpartyBoth
+		}
+	}
+
+	rows := make([]db.FindCounterpartyAccountsRow, 0, len(counterparties))
+	for _, row := range counterparties {
+		rows = append(rows, *row)
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].ID < rows[j].ID })
+	start, end := page(len(rows), arg.Limit, arg.Offset)
+	var items []db.FindCounterpartyAccountsRow
+	items = append(items, rows[start:end]...)
+	return items, nil
+}
+
+func (store *InMemoryStore) SumTransferFeesByAccount(ctx context.Context, fromAccountID int64) (int64, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var total int64
+	for _, transfer := range store.transfers {
+		if transfer.FromAccountID == fromAccountID {
+			total += transfer.Fee
+		}
+	}
+	return total, nil
+}
+
+func (store *InMemoryStore) CountRecentTransfersFromAccount(ctx context.Context, arg db.CountRecentTransfersFromAccountParams) (int64, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var count int64
+	for _, transfer := range store.transfers {
+		if transfer.FromAccountID == arg.FromAccountID && !transfer.CreatedAt.Before(arg.Since) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (store *InMemoryStore) RecentSimilarTransferExists(ctx context.Context, arg db.RecentSimilarTransferExistsParams) (bool, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	for _, transfer := range store.transfers {
+		if transfer.FromAccountID == arg.FromAccountID &&
+			transfer.ToAccountID == arg.ToAccountID &&
+			transfer.Amount == arg.Amount &&
+			!transfer.CreatedAt.Before(arg.Since) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (store *InMemoryStore) SpendingByCategory(ctx context.Context, arg db.SpendingByCategoryParams) ([]db.SpendingByCategoryRow, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	totals := make(map[string]*db.SpendingByCategoryRow)
+	for _, transfer := range store.transfers {
+		if transfer.FromAccountID != arg.AccountID ||
+			transfer.CreatedAt.Before(arg.FromTime) || !transfer.CreatedAt.Before(arg.ToTime) {
+			continue
+		}
+		category := ""
+		if transfer.Category != nil {
+			category = *transfer.Category
+		}
+		row, ok := totals[category]
+		if !ok {
+			row = &db.SpendingByCategoryRow{Category: category}
+			totals[category] = row
+		}
+		row.Total += transfer.Amount
+		row.Transfers++
+	}
+
+	var rows []db.SpendingByCategoryRow
+	for _, row := range totals {
+		rows = append(rows, *row)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Total != rows[j].Total {
+			return rows[i].Total > rows[j].Total
+		}
+		return rows[i].Category < rows[j].Category
+	})
+	return rows, nil
+}
+
+func (store *InMemoryStore) SetAccountCurrency(ctx context.Context, arg db.SetAccountCurrencyParams) (db.Account, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return store.setAccountCurrency(arg)
+}
+
+func (store *InMemoryStore) setAccountCurrency(arg db.SetAccountCurrencyParams) (db.Account, error) {
+	account, ok := store.accounts[arg.ID]
+	if !ok {
+		return db.Account{}, sql.ErrNoRows
+	}
+	if err := checkBalance(arg.Balance); err != nil {
+		return db.Account{}, err
+	}
+	if err := checkHeldBalance(arg.Balance, account.HeldBalance); err != nil {
+		return db.Account{}, err
+	}
+	if arg.NumberPerCurrency {
+		account.Number = store.nextAccountNumber(arg.Currency, true)
+	}
+	account.Currency = arg.Currency
+	account.Balance = arg.Balance
+	account.MinBalance = arg.MinBalance
+	store.putAccount(account)
+	return account, nil
+}
+
+// ConvertAccountCurrencyTx converts the balance and the minimum balance of an account at the given rate and switches it to the new currency,
+// folding the sub-balance it holds in the new currency into its balance.
+func (store *InMemoryStore) ConvertAccountCurrencyTx(ctx context.Context, params db.ConvertAccountCurrencyTxParams) (db.ConvertAccountCurrencyTxResult, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var result db.ConvertAccountCurrencyTxResult
+	account, ok := store.accounts[params.AccountID]
+	if !ok {
+		return result, sql.ErrNoRows
+	}
+	if account.Currency == params.Currency {
+		return result, db.ErrCurrencyUnchanged
+	}
+	if !params.AllowedPairs.Allows(account.Currency, params.Currency) {
+		return result, fmt.Errorf("%w: %s to %s", db.ErrCurrencyPairNotAllowed, account.Currency, params.Currency)
+	}
+	if store.countAuthorizedHolds(params.AccountID) > 0 {
+		return result, db.ErrPendingHolds
+	}
+	if store.countPendingScheduledTransfers(params.AccountID) > 0 {
+		return result, db.ErrPendingScheduledTransfers
+	}
+	if store.countPendingTransferConfirmations(params.AccountID, time.Now()) > 0 {
+		return result, db.ErrPendingConfirmations
+	}
+
+	result.RoundingMode = params.RoundingMode
+	if result.RoundingMode == "" {
+		result.RoundingMode = util.RoundHalfUp
+	}
+	balance, err := util.ConvertAmount(account.Balance, params.Rate, params.Currency, result.RoundingMode)
+	if err != nil {
+		return result, err
+	}
+	minBalance, err := util.ConvertAmount(account.MinBalance, params.Rate, params.Currency, result.RoundingMode)
+	if err != nil {
+		return result, err
+	}
+	subBalance := store.subBalances[params.AccountID][params.Currency].Balance
+	if err := checkBalance(balance + subBalance); err != nil {
+		return result, err
+	}
+
+	if subBalance > 0 {
+		folded, err := store.changeBalance(&account, params.Currency, -subBalance)
+		if err != nil {
+			return result, err
+		}
+		result.FoldedSubBalance = &folded
+		balance += subBalance
+	}
+	result.Entry, err = store.createEntry(params.AccountID, balance-account.Balance)
+	if err != nil {
+		return result, err
+	}
+	result.Account, err = store.setAccountCurrency(db.SetAccountCurrencyParams{
+		ID:                params.AccountID,
+		Currency:          params.Currency,
+		Balance:           balance,
+		MinBalance:        minBalance,
+		NumberPerCurrency: params.NumberPerCurrency,
+	})
+	return result, err
+}
+
+func (store *InMemoryStore) SumEntriesByAccount(ctx context.Context, accountID int64) (int64, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return store.sumEntries(accountID), nil
+}
+
+func (store *InMemoryStore) SumEntriesByAccountAsOf(ctx context.Context, arg db.SumEntriesByAccountAsOfParams) (int64, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return store.sumEntriesAsOf(arg.AccountID, arg.AsOf), nil
+}
+
+func (store *InMemoryStore) sumEntriesAsOf(accountID int64, t time.Time) int64 {
+	var total int64
+	for _, entry := range store.entries {
+		if entry.AccountID == accountID && !entry.CreatedAt.After(t) {
+			total += entry.Amount
+		}
+	}
+	for _, entry := range store.entriesArchive {
+		if entry.AccountID == accountID && !entry.CreatedAt.After(t) {
+			total += entry.Amount
+		}
+	}
+	return total
+}
+
+// AccountBalanceAsOf reconstructs the balance an account had at t from its entries.
+func (store *InMemoryStore) AccountBalanceAsOf(ctx context.Context, accountID int64, t time.Time) (int64, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	account, ok := store.accounts[accountID]
+	if !ok {
+		return 0, sql.ErrNoRows
+	}
+	if t.Before(account.CreatedAt) {
+		return 0, nil
+	}
+	if !t.Before(time.Now()) {
+		return account.Balance, nil
+	}
+	return store.sumEntriesAsOf(accountID, t), nil
+}
+
+func (store *InMemoryStore) ListEntriesWithRunningBalance(ctx context.Context, arg db.ListEntriesWithRunningBalanceParams) ([]db.ListEntriesWithRunningBalanceRow, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	entries := store.statementEntries(arg.AccountID, time.Time{}, time.Unix(1<<62, 0))
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].CreatedAt.Before(entries[j].CreatedAt) })
+
+	rows := make([]db.ListEntriesWithRunningBalanceRow, len(entries))
+	var balance int64
+	for i, entry := range entries {
+		balance += entry.Amount
+		rows[i] = db.ListEntriesWithRunningBalanceRow{
+			ID:             entry.ID,
+			AccountID:      entry.AccountID,
+			Amount:         entry.Amount,
+			CreatedAt:      entry.CreatedAt,
+			RunningBalance: balance,
+		}
+	}
+	start, end := page(len(rows), arg.Limit, arg.Offset)
+	var items []db.ListEntriesWithRunningBalanceRow
+	items = append(items, rows[start:end]...)
+	return items, nil
+}
+
+// ListEntriesWithBalance returns a page of the entries of an account, archived ones included, oldest first,
+// each with the running balance of the account.
+func (store *InMemoryStore) ListEntriesWithBalance(ctx context.Context, accountID int64, limit, offset int32) ([]db.EntryWithBalance, error) {
+	rows, err := store.ListEntriesWithRunningBalance(ctx, db.ListEntriesWithRunningBalanceParams{
+		AccountID: accountID,
+		Limit:     limit,
+		Offset:    offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]db.EntryWithBalance, len(rows))
+	for i, row := range rows {
+		entries[i] = db.EntryWithBalance{
+			Entry: db.Entry{
+				ID:        row.ID,
+				AccountID: row.AccountID,
+				Amount:    row.Amount,
+				CreatedAt: row.CreatedAt,
+			},
+			RunningBalance: row.RunningBalance,
+		}
+	}
+	return entries, nil
+}
+
+func (store *InMemoryStore) sumEntries(accountID int64) int64 {
+	var total int64
+	for _, entry := range store.entries {
+		if entry.AccountID == accountID {
+			total += entry.Amount
+		}
+	}
+	for _, entry := range store.entriesArchive {
+		if entry.AccountID == accountID {
+			total += entry.Amount
+		}
+	}
+	return total
+}
+
+// ReconcileAccount compares the balance of an account with the sum of its entries.
+func (store *InMemoryStore) ReconcileAccount(ctx context.Context, accountID int64) (db.AccountReconciliation, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	account, ok := store.accounts[accountID]
+	if !ok {
+		return db.AccountReconciliation{}, sql.ErrNoRows
+	}
+
+	total := store.sumEntries(accountID)
+	return db.AccountReconciliation{
+		AccountID:    account.ID,
+		Balance:      account.Balance,
+		EntriesTotal: total,
+		Discrepancy:  account.Balance - total,
+	}, nil
+}
+
+// FindBalanceDiscrepancies lists the accounts whose balance differs from the sum of their entries, in id order.
+func (store *InMemoryStore) FindBalanceDiscrepancies(ctx context.Context, arg db.FindBalanceDiscrepanciesParams) ([]db.FindBalanceDiscrepanciesRow, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	totals := make(map[int64]int64)
+	for _, entry := range store.entries {
+		totals[entry.AccountID] += entry.Amount
+	}
+	for _, entry := range store.entriesArchive {
+		totals[entry.AccountID] += entry.Amount
+	}
+
+	var discrepancies []db.FindBalanceDiscrepanciesRow
+	for _, account := range store.sortedAccounts() {
+		total := totals[account.ID]
+		if account.Balance != total {
+			discrepancies = append(discrepancies, db.FindBalanceDiscrepanciesRow{
+				AccountID:    account.ID,
+				Balance:      account.Balance,
+				EntriesTotal: total,
+				Discrepancy:  account.Balance - total,
+			})
+		}
+	}
+	start, end := page(len(discrepancies), arg.Limit, arg.Offset)
+	var items []db.FindBalanceDiscrepanciesRow
+	items = append(items, discrepancies[start:end]...)
+	return items, nil
+}
+
+// TotalSystemBalance sums the balances and entries of all accounts per currency, in currency order.
+func (store *InMemoryStore) TotalSystemBalance(ctx context.Context) ([]db.TotalSystemBalanceRow, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	totals := make(map[int64]int64)
+	for _, entry := range store.entries {
+		totals[entry.AccountID] += entry.Amount
+	}
+	for _, entry := range store.entriesArchive {
+		totals[entry.AccountID] += entry.Amount
+	}
+
+	byCurrency := make(map[string]*db.TotalSystemBalanceRow)
+	var currencies []string
+	for _, account := range store.accounts {
+		row, ok := byCurrency[account.Currency]
+		if !ok {
+			row = &db.TotalSystemBalanceRow{Currency: account.Currency}
+			byCurrency[account.Currency] = row
+			currencies = append(currencies, account.Currency)
+		}
+		row.Accounts++
+		row.Balance += account.Balance
+		row.EntriesTotal += totals[account.ID]
+		row.Discrepancy += account.Balance - totals[account.ID]
+	}
+
+	// sub-balances count towards their own currency, backed by their sub-balance entries
+	subTotals := make(map[string]int64)
+	for _, entry := range store.subBalanceEntries {
+		subTotals[entry.Currency] += entry.Amount
+	}
+	for _, subBalances := range store.subBalances {
+		for _, subBalance := range subBalances {
+			row, ok := byCurrency[subBalance.Currency]
+			if !ok {
+				row = &db.TotalSystemBalanceRow{Currency: subBalance.Currency}
+				byCurrency[subBalance.Currency] = row
+				currencies = append(currencies, subBalance.Currency)
+			}
+			row.Balance += subBalance.Balance
+			row.Discrepancy += subBalance.Balance
+		}
+	}
+	for currency, total := range subTotals {
+		row := byCurrency[currency]
+		row.EntriesTotal += total
+		row.Discrepancy -= total
+	}
+
+	sort.Strings(currencies)
+	var rows []db.TotalSystemBalanceRow
+	for _, currency := range currencies {
+		rows = append(rows, *byCurrency[currency])
+	}
+	return rows, nil
+}
+
+// StreamAllAccounts calls fn with every account in id order.
+// The accounts are copied first so fn may call back into the store.
+func (store *InMemoryStore) StreamAllAccounts(ctx context.Context, fn func(db.Account) error) error {
+	store.mu.Lock()
+	accounts := store.sortedAccounts()
+	store.mu.Unlock()
+
+	for _, account := range accounts {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(account); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (store *InMemoryStore) UpdateAccount(ctx context.Context, arg db.UpdateAccountParams) (db.Account, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	account, ok := store.accounts[arg.ID]
+	if !ok {
+		return db.Account{}, sql.ErrNoRows
+	}
+	if err := checkBalance(arg.Balance); err != nil {
+		return db.Account{}, err
+	}
+	if err := checkHeldBalance(arg.Balance, account.HeldBalance); err != nil {
+		return db.Account{}, err
+	}
+	account.Balance = arg.Balance
+	store.putAccount(account)
+	return account, nil
+}
+
+func (store *InMemoryStore) UpdateEntry(ctx context.Context, arg db.UpdateEntryParams) (db.Entry, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	entry, ok := store.entries[arg.ID]
+	if !ok {
+		return db.Entry{}, sql.ErrNoRows
+	}
+	entry.Amount = arg.Amount
+	store.entries[arg.ID] = entry
+	return entry, nil
+}
+
+func (store *InMemoryStore) UpdateTransfer(ctx context.Context, arg db.UpdateTransferParams) (db.Transfer, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	transfer, ok := store.transfers[arg.ID]
+	if !ok {
+		return db.Transfer{}, sql.ErrNoRows
+	}
+	if err := store.requireAccount(arg.FromAccountID); err != nil {
+		return db.Transfer{}, err
+	}
+	if err := store.requireAccount(arg.ToAccountID); err != nil {
+		return db.Transfer{}, err
+	}
+	if err := checkTransferAmount(arg.Amount); err != nil {
+		return db.Transfer{}, err
+	}
+	transfer.Amount = arg.Amount
+	transfer.FromAccountID = arg.FromAccountID
+	transfer.ToAccountID = arg.ToAccountID
+	store.transfers[arg.ID] = transfer
+	return transfer, nil
+}
+
+// TransferTx performs a money transfer from one account to another account.
+// All checks run before any state changes, so a failed transfer leaves the store untouched.
+func (store *InMemoryStore) TransferTx(ctx context.Context, params db.TransferTxParams) (db.TransferTxResult, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if err := store.requireAccount(params.FromAccountID); err != nil {
+		return db.TransferTxResult{}, err
+	}
+	if err := store.requireAccount(params.ToAccountID); err != nil {
+		return db.TransferTxResult{}, err
+	}
+
+	return store.transfer(params)
+}
+
+// BatchTransferTx performs the transfers in order; when one fails, the ones before it are rolled back.
+func (store *InMemoryStore) BatchTransferTx(ctx context.Context, params []db.TransferTxParams) ([]db.TransferTxResult, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	saved := store.saveTransferState()
+	results := make([]db.TransferTxResult, 0, len(params))
+	for i, p := range params {
+		err := store.requireAccount(p.FromAccountID)
+		if err == nil {
+			err = store.requireAccount(p.ToAccountID)
+		}
+		var result db.TransferTxResult
+		if err == nil {
+			result, err = store.transfer(p)
+		}
+		if err != nil {
+			store.restoreTransferState(saved)
+			return nil, &db.BatchTransferError{Index: i, Err: err}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// SimulateTransfersTx makes the transfers like BatchTransferTx, undoing each one that fails on its own,
+// then restores the store to what it was before the first one
+func (store *InMemoryStore) SimulateTransfersTx(ctx context.Context, params []db.TransferTxParams) (db.TransferSimulation, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	initial := store.saveTransferState()
+	defer store.restoreTransferState(initial)
+
+	simulation := db.TransferSimulation{Transfers: make([]db.SimulatedTransfer, len(params))}
+	touched := make(map[int64]bool)
+	for i, p := range params {
+		saved := store.saveTransferState()
+		err := store.requireAccount(p.FromAccountID)
+		if err == nil {
+			err = store.requireAccount(p.ToAccountID)
+		}
+		var result db.TransferTxResult
+		if err == nil {
+			result, err = store.transfer(p)
+		}
+		if err != nil {
+			store.restoreTransferState(saved)
+		}
+		simulation.Transfers[i] = db.SimulatedTransfer{Index: i, Result: result, Err: err}
+
+		touched[p.FromAccountID] = true
+		touched[p.ToAccountID] = true
+		if p.Fee > 0 {
+			touched[p.FeeAccountID] = true
+		}
+	}
+
+	for _, account := range store.sortedAccounts() {
+		if touched[account.ID] {
+			simulation.Balances = append(simulation.Balances, db.ProjectedBalance{AccountID: account.ID, Balance: account.Balance})
+		}
+	}
+	return simulation, nil
+}
+
+// transferState is what transfers change in the store, saved so that a failed batch can be rolled back
+type transferState struct {
+	accounts       map[int64]db.Account
+	entries        map[int64]db.Entry
+	transfers      map[int64]db.Transfer
+	events         int
+	auditLog       int
+	nextEntryID    int64
+	nextTransferID int64
+}
+
+func (store *InMemoryStore) saveTransferState() transferState {
+	state := transferState{
+		accounts:       make(map[int64]db.Account, len(store.accounts)),
+		entries:        make(map[int64]db.Entry, len(store.entries)),
+		transfers:      make(map[int64]db.Transfer, len(store.transfers)),
+		events:         len(store.events),
+		auditLog:       len(store.auditLog),
+		nextEntryID:    store.nextEntryID,
+		nextTransferID: store.nextTransferID,
+	}
+	for id, account := range store.accounts {
+		state.accounts[id] = account
+	}
+	for id, entry := range store.entries {
+		state.entries[id] = entry
+	}
+	for id, transfer := range store.transfers {
+		state.transfers[id] = transfer
+	}
+	return state
+}
+
+func (store *InMemoryStore) restoreTransferState(state transferState) {
+	store.accounts = state.accounts
+	store.entries = state.entries
+	store.transfers = state.transfers
+	store.events = store.events[:state.events]
+	store.auditLog = store.auditLog[:state.auditLog]
+	store.nextEntryID = state.nextEntryID
+	store.nextTransferID = state.nextTransferID
+}
+
+// SweepOwnAccountsTx moves the whole available balance of one account to another account of the same owner.
+func (store *InMemoryStore) SweepOwnAccountsTx(ctx context.Context, fromAccountID, toAccountID int64, owner string) (db.TransferTxResult, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	fromAccount, ok := store.accounts[fromAccountID]
+	if !ok {
+		return db.TransferTxResult{}, sql.ErrNoRows
+	}
+	toAccount, ok := store.accounts[toAccountID]
+	if !ok {
+		return db.TransferTxResult{}, sql.ErrNoRows
+	}
+
+	if fromAccount.Owner != owner || toAccount.Owner != owner {
+		return db.TransferTxResult{}, db.ErrAccountOwnerMismatch
+	}
+	if fromAccount.Currency != toAccount.Currency {
+		return db.TransferTxResult{}, db.ErrCurrencyMismatch
+	}
+	// the higher of both minimum balances stays behind, like in db.SweepOwnAccountsTx
+	floor := fromAccount.MinBalance
+	policy := store.accountPolicies().For(fromAccount.AccountType)
+	if !policy.AllowOverdraft && policy.MinBalance > floor {
+		floor = policy.MinBalance
+	}
+	available := fromAccount.Balance - fromAccount.HeldBalance - floor
+	if available <= 0 {
+		return db.TransferTxResult{}, db.ErrNothingToSweep
+	}
+
+	return store.transfer(db.TransferTxParams{
+		FromAccountID: fromAccountID,
+		ToAccountID:   toAccountID,
+		Amount:        available,
+	})
+}
+
+// checkNotFrozen mirrors the frozen check db.TransferTx makes on the locked accounts
+func checkNotFrozen(accounts ...db.Account) error {
+	for _, account := range accounts {
+		if account.Frozen {
+			return fmt.Errorf("%w: account [%d]", db.ErrAccountFrozen, account.ID)
+		}
+	}
+	return nil
+}
+
+// checkAccountPolicy mirrors the policy checks of the account type made by db.TransferTx
+func checkAccountPolicy(policy util.AccountPolicy, amount, balanceAfter int64) error {
+	if policy.MaxTransferAmount > 0 && amount > policy.MaxTransferAmount {
+		return fmt.Errorf("%w: %d is above the maximum of %d", db.ErrTransferLimitExceeded, amount, policy.MaxTransferAmount)
+	}
+	if !policy.AllowOverdraft && balanceAfter < policy.MinBalance {
+		return fmt.Errorf("%w: %d is below the minimum of %d", db.ErrBelowMinimumBalance, balanceAfter, policy.MinBalance)
+	}
+	return nil
+}
+
+// isInternalTransfer mirrors the free transfers between accounts of the same owner of db.TransferTx
+func isInternalTransfer(from, to db.Account) bool {
+	return from.ID != to.ID && from.Owner == to.Owner && from.Currency == to.Currency
+}
+
+// transfer records a transfer between two existing accounts; callers must hold the mutex.
+// The constraints are checked up front since there is no transaction to roll back.
+func (store *InMemoryStore) transfer(params db.TransferTxParams) (db.TransferTxResult, error) {
+	var result db.TransferTxResult
+	if err := checkTransferAmount(params.Amount); err != nil {
+		return result, err
+	}
+	fromAccount := store.accounts[params.FromAccountID]
+	if err := checkNotFrozen(fromAccount, store.accounts[params.ToAccountID]); err != nil {
+		return result, err
+	}
+	if isInternalTransfer(fromAccount, store.accounts[params.ToAccountID]) {
+		params.Fee = 0
+		params.FeeAccountID = 0
+	}
+	if err := checkTransferFee(params.Fee); err != nil {
+		return result, err
+	}
+	debit := params.Amount + params.Fee
+	if fromAccount.Balance-fromAccount.HeldBalance < debit {
+		return result, fmt.Errorf("%w: %d available, %d needed", db.ErrInsufficientFunds, fromAccount.Balance-fromAccount.HeldBalance, debit)
+	}
+	if err := store.checkWhitelisted(params.FromAccountID, params.ToAccountID); err != nil {
+		return result, err
+	}
+	if err := checkAccountPolicy(store.accountPolicies().For(fromAccount.AccountType), params.Amount, fromAccount.Balance-debit); err != nil {
+		return result, err
+	}
+	if fromAccount.Balance-debit < fromAccount.MinBalance {
+		return result, fmt.Errorf("%w: %d is below the minimum of %d", db.ErrMinBalanceViolation, fromAccount.Balance-debit, fromAccount.MinBalance)
+	}
+	if params.Fee > 0 {
+		feeAccount, ok := store.accounts[params.FeeAccountID]
+		if !ok {
+			return result, sql.ErrNoRows
+		}
+		if feeAccount.Currency != fromAccount.Currency {
+			return result, fmt.Errorf("%w: fee account [%d] holds %s", db.ErrCurrencyMismatch, params.FeeAccountID, feeAccount.Currency)
+		}
+	}
+
+	var err error
+	transferArg := db.CreateTransferParams{
+		FromAccountID: params.FromAccountID,
+		ToAccountID:   params.ToAccountID,
+		Amount:        params.Amount,
+		Fee:           params.Fee,
+	}
+	if params.ExternalRef != "" {
+		transferArg.ExternalRef = &params.ExternalRef
+	}
+	if params.Category != "" {
+		transferArg.Category = &params.Category
+	}
+	result.Transfer, err = store.createTransfer(transferArg)
+	if err != nil {
+		return result, err
+	}
+	result.FromEntry, err = store.createEntry(params.FromAccountID, -params.Amount)
+	if err != nil {
+		return result, err
+	}
+	result.ToEntry, err = store.createEntry(params.ToAccountID, params.Amount)
+	if err != nil {
+		return result, err
+	}
+	result.FromAccount, err = store.addAccountBalance(params.FromAccountID, -params.Amount)
+	if err != nil {
+		return result, err
+	}
+	result.ToAccount, err = store.addAccountBalance(params.ToAccountID, params.Amount)
+	if err != nil {
+		return result, err
+	}
+	if params.Fee == 0 {
+		return result, store.recordTransferEvents(result.Transfer, params)
+	}
+
+	feeEntry, err := store.createEntry(params.FromAccountID, -params.Fee)
+	if err != nil {
+		return result, err
+	}
+	result.FeeEntry = &feeEntry
+	if _, err := store.createEntry(params.FeeAccountID, params.Fee); err != nil {
+		return result, err
+	}
+	result.FromAccount, err = store.addAccountBalance(params.FromAccountID, -params.Fee)
+	if err != nil {
+		return result, err
+	}
+	feeAccount, err := store.addAccountBalance(params.FeeAccountID, params.Fee)
+	if err != nil {
+		return result, err
+	}
+	switch params.FeeAccountID {
+	case params.FromAccountID:
+		result.FromAccount = feeAccount
+	case params.ToAccountID:
+		result.ToAccount = feeAccount
+	}
+
+	return result, store.recordTransferEvents(result.Transfer, params)
+}
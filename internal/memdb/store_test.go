@@ -0,0 +1,2043 @@
+package memdb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	db "github.com/khuongkd/simplebank/db/sqlc"
+	"github.com/khuongkd/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createTestAccount(t *testing.T, store *InMemoryStore) db.Account {
+	currency := util.RandomCurrency()
+	arg := db.CreateAcountParams{
+		Owner:    util.RandomOwner(),
+		Balance:  util.RandomMoneyForCurrency(currency) + util.CurrencyAmountStep(currency),
+		Currency: currency,
+	}
+
+	account, err := store.CreateAcount(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, account.ID)
+	require.Equal(t, arg.Balance, account.Balance)
+	return account
+}
+
+func TestInMemoryStoreImplementsStore(t *testing.T) {
+	var store db.Store = NewInMemoryStore()
+	require.NotNil(t, store)
+}
+
+func TestGetAccount(t *testing.T) {
+	store := NewInMemoryStore()
+	account1 := createTestAccount(t, store)
+
+	account2, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1, account2)
+
+	err = store.DeleteAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+
+	_, err = store.GetAccount(context.Background(), account1.ID)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
+
+func TestListAccounts(t *testing.T) {
+	store := NewInMemoryStore()
+	for i := 0; i < 10; i++ {
+		createTestAccount(t, store)
+	}
+
+	accounts, err := store.ListAccounts(context.Background(), db.ListAccountsParams{
+		Limit:  5,
+		Offset: 8,
+	})
+	require.NoError(t, err)
+	require.Len(t, accounts, 2)
+	require.Equal(t, int64(9), accounts[0].ID)
+	require.Equal(t, int64(10), accounts[1].ID)
+}
+
+func TestTransferTx(t *testing.T) {
+	store := NewInMemoryStore()
+	account1 := createTestAccount(t, store)
+	account2 := createTestAccount(t, store)
+
+	// fund both sides so that no transfer below can overdraw either, whatever order they run in
+	account1, err := store.AddAccountBalance(context.Background(), db.AddAccountBalanceParams{ID: account1.ID, Amount: 1000})
+	require.NoError(t, err)
+	account2, err = store.AddAccountBalance(context.Background(), db.AddAccountBalanceParams{ID: account2.ID, Amount: 1000})
+	require.NoError(t, err)
+
+	// run n concurrent transfer transactions in both directions
+	n := 20
+	amount := int64(10)
+
+	errs := make(chan error)
+	for i := 0; i < n; i++ {
+		fromAccount := account1
+		toAccount := account2
+		if i%4 == 3 {
+			fromAccount = account2
+			toAccount = account1
+		}
+
+		go func() {
+			_, err := store.TransferTx(context.Background(), db.TransferTxParams{
+				FromAccountID: fromAccount.ID,
+				ToAccountID:   toAccount.ID,
+				Amount:        amount,
+			})
+			errs <- err
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		require.NoError(t, <-errs)
+	}
+
+	// 15 transfers went from account1 to account2 and 5 came back
+	net := int64(n/2) * amount
+	updatedAccount1, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance-net, updatedAccount1.Balance)
+
+	updatedAccount2, err := store.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, account2.Balance+net, updatedAccount2.Balance)
+
+	transfers, err := store.ListTransfers(context.Background(), db.ListTransfersParams{Limit: int32(n + 1)})
+	require.NoError(t, err)
+	require.Len(t, transfers, n)
+
+	entries1 := listAccountEntries(t, store, account1.ID)
+	require.Len(t, entries1, n)
+
+	var sum int64
+	for _, entry := range entries1 {
+		sum += entry.Amount
+	}
+	require.Equal(t, -net, sum)
+}
+
+// listAccountEntries returns every entry of the account, archived ones included
+func listAccountEntries(t *testing.T, store *InMemoryStore, accountID int64) []db.Entry {
+	entries, err := store.ListStatementEntries(context.Background(), db.ListStatementEntriesParams{
+		Limit:     1000,
+		AccountID: accountID,
+		ToTime:    time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	return entries
+}
+
+func TestTransferTxUnknownAccount(t *testing.T) {
+	store := NewInMemoryStore()
+	account := createTestAccount(t, store)
+
+	_, err := store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: account.ID,
+		ToAccountID:   account.ID + 1,
+		Amount:        10,
+	})
+	require.Error(t, err)
+
+	// a failed transfer must not leave partial state behind
+	updatedAccount, err := store.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance, updatedAccount.Balance)
+
+	require.Empty(t, listAccountEntries(t, store, account.ID))
+}
+
+func TestSweepOwnAccountsTx(t *testing.T) {
+	store := NewInMemoryStore()
+	owner := util.RandomOwner()
+	newAccount := func(owner, currency string) db.Account {
+		account, err := store.CreateAcount(context.Background(), db.CreateAcountParams{
+			Owner:    owner,
+			Balance:  25,
+			Currency: currency,
+		})
+		require.NoError(t, err)
+		return account
+	}
+	account1 := newAccount(owner, "USD")
+	account2 := newAccount(owner, "USD")
+
+	_, err := store.SweepOwnAccountsTx(context.Background(), account1.ID, newAccount(util.RandomOwner(), "USD").ID, owner)
+	require.ErrorIs(t, err, db.ErrAccountOwnerMismatch)
+
+	_, err = store.SweepOwnAccountsTx(context.Background(), account1.ID, newAccount(owner, "EUR").ID, owner)
+	require.ErrorIs(t, err, db.ErrCurrencyMismatch)
+
+	result, err := store.SweepOwnAccountsTx(context.Background(), account1.ID, account2.ID, owner)
+	require.NoError(t, err)
+	require.Equal(t, int64(25), result.Transfer.Amount)
+	require.Zero(t, result.FromAccount.Balance)
+	require.Equal(t, int64(50), result.ToAccount.Balance)
+
+	_, err = store.SweepOwnAccountsTx(context.Background(), account1.ID, account2.ID, owner)
+	require.ErrorIs(t, err, db.ErrNothingToSweep)
+}
+
+func TestSweepOwnAccountsTxPolicy(t *testing.T) {
+	store := NewInMemoryStore()
+	store.UseAccountPolicies(func() util.AccountPolicies {
+		return util.AccountPolicies{
+			db.AccountTypeSavings: {MinBalance: 1000},
+		}
+	})
+	owner := util.RandomOwner()
+	savings, err := store.CreateAcount(context.Background(), db.CreateAcountParams{
+		Owner:       owner,
+		Balance:     1500,
+		Currency:    "USD",
+		AccountType: db.AccountTypeSavings,
+	})
+	require.NoError(t, err)
+	_, err = store.SetAccountMinBalance(context.Background(), db.SetAccountMinBalanceParams{ID: savings.ID, MinBalance: 200})
+	require.NoError(t, err)
+	checking, err := store.CreateAcount(context.Background(), db.CreateAcountParams{
+		Owner:    owner,
+		Currency: "USD",
+	})
+	require.NoError(t, err)
+
+	// the minimum of the policy is above the one of the account, so it is what stays behind
+	result, err := store.SweepOwnAccountsTx(context.Background(), savings.ID, checking.ID, owner)
+	require.NoError(t, err)
+	require.Equal(t, int64(500), result.Transfer.Amount)
+	require.Equal(t, int64(1000), result.FromAccount.Balance)
+
+	_, err = store.SweepOwnAccountsTx(context.Background(), savings.ID, checking.ID, owner)
+	require.ErrorIs(t, err, db.ErrNothingToSweep)
+}
+
+func TestSweepOwnAccountsTxFrozen(t *testing.T) {
+	store := NewInMemoryStore()
+	owner := util.RandomOwner()
+	newAccount := func() db.Account {
+		account, err := store.CreateAcount(context.Background(), db.CreateAcountParams{
+			Owner:    owner,
+			Balance:  25,
+			Currency: "USD",
+		})
+		require.NoError(t, err)
+		return account
+	}
+	account1 := newAccount()
+	account2 := newAccount()
+
+	_, err := store.PatchAccount(context.Background(), db.PatchAccountParams{ID: account1.ID, SetFrozen: true, Frozen: true, FreezeReason: "kyc"})
+	require.NoError(t, err)
+
+	_, err = store.SweepOwnAccountsTx(context.Background(), account1.ID, account2.ID, owner)
+	require.ErrorIs(t, err, db.ErrAccountFrozen)
+
+	updatedAccount1, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(25), updatedAccount1.Balance)
+}
+
+func TestConstraints(t *testing.T) {
+	store := NewInMemoryStore()
+	account1 := createTestAccount(t, store)
+	account2 := createTestAccount(t, store)
+
+	_, err := store.UpdateAccount(context.Background(), db.UpdateAccountParams{ID: account1.ID, Balance: -1})
+	require.ErrorIs(t, err, db.ErrConstraintViolation)
+
+	_, err = store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.Balance + 1,
+	})
+	require.ErrorIs(t, err, db.ErrInsufficientFunds)
+
+	_, err = store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        0,
+	})
+	require.ErrorIs(t, err, db.ErrConstraintViolation)
+	require.Contains(t, err.Error(), db.ConstraintAmountPositive)
+
+	// neither failed transfer may leave anything behind
+	updatedAccount1, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updatedAccount1.Balance)
+
+	require.Empty(t, listAccountEntries(t, store, account1.ID))
+}
+
+func TestCountAccountsByOwnerSince(t *testing.T) {
+	store := NewInMemoryStore()
+	account := createTestAccount(t, store)
+	createTestAccount(t, store)
+
+	count, err := store.CountAccountsByOwnerSince(context.Background(), db.CountAccountsByOwnerSinceParams{
+		Owner: account.Owner,
+		Since: account.CreatedAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+
+	count, err = store.CountAccountsByOwnerSince(context.Background(), db.CountAccountsByOwnerSinceParams{
+		Owner: account.Owner,
+		Since: account.CreatedAt.Add(time.Nanosecond),
+	})
+	require.NoError(t, err)
+	require.Zero(t, count)
+}
+
+func TestTransferTxWhitelist(t *testing.T) {
+	store := NewInMemoryStore()
+	account1 := createTestAccount(t, store)
+	account2 := createTestAccount(t, store)
+	account3 := createTestAccount(t, store)
+	account1, err := store.AddAccountBalance(context.Background(), db.AddAccountBalanceParams{ID: account1.ID, Amount: 10})
+	require.NoError(t, err)
+
+	_, err = store.SetAccountWhitelistEnabled(context.Background(), db.SetAccountWhitelistEnabledParams{ID: account1.ID, Enabled: true})
+	require.NoError(t, err)
+	err = store.AddWhitelistedDestination(context.Background(), db.AddWhitelistedDestinationParams{
+		AccountID:            account1.ID,
+		DestinationAccountID: account2.ID,
+	})
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1,
+	})
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account3.ID,
+		Amount:        1,
+	})
+	require.ErrorIs(t, err, db.ErrDestinationNotWhitelisted)
+
+	// turning the whitelist off lets the transfer through
+	_, err = store.SetAccountWhitelistEnabled(context.Background(), db.SetAccountWhitelistEnabledParams{ID: account1.ID, Enabled: false})
+	require.NoError(t, err)
+	_, err = store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account3.ID,
+		Amount:        1,
+	})
+	require.NoError(t, err)
+}
+
+func TestAuthorizeThenCaptureTransferTx(t *testing.T) {
+	store := NewInMemoryStore()
+	account1 := createTestAccount(t, store)
+	account2 := createTestAccount(t, store)
+	account1, err := store.AddAccountBalance(context.Background(), db.AddAccountBalanceParams{ID: account1.ID, Amount: 100})
+	require.NoError(t, err)
+
+	hold, err := store.AuthorizeTransferTx(context.Background(), db.CreateTransferParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        60,
+	})
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, hold.FromAccount.Balance)
+	require.Equal(t, int64(60), hold.FromAccount.HeldBalance)
+
+	// the held amount cannot be spent by a plain transfer
+	_, err = store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.Balance - 59,
+	})
+	require.ErrorIs(t, err, db.ErrInsufficientFunds)
+
+	result, err := store.CaptureTransferTx(context.Background(), hold.Hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance-60, result.FromAccount.Balance)
+	require.Zero(t, result.FromAccount.HeldBalance)
+	require.Equal(t, account2.Balance+60, result.ToAccount.Balance)
+
+	settled, err := store.GetTransferHold(context.Background(), hold.Hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, db.TransferHoldCaptured, settled.Status)
+	require.Equal(t, result.Transfer.ID, settled.TransferID.Int64)
+
+	_, err = store.VoidTransferTx(context.Background(), hold.Hold.ID)
+	require.ErrorIs(t, err, db.ErrHoldNotAuthorized)
+}
+
+func TestCaptureTransferTxFrozen(t *testing.T) {
+	store := NewInMemoryStore()
+	account1 := createTestAccount(t, store)
+	account2 := createTestAccount(t, store)
+
+	hold, err := store.AuthorizeTransferTx(context.Background(), db.CreateTransferParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1,
+	})
+	require.NoError(t, err)
+	_, err = store.PatchAccount(context.Background(), db.PatchAccountParams{ID: account2.ID, SetFrozen: true, Frozen: true, FreezeReason: "kyc"})
+	require.NoError(t, err)
+
+	_, err = store.CaptureTransferTx(context.Background(), hold.Hold.ID)
+	require.ErrorIs(t, err, db.ErrAccountFrozen)
+
+	// the hold stays authorized and keeps its amount held
+	unsettled, err := store.GetTransferHold(context.Background(), hold.Hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, db.TransferHoldAuthorized, unsettled.Status)
+	updatedAccount1, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updatedAccount1.Balance)
+	require.Equal(t, int64(1), updatedAccount1.HeldBalance)
+}
+
+func TestAuthorizeTransferTxPolicy(t *testing.T) {
+	store := NewInMemoryStore()
+	store.UseAccountPolicies(func() util.AccountPolicies {
+		return util.AccountPolicies{
+			db.AccountTypeSavings: {MaxTransferAmount: 500, MinBalance: 1000},
+		}
+	})
+	savings, err := store.CreateAcount(context.Background(), db.CreateAcountParams{
+		Owner:       util.RandomOwner(),
+		Balance:     1500,
+		Currency:    "USD",
+		AccountType: db.AccountTypeSavings,
+	})
+	require.NoError(t, err)
+	checking, err := store.CreateAcount(context.Background(), db.CreateAcountParams{
+		Owner:    util.RandomOwner(),
+		Balance:  100,
+		Currency: "USD",
+	})
+	require.NoError(t, err)
+	_, err = store.SetAccountMinBalance(context.Background(), db.SetAccountMinBalanceParams{ID: checking.ID, MinBalance: 60})
+	require.NoError(t, err)
+
+	authorize := func(from, to db.Account, amount int64) error {
+		_, err := store.AuthorizeTransferTx(context.Background(), db.CreateTransferParams{
+			FromAccountID: from.ID,
+			ToAccountID:   to.ID,
+			Amount:        amount,
+		})
+		return err
+	}
+
+	require.ErrorIs(t, authorize(savings, checking, 600), db.ErrTransferLimitExceeded)
+	require.NoError(t, authorize(savings, checking, 400))
+	// the first hold counts, so the second one would leave 700
+	require.ErrorIs(t, authorize(savings, checking, 400), db.ErrBelowMinimumBalance)
+
+	require.ErrorIs(t, authorize(checking, savings, 50), db.ErrMinBalanceViolation)
+	require.NoError(t, authorize(checking, savings, 40))
+}
+
+func TestAuthorizeThenVoidTransferTx(t *testing.T) {
+	store := NewInMemoryStore()
+	account1 := createTestAccount(t, store)
+	account2 := createTestAccount(t, store)
+	account1, err := store.AddAccountBalance(context.Background(), db.AddAccountBalanceParams{ID: account1.ID, Amount: 100})
+	require.NoError(t, err)
+
+	_, err = store.AuthorizeTransferTx(context.Background(), db.CreateTransferParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.Balance + 1,
+	})
+	require.ErrorIs(t, err, db.ErrInsufficientFunds)
+
+	hold, err := store.AuthorizeTransferTx(context.Background(), db.CreateTransferParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.Balance,
+	})
+	require.NoError(t, err)
+
+	result, err := store.VoidTransferTx(context.Background(), hold.Hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, db.TransferHoldVoided, result.Hold.Status)
+	require.Equal(t, account1.Balance, result.FromAccount.Balance)
+	require.Zero(t, result.FromAccount.HeldBalance)
+
+	updatedAccount2, err := store.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, account2.Balance, updatedAccount2.Balance)
+
+	_, err = store.CaptureTransferTx(context.Background(), hold.Hold.ID)
+	require.ErrorIs(t, err, db.ErrHoldNotAuthorized)
+}
+
+func TestReconcileAccount(t *testing.T) {
+	store := NewInMemoryStore()
+	source := createTestAccount(t, store)
+	source, err := store.AddAccountBalance(context.Background(), db.AddAccountBalanceParams{ID: source.ID, Amount: 100})
+	require.NoError(t, err)
+	account, err := store.CreateAcount(context.Background(), db.CreateAcountParams{
+		Owner:    util.RandomOwner(),
+		Currency: source.Currency,
+	})
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: source.ID,
+		ToAccountID:   account.ID,
+		Amount:        40,
+	})
+	require.NoError(t, err)
+
+	result, err := store.ReconcileAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(40), result.EntriesTotal)
+	require.Zero(t, result.Discrepancy)
+
+	_, err = store.UpdateAccount(context.Background(), db.UpdateAccountParams{ID: account.ID, Balance: 55})
+	require.NoError(t, err)
+
+	result, err = store.ReconcileAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(15), result.Discrepancy)
+
+	_, err = store.ReconcileAccount(context.Background(), account.ID+100)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestConvertAccountCurrencyTx(t *testing.T) {
+	store := NewInMemoryStore()
+	account, err := store.CreateAcount(context.Background(), db.CreateAcountParams{
+		Owner:    util.RandomOwner(),
+		Balance:  10000,
+		Currency: "USD",
+	})
+	require.NoError(t, err)
+	payee, err := store.CreateAcount(context.Background(), db.CreateAcountParams{
+		Owner:    util.RandomOwner(),
+		Currency: "USD",
+	})
+	require.NoError(t, err)
+
+	hold, err := store.AuthorizeTransferTx(context.Background(), db.CreateTransferParams{
+		FromAccountID: account.ID,
+		ToAccountID:   payee.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	params := db.ConvertAccountCurrencyTxParams{
+		AccountID: account.ID,
+		Currency:  "VND",
+		Rate:      big.NewRat(240005, 10),
+	}
+	_, err = store.ConvertAccountCurrencyTx(context.Background(), params)
+	require.ErrorIs(t, err, db.ErrPendingHolds)
+
+	_, err = store.VoidTransferTx(context.Background(), hold.Hold.ID)
+	require.NoError(t, err)
+
+	result, err := store.ConvertAccountCurrencyTx(context.Background(), params)
+	require.NoError(t, err)
+	require.Equal(t, "VND", result.Account.Currency)
+	require.Equal(t, int64(240005000), result.Account.Balance)
+	require.Equal(t, result.Account.Balance-10000, result.Entry.Amount)
+	require.Equal(t, util.RoundHalfUp, result.RoundingMode)
+
+	_, err = store.ConvertAccountCurrencyTx(context.Background(), params)
+	require.ErrorIs(t, err, db.ErrCurrencyUnchanged)
+}
+
+func TestConvertAccountCurrencyTxKeepsTransfers(t *testing.T) {
+	store := NewInMemoryStore()
+	create := func() db.Account {
+		account, err := store.CreateAcount(context.Background(), db.CreateAcountParams{
+			Owner:             util.RandomOwner(),
+			Currency:          "USD",
+			NumberPerCurrency: true,
+		})
+		require.NoError(t, err)
+		return account
+	}
+	from := create()
+	to := create()
+
+	transfer, err := store.CreateTransfer(context.Background(), db.CreateTransferParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "USD", transfer.Currency)
+	require.Equal(t, from.Number, transfer.FromAccountNumber)
+	require.Equal(t, to.Number, transfer.ToAccountNumber)
+
+	// an EUR account already holds the number of from, so that converting from gives it another one
+	_, err = store.CreateAcount(context.Background(), db.CreateAcountParams{
+		Owner:             util.RandomOwner(),
+		Currency:          "EUR",
+		NumberPerCurrency: true,
+	})
+	require.NoError(t, err)
+	result, err := store.ConvertAccountCurrencyTx(context.Background(), db.ConvertAccountCurrencyTxParams{
+		AccountID:         from.ID,
+		Currency:          "EUR",
+		Rate:              big.NewRat(9, 10),
+		NumberPerCurrency: true,
+	})
+	require.NoError(t, err)
+	require.NotEqual(t, from.Number, result.Account.Number)
+
+	got, err := store.GetTransfer(context.Background(), transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, "USD", got.Currency)
+	require.Equal(t, from.Number, got.FromAccountNumber)
+	require.Equal(t, to.Number, got.ToAccountNumber)
+}
+
+func TestConvertAccountCurrencyTxSubBalanceAndMinBalance(t *testing.T) {
+	store := NewInMemoryStore()
+	account, err := store.CreateAcount(context.Background(), db.CreateAcountParams{
+		Owner:    util.RandomOwner(),
+		Balance:  10000,
+		Currency: "USD",
+	})
+	require.NoError(t, err)
+	_, err = store.SetAccountMinBalance(context.Background(), db.SetAccountMinBalanceParams{ID: account.ID, MinBalance: 1000})
+	require.NoError(t, err)
+
+	// 2000 USD become a sub-balance of 1800 EUR, leaving 8000 USD
+	_, err = store.ConvertSubBalanceTx(context.Background(), db.ConvertSubBalanceTxParams{
+		AccountID:    account.ID,
+		FromCurrency: "USD",
+		ToCurrency:   "EUR",
+		Amount:       2000,
+		Rate:         big.NewRat(9, 10),
+	})
+	require.NoError(t, err)
+
+	result, err := store.ConvertAccountCurrencyTx(context.Background(), db.ConvertAccountCurrencyTxParams{
+		AccountID: account.ID,
+		Currency:  "EUR",
+		Rate:      big.NewRat(9, 10),
+	})
+	require.NoError(t, err)
+	require.Equal(t, "EUR", result.Account.Currency)
+	require.Equal(t, int64(7200+1800), result.Account.Balance)
+	require.Equal(t, int64(900), result.Account.MinBalance)
+	require.Equal(t, int64(9000-8000), result.Entry.Amount)
+	require.NotNil(t, result.FoldedSubBalance)
+	require.Equal(t, int64(-1800), result.FoldedSubBalance.Amount)
+	require.Zero(t, result.FoldedSubBalance.Balance)
+
+	subBalances, err := store.ListSubBalances(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Len(t, subBalances, 1)
+	require.Zero(t, subBalances[0].Balance)
+}
+
+func TestConvertAccountCurrencyTxPendingTransfers(t *testing.T) {
+	store := NewInMemoryStore()
+	account, err := store.CreateAcount(context.Background(), db.CreateAcountParams{Owner: util.RandomOwner(), Balance: 100, Currency: "USD"})
+	require.NoError(t, err)
+	payee, err := store.CreateAcount(context.Background(), db.CreateAcountParams{Owner: util.RandomOwner(), Currency: "USD"})
+	require.NoError(t, err)
+
+	// the payee is blocked as much as the payer
+	params := db.ConvertAccountCurrencyTxParams{
+		AccountID: payee.ID,
+		Currency:  "VND",
+		Rate:      big.NewRat(240005, 10),
+	}
+
+	scheduled, err := store.CreateScheduledTransfer(context.Background(), db.CreateScheduledTransferParams{
+		FromAccountID: account.ID,
+		ToAccountID:   payee.ID,
+		Amount:        10,
+		Currency:      account.Currency,
+		RunAt:         time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	_, err = store.ConvertAccountCurrencyTx(context.Background(), params)
+	require.ErrorIs(t, err, db.ErrPendingScheduledTransfers)
+
+	_, err = store.CancelScheduledTransfer(context.Background(), scheduled.ID)
+	require.NoError(t, err)
+
+	_, err = store.CreateTransferConfirmation(context.Background(), db.CreateTransferConfirmationParams{
+		FromAccountID: account.ID,
+		ToAccountID:   payee.ID,
+		Amount:        10,
+		Currency:      account.Currency,
+		TokenHash:     "hash",
+		ExpiresAt:     time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+	_, err = store.ConvertAccountCurrencyTx(context.Background(), params)
+	require.ErrorIs(t, err, db.ErrPendingConfirmations)
+}
+
+func TestExecuteScheduledTransferTxAccounts(t *testing.T) {
+	store := NewInMemoryStore()
+	from, err := store.CreateAcount(context.Background(), db.CreateAcountParams{Owner: util.RandomOwner(), Balance: 100, Currency: "USD"})
+	require.NoError(t, err)
+	to, err := store.CreateAcount(context.Background(), db.CreateAcountParams{Owner: util.RandomOwner(), Currency: "USD"})
+	require.NoError(t, err)
+
+	schedule := func() db.ScheduledTransfer {
+		scheduled, err := store.CreateScheduledTransfer(context.Background(), db.CreateScheduledTransferParams{
+			FromAccountID: from.ID,
+			ToAccountID:   to.ID,
+			Amount:        10,
+			Currency:      "USD",
+			RunAt:         time.Now(),
+		})
+		require.NoError(t, err)
+		return scheduled
+	}
+
+	frozen := schedule()
+	_, err = store.PatchAccount(context.Background(), db.PatchAccountParams{ID: to.ID, SetFrozen: true, Frozen: true, FreezeReason: "kyc"})
+	require.NoError(t, err)
+	_, err = store.ExecuteScheduledTransferTx(context.Background(), frozen.ID)
+	require.ErrorIs(t, err, db.ErrAccountFrozen)
+
+	_, err = store.PatchAccount(context.Background(), db.PatchAccountParams{ID: to.ID, SetFrozen: true, Frozen: false})
+	require.NoError(t, err)
+	_, err = store.CancelScheduledTransfer(context.Background(), frozen.ID)
+	require.NoError(t, err)
+	_, err = store.ConvertAccountCurrencyTx(context.Background(), db.ConvertAccountCurrencyTxParams{
+		AccountID: to.ID,
+		Currency:  "EUR",
+		Rate:      big.NewRat(9, 10),
+	})
+	require.NoError(t, err)
+
+	// a transfer scheduled before the conversion would credit euros with dollars
+	mismatched := schedule()
+	_, err = store.ExecuteScheduledTransferTx(context.Background(), mismatched.ID)
+	require.ErrorIs(t, err, db.ErrCurrencyMismatch)
+
+	account, err := store.GetAccount(context.Background(), from.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(100), account.Balance)
+}
+
+func TestConvertAccountCurrencyTxAllowedPairs(t *testing.T) {
+	store := NewInMemoryStore()
+	account, err := store.CreateAcount(context.Background(), db.CreateAcountParams{
+		Owner:    util.RandomOwner(),
+		Balance:  10000,
+		Currency: "USD",
+	})
+	require.NoError(t, err)
+
+	pairs, err := util.ParseCurrencyPairs([]string{"USD/EUR"})
+	require.NoError(t, err)
+
+	_, err = store.ConvertAccountCurrencyTx(context.Background(), db.ConvertAccountCurrencyTxParams{
+		AccountID:    account.ID,
+		Currency:     "GBP",
+		Rate:         big.NewRat(8, 10),
+		AllowedPairs: pairs,
+	})
+	require.ErrorIs(t, err, db.ErrCurrencyPairNotAllowed)
+
+	unchanged, err := store.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account, unchanged)
+
+	result, err := store.ConvertAccountCurrencyTx(context.Background(), db.ConvertAccountCurrencyTxParams{
+		AccountID:    account.ID,
+		Currency:     "EUR",
+		Rate:         big.NewRat(9, 10),
+		AllowedPairs: pairs,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "EUR", result.Account.Currency)
+	require.Equal(t, int64(9000), result.Account.Balance)
+
+	// the pair only allows the one direction
+	_, err = store.ConvertAccountCurrencyTx(context.Background(), db.ConvertAccountCurrencyTxParams{
+		AccountID:    account.ID,
+		Currency:     "USD",
+		Rate:         big.NewRat(10, 9),
+		AllowedPairs: pairs,
+	})
+	require.ErrorIs(t, err, db.ErrCurrencyPairNotAllowed)
+}
+
+func TestArchiveEntries(t *testing.T) {
+	store := NewInMemoryStore()
+	account := createTestAccount(t, store)
+	var created []db.Entry
+	for _, amount := range []int64{10, -4, 7} {
+		entry, err := store.CreateEntry(context.Background(), db.CreateEntryParams{AccountID: account.ID, Amount: amount})
+		require.NoError(t, err)
+		created = append(created, entry)
+	}
+
+	n, err := store.ArchiveEntries(context.Background(), db.ArchiveEntriesParams{Before: time.Now().Add(time.Second), BatchSize: 2})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), n)
+
+	// the oldest entries moved out of the entries table, the last one stayed
+	for i, entry := range created {
+		_, err := store.GetEntry(context.Background(), entry.ID)
+		if i < 2 {
+			require.ErrorIs(t, err, sql.ErrNoRows)
+		} else {
+			require.NoError(t, err)
+		}
+	}
+
+	archived, err := store.ListArchivedEntriesByAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Len(t, archived, 2)
+	require.Equal(t, int64(10), archived[0].Amount)
+
+	total, err := store.SumEntriesByAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(13), total)
+}
+
+func TestAcceptOwnershipTransferTx(t *testing.T) {
+	store := NewInMemoryStore()
+	account := createTestAccount(t, store)
+	newOwner := util.RandomOwner()
+
+	createRequest := func(expiresAt time.Time) db.OwnershipTransferRequest {
+		request, err := store.CreateOwnershipTransferRequest(context.Background(), db.CreateOwnershipTransferRequestParams{
+			AccountID:    account.ID,
+			CurrentOwner: account.Owner,
+			NewOwner:     newOwner,
+			ExpiresAt:    expiresAt,
+		})
+		require.NoError(t, err)
+		return request
+	}
+	expired := createRequest(time.Now().Add(-time.Minute))
+	pending := createRequest(time.Now().Add(time.Hour))
+
+	_, err := store.AcceptOwnershipTransferTx(context.Background(), expired.ID, newOwner)
+	require.ErrorIs(t, err, db.ErrOwnershipRequestExpired)
+
+	n, err := store.ExpireOwnershipTransferRequests(context.Background(), time.Now())
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+
+	_, err = store.AcceptOwnershipTransferTx(context.Background(), pending.ID, util.RandomOwner())
+	require.ErrorIs(t, err, db.ErrAccountOwnerMismatch)
+
+	result, err := store.AcceptOwnershipTransferTx(context.Background(), pending.ID, newOwner)
+	require.NoError(t, err)
+	require.Equal(t, newOwner, result.Account.Owner)
+	require.Equal(t, db.OwnershipRequestAccepted, result.Request.Status)
+
+	_, err = store.AcceptOwnershipTransferTx(context.Background(), pending.ID, newOwner)
+	require.ErrorIs(t, err, db.ErrOwnershipRequestNotPending)
+}
+
+func TestTransferTxFee(t *testing.T) {
+	store := NewInMemoryStore()
+	account1 := createTestAccount(t, store)
+	account2 := createTestAccount(t, store)
+	feeAccount := createTestAccount(t, store)
+	account2.Currency, feeAccount.Currency = account1.Currency, account1.Currency
+	store.accounts[account2.ID] = account2
+	store.accounts[feeAccount.ID] = feeAccount
+
+	account1, err := store.AddAccountBalance(context.Background(), db.AddAccountBalanceParams{ID: account1.ID, Amount: 100})
+	require.NoError(t, err)
+
+	result, err := store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        50,
+		Fee:           5,
+		FeeAccountID:  feeAccount.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(5), result.Transfer.Fee)
+	require.Equal(t, account1.Balance-55, result.FromAccount.Balance)
+	require.Equal(t, account2.Balance+50, result.ToAccount.Balance)
+	require.NotNil(t, result.FeeEntry)
+	require.Equal(t, int64(-5), result.FeeEntry.Amount)
+
+	updatedFeeAccount, err := store.GetAccount(context.Background(), feeAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, feeAccount.Balance+5, updatedFeeAccount.Balance)
+
+	fees, err := store.SumTransferFeesByAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), fees)
+
+	// the balance left covers the amount but not the fee on top of it
+	_, err = store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        result.FromAccount.Balance,
+		Fee:           5,
+		FeeAccountID:  feeAccount.ID,
+	})
+	require.ErrorIs(t, err, db.ErrInsufficientFunds)
+
+	unchanged, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, result.FromAccount.Balance, unchanged.Balance)
+}
+
+func TestScheduledAndCapturedTransfersFee(t *testing.T) {
+	store := NewInMemoryStore()
+	newAccount := func(balance int64) db.Account {
+		account, err := store.CreateAcount(context.Background(), db.CreateAcountParams{Owner: util.RandomOwner(), Balance: balance, Currency: "USD"})
+		require.NoError(t, err)
+		return account
+	}
+	from := newAccount(100)
+	to := newAccount(0)
+	feeAccount := newAccount(0)
+	store.UseTransferFees(func(amount int64, currency string) (int64, int64, error) {
+		return util.Config{TransferFeeFlat: 2, TransferFeeAccounts: map[string]int64{"USD": feeAccount.ID}}.TransferFee(amount, currency)
+	})
+
+	scheduled, err := store.CreateScheduledTransfer(context.Background(), db.CreateScheduledTransferParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        10,
+		Currency:      "USD",
+		RunAt:         time.Now(),
+	})
+	require.NoError(t, err)
+	scheduled, err = store.ExecuteScheduledTransferTx(context.Background(), scheduled.ID)
+	require.NoError(t, err)
+	transfer, err := store.GetTransfer(context.Background(), scheduled.TransferID.Int64)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), transfer.Fee)
+
+	hold, err := store.AuthorizeTransferTx(context.Background(), db.CreateTransferParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        20,
+	})
+	require.NoError(t, err)
+	result, err := store.CaptureTransferTx(context.Background(), hold.Hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), result.Transfer.Fee)
+	require.Equal(t, int64(100-12-22), result.FromAccount.Balance)
+
+	updatedFeeAccount, err := store.GetAccount(context.Background(), feeAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(4), updatedFeeAccount.Balance)
+}
+
+func TestAccountNumberPerCurrency(t *testing.T) {
+	store := NewInMemoryStore()
+	create := func(currency string, perCurrency bool) db.Account {
+		account, err := store.CreateAcount(context.Background(), db.CreateAcountParams{
+			Owner:             util.RandomOwner(),
+			Currency:          currency,
+			NumberPerCurrency: perCurrency,
+		})
+		require.NoError(t, err)
+		return account
+	}
+
+	usd1 := create("USD", true)
+	eur := create("EUR", true)
+	usd2 := create("USD", true)
+	require.Equal(t, usd1.Number, eur.Number)
+	require.NotEqual(t, usd1.Number, usd2.Number)
+
+	gotEUR, err := store.GetAccountByNumberAndCurrency(context.Background(), db.GetAccountByNumberAndCurrencyParams{
+		Number:   eur.Number,
+		Currency: "EUR",
+	})
+	require.NoError(t, err)
+	require.Equal(t, eur.ID, gotEUR.ID)
+
+	// numbers assigned across all accounts never repeat, whatever the currency
+	global := create("EUR", false)
+	require.Greater(t, global.Number, usd2.Number)
+
+	// converting renumbers the account, since its number may already be taken in the new currency
+	result, err := store.ConvertAccountCurrencyTx(context.Background(), db.ConvertAccountCurrencyTxParams{
+		AccountID:         usd1.ID,
+		Currency:          "EUR",
+		Rate:              big.NewRat(1, 1),
+		NumberPerCurrency: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, global.Number+1, result.Account.Number)
+}
+
+func TestAdjustAccountBalanceTx(t *testing.T) {
+	store := NewInMemoryStore()
+	account, err := store.CreateAcount(context.Background(), db.CreateAcountParams{
+		Owner:    util.RandomOwner(),
+		Balance:  100,
+		Currency: "USD",
+	})
+	require.NoError(t, err)
+
+	credit, err := store.AdjustAccountBalanceTx(context.Background(), db.AdjustAccountBalanceTxParams{
+		AccountID:  account.ID,
+		Amount:     50,
+		Reason:     "refund of a duplicated charge",
+		AdjustedBy: "banker",
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(150), credit.Account.Balance)
+	require.Equal(t, credit.Entry.ID, credit.Adjustment.EntryID)
+
+	debit, err := store.AdjustAccountBalanceTx(context.Background(), db.AdjustAccountBalanceTxParams{
+		AccountID:  account.ID,
+		Amount:     -150,
+		Reason:     "account closed in error",
+		AdjustedBy: "banker",
+	})
+	require.NoError(t, err)
+	require.Zero(t, debit.Account.Balance)
+
+	_, err = store.AdjustAccountBalanceTx(context.Background(), db.AdjustAccountBalanceTxParams{
+		AccountID:  account.ID,
+		Amount:     -1,
+		Reason:     "overdraw",
+		AdjustedBy: "banker",
+	})
+	require.ErrorIs(t, err, db.ErrInsufficientFunds)
+
+	adjustments, err := store.ListAccountAdjustments(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, []db.AccountAdjustment{credit.Adjustment, debit.Adjustment}, adjustments)
+}
+
+func TestTransferTxAccountPolicy(t *testing.T) {
+	store := NewInMemoryStore()
+	store.UseAccountPolicies(func() util.AccountPolicies {
+		return util.AccountPolicies{
+			db.AccountTypeChecking: {MinBalance: 100, AllowOverdraft: true},
+			db.AccountTypeSavings:  {MaxTransferAmount: 500, MinBalance: 1000},
+		}
+	})
+
+	checking, err := store.CreateAcount(context.Background(), db.CreateAcountParams{
+		Owner:    util.RandomOwner(),
+		Balance:  1500,
+		Currency: "USD",
+	})
+	require.NoError(t, err)
+	require.Equal(t, db.AccountTypeChecking, checking.AccountType)
+	savings, err := store.CreateAcount(context.Background(), db.CreateAcountParams{
+		Owner:       util.RandomOwner(),
+		Balance:     1500,
+		Currency:    "USD",
+		AccountType: db.AccountTypeSavings,
+	})
+	require.NoError(t, err)
+
+	transfer := func(from, to db.Account, amount int64) (db.TransferTxResult, error) {
+		return store.TransferTx(context.Background(), db.TransferTxParams{
+			FromAccountID: from.ID,
+			ToAccountID:   to.ID,
+			Amount:        amount,
+		})
+	}
+
+	_, err = transfer(savings, checking, 600)
+	require.ErrorIs(t, err, db.ErrTransferLimitExceeded)
+	_, err = transfer(savings, checking, 500)
+	require.NoError(t, err)
+	_, err = transfer(savings, checking, 100)
+	require.ErrorIs(t, err, db.ErrBelowMinimumBalance)
+
+	result, err := transfer(checking, savings, 1950)
+	require.NoError(t, err)
+	require.Equal(t, int64(50), result.FromAccount.Balance)
+
+	// scheduled transfers and holds follow the policy too
+	scheduled, err := store.CreateScheduledTransfer(context.Background(), db.CreateScheduledTransferParams{
+		FromAccountID: savings.ID,
+		ToAccountID:   checking.ID,
+		Amount:        600,
+		Currency:      "USD",
+		RunAt:         time.Now(),
+	})
+	require.NoError(t, err)
+	_, err = store.ExecuteScheduledTransferTx(context.Background(), scheduled.ID)
+	require.ErrorIs(t, err, db.ErrTransferLimitExceeded)
+
+	_, err = store.AuthorizeTransferTx(context.Background(), db.CreateTransferParams{
+		FromAccountID: savings.ID,
+		ToAccountID:   checking.ID,
+		Amount:        600,
+	})
+	require.ErrorIs(t, err, db.ErrTransferLimitExceeded)
+
+	_, err = store.CreateAcount(context.Background(), db.CreateAcountParams{
+		Owner:       util.RandomOwner(),
+		Currency:    "USD",
+		AccountType: "brokerage",
+	})
+	require.ErrorIs(t, err, db.ErrConstraintViolation)
+}
+
+func TestTransferTxEvents(t *testing.T) {
+	store := NewInMemoryStore()
+	account1 := createTestAccount(t, store)
+	account2 := createTestAccount(t, store)
+	account1, err := store.AddAccountBalance(context.Background(), db.AddAccountBalanceParams{ID: account1.ID, Amount: 100})
+	require.NoError(t, err)
+	before := map[int64]int64{account1.ID: account1.Balance, account2.ID: account2.Balance}
+
+	_, err = store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+	_, err = store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: account2.ID,
+		ToAccountID:   account1.ID,
+		Amount:        4,
+	})
+	require.NoError(t, err)
+
+	events, err := store.ListEvents(context.Background(), db.ListEventsParams{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	require.Equal(t, db.EventTransferCreated, events[0].EventType)
+	require.Equal(t, []int64{account1.ID, account2.ID}, events[0].AccountIds)
+
+	changes, err := db.ReplayEvents(events)
+	require.NoError(t, err)
+	for id, balance := range before {
+		current, err := store.GetAccount(context.Background(), id)
+		require.NoError(t, err)
+		require.Equal(t, current.Balance, balance+changes[id])
+	}
+}
+
+func TestTransferTxFlagged(t *testing.T) {
+	store := NewInMemoryStore()
+	account1 := createTestAccount(t, store)
+	account2 := createTestAccount(t, store)
+	_, err := store.AddAccountBalance(context.Background(), db.AddAccountBalanceParams{ID: account1.ID, Amount: 100})
+	require.NoError(t, err)
+
+	since := time.Now()
+	for i := 0; i < 2; i++ {
+		_, err = store.TransferTx(context.Background(), db.TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        10,
+			FlagReason:    "velocity",
+		})
+		require.NoError(t, err)
+	}
+
+	count, err := store.CountRecentTransfersFromAccount(context.Background(), db.CountRecentTransfersFromAccountParams{
+		FromAccountID: account1.ID,
+		Since:         since,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), count)
+
+	events, err := store.ListEventsByAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Len(t, events, 4)
+	require.Equal(t, db.EventTransferCreated, events[0].EventType)
+	require.Equal(t, db.EventTransferFlagged, events[1].EventType)
+	require.Equal(t, []int64{account1.ID}, events[1].AccountIds)
+}
+
+func TestTransferTxMinBalance(t *testing.T) {
+	store := NewInMemoryStore()
+	account1, err := store.CreateAcount(context.Background(), db.CreateAcountParams{
+		Owner:    util.RandomOwner(),
+		Balance:  100,
+		Currency: "USD",
+	})
+	require.NoError(t, err)
+	account2, err := store.CreateAcount(context.Background(), db.CreateAcountParams{
+		Owner:    account1.Owner,
+		Currency: "USD",
+	})
+	require.NoError(t, err)
+
+	_, err = store.SetAccountMinBalance(context.Background(), db.SetAccountMinBalanceParams{ID: account1.ID, MinBalance: 60})
+	require.NoError(t, err)
+
+	result, err := store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        30,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(70), result.FromAccount.Balance)
+
+	_, err = store.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        11,
+	})
+	require.ErrorIs(t, err, db.ErrMinBalanceViolation)
+
+	// a sweep leaves the minimum behind
+	result, err = store.SweepOwnAccountsTx(context.Background(), account1.ID, account2.ID, account1.Owner)
+	require.NoError(t, err)
+	require.Equal(t, int64(60), result.FromAccount.Balance)
+
+	_, err = store.SetAccountMinBalance(context.Background(), db.SetAccountMinBalanceParams{ID: account1.ID, MinBalance: -1})
+	require.ErrorIs(t, err, db.ErrConstraintViolation)
+}
+
+func TestListDormantAccounts(t *testing.T) {
+	store := NewInMemoryStore()
+	neverUsed := createTestAccount(t, store)
+	stale := createTestAccount(t, store)
+	active := createTestAccount(t, store)
+
+	_, err := store.CreateEntry(context.Background(), db.CreateEntryParams{AccountID: stale.ID, Amount: 10})
+	require.NoError(t, err)
+	since := time.Now()
+	_, err = store.CreateEntry(context.Background(), db.CreateEntryParams{AccountID: active.ID, Amount: 10})
+	require.NoError(t, err)
+
+	accounts, err := store.ListDormantAccounts(context.Background(), db.ListDormantAccountsParams{Since: since, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, accounts, 2)
+	require.Equal(t, neverUsed.ID, accounts[0].ID)
+	require.Equal(t, stale.ID, accounts[1].ID)
+
+	accounts, err = store.ListDormantAccounts(context.Background(), db.ListDormantAccountsParams{Since: since, Limit: 1, Offset: 1})
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+	require.Equal(t, stale.ID, accounts[0].ID)
+}
+
+func TestGetTransfersByIDs(t *testing.T) {
+	store := NewInMemoryStore()
+	account := createTestAccount(t, store)
+	other1 := createTestAccount(t, store)
+	other2 := createTestAccount(t, store)
+
+	newTransfer := func(from, to db.Account) db.Transfer {
+		transfer, err := store.CreateTransfer(context.Background(), db.CreateTransferParams{
+			FromAccountID: from.ID,
+			ToAccountID:   to.ID,
+			Amount:        10,
+		})
+		require.NoError(t, err)
+		return transfer
+	}
+	outgoing := newTransfer(account, other1)
+	incoming := newTransfer(other2, account)
+	unrelated := newTransfer(other1, other2)
+
+	transfers, err := store.GetTransfersByIDs(context.Background(), db.GetTransfersByIDsParams{
+		Ids:   []int64{unrelated.ID, incoming.ID, outgoing.ID, 1000},
+		Owner: account.Owner,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []db.Transfer{outgoing, incoming}, transfers)
+}
+
+func TestFindCounterpartyAccounts(t *testing.T) {
+	store := NewInMemoryStore()
+	flagged := createTestAccount(t, store)
+	b := createTestAccount(t, store)
+	c := createTestAccount(t, store)
+	d := createTestAccount(t, store)
+	e := createTestAccount(t, store)
+
+	for _, transfer := range []struct {
+		from, to db.Account
+		amount   int64
+	}{
+		{flagged, b, 10},
+		{flagged, b, 20},
+		{c, flagged, 5},
+		{flagged, d, 7},
+		{d, flagged, 3},
+		{b, c, 100},
+		{e, b, 100},
+	} {
+		_, err := store.CreateTransfer(context.Background(), db.CreateTransferParams{
+			FromAccountID: transfer.from.ID,
+			ToAccountID:   transfer.to.ID,
+			Amount:        transfer.amount,
+		})
+		require.NoError(t, err)
+	}
+
+	counterparties, err := store.FindCounterpartyAccounts(context.Background(), db.FindCounterpartyAccountsParams{
+		AccountID: flagged.ID,
+		Limit:     10,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []db.FindCounterpartyAccountsRow{
+		{ID: b.ID, Owner: b.Owner, Currency: b.Currency, Direction: db.CounterpartyOutgoing, Transfers: 2, AmountSent: 30},
+		{ID: c.ID, Owner: c.Owner, Currency: c.Currency, Direction: db.CounterpartyIncoming, Transfers: 1, AmountReceived: 5},
+		{ID: d.ID, Owner: d.Owner, Currency: d.Currency, Direction: db.CounterpartyBoth, Transfers: 2, AmountSent: 7, AmountReceived: 3},
+	}, counterparties)
+
+	counterparties, err = store.FindCounterpartyAccounts(context.Background(), db.FindCounterpartyAccountsParams{
+		AccountID: flagged.ID,
+		Limit:     2,
+		Offset:    2,
+	})
+	require.NoError(t, err)
+	require.Len(t, counterparties, 1)
+	require.Equal(t, d.ID, counterparties[0].ID)
+
+	counterparties, err = store.FindCounterpartyAccounts(context.Background(), db.FindCounterpartyAccountsParams{
+		AccountID: e.ID,
+		Limit:     10,
+	})
+	require.NoError(t, err)
+	require.Len(t, counterparties, 1)
+	require.Equal(t, b.ID, counterparties[0].ID)
+}
+
+func TestRecentSimilarTransferExists(t *testing.T) {
+	store := NewInMemoryStore()
+	account1 := createTestAccount(t, store)
+	account2 := createTestAccount(t, store)
+
+	transfer, err := store.CreateTransfer(context.Background(), db.CreateTransferParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	arg := db.RecentSimilarTransferExistsParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		Since:         transfer.CreatedAt.Add(-time.Minute),
+	}
+	found, err := store.RecentSimilarTransferExists(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, found)
+
+	arg.Since = transfer.CreatedAt.Add(time.Minute)
+	found, err = store.RecentSimilarTransferExists(context.Background(), arg)
+	require.NoError(t, err)
+	require.False(t, found)
+}
+
+func TestListAccountsAfter(t *testing.T) {
+	store := NewInMemoryStore()
+	account1 := createTestAccount(t, store)
+	account2 := createTestAccount(t, store)
+	account3 := createTestAccount(t, store)
+
+	accounts, err := store.ListAccountsAfter(context.Background(), db.ListAccountsAfterParams{AfterID: account1.ID, BatchSize: 1})
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+	require.Equal(t, account2.ID, accounts[0].ID)
+
+	accounts, err = store.ListAccountsAfter(context.Background(), db.ListAccountsAfterParams{AfterID: account2.ID, BatchSize: 10})
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+	require.Equal(t, account3.ID, accounts[0].ID)
+}
+
+func TestStreamAllAccounts(t *testing.T) {
+	store := NewInMemoryStore()
+	want := make(map[int64]bool)
+	for i := 0; i < 5; i++ {
+		want[createTestAccount(t, store).ID] = true
+	}
+
+	seen := make(map[int64]int)
+	err := store.StreamAllAccounts(context.Background(), func(account db.Account) error {
+		seen[account.ID]++
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, seen, len(want))
+	for id, count := range seen {
+		require.True(t, want[id])
+		require.Equal(t, 1, count)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	visited := 0
+	err = store.StreamAllAccounts(ctx, func(account db.Account) error {
+		visited++
+		if visited == 2 {
+			cancel()
+		}
+		return nil
+	})
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, 2, visited)
+}
+
+func TestAccountBalanceAsOf(t *testing.T) {
+	store := NewInMemoryStore()
+	account := createTestAccount(t, store)
+
+	created := time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)
+	account.CreatedAt = created
+	store.accounts[account.ID] = account
+
+	history := []struct {
+		amount int64
+		at     time.Time
+	}{
+		{100, created.Add(time.Hour)},
+		{-30, created.Add(2 * time.Hour)},
+		{50, created.Add(3 * time.Hour)},
+	}
+	for _, change := range history {
+		entry, err := store.CreateEntry(context.Background(), db.CreateEntryParams{AccountID: account.ID, Amount: change.amount})
+		require.NoError(t, err)
+		entry.CreatedAt = change.at
+		store.entries[entry.ID] = entry
+	}
+	_, err := store.ArchiveEntries(context.Background(), db.ArchiveEntriesParams{Before: created.Add(90 * time.Minute), BatchSize: 10})
+	require.NoError(t, err)
+	_, err = store.UpdateAccount(context.Background(), db.UpdateAccountParams{ID: account.ID, Balance: 120})
+	require.NoError(t, err)
+
+	points := []struct {
+		at      time.Time
+		balance int64
+	}{
+		{created.Add(-time.Hour), 0},
+		{created, 0},
+		{created.Add(time.Hour), 100},
+		{created.Add(90 * time.Minute), 100},
+		{created.Add(2 * time.Hour), 70},
+		{created.Add(3 * time.Hour), 120},
+		{time.Now().Add(time.Hour), 120},
+	}
+	for _, point := range points {
+		balance, err := store.AccountBalanceAsOf(context.Background(), account.ID, point.at)
+		require.NoError(t, err)
+		require.Equal(t, point.balance, balance, "balance at %v", point.at)
+	}
+
+	_, err = store.AccountBalanceAsOf(context.Background(), account.ID+100, created)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestBatchTransferTx(t *testing.T) {
+	store := NewInMemoryStore()
+	account1 := createTestAccount(t, store)
+	account2 := createTestAccount(t, store)
+	account1, err := store.AddAccountBalance(context.Background(), db.AddAccountBalanceParams{ID: account1.ID, Amount: 10})
+	require.NoError(t, err)
+
+	results, err := store.BatchTransferTx(context.Background(), []db.TransferTxParams{
+		{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 10},
+		{FromAccountID: account2.ID, ToAccountID: account1.ID, Amount: 4},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	updatedAccount1, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance-6, updatedAccount1.Balance)
+
+	updatedAccount2, err := store.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, account2.Balance+6, updatedAccount2.Balance)
+}
+
+func TestBatchTransferTxRollback(t *testing.T) {
+	store := NewInMemoryStore()
+	account1 := createTestAccount(t, store)
+	account2 := createTestAccount(t, store)
+
+	// the second transfer overdraws account1, so the first one must be undone as well
+	_, err := store.BatchTransferTx(context.Background(), []db.TransferTxParams{
+		{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 1},
+		{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: account1.Balance},
+	})
+	require.ErrorIs(t, err, db.ErrInsufficientFunds)
+
+	var batchErr *db.BatchTransferError
+	require.True(t, errors.As(err, &batchErr))
+	require.Equal(t, 1, batchErr.Index)
+
+	updatedAccount1, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updatedAccount1.Balance)
+
+	transfers, err := store.ListTransfers(context.Background(), db.ListTransfersParams{Limit: 10})
+	require.NoError(t, err)
+	require.Empty(t, transfers)
+}
+
+func TestTransferTxExternalRef(t *testing.T) {
+	store := NewInMemoryStore()
+	account1 := createTestAccount(t, store)
+	account2 := createTestAccount(t, store)
+	arg := db.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1,
+		ExternalRef:   "order-42",
+	}
+
+	result, err := store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, "order-42", *result.Transfer.ExternalRef)
+
+	_, err = store.TransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, db.ErrDuplicateExternalRef)
+
+	// the rejected transfer moved no money
+	updatedAccount1, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance-1, updatedAccount1.Balance)
+
+	// transfers without a reference never collide
+	arg.ExternalRef = ""
+	for i := 0; i < 2; i++ {
+		result, err = store.TransferTx(context.Background(), arg)
+		require.NoError(t, err)
+		require.Nil(t, result.Transfer.ExternalRef)
+	}
+}
+
+func TestPatchAccount(t *testing.T) {
+	store := NewInMemoryStore()
+	account := createTestAccount(t, store)
+	require.JSONEq(t, `{}`, string(account.Metadata))
+
+	patched, err := store.PatchAccount(context.Background(), db.PatchAccountParams{
+		ID:          account.ID,
+		SetNickname: true,
+		Nickname:    "rainy day",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "rainy day", *patched.Nickname)
+	require.JSONEq(t, `{}`, string(patched.Metadata))
+	require.False(t, patched.Frozen)
+
+	patched, err = store.PatchAccount(context.Background(), db.PatchAccountParams{
+		ID:           account.ID,
+		SetNickname:  true,
+		SetMetadata:  true,
+		Metadata:     `{"color": "blue"}`,
+		SetFrozen:    true,
+		Frozen:       true,
+		FreezeReason: "legal",
+	})
+	require.NoError(t, err)
+	require.Nil(t, patched.Nickname)
+	require.JSONEq(t, `{"color": "blue"}`, string(patched.Metadata))
+	require.True(t, patched.Frozen)
+	require.Equal(t, "legal", *patched.FreezeReason)
+
+	_, err = store.PatchAccount(context.Background(), db.PatchAccountParams{ID: account.ID, SetMetadata: true, Metadata: "{"})
+	require.Error(t, err)
+
+	_, err = store.PatchAccount(context.Background(), db.PatchAccountParams{ID: account.ID + 1, SetFrozen: true})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// every change shows in the audit log
+	records, err := store.ListAccountAuditLog(context.Background(), db.ListAccountAuditLogParams{AccountID: account.ID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+}
+
+func TestTransferTxInternal(t *testing.T) {
+	store := NewInMemoryStore()
+	newAccount := func(owner string) db.Account {
+		account, err := store.CreateAcount(context.Background(), db.CreateAcountParams{
+			Owner:    owner,
+			Balance:  100,
+			Currency: "USD",
+		})
+		require.NoError(t, err)
+		return account
+	}
+	owner := util.RandomOwner()
+	account1, err := store.AddAccountBalance(context.Background(), db.AddAccountBalanceParams{ID: newAccount(owner).ID, Amount: 100})
+	require.NoError(t, err)
+	account2 := newAccount(owner)
+	other := newAccount(util.RandomOwner())
+	feeAccount := newAccount(util.RandomOwner())
+	policies := util.AccountPolicies{db.AccountTypeChecking: {MaxTransferAmount: 10}}
+	store.UseAccountPolicies(func() util.AccountPolicies { return policies })
+
+	transfer := func(toAccountID int64) (db.TransferTxResult, error) {
+		return store.TransferTx(context.Background(), db.TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   toAccountID,
+			Amount:        50,
+			Fee:           5,
+			FeeAccountID:  feeAccount.ID,
+		})
+	}
+
+	// between accounts of the same owner the policy and the whitelist still apply
+	_, err = transfer(account2.ID)
+	require.ErrorIs(t, err, db.ErrTransferLimitExceeded)
+
+	_, err = store.SetAccountWhitelistEnabled(context.Background(), db.SetAccountWhitelistEnabledParams{ID: account1.ID, Enabled: true})
+	require.NoError(t, err)
+	policies = nil
+	_, err = transfer(account2.ID)
+	require.ErrorIs(t, err, db.ErrDestinationNotWhitelisted)
+
+	_, err = store.SetAccountWhitelistEnabled(context.Background(), db.SetAccountWhitelistEnabledParams{ID: account1.ID, Enabled: false})
+	require.NoError(t, err)
+
+	// but the fee does not
+	result, err := transfer(account2.ID)
+	require.NoError(t, err)
+	require.Zero(t, result.Transfer.Fee)
+	require.Nil(t, result.FeeEntry)
+	require.Equal(t, int64(150), result.FromAccount.Balance)
+	require.Equal(t, int64(150), result.ToAccount.Balance)
+
+	// to another owner the fee is charged as well
+	result, err = transfer(other.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), result.Transfer.Fee)
+	require.NotNil(t, result.FeeEntry)
+	require.Equal(t, int64(95), result.FromAccount.Balance)
+	require.Equal(t, int64(150), result.ToAccount.Balance)
+
+	updatedFeeAccount, err := store.GetAccount(context.Background(), feeAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(105), updatedFeeAccount.Balance)
+}
+
+func TestFindBalanceDiscrepancies(t *testing.T) {
+	store := NewInMemoryStore()
+	newAccount := func() db.Account {
+		account, err := store.CreateAcount(context.Background(), db.CreateAcountParams{
+			Owner:    util.RandomOwner(),
+			Currency: "USD",
+		})
+		require.NoError(t, err)
+		return account
+	}
+	source := newAccount()
+	_, err := store.AdjustAccountBalanceTx(context.Background(), db.AdjustAccountBalanceTxParams{
+		AccountID:  source.ID,
+		Amount:     100,
+		Reason:     "opening deposit",
+		AdjustedBy: "banker",
+	})
+	require.NoError(t, err)
+
+	consistent := newAccount()
+	drifted := newAccount()
+	for _, account := range []db.Account{consistent, drifted} {
+		_, err := store.TransferTx(context.Background(), db.TransferTxParams{
+			FromAccountID: source.ID,
+			ToAccountID:   account.ID,
+			Amount:        40,
+		})
+		require.NoError(t, err)
+	}
+
+	rows, err := store.FindBalanceDiscrepancies(context.Background(), db.FindBalanceDiscrepanciesParams{Limit: 10})
+	require.NoError(t, err)
+	require.Empty(t, rows)
+
+	_, err = store.UpdateAccount(context.Background(), db.UpdateAccountParams{ID: drifted.ID, Balance: 55})
+	require.NoError(t, err)
+
+	rows, err = store.FindBalanceDiscrepancies(context.Background(), db.FindBalanceDiscrepanciesParams{Limit: 10})
+	require.NoError(t, err)
+	require.Equal(t, []db.FindBalanceDiscrepanciesRow{
+		{AccountID: drifted.ID, Balance: 55, EntriesTotal: 40, Discrepancy: 15},
+	}, rows)
+
+	rows, err = store.FindBalanceDiscrepancies(context.Background(), db.FindBalanceDiscrepanciesParams{Limit: 10, Offset: 1})
+	require.NoError(t, err)
+	require.Empty(t, rows)
+}
+
+func TestTotalSystemBalance(t *testing.T) {
+	store := NewInMemoryStore()
+	rows, err := store.TotalSystemBalance(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, rows)
+
+	newAccount := func(currency string, deposit int64) db.Account {
+		account, err := store.CreateAcount(context.Background(), db.CreateAcountParams{
+			Owner:    util.RandomOwner(),
+			Currency: currency,
+		})
+		require.NoError(t, err)
+		_, err = store.AdjustAccountBalanceTx(context.Background(), db.AdjustAccountBalanceTxParams{
+			AccountID:  account.ID,
+			Amount:     deposit,
+			Reason:     "opening deposit",
+			AdjustedBy: "banker",
+		})
+		require.NoError(t, err)
+		return account
+	}
+	usd1 := newAccount("USD", 100)
+	usd2 := newAccount("USD", 50)
+	newAccount("EUR", 70)
+
+	_, err = store.TransferTx(context.Background(), db.TransferTxParams{FromAccountID: usd1.ID, ToAccountID: usd2.ID, Amount: 30})
+	require.NoError(t, err)
+	_, err = store.UpdateAccount(context.Background(), db.UpdateAccountParams{ID: usd2.ID, Balance: 90})
+	require.NoError(t, err)
+	// 40 USD become a sub-balance of 20 EUR, which counts towards EUR
+	_, err = store.ConvertSubBalanceTx(context.Background(), db.ConvertSubBalanceTxParams{
+		AccountID:    usd1.ID,
+		FromCurrency: "USD",
+		ToCurrency:   "EUR",
+		Amount:       40,
+		Rate:         big.NewRat(1, 2),
+	})
+	require.NoError(t, err)
+
+	rows, err = store.TotalSystemBalance(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []db.TotalSystemBalanceRow{
+		{Currency: "EUR", Accounts: 1, Balance: 90, EntriesTotal: 90},
+		{Currency: "USD", Accounts: 2, Balance: 120, EntriesTotal: 110, Discrepancy: 10},
+	}, rows)
+}
+
+func TestConfirmTransferTx(t *testing.T) {
+	store := NewInMemoryStore()
+	from := createTestAccount(t, store)
+	to, err := store.CreateAcount(context.Background(), db.CreateAcountParams{Owner: util.RandomOwner(), Currency: from.Currency})
+	require.NoError(t, err)
+	from, err = store.AddAccountBalance(context.Background(), db.AddAccountBalanceParams{ID: from.ID, Amount: 10})
+	require.NoError(t, err)
+
+	createConfirmation := func(expiresAt time.Time) db.TransferConfirmation {
+		confirmation, err := store.CreateTransferConfirmation(context.Background(), db.CreateTransferConfirmationParams{
+			FromAccountID: from.ID,
+			ToAccountID:   to.ID,
+			Amount:        10,
+			Currency:      from.Currency,
+			TokenHash:     "hash",
+			ExpiresAt:     expiresAt,
+		})
+		require.NoError(t, err)
+		return confirmation
+	}
+	arg := db.TransferTxParams{FromAccountID: from.ID, ToAccountID: to.ID, Amount: 10}
+
+	expired := createConfirmation(time.Now().Add(-time.Second))
+	_, err = store.ConfirmTransferTx(context.Background(), expired.ID, arg)
+	require.ErrorIs(t, err, db.ErrTransferConfirmationExpired)
+
+	confirmation := createConfirmation(time.Now().Add(time.Minute))
+	result, err := store.ConfirmTransferTx(context.Background(), confirmation.ID, arg)
+	require.NoError(t, err)
+	require.Equal(t, from.Balance-10, result.FromAccount.Balance)
+	require.Equal(t, int64(10), result.ToAccount.Balance)
+
+	confirmed, err := store.GetTransferConfirmation(context.Background(), confirmation.ID)
+	require.NoError(t, err)
+	require.Equal(t, db.TransferConfirmationConfirmed, confirmed.Status)
+	require.Equal(t, sql.NullInt64{Int64: result.Transfer.ID, Valid: true}, confirmed.TransferID)
+
+	_, err = store.ConfirmTransferTx(context.Background(), confirmation.ID, arg)
+	require.ErrorIs(t, err, db.ErrTransferConfirmationNotPending)
+	_, err = store.ConfirmTransferTx(context.Background(), 1000, arg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestListStatementEntries(t *testing.T) {
+	store := NewInMemoryStore()
+	account := createTestAccount(t, store)
+	for _, amount := range []int64{10, -4, 7} {
+		_, err := store.CreateEntry(context.Background(), db.CreateEntryParams{AccountID: account.ID, Amount: amount})
+		require.NoError(t, err)
+	}
+	_, err := store.ArchiveEntries(context.Background(), db.ArchiveEntriesParams{Before: time.Now().Add(time.Second), BatchSize: 1})
+	require.NoError(t, err)
+
+	from, to := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	total, err := store.CountStatementEntries(context.Background(), db.CountStatementEntriesParams{AccountID: account.ID, FromTime: from, ToTime: to})
+	require.NoError(t, err)
+	require.Equal(t, int64(3), total)
+
+	arg := db.ListStatementEntriesParams{AccountID: account.ID, FromTime: from, ToTime: to, Limit: 2}
+	page, err := store.ListStatementEntries(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	require.Equal(t, int64(10), page[0].Amount)
+	require.Equal(t, int64(-4), page[1].Amount)
+
+	arg.AfterID = page[1].ID
+	page, err = store.ListStatementEntries(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	require.Equal(t, int64(7), page[0].Amount)
+
+	total, err = store.CountStatementEntries(context.Background(), db.CountStatementEntriesParams{AccountID: account.ID, FromTime: to, ToTime: to.Add(time.Hour)})
+	require.NoError(t, err)
+	require.Zero(t, total)
+}
+
+func TestListEntriesWithBalance(t *testing.T) {
+	store := NewInMemoryStore()
+	account := createTestAccount(t, store)
+	for _, amount := range []int64{10, -4, 7} {
+		_, err := store.CreateEntry(context.Background(), db.CreateEntryParams{AccountID: account.ID, Amount: amount})
+		require.NoError(t, err)
+	}
+	_, err := store.CreateEntry(context.Background(), db.CreateEntryParams{AccountID: createTestAccount(t, store).ID, Amount: 100})
+	require.NoError(t, err)
+	_, err = store.ArchiveEntries(context.Background(), db.ArchiveEntriesParams{Before: time.Now().Add(time.Second), BatchSize: 1})
+	require.NoError(t, err)
+
+	entries, err := store.ListEntriesWithBalance(context.Background(), account.ID, 2, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, int64(10), entries[0].RunningBalance)
+	require.Equal(t, int64(6), entries[1].RunningBalance)
+
+	entries, err = store.ListEntriesWithBalance(context.Background(), account.ID, 2, 2)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, int64(7), entries[0].Amount)
+	require.Equal(t, int64(13), entries[0].RunningBalance)
+}
+
+func TestSpendingByCategory(t *testing.T) {
+	store := NewInMemoryStore()
+	account1 := createTestAccount(t, store)
+	account2 := createTestAccount(t, store)
+	_, err := store.AddAccountBalance(context.Background(), db.AddAccountBalanceParams{ID: account1.ID, Amount: 2000})
+	require.NoError(t, err)
+
+	for _, transfer := range []struct {
+		amount   int64
+		category string
+	}{{40, "groceries"}, {900, "rent"}, {60, "groceries"}, {5, ""}, {7, ""}} {
+		result, err := store.TransferTx(context.Background(), db.TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        transfer.amount,
+			Category:      transfer.category,
+		})
+		require.NoError(t, err)
+		if transfer.category == "" {
+			require.Nil(t, result.Transfer.Category)
+		} else {
+			require.Equal(t, transfer.category, *result.Transfer.Category)
+		}
+	}
+
+	arg := db.SpendingByCategoryParams{
+		AccountID: account1.ID,
+		FromTime:  time.Now().Add(-time.Hour),
+		ToTime:    time.Now().Add(time.Hour),
+	}
+	rows, err := store.SpendingByCategory(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, []db.SpendingByCategoryRow{
+		{Category: "rent", Total: 900, Transfers: 1},
+		{Category: "groceries", Total: 100, Transfers: 2},
+		{Category: "", Total: 12, Transfers: 2},
+	}, rows)
+
+	// spending is counted on the sending side only
+	arg.AccountID = account2.ID
+	rows, err = store.SpendingByCategory(context.Background(), arg)
+	require.NoError(t, err)
+	require.Empty(t, rows)
+}
+
+func TestTopAccountsByBalance(t *testing.T) {
+	store := NewInMemoryStore()
+	seed := func(currency string, balance int64) db.Account {
+		account, err := store.CreateAcount(context.Background(), db.CreateAcountParams{
+			Owner:    util.RandomOwner(),
+			Balance:  balance,
+			Currency: currency,
+		})
+		require.NoError(t, err)
+		return account
+	}
+
+	middle := seed("EUR", 200)
+	highest := seed("EUR", 300)
+	seed("USD", 1000)
+	tied := seed("EUR", 200)
+
+	accounts, err := store.TopAccountsByBalance(context.Background(), db.TopAccountsByBalanceParams{Currency: "EUR", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, accounts, 3)
+	require.Equal(t, highest.ID, accounts[0].ID)
+	// ties go to the oldest account
+	require.Equal(t, middle.ID, accounts[1].ID)
+	require.Equal(t, tied.ID, accounts[2].ID)
+
+	accounts, err = store.TopAccountsByBalance(context.Background(), db.TopAccountsByBalanceParams{Currency: "EUR", Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+	require.Equal(t, highest.ID, accounts[0].ID)
+}
+
+func TestConvertSubBalanceTx(t *testing.T) {
+	store := NewInMemoryStore()
+	account, err := store.CreateAcount(context.Background(), db.CreateAcountParams{
+		Owner:    util.RandomOwner(),
+		Balance:  10000,
+		Currency: "USD",
+	})
+	require.NoError(t, err)
+
+	result, err := store.ConvertSubBalanceTx(context.Background(), db.ConvertSubBalanceTxParams{
+		AccountID:    account.ID,
+		FromCurrency: "USD",
+		ToCurrency:   "EUR",
+		Amount:       1000,
+		Rate:         big.NewRat(9, 10),
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(9000), result.Account.Balance)
+	require.Equal(t, db.CurrencyBalanceChange{Currency: "USD", Amount: -1000, Balance: 9000, EntryID: result.From.EntryID}, result.From)
+	require.Equal(t, db.CurrencyBalanceChange{Currency: "EUR", Amount: 900, Balance: 900, EntryID: result.To.EntryID}, result.To)
+
+	result, err = store.ConvertSubBalanceTx(context.Background(), db.ConvertSubBalanceTxParams{
+		AccountID:    account.ID,
+		FromCurrency: "EUR",
+		ToCurrency:   "USD",
+		Amount:       500,
+		Rate:         big.NewRat(11, 10),
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(400), result.From.Balance)
+	require.Equal(t, int64(9550), result.Account.Balance)
+
+	// the source sub-balance can't go negative
+	_, err = store.ConvertSubBalanceTx(context.Background(), db.ConvertSubBalanceTxParams{
+		AccountID:    account.ID,
+		FromCurrency: "EUR",
+		ToCurrency:   "USD",
+		Amount:       401,
+		Rate:         big.NewRat(11, 10),
+	})
+	require.ErrorIs(t, err, db.ErrInsufficientFunds)
+
+	subBalances, err := store.ListSubBalances(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Len(t, subBalances, 1)
+	require.Equal(t, int64(400), subBalances[0].Balance)
+
+	_, err = store.ConvertSubBalanceTx(context.Background(), db.ConvertSubBalanceTxParams{
+		AccountID:    account.ID,
+		FromCurrency: "GBP",
+		ToCurrency:   "USD",
+		Amount:       1,
+		Rate:         big.NewRat(1, 1),
+	})
+	require.ErrorIs(t, err, db.ErrInsufficientFunds)
+
+	_, err = store.ConvertSubBalanceTx(context.Background(), db.ConvertSubBalanceTxParams{
+		AccountID:    account.ID,
+		FromCurrency: "USD",
+		ToCurrency:   "USD",
+		Amount:       1,
+		Rate:         big.NewRat(1, 1),
+	})
+	require.ErrorIs(t, err, db.ErrSameCurrency)
+}
+
+func TestListFrozenAccounts(t *testing.T) {
+	store := NewInMemoryStore()
+	frozen := make(map[string]int64)
+	for _, reason := range util.DefaultFreezeReasons() {
+		account, err := store.PatchAccount(context.Background(), db.PatchAccountParams{
+			ID:           createTestAccount(t, store).ID,
+			SetFrozen:    true,
+			Frozen:       true,
+			FreezeReason: reason,
+		})
+		require.NoError(t, err)
+		require.Equal(t, reason, *account.FreezeReason)
+		frozen[reason] = account.ID
+	}
+	createTestAccount(t, store)
+
+	for reason, id := range frozen {
+		accounts, err := store.ListFrozenAccounts(context.Background(), db.ListFrozenAccountsParams{Reason: reason, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, accounts, 1)
+		require.Equal(t, id, accounts[0].ID)
+	}
+
+	accounts, err := store.ListFrozenAccounts(context.Background(), db.ListFrozenAccountsParams{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, accounts, len(frozen))
+
+	// unfreezing clears the reason
+	unfrozen, err := store.PatchAccount(context.Background(), db.PatchAccountParams{ID: frozen["kyc"], SetFrozen: true})
+	require.NoError(t, err)
+	require.Nil(t, unfrozen.FreezeReason)
+
+	accounts, err = store.ListFrozenAccounts(context.Background(), db.ListFrozenAccountsParams{Reason: "kyc", Limit: 10})
+	require.NoError(t, err)
+	require.Empty(t, accounts)
+
+	_, err = store.PatchAccount(context.Background(), db.PatchAccountParams{ID: unfrozen.ID, SetFrozen: true, Frozen: true})
+	require.ErrorIs(t, err, db.ErrConstraintViolation)
+}
+
+func TestSimulateTransfersTx(t *testing.T) {
+	store := NewInMemoryStore()
+	accounts := make([]db.Account, 3)
+	for i, balance := range []int64{100, 0, 0} {
+		account, err := store.CreateAcount(context.Background(), db.CreateAcountParams{
+			Owner:    util.RandomOwner(),
+			Balance:  balance,
+			Currency: "USD",
+		})
+		require.NoError(t, err)
+		accounts[i] = account
+	}
+	a1, a2, a3 := accounts[0].ID, accounts[1].ID, accounts[2].ID
+
+	simulation, err := store.SimulateTransfersTx(context.Background(), []db.TransferTxParams{
+		{FromAccountID: a1, ToAccountID: a2, Amount: 150},
+		// only goes through because the first transfer did not
+		{FromAccountID: a1, ToAccountID: a2, Amount: 100},
+		{FromAccountID: a2, ToAccountID: a3, Amount: 40},
+		{FromAccountID: a1, ToAccountID: a3, Amount: 1},
+	})
+	require.NoError(t, err)
+	require.Len(t, simulation.Transfers, 4)
+	require.ErrorIs(t, simulation.Transfers[0].Err, db.ErrInsufficientFunds)
+	require.NoError(t, simulation.Transfers[1].Err)
+	require.NoError(t, simulation.Transfers[2].Err)
+	require.ErrorIs(t, simulation.Transfers[3].Err, db.ErrInsufficientFunds)
+	require.Equal(t, []db.ProjectedBalance{
+		{AccountID: a1, Balance: 0},
+		{AccountID: a2, Balance: 60},
+		{AccountID: a3, Balance: 40},
+	}, simulation.Balances)
+
+	// nothing was persisted
+	for _, account := range accounts {
+		unchanged, err := store.GetAccount(context.Background(), account.ID)
+		require.NoError(t, err)
+		require.Equal(t, account.Balance, unchanged.Balance)
+	}
+	require.Empty(t, store.entries)
+	require.Empty(t, store.transfers)
+}
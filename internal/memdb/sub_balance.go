@@ -0,0 +1,162 @@
+package memdb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	db "github.com/khuongkd/simplebank/db/sqlc"
+	"github.com/khuongkd/simplebank/util"
+)
+
+// checkSubBalance mirrors the CHECK constraint of the account_sub_balances table
+func checkSubBalance(balance int64) error {
+	if balance < 0 {
+		return fmt.Errorf("%w: %s", db.ErrConstraintViolation, db.ConstraintSubBalanceNonNegative)
+	}
+	return nil
+}
+
+func (store *InMemoryStore) GetSubBalanceForUpdate(ctx context.Context, arg db.GetSubBalanceForUpdateParams) (db.AccountSubBalance, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	subBalance, ok := store.subBalances[arg.AccountID][arg.Currency]
+	if !ok {
+		return db.AccountSubBalance{}, sql.ErrNoRows
+	}
+	return subBalance, nil
+}
+
+func (store *InMemoryStore) ListSubBalances(ctx context.Context, accountID int64) ([]db.AccountSubBalance, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var subBalances []db.AccountSubBalance
+	for _, subBalance := range store.subBalances[accountID] {
+		subBalances = append(subBalances, subBalance)
+	}
+	sort.Slice(subBalances, func(i, j int) bool { return subBalances[i].Currency < subBalances[j].Currency })
+	return subBalances, nil
+}
+
+func (store *InMemoryStore) addSubBalance(arg db.AddSubBalanceParams) (db.AccountSubBalance, error) {
+	if err := store.requireAccount(arg.AccountID); err != nil {
+		return db.AccountSubBalance{}, err
+	}
+	subBalance, ok := store.subBalances[arg.AccountID][arg.Currency]
+	if !ok {
+		subBalance = db.AccountSubBalance{AccountID: arg.AccountID, Currency: arg.Currency}
+	}
+	if err := checkSubBalance(subBalance.Balance + arg.Amount); err != nil {
+		return db.AccountSubBalance{}, err
+	}
+
+	subBalance.Balance += arg.Amount
+	subBalance.UpdatedAt = time.Now()
+	if store.subBalances[arg.AccountID] == nil {
+		store.subBalances[arg.AccountID] = make(map[string]db.AccountSubBalance)
+	}
+	store.subBalances[arg.AccountID][arg.Currency] = subBalance
+	return subBalance, nil
+}
+
+func (store *InMemoryStore) AddSubBalance(ctx context.Context, arg db.AddSubBalanceParams) (db.AccountSubBalance, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return store.addSubBalance(arg)
+}
+
+func (store *InMemoryStore) createSubBalanceEntry(arg db.CreateSubBalanceEntryParams) (db.SubBalanceEntry, error) {
+	// mirrors the foreign key of sub_balance_entries on account_sub_balances
+	if _, ok := store.subBalances[arg.AccountID][arg.Currency]; !ok {
+		return db.SubBalanceEntry{}, sql.ErrNoRows
+	}
+	entry := db.SubBalanceEntry{
+		ID:        int64(len(store.subBalanceEntries) + 1),
+		AccountID: arg.AccountID,
+		Currency:  arg.Currency,
+		Amount:    arg.Amount,
+		CreatedAt: time.Now(),
+	}
+	store.subBalanceEntries = append(store.subBalanceEntries, entry)
+	return entry, nil
+}
+
+func (store *InMemoryStore) CreateSubBalanceEntry(ctx context.Context, arg db.CreateSubBalanceEntryParams) (db.SubBalanceEntry, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return store.createSubBalanceEntry(arg)
+}
+
+// ConvertSubBalanceTx checks every condition before changing anything, since there is no transaction to roll back
+func (store *InMemoryStore) ConvertSubBalanceTx(ctx context.Context, params db.ConvertSubBalanceTxParams) (db.ConvertSubBalanceTxResult, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var result db.ConvertSubBalanceTxResult
+	account, ok := store.accounts[params.AccountID]
+	if !ok {
+		return result, sql.ErrNoRows
+	}
+	result.Account = account
+	if params.FromCurrency == params.ToCurrency {
+		return result, db.ErrSameCurrency
+	}
+	if !params.AllowedPairs.Allows(params.FromCurrency, params.ToCurrency) {
+		return result, fmt.Errorf("%w: %s to %s", db.ErrCurrencyPairNotAllowed, params.FromCurrency, params.ToCurrency)
+	}
+
+	result.RoundingMode = params.RoundingMode
+	if result.RoundingMode == "" {
+		result.RoundingMode = util.RoundHalfUp
+	}
+	converted, err := util.ConvertAmount(params.Amount, params.Rate, params.ToCurrency, result.RoundingMode)
+	if err != nil {
+		return result, err
+	}
+
+	if params.FromCurrency == account.Currency {
+		if account.Balance-params.Amount < account.HeldBalance {
+			return result, db.ErrInsufficientFunds
+		}
+		if account.Balance-params.Amount < account.MinBalance {
+			return result, fmt.Errorf("%w: %d is below the minimum of %d", db.ErrMinBalanceViolation, account.Balance-params.Amount, account.MinBalance)
+		}
+	} else if store.subBalances[account.ID][params.FromCurrency].Balance < params.Amount {
+		return result, db.ErrInsufficientFunds
+	}
+
+	result.From, err = store.changeBalance(&result.Account, params.FromCurrency, -params.Amount)
+	if err != nil {
+		return result, err
+	}
+	result.To, err = store.changeBalance(&result.Account, params.ToCurrency, converted)
+	return result, err
+}
+
+// changeBalance moves amount into the balance of the account when currency is its own, into its sub-balance otherwise
+func (store *InMemoryStore) changeBalance(account *db.Account, currency string, amount int64) (db.CurrencyBalanceChange, error) {
+	change := db.CurrencyBalanceChange{Currency: currency, Amount: amount}
+	if currency == account.Currency {
+		entry, err := store.createEntry(account.ID, amount)
+		if err != nil {
+			return change, err
+		}
+		*account, err = store.addAccountBalance(account.ID, amount)
+		change.Balance, change.EntryID = account.Balance, entry.ID
+		return change, err
+	}
+
+	subBalance, err := store.addSubBalance(db.AddSubBalanceParams{AccountID: account.ID, Currency: currency, Amount: amount})
+	if err != nil {
+		return change, err
+	}
+	entry, err := store.createSubBalanceEntry(db.CreateSubBalanceEntryParams{AccountID: account.ID, Currency: currency, Amount: amount})
+	change.Balance, change.EntryID = subBalance.Balance, entry.ID
+	return change, err
+}
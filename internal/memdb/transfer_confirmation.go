@@ -0,0 +1,122 @@
+package memdb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	db "github.com/khuongkd/simplebank/db/sqlc"
+)
+
+// completeTransferConfirmation marks a pending confirmation confirmed, like the guarded UPDATE query does
+func (store *InMemoryStore) completeTransferConfirmation(arg db.CompleteTransferConfirmationParams) (db.TransferConfirmation, error) {
+	confirmation, ok := store.transferConfirmations[arg.ID]
+	if !ok || confirmation.Status != db.TransferConfirmationPending {
+		return db.TransferConfirmation{}, sql.ErrNoRows
+	}
+	confirmation.Status = db.TransferConfirmationConfirmed
+	confirmation.TransferID = arg.TransferID
+	store.transferConfirmations[arg.ID] = confirmation
+	return confirmation, nil
+}
+
+func (store *InMemoryStore) CreateTransferConfirmation(ctx context.Context, arg db.CreateTransferConfirmationParams) (db.TransferConfirmation, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if err := store.requireAccount(arg.FromAccountID); err != nil {
+		return db.TransferConfirmation{}, err
+	}
+	if err := store.requireAccount(arg.ToAccountID); err != nil {
+		return db.TransferConfirmation{}, err
+	}
+	if arg.Amount <= 0 {
+		return db.TransferConfirmation{}, fmt.Errorf("%w: %s", db.ErrConstraintViolation, db.ConstraintConfirmationAmountPositive)
+	}
+
+	store.nextTransferConfirmationID++
+	confirmation := db.TransferConfirmation{
+		ID:            store.nextTransferConfirmationID,
+		FromAccountID: arg.FromAccountID,
+		ToAccountID:   arg.ToAccountID,
+		Amount:        arg.Amount,
+		Currency:      arg.Currency,
+		ExternalRef:   arg.ExternalRef,
+		Category:      arg.Category,
+		TokenHash:     arg.TokenHash,
+		Status:        db.TransferConfirmationPending,
+		ExpiresAt:     arg.ExpiresAt,
+		CreatedAt:     time.Now(),
+	}
+	store.transferConfirmations[confirmation.ID] = confirmation
+	return confirmation, nil
+}
+
+func (store *InMemoryStore) GetTransferConfirmation(ctx context.Context, id int64) (db.TransferConfirmation, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	confirmation, ok := store.transferConfirmations[id]
+	if !ok {
+		return db.TransferConfirmation{}, sql.ErrNoRows
+	}
+	return confirmation, nil
+}
+
+func (store *InMemoryStore) GetTransferConfirmationForUpdate(ctx context.Context, id int64) (db.TransferConfirmation, error) {
+	return store.GetTransferConfirmation(ctx, id)
+}
+
+func (store *InMemoryStore) CompleteTransferConfirmation(ctx context.Context, arg db.CompleteTransferConfirmationParams) (db.TransferConfirmation, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return store.completeTransferConfirmation(arg)
+}
+
+// ConfirmTransferTx performs the transfer awaiting confirmation and marks it confirmed.
+func (store *InMemoryStore) ConfirmTransferTx(ctx context.Context, id int64, params db.TransferTxParams) (db.TransferTxResult, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	confirmation, ok := store.transferConfirmations[id]
+	if !ok {
+		return db.TransferTxResult{}, sql.ErrNoRows
+	}
+	if confirmation.Status != db.TransferConfirmationPending {
+		return db.TransferTxResult{}, db.ErrTransferConfirmationNotPending
+	}
+	if !time.Now().Before(confirmation.ExpiresAt) {
+		return db.TransferTxResult{}, db.ErrTransferConfirmationExpired
+	}
+
+	result, err := store.transfer(params)
+	if err != nil {
+		return result, err
+	}
+
+	_, err = store.completeTransferConfirmation(db.CompleteTransferConfirmationParams{
+		ID:         id,
+		TransferID: sql.NullInt64{Int64: result.Transfer.ID, Valid: true},
+	})
+	return result, err
+}
+
+func (store *InMemoryStore) CountPendingTransferConfirmationsByAccount(ctx context.Context, arg db.CountPendingTransferConfirmationsByAccountParams) (int64, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return store.countPendingTransferConfirmations(arg.AccountID, arg.Now), nil
+}
+
+func (store *InMemoryStore) countPendingTransferConfirmations(accountID int64, now time.Time) int64 {
+	var count int64
+	for _, confirmation := range store.transferConfirmations {
+		if confirmation.Status == db.TransferConfirmationPending && confirmation.ExpiresAt.After(now) &&
+			(confirmation.FromAccountID == accountID || confirmation.ToAccountID == accountID) {
+			count++
+		}
+	}
+	return count
+}
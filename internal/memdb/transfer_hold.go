@@ -0,0 +1,242 @@
+package memdb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	db "github.com/khuongkd/simplebank/db/sqlc"
+)
+
+func (store *InMemoryStore) addAccountHeldBalance(id, amount int64) (db.Account, error) {
+	account, ok := store.accounts[id]
+	if !ok {
+		return db.Account{}, sql.ErrNoRows
+	}
+	if err := checkHeldBalance(account.Balance, account.HeldBalance+amount); err != nil {
+		return db.Account{}, err
+	}
+	account.HeldBalance += amount
+	store.putAccount(account)
+	return account, nil
+}
+
+func (store *InMemoryStore) createTransferHold(arg db.CreateTransferHoldParams) (db.TransferHold, error) {
+	if err := store.requireAccount(arg.FromAccountID); err != nil {
+		return db.TransferHold{}, err
+	}
+	if err := store.requireAccount(arg.ToAccountID); err != nil {
+		return db.TransferHold{}, err
+	}
+	if arg.Amount <= 0 {
+		return db.TransferHold{}, fmt.Errorf("%w: %s", db.ErrConstraintViolation, db.ConstraintHoldAmountPositive)
+	}
+
+	store.nextTransferHoldID++
+	hold := db.TransferHold{
+		ID:            store.nextTransferHoldID,
+		FromAccountID: arg.FromAccountID,
+		ToAccountID:   arg.ToAccountID,
+		Amount:        arg.Amount,
+		Status:        db.TransferHoldAuthorized,
+		CreatedAt:     time.Now(),
+	}
+	store.transferHolds[hold.ID] = hold
+	return hold, nil
+}
+
+// settleTransferHold updates an authorized hold, like the guarded UPDATE query does
+func (store *InMemoryStore) settleTransferHold(arg db.SettleTransferHoldParams) (db.TransferHold, error) {
+	hold, ok := store.transferHolds[arg.ID]
+	if !ok || hold.Status != db.TransferHoldAuthorized {
+		return db.TransferHold{}, sql.ErrNoRows
+	}
+	hold.Status = arg.Status
+	hold.TransferID = arg.TransferID
+	store.transferHolds[arg.ID] = hold
+	return hold, nil
+}
+
+func (store *InMemoryStore) AddAccountHeldBalance(ctx context.Context, arg db.AddAccountHeldBalanceParams) (db.Account, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return store.addAccountHeldBalance(arg.ID, arg.Amount)
+}
+
+func (store *InMemoryStore) CountAuthorizedHoldsByAccount(ctx context.Context, accountID int64) (int64, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return store.countAuthorizedHolds(accountID), nil
+}
+
+// countAuthorizedHolds counts the authorized holds paying from or into the account; callers must hold the mutex
+func (store *InMemoryStore) countAuthorizedHolds(accountID int64) int64 {
+	var count int64
+	for _, hold := range store.transferHolds {
+		if hold.Status == db.TransferHoldAuthorized && (hold.FromAccountID == accountID || hold.ToAccountID == accountID) {
+			count++
+		}
+	}
+	return count
+}
+
+func (store *InMemoryStore) CreateTransferHold(ctx context.Context, arg db.CreateTransferHoldParams) (db.TransferHold, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return store.createTransferHold(arg)
+}
+
+func (store *InMemoryStore) GetTransferHold(ctx context.Context, id int64) (db.TransferHold, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	hold, ok := store.transferHolds[id]
+	if !ok {
+		return db.TransferHold{}, sql.ErrNoRows
+	}
+	return hold, nil
+}
+
+func (store *InMemoryStore) GetTransferHoldForUpdate(ctx context.Context, id int64) (db.TransferHold, error) {
+	return store.GetTransferHold(ctx, id)
+}
+
+func (store *InMemoryStore) SettleTransferHold(ctx context.Context, arg db.SettleTransferHoldParams) (db.TransferHold, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return store.settleTransferHold(arg)
+}
+
+// AuthorizeTransferTx reserves the amount on the source account without moving any money.
+func (store *InMemoryStore) AuthorizeTransferTx(ctx context.Context, params db.CreateTransferParams) (db.TransferHoldTxResult, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var result db.TransferHoldTxResult
+	fromAccount, ok := store.accounts[params.FromAccountID]
+	if !ok {
+		return result, sql.ErrNoRows
+	}
+	if _, ok := store.accounts[params.ToAccountID]; !ok {
+		return result, sql.ErrNoRows
+	}
+	if fromAccount.Balance-fromAccount.HeldBalance < params.Amount {
+		return result, db.ErrInsufficientFunds
+	}
+	if err := store.checkWhitelisted(params.FromAccountID, params.ToAccountID); err != nil {
+		return result, err
+	}
+	// check the balance left once every hold on the account is captured, like db.SQLStore.AuthorizeTransferTx
+	balanceAfter := fromAccount.Balance - fromAccount.HeldBalance - params.Amount
+	if err := checkAccountPolicy(store.accountPolicies().For(fromAccount.AccountType), params.Amount, balanceAfter); err != nil {
+		return result, err
+	}
+	if balanceAfter < fromAccount.MinBalance {
+		return result, fmt.Errorf("%w: %d is below the minimum of %d", db.ErrMinBalanceViolation, balanceAfter, fromAccount.MinBalance)
+	}
+
+	var err error
+	result.Hold, err = store.createTransferHold(db.CreateTransferHoldParams{
+		FromAccountID: params.FromAccountID,
+		ToAccountID:   params.ToAccountID,
+		Amount:        params.Amount,
+	})
+	if err != nil {
+		return result, err
+	}
+	result.FromAccount, err = store.addAccountHeldBalance(params.FromAccountID, params.Amount)
+	return result, err
+}
+
+// CaptureTransferTx releases an authorized hold and performs the transfer it reserved.
+// As in TransferTx, every check runs before the store changes.
+func (store *InMemoryStore) CaptureTransferTx(ctx context.Context, holdID int64) (db.TransferTxResult, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	hold, err := store.authorizedHold(holdID)
+	if err != nil {
+		return db.TransferTxResult{}, err
+	}
+	if err := checkTransferAmount(hold.Amount); err != nil {
+		return db.TransferTxResult{}, err
+	}
+	if err := store.checkWhitelisted(hold.FromAccountID, hold.ToAccountID); err != nil {
+		return db.TransferTxResult{}, err
+	}
+
+	fee, feeAccountID, err := store.transferFee(hold.Amount, store.accounts[hold.FromAccountID].Currency)
+	if err != nil {
+		return db.TransferTxResult{}, err
+	}
+
+	if _, err := store.addAccountHeldBalance(hold.FromAccountID, -hold.Amount); err != nil {
+		return db.TransferTxResult{}, err
+	}
+	result, err := store.transfer(db.TransferTxParams{
+		FromAccountID: hold.FromAccountID,
+		ToAccountID:   hold.ToAccountID,
+		Amount:        hold.Amount,
+		Fee:           fee,
+		FeeAccountID:  feeAccountID,
+	})
+	if err != nil {
+		// there is no transaction to roll back, so hold the amount again
+		if _, herr := store.addAccountHeldBalance(hold.FromAccountID, hold.Amount); herr != nil {
+			return result, herr
+		}
+		return result, err
+	}
+
+	_, err = store.settleTransferHold(db.SettleTransferHoldParams{
+		ID:         holdID,
+		Status:     db.TransferHoldCaptured,
+		TransferID: sql.NullInt64{Int64: result.Transfer.ID, Valid: true},
+	})
+	return result, err
+}
+
+// VoidTransferTx releases an authorized hold without moving any money.
+func (store *InMemoryStore) VoidTransferTx(ctx context.Context, holdID int64) (db.TransferHoldTxResult, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var result db.TransferHoldTxResult
+	hold, err := store.authorizedHold(holdID)
+	if err != nil {
+		return result, err
+	}
+
+	result.FromAccount, err = store.addAccountHeldBalance(hold.FromAccountID, -hold.Amount)
+	if err != nil {
+		return result, err
+	}
+	result.Hold, err = store.settleTransferHold(db.SettleTransferHoldParams{
+		ID:     holdID,
+		Status: db.TransferHoldVoided,
+	})
+	return result, err
+}
+
+// authorizedHold returns the hold when it is still authorized and both of its accounts exist; callers must hold the mutex
+func (store *InMemoryStore) authorizedHold(id int64) (db.TransferHold, error) {
+	hold, ok := store.transferHolds[id]
+	if !ok {
+		return hold, sql.ErrNoRows
+	}
+	if hold.Status != db.TransferHoldAuthorized {
+		return hold, db.ErrHoldNotAuthorized
+	}
+	if _, ok := store.accounts[hold.FromAccountID]; !ok {
+		return hold, sql.ErrNoRows
+	}
+	if _, ok := store.accounts[hold.ToAccountID]; !ok {
+		return hold, sql.ErrNoRows
+	}
+	return hold, nil
+}
@@ -0,0 +1,47 @@
+// Package queryobserver provides observers that record the queries and transactions of a store for metrics and tracing.
+package queryobserver
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Statuses an operation is labelled with in the metrics
+const (
+	statusOK    = "ok"
+	statusError = "error"
+)
+
+// Prometheus records the duration of every store operation in a histogram labelled with its method and status
+type Prometheus struct {
+	durations *prometheus.HistogramVec
+}
+
+// NewPrometheus returns an observer whose metrics are registered with registerer
+func NewPrometheus(registerer prometheus.Registerer) (*Prometheus, error) {
+	durations := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "simplebank",
+		Subsystem: "db",
+		Name:      "operation_duration_seconds",
+		Help:      "Duration of the queries and transactions run by the store.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "status"})
+	if err := registerer.Register(durations); err != nil {
+		return nil, err
+	}
+	return &Prometheus{durations: durations}, nil
+}
+
+func (observer *Prometheus) BeforeQuery(ctx context.Context, method string) context.Context {
+	return ctx
+}
+
+func (observer *Prometheus) AfterQuery(ctx context.Context, method string, duration time.Duration, err error) {
+	status := statusOK
+	if err != nil {
+		status = statusError
+	}
+	observer.durations.WithLabelValues(method, status).Observe(duration.Seconds())
+}
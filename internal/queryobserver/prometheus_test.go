@@ -0,0 +1,51 @@
+package queryobserver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrometheus(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	observer, err := NewPrometheus(registry)
+	require.NoError(t, err)
+
+	for _, err := range []error{nil, nil, errors.New("failed")} {
+		ctx := observer.BeforeQuery(context.Background(), "GetAccount")
+		observer.AfterQuery(ctx, "GetAccount", time.Millisecond, err)
+	}
+	ctx := observer.BeforeQuery(context.Background(), "TransferTx")
+	observer.AfterQuery(ctx, "TransferTx", time.Second, nil)
+
+	require.Equal(t, 3, testutil.CollectAndCount(observer.durations))
+	count, err := testutil.GatherAndCount(registry, "simplebank_db_operation_duration_seconds")
+	require.NoError(t, err)
+	require.Equal(t, 3, count)
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	require.Len(t, families, 1)
+	samples := map[string]uint64{}
+	for _, metric := range families[0].GetMetric() {
+		labels := map[string]string{}
+		for _, label := range metric.GetLabel() {
+			labels[label.GetName()] = label.GetValue()
+		}
+		samples[labels["method"]+" "+labels["status"]] = metric.GetHistogram().GetSampleCount()
+	}
+	require.Equal(t, map[string]uint64{
+		"GetAccount ok":    2,
+		"GetAccount error": 1,
+		"TransferTx ok":    1,
+	}, samples)
+
+	// the metrics can only be registered once
+	_, err = NewPrometheus(registry)
+	require.Error(t, err)
+}
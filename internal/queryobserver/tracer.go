@@ -0,0 +1,56 @@
+package queryobserver
+
+import (
+	"context"
+	"log"
+	"sync/atomic"
+	"time"
+
+	db "github.com/khuongkd/simplebank/db/sqlc"
+)
+
+// Tracer logs a span for every store operation. Each span has an ID,
+// and the queries a transaction runs name the span of the transaction as their parent.
+type Tracer struct {
+	logger *log.Logger
+	lastID uint64
+}
+
+// NewTracer returns a tracer that logs its spans to the standard logger
+func NewTracer() *Tracer {
+	return &Tracer{logger: log.Default()}
+}
+
+type spanKey struct{}
+
+// span is the operation being traced
+type span struct {
+	id     uint64
+	parent uint64
+	start  time.Time
+}
+
+func (tracer *Tracer) BeforeQuery(ctx context.Context, method string) context.Context {
+	s := span{
+		id:    atomic.AddUint64(&tracer.lastID, 1),
+		start: time.Now(),
+	}
+	if parent, ok := ctx.Value(spanKey{}).(span); ok {
+		s.parent = parent.id
+	}
+	return context.WithValue(ctx, spanKey{}, s)
+}
+
+func (tracer *Tracer) AfterQuery(ctx context.Context, method string, duration time.Duration, err error) {
+	s, _ := ctx.Value(spanKey{}).(span)
+	actor, ok := db.ActorFrom(ctx)
+	if !ok {
+		actor = "-"
+	}
+	status := statusOK
+	if err != nil {
+		status = statusError
+	}
+	tracer.logger.Printf("span=%d parent=%d method=%s start=%s duration=%s actor=%s status=%s err=%v",
+		s.id, s.parent, method, s.start.UTC().Format(time.RFC3339Nano), duration, actor, status, err)
+}
@@ -0,0 +1,32 @@
+package queryobserver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	db "github.com/khuongkd/simplebank/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracer(t *testing.T) {
+	var logs bytes.Buffer
+	tracer := NewTracer()
+	tracer.logger = log.New(&logs, "", 0)
+
+	txCtx := tracer.BeforeQuery(db.WithActor(context.Background(), "alice"), "TransferTx")
+	queryCtx := tracer.BeforeQuery(txCtx, "GetAccountForUpdate")
+	tracer.AfterQuery(queryCtx, "GetAccountForUpdate", time.Millisecond, nil)
+	tracer.AfterQuery(txCtx, "TransferTx", 2*time.Millisecond, errors.New("failed"))
+
+	lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+	require.Len(t, lines, 2)
+	require.Contains(t, lines[0], "span=2 parent=1 method=GetAccountForUpdate ")
+	require.Contains(t, lines[0], "duration=1ms actor=alice status=ok err=<nil>")
+	require.Contains(t, lines[1], "span=1 parent=0 method=TransferTx ")
+	require.Contains(t, lines[1], "duration=2ms actor=alice status=error err=failed")
+}
@@ -0,0 +1,119 @@
+// Package scheduler performs scheduled transfers once they are due
+// and expires ownership transfer requests that were not accepted in time.
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	db "github.com/khuongkd/simplebank/db/sqlc"
+)
+
+// defaultBatchSize caps how many due transfers one run picks up
+const defaultBatchSize = 100
+
+// Scheduler periodically performs the scheduled transfers that are due
+type Scheduler struct {
+	store     db.Store
+	interval  time.Duration
+	batchSize int32
+	now       func() time.Time
+}
+
+// New returns a scheduler checking for due transfers every interval
+func New(store db.Store, interval time.Duration) *Scheduler {
+	return &Scheduler{
+		store:     store,
+		interval:  interval,
+		batchSize: defaultBatchSize,
+		now:       time.Now,
+	}
+}
+
+// Run performs due transfers every interval until ctx is done
+func (scheduler *Scheduler) Run(ctx context.Context) {
+	ctx = db.WithActor(ctx, "scheduler")
+	ticker := time.NewTicker(scheduler.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := scheduler.RunDue(ctx); err != nil {
+			log.Println("cannot run scheduled transfers:", err)
+		}
+		if _, err := scheduler.ExpireOwnershipRequests(ctx); err != nil {
+			log.Println("cannot expire ownership transfer requests:", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDue performs the transfers that are due now and returns how many it processed.
+// A transfer that cannot succeed is marked failed with the reason; one that hit a
+// transient error stays pending and is retried on the next run.
+func (scheduler *Scheduler) RunDue(ctx context.Context) (int, error) {
+	due, err := scheduler.store.ListDueScheduledTransfers(ctx, db.ListDueScheduledTransfersParams{
+		Now:      scheduler.now(),
+		MaxCount: scheduler.batchSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, scheduled := range due {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+
+		_, err := scheduler.store.ExecuteScheduledTransferTx(ctx, scheduled.ID)
+		switch {
+		case err == nil, errors.Is(err, db.ErrScheduledTransferNotPending):
+		case permanentFailure(err):
+			_, err = scheduler.store.FailScheduledTransfer(ctx, db.FailScheduledTransferParams{
+				ID:            scheduled.ID,
+				FailureReason: failureReason(err),
+			})
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				log.Printf("cannot mark scheduled transfer %d failed: %v", scheduled.ID, err)
+			}
+		default:
+			log.Printf("scheduled transfer %d will be retried: %v", scheduled.ID, err)
+		}
+	}
+
+	return len(due), nil
+}
+
+// ExpireOwnershipRequests marks the pending ownership transfer requests past their expiry as expired
+// and returns how many there were
+func (scheduler *Scheduler) ExpireOwnershipRequests(ctx context.Context) (int64, error) {
+	return scheduler.store.ExpireOwnershipTransferRequests(ctx, scheduler.now())
+}
+
+// permanentFailure reports whether retrying the transfer later cannot help.
+// These are the rejections of the transfer itself; any other error may be transient.
+func permanentFailure(err error) bool {
+	return errors.Is(err, db.ErrInsufficientFunds) ||
+		errors.Is(err, db.ErrConstraintViolation) ||
+		errors.Is(err, db.ErrDestinationNotWhitelisted) ||
+		errors.Is(err, db.ErrTransferLimitExceeded) ||
+		errors.Is(err, db.ErrBelowMinimumBalance) ||
+		errors.Is(err, db.ErrMinBalanceViolation) ||
+		errors.Is(err, db.ErrCurrencyMismatch) ||
+		errors.Is(err, db.ErrAccountFrozen) ||
+		errors.Is(err, sql.ErrNoRows)
+}
+
+func failureReason(err error) string {
+	if errors.Is(err, sql.ErrNoRows) {
+		return "account not found"
+	}
+	return err.Error()
+}
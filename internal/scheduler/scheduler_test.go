@@ -0,0 +1,209 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khuongkd/simplebank/db/mock"
+	db "github.com/khuongkd/simplebank/db/sqlc"
+	"github.com/khuongkd/simplebank/internal/memdb"
+	"github.com/khuongkd/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createTestAccount(t *testing.T, store db.Store, balance int64) db.Account {
+	account, err := store.CreateAcount(context.Background(), db.CreateAcountParams{
+		Owner:    util.RandomOwner(),
+		Balance:  balance,
+		Currency: "USD",
+	})
+	require.NoError(t, err)
+	return account
+}
+
+func scheduleTestTransfer(t *testing.T, store db.Store, from, to db.Account, amount int64, runAt time.Time) db.ScheduledTransfer {
+	scheduled, err := store.CreateScheduledTransfer(context.Background(), db.CreateScheduledTransferParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        amount,
+		Currency:      from.Currency,
+		RunAt:         runAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, db.ScheduledTransferPending, scheduled.Status)
+	return scheduled
+}
+
+func TestRunDue(t *testing.T) {
+	store := memdb.NewInMemoryStore()
+	account1 := createTestAccount(t, store, 100)
+	account2 := createTestAccount(t, store, 0)
+
+	now := time.Now()
+	due := scheduleTestTransfer(t, store, account1, account2, 30, now.Add(-time.Minute))
+	future := scheduleTestTransfer(t, store, account1, account2, 30, now.Add(time.Hour))
+	canceled := scheduleTestTransfer(t, store, account1, account2, 30, now.Add(-time.Minute))
+	_, err := store.CancelScheduledTransfer(context.Background(), canceled.ID)
+	require.NoError(t, err)
+
+	scheduler := New(store, time.Minute)
+	scheduler.now = func() time.Time { return now }
+
+	n, err := scheduler.RunDue(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	done, err := store.GetScheduledTransfer(context.Background(), due.ID)
+	require.NoError(t, err)
+	require.Equal(t, db.ScheduledTransferDone, done.Status)
+	require.True(t, done.TransferID.Valid)
+
+	transfer, err := store.GetTransfer(context.Background(), done.TransferID.Int64)
+	require.NoError(t, err)
+	require.Equal(t, int64(30), transfer.Amount)
+
+	updatedAccount1, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(70), updatedAccount1.Balance)
+
+	pending, err := store.GetScheduledTransfer(context.Background(), future.ID)
+	require.NoError(t, err)
+	require.Equal(t, db.ScheduledTransferPending, pending.Status)
+
+	// a second run finds nothing left to do
+	n, err = scheduler.RunDue(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, n)
+}
+
+func TestRunDueInsufficientFunds(t *testing.T) {
+	store := memdb.NewInMemoryStore()
+	account1 := createTestAccount(t, store, 10)
+	account2 := createTestAccount(t, store, 0)
+	scheduled := scheduleTestTransfer(t, store, account1, account2, 30, time.Now().Add(-time.Minute))
+
+	n, err := New(store, time.Minute).RunDue(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	failed, err := store.GetScheduledTransfer(context.Background(), scheduled.ID)
+	require.NoError(t, err)
+	require.Equal(t, db.ScheduledTransferFailed, failed.Status)
+	require.Equal(t, db.ErrInsufficientFunds.Error(), failed.FailureReason)
+	require.False(t, failed.TransferID.Valid)
+
+	updatedAccount1, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updatedAccount1.Balance)
+}
+
+func TestRunDuePermanentFailure(t *testing.T) {
+	testCases := []struct {
+		name   string
+		err    error
+		reason string
+	}{
+		{name: "InsufficientFunds", err: db.ErrInsufficientFunds},
+		{name: "ConstraintViolation", err: fmt.Errorf("%w: %s", db.ErrConstraintViolation, db.ConstraintBalanceNonNegative)},
+		{name: "DestinationNotWhitelisted", err: db.ErrDestinationNotWhitelisted},
+		{name: "TransferLimitExceeded", err: fmt.Errorf("%w: 600 is above the maximum of 500", db.ErrTransferLimitExceeded)},
+		{name: "BelowMinimumBalance", err: fmt.Errorf("%w: 50 is below the minimum of 100", db.ErrBelowMinimumBalance)},
+		{name: "MinBalanceViolation", err: fmt.Errorf("%w: 50 is below the minimum of 100", db.ErrMinBalanceViolation)},
+		{name: "CurrencyMismatch", err: db.ErrCurrencyMismatch},
+		{name: "AccountFrozen", err: fmt.Errorf("%w: account [2]", db.ErrAccountFrozen)},
+		{name: "AccountNotFound", err: sql.ErrNoRows, reason: "account not found"},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			scheduled := db.ScheduledTransfer{ID: 1, Status: db.ScheduledTransferPending}
+			reason := tc.reason
+			if reason == "" {
+				reason = tc.err.Error()
+			}
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().ListDueScheduledTransfers(gomock.Any(), gomock.Any()).Times(1).Return([]db.ScheduledTransfer{scheduled}, nil)
+			store.EXPECT().ExecuteScheduledTransferTx(gomock.Any(), gomock.Eq(scheduled.ID)).Times(1).Return(scheduled, tc.err)
+			arg := db.FailScheduledTransferParams{ID: scheduled.ID, FailureReason: reason}
+			store.EXPECT().FailScheduledTransfer(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.ScheduledTransfer{}, nil)
+
+			n, err := New(store, time.Minute).RunDue(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, 1, n)
+		})
+	}
+}
+
+func TestRunDueTransientError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	scheduled := db.ScheduledTransfer{ID: 1, Status: db.ScheduledTransferPending}
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ListDueScheduledTransfers(gomock.Any(), gomock.Any()).Times(1).Return([]db.ScheduledTransfer{scheduled}, nil)
+	store.EXPECT().ExecuteScheduledTransferTx(gomock.Any(), gomock.Eq(scheduled.ID)).Times(1).Return(scheduled, sql.ErrConnDone)
+	store.EXPECT().FailScheduledTransfer(gomock.Any(), gomock.Any()).Times(0)
+
+	n, err := New(store, time.Minute).RunDue(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+}
+
+func TestRunStopsWithContext(t *testing.T) {
+	store := memdb.NewInMemoryStore()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		New(store, time.Millisecond).Run(ctx)
+		close(done)
+	}()
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("scheduler did not stop after the context was canceled")
+	}
+}
+
+func TestExpireOwnershipRequests(t *testing.T) {
+	store := memdb.NewInMemoryStore()
+	account := createTestAccount(t, store, 0)
+
+	now := time.Now()
+	request, err := store.CreateOwnershipTransferRequest(context.Background(), db.CreateOwnershipTransferRequestParams{
+		AccountID:    account.ID,
+		CurrentOwner: account.Owner,
+		NewOwner:     util.RandomOwner(),
+		ExpiresAt:    now.Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	scheduler := New(store, time.Minute)
+	scheduler.now = func() time.Time { return now }
+
+	n, err := scheduler.ExpireOwnershipRequests(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	scheduler.now = func() time.Time { return now.Add(time.Hour) }
+	n, err = scheduler.ExpireOwnershipRequests(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+
+	expired, err := store.GetOwnershipTransferRequest(context.Background(), request.ID)
+	require.NoError(t, err)
+	require.Equal(t, db.OwnershipRequestExpired, expired.Status)
+}
@@ -0,0 +1,70 @@
+// Package tracing exports OpenTelemetry spans for the requests the service handles and the store operations they run.
+package tracing
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ServiceName is the name the spans of the service are exported under
+const ServiceName = "simplebank"
+
+// InstrumentationName names the tracers of the service
+const InstrumentationName = "github.com/khuongkd/simplebank"
+
+// NewProvider returns a provider that exports spans in batches to the OTLP/HTTP collector at endpoint.
+// It must be shut down to flush the spans it still holds.
+func NewProvider(ctx context.Context, endpoint *url.URL) (*sdktrace.TracerProvider, error) {
+	options := []otlptracehttp.Option{otlptracehttp.WithEndpoint(endpoint.Host)}
+	if endpoint.Scheme == "http" {
+		options = append(options, otlptracehttp.WithInsecure())
+	}
+	if endpoint.Path != "" && endpoint.Path != "/" {
+		options = append(options, otlptracehttp.WithURLPath(endpoint.Path))
+	}
+	exporter, err := otlptracehttp.New(ctx, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String(ServiceName))),
+	), nil
+}
+
+// Observer starts a span around every query and transaction of the store.
+// The queries a transaction runs are children of its span, and the transaction is a child of the span of the request.
+type Observer struct {
+	tracer trace.Tracer
+}
+
+// NewObserver returns an observer whose spans are started by provider
+func NewObserver(provider trace.TracerProvider) *Observer {
+	return &Observer{tracer: provider.Tracer(InstrumentationName)}
+}
+
+func (observer *Observer) BeforeQuery(ctx context.Context, method string) context.Context {
+	ctx, _ = observer.tracer.Start(ctx, method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBOperationKey.String(method)),
+	)
+	return ctx
+}
+
+func (observer *Observer) AfterQuery(ctx context.Context, method string, duration time.Duration, err error) {
+	span := trace.SpanFromContext(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,47 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestObserver(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	observer := NewObserver(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+
+	txCtx := observer.BeforeQuery(context.Background(), "TransferTx")
+	queryCtx := observer.BeforeQuery(txCtx, "GetAccountForUpdate")
+	observer.AfterQuery(queryCtx, "GetAccountForUpdate", 0, nil)
+	observer.AfterQuery(txCtx, "TransferTx", 0, errors.New("failed"))
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	query, tx := spans[0], spans[1]
+
+	require.Equal(t, "GetAccountForUpdate", query.Name)
+	require.Equal(t, tx.SpanContext.SpanID(), query.Parent.SpanID())
+	require.Equal(t, codes.Unset, query.Status.Code)
+
+	require.Equal(t, "TransferTx", tx.Name)
+	require.False(t, tx.Parent.IsValid())
+	require.Equal(t, codes.Error, tx.Status.Code)
+	require.Equal(t, "failed", tx.Status.Description)
+	require.Len(t, tx.Events, 1)
+}
+
+func TestNewProvider(t *testing.T) {
+	endpoint, err := url.Parse("http://localhost:4318")
+	require.NoError(t, err)
+
+	// the exporter only connects to the collector when it has spans to send
+	provider, err := NewProvider(context.Background(), endpoint)
+	require.NoError(t, err)
+	require.NoError(t, provider.Shutdown(context.Background()))
+}
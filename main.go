@@ -1,15 +1,28 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"log"
 
 	"github.com/khuongkd/simplebank/api"
 	db "github.com/khuongkd/simplebank/db/sqlc"
+	"github.com/khuongkd/simplebank/internal/accountcache"
+	"github.com/khuongkd/simplebank/internal/archiver"
+	"github.com/khuongkd/simplebank/internal/listcap"
+	"github.com/khuongkd/simplebank/internal/queryobserver"
+	"github.com/khuongkd/simplebank/internal/scheduler"
+	"github.com/khuongkd/simplebank/internal/tracing"
 	"github.com/khuongkd/simplebank/util"
 	_ "github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
 )
 
+// @title        Simple Bank API
+// @version      1.0
+// @description  HTTP API of the simple bank service.
+// @BasePath     /
 func main() {
 	config, err := util.LoadConfig(".")
 	if err != nil {
@@ -20,8 +33,73 @@ func main() {
 		log.Fatal("Cannot connect to db:", err)
 	}
 
-	store := db.NewStore(conn)
-	server := api.NewServer(store)
+	dialect, err := db.DialectFor(config.DBDriver)
+	if err != nil {
+		log.Fatal("cannot use db driver:", err)
+	}
+	var observers []db.QueryObserver
+	if config.QueryMetrics {
+		metrics, err := queryobserver.NewPrometheus(prometheus.DefaultRegisterer)
+		if err != nil {
+			log.Fatal("cannot register query metrics:", err)
+		}
+		observers = append(observers, metrics)
+	}
+	if config.QueryTracing {
+		observers = append(observers, queryobserver.NewTracer())
+	}
+	tracingEndpoint, err := config.TracingEndpointURL()
+	if err != nil {
+		log.Fatal("cannot use tracing endpoint:", err)
+	}
+	if tracingEndpoint != nil {
+		provider, err := tracing.NewProvider(context.Background(), tracingEndpoint)
+		if err != nil {
+			log.Fatal("cannot export traces:", err)
+		}
+		defer provider.Shutdown(context.Background())
+		otel.SetTracerProvider(provider)
+		observers = append(observers, tracing.NewObserver(provider))
+	}
+	configWatcher := util.NewConfigWatcher(".", config)
+	go func() {
+		if err := configWatcher.Watch(context.Background()); err != nil {
+			log.Println("config hot reload disabled:", err)
+		}
+	}()
+
+	sqlStore := db.NewStoreWithDialect(conn, dialect, observers...)
+	sqlStore.UseAccountPolicies(func() util.AccountPolicies {
+		return configWatcher.Config().AccountPolicies()
+	})
+	sqlStore.UseTransferFees(func(amount int64, currency string) (int64, int64, error) {
+		return configWatcher.Config().TransferFee(amount, currency)
+	})
+	var store db.Store = sqlStore
+	if config.SkipSelfCheck {
+		log.Println("startup self-check skipped")
+	} else if err := util.SelfCheck(config, store); err != nil {
+		log.Fatal("startup self-check failed: ", err)
+	}
+
+	if config.AccountCacheSize > 0 && config.AccountCacheTTL > 0 {
+		store = accountcache.New(store, config.AccountCacheSize, config.AccountCacheTTL)
+	}
+	if config.MaxListAccounts > 0 {
+		store = listcap.New(store, config.MaxListAccounts)
+	}
+
+	server := api.NewServer(config, store)
+	server.UseConfigWatcher(configWatcher)
+
+	if config.SchedulerInterval > 0 {
+		go scheduler.New(store, config.SchedulerInterval).Run(context.Background())
+	}
+
+	if config.EntryRetention > 0 && config.ArchiveInterval > 0 {
+		go archiver.New(store, config.ArchiveInterval, config.EntryRetention, config.ArchiveBatchSize).Run(context.Background())
+	}
+
 	err = server.Start(config.ServerAddress)
 	if err != nil {
 		log.Fatal("cannot start server", err)
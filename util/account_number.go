@@ -0,0 +1,45 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// accountNumberDigits is the width the account number is zero-padded to
+const accountNumberDigits = 10
+
+var ErrInvalidAccountNumber = errors.New("invalid account number")
+
+// FormatAccountNumber derives the public account number from the bank prefix and the number stored on the account
+func FormatAccountNumber(prefix string, number int64) string {
+	return fmt.Sprintf("%s%0*d", prefix, accountNumberDigits, number)
+}
+
+// maskedAccountNumberDigits is how many trailing digits MaskAccountNumber leaves visible
+const maskedAccountNumberDigits = 4
+
+// MaskAccountNumber hides all but the last digits of a formatted account number, keeping its length
+func MaskAccountNumber(number string) string {
+	if len(number) <= maskedAccountNumberDigits {
+		return number
+	}
+	return strings.Repeat("*", len(number)-maskedAccountNumberDigits) + number[len(number)-maskedAccountNumberDigits:]
+}
+
+// ParseAccountNumber reverses FormatAccountNumber and returns the number stored on the account.
+// Only the canonical form is accepted, so that each account has a single number:
+// one with extra leading zeros or a sign is rejected even though it reads as the same value.
+func ParseAccountNumber(prefix string, number string) (int64, error) {
+	if !strings.HasPrefix(number, prefix) {
+		return 0, ErrInvalidAccountNumber
+	}
+
+	n, err := strconv.ParseInt(number[len(prefix):], 10, 64)
+	if err != nil || n < 1 || FormatAccountNumber(prefix, n) != number {
+		return 0, ErrInvalidAccountNumber
+	}
+
+	return n, nil
+}
@@ -0,0 +1,47 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatAccountNumber(t *testing.T) {
+	require.Equal(t, "SB0000000042", FormatAccountNumber("SB", 42))
+	require.Equal(t, "0000000042", FormatAccountNumber("", 42))
+	require.Equal(t, FormatAccountNumber("SB", 42), FormatAccountNumber("SB", 42))
+}
+
+func TestMaskAccountNumber(t *testing.T) {
+	require.Equal(t, "********0042", MaskAccountNumber("SB0000000042"))
+	require.Equal(t, "******1234", MaskAccountNumber(FormatAccountNumber("", 1234)))
+	require.Equal(t, "42", MaskAccountNumber("42"))
+}
+
+func TestParseAccountNumber(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		id := RandomInt(1, 1<<40)
+		number := FormatAccountNumber("SB", id)
+
+		parsedID, err := ParseAccountNumber("SB", number)
+		require.NoError(t, err)
+		require.Equal(t, id, parsedID)
+	}
+
+	invalidNumbers := []string{
+		"",
+		"SB",
+		"SB42",
+		"XX0000000042",
+		"SB00000000-2",
+		"SB00000abc42",
+		"SB0000000000",
+		"SB00000000042",
+		"SB+000000042",
+		"sb0000000042",
+	}
+	for _, number := range invalidNumbers {
+		_, err := ParseAccountNumber("SB", number)
+		require.ErrorIs(t, err, ErrInvalidAccountNumber, number)
+	}
+}
@@ -0,0 +1,19 @@
+package util
+
+// AccountPolicy holds the rules applied to transfers out of accounts of one type
+type AccountPolicy struct {
+	// MaxTransferAmount caps a single transfer; zero means no cap
+	MaxTransferAmount int64
+	// MinBalance is the balance a transfer must leave on the account
+	MinBalance int64
+	// AllowOverdraft lets transfers take the balance below MinBalance, though never below zero
+	AllowOverdraft bool
+}
+
+// AccountPolicies maps account types to the policy applied to them
+type AccountPolicies map[string]AccountPolicy
+
+// For returns the policy of the account type. Types without a policy are not restricted.
+func (policies AccountPolicies) For(accountType string) AccountPolicy {
+	return policies[accountType]
+}
@@ -1,25 +1,501 @@
 package util
 
-import "github.com/spf13/viper"
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/viper"
+)
 
 type Config struct {
-	DBDriver      string `mapstructure:"DB_DRIVER"`
-	DBSource      string `mapstructure:"DB_SOURCE"`
-	ServerAddress string `mapstructure:"SERVER_ADDRESS"`
+	// DBDriver is the database/sql driver; postgres is the only one supported
+	DBDriver              string        `mapstructure:"DB_DRIVER"`
+	DBSource              string        `mapstructure:"DB_SOURCE"`
+	DBPasswordFile        string        `mapstructure:"DB_PASSWORD_FILE"`
+	ServerAddress         string        `mapstructure:"SERVER_ADDRESS"`
+	GzipMinSize           int           `mapstructure:"GZIP_MIN_SIZE"`
+	AccountNumberPrefix   string        `mapstructure:"ACCOUNT_NUMBER_PREFIX"`
+	MaxTransferAmount     int64         `mapstructure:"MAX_TRANSFER_AMOUNT"`
+	EnableSwagger         bool          `mapstructure:"ENABLE_SWAGGER"`
+	TransferLockTimeout   time.Duration `mapstructure:"TRANSFER_LOCK_TIMEOUT"`
+	SupportedCurrencies   []string      `mapstructure:"SUPPORTED_CURRENCIES"`
+	AccountCreationLimit  int           `mapstructure:"ACCOUNT_CREATION_LIMIT"`
+	AccountCreationWindow time.Duration `mapstructure:"ACCOUNT_CREATION_WINDOW"`
+	SchedulerInterval     time.Duration `mapstructure:"SCHEDULER_INTERVAL"`
+	DefaultPageSize       int32         `mapstructure:"DEFAULT_PAGE_SIZE"`
+	EntryRetention        time.Duration `mapstructure:"ENTRY_RETENTION"`
+	ArchiveInterval       time.Duration `mapstructure:"ARCHIVE_INTERVAL"`
+	ArchiveBatchSize      int32         `mapstructure:"ARCHIVE_BATCH_SIZE"`
+	OwnershipRequestTTL   time.Duration `mapstructure:"OWNERSHIP_REQUEST_TTL"`
+	// RequestTimeout bounds every request; RouteTimeouts overrides it for the listed route paths
+	RequestTimeout time.Duration            `mapstructure:"REQUEST_TIMEOUT"`
+	RouteTimeouts  map[string]time.Duration `mapstructure:"ROUTE_TIMEOUTS"`
+	// TransferFeeFlat and TransferFeeBasisPoints make up the fee charged on a transfer,
+	// which is credited to the account TransferFeeAccounts lists for the transfer currency
+	TransferFeeFlat        int64            `mapstructure:"TRANSFER_FEE_FLAT"`
+	TransferFeeBasisPoints int64            `mapstructure:"TRANSFER_FEE_BASIS_POINTS"`
+	TransferFeeAccounts    map[string]int64 `mapstructure:"TRANSFER_FEE_ACCOUNTS"`
+	// AccountNumberPerCurrency makes account numbers unique within each currency instead of across all accounts
+	AccountNumberPerCurrency bool `mapstructure:"ACCOUNT_NUMBER_PER_CURRENCY"`
+	// AccountTypeMaxTransfer, AccountTypeMinBalance and AccountTypeOverdraft make up the policy of each account type
+	AccountTypeMaxTransfer map[string]int64 `mapstructure:"ACCOUNT_TYPE_MAX_TRANSFER"`
+	AccountTypeMinBalance  map[string]int64 `mapstructure:"ACCOUNT_TYPE_MIN_BALANCE"`
+	AccountTypeOverdraft   map[string]bool  `mapstructure:"ACCOUNT_TYPE_OVERDRAFT"`
+	// FXRoundingMode rounds converted amounts; empty means half_up
+	FXRoundingMode RoundingMode `mapstructure:"FX_ROUNDING_MODE"`
+	// DuplicateTransferWindow is how far back a transfer with the same accounts and amount makes a new one a likely duplicate;
+	// zero turns the check off. BlockDuplicateTransfers rejects likely duplicates instead of only flagging them
+	DuplicateTransferWindow time.Duration `mapstructure:"DUPLICATE_TRANSFER_WINDOW"`
+	BlockDuplicateTransfers bool          `mapstructure:"BLOCK_DUPLICATE_TRANSFERS"`
+	// SkipSelfCheck starts the server without checking the database and config first, see SelfCheck
+	SkipSelfCheck bool `mapstructure:"SKIP_SELF_CHECK"`
+	// LogRequestBodies logs request bodies with passwords and tokens masked, up to LogRequestBodyLimit bytes
+	LogRequestBodies    bool `mapstructure:"LOG_REQUEST_BODIES"`
+	LogRequestBodyLimit int  `mapstructure:"LOG_REQUEST_BODY_LIMIT"`
+	// MaintenanceMode starts the server rejecting writes; it is turned on and off at runtime through /admin/maintenance
+	MaintenanceMode bool `mapstructure:"MAINTENANCE_MODE"`
+	// TransferVelocityLimit is the most transfers an account may send within TransferVelocityWindow; zero turns the check off.
+	// Transfers beyond it are rejected when BlockHighVelocity is set and flagged for review in the event log otherwise
+	TransferVelocityLimit  int64         `mapstructure:"TRANSFER_VELOCITY_LIMIT"`
+	TransferVelocityWindow time.Duration `mapstructure:"TRANSFER_VELOCITY_WINDOW"`
+	BlockHighVelocity      bool          `mapstructure:"BLOCK_HIGH_VELOCITY"`
+	// AccountCacheSize is how many accounts are kept in memory for AccountCacheTTL after being read; zero turns the cache off
+	AccountCacheSize int           `mapstructure:"ACCOUNT_CACHE_SIZE"`
+	AccountCacheTTL  time.Duration `mapstructure:"ACCOUNT_CACHE_TTL"`
+	// FXAllowedPairs lists the currency conversions allowed, as FROM/TO; empty allows every pair
+	FXAllowedPairs []string `mapstructure:"FX_ALLOWED_PAIRS"`
+	// ReceiptSigningKey signs transfer receipts so they can be proven to come from the bank; empty leaves them unsigned
+	ReceiptSigningKey string `mapstructure:"RECEIPT_SIGNING_KEY"`
+	// MaxListAccounts is the most accounts a single ListAccounts call returns, whatever limit is asked for; zero turns the cap off
+	MaxListAccounts int32 `mapstructure:"MAX_LIST_ACCOUNTS"`
+	// MaxInFlightRequests sheds requests with a 503 once that many are being served; zero turns the limit off.
+	// LatencyBudget sheds them while the p99 latency over the last LatencyWindow exceeds it; zero turns it off
+	MaxInFlightRequests int64         `mapstructure:"MAX_IN_FLIGHT_REQUESTS"`
+	LatencyBudget       time.Duration `mapstructure:"LATENCY_BUDGET"`
+	LatencyWindow       time.Duration `mapstructure:"LATENCY_WINDOW"`
+	// RateLimitRetryAfter is how long rate limited clients are asked to wait, in whole seconds and never less than one.
+	// Each response adds a random amount of up to RateLimitRetryJitter either way, to spread their retries
+	RateLimitRetryAfter  time.Duration `mapstructure:"RATE_LIMIT_RETRY_AFTER"`
+	RateLimitRetryJitter time.Duration `mapstructure:"RATE_LIMIT_RETRY_JITTER"`
+	// RequestIDHeaders lists the headers a request ID is read from, the first one set winning;
+	// the ID is echoed back under the first header of the list. Empty uses X-Request-ID
+	RequestIDHeaders []string `mapstructure:"REQUEST_ID_HEADERS"`
+	// MaxStatementEntries is the most entries a statement holds; a longer one must be narrowed or paged through.
+	// Zero turns the cap off
+	MaxStatementEntries int32 `mapstructure:"MAX_STATEMENT_ENTRIES"`
+	// ResponseFormat is the shape of list responses for clients that do not ask for one with ?format=,
+	// bare or envelope; empty means bare
+	ResponseFormat ResponseFormat `mapstructure:"RESPONSE_FORMAT"`
+	// TransferFeeFlatByCurrency and TransferFeeBasisPointsByCurrency set the fee schedule of single currencies,
+	// such as USD=25; a currency that is not listed in one of them takes TransferFeeFlat or TransferFeeBasisPoints
+	TransferFeeFlatByCurrency        map[string]int64 `mapstructure:"TRANSFER_FEE_FLAT_BY_CURRENCY"`
+	TransferFeeBasisPointsByCurrency map[string]int64 `mapstructure:"TRANSFER_FEE_BASIS_POINTS_BY_CURRENCY"`
+	// FreezeReasons lists the reasons a banker may give for freezing an account; empty allows DefaultFreezeReasons
+	FreezeReasons []string `mapstructure:"FREEZE_REASONS"`
+	// OwnerMinLength, OwnerMaxLength and OwnerAllowedSymbols are the rules the owner of a new account must satisfy;
+	// letters and digits are always allowed and a zero OwnerMaxLength puts no cap on the length
+	OwnerMinLength      int    `mapstructure:"OWNER_MIN_LENGTH"`
+	OwnerMaxLength      int    `mapstructure:"OWNER_MAX_LENGTH"`
+	OwnerAllowedSymbols string `mapstructure:"OWNER_ALLOWED_SYMBOLS"`
+	// QueryMetrics records the duration of every store query and transaction and serves them at /metrics
+	QueryMetrics bool `mapstructure:"QUERY_METRICS"`
+	// QueryTracing logs a span for every store query and transaction
+	QueryTracing bool `mapstructure:"QUERY_TRACING"`
+	// TracingEndpoint is the URL of the OTLP/HTTP collector that OpenTelemetry spans are exported to,
+	// such as http://localhost:4318; empty disables distributed tracing
+	TracingEndpoint string `mapstructure:"TRACING_ENDPOINT"`
+	// BalanceUpdateOrder is the order transfers update the balances of their accounts in, id or amount;
+	// empty means id, the only one that cannot deadlock
+	BalanceUpdateOrder BalanceUpdateOrder `mapstructure:"BALANCE_UPDATE_ORDER"`
+	// MoneyAsString writes amounts of money in JSON responses as strings, so that JavaScript clients don't lose precision
+	// above 2^53. Clients can override it with a money=string or money=number parameter of the Accept header.
+	MoneyAsString bool `mapstructure:"MONEY_AS_STRING"`
+	// TransferConfirmationThreshold is the amount above which a transfer waits for the client to confirm it; 0 disables confirmations
+	TransferConfirmationThreshold int64 `mapstructure:"TRANSFER_CONFIRMATION_THRESHOLD"`
+	// TransferConfirmationTTL is how long a transfer can be confirmed for once requested
+	TransferConfirmationTTL time.Duration `mapstructure:"TRANSFER_CONFIRMATION_TTL"`
 }
 
+const (
+	configName = "app"
+	configType = "env"
+)
+
+// LoadConfig reads app.env from path, letting environment variables override its values.
+// When DB_PASSWORD_FILE is set, the password it holds is put into DBSource.
 func LoadConfig(path string) (config Config, err error) {
-	viper.AddConfigPath(path)
-	viper.SetConfigName("app")
-	viper.SetConfigType("env")
+	v := viper.New()
+	v.AddConfigPath(path)
+	v.SetConfigName(configName)
+	v.SetConfigType(configType)
 
-	viper.AutomaticEnv()
+	v.AutomaticEnv()
+
+	err = v.ReadInConfig()
+	if err != nil {
+		return
+	}
 
-	err = viper.ReadInConfig()
+	err = v.Unmarshal(&config, viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+		stringToMapHook,
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.StringToSliceHookFunc(","),
+	)))
 	if err != nil {
 		return
 	}
 
-	err = viper.Unmarshal(&config)
+	// the queries are Postgres SQL; MySQL is not supported
+	if config.DBDriver != "" && config.DBDriver != "postgres" {
+		err = fmt.Errorf("DB_DRIVER %q is not supported, only postgres is", config.DBDriver)
+		return
+	}
+
+	if config.FXRoundingMode != "" && !config.FXRoundingMode.Valid() {
+		err = fmt.Errorf("FX_ROUNDING_MODE %q must be one of half_up, half_even, floor or ceil", config.FXRoundingMode)
+		return
+	}
+
+	if _, err = ParseCurrencyPairs(config.FXAllowedPairs); err != nil {
+		err = fmt.Errorf("FX_ALLOWED_PAIRS: %w", err)
+		return
+	}
+
+	for _, reason := range config.FreezeReasons {
+		if reason == "" || len(reason) > MaxFreezeReasonLength {
+			err = fmt.Errorf("FREEZE_REASONS %q must be between 1 and %d characters", reason, MaxFreezeReasonLength)
+			return
+		}
+	}
+
+	if config.OwnerMinLength < 0 || config.OwnerMaxLength < 0 ||
+		(config.OwnerMaxLength > 0 && config.OwnerMaxLength < config.OwnerMinLength) {
+		err = fmt.Errorf("OWNER_MIN_LENGTH %d and OWNER_MAX_LENGTH %d must not be negative, and the maximum not below the minimum",
+			config.OwnerMinLength, config.OwnerMaxLength)
+		return
+	}
+
+	if _, err = config.TracingEndpointURL(); err != nil {
+		err = fmt.Errorf("TRACING_ENDPOINT %q: %w", config.TracingEndpoint, err)
+		return
+	}
+
+	if config.ResponseFormat != "" && !config.ResponseFormat.Valid() {
+		err = fmt.Errorf("RESPONSE_FORMAT %q must be bare or envelope", config.ResponseFormat)
+		return
+	}
+
+	if config.BalanceUpdateOrder != "" && !config.BalanceUpdateOrder.Valid() {
+		err = fmt.Errorf("BALANCE_UPDATE_ORDER %q must be id or amount", config.BalanceUpdateOrder)
+		return
+	}
+
+	if config.TransferConfirmationThreshold < 0 || config.TransferConfirmationTTL < 0 {
+		err = fmt.Errorf("TRANSFER_CONFIRMATION_THRESHOLD %d and TRANSFER_CONFIRMATION_TTL %s cannot be negative",
+			config.TransferConfirmationThreshold, config.TransferConfirmationTTL)
+		return
+	}
+
+	if config.RateLimitRetryAfter < 0 || config.RateLimitRetryJitter < 0 {
+		err = fmt.Errorf("RATE_LIMIT_RETRY_AFTER %s and RATE_LIMIT_RETRY_JITTER %s cannot be negative", config.RateLimitRetryAfter, config.RateLimitRetryJitter)
+		return
+	}
+
+	if config.DBPasswordFile != "" {
+		config.DBSource, err = withPasswordFromFile(config.DBSource, config.DBPasswordFile)
+	}
 	return
 }
+
+// withPasswordFromFile returns the connection URL with its password replaced by the content of the file,
+// so the password can come from a mounted secret instead of the environment
+func withPasswordFromFile(source, passwordFile string) (string, error) {
+	data, err := os.ReadFile(passwordFile)
+	if err != nil {
+		return "", fmt.Errorf("cannot read database password file: %w", err)
+	}
+
+	password := strings.TrimRight(string(data), "\r\n")
+	if password == "" {
+		return "", fmt.Errorf("database password file %s is empty", passwordFile)
+	}
+
+	dsn, err := url.Parse(source)
+	if err != nil {
+		return "", fmt.Errorf("cannot parse database source: %w", err)
+	}
+	if dsn.User == nil {
+		return "", errors.New("database source has no user to set the password for")
+	}
+
+	dsn.User = url.UserPassword(dsn.User.Username(), password)
+	return dsn.String(), nil
+}
+
+// stringToMapHook splits a comma separated list of key=value pairs, such as
+// "/accounts/:id/statement.pdf=30s,/transfers=5s", into a map keyed by strings.
+// The values are decoded into the element type of the map by the hooks that follow.
+func stringToMapHook(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+	if from.Kind() != reflect.String || to.Kind() != reflect.Map || to.Key().Kind() != reflect.String {
+		return data, nil
+	}
+
+	pairs := make(map[string]string)
+	for _, pair := range strings.Split(data.(string), ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		sep := strings.LastIndex(pair, "=")
+		if sep <= 0 {
+			return nil, fmt.Errorf("%q must look like key=value", pair)
+		}
+		pairs[strings.TrimSpace(pair[:sep])] = pair[sep+1:]
+	}
+	return pairs, nil
+}
+
+// TimeoutFor returns the timeout of the route path, falling back to RequestTimeout
+func (config Config) TimeoutFor(path string) time.Duration {
+	if timeout, ok := config.RouteTimeouts[path]; ok {
+		return timeout
+	}
+	return config.RequestTimeout
+}
+
+// withReloaded returns config with the fields that are safe to change at runtime taken from next.
+// Everything else, such as the database source or the server address, keeps its startup value.
+func (config Config) withReloaded(next Config) Config {
+	config.MaxTransferAmount = next.MaxTransferAmount
+	config.TransferLockTimeout = next.TransferLockTimeout
+	config.SupportedCurrencies = next.SupportedCurrencies
+	config.AccountCreationLimit = next.AccountCreationLimit
+	config.AccountCreationWindow = next.AccountCreationWindow
+	config.DefaultPageSize = next.DefaultPageSize
+	config.OwnershipRequestTTL = next.OwnershipRequestTTL
+	config.RequestTimeout = next.RequestTimeout
+	config.RouteTimeouts = next.RouteTimeouts
+	config.TransferFeeFlat = next.TransferFeeFlat
+	config.TransferFeeBasisPoints = next.TransferFeeBasisPoints
+	config.TransferFeeAccounts = next.TransferFeeAccounts
+	config.AccountTypeMaxTransfer = next.AccountTypeMaxTransfer
+	config.AccountTypeMinBalance = next.AccountTypeMinBalance
+	config.AccountTypeOverdraft = next.AccountTypeOverdraft
+	config.FXRoundingMode = next.FXRoundingMode
+	config.DuplicateTransferWindow = next.DuplicateTransferWindow
+	config.BlockDuplicateTransfers = next.BlockDuplicateTransfers
+	config.LogRequestBodies = next.LogRequestBodies
+	config.LogRequestBodyLimit = next.LogRequestBodyLimit
+	config.TransferVelocityLimit = next.TransferVelocityLimit
+	config.TransferVelocityWindow = next.TransferVelocityWindow
+	config.BlockHighVelocity = next.BlockHighVelocity
+	config.FXAllowedPairs = next.FXAllowedPairs
+	config.MaxInFlightRequests = next.MaxInFlightRequests
+	config.LatencyBudget = next.LatencyBudget
+	config.LatencyWindow = next.LatencyWindow
+	config.RateLimitRetryAfter = next.RateLimitRetryAfter
+	config.RateLimitRetryJitter = next.RateLimitRetryJitter
+	config.MaxStatementEntries = next.MaxStatementEntries
+	config.ResponseFormat = next.ResponseFormat
+	config.TransferFeeFlatByCurrency = next.TransferFeeFlatByCurrency
+	config.TransferFeeBasisPointsByCurrency = next.TransferFeeBasisPointsByCurrency
+	config.FreezeReasons = next.FreezeReasons
+	config.OwnerMinLength = next.OwnerMinLength
+	config.OwnerMaxLength = next.OwnerMaxLength
+	config.OwnerAllowedSymbols = next.OwnerAllowedSymbols
+	config.BalanceUpdateOrder = next.BalanceUpdateOrder
+	config.MoneyAsString = next.MoneyAsString
+	config.TransferConfirmationThreshold = next.TransferConfirmationThreshold
+	config.TransferConfirmationTTL = next.TransferConfirmationTTL
+	return config
+}
+
+// Currencies returns the currencies accounts may hold, or every known currency when none are configured
+func (config Config) Currencies() []string {
+	if len(config.SupportedCurrencies) == 0 {
+		return KnownCurrencies()
+	}
+	return config.SupportedCurrencies
+}
+
+// DefaultRequestIDHeader is the request ID header used when RequestIDHeaders is empty
+const DefaultRequestIDHeader = "X-Request-ID"
+
+// RequestIDHeaderNames returns the headers a request ID is read from, in order of preference
+func (config Config) RequestIDHeaderNames() []string {
+	var names []string
+	for _, name := range config.RequestIDHeaders {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return []string{DefaultRequestIDHeader}
+	}
+	return names
+}
+
+// SupportsCurrency reports whether accounts may hold the currency
+func (config Config) SupportsCurrency(currency string) bool {
+	for _, supported := range config.Currencies() {
+		if supported == currency {
+			return true
+		}
+	}
+	return false
+}
+
+// OwnerPolicy returns the rules the owner of a new account must satisfy
+func (config Config) OwnerPolicy() OwnerPolicy {
+	return OwnerPolicy{
+		MinLength:      config.OwnerMinLength,
+		MaxLength:      config.OwnerMaxLength,
+		AllowedSymbols: config.OwnerAllowedSymbols,
+	}
+}
+
+// TransferFeePolicy returns the fee policy applied to transfers in the currency:
+// its own flat fee and percentage where they are configured, the default ones otherwise
+func (config Config) TransferFeePolicy(currency string) FeePolicy {
+	policy := FeePolicy{
+		Flat:        config.TransferFeeFlat,
+		BasisPoints: config.TransferFeeBasisPoints,
+	}
+	if flat, ok := config.TransferFeeFlatByCurrency[currency]; ok {
+		policy.Flat = flat
+	}
+	if basisPoints, ok := config.TransferFeeBasisPointsByCurrency[currency]; ok {
+		policy.BasisPoints = basisPoints
+	}
+	return policy
+}
+
+// TransferFee returns the fee charged on a transfer of amount in the currency and the account it is credited to.
+// It returns ErrNoFeeAccount when there is a fee but TransferFeeAccounts lists no account for the currency.
+func (config Config) TransferFee(amount int64, currency string) (fee int64, feeAccountID int64, err error) {
+	fee, err = config.TransferFeePolicy(currency).Fee(amount, currency)
+	if err != nil || fee == 0 {
+		return 0, 0, err
+	}
+
+	feeAccountID, ok := config.TransferFeeAccounts[currency]
+	if !ok {
+		return 0, 0, fmt.Errorf("%w: %s", ErrNoFeeAccount, currency)
+	}
+	return fee, feeAccountID, nil
+}
+
+// AccountPolicies returns the policy of every account type that has a rule configured, or nil when none has
+func (config Config) AccountPolicies() AccountPolicies {
+	if len(config.AccountTypeMaxTransfer) == 0 && len(config.AccountTypeMinBalance) == 0 && len(config.AccountTypeOverdraft) == 0 {
+		return nil
+	}
+
+	policies := make(AccountPolicies)
+	for accountType, max := range config.AccountTypeMaxTransfer {
+		policy := policies[accountType]
+		policy.MaxTransferAmount = max
+		policies[accountType] = policy
+	}
+	for accountType, min := range config.AccountTypeMinBalance {
+		policy := policies[accountType]
+		policy.MinBalance = min
+		policies[accountType] = policy
+	}
+	for accountType, allowed := range config.AccountTypeOverdraft {
+		policy := policies[accountType]
+		policy.AllowOverdraft = allowed
+		policies[accountType] = policy
+	}
+	return policies
+}
+
+// CurrencyPairs returns the currency conversions FXAllowedPairs allows, or nil when every pair is allowed.
+// LoadConfig rejects a list that does not parse, so an invalid one only comes from code and allows nothing.
+func (config Config) CurrencyPairs() CurrencyPairs {
+	pairs, err := ParseCurrencyPairs(config.FXAllowedPairs)
+	if err != nil {
+		return CurrencyPairs{}
+	}
+	return pairs
+}
+
+// ResponseFormat is the shape of list responses
+type ResponseFormat string
+
+// Supported response formats
+const (
+	// ResponseFormatBare sends a list as a bare JSON array
+	ResponseFormatBare ResponseFormat = "bare"
+	// ResponseFormatEnvelope wraps a list in an object along with its paging metadata
+	ResponseFormatEnvelope ResponseFormat = "envelope"
+)
+
+// Valid reports whether the response format is one of the supported formats
+func (format ResponseFormat) Valid() bool {
+	return format == ResponseFormatBare || format == ResponseFormatEnvelope
+}
+
+// BalanceUpdateOrder is the order transfers update the balances of their accounts in
+type BalanceUpdateOrder string
+
+// Supported balance update orders
+const (
+	// BalanceUpdateOrderID updates the balances in account ID order
+	BalanceUpdateOrderID BalanceUpdateOrder = "id"
+	// BalanceUpdateOrderAmount updates the balances from the largest debit to the largest credit
+	BalanceUpdateOrderAmount BalanceUpdateOrder = "amount"
+)
+
+// Valid reports whether the balance update order is one of the supported orders
+func (order BalanceUpdateOrder) Valid() bool {
+	return order == BalanceUpdateOrderID || order == BalanceUpdateOrderAmount
+}
+
+// MaxFreezeReasonLength is the longest freeze reason the accounts table holds
+const MaxFreezeReasonLength = 32
+
+// DefaultFreezeReasons returns the reasons an account may be frozen for when FreezeReasons is empty
+func DefaultFreezeReasons() []string {
+	return []string{"fraud", "legal", "kyc", "user_request"}
+}
+
+// AllowedFreezeReasons returns the reasons an account may be frozen for
+func (config Config) AllowedFreezeReasons() []string {
+	if len(config.FreezeReasons) == 0 {
+		return DefaultFreezeReasons()
+	}
+	return config.FreezeReasons
+}
+
+// AllowsFreezeReason reports whether an account may be frozen for reason
+func (config Config) AllowsFreezeReason(reason string) bool {
+	for _, allowed := range config.AllowedFreezeReasons() {
+		if reason == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// TracingEndpointURL returns the URL spans are exported to, or nil when distributed tracing is disabled
+func (config Config) TracingEndpointURL() (*url.URL, error) {
+	if config.TracingEndpoint == "" {
+		return nil, nil
+	}
+	endpoint, err := url.Parse(config.TracingEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	if (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, errors.New("must be an http or https URL with a host")
+	}
+	return endpoint, nil
+}
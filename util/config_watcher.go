@@ -0,0 +1,88 @@
+package util
+
+import (
+	"context"
+	"log"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// configReloadDelay lets an editor finish writing the file before it is read again;
+// changes within the delay are picked up by the same reload
+const configReloadDelay = 100 * time.Millisecond
+
+// ConfigWatcher holds the current config and reloads it when app.env changes on disk.
+// Only the fields listed in Config.withReloaded change after startup.
+type ConfigWatcher struct {
+	path    string
+	current atomic.Value // *Config
+}
+
+// NewConfigWatcher returns a watcher for the app.env in path, starting from the already loaded config
+func NewConfigWatcher(path string, config Config) *ConfigWatcher {
+	watcher := &ConfigWatcher{path: path}
+	watcher.current.Store(&config)
+	return watcher
+}
+
+// Config returns the config currently in effect
+func (watcher *ConfigWatcher) Config() *Config {
+	return watcher.current.Load().(*Config)
+}
+
+// Reload reads the config file again and swaps in its reload-safe fields
+func (watcher *ConfigWatcher) Reload() error {
+	next, err := LoadConfig(watcher.path)
+	if err != nil {
+		return err
+	}
+
+	config := watcher.Config().withReloaded(next)
+	watcher.current.Store(&config)
+	return nil
+}
+
+// Watch reloads the config whenever the file changes until ctx is done.
+// A file that cannot be read is logged and the previous config stays in effect.
+func (watcher *ConfigWatcher) Watch(ctx context.Context) error {
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer fsWatcher.Close()
+
+	// watch the directory rather than the file, since editors often replace the file on save
+	if err := fsWatcher.Add(watcher.path); err != nil {
+		return err
+	}
+	file := filepath.Join(watcher.path, configName+"."+configType)
+
+	var reload <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-fsWatcher.Events:
+			if !ok {
+				return nil
+			}
+			changed := filepath.Clean(event.Name) == filepath.Clean(file) && event.Op&(fsnotify.Write|fsnotify.Create) != 0
+			if changed && reload == nil {
+				reload = time.After(configReloadDelay)
+			}
+		case err, ok := <-fsWatcher.Errors:
+			if !ok {
+				return nil
+			}
+			log.Println("config watcher:", err)
+		case <-reload:
+			reload = nil
+			if err := watcher.Reload(); err != nil {
+				log.Println("cannot reload config:", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package util
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestConfig(t *testing.T, dir, content string) {
+	err := ioutil.WriteFile(filepath.Join(dir, "app.env"), []byte(content), 0644)
+	require.NoError(t, err)
+}
+
+func TestConfigWatcherReloadSafeFieldsOnly(t *testing.T) {
+	dir := t.TempDir()
+	writeTestConfig(t, dir, "DB_SOURCE=postgres://old\nMAX_TRANSFER_AMOUNT=100\nTRANSFER_LOCK_TIMEOUT=1s\n")
+
+	config, err := LoadConfig(dir)
+	require.NoError(t, err)
+	watcher := NewConfigWatcher(dir, config)
+
+	writeTestConfig(t, dir, "DB_SOURCE=postgres://new\nMAX_TRANSFER_AMOUNT=200\nTRANSFER_LOCK_TIMEOUT=3s\n")
+	require.NoError(t, watcher.Reload())
+
+	reloaded := watcher.Config()
+	require.Equal(t, int64(200), reloaded.MaxTransferAmount)
+	require.Equal(t, 3*time.Second, reloaded.TransferLockTimeout)
+	require.Equal(t, "postgres://old", reloaded.DBSource)
+}
+
+func TestConfigWatcherWatch(t *testing.T) {
+	dir := t.TempDir()
+	writeTestConfig(t, dir, "MAX_TRANSFER_AMOUNT=100\n")
+
+	config, err := LoadConfig(dir)
+	require.NoError(t, err)
+	watcher := NewConfigWatcher(dir, config)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- watcher.Watch(ctx)
+	}()
+
+	// the watcher may not be registered yet, so keep rewriting until it notices
+	require.Eventually(t, func() bool {
+		writeTestConfig(t, dir, "MAX_TRANSFER_AMOUNT=200\n")
+		return watcher.Config().MaxTransferAmount == 200
+	}, 5*time.Second, 50*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+}
@@ -0,0 +1,86 @@
+package util
+
+import (
+	"errors"
+	"math/big"
+)
+
+var ErrAmountOutOfRange = errors.New("converted amount is out of range")
+
+// Money amounts are stored as int64 hundredths of the major currency unit,
+// so a currency with fewer decimal places only uses multiples of a larger step.
+const amountDecimals = 2
+
+const defaultCurrencyDecimals = 2
+
+// CurrencyInfo describes how a currency is displayed to clients
+type CurrencyInfo struct {
+	Code   string
+	Symbol string
+	// Decimals is the number of decimal places (ISO 4217 minor units)
+	Decimals int
+}
+
+// currencies is the metadata table of known currencies, in the order they are listed to clients
+var currencies = []CurrencyInfo{
+	{Code: "USD", Symbol: "$", Decimals: 2},
+	{Code: "EUR", Symbol: "€", Decimals: 2},
+	{Code: "GBP", Symbol: "£", Decimals: 2},
+	{Code: "VND", Symbol: "₫", Decimals: 0},
+	{Code: "JPY", Symbol: "¥", Decimals: 0},
+}
+
+// KnownCurrencies returns the codes of all currencies in the metadata table
+func KnownCurrencies() []string {
+	codes := make([]string, len(currencies))
+	for i, currency := range currencies {
+		codes[i] = currency.Code
+	}
+	return codes
+}
+
+// LookupCurrency returns the metadata of the currency.
+// A currency missing from the table uses its code as symbol and the default decimal places.
+func LookupCurrency(code string) CurrencyInfo {
+	for _, currency := range currencies {
+		if currency.Code == code {
+			return currency
+		}
+	}
+	return CurrencyInfo{Code: code, Symbol: code, Decimals: defaultCurrencyDecimals}
+}
+
+// CurrencyDecimals returns the number of decimal places used by the currency
+func CurrencyDecimals(currency string) int {
+	return LookupCurrency(currency).Decimals
+}
+
+// CurrencyAmountStep returns the smallest valid amount of the currency in stored units
+func CurrencyAmountStep(currency string) int64 {
+	step := int64(1)
+	for i := CurrencyDecimals(currency); i < amountDecimals; i++ {
+		step *= 10
+	}
+	return step
+}
+
+// ConvertAmount converts an amount at the given rate into the currency,
+// rounding to the smallest valid amount of that currency with the rounding mode
+func ConvertAmount(amount int64, rate *big.Rat, currency string, mode RoundingMode) (int64, error) {
+	step := big.NewInt(CurrencyAmountStep(currency))
+
+	// scale to whole steps, round, then scale back
+	scaled := new(big.Rat).Mul(new(big.Rat).SetInt64(amount), rate)
+	scaled.Quo(scaled, new(big.Rat).SetInt(step))
+
+	quo, err := mode.round(scaled)
+	if err != nil {
+		return 0, err
+	}
+
+	quo.Mul(quo, step)
+	if !quo.IsInt64() {
+		return 0, ErrAmountOutOfRange
+	}
+	return quo.Int64(), nil
+}
@@ -0,0 +1,42 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CurrencyPair is a conversion from one currency to another
+type CurrencyPair struct {
+	From string
+	To   string
+}
+
+// CurrencyPairs is the set of conversions that are allowed. A nil set allows every pair.
+type CurrencyPairs map[CurrencyPair]bool
+
+// ParseCurrencyPairs reads pairs written as FROM/TO, such as USD/EUR. Each one only allows that direction.
+// An empty list gives a nil set.
+func ParseCurrencyPairs(list []string) (CurrencyPairs, error) {
+	var pairs CurrencyPairs
+	for _, item := range list {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		parts := strings.Split(item, "/")
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+			return nil, fmt.Errorf("currency pair %q must look like FROM/TO", item)
+		}
+		if pairs == nil {
+			pairs = make(CurrencyPairs)
+		}
+		pairs[CurrencyPair{From: strings.TrimSpace(parts[0]), To: strings.TrimSpace(parts[1])}] = true
+	}
+	return pairs, nil
+}
+
+// Allows reports whether converting from one currency to the other is allowed
+func (pairs CurrencyPairs) Allows(from, to string) bool {
+	return pairs == nil || pairs[CurrencyPair{From: from, To: to}]
+}
@@ -0,0 +1,28 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCurrencyPairs(t *testing.T) {
+	pairs, err := ParseCurrencyPairs([]string{"USD/EUR", " EUR / GBP ", ""})
+	require.NoError(t, err)
+	require.Equal(t, CurrencyPairs{{From: "USD", To: "EUR"}: true, {From: "EUR", To: "GBP"}: true}, pairs)
+
+	require.True(t, pairs.Allows("USD", "EUR"))
+	require.True(t, pairs.Allows("EUR", "GBP"))
+	require.False(t, pairs.Allows("EUR", "USD"))
+	require.False(t, pairs.Allows("USD", "JPY"))
+
+	pairs, err = ParseCurrencyPairs(nil)
+	require.NoError(t, err)
+	require.Nil(t, pairs)
+	require.True(t, pairs.Allows("USD", "JPY"))
+
+	for _, invalid := range []string{"USD", "USD/", "/EUR", "USD/EUR/GBP"} {
+		_, err = ParseCurrencyPairs([]string{invalid})
+		require.Error(t, err, invalid)
+	}
+}
@@ -0,0 +1,84 @@
+package util
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCurrencyDecimals(t *testing.T) {
+	require.Equal(t, 2, CurrencyDecimals("USD"))
+	require.Equal(t, 0, CurrencyDecimals("JPY"))
+	require.Equal(t, 0, CurrencyDecimals("VND"))
+	require.Equal(t, defaultCurrencyDecimals, CurrencyDecimals("XYZ"))
+
+	require.Equal(t, int64(1), CurrencyAmountStep("USD"))
+	require.Equal(t, int64(100), CurrencyAmountStep("JPY"))
+}
+
+func TestRandomMoneyForCurrency(t *testing.T) {
+	n := 200
+
+	hasSubUnits := false
+	for i := 0; i < n; i++ {
+		amount := RandomMoneyForCurrency("USD")
+		require.True(t, amount >= 0 && amount <= 1000)
+		if amount%100 != 0 {
+			hasSubUnits = true
+		}
+	}
+	require.True(t, hasSubUnits, "USD amounts should use cents")
+
+	for i := 0; i < n; i++ {
+		amount := RandomMoneyForCurrency("JPY")
+		require.True(t, amount >= 0 && amount <= 1000)
+		require.Zero(t, amount%100, "JPY amounts must be whole yen")
+	}
+}
+
+func TestLookupCurrency(t *testing.T) {
+	require.Equal(t, CurrencyInfo{Code: "EUR", Symbol: "€", Decimals: 2}, LookupCurrency("EUR"))
+	require.Equal(t, CurrencyInfo{Code: "XYZ", Symbol: "XYZ", Decimals: defaultCurrencyDecimals}, LookupCurrency("XYZ"))
+	require.Equal(t, []string{"USD", "EUR", "GBP", "VND", "JPY"}, KnownCurrencies())
+}
+
+func TestConvertAmount(t *testing.T) {
+	rate := func(s string) *big.Rat {
+		r, ok := new(big.Rat).SetString(s)
+		require.True(t, ok)
+		return r
+	}
+
+	testCases := []struct {
+		name     string
+		amount   int64
+		rate     string
+		currency string
+		expected int64
+	}{
+		{name: "Identity", amount: 12345, rate: "1", currency: "EUR", expected: 12345},
+		{name: "RoundsDown", amount: 10000, rate: "0.91234", currency: "EUR", expected: 9123},
+		{name: "RoundsHalfUp", amount: 50, rate: "0.5", currency: "EUR", expected: 25},
+		{name: "HalfAwayFromZero", amount: 5, rate: "0.5", currency: "EUR", expected: 3},
+		{name: "NegativeHalfAwayFromZero", amount: -5, rate: "0.5", currency: "EUR", expected: -3},
+		{name: "WholeUnitCurrency", amount: 1050, rate: "24000.5", currency: "VND", expected: 25200500},
+		{name: "WholeUnitRounding", amount: 150, rate: "1", currency: "JPY", expected: 200},
+		{name: "Zero", amount: 0, rate: "1.5", currency: "USD", expected: 0},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			converted, err := ConvertAmount(tc.amount, rate(tc.rate), tc.currency, RoundHalfUp)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, converted)
+			require.Zero(t, converted%CurrencyAmountStep(tc.currency))
+		})
+	}
+
+	_, err := ConvertAmount(math.MaxInt64, rate("2"), "USD", RoundHalfUp)
+	require.ErrorIs(t, err, ErrAmountOutOfRange)
+}
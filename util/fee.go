@@ -0,0 +1,39 @@
+package util
+
+import (
+	"errors"
+	"math/big"
+)
+
+// ErrNoFeeAccount is returned when a transfer is charged a fee but no account is configured to credit it to
+var ErrNoFeeAccount = errors.New("no fee account is configured for the currency")
+
+// basisPointsPerUnit is the number of basis points in a whole (100%)
+const basisPointsPerUnit = 10000
+
+// FeePolicy describes the fee charged on a transfer: a flat amount plus a percentage of the amount,
+// expressed in basis points (hundredths of a percent)
+type FeePolicy struct {
+	Flat        int64
+	BasisPoints int64
+}
+
+// Fee returns the fee charged on a transfer of amount in the currency.
+// The percentage part is rounded up to the smallest valid amount of the currency.
+func (policy FeePolicy) Fee(amount int64, currency string) (int64, error) {
+	step := big.NewInt(CurrencyAmountStep(currency))
+
+	// amount * basis points / (10000 * step), rounded up, in whole steps
+	num := new(big.Int).Mul(big.NewInt(amount), big.NewInt(policy.BasisPoints))
+	denom := new(big.Int).Mul(big.NewInt(basisPointsPerUnit), step)
+	quo, rem := new(big.Int).QuoRem(num, denom, new(big.Int))
+	if rem.Sign() > 0 {
+		quo.Add(quo, big.NewInt(1))
+	}
+
+	fee := quo.Mul(quo, step).Add(quo, big.NewInt(policy.Flat))
+	if !fee.IsInt64() {
+		return 0, ErrAmountOutOfRange
+	}
+	return fee.Int64(), nil
+}
@@ -0,0 +1,38 @@
+package util
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeePolicy(t *testing.T) {
+	testCases := []struct {
+		name     string
+		policy   FeePolicy
+		amount   int64
+		currency string
+		fee      int64
+	}{
+		{"None", FeePolicy{}, 1000, "USD", 0},
+		{"Flat", FeePolicy{Flat: 50}, 1000, "USD", 50},
+		{"Percentage", FeePolicy{BasisPoints: 150}, 1000, "USD", 15},
+		{"PercentageRoundsUp", FeePolicy{BasisPoints: 150}, 1001, "USD", 16},
+		{"FlatAndPercentage", FeePolicy{Flat: 25, BasisPoints: 100}, 2000, "USD", 45},
+		{"CurrencyStep", FeePolicy{BasisPoints: 100}, 1000, "JPY", 100},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			fee, err := tc.policy.Fee(tc.amount, tc.currency)
+			require.NoError(t, err)
+			require.Equal(t, tc.fee, fee)
+		})
+	}
+
+	_, err := FeePolicy{Flat: math.MaxInt64, BasisPoints: 100}.Fee(math.MaxInt64, "USD")
+	require.ErrorIs(t, err, ErrAmountOutOfRange)
+}
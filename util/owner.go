@@ -0,0 +1,67 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// OwnerRule identifies a single rule of the owner policy
+type OwnerRule string
+
+const (
+	OwnerRuleMinLength  OwnerRule = "min_length"
+	OwnerRuleMaxLength  OwnerRule = "max_length"
+	OwnerRuleCharacters OwnerRule = "characters"
+	OwnerRuleWhitespace OwnerRule = "whitespace"
+)
+
+// OwnerPolicy contains the rules the owner of a new account must satisfy
+type OwnerPolicy struct {
+	MinLength int
+	// MaxLength of zero puts no cap on the length
+	MaxLength int
+	// AllowedSymbols are the characters allowed besides letters and digits
+	AllowedSymbols string
+}
+
+// OwnerPolicyError is returned when an owner violates one or more rules of the policy
+type OwnerPolicyError struct {
+	Violations []OwnerRule `json:"violations"`
+}
+
+func (e *OwnerPolicyError) Error() string {
+	rules := make([]string, len(e.Violations))
+	for i, rule := range e.Violations {
+		rules[i] = string(rule)
+	}
+	return fmt.Sprintf("owner does not satisfy policy: %s", strings.Join(rules, ", "))
+}
+
+// ValidateOwner checks the owner against the policy and reports every rule it violates.
+// Leading or trailing whitespace is never allowed, whatever the policy.
+func ValidateOwner(owner string, policy OwnerPolicy) error {
+	var violations []OwnerRule
+	length := len([]rune(owner))
+	if length < policy.MinLength {
+		violations = append(violations, OwnerRuleMinLength)
+	}
+	if policy.MaxLength > 0 && length > policy.MaxLength {
+		violations = append(violations, OwnerRuleMaxLength)
+	}
+	for _, c := range owner {
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) && !strings.ContainsRune(policy.AllowedSymbols, c) {
+			violations = append(violations, OwnerRuleCharacters)
+			break
+		}
+	}
+	if strings.TrimSpace(owner) != owner {
+		violations = append(violations, OwnerRuleWhitespace)
+	}
+
+	if len(violations) > 0 {
+		return &OwnerPolicyError{Violations: violations}
+	}
+
+	return nil
+}
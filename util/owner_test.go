@@ -0,0 +1,99 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateOwner(t *testing.T) {
+	policy := OwnerPolicy{MinLength: 3, MaxLength: 10, AllowedSymbols: "._-"}
+
+	testCases := []struct {
+		name       string
+		owner      string
+		policy     OwnerPolicy
+		violations []OwnerRule
+	}{
+		{
+			name:   "OK",
+			owner:  "jane.doe-1",
+			policy: policy,
+		},
+		{
+			name:   "NonASCIILetters",
+			owner:  "nguyễn",
+			policy: policy,
+		},
+		{
+			name:       "TooShort",
+			owner:      "jd",
+			policy:     policy,
+			violations: []OwnerRule{OwnerRuleMinLength},
+		},
+		{
+			name:       "TooLong",
+			owner:      "jane.doe.smith",
+			policy:     policy,
+			violations: []OwnerRule{OwnerRuleMaxLength},
+		},
+		{
+			name:       "DisallowedCharacter",
+			owner:      "jane@doe",
+			policy:     policy,
+			violations: []OwnerRule{OwnerRuleCharacters},
+		},
+		{
+			name:       "InnerSpace",
+			owner:      "jane doe",
+			policy:     policy,
+			violations: []OwnerRule{OwnerRuleCharacters},
+		},
+		{
+			name:       "LeadingWhitespace",
+			owner:      " jane",
+			policy:     OwnerPolicy{AllowedSymbols: " "},
+			violations: []OwnerRule{OwnerRuleWhitespace},
+		},
+		{
+			name:       "TrailingWhitespace",
+			owner:      "jane\t",
+			policy:     policy,
+			violations: []OwnerRule{OwnerRuleCharacters, OwnerRuleWhitespace},
+		},
+		{
+			name:       "Empty",
+			owner:      "",
+			policy:     policy,
+			violations: []OwnerRule{OwnerRuleMinLength},
+		},
+		{
+			name:   "EmptyPolicy",
+			owner:  "a",
+			policy: OwnerPolicy{},
+		},
+		{
+			name:       "LengthCountsRunes",
+			owner:      "éé",
+			policy:     OwnerPolicy{MinLength: 3},
+			violations: []OwnerRule{OwnerRuleMinLength},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateOwner(tc.owner, tc.policy)
+			if len(tc.violations) == 0 {
+				require.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			policyErr, ok := err.(*OwnerPolicyError)
+			require.True(t, ok)
+			require.Equal(t, tc.violations, policyErr.Violations)
+		})
+	}
+}
@@ -40,6 +40,13 @@ func RandomMoney() int64 {
 	return RandomInt(0, 1000)
 }
 
+// RandomMoneyForCurrency generate a random amount of money that is valid for the currency,
+// e.g. a whole number of yen for JPY
+func RandomMoneyForCurrency(currency string) int64 {
+	step := CurrencyAmountStep(currency)
+	return RandomInt(0, 1000/step) * step
+}
+
 // RandomCurrency generate a random currency code
 func RandomCurrency() string {
 	currencies := []string{"USD", "EUR", "GBP", "VND"}
@@ -0,0 +1,64 @@
+package util
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// RoundingMode tells how a converted amount that falls between two valid amounts of the currency is rounded
+type RoundingMode string
+
+// Supported rounding modes
+const (
+	// RoundHalfUp rounds to the nearest amount, halves away from zero
+	RoundHalfUp RoundingMode = "half_up"
+	// RoundHalfEven rounds to the nearest amount, halves to the even one
+	RoundHalfEven RoundingMode = "half_even"
+	// RoundFloor rounds towards negative infinity
+	RoundFloor RoundingMode = "floor"
+	// RoundCeil rounds towards positive infinity
+	RoundCeil RoundingMode = "ceil"
+)
+
+// Valid reports whether the rounding mode is one of the supported modes
+func (mode RoundingMode) Valid() bool {
+	switch mode {
+	case RoundHalfUp, RoundHalfEven, RoundFloor, RoundCeil:
+		return true
+	}
+	return false
+}
+
+// round returns r rounded to an integer. An empty mode rounds half up.
+func (mode RoundingMode) round(r *big.Rat) (*big.Int, error) {
+	num, denom := r.Num(), r.Denom()
+	quo, rem := new(big.Int).QuoRem(num, denom, new(big.Int))
+	if rem.Sign() == 0 {
+		return quo, nil
+	}
+
+	// compare twice the remainder with the denominator to tell which half r falls in
+	half := new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2)).Cmp(denom)
+	away := big.NewInt(int64(num.Sign()))
+	switch mode {
+	case RoundHalfUp, "":
+		if half >= 0 {
+			quo.Add(quo, away)
+		}
+	case RoundHalfEven:
+		if half > 0 || (half == 0 && quo.Bit(0) == 1) {
+			quo.Add(quo, away)
+		}
+	case RoundFloor:
+		if rem.Sign() < 0 {
+			quo.Sub(quo, big.NewInt(1))
+		}
+	case RoundCeil:
+		if rem.Sign() > 0 {
+			quo.Add(quo, big.NewInt(1))
+		}
+	default:
+		return nil, fmt.Errorf("unknown rounding mode %q", mode)
+	}
+	return quo, nil
+}
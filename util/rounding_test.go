@@ -0,0 +1,77 @@
+package util
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertAmountRoundingModes(t *testing.T) {
+	testCases := []struct {
+		name     string
+		amount   int64
+		rate     *big.Rat
+		expected map[RoundingMode]int64
+	}{
+		{
+			name:     "HalfToEven",
+			amount:   5,
+			rate:     big.NewRat(1, 2),
+			expected: map[RoundingMode]int64{RoundHalfUp: 3, RoundHalfEven: 2, RoundFloor: 2, RoundCeil: 3},
+		},
+		{
+			name:     "HalfToOdd",
+			amount:   7,
+			rate:     big.NewRat(1, 2),
+			expected: map[RoundingMode]int64{RoundHalfUp: 4, RoundHalfEven: 4, RoundFloor: 3, RoundCeil: 4},
+		},
+		{
+			name:     "BelowHalf",
+			amount:   10,
+			rate:     big.NewRat(24, 100),
+			expected: map[RoundingMode]int64{RoundHalfUp: 2, RoundHalfEven: 2, RoundFloor: 2, RoundCeil: 3},
+		},
+		{
+			name:     "AboveHalf",
+			amount:   10,
+			rate:     big.NewRat(26, 100),
+			expected: map[RoundingMode]int64{RoundHalfUp: 3, RoundHalfEven: 3, RoundFloor: 2, RoundCeil: 3},
+		},
+		{
+			name:     "NegativeHalf",
+			amount:   -5,
+			rate:     big.NewRat(1, 2),
+			expected: map[RoundingMode]int64{RoundHalfUp: -3, RoundHalfEven: -2, RoundFloor: -3, RoundCeil: -2},
+		},
+		{
+			name:     "Exact",
+			amount:   10,
+			rate:     big.NewRat(3, 1),
+			expected: map[RoundingMode]int64{RoundHalfUp: 30, RoundHalfEven: 30, RoundFloor: 30, RoundCeil: 30},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			for mode, expected := range tc.expected {
+				converted, err := ConvertAmount(tc.amount, tc.rate, "EUR", mode)
+				require.NoError(t, err)
+				require.Equal(t, expected, converted, "rounding mode %s", mode)
+			}
+		})
+	}
+
+	// whole unit currencies round to their step
+	converted, err := ConvertAmount(150, big.NewRat(1, 1), "JPY", RoundHalfEven)
+	require.NoError(t, err)
+	require.Equal(t, int64(200), converted)
+	converted, err = ConvertAmount(150, big.NewRat(1, 1), "JPY", RoundFloor)
+	require.NoError(t, err)
+	require.Equal(t, int64(100), converted)
+
+	_, err = ConvertAmount(5, big.NewRat(1, 2), "EUR", "truncate")
+	require.Error(t, err)
+}
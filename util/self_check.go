@@ -0,0 +1,56 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// selfCheckTimeout bounds the database round trips of the startup self-check
+const selfCheckTimeout = 5 * time.Second
+
+var (
+	ErrDatabaseUnreachable = errors.New("database is not reachable")
+	ErrSchemaNotCurrent    = errors.New("database schema is not current")
+	ErrInvalidCurrencies   = errors.New("supported currencies are invalid")
+)
+
+// SelfCheckStore is the part of the store the startup self-check looks at
+type SelfCheckStore interface {
+	Ping(ctx context.Context) error
+	CheckSchema(ctx context.Context) error
+}
+
+// SelfCheck verifies the invariants the server relies on before it starts serving,
+// so a misconfigured deployment fails at boot with a clear message instead of on its first requests.
+// It returns the first check that fails.
+func SelfCheck(config Config, store SelfCheckStore) error {
+	if err := checkCurrencies(config); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), selfCheckTimeout)
+	defer cancel()
+
+	if err := store.Ping(ctx); err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseUnreachable, err)
+	}
+	if err := store.CheckSchema(ctx); err != nil {
+		return fmt.Errorf("%w: %v", ErrSchemaNotCurrent, err)
+	}
+	return nil
+}
+
+// checkCurrencies checks no configured currency is blank, which would leave accounts in a currency clients cannot name.
+// An empty list is fine since it supports every known currency,
+// and currencies missing from the metadata table are allowed as they are everywhere else.
+func checkCurrencies(config Config) error {
+	for _, currency := range config.SupportedCurrencies {
+		if strings.TrimSpace(currency) == "" {
+			return fmt.Errorf("%w: SUPPORTED_CURRENCIES has an empty entry", ErrInvalidCurrencies)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSelfCheckStore struct {
+	pingErr   error
+	schemaErr error
+}
+
+func (store fakeSelfCheckStore) Ping(ctx context.Context) error {
+	return store.pingErr
+}
+
+func (store fakeSelfCheckStore) CheckSchema(ctx context.Context) error {
+	return store.schemaErr
+}
+
+func TestSelfCheck(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config Config
+		store  fakeSelfCheckStore
+		err    error
+	}{
+		{
+			name:   "OK",
+			config: Config{SupportedCurrencies: []string{"USD", "EUR"}},
+		},
+		{
+			name:   "AllKnownCurrencies",
+			config: Config{},
+		},
+		{
+			name:   "DatabaseUnreachable",
+			config: Config{},
+			store:  fakeSelfCheckStore{pingErr: errors.New("connection refused")},
+			err:    ErrDatabaseUnreachable,
+		},
+		{
+			name:   "SchemaNotCurrent",
+			config: Config{},
+			store:  fakeSelfCheckStore{schemaErr: errors.New("database is at migration 12, expected 13")},
+			err:    ErrSchemaNotCurrent,
+		},
+		{
+			name:   "BlankCurrency",
+			config: Config{SupportedCurrencies: []string{"USD", " "}},
+			err:    ErrInvalidCurrencies,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			err := SelfCheck(tc.config, tc.store)
+			if tc.err == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, tc.err)
+		})
+	}
+}